@@ -38,6 +38,11 @@ def generate_config_file(rd_server_v,db_name_v,redis_ip_v,redis_port_v,redis_use
     maxIdleConns = 1000
     [errors]
     res=conf/errors
+    [audit]
+    archive_path=./archive
+    archive_interval_minutes=60
+    [audit_retention]
+    default=0
     '''
     template = FileTemplate(auditcontroller_file_template_str)
     result = template.substitute(dict(db=db_name_v,mongo_user=mongo_user_v,mongo_host=mongo_ip_v,mongo_pass=mongo_pass_v,mongo_port=mongo_port_v))
@@ -94,6 +99,8 @@ def generate_config_file(rd_server_v,db_name_v,redis_ip_v,redis_port_v,redis_use
     maxIDleConns=1000
     [errors]
     res=conf/errors
+    [event]
+    history_expire_days=7
     '''
     
     template = FileTemplate(eventserver_file_template_str)
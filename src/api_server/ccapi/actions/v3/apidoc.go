@@ -0,0 +1,36 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package v3
+
+// MapData the properties keyed by the property id, the free form body of the instance, set, module and so on
+type MapData map[string]interface{}
+
+// CreateResult the data of the create actions which reply the id of the created item
+type CreateResult struct {
+	ID int `json:"id"`
+}
+
+// SearchResult the data of the search actions which reply the matched items of the page,
+// the count is omitted if skip_count is set, the next_cursor is replied if the page is requested by the cursor
+type SearchResult struct {
+	Count      int       `json:"count,omitempty"`
+	Info       []MapData `json:"info"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// PropertyValue the value of the property in the detail of the host or process
+type PropertyValue struct {
+	PropertyID    string      `json:"bk_property_id"`
+	PropertyName  string      `json:"bk_property_name"`
+	PropertyValue interface{} `json:"bk_property_value"`
+}
@@ -15,9 +15,11 @@ package controllers
 import (
 	"configcenter/src/api_server/ccapi/actions/v3"
 	"configcenter/src/common"
+	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/actions"
 	"configcenter/src/common/core/cc/api"
 	httpcli "configcenter/src/common/http/httpclient"
+	"configcenter/src/source_controller/common/commondata"
 	"io"
 
 	"github.com/emicklei/go-restful"
@@ -37,7 +39,16 @@ func (cli *auditAction) Search(req *restful.Request, resp *restful.Response) {
 
 }
 
+// Export export the operation logs as jsonl or csv
+func (cli *auditAction) Export(req *restful.Request, resp *restful.Response) {
+	url := cli.cc.TopoAPI() + "/topo/v1/audit/export?format=" + req.QueryParameter("format")
+	if err := httpcli.ReqStreamForward(req, resp, url, common.HTTPSelectPost); nil != err {
+		blog.Errorf("export operation log error: %v", err)
+	}
+}
+
 func init() {
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/audit/search", Params: nil, Handler: audit.Search, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/audit/search", Params: nil, Handler: audit.Search, Version: v3.APIVersion, Doc: "search the operation logs", Request: commondata.ObjQueryInput{}, Response: AuditSearchResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/audit/export", Params: []*restful.Parameter{restful.QueryParameter("format", "the export format, jsonl or csv")}, Handler: audit.Export, Version: v3.APIVersion, Doc: "export the operation logs as jsonl or csv", Request: commondata.ObjQueryInput{}})
 	audit.cc = api.NewAPIResource()
 }
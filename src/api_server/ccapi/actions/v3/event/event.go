@@ -16,10 +16,12 @@ import (
 	"configcenter/src/api_server/ccapi/actions/v3"
 	"configcenter/src/common"
 	"configcenter/src/common/core/cc/actions"
+	params "configcenter/src/common/paraparse"
 
 	"configcenter/src/common/base"
 	"configcenter/src/common/blog"
 	httpcli "configcenter/src/common/http/httpclient"
+	"configcenter/src/scene_server/event_server/types"
 	"io"
 
 	"github.com/emicklei/go-restful"
@@ -95,14 +97,80 @@ func (cli *procAction) Telnet(req *restful.Request, resp *restful.Response) {
 	io.WriteString(resp, rsp)
 }
 
+//search dead letters
+func (cli *procAction) SearchDeadLetters(req *restful.Request, resp *restful.Response) {
+	blog.Info("search dead letters")
+	pathParams := req.PathParameters()
+	ownerID := pathParams["owner_id"]
+	appID := pathParams["app_id"]
+	subscribeID := pathParams["subscribe_id"]
+	url := cli.CC.EventAPI() + "/event/v1/subscribe/deadletter/search/" + ownerID + "/" + appID + "/" + subscribeID
+	rsp, _ := httpcli.ReqForward(req, url, common.HTTPSelectPost)
+	io.WriteString(resp, rsp)
+}
+
+//get dead letter
+func (cli *procAction) GetDeadLetter(req *restful.Request, resp *restful.Response) {
+	blog.Info("get dead letter")
+	pathParams := req.PathParameters()
+	ownerID := pathParams["owner_id"]
+	appID := pathParams["app_id"]
+	subscribeID := pathParams["subscribe_id"]
+	dstbID := pathParams["dstb_id"]
+	url := cli.CC.EventAPI() + "/event/v1/subscribe/deadletter/" + ownerID + "/" + appID + "/" + subscribeID + "/" + dstbID
+	rsp, _ := httpcli.ReqForward(req, url, common.HTTPSelectGet)
+	io.WriteString(resp, rsp)
+}
+
+//replay dead letters
+func (cli *procAction) ReplayDeadLetters(req *restful.Request, resp *restful.Response) {
+	blog.Info("replay dead letters")
+	pathParams := req.PathParameters()
+	ownerID := pathParams["owner_id"]
+	appID := pathParams["app_id"]
+	subscribeID := pathParams["subscribe_id"]
+	url := cli.CC.EventAPI() + "/event/v1/subscribe/deadletter/replay/" + ownerID + "/" + appID + "/" + subscribeID
+	rsp, _ := httpcli.ReqForward(req, url, common.HTTPSelectPost)
+	io.WriteString(resp, rsp)
+}
+
+//delete dead letters
+func (cli *procAction) DeleteDeadLetters(req *restful.Request, resp *restful.Response) {
+	blog.Info("delete dead letters")
+	pathParams := req.PathParameters()
+	ownerID := pathParams["owner_id"]
+	appID := pathParams["app_id"]
+	subscribeID := pathParams["subscribe_id"]
+	url := cli.CC.EventAPI() + "/event/v1/subscribe/deadletter/" + ownerID + "/" + appID + "/" + subscribeID
+	rsp, _ := httpcli.ReqForward(req, url, common.HTTPDelete)
+	io.WriteString(resp, rsp)
+}
+
+//replay events
+func (cli *procAction) Replay(req *restful.Request, resp *restful.Response) {
+	blog.Info("replay events")
+	pathParams := req.PathParameters()
+	ownerID := pathParams["owner_id"]
+	appID := pathParams["app_id"]
+	subscribeID := pathParams["subscribe_id"]
+	url := cli.CC.EventAPI() + "/event/v1/subscribe/replay/" + ownerID + "/" + appID + "/" + subscribeID
+	rsp, _ := httpcli.ReqForward(req, url, common.HTTPSelectPost)
+	io.WriteString(resp, rsp)
+}
+
 func init() {
 
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/event/subscribe/ping", Params: nil, Handler: event.Ping, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/event/subscribe/telnet", Params: nil, Handler: event.Telnet, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/event/subscribe/search/{owner_id}/{app_id}", Params: nil, Handler: event.Query, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/event/subscribe/{owner_id}/{app_id}", Params: nil, Handler: event.Subscribe, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/event/subscribe/{owner_id}/{app_id}/{subscribe_id}", Params: nil, Handler: event.UnSubscribe, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/event/subscribe/{owner_id}/{app_id}/{subscribe_id}", Params: nil, Handler: event.Rebook, FilterHandler: nil, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/event/subscribe/ping", Params: nil, Handler: event.Ping, FilterHandler: nil, Version: v3.APIVersion, Doc: "ping the callback url of the subscription", Request: CallbackParams{}, Response: PingResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/event/subscribe/telnet", Params: nil, Handler: event.Telnet, FilterHandler: nil, Version: v3.APIVersion, Doc: "check the callback address of the subscription is reachable", Request: CallbackParams{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/event/subscribe/search/{owner_id}/{app_id}", Params: nil, Handler: event.Query, FilterHandler: nil, Version: v3.APIVersion, Doc: "search the subscriptions", Request: params.SubscribeCommonSearch{}, Response: SubscriptionSearchResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/event/subscribe/{owner_id}/{app_id}", Params: nil, Handler: event.Subscribe, FilterHandler: nil, Version: v3.APIVersion, Doc: "create the subscription", Request: types.Subscription{}, Response: SubscriptionResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/event/subscribe/{owner_id}/{app_id}/{subscribe_id}", Params: nil, Handler: event.UnSubscribe, FilterHandler: nil, Version: v3.APIVersion, Doc: "delete the subscription"})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/event/subscribe/{owner_id}/{app_id}/{subscribe_id}", Params: nil, Handler: event.Rebook, FilterHandler: nil, Version: v3.APIVersion, Doc: "update the subscription", Request: types.Subscription{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/event/subscribe/deadletter/search/{owner_id}/{app_id}/{subscribe_id}", Params: nil, Handler: event.SearchDeadLetters, FilterHandler: nil, Version: v3.APIVersion, Doc: "search the dead letters of the subscription", Request: DeadLetterSearch{}, Response: DeadLetterSearchResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/event/subscribe/deadletter/{owner_id}/{app_id}/{subscribe_id}/{dstb_id}", Params: nil, Handler: event.GetDeadLetter, FilterHandler: nil, Version: v3.APIVersion, Doc: "get the dead letter", Response: types.DeadLetter{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/event/subscribe/deadletter/replay/{owner_id}/{app_id}/{subscribe_id}", Params: nil, Handler: event.ReplayDeadLetters, FilterHandler: nil, Version: v3.APIVersion, Doc: "replay the dead letters of the subscription", Request: DeadLetterParams{}, Response: map[string]string{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/event/subscribe/deadletter/{owner_id}/{app_id}/{subscribe_id}", Params: nil, Handler: event.DeleteDeadLetters, FilterHandler: nil, Version: v3.APIVersion, Doc: "delete the dead letters of the subscription", Request: DeadLetterParams{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/event/subscribe/replay/{owner_id}/{app_id}/{subscribe_id}", Params: nil, Handler: event.Replay, FilterHandler: nil, Version: v3.APIVersion, Doc: "replay the events of the subscription", Request: ReplayOption{}, Response: ReplayResult{}})
 	// set cc api interface
 	event.CreateAction()
 }
@@ -0,0 +1,70 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package process
+
+import (
+	params "configcenter/src/common/paraparse"
+	commontypes "configcenter/src/common/types"
+	"configcenter/src/scene_server/event_server/types"
+)
+
+// CallbackParams the callback url of the subscription to check, the ping posts the data to it
+type CallbackParams struct {
+	CallbackURL string `json:"bk_callback_url"`
+	Data        string `json:"bk_data,omitempty"`
+}
+
+// PingResult the reply of the callback url
+type PingResult struct {
+	HTTPStatus   int    `json:"bk_http_status"`
+	ResponseBody string `json:"bk_response_body"`
+}
+
+// SubscriptionResult the id of the created subscription
+type SubscriptionResult struct {
+	SubscriptionID int64 `json:"subscription_id"`
+}
+
+// SubscriptionSearchResult the subscriptions of the page
+type SubscriptionSearchResult struct {
+	Count int                  `json:"count"`
+	Info  []types.Subscription `json:"info"`
+}
+
+// DeadLetterSearch the page of the dead letters to search
+type DeadLetterSearch struct {
+	Page params.PageInfo `json:"page"`
+}
+
+// DeadLetterSearchResult the dead letters of the page
+type DeadLetterSearchResult struct {
+	Count int                `json:"count"`
+	Info  []types.DeadLetter `json:"info"`
+}
+
+// DeadLetterParams the dead letters to replay or delete, all the dead letters of the subscription if empty
+type DeadLetterParams struct {
+	DstbIDs []int64 `json:"dstb_ids"`
+}
+
+// ReplayOption the range of the events to replay, all the fields are optional
+type ReplayOption struct {
+	StartEventID int64             `json:"start_event_id"`
+	StartTime    *commontypes.Time `json:"start_time"`
+	EndTime      *commontypes.Time `json:"end_time"`
+}
+
+// ReplayResult the count of the replayed events
+type ReplayResult struct {
+	Count int `json:"count"`
+}
@@ -18,6 +18,7 @@ import (
 	"configcenter/src/common/core/cc/actions"
 	"configcenter/src/common/core/cc/api"
 	httpcli "configcenter/src/common/http/httpclient"
+	"configcenter/src/source_controller/common/commondata"
 	"fmt"
 	"io"
 
@@ -75,10 +76,10 @@ func IncrHostFavouritesCount(req *restful.Request, resp *restful.Response) {
 }
 
 func init() {
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/hosts/favorites/search", Params: nil, Handler: GetHostFavourites, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/hosts/favorites", Params: nil, Handler: AddHostFavourite, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/hosts/favorites/{id}", Params: nil, Handler: EditHostFavourite, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/hosts/favorites/{id}", Params: nil, Handler: DeleteHostFavourite, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/hosts/favorites/{id}/incr", Params: nil, Handler: IncrHostFavouritesCount, FilterHandler: nil, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/hosts/favorites/search", Params: nil, Handler: GetHostFavourites, FilterHandler: nil, Version: v3.APIVersion, Doc: "search the host favorites", Request: commondata.ObjQueryInput{}, Response: v3.SearchResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/hosts/favorites", Params: nil, Handler: AddHostFavourite, FilterHandler: nil, Version: v3.APIVersion, Doc: "create the host favorite", Request: FavouriteParams{}, Response: IDResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/hosts/favorites/{id}", Params: nil, Handler: EditHostFavourite, FilterHandler: nil, Version: v3.APIVersion, Doc: "update the host favorite", Request: FavouriteParams{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/hosts/favorites/{id}", Params: nil, Handler: DeleteHostFavourite, FilterHandler: nil, Version: v3.APIVersion, Doc: "delete the host favorite"})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/hosts/favorites/{id}/incr", Params: nil, Handler: IncrHostFavouritesCount, FilterHandler: nil, Version: v3.APIVersion, Doc: "increase the usage count of the host favorite"})
 
 }
@@ -57,7 +57,7 @@ func (cli *historyAction) GetHistorys(req *restful.Request, resp *restful.Respon
 
 func init() {
 	history.CreateAction()
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/hosts/history", Params: nil, Handler: history.AddHistory, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/hosts/history/{skip}/{limit}", Params: nil, Handler: history.GetHistorys, FilterHandler: nil, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/hosts/history", Params: nil, Handler: history.AddHistory, FilterHandler: nil, Version: v3.APIVersion, Doc: "save the host search history", Request: HistoryParams{}, Response: IDResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/hosts/history/{skip}/{limit}", Params: nil, Handler: history.GetHistorys, FilterHandler: nil, Version: v3.APIVersion, Doc: "search the host search histories", Response: v3.SearchResult{}})
 
 }
@@ -19,6 +19,7 @@ import (
 	"configcenter/src/common/core/cc/actions"
 	"configcenter/src/common/core/cc/api"
 	httpcli "configcenter/src/common/http/httpclient"
+	params "configcenter/src/common/paraparse"
 	"io"
 
 	"github.com/emicklei/go-restful"
@@ -120,17 +121,17 @@ func (cli *hostAction) addHostFromAgent(req *restful.Request, resp *restful.Resp
 }
 
 func init() {
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/hosts/search", Params: nil, Handler: host.GetHosts, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/hosts/batch", Params: nil, Handler: host.DeleteHosts, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/hosts/batch", Params: nil, Handler: host.UpdateHosts, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/hosts/{bk_supplier_account}/{bk_host_id}", Params: nil, Handler: host.GetHostDetail, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/hosts/modules", Params: nil, Handler: host.HostAddModulesRelation, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/hosts/modules/idle", Params: nil, Handler: host.HostMoveToIDleModules, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/hosts/modules/fault", Params: nil, Handler: host.HostMoveToFaultModules, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/hosts/modules/resource", Params: nil, Handler: host.HostMoveToResoucePool, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/hosts/add", Params: nil, Handler: host.AddHost, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/hosts/modules/resource/idle", Params: nil, Handler: host.AssginHostToApp, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/hosts/snapshot/{bk_host_id}", Params: nil, Handler: host.Snapshot, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/host/add/agent", Params: nil, Handler: host.addHostFromAgent, FilterHandler: nil, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/hosts/search", Params: nil, Handler: host.GetHosts, FilterHandler: nil, Version: v3.APIVersion, Doc: "search the hosts", Request: params.HostCommonSearch{}, Response: v3.SearchResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/hosts/batch", Params: nil, Handler: host.DeleteHosts, FilterHandler: nil, Version: v3.APIVersion, Doc: "delete the hosts", Request: HostIDParams{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/hosts/batch", Params: nil, Handler: host.UpdateHosts, FilterHandler: nil, Version: v3.APIVersion, Doc: "update the hosts", Request: v3.MapData{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/hosts/{bk_supplier_account}/{bk_host_id}", Params: nil, Handler: host.GetHostDetail, FilterHandler: nil, Version: v3.APIVersion, Doc: "get the host detail", Response: []v3.PropertyValue{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/hosts/modules", Params: nil, Handler: host.HostAddModulesRelation, FilterHandler: nil, Version: v3.APIVersion, Doc: "transfer the hosts to the modules", Request: ModuleHostParams{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/hosts/modules/idle", Params: nil, Handler: host.HostMoveToIDleModules, FilterHandler: nil, Version: v3.APIVersion, Doc: "move the hosts to the idle module", Request: DefaultModuleHostParams{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/hosts/modules/fault", Params: nil, Handler: host.HostMoveToFaultModules, FilterHandler: nil, Version: v3.APIVersion, Doc: "move the hosts to the fault module", Request: DefaultModuleHostParams{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/hosts/modules/resource", Params: nil, Handler: host.HostMoveToResoucePool, FilterHandler: nil, Version: v3.APIVersion, Doc: "move the hosts to the resource pool", Request: DefaultModuleHostParams{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/hosts/add", Params: nil, Handler: host.AddHost, FilterHandler: nil, Version: v3.APIVersion, Doc: "import the hosts to the resource pool", Request: AddHostParams{}, Response: AddHostResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/hosts/modules/resource/idle", Params: nil, Handler: host.AssginHostToApp, FilterHandler: nil, Version: v3.APIVersion, Doc: "assign the hosts in the resource pool to the business", Request: DefaultModuleHostParams{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/hosts/snapshot/{bk_host_id}", Params: nil, Handler: host.Snapshot, FilterHandler: nil, Version: v3.APIVersion, Doc: "get the host snapshot", Response: v3.MapData{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/host/add/agent", Params: nil, Handler: host.addHostFromAgent, FilterHandler: nil, Version: v3.APIVersion, Doc: "add the host reported by the agent", Request: AgentHostParams{}})
 	host.cc = api.NewAPIResource()
 }
@@ -0,0 +1,97 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package host
+
+import (
+	"configcenter/src/api_server/ccapi/actions/v3"
+)
+
+// FavouriteParams the host favorite, the query params and info are the json of the search conditions
+type FavouriteParams struct {
+	ID          string `json:"id"`
+	Info        string `json:"info"`
+	QueryParams string `json:"query_params"`
+	Name        string `json:"name"`
+	IsDefault   int    `json:"is_default"`
+	Count       int    `json:"count"`
+}
+
+// IDResult the id of the created favorite, history or custom query
+type IDResult struct {
+	ID string `json:"id"`
+}
+
+// HistoryParams the host search history
+type HistoryParams struct {
+	Content string `json:"content"`
+}
+
+// HostIDParams the hosts of the batch update or delete, the bk_host_id is the comma separated host ids,
+// the update takes the other properties of the host in the same body
+type HostIDParams struct {
+	HostID string `json:"bk_host_id"`
+}
+
+// ModuleHostParams transfer the hosts of the business to the modules
+type ModuleHostParams struct {
+	ApplicationID int   `json:"bk_biz_id"`
+	HostID        []int `json:"bk_host_id"`
+	ModuleID      []int `json:"bk_module_id"`
+	IsIncrement   bool  `json:"is_increment"`
+}
+
+// DefaultModuleHostParams move the hosts of the business to the idle, fault module or the resource pool
+type DefaultModuleHostParams struct {
+	ApplicationID int   `json:"bk_biz_id"`
+	HostID        []int `json:"bk_host_id"`
+}
+
+// AddHostParams the hosts to import, keyed by the row index
+type AddHostParams struct {
+	ApplicationID int                `json:"bk_biz_id"`
+	HostInfo      map[int]v3.MapData `json:"host_info"`
+	SupplierID    int                `json:"bk_supplier_id"`
+}
+
+// AddHostResult the rows imported and the errors of the failed rows
+type AddHostResult struct {
+	Success     []string `json:"success"`
+	Error       []string `json:"error,omitempty"`
+	UpdateError []string `json:"update_error,omitempty"`
+}
+
+// AgentHostParams the host reported by the agent
+type AgentHostParams struct {
+	HostInfo v3.MapData `json:"HostInfo"`
+}
+
+// UserAPIParams the custom query, the dynamic query is evaluated every eval_interval, e.g. 10m
+type UserAPIParams struct {
+	Name         string `json:"name"`
+	AppID        int    `json:"bk_biz_id"`
+	Info         string `json:"info"`
+	Dynamic      bool   `json:"dynamic,omitempty"`
+	EvalInterval string `json:"eval_interval,omitempty"`
+}
+
+// HostGroupMember the host matched by the dynamic host group
+type HostGroupMember struct {
+	HostID  int    `json:"bk_host_id"`
+	InnerIP string `json:"bk_host_innerip"`
+}
+
+// HostGroupMembers the hosts of the dynamic host group
+type HostGroupMembers struct {
+	Count int               `json:"count"`
+	Info  []HostGroupMember `json:"info"`
+}
@@ -18,6 +18,7 @@ import (
 	"configcenter/src/common/base"
 	"configcenter/src/common/core/cc/actions"
 	httpcli "configcenter/src/common/http/httpclient"
+	"configcenter/src/source_controller/common/commondata"
 	"fmt"
 	"io"
 
@@ -87,14 +88,25 @@ func (u *userAPIAction) GetUserAPIData(req *restful.Request, resp *restful.Respo
 	return
 }
 
+//GetMembers 获取动态主机组的成员
+func (u *userAPIAction) GetMembers(req *restful.Request, resp *restful.Response) {
+
+	url := userAPI.CC.HostAPI() + fmt.Sprintf("/host/v1/userapi/members/%s/%s", req.PathParameter("app_id"), req.PathParameter("id"))
+	rsp, _ := httpcli.ReqForward(req, url, common.HTTPSelectGet)
+
+	io.WriteString(resp, rsp)
+	return
+}
+
 func init() {
 	userAPI.CreateAction()
 
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/userapi", Params: nil, Handler: userAPI.Add, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/userapi/{app_id}/{id}", Params: nil, Handler: userAPI.Update, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/userapi/{app_id}/{id}", Params: nil, Handler: userAPI.Delete, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/userapi/search/{app_id}", Params: nil, Handler: userAPI.Get, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/userapi/detail/{app_id}/{id}", Params: nil, Handler: userAPI.Detail, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/userapi/data/{app_id}/{id}/{skip}/{limit}", Params: nil, Handler: userAPI.GetUserAPIData, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/userapi", Params: nil, Handler: userAPI.Add, Version: v3.APIVersion, Doc: "create the custom query", Request: UserAPIParams{}, Response: IDResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/userapi/{app_id}/{id}", Params: nil, Handler: userAPI.Update, Version: v3.APIVersion, Doc: "update the custom query", Request: UserAPIParams{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/userapi/{app_id}/{id}", Params: nil, Handler: userAPI.Delete, Version: v3.APIVersion, Doc: "delete the custom query"})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/userapi/search/{app_id}", Params: nil, Handler: userAPI.Get, Version: v3.APIVersion, Doc: "search the custom queries of the business", Request: commondata.ObjQueryInput{}, Response: v3.SearchResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/userapi/detail/{app_id}/{id}", Params: nil, Handler: userAPI.Detail, Version: v3.APIVersion, Doc: "get the custom query", Response: v3.MapData{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/userapi/data/{app_id}/{id}/{skip}/{limit}", Params: nil, Handler: userAPI.GetUserAPIData, Version: v3.APIVersion, Doc: "search the hosts matched by the custom query", Response: v3.SearchResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/userapi/members/{app_id}/{id}", Params: nil, Handler: userAPI.GetMembers, Version: v3.APIVersion, Doc: "get the hosts of the dynamic host group stored by the last evaluation", Response: HostGroupMembers{}})
 
 }
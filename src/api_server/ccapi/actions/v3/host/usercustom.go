@@ -48,8 +48,8 @@ func GetDefault(req *restful.Request, resp *restful.Response) {
 }
 
 func init() {
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/usercustom", Params: nil, Handler: SaveUserCustom, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/usercustom/user/search", Params: nil, Handler: GetUser, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/usercustom/default/search", Params: nil, Handler: GetDefault, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/usercustom", Params: nil, Handler: SaveUserCustom, Version: v3.APIVersion, Doc: "save the custom settings of the user", Request: v3.MapData{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/usercustom/user/search", Params: nil, Handler: GetUser, Version: v3.APIVersion, Doc: "get the custom settings of the user", Response: v3.MapData{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/usercustom/default/search", Params: nil, Handler: GetDefault, Version: v3.APIVersion, Doc: "get the default custom settings", Response: v3.MapData{}})
 
 }
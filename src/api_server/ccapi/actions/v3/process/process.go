@@ -19,6 +19,7 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/actions"
 	httpcli "configcenter/src/common/http/httpclient"
+	params "configcenter/src/common/paraparse"
 	"io"
 
 	"github.com/emicklei/go-restful"
@@ -119,14 +120,14 @@ func (cli *procAction) DeleteProcBindModule(req *restful.Request, resp *restful.
 	io.WriteString(resp, rsp)
 }
 func init() {
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/proc/{owner_id}/{app_id}", Params: nil, Handler: proc.CreateProcess, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/proc/{owner_id}/{app_id}/{proc_id}", Params: nil, Handler: proc.DeleteProcess, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/proc/{owner_id}/{app_id}/{proc_id}", Params: nil, Handler: proc.UpdateProcess, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/proc/search/{owner_id}/{app_id}", Params: nil, Handler: proc.SearchProcess, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/proc/{owner_id}/{app_id}/{proc_id}", Params: nil, Handler: proc.GetProcess, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/proc/module/{owner_id}/{app_id}/{proc_id}/{module_name}", Params: nil, Handler: proc.BindProcModule, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/proc/module/{owner_id}/{app_id}/{proc_id}", Params: nil, Handler: proc.GetProcBindModule, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/proc/module/{owner_id}/{app_id}/{proc_id}/{module_name}", Params: nil, Handler: proc.DeleteProcBindModule, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/proc/{owner_id}/{app_id}", Params: nil, Handler: proc.CreateProcess, Version: v3.APIVersion, Doc: "create the process", Request: v3.MapData{}, Response: ProcessResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/proc/{owner_id}/{app_id}/{proc_id}", Params: nil, Handler: proc.DeleteProcess, Version: v3.APIVersion, Doc: "delete the process"})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/proc/{owner_id}/{app_id}/{proc_id}", Params: nil, Handler: proc.UpdateProcess, Version: v3.APIVersion, Doc: "update the process", Request: v3.MapData{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/proc/search/{owner_id}/{app_id}", Params: nil, Handler: proc.SearchProcess, Version: v3.APIVersion, Doc: "search the processes of the business", Request: params.SearchParams{}, Response: v3.SearchResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/proc/{owner_id}/{app_id}/{proc_id}", Params: nil, Handler: proc.GetProcess, Version: v3.APIVersion, Doc: "get the process detail", Response: []v3.PropertyValue{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/proc/module/{owner_id}/{app_id}/{proc_id}/{module_name}", Params: nil, Handler: proc.BindProcModule, Version: v3.APIVersion, Doc: "bind the process to the module"})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/proc/module/{owner_id}/{app_id}/{proc_id}", Params: nil, Handler: proc.GetProcBindModule, Version: v3.APIVersion, Doc: "search the modules bound to the process", Response: []ProcessModule{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/proc/module/{owner_id}/{app_id}/{proc_id}/{module_name}", Params: nil, Handler: proc.DeleteProcBindModule, Version: v3.APIVersion, Doc: "unbind the process from the module"})
 	// set cc api interface
 	proc.CreateAction()
 }
@@ -19,6 +19,7 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/actions"
 	httpcli "configcenter/src/common/http/httpclient"
+	params "configcenter/src/common/paraparse"
 	"io"
 
 	"github.com/emicklei/go-restful"
@@ -95,11 +96,11 @@ func (cli *appAction) GetInternalTopo(req *restful.Request, resp *restful.Respon
 
 func init() {
 
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/biz/{owner_id}", Params: nil, Handler: app.CreateApp, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/biz/{owner_id}/{app_id}", Params: nil, Handler: app.DeleteApp, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/biz/{owner_id}/{app_id}", Params: nil, Handler: app.UpdateApp, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/biz/search/{owner_id}", Params: nil, Handler: app.SearchApp, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/topo/internal/{owner_id}/{app_id}", Params: nil, Handler: app.GetInternalTopo, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/biz/{owner_id}", Params: nil, Handler: app.CreateApp, Version: v3.APIVersion, Doc: "create the business", Request: v3.MapData{}, Response: AppResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/biz/{owner_id}/{app_id}", Params: nil, Handler: app.DeleteApp, Version: v3.APIVersion, Doc: "delete the business"})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/biz/{owner_id}/{app_id}", Params: nil, Handler: app.UpdateApp, Version: v3.APIVersion, Doc: "update the business", Request: v3.MapData{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/biz/search/{owner_id}", Params: nil, Handler: app.SearchApp, Version: v3.APIVersion, Doc: "search the businesses", Request: params.SearchParams{}, Response: v3.SearchResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/topo/internal/{owner_id}/{app_id}", Params: nil, Handler: app.GetInternalTopo, Version: v3.APIVersion, Doc: "search the idle and fault modules of the business", Response: InternalTopo{}})
 	// set cc api interface
 	app.CreateAction()
 }
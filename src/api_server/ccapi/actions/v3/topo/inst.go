@@ -19,6 +19,7 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/actions"
 	"configcenter/src/common/http/httpclient"
+	params "configcenter/src/common/paraparse"
 	"configcenter/src/scene_server/api"
 
 	"github.com/emicklei/go-restful"
@@ -34,12 +35,12 @@ type instAction struct {
 func init() {
 
 	// register actions
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/inst/{owner_id}/{obj_id}", Params: nil, Handler: inst.CreateInst, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/inst/{owner_id}/{obj_id}/{inst_id}", Params: nil, Handler: inst.DeleteInst, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/inst/{owner_id}/{obj_id}/{inst_id}", Params: nil, Handler: inst.UpdateInst, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/inst/search/{owner_id}/{obj_id}", Params: nil, Handler: inst.SelectInsts, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/inst/search/{owner_id}/{obj_id}/{inst_id}", Params: nil, Handler: inst.SelectInst, FilterHandler: nil, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/inst/search/topo/owner/{owner_id}/object/{object_id}/inst/{inst_id}", Params: nil, Handler: inst.SelectTopo, FilterHandler: nil, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/inst/{owner_id}/{obj_id}", Params: nil, Handler: inst.CreateInst, FilterHandler: nil, Version: v3.APIVersion, Doc: "create the instance", Request: v3.MapData{}, Response: v3.MapData{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/inst/{owner_id}/{obj_id}/{inst_id}", Params: nil, Handler: inst.DeleteInst, FilterHandler: nil, Version: v3.APIVersion, Doc: "delete the instance"})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/inst/{owner_id}/{obj_id}/{inst_id}", Params: nil, Handler: inst.UpdateInst, FilterHandler: nil, Version: v3.APIVersion, Doc: "update the instance", Request: v3.MapData{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/inst/search/{owner_id}/{obj_id}", Params: nil, Handler: inst.SelectInsts, FilterHandler: nil, Version: v3.APIVersion, Doc: "search the instances of the object", Request: params.SearchParams{}, Response: v3.SearchResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/inst/search/{owner_id}/{obj_id}/{inst_id}", Params: nil, Handler: inst.SelectInst, FilterHandler: nil, Version: v3.APIVersion, Doc: "search the instance", Request: params.SearchParams{}, Response: v3.SearchResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/inst/search/topo/owner/{owner_id}/object/{object_id}/inst/{inst_id}", Params: nil, Handler: inst.SelectTopo, FilterHandler: nil, Version: v3.APIVersion, Doc: "search the association topo of the instance", Request: params.SearchParams{}, Response: []InstAsstTopo{}})
 
 	// set cc api interface
 	inst.CreateAction()
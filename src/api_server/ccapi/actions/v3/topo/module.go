@@ -19,6 +19,7 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/actions"
 	"configcenter/src/common/http/httpclient"
+	params "configcenter/src/common/paraparse"
 	"configcenter/src/scene_server/api"
 
 	"github.com/emicklei/go-restful"
@@ -33,10 +34,10 @@ type moduleAction struct {
 func init() {
 
 	// register actions
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/module/{app_id}/{set_id}", Params: nil, Handler: module.CreateModule, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/module/{app_id}/{set_id}/{module_id}", Params: nil, Handler: module.DeleteModule, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/module/{app_id}/{set_id}/{module_id}", Params: nil, Handler: module.UpdateModule, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/module/search/{owner_id}/{app_id}/{set_id}", Params: nil, Handler: module.SelectModule, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/module/{app_id}/{set_id}", Params: nil, Handler: module.CreateModule, Version: v3.APIVersion, Doc: "create the module", Request: v3.MapData{}, Response: v3.MapData{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/module/{app_id}/{set_id}/{module_id}", Params: nil, Handler: module.DeleteModule, Version: v3.APIVersion, Doc: "delete the module"})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/module/{app_id}/{set_id}/{module_id}", Params: nil, Handler: module.UpdateModule, Version: v3.APIVersion, Doc: "update the module", Request: v3.MapData{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/module/search/{owner_id}/{app_id}/{set_id}", Params: nil, Handler: module.SelectModule, Version: v3.APIVersion, Doc: "search the modules of the set", Request: params.SearchParams{}, Response: v3.SearchResult{}})
 
 	// set cc api interface
 	module.CreateAction()
@@ -20,6 +20,7 @@ import (
 	"configcenter/src/common/core/cc/actions"
 	"configcenter/src/common/http/httpclient"
 	"configcenter/src/scene_server/api"
+	"configcenter/src/source_controller/api/metadata"
 
 	"github.com/emicklei/go-restful"
 )
@@ -33,13 +34,13 @@ type objectAction struct {
 func init() {
 
 	// register actions
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/object/batch", Params: nil, Handler: obj.CreateObjectBatch, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/object/search/batch", Params: nil, Handler: obj.SelectObjectBatch, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/object", Params: nil, Handler: obj.CreateObject, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/object/{id}", Params: nil, Handler: obj.DeleteObject, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/object/{id}", Params: nil, Handler: obj.UpdateObject, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/objects", Params: nil, Handler: obj.SelectObjectWithParams, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/objects/topo", Params: nil, Handler: obj.SelectObjectTopo, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/object/batch", Params: nil, Handler: obj.CreateObjectBatch, Version: v3.APIVersion, Doc: "import the objects and their properties", Request: v3.MapData{}, Response: v3.MapData{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/object/search/batch", Params: nil, Handler: obj.SelectObjectBatch, Version: v3.APIVersion, Doc: "export the objects and their properties", Request: ObjectBatchSearch{}, Response: v3.MapData{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/object", Params: nil, Handler: obj.CreateObject, Version: v3.APIVersion, Doc: "create the object", Request: api.ObjectDes{}, Response: v3.CreateResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/object/{id}", Params: nil, Handler: obj.DeleteObject, Version: v3.APIVersion, Doc: "delete the object"})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/object/{id}", Params: nil, Handler: obj.UpdateObject, Version: v3.APIVersion, Doc: "update the object", Request: v3.MapData{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/objects", Params: nil, Handler: obj.SelectObjectWithParams, Version: v3.APIVersion, Doc: "search the objects", Request: v3.MapData{}, Response: []metadata.ObjectDes{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/objects/topo", Params: nil, Handler: obj.SelectObjectTopo, Version: v3.APIVersion, Doc: "search the object topo", Request: v3.MapData{}, Response: []ObjectTopo{}})
 
 	// init
 	obj.CreateAction()
@@ -20,6 +20,7 @@ import (
 	"configcenter/src/common/core/cc/actions"
 	"configcenter/src/common/http/httpclient"
 	"configcenter/src/scene_server/api"
+	"configcenter/src/source_controller/api/object"
 
 	"github.com/emicklei/go-restful"
 )
@@ -33,10 +34,10 @@ type objectAttAction struct {
 func init() {
 
 	// register actions
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/object/attr", Params: nil, Handler: objatt.CreateObjectAtt, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/object/attr/{attr_id}", Params: nil, Handler: objatt.DeleteObjectAtt, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/object/attr/{attr_id}", Params: nil, Handler: objatt.UpdateObjectAtt, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/object/attr/search", Params: nil, Handler: objatt.SelectObjectAttWithParams, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/object/attr", Params: nil, Handler: objatt.CreateObjectAtt, Version: v3.APIVersion, Doc: "create the object property", Request: object.ObjAttDes{}, Response: v3.CreateResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/object/attr/{attr_id}", Params: nil, Handler: objatt.DeleteObjectAtt, Version: v3.APIVersion, Doc: "delete the object property"})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/object/attr/{attr_id}", Params: nil, Handler: objatt.UpdateObjectAtt, Version: v3.APIVersion, Doc: "update the object property", Request: v3.MapData{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/object/attr/search", Params: nil, Handler: objatt.SelectObjectAttWithParams, Version: v3.APIVersion, Doc: "search the object properties", Request: v3.MapData{}, Response: []object.ObjAttDes{}})
 
 	// init
 	objatt.CreateAction()
@@ -20,6 +20,7 @@ import (
 	"configcenter/src/common/core/cc/actions"
 	"configcenter/src/common/http/httpclient"
 	"configcenter/src/scene_server/api"
+	"configcenter/src/source_controller/api/metadata"
 
 	restful "github.com/emicklei/go-restful"
 )
@@ -33,12 +34,12 @@ type objectAttGroupAction struct {
 func init() {
 
 	// register actions
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/objectatt/group/new", Params: nil, Handler: objattgroup.CreatePropertyGroup, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/objectatt/group/update", Params: nil, Handler: objattgroup.UpdatePropertyGroup, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/objectatt/group/groupid/{id}", Params: nil, Handler: objattgroup.DeletePropertyGroup, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/objectatt/group/property", Params: nil, Handler: objattgroup.UpdatePropertyGroupObjectAtt, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/objectatt/group/owner/{owner_id}/object/{object_id}/propertyids/{property_id}/groupids/{group_id}", Params: nil, Handler: objattgroup.DeletePropertyGroupObjectAtt, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/objectatt/group/property/owner/{owner_id}/object/{object_id}", Params: nil, Handler: objattgroup.SelectPropertyGroupByObjectID, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/objectatt/group/new", Params: nil, Handler: objattgroup.CreatePropertyGroup, Version: v3.APIVersion, Doc: "create the property group", Request: metadata.PropertyGroup{}, Response: v3.CreateResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/objectatt/group/update", Params: nil, Handler: objattgroup.UpdatePropertyGroup, Version: v3.APIVersion, Doc: "update the property group", Request: PropertyGroupParams{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/objectatt/group/groupid/{id}", Params: nil, Handler: objattgroup.DeletePropertyGroup, Version: v3.APIVersion, Doc: "delete the property group"})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/objectatt/group/property", Params: nil, Handler: objattgroup.UpdatePropertyGroupObjectAtt, Version: v3.APIVersion, Doc: "move the object properties to the property group", Request: PropertyGroupObjectAtt{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/objectatt/group/owner/{owner_id}/object/{object_id}/propertyids/{property_id}/groupids/{group_id}", Params: nil, Handler: objattgroup.DeletePropertyGroupObjectAtt, Version: v3.APIVersion, Doc: "remove the object property from the property group"})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/objectatt/group/property/owner/{owner_id}/object/{object_id}", Params: nil, Handler: objattgroup.SelectPropertyGroupByObjectID, Version: v3.APIVersion, Doc: "search the property groups of the object", Request: v3.MapData{}, Response: []metadata.PropertyGroup{}})
 
 	// init
 	objattgroup.CreateAction()
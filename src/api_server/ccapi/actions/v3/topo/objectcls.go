@@ -20,6 +20,7 @@ import (
 	"configcenter/src/common/core/cc/actions"
 	"configcenter/src/common/http/httpclient"
 	"configcenter/src/scene_server/api"
+	"configcenter/src/source_controller/api/metadata"
 
 	"github.com/emicklei/go-restful"
 )
@@ -33,11 +34,11 @@ type objClsAction struct {
 func init() {
 
 	// register actions
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/object/classification", Params: nil, Handler: objcls.CreateClassification, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/object/classification/{id}", Params: nil, Handler: objcls.DeleteClassification, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/object/classification/{id}", Params: nil, Handler: objcls.UpdateClassification, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/object/classifications", Params: nil, Handler: objcls.SelectClassification, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/object/classification/{owner_id}/objects", Params: nil, Handler: objcls.SelectClassificationWithObjects, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/object/classification", Params: nil, Handler: objcls.CreateClassification, Version: v3.APIVersion, Doc: "create the object classification", Request: metadata.ObjClassification{}, Response: v3.CreateResult{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/object/classification/{id}", Params: nil, Handler: objcls.DeleteClassification, Version: v3.APIVersion, Doc: "delete the object classification"})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/object/classification/{id}", Params: nil, Handler: objcls.UpdateClassification, Version: v3.APIVersion, Doc: "update the object classification", Request: v3.MapData{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/object/classifications", Params: nil, Handler: objcls.SelectClassification, Version: v3.APIVersion, Doc: "search the object classifications", Request: v3.MapData{}, Response: []metadata.ObjClassification{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/object/classification/{owner_id}/objects", Params: nil, Handler: objcls.SelectClassificationWithObjects, Version: v3.APIVersion, Doc: "search the object classifications with their objects", Request: v3.MapData{}, Response: []metadata.ObjClassificationObject{}})
 
 	// init
 	objcls.CreateAction()
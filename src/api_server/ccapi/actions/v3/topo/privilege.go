@@ -19,6 +19,7 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/actions"
 	httpcli "configcenter/src/common/http/httpclient"
+	params "configcenter/src/common/paraparse"
 	"io"
 
 	"github.com/emicklei/go-restful"
@@ -130,17 +131,17 @@ func (cli *priAction) GetUserPri(req *restful.Request, resp *restful.Response) {
 }
 
 func init() {
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/topo/privilege/{owner_id}/{obj_id}/{property_id}", Params: nil, Handler: pri.CreateRolePri, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/topo/privilege/{owner_id}/{obj_id}/{property_id}", Params: nil, Handler: pri.GetRolePri, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/topo/privilege/{owner_id}/{obj_id}/{property_id}", Params: nil, Handler: pri.CreateRolePri, Version: v3.APIVersion, Doc: "create the role privilege of the object property", Request: []string{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/topo/privilege/{owner_id}/{obj_id}/{property_id}", Params: nil, Handler: pri.GetRolePri, Version: v3.APIVersion, Doc: "search the role privilege of the object property", Response: []string{}})
 	//user group action
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/topo/privilege/group/{owner_id}", Params: nil, Handler: pri.CreateUserGroup, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/topo/privilege/group/{owner_id}/{group_id}", Params: nil, Handler: pri.UpdateUserGroup, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/topo/privilege/group/{owner_id}/search", Params: nil, Handler: pri.SearchUserGroup, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/topo/privilege/group/{owner_id}/{group_id}", Params: nil, Handler: pri.DeleteUserGroup, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/topo/privilege/group/{owner_id}", Params: nil, Handler: pri.CreateUserGroup, Version: v3.APIVersion, Doc: "create the user group", Request: UserGroup{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/topo/privilege/group/{owner_id}/{group_id}", Params: nil, Handler: pri.UpdateUserGroup, Version: v3.APIVersion, Doc: "update the user group", Request: UserGroup{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/topo/privilege/group/{owner_id}/search", Params: nil, Handler: pri.SearchUserGroup, Version: v3.APIVersion, Doc: "search the user groups", Request: v3.MapData{}, Response: []UserGroup{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/topo/privilege/group/{owner_id}/{group_id}", Params: nil, Handler: pri.DeleteUserGroup, Version: v3.APIVersion, Doc: "delete the user group"})
 	//user group privilege
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/topo/privilege/group/detail/{owner_id}/{group_id}", Params: nil, Handler: pri.GetUserGroupPri, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/topo/privilege/group/detail/{owner_id}/{group_id}", Params: nil, Handler: pri.UpdateUserGroupPri, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/topo/privilege/user/detail/{owner_id}/{user_name}", Params: nil, Handler: pri.GetUserPri, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/topo/privilege/group/detail/{owner_id}/{group_id}", Params: nil, Handler: pri.GetUserGroupPri, Version: v3.APIVersion, Doc: "search the privilege of the user group", Response: params.GroupPrivilege{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/topo/privilege/group/detail/{owner_id}/{group_id}", Params: nil, Handler: pri.UpdateUserGroupPri, Version: v3.APIVersion, Doc: "update the privilege of the user group", Request: params.Privilege{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/topo/privilege/user/detail/{owner_id}/{user_name}", Params: nil, Handler: pri.GetUserPri, Version: v3.APIVersion, Doc: "search the privilege of the user", Response: params.Gprivilege{}})
 	// set cc api interface
 	pri.CreateAction()
 }
@@ -19,6 +19,7 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/actions"
 	"configcenter/src/common/http/httpclient"
+	params "configcenter/src/common/paraparse"
 	"configcenter/src/scene_server/api"
 
 	"github.com/emicklei/go-restful"
@@ -33,10 +34,10 @@ type setAction struct {
 func init() {
 
 	// register actions
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/set/{app_id}", Params: nil, Handler: set.CreateSet, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/set/{app_id}/{set_id}", Params: nil, Handler: set.DeleteSet, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/set/{app_id}/{set_id}", Params: nil, Handler: set.UpdateSet, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/set/search/{owner_id}/{app_id}", Params: nil, Handler: set.SelectSet, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/set/{app_id}", Params: nil, Handler: set.CreateSet, Version: v3.APIVersion, Doc: "create the set", Request: v3.MapData{}, Response: v3.MapData{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/set/{app_id}/{set_id}", Params: nil, Handler: set.DeleteSet, Version: v3.APIVersion, Doc: "delete the set"})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/set/{app_id}/{set_id}", Params: nil, Handler: set.UpdateSet, Version: v3.APIVersion, Doc: "update the set", Request: v3.MapData{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/set/search/{owner_id}/{app_id}", Params: nil, Handler: set.SelectSet, Version: v3.APIVersion, Doc: "search the sets of the business", Request: params.SearchParams{}, Response: v3.SearchResult{}})
 
 	// init
 	set.CreateAction()
@@ -33,12 +33,14 @@ type topoAction struct {
 func init() {
 
 	// register actions
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/topo/model/mainline", Params: nil, Handler: topo.CreateTopoModel, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/topo/model/mainline/owners/{owner_id}/objectids/{obj_id}", Params: nil, Handler: topo.DeleteTopoModel, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/topo/model/{owner_id}", Params: nil, Handler: topo.SelectTopoModel, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/topo/model/{owner_id}/{cls_id}/{obj_id}", Params: nil, Handler: topo.SelectTopoModelByClsID, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/topo/inst/{owner_id}/{app_id}", Params: nil, Handler: topo.SelectTopoInst, Version: v3.APIVersion})
-	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/topo/inst/child/{owner_id}/{obj_id}/{app_id}/{inst_id}", Params: nil, Handler: topo.SelectTopoInstChild, Version: v3.APIVersion})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/topo/model/mainline", Params: nil, Handler: topo.CreateTopoModel, Version: v3.APIVersion, Doc: "create the mainline topo model", Request: MainlineParams{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/topo/model/mainline/owners/{owner_id}/objectids/{obj_id}", Params: nil, Handler: topo.DeleteTopoModel, Version: v3.APIVersion, Doc: "delete the mainline topo model"})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/topo/model/{owner_id}", Params: nil, Handler: topo.SelectTopoModel, Version: v3.APIVersion, Doc: "search the mainline topo model", Response: []TopoModel{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/topo/model/{owner_id}/{cls_id}/{obj_id}", Params: nil, Handler: topo.SelectTopoModelByClsID, Version: v3.APIVersion, Doc: "search the topo model of the classification", Response: []TopoModel{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/topo/inst/{owner_id}/{app_id}", Params: nil, Handler: topo.SelectTopoInst, Version: v3.APIVersion, Doc: "search the mainline topo instances of the business", Response: []TopoInst{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/topo/inst/child/{owner_id}/{obj_id}/{app_id}/{inst_id}", Params: nil, Handler: topo.SelectTopoInstChild, Version: v3.APIVersion, Doc: "search the child topo instances of the instance", Response: []TopoInst{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/topo/bundle/{owner_id}/{app_id}", Params: nil, Handler: topo.ExportTopoBundle, Version: v3.APIVersion, Doc: "export the business with its topo, processes and custom attributes", Response: v3.MapData{}})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/topo/bundle/{owner_id}/import", Params: nil, Handler: topo.ImportTopoBundle, Version: v3.APIVersion, Doc: "import the business bundle into the owner", Request: BundleImportParams{}, Response: BundleImportResult{}})
 
 	// set cc api interface
 	topo.CreateAction()
@@ -143,3 +145,34 @@ func (cli *topoAction) SelectTopoInstChild(req *restful.Request, resp *restful.R
 		resp)
 
 }
+
+// ExportTopoBundle export the business as a bundle
+func (cli *topoAction) ExportTopoBundle(req *restful.Request, resp *restful.Response) {
+
+	blog.Info("export topo bundle")
+
+	ownerID := req.PathParameter("owner_id")
+	appID := req.PathParameter("app_id")
+
+	senceCLI := api.NewClient(topo.CC.TopoAPI())
+	cli.CallResponse(
+		senceCLI.ReForwardExportTopoBundle(func(url, method string) (string, error) {
+			return httpclient.ReqForward(req, url, method)
+		}, ownerID, appID),
+		resp)
+}
+
+// ImportTopoBundle import the business bundle
+func (cli *topoAction) ImportTopoBundle(req *restful.Request, resp *restful.Response) {
+
+	blog.Info("import topo bundle")
+
+	ownerID := req.PathParameter("owner_id")
+
+	senceCLI := api.NewClient(topo.CC.TopoAPI())
+	cli.CallResponse(
+		senceCLI.ReForwardImportTopoBundle(func(url, method string) (string, error) {
+			return httpclient.ReqForward(req, url, method)
+		}, ownerID),
+		resp)
+}
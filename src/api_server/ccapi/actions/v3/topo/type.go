@@ -0,0 +1,159 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package topo
+
+import (
+	"configcenter/src/api_server/ccapi/actions/v3"
+	"configcenter/src/source_controller/api/metadata"
+)
+
+// MainlineParams the object inserted into the mainline topo under the association object
+type MainlineParams struct {
+	metadata.ObjectDes `json:",inline"`
+	AssociationID      string `json:"bk_asst_obj_id"`
+}
+
+// TopoModel the object of the mainline topo model
+type TopoModel struct {
+	ObjID      string `json:"bk_obj_id"`
+	ObjName    string `json:"bk_obj_name"`
+	OwnerID    string `json:"bk_supplier_account"`
+	NextObj    string `json:"bk_next_obj"`
+	NextName   string `json:"bk_next_name"`
+	PreObjID   string `json:"bk_pre_obj_id"`
+	PreObjName string `json:"bk_pre_obj_name"`
+}
+
+// TopoInst the instance of the mainline topo with its children
+type TopoInst struct {
+	InstID   int        `json:"bk_inst_id"`
+	InstName string     `json:"bk_inst_name"`
+	ObjID    string     `json:"bk_obj_id"`
+	ObjName  string     `json:"bk_obj_name"`
+	Default  int        `json:"default"`
+	Child    []TopoInst `json:"child"`
+}
+
+// BundleImportParams the bundle exported from the business and how to import it,
+// the policy of the conflict is skip, overwrite or fail
+type BundleImportParams struct {
+	Bundle v3.MapData `json:"bundle"`
+	AppID  int        `json:"bk_biz_id"`
+	Policy string     `json:"policy"`
+	DryRun bool       `json:"dry_run"`
+}
+
+// BundleChange one change of the bundle import
+type BundleChange struct {
+	Kind   string   `json:"kind"`
+	ObjID  string   `json:"bk_obj_id"`
+	Path   string   `json:"path"`
+	Action string   `json:"action"`
+	Fields []string `json:"fields,omitempty"`
+	SrcID  int      `json:"src_id,omitempty"`
+	DstID  int      `json:"dst_id,omitempty"`
+}
+
+// BundleImportResult the changes of the bundle import, the id map is keyed by the object id then the source id
+type BundleImportResult struct {
+	DryRun    bool                   `json:"dry_run"`
+	AppID     int                    `json:"bk_biz_id"`
+	Conflicts int                    `json:"conflicts"`
+	Changes   []BundleChange         `json:"changes"`
+	IDMap     map[string]map[int]int `json:"id_map"`
+}
+
+// AppResult the id of the created business
+type AppResult struct {
+	AppID int `json:"bk_biz_id"`
+}
+
+// InternalModule the idle or fault module of the business
+type InternalModule struct {
+	ModuleID   int    `json:"bk_module_id"`
+	ModuleName string `json:"bk_module_name"`
+}
+
+// InternalTopo the set of the idle and fault modules of the business
+type InternalTopo struct {
+	SetID   int              `json:"bk_set_id"`
+	SetName string           `json:"bk_set_name"`
+	Module  []InternalModule `json:"module"`
+}
+
+// PropertyGroupParams update the property groups matched by the condition with the data
+type PropertyGroupParams struct {
+	Condition v3.MapData `json:"condition"`
+	Data      v3.MapData `json:"data"`
+}
+
+// PropertyGroupObjectAtt move the object property to the property group
+type PropertyGroupObjectAtt struct {
+	Condition struct {
+		OwnerID    string `json:"bk_supplier_account"`
+		ObjectID   string `json:"bk_obj_id"`
+		PropertyID string `json:"bk_property_id"`
+	} `json:"condition"`
+	Data struct {
+		PropertyGroupID string `json:"bk_property_group_id"`
+		PropertyIndex   int    `json:"bk_property_index"`
+	} `json:"data"`
+}
+
+// UserGroup the user group of the privilege, the user list is separated by the semicolon
+type UserGroup struct {
+	GroupID   string `json:"group_id,omitempty"`
+	GroupName string `json:"group_name"`
+	UserList  string `json:"user_list"`
+}
+
+// ObjectBatchSearch the objects to export with their properties
+type ObjectBatchSearch struct {
+	Condition []string `json:"condition"`
+}
+
+// TopoItem the end of the object association
+type TopoItem struct {
+	ClassificationID string `json:"bk_classification_id"`
+	Position         string `json:"position"`
+	ObjID            string `json:"bk_obj_id"`
+	OwnerID          string `json:"bk_supplier_account"`
+	ObjName          string `json:"bk_obj_name"`
+}
+
+// ObjectTopo the association between two objects
+type ObjectTopo struct {
+	LabelType string   `json:"label_type"`
+	LabelName string   `json:"label_name"`
+	Label     string   `json:"label"`
+	From      TopoItem `json:"from"`
+	To        TopoItem `json:"to"`
+	Arrows    string   `json:"arrows"`
+}
+
+// InstAsst the instance associated with the instance
+type InstAsst struct {
+	ID         string `json:"id"`
+	ObjID      string `json:"bk_obj_id"`
+	ObjIcon    string `json:"bk_obj_icon"`
+	InstID     int    `json:"bk_inst_id"`
+	ObjectName string `json:"bk_obj_name"`
+	InstName   string `json:"bk_inst_name"`
+}
+
+// InstAsstTopo the instances of one object associated with the instance
+type InstAsstTopo struct {
+	InstAsst
+	Count    int        `json:"count"`
+	Children []InstAsst `json:"children"`
+}
@@ -0,0 +1,44 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package ccapi
+
+import (
+	"configcenter/src/api_server/ccapi/actions/v3"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/core/cc/actions"
+	"encoding/json"
+	"net/http"
+)
+
+// APIRootPath the root path the api web service is registered under
+const APIRootPath = "/api"
+
+// APIDocumentPath the path of the openapi document of the v3 api
+const APIDocumentPath = APIRootPath + v3.APIVersion + "/openapi.json"
+
+// registerAPIDocument generate the openapi document of the v3 actions and serve it
+func (ccAPI *CCAPIServer) registerAPIDocument() error {
+	apiDoc := actions.GetAPIDocument("bk-cmdb api", v3.APIVersion)
+	apiDoc.AddServer(APIRootPath)
+	doc, err := json.Marshal(apiDoc)
+	if nil != err {
+		blog.Errorf("generate the api document error: %v", err)
+		return err
+	}
+
+	ccAPI.httpServ.GetWebContainer().Handle(APIDocumentPath, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(doc)
+	}))
+	return nil
+}
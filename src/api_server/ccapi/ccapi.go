@@ -14,10 +14,10 @@ package ccapi
 
 import (
 	confCenter "configcenter/src/api_server/ccapi/config"
-	"configcenter/src/api_server/ccapi/rdiscover"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/api"
 	"configcenter/src/common/core/cc/config"
+	"configcenter/src/common/discovery"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/http/httpserver"
 	"configcenter/src/common/rdapi"
@@ -29,7 +29,7 @@ import (
 type CCAPIServer struct {
 	conf     *config.CCAPIConfig
 	httpServ *httpserver.HttpServer
-	rd       *rdiscover.RegDiscover
+	rd       *discovery.RegDiscover
 	cfCenter *confCenter.ConfCenter
 }
 
@@ -49,10 +49,10 @@ func NewCCAPIServer(conf *config.CCAPIConfig) (*CCAPIServer, error) {
 	a.InitAction()
 
 	//RDiscover
-	s.rd = rdiscover.NewRegDiscover(s.conf.RegDiscover, addr, port, false)
+	s.rd = discovery.NewRegDiscover(s.conf.GetRegDiscover(), types.CC_MODULE_APISERVER, addr, port, false, discovery.NewOptions(s.conf), types.CC_MODULE_HOST, types.CC_MODULE_TOPO, types.CC_MODULE_PROC, types.CC_MODULE_EVENTSERVER)
 
 	//Configure Center
-	s.cfCenter = confCenter.NewConfCenter(s.conf.RegDiscover)
+	s.cfCenter = confCenter.NewConfCenter(s.conf.GetRegDiscover())
 
 	return s, nil
 }
@@ -66,7 +66,9 @@ func (ccAPI *CCAPIServer) Stop() error {
 func (ccAPI *CCAPIServer) Start() error {
 	chErr := make(chan error, 3)
 	//http server
-	ccAPI.initHttpServ()
+	if err := ccAPI.initHttpServ(); nil != err {
+		return err
+	}
 
 	a := api.NewAPIResource()
 
@@ -160,7 +162,12 @@ func (ccAPI *CCAPIServer) Start() error {
 
 func (ccAPI *CCAPIServer) initHttpServ() error {
 	a := api.NewAPIResource()
-	ccAPI.httpServ.RegisterWebServer("/api", rdapi.AllGlobalFilter(), a.Actions)
+	ccAPI.httpServ.RegisterWebServer(APIRootPath, rdapi.AllGlobalFilter(), a.Actions)
+	a.RegisterHealth(ccAPI.httpServ)
+	if err := ccAPI.registerAPIDocument(); nil != err {
+		return err
+	}
+	ccAPI.httpServ.RegisterWebServer("/discovery", nil, []*httpserver.Action{ccAPI.rd.SnapshotAction()})
 
 	return nil
 }
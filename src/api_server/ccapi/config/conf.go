@@ -15,6 +15,7 @@ package config
 import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/confregdiscover"
+	"configcenter/src/common/confregistry"
 	"configcenter/src/common/core/cc/api"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/types"
@@ -133,5 +134,7 @@ func (cc *ConfCenter) dealConfChangeEvent(data []byte) error {
 
 	cc.ctx = data
 
+	confregistry.UpdateData(data)
+
 	return nil
 }
@@ -0,0 +1,110 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package RegisterDiscover
+
+import (
+	"configcenter/src/common/blog"
+	"configcenter/src/common/fileregistry"
+	"context"
+	"fmt"
+	"reflect"
+)
+
+//FileRegDiscv do register and discover by the static registry file
+type FileRegDiscv struct {
+	registry *fileregistry.FileRegistry
+	cancel   context.CancelFunc
+	rootCxt  context.Context
+}
+
+//NewFileRegDiscv create a object of FileRegDiscv, the serv is the path of the registry file
+func NewFileRegDiscv(serv string) *FileRegDiscv {
+	return &FileRegDiscv{
+		registry: fileregistry.NewFileRegistry(serv, fileregistry.DefaultInterval),
+	}
+}
+
+//Start load the registry file and watch its changes
+func (fileRD *FileRegDiscv) Start() error {
+	if err := fileRD.registry.Start(); err != nil {
+		return fmt.Errorf("fail to load registry file. err:%s", err.Error())
+	}
+	fileRD.rootCxt, fileRD.cancel = context.WithCancel(context.Background())
+	return nil
+}
+
+//Stop used to stop register and discover server
+func (fileRD *FileRegDiscv) Stop() error {
+	fileRD.registry.Stop()
+	fileRD.cancel()
+	return nil
+}
+
+//Register add the service into the registry of this process, the other processes only see the services in the file
+func (fileRD *FileRegDiscv) Register(path string, data []byte) error {
+	blog.Infof("register server into the static registry. path(%s), data(%s)", path, string(data))
+	fileRD.registry.Register(path, data)
+	return nil
+}
+
+//RegisterAndWatch register the service, the registration never expires in the static registry
+func (fileRD *FileRegDiscv) RegisterAndWatch(path string, data []byte) error {
+	return fileRD.Register(path, data)
+}
+
+//Ping check whether the registry file is still readable
+func (fileRD *FileRegDiscv) Ping() error {
+	return fileRD.registry.Ping()
+}
+
+//Discover watch the servers of the path in the registry file
+func (fileRD *FileRegDiscv) Discover(path string) (<-chan *DiscoverEvent, error) {
+	blog.Infof("begin to discover servers of path(%s) in the registry file", path)
+	env := make(chan *DiscoverEvent, 1)
+	go fileRD.loopDiscover(path, env)
+	return env, nil
+}
+
+func (fileRD *FileRegDiscv) loopDiscover(path string, env chan *DiscoverEvent) {
+	changed, stop := fileRD.registry.Watch()
+	defer stop()
+
+	var last []string
+	for {
+		servers := fileRD.registry.GetServers(path)
+		if nil == last || !reflect.DeepEqual(last, servers) {
+			discvEnv := &DiscoverEvent{
+				Err:    nil,
+				Key:    path,
+				Server: servers,
+			}
+			for i := range servers {
+				discvEnv.Nodes = append(discvEnv.Nodes, fmt.Sprintf("%s%010d", "node", i))
+			}
+			last = servers
+
+			select {
+			case env <- discvEnv:
+			case <-fileRD.rootCxt.Done():
+				return
+			}
+		}
+
+		select {
+		case <-fileRD.rootCxt.Done():
+			blog.Infof("discover path(%s) done", path)
+			return
+		case <-changed:
+		}
+	}
+}
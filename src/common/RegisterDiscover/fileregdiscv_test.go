@@ -0,0 +1,61 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package RegisterDiscover
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileRegDiscv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "regdiscv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "registry.json")
+	content := `{"services": {"/cc/services/endpoints/host": [{"ip": "127.0.0.1", "port": 60001, "scheme": "http"}]}}`
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rd := NewRegDiscoverEx("file://"+path, time.Second)
+	if err := rd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer rd.Stop()
+
+	env, err := rd.DiscoverService("/cc/services/endpoints/host")
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := <-env
+	if 1 != len(event.Server) || 1 != len(event.Nodes) {
+		t.Fatalf("unexpected discover event %+v", event)
+	}
+
+	if err := rd.RegisterAndWatchService("/cc/services/endpoints/host/127.0.0.2", []byte(`{"ip":"127.0.0.2"}`)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event = <-env:
+	case <-time.After(time.Second * 5):
+		t.Fatal("the registered server is not discovered")
+	}
+	if 2 != len(event.Server) || `{"ip":"127.0.0.2"}` != event.Server[1] {
+		t.Fatalf("unexpected discover event %+v", event)
+	}
+}
@@ -24,4 +24,6 @@ type RegDiscvServer interface {
 	RegisterAndWatch(key string, data []byte) error
 	// discover server from the registe-discover service platform
 	Discover(key string) (<-chan *DiscoverEvent, error)
+	// Ping check the connection to the registe-discover service platform
+	Ping() error
 }
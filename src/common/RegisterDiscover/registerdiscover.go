@@ -13,6 +13,7 @@
 package RegisterDiscover
 
 import (
+	"configcenter/src/common/fileregistry"
 	"time"
 )
 
@@ -31,22 +32,21 @@ type RegDiscover struct {
 
 //NewRegDiscover used to create a object of RegDiscover
 func NewRegDiscover(serv string) *RegDiscover {
-	regDiscv := &RegDiscover{
-		rdServer: nil,
-	}
-
-	regDiscv.rdServer = RegDiscvServer(NewZkRegDiscv(serv, time.Second*60))
-
-	return regDiscv
+	return NewRegDiscoverEx(serv, time.Second*60)
 }
 
 //NewRegDiscoverEx used to create a object of RegDiscover
+// serv is the zookeeper hosts, or the registry file path like file:///data/cmdb/registry.yaml
 func NewRegDiscoverEx(serv string, timeOut time.Duration) *RegDiscover {
 	regDiscv := &RegDiscover{
 		rdServer: nil,
 	}
 
-	regDiscv.rdServer = RegDiscvServer(NewZkRegDiscv(serv, timeOut))
+	if fileregistry.IsFileAddr(serv) {
+		regDiscv.rdServer = RegDiscvServer(NewFileRegDiscv(serv))
+	} else {
+		regDiscv.rdServer = RegDiscvServer(NewZkRegDiscv(serv, timeOut))
+	}
 
 	return regDiscv
 }
@@ -76,6 +76,11 @@ func (rd *RegDiscover) RegisterAndWatchService(key string, data []byte) error {
 	return rd.rdServer.RegisterAndWatch(key, data)
 }
 
+//Ping check the connection to the register and discover server
+func (rd *RegDiscover) Ping() error {
+	return rd.rdServer.Ping()
+}
+
 //DiscoverService used to discover the service that registered in `key`
 func (rd *RegDiscover) DiscoverService(key string) (<-chan *DiscoverEvent, error) {
 	return rd.rdServer.Discover(key)
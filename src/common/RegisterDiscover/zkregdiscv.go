@@ -22,6 +22,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
 )
 
 //ZkRegDiscv do register and discover by zookeeper
@@ -66,6 +68,17 @@ func (zkRD *ZkRegDiscv) Stop() error {
 	return nil
 }
 
+//Ping check whether the zookeeper session is alive
+func (zkRD *ZkRegDiscv) Ping() error {
+	if nil == zkRD.zkcli.ZkConn {
+		return fmt.Errorf("zookeeper is not connected")
+	}
+	if state := zkRD.zkcli.State(); state != zk.StateHasSession {
+		return fmt.Errorf("zookeeper session is not alive, state:%s", state.String())
+	}
+	return nil
+}
+
 //Register create ephemeral node for the service
 func (zkRD *ZkRegDiscv) Register(path string, data []byte) error {
 	//blog.Info("register server. path(%s), data(%s)", path, string(data))
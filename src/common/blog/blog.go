@@ -16,6 +16,7 @@ import (
 	"encoding/json"
 	"flag"
 	"log"
+	"strconv"
 	"sync"
 	"time"
 
@@ -77,11 +78,38 @@ var (
 	V = glog.V
 )
 
+// DebugV the verbosity from which the Debug logs are written
+const DebugV = 3
+
+var (
+	startupV     string
+	startupVOnce sync.Once
+)
+
+// SetV change the verbosity of the logs, it is the same as the -v flag,
+// a negative level restores the -v flag given at startup
+func SetV(level int) {
+	startupVOnce.Do(func() {
+		if f := flag.Lookup("v"); nil != f {
+			startupV = f.Value.String()
+		}
+	})
+	if level < 0 {
+		flag.Set("v", startupV)
+		return
+	}
+	flag.Set("v", strconv.Itoa(level))
+}
+
+// Debug write the log if the verbosity is DebugV or above
 func Debug(args ...interface{}) {
+	if !glog.V(DebugV) {
+		return
+	}
 	if format, ok := (args[0]).(string); ok {
-		glog.V(0).Infof(format, args[1:]...)
+		glog.Infof(format, args[1:]...)
 	} else {
-		glog.V(0).Info(args)
+		glog.Info(args)
 	}
 }
 
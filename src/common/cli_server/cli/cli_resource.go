@@ -18,6 +18,7 @@ import (
 	"configcenter/src/common/conf"
 	storage "configcenter/src/storage"
 	dbcli "configcenter/src/storage/dbclient"
+	"configcenter/src/storage/mgoclient"
 )
 
 type cliResource struct {
@@ -60,8 +61,16 @@ func (cc *cliResource) GetDataCli(config map[string]string, dType string) error
 	pwd := config[dType+".pwd"]
 	dbName := config[dType+".database"]
 	mechanism := config[dType+".mechanism"]
+	var mgoOpts *mgoclient.Options
+	if dType == storage.DI_MONGO {
+		opts, err := mgoclient.ParseOptions(config, dType)
+		if err != nil {
+			return err
+		}
+		mgoOpts = opts
+	}
 
-	dataCli, err := dbcli.NewDB(host, port, user, pwd, mechanism, dbName, dType)
+	dataCli, err := dbcli.NewDB(host, port, user, pwd, mechanism, dbName, dType, mgoOpts)
 	if err != nil {
 		return err
 	}
@@ -13,6 +13,7 @@
 package confregdiscover
 
 import (
+	"configcenter/src/common/fileregistry"
 	"time"
 )
 
@@ -31,22 +32,21 @@ type ConfRegDiscover struct {
 // NewConfRegDiscover used to create a object of ConfRegDiscover
 // session timeout default 60 second
 func NewConfRegDiscover(serv string) *ConfRegDiscover {
-	confRD := &ConfRegDiscover{
-		confrdServer: nil,
-	}
-
-	confRD.confrdServer = ConfRegDiscvServer(NewZkRegDiscover(serv, time.Second*60))
-
-	return confRD
+	return NewConfRegDiscoverWithTimeOut(serv, time.Second*60)
 }
 
 // NewConfRegDiscoverWithTimeOut used to create a object
+// serv is the zookeeper hosts, or the registry file path like file:///data/cmdb/registry.yaml
 func NewConfRegDiscoverWithTimeOut(serv string, timeOut time.Duration) *ConfRegDiscover {
 	confRD := &ConfRegDiscover{
 		confrdServer: nil,
 	}
 
-	confRD.confrdServer = ConfRegDiscvServer(NewZkRegDiscover(serv, timeOut))
+	if fileregistry.IsFileAddr(serv) {
+		confRD.confrdServer = ConfRegDiscvServer(NewFileRegDiscover(serv))
+	} else {
+		confRD.confrdServer = ConfRegDiscvServer(NewZkRegDiscover(serv, timeOut))
+	}
 
 	return confRD
 }
@@ -66,6 +66,11 @@ func (crd *ConfRegDiscover) Write(key string, data []byte) error {
 	return crd.confrdServer.Write(key, data)
 }
 
+//Read the data, nil if not exists
+func (crd *ConfRegDiscover) Read(key string) ([]byte, error) {
+	return crd.confrdServer.Read(key)
+}
+
 //DiscoverConfig discover the config wether is changed
 func (crd *ConfRegDiscover) DiscoverConfig(key string) (<-chan *DiscoverEvent, error) {
 	return crd.confrdServer.Discover(key)
@@ -0,0 +1,95 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package confregdiscover
+
+import (
+	"bytes"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/fileregistry"
+	"context"
+	"fmt"
+)
+
+// FileRegDiscover config register and discover by the static registry file
+type FileRegDiscover struct {
+	registry *fileregistry.FileRegistry
+	cancel   context.CancelFunc
+	rootCtx  context.Context
+}
+
+// NewFileRegDiscover create a object of FileRegDiscover, the serv is the path of the registry file
+func NewFileRegDiscover(serv string) *FileRegDiscover {
+	return &FileRegDiscover{
+		registry: fileregistry.NewFileRegistry(serv, fileregistry.DefaultInterval),
+	}
+}
+
+// Start load the registry file and watch its changes
+func (fileRD *FileRegDiscover) Start() error {
+	if err := fileRD.registry.Start(); err != nil {
+		return fmt.Errorf("fail to load registry file. err:%s", err.Error())
+	}
+	fileRD.rootCtx, fileRD.cancel = context.WithCancel(context.Background())
+	return nil
+}
+
+//Stop to stop register and discover server
+func (fileRD *FileRegDiscover) Stop() error {
+	fileRD.registry.Stop()
+	fileRD.cancel()
+	return nil
+}
+
+//Write to save config data into the registry file
+func (fileRD *FileRegDiscover) Write(path string, data []byte) error {
+	return fileRD.registry.WriteConfig(path, data)
+}
+
+//Read the config data from the registry file, nil if not exists
+func (fileRD *FileRegDiscover) Read(path string) ([]byte, error) {
+	if data, ok := fileRD.registry.GetConfig(path); ok {
+		return data, nil
+	}
+	return nil, nil
+}
+
+// Discover watch the config of the key, the event is sent once the config exists or changes
+func (fileRD *FileRegDiscover) Discover(key string) (<-chan *DiscoverEvent, error) {
+	env := make(chan *DiscoverEvent, 1)
+	go fileRD.loopDiscover(key, env)
+	return env, nil
+}
+
+func (fileRD *FileRegDiscover) loopDiscover(path string, env chan *DiscoverEvent) {
+	changed, stop := fileRD.registry.Watch()
+	defer stop()
+
+	var last []byte
+	for {
+		if data, ok := fileRD.registry.GetConfig(path); ok && (nil == last || !bytes.Equal(last, data)) {
+			last = data
+			select {
+			case env <- &DiscoverEvent{Err: nil, Key: path, Data: data}:
+			case <-fileRD.rootCtx.Done():
+				return
+			}
+		}
+
+		select {
+		case <-fileRD.rootCtx.Done():
+			blog.Infof("discover path(%s) done", path)
+			return
+		case <-changed:
+		}
+	}
+}
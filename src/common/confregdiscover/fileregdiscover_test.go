@@ -0,0 +1,55 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package confregdiscover
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileRegDiscover(t *testing.T) {
+	dir, err := ioutil.TempDir("", "confregdiscover")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "registry.yaml")
+	if err := ioutil.WriteFile(path, []byte("configs: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	crd := NewConfRegDiscover("file://" + path)
+	if err := crd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer crd.Stop()
+
+	env, err := crd.DiscoverConfig("/cc/services/config/host")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crd.Write("/cc/services/config/host", []byte("[errors]\nres=conf/errors")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-env:
+		if "[errors]\nres=conf/errors" != string(event.Data) {
+			t.Fatalf("unexpected config %q", event.Data)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("the written config is not discovered")
+	}
+}
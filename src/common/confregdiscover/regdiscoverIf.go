@@ -20,6 +20,8 @@ type ConfRegDiscvServer interface {
 	Stop() error
 	// Write the config data into register-discover service
 	Write(key string, data []byte) error
+	// Read the config data, nil if the config does not exist
+	Read(key string) ([]byte, error)
 	// Discover the config change
 	Discover(key string) (<-chan *DiscoverEvent, error)
 }
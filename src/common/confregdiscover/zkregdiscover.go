@@ -63,6 +63,15 @@ func (zkRD *ZkRegDiscover) Write(path string, data []byte) error {
 	return zkRD.zkcli.Update(path, string(data))
 }
 
+//Read the config data from zookeeper, nil if the node does not exist
+func (zkRD *ZkRegDiscover) Read(path string) ([]byte, error) {
+	data, _, err := zkRD.zkcli.GetEx(path)
+	if zkclient.ErrNoNode == err {
+		return nil, nil
+	}
+	return data, err
+}
+
 func (zkRD *ZkRegDiscover) Discover(key string) (<-chan *DiscoverEvent, error) {
 
 	discvCtx, _ := context.WithCancel(zkRD.rootCtx)
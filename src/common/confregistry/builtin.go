@@ -0,0 +1,24 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package confregistry
+
+import (
+	"configcenter/src/common/blog"
+)
+
+// LogLevel the verbosity of the logs, reloaded from log.level, the debug logs are written from blog.DebugV,
+// the -v flag given at startup is restored once the config removes it
+var LogLevel = Default.Int("log.level", -1, func(level int) {
+	blog.Infof("change the log level to %d", level)
+	blog.SetV(level)
+})
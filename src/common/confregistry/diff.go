@@ -0,0 +1,74 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package confregistry
+
+import (
+	"fmt"
+	"sort"
+
+	"configcenter/src/common/conf"
+)
+
+// ChangeType how the config item changed
+type ChangeType string
+
+const (
+	Added    ChangeType = "added"
+	Removed  ChangeType = "removed"
+	Modified ChangeType = "modified"
+)
+
+// Change the change of a config item, the key is like section.name
+type Change struct {
+	Key  string
+	Type ChangeType
+	Old  string
+	New  string
+}
+
+func (c Change) String() string {
+	switch c.Type {
+	case Added:
+		return fmt.Sprintf("+ %s = %s", c.Key, c.New)
+	case Removed:
+		return fmt.Sprintf("- %s = %s", c.Key, c.Old)
+	}
+	return fmt.Sprintf("~ %s = %s -> %s", c.Key, c.Old, c.New)
+}
+
+// Parse parse the config content in the ini format into the items keyed like section.name
+func Parse(data []byte) map[string]string {
+	config := new(conf.Config)
+	config.ParseConf(data)
+	return config.Configmap
+}
+
+// Diff compare the config items, the changes are ordered by the key
+func Diff(old, new map[string]string) []Change {
+	changes := make([]Change, 0)
+	for key, value := range new {
+		oldValue, ok := old[key]
+		if !ok {
+			changes = append(changes, Change{Key: key, Type: Added, New: value})
+		} else if oldValue != value {
+			changes = append(changes, Change{Key: key, Type: Modified, Old: oldValue, New: value})
+		}
+	}
+	for key, value := range old {
+		if _, ok := new[key]; !ok {
+			changes = append(changes, Change{Key: key, Type: Removed, Old: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
@@ -0,0 +1,220 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package confregistry
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"configcenter/src/common/blog"
+)
+
+// setting a reloadable config item
+type setting interface {
+	// apply parse and store the value, the default is restored if the raw value is empty
+	apply(raw string) (changed bool, err error)
+	// notify call the callback with the current value
+	notify()
+}
+
+// Registry keep the reloadable settings which the service declared,
+// the settings are updated once the config of the service in the config center changes
+type Registry struct {
+	lock     sync.Mutex
+	settings map[string][]setting
+	last     map[string]string
+}
+
+// NewRegistry create an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		settings: make(map[string][]setting),
+		last:     make(map[string]string),
+	}
+}
+
+// Default the registry which the services update from their config center
+var Default = NewRegistry()
+
+func (r *Registry) add(key string, s setting) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.settings[key] = append(r.settings[key], s)
+	if raw, ok := r.last[key]; ok {
+		if _, err := s.apply(raw); nil != err {
+			blog.Errorf("invalid config %s=%s, keep the default. err:%s", key, raw, err.Error())
+		}
+	}
+}
+
+// Update apply the config items to the declared settings, the callbacks of the changed settings are called,
+// the invalid values are skipped with the last values kept. It returns the changes of the items
+func (r *Registry) Update(config map[string]string) []Change {
+	r.lock.Lock()
+	changes := Diff(r.last, config)
+	r.last = make(map[string]string, len(config))
+	for key, value := range config {
+		r.last[key] = value
+	}
+	changed := make([]setting, 0)
+	for _, change := range changes {
+		for _, s := range r.settings[change.Key] {
+			ok, err := s.apply(change.New)
+			if nil != err {
+				blog.Errorf("invalid config %s=%s, keep the last value. err:%s", change.Key, change.New, err.Error())
+				continue
+			}
+			if ok {
+				blog.Infof("config reloaded, %s", change.String())
+				changed = append(changed, s)
+			}
+		}
+	}
+	r.lock.Unlock()
+
+	for _, s := range changed {
+		s.notify()
+	}
+	return changes
+}
+
+// UpdateData parse the config content of the config center event and update the default registry,
+// the error event has no data and is skipped
+func UpdateData(data []byte) []Change {
+	if nil == data {
+		return nil
+	}
+	return Default.Update(Parse(data))
+}
+
+// value the typed value shared by the settings
+type value struct {
+	lock     sync.RWMutex
+	key      string
+	current  interface{}
+	def      interface{}
+	parse    func(raw string) (interface{}, error)
+	onChange func(v interface{})
+}
+
+func (v *value) apply(raw string) (bool, error) {
+	next := v.def
+	if "" != raw {
+		parsed, err := v.parse(raw)
+		if nil != err {
+			return false, err
+		}
+		next = parsed
+	}
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if next == v.current {
+		return false, nil
+	}
+	v.current = next
+	return true, nil
+}
+
+func (v *value) notify() {
+	if nil != v.onChange {
+		v.onChange(v.get())
+	}
+}
+
+func (v *value) get() interface{} {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+	return v.current
+}
+
+func (r *Registry) newValue(key string, def interface{}, parse func(string) (interface{}, error), onChange func(interface{})) *value {
+	v := &value{key: key, current: def, def: def, parse: parse, onChange: onChange}
+	r.add(key, v)
+	return v
+}
+
+// String the reloadable string setting
+type String struct{ v *value }
+
+// Get return the current value
+func (s *String) Get() string { return s.v.get().(string) }
+
+// String declare the string setting, onChange is called with the new value once it changes, it can be nil
+func (r *Registry) String(key, def string, onChange func(string)) *String {
+	return &String{v: r.newValue(key, def,
+		func(raw string) (interface{}, error) { return raw, nil },
+		func(v interface{}) {
+			if nil != onChange {
+				onChange(v.(string))
+			}
+		})}
+}
+
+// Int the reloadable integer setting
+type Int struct{ v *value }
+
+// Get return the current value
+func (i *Int) Get() int { return i.v.get().(int) }
+
+// Int declare the integer setting, onChange is called with the new value once it changes, it can be nil
+func (r *Registry) Int(key string, def int, onChange func(int)) *Int {
+	return &Int{v: r.newValue(key, def,
+		func(raw string) (interface{}, error) { return strconv.Atoi(raw) },
+		func(v interface{}) {
+			if nil != onChange {
+				onChange(v.(int))
+			}
+		})}
+}
+
+// Bool the reloadable switch setting
+type Bool struct{ v *value }
+
+// Get return the current value
+func (b *Bool) Get() bool { return b.v.get().(bool) }
+
+// Bool declare the switch setting, onChange is called with the new value once it changes, it can be nil
+func (r *Registry) Bool(key string, def bool, onChange func(bool)) *Bool {
+	return &Bool{v: r.newValue(key, def,
+		func(raw string) (interface{}, error) { return strconv.ParseBool(raw) },
+		func(v interface{}) {
+			if nil != onChange {
+				onChange(v.(bool))
+			}
+		})}
+}
+
+// Duration the reloadable duration setting, the value is like 10s or 1m30s
+type Duration struct{ v *value }
+
+// Get return the current value
+func (d *Duration) Get() time.Duration { return d.v.get().(time.Duration) }
+
+// Duration declare the duration setting, onChange is called with the new value once it changes, it can be nil
+func (r *Registry) Duration(key string, def time.Duration, onChange func(time.Duration)) *Duration {
+	return &Duration{v: r.newValue(key, def,
+		func(raw string) (interface{}, error) {
+			d, err := time.ParseDuration(raw)
+			if nil == err && d < 0 {
+				err = fmt.Errorf("negative duration %s", raw)
+			}
+			return d, err
+		},
+		func(v interface{}) {
+			if nil != onChange {
+				onChange(v.(time.Duration))
+			}
+		})}
+}
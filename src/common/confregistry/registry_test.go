@@ -0,0 +1,80 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package confregistry
+
+import (
+	"testing"
+	"time"
+
+	"configcenter/src/common/blog"
+
+	"github.com/golang/glog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	old := Parse([]byte("[log]\nlevel = 3\n[event]\ncallback_timeout = 10s\n"))
+	new := Parse([]byte("[log]\nlevel = 5 # verbose\n[session]\nname = cc3\n"))
+	assert.Equal(t, []Change{
+		{Key: "event.callback_timeout", Type: Removed, Old: "10s"},
+		{Key: "log.level", Type: Modified, Old: "3", New: "5"},
+		{Key: "session.name", Type: Added, New: "cc3"},
+	}, Diff(old, new))
+	assert.Empty(t, Diff(new, new))
+	assert.Equal(t, "~ log.level = 3 -> 5", Diff(old, new)[1].String())
+}
+
+func TestRegistryUpdate(t *testing.T) {
+	r := NewRegistry()
+	levels := make([]int, 0)
+	level := r.Int("log.level", 0, func(v int) { levels = append(levels, v) })
+	timeout := r.Duration("event.callback_timeout", 10*time.Second, nil)
+	enabled := r.Bool("feature.enabled", false, nil)
+	url := r.String("site.login", "http://login", nil)
+
+	r.Update(map[string]string{"log.level": "3", "event.callback_timeout": "5s", "feature.enabled": "true"})
+	assert.Equal(t, 3, level.Get())
+	assert.Equal(t, 5*time.Second, timeout.Get())
+	assert.True(t, enabled.Get())
+	assert.Equal(t, "http://login", url.Get())
+	assert.Equal(t, []int{3}, levels)
+
+	// the unchanged settings are not notified, the invalid value is skipped
+	r.Update(map[string]string{"log.level": "3", "event.callback_timeout": "-1s", "feature.enabled": "true"})
+	assert.Equal(t, 5*time.Second, timeout.Get())
+	assert.Equal(t, []int{3}, levels)
+
+	// the removed item restores the default
+	changes := r.Update(map[string]string{"event.callback_timeout": "5s", "feature.enabled": "true"})
+	assert.Equal(t, []Change{{Key: "event.callback_timeout", Type: Modified, Old: "-1s", New: "5s"}, {Key: "log.level", Type: Removed, Old: "3"}}, changes)
+	assert.Equal(t, 0, level.Get())
+	assert.Equal(t, 5*time.Second, timeout.Get())
+	assert.Equal(t, []int{3, 0}, levels)
+
+	// the setting declared later takes the loaded value
+	late := r.Bool("feature.enabled", false, nil)
+	assert.True(t, late.Get())
+}
+
+func TestLogLevel(t *testing.T) {
+	assert.Nil(t, UpdateData(nil))
+
+	UpdateData([]byte("[log]\nlevel = 3\n"))
+	assert.Equal(t, 3, LogLevel.Get())
+	assert.True(t, bool(glog.V(blog.DebugV)))
+
+	// removing the level restores the -v flag given at startup
+	UpdateData([]byte("[session]\nname = cc3\n"))
+	assert.Equal(t, -1, LogLevel.Get())
+	assert.False(t, bool(glog.V(blog.DebugV)))
+}
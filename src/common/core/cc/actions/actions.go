@@ -28,10 +28,14 @@ type Action struct {
 	Params        []*restful.Parameter // List of parameters associated with the action.
 	Handler       restful.RouteFunction
 	FilterHandler []restful.FilterFunction
-	Version       string //api 版本号，为空表示没有版本
+	Version       string      //api 版本号，为空表示没有版本
+	Doc           string      // summary of the action in the api document
+	Request       interface{} // sample of the request body, nil if the action takes no json body
+	Response      interface{} // sample of the data in the response, nil if not described
 }
 
 var acts = []*httpserver.Action{}
+var docs = []Action{}
 
 // RegisterNewAction registe action to actions
 func RegisterNewAction(action Action) {
@@ -46,6 +50,7 @@ func RegisterNewAction(action Action) {
 		}
 	}
 	acts = append(acts, httpserver.NewAction(action.Verb, action.Path, action.Params, action.Handler, action.FilterHandler))
+	docs = append(docs, action)
 }
 
 // GetAPIAction fetch api actions
@@ -0,0 +1,95 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package actions
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/openapi"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+)
+
+// GetAPIDocument generate the openapi document of the actions registered with the api version
+func GetAPIDocument(title, version string) *openapi.Document {
+	doc := openapi.NewDocument(title, strings.Trim(version, "/"))
+	for _, action := range docs {
+		if version != action.Version {
+			continue
+		}
+		op := &openapi.Operation{
+			Summary: action.Doc,
+			Tags:    []string{actionTag(action)},
+		}
+		for _, param := range action.Params {
+			data := param.Data()
+			in := paramLocation(data.Kind)
+			if "" == in {
+				continue
+			}
+			dataType := data.DataType
+			if "" == dataType {
+				dataType = "string"
+			}
+			op.Parameters = append(op.Parameters, &openapi.Parameter{
+				Name:        data.Name,
+				In:          in,
+				Description: data.Description,
+				Required:    data.Required || "path" == in,
+				Schema:      &openapi.Schema{Type: dataType, Format: data.DataFormat},
+			})
+		}
+		if nil != action.Request {
+			op.RequestBody = &openapi.RequestBody{Required: true, Content: openapi.JSONContent(doc.Schema(action.Request))}
+		}
+		op.Responses = map[string]*openapi.Response{
+			"200": {Description: "success", Content: openapi.JSONContent(responseSchema(doc.Schema(action.Response)))},
+		}
+		doc.AddOperation(action.Verb, action.Path, op)
+	}
+	return doc
+}
+
+// responseSchema the schema of the api response which carries the data
+func responseSchema(data *openapi.Schema) *openapi.Schema {
+	return &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"result":                     {Type: "boolean"},
+			common.HTTPBKAPIErrorCode:    {Type: "integer", Format: "int32"},
+			common.HTTPBKAPIErrorMessage: {},
+			"data":                       data,
+		},
+	}
+}
+
+// actionTag the first path segment after the version
+func actionTag(action Action) string {
+	path := strings.TrimPrefix(action.Path, "/")
+	if "" != action.Version {
+		path = strings.TrimPrefix(path, strings.Trim(action.Version, "/")+"/")
+	}
+	return strings.SplitN(path, "/", 2)[0]
+}
+
+func paramLocation(kind int) string {
+	switch kind {
+	case restful.PathParameterKind:
+		return "path"
+	case restful.QueryParameterKind:
+		return "query"
+	case restful.HeaderParameterKind:
+		return "header"
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package actions
+
+import (
+	"configcenter/src/common"
+	"testing"
+
+	restful "github.com/emicklei/go-restful"
+)
+
+type searchInput struct {
+	Condition map[string]interface{} `json:"condition"`
+}
+
+func TestGetAPIDocument(t *testing.T) {
+	RegisterNewAction(Action{Verb: common.HTTPSelectPost, Path: "/hosts/search", Version: "/v3", Doc: "search the hosts", Request: searchInput{}, Response: []string{}})
+	RegisterNewAction(Action{Verb: common.HTTPSelectPost, Path: "/audit/export", Version: "/v3",
+		Params: []*restful.Parameter{restful.QueryParameter("format", "the export format")}})
+	RegisterNewAction(Action{Verb: common.HTTPSelectGet, Path: "/hosts/{id}", Version: "/v2"})
+
+	doc := GetAPIDocument("test", "/v3")
+	if "v3" != doc.Info.Version {
+		t.Errorf("unexpected version %s", doc.Info.Version)
+	}
+	if nil != doc.Operation("GET", "/v2/hosts/{id}") {
+		t.Errorf("action of the other version should not be documented")
+	}
+
+	op := doc.Operation("POST", "/v3/hosts/search")
+	if nil == op {
+		t.Fatal("action not documented")
+	}
+	if "search the hosts" != op.Summary || 1 != len(op.Tags) || "hosts" != op.Tags[0] {
+		t.Errorf("unexpected summary or tags: %s %v", op.Summary, op.Tags)
+	}
+	if nil == op.RequestBody || "#/components/schemas/actions.searchInput" != op.RequestBody.Content["application/json"].Schema.Ref {
+		t.Errorf("request body not documented: %#v", op.RequestBody)
+	}
+	rsp := op.Responses["200"].Content["application/json"].Schema
+	if "array" != rsp.Properties["data"].Type || nil == rsp.Properties[common.HTTPBKAPIErrorCode] {
+		t.Errorf("unexpected response schema: %#v", rsp)
+	}
+
+	export := doc.Operation("POST", "/v3/audit/export")
+	if nil == export || 1 != len(export.Parameters) || "query" != export.Parameters[0].In || "format" != export.Parameters[0].Name {
+		t.Errorf("query parameter not documented: %#v", export)
+	}
+	if nil != export.RequestBody {
+		t.Errorf("action without request sample should not have a request body")
+	}
+}
@@ -26,8 +26,10 @@ import (
 	_ "configcenter/src/common/ssl"
 	"configcenter/src/storage"
 	"configcenter/src/storage/dbclient"
+	"configcenter/src/storage/mgoclient"
 	"crypto/tls"
 	"encoding/json"
+	"sync"
 
 	restful "github.com/emicklei/go-restful"
 )
@@ -66,6 +68,7 @@ type APIResource struct {
 	MetaCli      storage.DI
 	InstCli      storage.DI
 	CacheCli     storage.DI
+	Cache        storage.Cache
 	Error        errors.CCErrorIf
 	HostCtrl     func() string
 	ObjCtrl      func() string
@@ -78,6 +81,9 @@ type APIResource struct {
 	EventAPI     func() string
 	APIAddr      func() string
 	AddrSrv      AddrSrv
+
+	healthLock   sync.RWMutex
+	healthChecks []namedHealthCheck
 }
 
 // AddrSrv get server address interface
@@ -163,7 +169,15 @@ func (a *APIResource) GetDataCli(config map[string]string, dType string) error {
 	pwd := config[dType+".pwd"]
 	dbName := config[dType+".database"]
 	mechanism := config[dType+".mechanism"]
-	dataCli, err := dbclient.NewDB(host, port, user, pwd, mechanism, dbName, dType)
+	var mgoOpts *mgoclient.Options
+	if dType == storage.DI_MONGO {
+		opts, err := mgoclient.ParseOptions(config, dType)
+		if err != nil {
+			return err
+		}
+		mgoOpts = opts
+	}
+	dataCli, err := dbclient.NewDB(host, port, user, pwd, mechanism, dbName, dType, mgoOpts)
 	if err != nil {
 		return err
 	}
@@ -173,8 +187,9 @@ func (a *APIResource) GetDataCli(config map[string]string, dType string) error {
 	}
 	if dType == storage.DI_MYSQL {
 		a.MetaCli = dataCli
-	} else if dType == storage.DI_REDIS {
+	} else if dType == storage.DI_REDIS || dType == storage.DI_MEMORY_REDIS {
 		a.CacheCli = dataCli
+		a.Cache, _ = dataCli.(storage.Cache)
 	} else {
 		a.InstCli = dataCli
 	}
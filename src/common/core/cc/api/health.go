@@ -0,0 +1,143 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package api
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/httpserver"
+	"configcenter/src/storage"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// HealthzPath the path of the liveness endpoint
+	HealthzPath = "/healthz"
+	// ReadyzPath the path of the readiness endpoint
+	ReadyzPath = "/readyz"
+)
+
+// HealthCheckTimeout the time limit of each dependency check
+var HealthCheckTimeout = 3 * time.Second
+
+// HealthCheck check a dependency, return the error if it is unavailable
+type HealthCheck func() error
+
+// HealthItem the check result of a dependency
+type HealthItem struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Message string `json:"message,omitempty"`
+}
+
+// HealthStatus the check result of all the dependencies
+type HealthStatus struct {
+	Healthy bool         `json:"healthy"`
+	Items   []HealthItem `json:"items"`
+}
+
+type namedHealthCheck struct {
+	name  string
+	check HealthCheck
+}
+
+// AddHealthCheck add the dependency check, the check with the same name is replaced
+func (a *APIResource) AddHealthCheck(name string, check HealthCheck) {
+	a.healthLock.Lock()
+	defer a.healthLock.Unlock()
+	for i := range a.healthChecks {
+		if a.healthChecks[i].name == name {
+			a.healthChecks[i].check = check
+			return
+		}
+	}
+	a.healthChecks = append(a.healthChecks, namedHealthCheck{name: name, check: check})
+}
+
+// CheckHealth probe the storages and the added dependencies,
+// the storages which are not used by the server are skipped
+func (a *APIResource) CheckHealth() HealthStatus {
+	checks := make([]namedHealthCheck, 0)
+	for _, cli := range []struct {
+		name string
+		di   storage.DI
+	}{{"meta_db", a.MetaCli}, {"inst_db", a.InstCli}, {"cache", a.CacheCli}} {
+		if nil != cli.di {
+			checks = append(checks, namedHealthCheck{name: cli.name, check: cli.di.Ping})
+		}
+	}
+	a.healthLock.RLock()
+	checks = append(checks, a.healthChecks...)
+	a.healthLock.RUnlock()
+
+	results := make([]chan error, len(checks))
+	for i, c := range checks {
+		results[i] = make(chan error, 1)
+		go func(check HealthCheck, result chan error) {
+			result <- check()
+		}(c.check, results[i])
+	}
+
+	status := HealthStatus{Healthy: true, Items: make([]HealthItem, 0, len(checks))}
+	timeout := time.After(HealthCheckTimeout)
+	for i, c := range checks {
+		item := HealthItem{Name: c.name, Healthy: true}
+		select {
+		case err := <-results[i]:
+			if nil != err {
+				item.Healthy = false
+				item.Message = err.Error()
+			}
+		case <-timeout:
+			item.Healthy = false
+			item.Message = fmt.Sprintf("no response in %s", HealthCheckTimeout)
+		}
+		if !item.Healthy {
+			status.Healthy = false
+		}
+		status.Items = append(status.Items, item)
+	}
+	return status
+}
+
+// RegisterHealth serve the liveness on /healthz and the readiness on /readyz of the http server
+func (a *APIResource) RegisterHealth(s *httpserver.HttpServer) {
+	s.GetWebContainer().Handle(HealthzPath, http.HandlerFunc(a.Healthz))
+	s.GetWebContainer().Handle(ReadyzPath, http.HandlerFunc(a.Readyz))
+}
+
+// Healthz answer the liveness probe, the server is alive as long as it responds
+func (a *APIResource) Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	rsp, _ := a.CreateAPIRspStr(common.CCSuccess, HealthStatus{Healthy: true, Items: []HealthItem{}})
+	io.WriteString(w, rsp)
+}
+
+// Readyz answer the readiness probe with the check result of each dependency,
+// it responds 503 if any dependency is unavailable
+func (a *APIResource) Readyz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	status := a.CheckHealth()
+	if status.Healthy {
+		rsp, _ := a.CreateAPIRspStr(common.CCSuccess, status)
+		io.WriteString(w, rsp)
+		return
+	}
+	blog.Warnf("server is not ready, %+v", status.Items)
+	rsp, _ := a.CreateAPIRspErrStrWithData(common.CCErrCommServiceNotReady, "the dependencies are unavailable", status)
+	w.WriteHeader(http.StatusServiceUnavailable)
+	io.WriteString(w, rsp)
+}
@@ -0,0 +1,84 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package api
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/storage/memclient"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckHealth(t *testing.T) {
+	a := &APIResource{InstCli: memclient.NewMemDB(), CacheCli: memclient.NewMemRedis()}
+	a.AddHealthCheck("registry", func() error { return nil })
+
+	status := a.CheckHealth()
+	assert.True(t, status.Healthy)
+	assert.Equal(t, []HealthItem{{Name: "inst_db", Healthy: true}, {Name: "cache", Healthy: true}, {Name: "registry", Healthy: true}}, status.Items)
+
+	// the check with the same name is replaced
+	a.AddHealthCheck("registry", func() error { return errors.New("zookeeper session is not alive") })
+	status = a.CheckHealth()
+	assert.False(t, status.Healthy)
+	require.Len(t, status.Items, 3)
+	assert.Equal(t, HealthItem{Name: "registry", Healthy: false, Message: "zookeeper session is not alive"}, status.Items[2])
+}
+
+func TestCheckHealthTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { HealthCheckTimeout = timeout }(HealthCheckTimeout)
+	HealthCheckTimeout = 10 * time.Millisecond
+
+	a := &APIResource{}
+	a.AddHealthCheck("server:hostcontroller", func() error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	status := a.CheckHealth()
+	assert.False(t, status.Healthy)
+	require.Len(t, status.Items, 1)
+	assert.False(t, status.Items[0].Healthy)
+}
+
+func TestReadyz(t *testing.T) {
+	a := &APIResource{}
+	rsp := httptest.NewRecorder()
+	a.Readyz(rsp, httptest.NewRequest("GET", ReadyzPath, nil))
+	assert.Equal(t, http.StatusOK, rsp.Code)
+
+	a.AddHealthCheck("server:hostcontroller", func() error { return errors.New("there is no hostcontroller servers") })
+	rsp = httptest.NewRecorder()
+	a.Readyz(rsp, httptest.NewRequest("GET", ReadyzPath, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rsp.Code)
+
+	result := struct {
+		BKAPIRsp
+		Data HealthStatus `json:"data"`
+	}{}
+	require.NoError(t, json.Unmarshal(rsp.Body.Bytes(), &result))
+	assert.False(t, result.Result)
+	assert.Equal(t, common.CCErrCommServiceNotReady, result.Code)
+	assert.Equal(t, []HealthItem{{Name: "server:hostcontroller", Healthy: false, Message: "there is no hostcontroller servers"}}, result.Data.Items)
+
+	// the liveness does not depend on the dependencies
+	rsp = httptest.NewRecorder()
+	a.Healthz(rsp, httptest.NewRequest("GET", HealthzPath, nil))
+	assert.Equal(t, http.StatusOK, rsp.Code)
+}
@@ -13,24 +13,55 @@
 package config
 
 import (
+	"configcenter/src/common/fileregistry"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
+)
+
+const (
+	// RegDiscoverZookeeper register and discover by zookeeper
+	RegDiscoverZookeeper = "zookeeper"
+	// RegDiscoverFile register and discover by the static registry file
+	RegDiscoverFile = "file"
 )
 
 // CCAPIConfig define configuration of ccapi server
 type CCAPIConfig struct {
 	AddrPort    string
 	RegDiscover string
-	ExConfig    string
+	// RegDiscoverBackend the backend of the register and discover server, zookeeper or file
+	RegDiscoverBackend string
+	ExConfig           string
+	// DiscoveryStrategy the way to pick one instance of the discovered servers, roundrobin, leastinflight or weighted
+	DiscoveryStrategy string
+	// DiscoveryMaxFails the consecutive failures to eject an instance of the discovered servers
+	DiscoveryMaxFails int
+	// DiscoveryEjectTime how long an ejected instance is kept out
+	DiscoveryEjectTime time.Duration
 }
 
 // NewCCAPIConfig create ccapi config object
 func NewCCAPIConfig() *CCAPIConfig {
 	return &CCAPIConfig{
-		AddrPort:    "127.0.0.1:8081",
-		RegDiscover: "",
+		AddrPort:           "127.0.0.1:8081",
+		RegDiscover:        "",
+		RegDiscoverBackend: RegDiscoverZookeeper,
+	}
+}
+
+// GetRegDiscover get the address of the register and discover server with the backend selected
+func (conf *CCAPIConfig) GetRegDiscover() string {
+	return GetRegDiscoverAddr(conf.RegDiscoverBackend, conf.RegDiscover)
+}
+
+// GetRegDiscoverAddr return the address for the backend, the file registry address has the file:// scheme
+func GetRegDiscoverAddr(backend, addr string) string {
+	if RegDiscoverFile == backend && !fileregistry.IsFileAddr(addr) {
+		return fileregistry.Scheme + addr
 	}
+	return addr
 }
 
 // GetAddress get the address
@@ -140,6 +140,12 @@ const (
 	// BKHostOuterIPField the host outerip field
 	BKHostOuterIPField = "bk_host_outerip"
 
+	// BKHostInnerIPListField the indexed array of the canonical inner ips of the host
+	BKHostInnerIPListField = "bk_host_innerip_list"
+
+	// BKHostOuterIPListField the indexed array of the canonical outer ips of the host
+	BKHostOuterIPListField = "bk_host_outerip_list"
+
 	// BKHostIDField the host id field
 	BKHostIDField = "bk_host_id"
 
@@ -263,6 +269,9 @@ const (
 	// BKOpTimeField the op time field
 	BKOpTimeField = "op_time"
 
+	// BKOpChangesField the changed properties field of the operation log
+	BKOpChangesField = "changes"
+
 	// BKSetEnvField the set env field
 	BKSetEnvField = "bk_set_env"
 
@@ -539,6 +548,9 @@ const (
 
 	// BKTableNameObjClassifiction the table name of the object classification
 	BKTableNameObjClassifiction = "cc_ObjClassification"
+
+	// BKTableNameSystem the table name of the system info, such as the migrate history
+	BKTableNameSystem = "cc_System"
 )
 
 const (
@@ -589,4 +601,6 @@ const (
 	// BKHTTPOwnerID the owner id
 	BKHTTPOwnerID = "HTTP_BLUEKING_SUPPLIER_ID"
 	//BKHTTPOwnerID = "HTTP_BLUEKING_OWNERID"
+	// BKHTTPRequestID the request id, it is kept the same in the forwarded requests to trace the request
+	BKHTTPRequestID = "X-Request-Id"
 )
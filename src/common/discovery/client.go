@@ -0,0 +1,327 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/types"
+)
+
+// Strategy the way to pick one instance of the server
+type Strategy string
+
+const (
+	// RoundRobin pick the instances in turn
+	RoundRobin Strategy = "roundrobin"
+	// LeastInflight pick the instance with the fewest requests in flight
+	LeastInflight Strategy = "leastinflight"
+	// Weighted pick the instances in turn in proportion to their weight
+	Weighted Strategy = "weighted"
+)
+
+const (
+	// DefaultMaxFails the consecutive failures to eject an instance
+	DefaultMaxFails = 3
+	// DefaultEjectTime how long an ejected instance is kept out of the selection
+	DefaultEjectTime = 30 * time.Second
+)
+
+// Options the selection and health options of the discovery client
+type Options struct {
+	Strategy Strategy
+	// MaxFails the consecutive failures to eject an instance, 0 means DefaultMaxFails, negative disables the ejection
+	MaxFails int
+	// EjectTime how long an ejected instance is kept out of the selection, 0 means DefaultEjectTime
+	EjectTime time.Duration
+}
+
+// InstanceStatus the instance of the server seen by the discovery client
+type InstanceStatus struct {
+	types.ServerInfo
+	Address      string     `json:"address"`
+	Inflight     int        `json:"inflight"`
+	Fails        int        `json:"fails"`
+	Healthy      bool       `json:"healthy"`
+	EjectedUntil *time.Time `json:"ejected_until,omitempty"`
+}
+
+type instance struct {
+	info         types.ServerInfo
+	addr         string
+	current      int
+	inflight     int
+	fails        int
+	ejectedUntil time.Time
+}
+
+type service struct {
+	instances []*instance
+	next      int
+}
+
+// Client pick the instance of the discovered servers, keyed by types.CC_MODULE_*
+type Client struct {
+	opts     Options
+	lock     sync.Mutex
+	services map[string]*service
+	now      func() time.Time
+}
+
+// NewClient create a discovery client
+func NewClient(opts Options) *Client {
+	switch opts.Strategy {
+	case RoundRobin, LeastInflight, Weighted:
+	case "":
+		opts.Strategy = RoundRobin
+	default:
+		blog.Warnf("unknown discovery strategy %s, use %s", opts.Strategy, RoundRobin)
+		opts.Strategy = RoundRobin
+	}
+	if 0 == opts.MaxFails {
+		opts.MaxFails = DefaultMaxFails
+	}
+	if 0 >= opts.EjectTime {
+		opts.EjectTime = DefaultEjectTime
+	}
+	return &Client{
+		opts:     opts,
+		services: make(map[string]*service),
+		now:      time.Now,
+	}
+}
+
+// Update replace the instances of the server with the discovered server infos,
+// the health state of the instances which are still there is kept
+func (c *Client) Update(module string, servInfos []string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	serv, ok := c.services[module]
+	if !ok {
+		serv = &service{}
+		c.services[module] = serv
+	}
+	existing := make(map[string]*instance, len(serv.instances))
+	for _, inst := range serv.instances {
+		existing[inst.addr] = inst
+	}
+
+	instances := make([]*instance, 0, len(servInfos))
+	for _, data := range servInfos {
+		info := types.ServerInfo{}
+		if err := json.Unmarshal([]byte(data), &info); nil != err {
+			blog.Warnf("fail to do json unmarshal(%s), err:%s", data, err.Error())
+			continue
+		}
+		if info.Weight <= 0 {
+			info.Weight = 1
+		}
+		addr := info.Scheme + "://" + info.IP + ":" + strconv.Itoa(int(info.Port))
+		inst, ok := existing[addr]
+		if !ok {
+			inst = &instance{addr: addr}
+		}
+		inst.info = info
+		instances = append(instances, inst)
+	}
+	serv.instances = instances
+}
+
+// GetServer pick one instance of the server, the address is like http://127.0.0.1:8080
+func (c *Client) GetServer(module string) (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	serv, ok := c.services[module]
+	if !ok || 0 == len(serv.instances) {
+		return "", fmt.Errorf("there is no %s servers", module)
+	}
+	return c.pick(module, serv).addr, nil
+}
+
+// Available check whether the server has any instance which is not ejected
+func (c *Client) Available(module string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	serv, ok := c.services[module]
+	if !ok || 0 == len(serv.instances) {
+		return fmt.Errorf("there is no %s servers", module)
+	}
+	now := c.now()
+	for _, inst := range serv.instances {
+		if !now.Before(inst.ejectedUntil) {
+			return nil
+		}
+	}
+	return fmt.Errorf("all the %s servers are ejected", module)
+}
+
+// pick the instance by the strategy, the ejected instances are skipped unless all of them are ejected
+func (c *Client) pick(module string, serv *service) *instance {
+	now := c.now()
+	candidates := make([]*instance, 0, len(serv.instances))
+	for _, inst := range serv.instances {
+		if !now.Before(inst.ejectedUntil) {
+			candidates = append(candidates, inst)
+		}
+	}
+	if 0 == len(candidates) {
+		blog.Warnf("all the %s servers are ejected, try them all", module)
+		candidates = serv.instances
+	}
+
+	switch c.opts.Strategy {
+	case LeastInflight:
+		start := serv.next % len(candidates)
+		serv.next++
+		best := candidates[start]
+		for i := 1; i < len(candidates); i++ {
+			inst := candidates[(start+i)%len(candidates)]
+			if inst.inflight < best.inflight {
+				best = inst
+			}
+		}
+		return best
+	case Weighted:
+		// the smooth weighted round robin, the heavier instance is picked more but not in a row
+		var best *instance
+		total := 0
+		for _, inst := range candidates {
+			inst.current += inst.info.Weight
+			total += inst.info.Weight
+			if nil == best || inst.current > best.current {
+				best = inst
+			}
+		}
+		best.current -= total
+		return best
+	default:
+		inst := candidates[serv.next%len(candidates)]
+		serv.next++
+		return inst
+	}
+}
+
+// Track count the request to the address as in flight,
+// the returned func must be called with the result when the request is done
+func (c *Client) Track(addr string) func(failed bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	instances := c.findInstances(addr)
+	for _, inst := range instances {
+		inst.inflight++
+	}
+	return func(failed bool) {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		for _, inst := range instances {
+			inst.inflight--
+			c.report(inst, failed)
+		}
+	}
+}
+
+// TrackRequest track the http request by its scheme and host,
+// the transport errors and the gateway errors are counted as the failures of the instance
+func (c *Client) TrackRequest(req *http.Request) func(rsp *http.Response, err error) {
+	done := c.Track(req.URL.Scheme + "://" + req.URL.Host)
+	return func(rsp *http.Response, err error) {
+		failed := nil != err
+		if nil != rsp {
+			switch rsp.StatusCode {
+			case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+				failed = true
+			}
+		}
+		done(failed)
+	}
+}
+
+// Module return the server which the address belongs to, empty if unknown
+func (c *Client) Module(addr string) string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for module, serv := range c.services {
+		for _, inst := range serv.instances {
+			if inst.addr == addr {
+				return module
+			}
+		}
+	}
+	return ""
+}
+
+func (c *Client) findInstances(addr string) []*instance {
+	var instances []*instance
+	for _, serv := range c.services {
+		for _, inst := range serv.instances {
+			if inst.addr == addr {
+				instances = append(instances, inst)
+			}
+		}
+	}
+	return instances
+}
+
+// report record the result of the request, the instance is ejected after too many consecutive failures
+func (c *Client) report(inst *instance, failed bool) {
+	if !failed {
+		inst.fails = 0
+		return
+	}
+	inst.fails++
+	if c.opts.MaxFails > 0 && inst.fails >= c.opts.MaxFails {
+		inst.ejectedUntil = c.now().Add(c.opts.EjectTime)
+		blog.Warnf("server %s failed %d times in a row, eject it until %s", inst.addr, inst.fails, inst.ejectedUntil.Format(time.RFC3339))
+	}
+}
+
+// Snapshot return the instances of each server currently seen, ordered by the address
+func (c *Client) Snapshot() map[string][]InstanceStatus {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.now()
+	result := make(map[string][]InstanceStatus, len(c.services))
+	for module, serv := range c.services {
+		statuses := make([]InstanceStatus, 0, len(serv.instances))
+		for _, inst := range serv.instances {
+			status := InstanceStatus{
+				ServerInfo: inst.info,
+				Address:    inst.addr,
+				Inflight:   inst.inflight,
+				Fails:      inst.fails,
+				Healthy:    !now.Before(inst.ejectedUntil),
+			}
+			if !status.Healthy {
+				ejectedUntil := inst.ejectedUntil
+				status.EjectedUntil = &ejectedUntil
+			}
+			statuses = append(statuses, status)
+		}
+		sort.Slice(statuses, func(i, j int) bool { return statuses[i].Address < statuses[j].Address })
+		result[module] = statuses
+	}
+	return result
+}
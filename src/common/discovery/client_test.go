@@ -0,0 +1,162 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package discovery
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"configcenter/src/common/http/httpclient"
+	"configcenter/src/common/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func servInfo(ip string, weight int) string {
+	data, _ := json.Marshal(types.ServerInfo{IP: ip, Port: 8080, Scheme: "http", Weight: weight})
+	return string(data)
+}
+
+func pickN(t *testing.T, c *Client, module string, n int) map[string]int {
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		addr, err := c.GetServer(module)
+		require.NoError(t, err)
+		counts[addr]++
+	}
+	return counts
+}
+
+func TestGetServerWithoutInstances(t *testing.T) {
+	c := NewClient(Options{})
+	_, err := c.GetServer(types.CC_MODULE_HOST)
+	assert.Error(t, err)
+
+	c.Update(types.CC_MODULE_HOST, []string{"not json"})
+	_, err = c.GetServer(types.CC_MODULE_HOST)
+	assert.Error(t, err)
+}
+
+func TestRoundRobin(t *testing.T) {
+	c := NewClient(Options{Strategy: RoundRobin})
+	c.Update(types.CC_MODULE_TOPO, []string{servInfo("127.0.0.1", 0), servInfo("127.0.0.2", 0)})
+
+	counts := pickN(t, c, types.CC_MODULE_TOPO, 10)
+	assert.Equal(t, map[string]int{"http://127.0.0.1:8080": 5, "http://127.0.0.2:8080": 5}, counts)
+}
+
+func TestWeighted(t *testing.T) {
+	c := NewClient(Options{Strategy: Weighted})
+	c.Update(types.CC_MODULE_PROC, []string{servInfo("127.0.0.1", 3), servInfo("127.0.0.2", 1)})
+
+	first, _ := c.GetServer(types.CC_MODULE_PROC)
+	second, _ := c.GetServer(types.CC_MODULE_PROC)
+	assert.Equal(t, "http://127.0.0.1:8080", first)
+	assert.Equal(t, "http://127.0.0.1:8080", second)
+
+	counts := pickN(t, c, types.CC_MODULE_PROC, 6)
+	assert.Equal(t, map[string]int{"http://127.0.0.1:8080": 4, "http://127.0.0.2:8080": 2}, counts)
+}
+
+func TestLeastInflight(t *testing.T) {
+	c := NewClient(Options{Strategy: LeastInflight})
+	c.Update(types.CC_MODULE_HOST, []string{servInfo("127.0.0.1", 0), servInfo("127.0.0.2", 0)})
+
+	done := c.Track("http://127.0.0.1:8080")
+	counts := pickN(t, c, types.CC_MODULE_HOST, 4)
+	assert.Equal(t, map[string]int{"http://127.0.0.2:8080": 4}, counts)
+
+	done(false)
+	counts = pickN(t, c, types.CC_MODULE_HOST, 4)
+	assert.Equal(t, 2, counts["http://127.0.0.1:8080"])
+}
+
+func TestEjectAfterFailures(t *testing.T) {
+	now := time.Now()
+	c := NewClient(Options{MaxFails: 2, EjectTime: time.Minute})
+	c.now = func() time.Time { return now }
+	c.Update(types.CC_MODULE_HOSTCONTROLLER, []string{servInfo("127.0.0.1", 0), servInfo("127.0.0.2", 0)})
+
+	c.Track("http://127.0.0.1:8080")(true)
+	assert.Len(t, pickN(t, c, types.CC_MODULE_HOSTCONTROLLER, 4), 2)
+
+	c.Track("http://127.0.0.1:8080")(true)
+	assert.Equal(t, map[string]int{"http://127.0.0.2:8080": 4}, pickN(t, c, types.CC_MODULE_HOSTCONTROLLER, 4))
+
+	// the ejection is kept through the rediscovery
+	c.Update(types.CC_MODULE_HOSTCONTROLLER, []string{servInfo("127.0.0.1", 0), servInfo("127.0.0.2", 0)})
+	snapshot := c.Snapshot()[types.CC_MODULE_HOSTCONTROLLER]
+	require.Len(t, snapshot, 2)
+	assert.False(t, snapshot[0].Healthy)
+	assert.Equal(t, 2, snapshot[0].Fails)
+	assert.NotNil(t, snapshot[0].EjectedUntil)
+	assert.True(t, snapshot[1].Healthy)
+
+	// all ejected, try them all rather than failing
+	c.Track("http://127.0.0.2:8080")(true)
+	c.Track("http://127.0.0.2:8080")(true)
+	assert.Len(t, pickN(t, c, types.CC_MODULE_HOSTCONTROLLER, 4), 2)
+
+	// back after the eject time, and healthy again after a success
+	now = now.Add(time.Minute)
+	c.Track("http://127.0.0.1:8080")(false)
+	snapshot = c.Snapshot()[types.CC_MODULE_HOSTCONTROLLER]
+	assert.True(t, snapshot[0].Healthy)
+	assert.Equal(t, 0, snapshot[0].Fails)
+}
+
+func TestAvailable(t *testing.T) {
+	now := time.Now()
+	c := NewClient(Options{MaxFails: 1, EjectTime: time.Minute})
+	c.now = func() time.Time { return now }
+	assert.Error(t, c.Available(types.CC_MODULE_HOSTCONTROLLER))
+
+	c.Update(types.CC_MODULE_HOSTCONTROLLER, []string{servInfo("127.0.0.1", 0)})
+	assert.NoError(t, c.Available(types.CC_MODULE_HOSTCONTROLLER))
+
+	c.Track("http://127.0.0.1:8080")(true)
+	assert.Error(t, c.Available(types.CC_MODULE_HOSTCONTROLLER))
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, c.Available(types.CC_MODULE_HOSTCONTROLLER))
+}
+
+func TestTrackRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c := NewClient(Options{MaxFails: 1})
+	addr := strings.TrimPrefix(server.URL, "http://")
+	ip, port := addr[:strings.LastIndex(addr, ":")], addr[strings.LastIndex(addr, ":")+1:]
+	c.Update(types.CC_MODULE_APISERVER, []string{`{"ip":"` + ip + `","port":` + port + `,"scheme":"http"}`})
+
+	httpclient.SetRequestTracker(c.TrackRequest)
+	defer httpclient.SetRequestTracker(nil)
+
+	code, _, err := httpclient.NewHttpClient().GETEx(server.URL+"/healthz", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+
+	snapshot := c.Snapshot()[types.CC_MODULE_APISERVER]
+	require.Len(t, snapshot, 1)
+	assert.Equal(t, server.URL, snapshot[0].Address)
+	assert.False(t, snapshot[0].Healthy)
+	assert.Equal(t, 0, snapshot[0].Inflight)
+}
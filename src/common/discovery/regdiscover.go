@@ -0,0 +1,231 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/RegisterDiscover"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/core/cc/api"
+	"configcenter/src/common/core/cc/config"
+	"configcenter/src/common/http/httpclient"
+	"configcenter/src/common/http/httpserver"
+	"configcenter/src/common/lifecycle"
+	"configcenter/src/common/types"
+	"configcenter/src/common/version"
+
+	restful "github.com/emicklei/go-restful"
+)
+
+// readyCheckInterval the interval to check the readiness before the registration
+var readyCheckInterval = 2 * time.Second
+
+// RegDiscover register the server itself and discover the servers it depends on
+type RegDiscover struct {
+	module  string
+	ip      string
+	port    uint
+	isSSL   bool
+	deps    []string
+	rd      *RegisterDiscover.RegDiscover
+	client  *Client
+	rootCtx context.Context
+	cancel  context.CancelFunc
+}
+
+// NewOptions create the discovery options from the server config
+func NewOptions(conf *config.CCAPIConfig) Options {
+	return Options{
+		Strategy:  Strategy(conf.DiscoveryStrategy),
+		MaxFails:  conf.DiscoveryMaxFails,
+		EjectTime: conf.DiscoveryEjectTime,
+	}
+}
+
+// NewRegDiscover create a RegDiscover object,
+// module is the server itself, deps are the servers it discovers
+func NewRegDiscover(zkserv string, module string, ip string, port uint, isSSL bool, opts Options, deps ...string) *RegDiscover {
+	return &RegDiscover{
+		module: module,
+		ip:     ip,
+		port:   port,
+		isSSL:  isSSL,
+		deps:   deps,
+		rd:     RegisterDiscover.NewRegDiscoverEx(zkserv, 10*time.Second),
+		client: NewClient(opts),
+	}
+}
+
+// Start the register and discover, it blocks until stopped
+func (r *RegDiscover) Start() error {
+	//create root context
+	r.rootCtx, r.cancel = context.WithCancel(context.Background())
+
+	//start regdiscover
+	if err := r.rd.Start(); err != nil {
+		blog.Errorf("fail to start register and discover serv. err:%s", err.Error())
+		return err
+	}
+	// leave the discovery first on shutdown, so that no new request is routed here while draining
+	lifecycle.Register(lifecycle.StageDeregister, "discovery "+r.module, func(ctx context.Context) error {
+		return r.Stop()
+	})
+
+	// here: discover other services
+	for _, module := range r.deps {
+		event, err := r.rd.DiscoverService(types.CC_SERV_BASEPATH + "/" + module)
+		if err != nil {
+			blog.Errorf("fail to register discover for %s. err:%s", module, err.Error())
+			return err
+		}
+		go r.watch(module, event)
+	}
+	if 0 != len(r.deps) {
+		httpclient.SetRequestTracker(r.client.TrackRequest)
+		httpclient.SetTargetResolver(r.client.Module)
+	}
+
+	// the server is registered once it is ready, so that no request comes before the dependencies are available
+	a := api.NewAPIResource()
+	a.AddHealthCheck("registry", r.rd.Ping)
+	for _, module := range r.deps {
+		module := module
+		a.AddHealthCheck("server:"+module, func() error { return r.client.Available(module) })
+	}
+	if !r.waitReady(a) {
+		blog.Warn("register and discover serv stopped before the server is ready")
+		return r.stopped()
+	}
+	if err := r.register(); err != nil {
+		blog.Errorf("fail to register %s(%s), err:%s", r.module, r.ip, err.Error())
+		return err
+	}
+	blog.Infof("%s(%s) is ready and registered", r.module, r.ip)
+
+	<-r.rootCtx.Done()
+	blog.Warn("register and discover serv done")
+	return r.stopped()
+}
+
+// stopped block until the process exits if stopped by the shutdown, the caller takes the return as a failure
+func (r *RegDiscover) stopped() error {
+	if lifecycle.ShuttingDown() {
+		lifecycle.Wait()
+	}
+	return nil
+}
+
+// Stop the register and discover
+func (r *RegDiscover) Stop() error {
+	r.cancel()
+
+	r.rd.Stop()
+
+	return nil
+}
+
+// GetServer fetch server info
+func (r *RegDiscover) GetServer(servType string) (string, error) {
+	addr, err := r.client.GetServer(servType)
+	if err != nil {
+		blog.Errorf("%s", err.Error())
+		return "", err
+	}
+	return addr, nil
+}
+
+// Snapshot return the instances of each server currently seen
+func (r *RegDiscover) Snapshot() map[string][]InstanceStatus {
+	return r.client.Snapshot()
+}
+
+// SnapshotAction the action to show the discovered instances, it is registered to the /discovery web service
+func (r *RegDiscover) SnapshotAction() *httpserver.Action {
+	return httpserver.NewAction(common.HTTPSelectGet, "/snapshot", nil, r.snapshot, nil)
+}
+
+func (r *RegDiscover) snapshot(req *restful.Request, resp *restful.Response) {
+	rsp, err := api.NewAPIResource().CreateAPIRspStr(common.CCSuccess, r.Snapshot())
+	if err != nil {
+		blog.Errorf("create response failed, error information is %v", err)
+		return
+	}
+	io.WriteString(resp, rsp)
+}
+
+// waitReady wait until all the health checks pass, return false if stopped
+func (r *RegDiscover) waitReady(a *api.APIResource) bool {
+	for {
+		status := a.CheckHealth()
+		if status.Healthy {
+			return true
+		}
+		for _, item := range status.Items {
+			if !item.Healthy {
+				blog.Warnf("%s is not ready, %s is unavailable: %s", r.module, item.Name, item.Message)
+			}
+		}
+		select {
+		case <-r.rootCtx.Done():
+			return false
+		case <-time.After(readyCheckInterval):
+		}
+	}
+}
+
+func (r *RegDiscover) watch(module string, event <-chan *RegisterDiscover.DiscoverEvent) {
+	for {
+		select {
+		case env, ok := <-event:
+			if !ok {
+				return
+			}
+			if nil != env.Err {
+				blog.Errorf("discover %s failed, keep the last servers, err:%s", module, env.Err.Error())
+				continue
+			}
+			blog.Infof("discover %s(%v)", module, env.Server)
+			r.client.Update(module, env.Server)
+		case <-r.rootCtx.Done():
+			return
+		}
+	}
+}
+
+func (r *RegDiscover) register() error {
+	servInfo := types.ServerInfo{
+		IP:      r.ip,
+		Port:    r.port,
+		Scheme:  "http",
+		Version: version.GetVersion(),
+		Pid:     os.Getpid(),
+	}
+	if r.isSSL {
+		servInfo.Scheme = "https"
+	}
+
+	data, err := json.Marshal(servInfo)
+	if err != nil {
+		blog.Errorf("fail to marshal %s server info to json. err:%s", r.module, err.Error())
+		return err
+	}
+
+	path := types.CC_SERV_BASEPATH + "/" + r.module + "/" + r.ip
+	return r.rd.RegisterAndWatchService(path, data)
+}
@@ -124,6 +124,12 @@ const (
 	// CCErrCommParams should be string
 	CCErrCommParamsShouldBeString = 1199032
 
+	// CCErrCommServiceNotReady the dependencies of the service are unavailable
+	CCErrCommServiceNotReady = 1199033
+
+	// CCErrCommImportFailed failed to import the data
+	CCErrCommImportFailed = 1199034
+
 	// apiserver 1100XXX
 
 	// toposerver 1101XXX
@@ -244,6 +250,15 @@ const (
 	CCErrTopoMulueIDNotfoundFailed = 1101080
 	CCErrTopoBkAppNotAllowedDelete = 1101081
 
+	// CCErrTopoBundleExportFailed failed to export the business bundle
+	CCErrTopoBundleExportFailed = 1101082
+	// CCErrTopoBundleImportFailed failed to import the business bundle
+	CCErrTopoBundleImportFailed = 1101083
+	// CCErrTopoBundleConflict the business bundle conflicts with the existing data
+	CCErrTopoBundleConflict = 1101084
+	// CCErrTopoBundleMainlineMismatch the mainline model of the business bundle is different from the target
+	CCErrTopoBundleMainlineMismatch = 1101085
+
 	// objectcontroller 1102XXX
 
 	// CCErrObjectPropertyGroupInsertFailed failed to save the property group
@@ -279,6 +294,18 @@ const (
 	// CCErrEventSubscribePingFailed failed to ping the filed
 	CCErrEventSubscribePingFailed = 1103004
 
+	// CCErrEventDeadLetterSelectFailed failed to select the dead letters
+	CCErrEventDeadLetterSelectFailed = 1103008
+
+	// CCErrEventDeadLetterReplayFailed failed to replay the dead letters
+	CCErrEventDeadLetterReplayFailed = 1103009
+
+	// CCErrEventDeadLetterDeleteFailed failed to delete the dead letters
+	CCErrEventDeadLetterDeleteFailed = 1103010
+
+	// CCErrEventReplayFailed failed to replay the events
+	CCErrEventReplayFailed = 1103011
+
 	// host 1104XXX
 	CCErrHostModuleRelationAddFailed = 1104000
 
@@ -0,0 +1,259 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package fileregistry
+
+import (
+	"configcenter/src/common/blog"
+	"configcenter/src/common/types"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Scheme the prefix of the register and discover server address which selects the file registry,
+// such as file:///data/cmdb/registry.yaml
+const Scheme = "file://"
+
+// DefaultInterval the default interval to check the file changes
+const DefaultInterval = time.Second * 2
+
+// Data the content of the registry file, the file is decoded as yaml unless it ends with .json
+type Data struct {
+	// Services the servers keyed by the discover path, such as /cc/services/endpoints/hostcontroller
+	Services map[string][]types.ServerInfo `json:"services" yaml:"services"`
+	// Configs the configures keyed by the config path, such as /cc/services/config/hostcontroller
+	Configs map[string]string `json:"configs" yaml:"configs"`
+}
+
+// FileRegistry the static registry which is loaded from the file, and reloaded once the file is changed
+type FileRegistry struct {
+	path     string
+	interval time.Duration
+
+	lock     sync.RWMutex
+	data     Data
+	modTime  time.Time
+	size     int64
+	local    map[string][]string
+	watchers map[chan struct{}]struct{}
+
+	cancel context.CancelFunc
+}
+
+// IsFileAddr check whether the register and discover server address selects the file registry
+func IsFileAddr(addr string) bool {
+	return strings.HasPrefix(addr, Scheme)
+}
+
+// NewFileRegistry create the file registry, the addr is the file path with or without the scheme
+func NewFileRegistry(addr string, interval time.Duration) *FileRegistry {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &FileRegistry{
+		path:     strings.TrimPrefix(addr, Scheme),
+		interval: interval,
+		local:    make(map[string][]string),
+		watchers: make(map[chan struct{}]struct{}),
+	}
+}
+
+// Start load the file and check its changes in background
+func (r *FileRegistry) Start() error {
+	if _, err := r.reload(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	go r.loopCheck(ctx)
+	return nil
+}
+
+// Stop stop checking the file changes
+func (r *FileRegistry) Stop() {
+	if nil != r.cancel {
+		r.cancel()
+	}
+}
+
+// Ping check whether the registry file exists
+func (r *FileRegistry) Ping() error {
+	if _, err := os.Stat(r.path); err != nil {
+		return fmt.Errorf("fail to stat registry file(%s). err:%s", r.path, err.Error())
+	}
+	return nil
+}
+
+// Watch return the channel which is notified once the registry changed, and the function to stop watching
+func (r *FileRegistry) Watch() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	r.lock.Lock()
+	r.watchers[ch] = struct{}{}
+	r.lock.Unlock()
+	return ch, func() {
+		r.lock.Lock()
+		delete(r.watchers, ch)
+		r.lock.Unlock()
+	}
+}
+
+// Register add the server to the registry of this process, the file is not changed
+func (r *FileRegistry) Register(path string, data []byte) {
+	dir := filepath.Dir(path)
+	r.lock.Lock()
+	r.local[dir] = append(r.local[dir], string(data))
+	r.lock.Unlock()
+	r.notify()
+}
+
+// GetServers return the server infos in json under the discover path, ordered as they are listed
+func (r *FileRegistry) GetServers(path string) []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	servers := make([]string, 0)
+	for _, server := range r.data.Services[path] {
+		data, err := json.Marshal(server)
+		if err != nil {
+			blog.Errorf("fail to marshal server info of path(%s). err:%s", path, err.Error())
+			continue
+		}
+		servers = append(servers, string(data))
+	}
+	return append(servers, r.local[path]...)
+}
+
+// GetConfig return the configure of the path, false if not exist
+func (r *FileRegistry) GetConfig(path string) ([]byte, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	data, ok := r.data.Configs[path]
+	return []byte(data), ok
+}
+
+// WriteConfig save the configure of the path into the file
+func (r *FileRegistry) WriteConfig(path string, data []byte) error {
+	r.lock.Lock()
+	if nil == r.data.Configs {
+		r.data.Configs = make(map[string]string)
+	}
+	r.data.Configs[path] = string(data)
+	content, err := r.encode(r.data)
+	r.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// write to the temporary file then rename it, the readers never see the partial file
+	tmp := r.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, content, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		return err
+	}
+	_, err = r.reload()
+	return err
+}
+
+func (r *FileRegistry) loopCheck(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := r.reload()
+			if err != nil {
+				blog.Errorf("fail to reload registry file(%s), keep the last one. err:%s", r.path, err.Error())
+				continue
+			}
+			if changed {
+				blog.Infof("registry file(%s) changed", r.path)
+			}
+		}
+	}
+}
+
+// reload load the file if it is changed since the last load, and notify the watchers if the content changed
+func (r *FileRegistry) reload() (bool, error) {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return false, fmt.Errorf("fail to stat registry file(%s). err:%s", r.path, err.Error())
+	}
+	r.lock.RLock()
+	same := info.ModTime().Equal(r.modTime) && info.Size() == r.size
+	r.lock.RUnlock()
+	if same {
+		return false, nil
+	}
+
+	content, err := ioutil.ReadFile(r.path)
+	if err != nil {
+		return false, err
+	}
+	data := Data{}
+	if err := r.decode(content, &data); err != nil {
+		return false, fmt.Errorf("fail to decode registry file(%s). err:%s", r.path, err.Error())
+	}
+
+	r.lock.Lock()
+	changed := !reflect.DeepEqual(data, r.data)
+	r.data = data
+	r.modTime = info.ModTime()
+	r.size = info.Size()
+	r.lock.Unlock()
+	if changed {
+		r.notify()
+	}
+	return changed, nil
+}
+
+func (r *FileRegistry) notify() {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	for ch := range r.watchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// the watcher has not consumed the last notification yet
+		}
+	}
+}
+
+func (r *FileRegistry) isJSON() bool {
+	return ".json" == strings.ToLower(filepath.Ext(r.path))
+}
+
+func (r *FileRegistry) decode(content []byte, data *Data) error {
+	if r.isJSON() {
+		return json.Unmarshal(content, data)
+	}
+	return yaml.Unmarshal(content, data)
+}
+
+func (r *FileRegistry) encode(data Data) ([]byte, error) {
+	if r.isJSON() {
+		return json.MarshalIndent(data, "", "    ")
+	}
+	return yaml.Marshal(data)
+}
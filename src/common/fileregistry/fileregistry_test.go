@@ -0,0 +1,109 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package fileregistry
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testRegistry = `
+services:
+  /cc/services/endpoints/hostcontroller:
+    - ip: 127.0.0.1
+      port: 50002
+      scheme: http
+configs:
+  /cc/services/config/hostcontroller: |
+    [mongodb]
+    host=127.0.0.1
+`
+
+func newTestRegistry(t *testing.T, name, content string) (*FileRegistry, string) {
+	dir, err := ioutil.TempDir("", "fileregistry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := NewFileRegistry(Scheme+path, time.Millisecond*10)
+	if err := r.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return r, path
+}
+
+func TestFileRegistry(t *testing.T) {
+	r, path := newTestRegistry(t, "registry.yaml", testRegistry)
+	defer os.RemoveAll(filepath.Dir(path))
+	defer r.Stop()
+
+	servers := r.GetServers("/cc/services/endpoints/hostcontroller")
+	expect := `{"ip":"127.0.0.1","port":50002,"hostname":"","scheme":"http","version":"","pid":0}`
+	if 1 != len(servers) || expect != servers[0] {
+		t.Fatalf("expect %s, got %v", expect, servers)
+	}
+	if conf, ok := r.GetConfig("/cc/services/config/hostcontroller"); !ok || "[mongodb]\nhost=127.0.0.1\n" != string(conf) {
+		t.Fatalf("unexpected config %q", conf)
+	}
+
+	changed, stop := r.Watch()
+	defer stop()
+
+	// register in this process
+	r.Register("/cc/services/endpoints/hostcontroller/127.0.0.2", []byte(`{"ip":"127.0.0.2"}`))
+	<-changed
+	if servers := r.GetServers("/cc/services/endpoints/hostcontroller"); 2 != len(servers) {
+		t.Fatalf("expect the registered server, got %v", servers)
+	}
+
+	// the file is changed by others
+	time.Sleep(time.Millisecond * 20)
+	content := testRegistry + "  /cc/services/config/topo: '[errors]'\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(time.Second * 5):
+		t.Fatal("the change of the file is not found")
+	}
+	if conf, ok := r.GetConfig("/cc/services/config/topo"); !ok || "[errors]" != string(conf) {
+		t.Fatalf("unexpected config %q", conf)
+	}
+}
+
+func TestFileRegistryWriteConfig(t *testing.T) {
+	r, path := newTestRegistry(t, "registry.json", `{"services": {}}`)
+	defer os.RemoveAll(filepath.Dir(path))
+	defer r.Stop()
+
+	if err := r.WriteConfig("/cc/services/errors", []byte(`{"en": {}}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the other process loads the written config
+	other := NewFileRegistry(path, 0)
+	if err := other.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer other.Stop()
+	if conf, ok := other.GetConfig("/cc/services/errors"); !ok || `{"en": {}}` != string(conf) {
+		t.Fatalf("unexpected config %q", conf)
+	}
+}
@@ -14,15 +14,84 @@ package httpclient
 
 import (
 	"bytes"
+	"configcenter/src/common/metrics"
 	"configcenter/src/common/ssl"
 	"context"
 	"crypto/tls"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"strconv"
+	"sync/atomic"
 	"time"
 )
 
+// RequestTracker is told of each outgoing request, the returned func is called with the result
+type RequestTracker func(req *http.Request) func(rsp *http.Response, err error)
+
+var tracker atomic.Value
+
+// SetRequestTracker set the tracker of all the outgoing requests,
+// the service discovery uses it to count the requests in flight and eject the failing servers
+func SetRequestTracker(t RequestTracker) {
+	tracker.Store(t)
+}
+
+// TargetResolver return the service name of the address like http://127.0.0.1:8080, empty if unknown
+type TargetResolver func(addr string) string
+
+var resolver atomic.Value
+
+// SetTargetResolver set the resolver of the service name which the outgoing request latency is recorded by,
+// the host of the url is used if the service is unknown
+func SetTargetResolver(r TargetResolver) {
+	resolver.Store(r)
+}
+
+var requestDuration = metrics.NewHistogramVec("cc_http_client_request_duration_seconds",
+	"the latency of the outgoing http requests, by target service, method and status", nil, "target", "method", "code")
+
+// trackedTransport report the requests to the tracker and record the latency
+type trackedTransport struct {
+	base http.RoundTripper
+}
+
+// NewTrackedTransport wrap the transport to report the requests to the tracker, nil means http.DefaultTransport
+func NewTrackedTransport(base http.RoundTripper) http.RoundTripper {
+	if nil == base {
+		base = http.DefaultTransport
+	}
+	return &trackedTransport{base: base}
+}
+
+func (t *trackedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	var done func(rsp *http.Response, err error)
+	if track, _ := tracker.Load().(RequestTracker); nil != track {
+		done = track(req)
+	}
+	rsp, err := t.base.RoundTrip(req)
+	if nil != done {
+		done(rsp, err)
+	}
+
+	code := "error"
+	if nil == err {
+		code = strconv.Itoa(rsp.StatusCode)
+	}
+	requestDuration.Since(start, targetOf(req), req.Method, code)
+	return rsp, err
+}
+
+func targetOf(req *http.Request) string {
+	if resolve, _ := resolver.Load().(TargetResolver); nil != resolve {
+		if target := resolve(req.URL.Scheme + "://" + req.URL.Host); "" != target {
+			return target
+		}
+	}
+	return req.URL.Host
+}
+
 type HttpClient struct {
 	caFile   string
 	certFile string
@@ -33,7 +102,7 @@ type HttpClient struct {
 
 func NewHttpClient() *HttpClient {
 	return &HttpClient{
-		httpCli: &http.Client{},
+		httpCli: &http.Client{Transport: NewTrackedTransport(nil)},
 		header:  make(map[string]string),
 	}
 }
@@ -47,7 +116,7 @@ func (client *HttpClient) SetTlsNoVerity() error {
 
 	trans := client.NewTransPort()
 	trans.TLSClientConfig = tlsConf
-	client.httpCli.Transport = trans
+	client.httpCli.Transport = NewTrackedTransport(trans)
 
 	return nil
 }
@@ -85,7 +154,7 @@ func (client *HttpClient) SetTlsVerity(caFile, certFile, keyFile, passwd string)
 func (client *HttpClient) SetTlsVerityConfig(tlsConf *tls.Config) {
 	trans := client.NewTransPort()
 	trans.TLSClientConfig = tlsConf
-	client.httpCli.Transport = trans
+	client.httpCli.Transport = NewTrackedTransport(trans)
 }
 
 func (client *HttpClient) NewTransPort() *http.Transport {
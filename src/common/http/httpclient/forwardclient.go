@@ -13,7 +13,13 @@
 package httpclient
 
 import (
+	"bytes"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/util"
+	"io"
 	"io/ioutil"
+	"net/http"
 	"net/http/httputil"
 
 	"net/url"
@@ -32,9 +38,11 @@ func ReqForward(req *restful.Request, url, method string) (string, error) {
 	httpcli := NewHttpClient()
 	httpcli.SetHeader("Content-Type", "application/json")
 	httpcli.SetHeader("Accept", "application/json")
+	rid := setRequestID(req.Request.Header)
 
 	reply, err := httpcli.Request(url, method, req.Request.Header, body)
 	if err != nil {
+		blog.Errorf("forward request to %s failed, error: %v, rid: %s", url, err, rid)
 		return err.Error(), err
 	}
 
@@ -45,7 +53,9 @@ func ReqForward(req *restful.Request, url, method string) (string, error) {
 func ProxyRestHttp(req *restful.Request, resp *restful.Response, addr string) {
 	u, err := url.Parse(addr)
 	if err == nil {
+		setRequestID(req.Request.Header)
 		proxy := httputil.NewSingleHostReverseProxy(u)
+		proxy.Transport = NewTrackedTransport(nil)
 		proxy.ServeHTTP(resp.ResponseWriter, req.Request)
 	} else {
 		resp.ResponseWriter.Write([]byte(err.Error()))
@@ -58,23 +68,91 @@ func ReqHttp(req *restful.Request, url, method string, body []byte) (string, err
 	httpcli := NewHttpClient()
 	httpcli.SetHeader("Content-Type", "application/json")
 	httpcli.SetHeader("Accept", "application/json")
+	rid := setRequestID(req.Request.Header)
 
 	reply, err := httpcli.Request(url, method, req.Request.Header, body)
 	if err != nil {
+		blog.Errorf("request %s failed, error: %v, rid: %s", url, err, rid)
 		return err.Error(), err
 	}
 
 	return string(reply), err
 }
 
+//ReqStreamForward 转发请求，并在收到响应时逐步写回，用于大量数据的导出
+func ReqStreamForward(req *restful.Request, resp *restful.Response, url, method string) error {
+	body, err := ioutil.ReadAll(req.Request.Body)
+	if err != nil {
+		return err
+	}
+	return ReqStreamHttp(req, resp, url, method, body)
+}
+
+//ReqStreamHttp 请求url，并在收到响应时逐步写回
+func ReqStreamHttp(req *restful.Request, resp *restful.Response, url, method string, body []byte) error {
+	httpReq, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header = req.Request.Header
+	httpReq.Header.Set("Content-Type", "application/json")
+	rid := setRequestID(httpReq.Header)
+
+	rsp, err := NewHttpClient().GetClient().Do(httpReq)
+	if err != nil {
+		blog.Errorf("request %s failed, error: %v, rid: %s", url, err, rid)
+		return err
+	}
+	defer rsp.Body.Close()
+
+	for key, values := range rsp.Header {
+		for _, value := range values {
+			resp.AddHeader(key, value)
+		}
+	}
+	resp.WriteHeader(rsp.StatusCode)
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := rsp.Body.Read(buf)
+		if n > 0 {
+			if _, err := resp.Write(buf[:n]); err != nil {
+				return err
+			}
+			if flusher, ok := resp.ResponseWriter.(http.Flusher); ok {
+				flusher.Flush()
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			blog.Errorf("read response of %s failed, error: %v, rid: %s", url, readErr, rid)
+			return readErr
+		}
+	}
+}
+
 //porxy http
 func ProxyHttp(c *gin.Context, addr string) {
 
 	u, err := url.Parse(addr)
 	if err == nil {
+		setRequestID(c.Request.Header)
 		proxy := httputil.NewSingleHostReverseProxy(u)
+		proxy.Transport = NewTrackedTransport(nil)
 		proxy.ServeHTTP(c.Writer, c.Request)
 	} else {
 		c.Writer.Write([]byte(err.Error()))
 	}
 }
+
+// setRequestID keep the request id of the header, generate one if it is not set
+func setRequestID(header http.Header) string {
+	rid := header.Get(common.BKHTTPRequestID)
+	if "" == rid {
+		rid = util.GenerateRequestID()
+		header.Set(common.BKHTTPRequestID, rid)
+	}
+	return rid
+}
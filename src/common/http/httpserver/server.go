@@ -13,12 +13,18 @@
 package httpserver
 
 import (
+	"configcenter/src/common"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/lifecycle"
+	"configcenter/src/common/metrics"
 	"configcenter/src/common/ssl"
+	"configcenter/src/common/util"
 	"fmt"
 	"net"
 	"net/http"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/emicklei/go-restful"
 )
@@ -34,6 +40,7 @@ type HttpServer struct {
 	keyFile      string
 	certPasswd   string
 	webContainer *restful.Container
+	metricsOnce  sync.Once
 }
 
 func NewHttpServer(port uint, addr, sock string) *HttpServer {
@@ -48,6 +55,7 @@ func NewHttpServer(port uint, addr, sock string) *HttpServer {
 	//		Container:      wsContainer}
 	//	wsContainer.Filter(cors.Filter)
 	//	wsContainer.Filter(wsContainer.OPTIONSFilter)
+	wsContainer.Filter(requestIDFilter)
 	return &HttpServer{
 		addr:         addr,
 		port:         port,
@@ -57,6 +65,27 @@ func NewHttpServer(port uint, addr, sock string) *HttpServer {
 	}
 }
 
+// requestIDFilter accept the request id of the caller or generate a new one,
+// the request id is written back in the response header
+func requestIDFilter(req *restful.Request, resp *restful.Response, fchain *restful.FilterChain) {
+	rid := util.GetActionRequestID(req)
+	if "" == rid {
+		rid = util.GenerateRequestID()
+		req.Request.Header.Set(common.BKHTTPRequestID, rid)
+		// only the entry of the request is logged, the forwarded ones carry the request id
+		blog.Debug("%s %s, rid: %s", req.Request.Method, req.Request.URL.Path, rid)
+	}
+	resp.AddHeader(common.BKHTTPRequestID, rid)
+	fchain.ProcessFilter(req, resp)
+}
+
+// metricsFilter record the latency of the request by the route and the status
+func metricsFilter(req *restful.Request, resp *restful.Response, fchain *restful.FilterChain) {
+	start := time.Now()
+	fchain.ProcessFilter(req, resp)
+	metrics.ObserveRequest(req.SelectedRoutePath(), req.Request.Method, resp.StatusCode(), start)
+}
+
 func (s *HttpServer) SetSsl(cafile, certfile, keyfile, certPasswd string) {
 	s.caFile = cafile
 	s.certFile = certfile
@@ -66,6 +95,11 @@ func (s *HttpServer) SetSsl(cafile, certfile, keyfile, certPasswd string) {
 }
 
 func (s *HttpServer) RegisterWebServer(rootPath string, filter restful.FilterFunction, actions []*Action) error {
+	// the metrics endpoint is served once for all the web services
+	s.metricsOnce.Do(func() {
+		s.webContainer.Handle(metrics.Path, metrics.Handler())
+	})
+
 	//new a web service
 	ws := s.NewWebService(rootPath, filter)
 
@@ -83,6 +117,7 @@ func (s *HttpServer) NewWebService(rootPath string, filter restful.FilterFunctio
 
 	ws.Produces(restful.MIME_JSON)
 
+	ws.Filter(metricsFilter)
 	if nil != filter {
 		ws.Filter(filter)
 	}
@@ -130,6 +165,7 @@ func (s *HttpServer) registerActionsFilter(r *restful.RouteBuilder, filters []re
 	}
 }
 
+// ListenAndServe serve until failed, on shutdown it drains the in-flight requests and blocks until the process exits
 func (s *HttpServer) ListenAndServe() error {
 
 	var chError = make(chan error)
@@ -146,10 +182,10 @@ func (s *HttpServer) ListenAndServe() error {
 			}
 			httpserver.TLSConfig = tlsConf
 			blog.Info("Start https service on(%s)", addrport)
-			chError <- httpserver.ListenAndServeTLS("", "")
+			chError <- lifecycle.Serve(httpserver, func() error { return httpserver.ListenAndServeTLS("", "") })
 		} else {
 			blog.Info("Start http service on(%s)", addrport)
-			chError <- httpserver.ListenAndServe()
+			chError <- lifecycle.Serve(httpserver, httpserver.ListenAndServe)
 		}
 	}()
 
@@ -0,0 +1,227 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package lifecycle
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"configcenter/src/common/blog"
+)
+
+// Stage the order of the shutdown hooks, the hooks of a stage run after all the hooks of the previous stage are done
+type Stage int
+
+const (
+	// StageDeregister remove the server from discovery, so that no new request is routed to it
+	StageDeregister Stage = iota
+	// StageServer stop accepting new connections and drain the in-flight requests
+	StageServer
+	// StageWorker stop the background workers at safe points
+	StageWorker
+
+	stageCount
+)
+
+func (s Stage) String() string {
+	switch s {
+	case StageDeregister:
+		return "deregister"
+	case StageServer:
+		return "server"
+	case StageWorker:
+		return "worker"
+	}
+	return "unknown"
+}
+
+// DefaultTimeout the default deadline of the whole shutdown
+const DefaultTimeout = 30 * time.Second
+
+// Hook stop a component, it should return once the ctx is done
+type Hook func(ctx context.Context) error
+
+type hook struct {
+	name string
+	fn   Hook
+}
+
+// Manager run the shutdown hooks stage by stage once the process receives SIGTERM or SIGINT
+type Manager struct {
+	// Timeout the deadline of the whole shutdown, the hooks still running then are abandoned
+	Timeout time.Duration
+
+	lock     sync.Mutex
+	hooks    [stageCount][]hook
+	stopping chan struct{}
+	stages   [stageCount]chan struct{}
+	done     chan struct{}
+	once     sync.Once
+	signal   sync.Once
+	exit     func(code int)
+}
+
+// NewManager create a shutdown manager
+func NewManager() *Manager {
+	m := &Manager{
+		Timeout:  DefaultTimeout,
+		stopping: make(chan struct{}),
+		done:     make(chan struct{}),
+		exit:     os.Exit,
+	}
+	for stage := range m.stages {
+		m.stages[stage] = make(chan struct{})
+	}
+	return m
+}
+
+// Register add the shutdown hook, the signal handler is installed once the first hook is registered
+func (m *Manager) Register(stage Stage, name string, fn Hook) {
+	m.lock.Lock()
+	m.hooks[stage] = append(m.hooks[stage], hook{name: name, fn: fn})
+	m.lock.Unlock()
+	m.signal.Do(m.notify)
+}
+
+func (m *Manager) notify() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		sig := <-ch
+		blog.Infof("receive signal %s, begin to shutdown", sig.String())
+		m.Shutdown()
+	}()
+}
+
+// Shutdown run the hooks stage by stage and exit the process, the hooks of the same stage run concurrently
+func (m *Manager) Shutdown() {
+	m.once.Do(func() {
+		close(m.stopping)
+		ctx, cancel := context.WithTimeout(context.Background(), m.Timeout)
+		defer cancel()
+
+		for stage := StageDeregister; stage < stageCount; stage++ {
+			m.lock.Lock()
+			hooks := append([]hook(nil), m.hooks[stage]...)
+			m.lock.Unlock()
+			close(m.stages[stage])
+
+			wg := sync.WaitGroup{}
+			for _, h := range hooks {
+				wg.Add(1)
+				go func(h hook) {
+					defer wg.Done()
+					if err := h.fn(ctx); nil != err {
+						blog.Errorf("shutdown %s(%s) failed, err:%s", h.name, stage.String(), err.Error())
+						return
+					}
+					blog.Infof("shutdown %s(%s) done", h.name, stage.String())
+				}(h)
+			}
+			finished := make(chan struct{})
+			go func() {
+				wg.Wait()
+				close(finished)
+			}()
+			select {
+			case <-finished:
+			case <-ctx.Done():
+				blog.Errorf("shutdown stage %s is not finished in %s, abandon it", stage.String(), m.Timeout)
+			}
+		}
+		blog.Info("shutdown finished")
+		blog.CloseLogs()
+		close(m.done)
+		m.exit(0)
+	})
+}
+
+// Stopping return the channel which is closed once the shutdown begins
+func (m *Manager) Stopping() <-chan struct{} {
+	return m.stopping
+}
+
+// ShuttingDown check whether the shutdown begins
+func (m *Manager) ShuttingDown() bool {
+	select {
+	case <-m.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// stageBegun check whether the shutdown reaches the stage
+func (m *Manager) stageBegun(stage Stage) bool {
+	select {
+	case <-m.stages[stage]:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait block until the shutdown is finished, the process exits then.
+// The loops stopped by the shutdown wait here rather than return, as their callers take the return as a failure
+func (m *Manager) Wait() {
+	<-m.done
+}
+
+// Serve run the http server by serve, which is like srv.ListenAndServe,
+// the server is shut down gracefully in the server stage
+func (m *Manager) Serve(srv *http.Server, serve func() error) error {
+	m.Register(StageServer, "http server "+srv.Addr, srv.Shutdown)
+	err := serve()
+	if http.ErrServerClosed == err && m.ShuttingDown() {
+		m.Wait()
+		return nil
+	}
+	return err
+}
+
+var defaultManager = NewManager()
+
+// Register add the shutdown hook to the default manager
+func Register(stage Stage, name string, fn Hook) {
+	defaultManager.Register(stage, name, fn)
+}
+
+// Shutdown shutdown by the default manager
+func Shutdown() {
+	defaultManager.Shutdown()
+}
+
+// Stopping return the channel which is closed once the default manager begins to shutdown
+func Stopping() <-chan struct{} {
+	return defaultManager.Stopping()
+}
+
+// ShuttingDown check whether the default manager begins to shutdown
+func ShuttingDown() bool {
+	return defaultManager.ShuttingDown()
+}
+
+// Wait block until the shutdown of the default manager is finished
+func Wait() {
+	defaultManager.Wait()
+}
+
+// Serve run the http server which is shut down gracefully by the default manager
+func Serve(srv *http.Server, serve func() error) error {
+	return defaultManager.Serve(srv, serve)
+}
@@ -0,0 +1,137 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package lifecycle
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManager() (*Manager, chan int) {
+	m := NewManager()
+	exited := make(chan int, 1)
+	m.exit = func(code int) { exited <- code }
+	// no signal handler in tests
+	m.signal.Do(func() {})
+	return m, exited
+}
+
+func TestShutdownStages(t *testing.T) {
+	m, exited := newTestManager()
+	lock := sync.Mutex{}
+	order := make([]string, 0)
+	record := func(name string) Hook {
+		return func(ctx context.Context) error {
+			lock.Lock()
+			defer lock.Unlock()
+			order = append(order, name)
+			return nil
+		}
+	}
+	m.Register(StageWorker, "worker", record("worker"))
+	m.Register(StageServer, "server", record("server"))
+	m.Register(StageDeregister, "discovery", record("discovery"))
+
+	assert.False(t, m.ShuttingDown())
+	m.Shutdown()
+	assert.True(t, m.ShuttingDown())
+	assert.Equal(t, []string{"discovery", "server", "worker"}, order)
+	assert.Equal(t, 0, <-exited)
+
+	// shutdown only once
+	m.Shutdown()
+	assert.Len(t, order, 3)
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	m, exited := newTestManager()
+	m.Timeout = 50 * time.Millisecond
+	m.Register(StageServer, "stuck", func(ctx context.Context) error {
+		time.Sleep(time.Minute)
+		return nil
+	})
+	start := time.Now()
+	m.Shutdown()
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Equal(t, 0, <-exited)
+}
+
+func TestServeDrain(t *testing.T) {
+	m, exited := newTestManager()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	entered := make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("done"))
+	})}
+	served := make(chan error, 1)
+	go func() {
+		served <- m.Serve(srv, func() error { return srv.Serve(ln) })
+	}()
+
+	body := make(chan string, 1)
+	go func() {
+		rsp, err := http.Get("http://" + ln.Addr().String())
+		if nil != err {
+			body <- err.Error()
+			return
+		}
+		defer rsp.Body.Close()
+		data, _ := ioutil.ReadAll(rsp.Body)
+		body <- string(data)
+	}()
+	<-entered
+
+	m.Shutdown()
+	// the in-flight request is finished before the exit
+	assert.Equal(t, "done", <-body)
+	assert.Equal(t, 0, <-exited)
+	assert.NoError(t, <-served)
+
+	_, err = http.Get("http://" + ln.Addr().String())
+	assert.Error(t, err)
+}
+
+func TestWorker(t *testing.T) {
+	m, exited := newTestManager()
+	w := m.NewWorker("test")
+	require.True(t, w.Begin())
+
+	ended := make(chan struct{})
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		close(ended)
+		w.End()
+	}()
+
+	m.Shutdown()
+	select {
+	case <-ended:
+	default:
+		t.Error("the shutdown does not wait for the running work")
+	}
+	assert.True(t, w.Stopping())
+	assert.False(t, w.Begin())
+	assert.Equal(t, 0, <-exited)
+}
@@ -0,0 +1,91 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package lifecycle
+
+import (
+	"context"
+	"sync"
+)
+
+// Worker a background loop which stops at a safe point on shutdown.
+// Each unit of work, such as an event, is wrapped by Begin and End,
+// the worker stage waits for the running units and no new unit begins then
+type Worker struct {
+	m       *Manager
+	lock    sync.Mutex
+	running int
+	idle    chan struct{}
+}
+
+// NewWorker create the worker of the manager
+func (m *Manager) NewWorker(name string) *Worker {
+	w := &Worker{m: m}
+	m.Register(StageWorker, name, w.wait)
+	return w
+}
+
+// NewWorker create the worker of the default manager
+func NewWorker(name string) *Worker {
+	return defaultManager.NewWorker(name)
+}
+
+// Stopping check whether the shutdown reaches the worker stage
+func (w *Worker) Stopping() bool {
+	return w.m.stageBegun(StageWorker)
+}
+
+// Begin mark a unit of work begins, return false if the worker is stopping and the unit must not begin
+func (w *Worker) Begin() bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.Stopping() {
+		return false
+	}
+	w.running++
+	return true
+}
+
+// End mark a unit of work ends
+func (w *Worker) End() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.running--
+	if 0 == w.running && nil != w.idle {
+		close(w.idle)
+		w.idle = nil
+	}
+}
+
+// Park stop the loop, it blocks until the shutdown is finished
+func (w *Worker) Park() {
+	w.m.Wait()
+}
+
+// wait the running units end
+func (w *Worker) wait(ctx context.Context) error {
+	w.lock.Lock()
+	if 0 == w.running {
+		w.lock.Unlock()
+		return nil
+	}
+	idle := make(chan struct{})
+	w.idle = idle
+	w.lock.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
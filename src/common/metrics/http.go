@@ -0,0 +1,42 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Path the path of the metrics endpoint
+const Path = "/metrics"
+
+var (
+	requestTotal    = NewCounterVec("cc_http_requests_total", "the http requests served, by route, method and status", "route", "method", "code")
+	requestDuration = NewHistogramVec("cc_http_request_duration_seconds", "the latency of the http requests served, by route, method and status", nil, "route", "method", "code")
+)
+
+// ObserveRequest record the served request, route is the registered path pattern rather than the real path
+func ObserveRequest(route, method string, code int, start time.Time) {
+	status := strconv.Itoa(code)
+	requestTotal.Inc(route, method, status)
+	requestDuration.Since(start, route, method, status)
+}
+
+// Handler serve the metrics of the default registry in the prometheus text format
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		DefaultRegistry.Write(w)
+	})
+}
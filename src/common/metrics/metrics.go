@@ -0,0 +1,377 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefBuckets the default latency buckets in seconds
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Collector a metric family which can be written in the prometheus text format
+type Collector interface {
+	Name() string
+	Write(w io.Writer) error
+}
+
+// Registry keep the collectors
+type Registry struct {
+	lock       sync.RWMutex
+	collectors map[string]Collector
+}
+
+// NewRegistry create an empty registry
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]Collector)}
+}
+
+// DefaultRegistry the registry which the New* functions register to
+var DefaultRegistry = NewRegistry()
+
+// Register add the collector, the name must be unique
+func (r *Registry) Register(c Collector) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.collectors[c.Name()]; ok {
+		return fmt.Errorf("duplicate metric %s", c.Name())
+	}
+	r.collectors[c.Name()] = c
+	return nil
+}
+
+// MustRegister add the collector, panic if the name is registered already
+func (r *Registry) MustRegister(c Collector) {
+	if err := r.Register(c); nil != err {
+		panic(err)
+	}
+}
+
+// Write write all the metrics ordered by name in the prometheus text format
+func (r *Registry) Write(w io.Writer) error {
+	r.lock.RLock()
+	collectors := make([]Collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.lock.RUnlock()
+	sort.Slice(collectors, func(i, j int) bool { return collectors[i].Name() < collectors[j].Name() })
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		if err := c.Write(bw); nil != err {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ContentType the content type of the prometheus text format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type desc struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+}
+
+func (d *desc) Name() string {
+	return d.name
+}
+
+func (d *desc) writeHeader(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, strings.Replace(d.help, "\n", " ", -1), d.name, d.typ)
+	return err
+}
+
+func (d *desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metric %s needs %d label values, got %d", d.name, len(d.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// labelString format the labels like {a="1",b="2"}, extra is appended as is
+func labelString(names, values []string, extra string) string {
+	parts := make([]string, 0, len(names)+1)
+	for i, name := range names {
+		parts = append(parts, name+"=\""+escape(values[i])+"\"")
+	}
+	if "" != extra {
+		parts = append(parts, extra)
+	}
+	if 0 == len(parts) {
+		return ""
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func escape(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(value)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+type series struct {
+	values []string
+	value  float64
+}
+
+// vec keep a value for each label values
+type vec struct {
+	desc
+	lock   sync.Mutex
+	series map[string]*series
+}
+
+func (v *vec) add(delta float64, values []string) {
+	key := v.key(values)
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{values: append([]string(nil), values...)}
+		v.series[key] = s
+	}
+	s.value += delta
+}
+
+func (v *vec) set(value float64, values []string) {
+	key := v.key(values)
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{values: append([]string(nil), values...)}
+		v.series[key] = s
+	}
+	s.value = value
+}
+
+// Delete remove the series of the label values
+func (v *vec) Delete(values ...string) {
+	key := v.key(values)
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	delete(v.series, key)
+}
+
+func (v *vec) Write(w io.Writer) error {
+	v.lock.Lock()
+	samples := make([]series, 0, len(v.series))
+	for _, s := range v.series {
+		samples = append(samples, *s)
+	}
+	v.lock.Unlock()
+	return writeSamples(w, &v.desc, samples)
+}
+
+func writeSamples(w io.Writer, d *desc, samples []series) error {
+	if err := d.writeHeader(w); nil != err {
+		return err
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return strings.Join(samples[i].values, "\xff") < strings.Join(samples[j].values, "\xff")
+	})
+	for _, s := range samples {
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", d.name, labelString(d.labels, s.values, ""), formatFloat(s.value)); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// CounterVec the counters partitioned by the labels
+type CounterVec struct {
+	vec
+}
+
+// NewCounterVec create and register a counter
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{vec{desc: desc{name: name, help: help, typ: "counter", labels: labels}, series: make(map[string]*series)}}
+	DefaultRegistry.MustRegister(c)
+	return c
+}
+
+// Inc increase the counter of the label values by 1
+func (c *CounterVec) Inc(values ...string) {
+	c.add(1, values)
+}
+
+// Add increase the counter of the label values, delta must not be negative
+func (c *CounterVec) Add(delta float64, values ...string) {
+	if delta < 0 {
+		return
+	}
+	c.add(delta, values)
+}
+
+// GaugeVec the gauges partitioned by the labels
+type GaugeVec struct {
+	vec
+}
+
+// NewGaugeVec create and register a gauge
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{vec{desc: desc{name: name, help: help, typ: "gauge", labels: labels}, series: make(map[string]*series)}}
+	DefaultRegistry.MustRegister(g)
+	return g
+}
+
+// Set the gauge of the label values
+func (g *GaugeVec) Set(value float64, values ...string) {
+	g.set(value, values)
+}
+
+// Add change the gauge of the label values by delta
+func (g *GaugeVec) Add(delta float64, values ...string) {
+	g.add(delta, values)
+}
+
+// Sample the value of the label values
+type Sample struct {
+	Values []string
+	Value  float64
+}
+
+// GaugeFunc the gauge whose samples are collected on each scrape
+type GaugeFunc struct {
+	desc
+	collect func() []Sample
+}
+
+// NewGaugeFunc create and register a gauge collected by the function on each scrape
+func NewGaugeFunc(name, help string, labels []string, collect func() []Sample) *GaugeFunc {
+	g := &GaugeFunc{desc: desc{name: name, help: help, typ: "gauge", labels: labels}, collect: collect}
+	DefaultRegistry.MustRegister(g)
+	return g
+}
+
+func (g *GaugeFunc) Write(w io.Writer) error {
+	samples := make([]series, 0)
+	for _, s := range g.collect() {
+		g.key(s.Values)
+		samples = append(samples, series{values: s.Values, value: s.Value})
+	}
+	return writeSamples(w, &g.desc, samples)
+}
+
+type histogram struct {
+	values []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// HistogramVec the histograms partitioned by the labels
+type HistogramVec struct {
+	desc
+	buckets []float64
+	lock    sync.Mutex
+	series  map[string]*histogram
+}
+
+// NewHistogramVec create and register a histogram, DefBuckets is used if buckets is empty
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if 0 == len(buckets) {
+		buckets = DefBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	h := &HistogramVec{
+		desc:    desc{name: name, help: help, typ: "histogram", labels: labels},
+		buckets: buckets,
+		series:  make(map[string]*histogram),
+	}
+	DefaultRegistry.MustRegister(h)
+	return h
+}
+
+// Observe add the value to the histogram of the label values
+func (h *HistogramVec) Observe(value float64, values ...string) {
+	key := h.key(values)
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogram{values: append([]string(nil), values...), counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+// Since observe the seconds elapsed since the start
+func (h *HistogramVec) Since(start time.Time, values ...string) {
+	h.Observe(time.Since(start).Seconds(), values...)
+}
+
+func (h *HistogramVec) Write(w io.Writer) error {
+	h.lock.Lock()
+	samples := make([]histogram, 0, len(h.series))
+	for _, s := range h.series {
+		copied := *s
+		copied.counts = append([]uint64(nil), s.counts...)
+		samples = append(samples, copied)
+	}
+	h.lock.Unlock()
+
+	if err := h.writeHeader(w); nil != err {
+		return err
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return strings.Join(samples[i].values, "\xff") < strings.Join(samples[j].values, "\xff")
+	})
+	for _, s := range samples {
+		for i, bound := range h.buckets {
+			le := "le=\"" + formatFloat(bound) + "\""
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labelString(h.labels, s.values, le), s.counts[i]); nil != err {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labelString(h.labels, s.values, "le=\"+Inf\""), s.count); nil != err {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s_sum%s %s\n", h.name, labelString(h.labels, s.values, ""), formatFloat(s.sum)); nil != err {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s_count%s %d\n", h.name, labelString(h.labels, s.values, ""), s.count); nil != err {
+			return err
+		}
+	}
+	return nil
+}
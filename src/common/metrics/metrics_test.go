@@ -0,0 +1,124 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCounterVec(t *testing.T) {
+	c := NewCounterVec("test_counter_total", "test counter", "code")
+	c.Inc("200")
+	c.Add(2, "200")
+	c.Add(-1, "200")
+	c.Inc("say \"hi\"\n")
+
+	buf := &bytes.Buffer{}
+	if err := c.Write(buf); nil != err {
+		t.Fatal(err)
+	}
+	expect := "# HELP test_counter_total test counter\n" +
+		"# TYPE test_counter_total counter\n" +
+		"test_counter_total{code=\"200\"} 3\n" +
+		"test_counter_total{code=\"say \\\"hi\\\"\\n\"} 1\n"
+	if buf.String() != expect {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+
+	c.Delete("200")
+	buf.Reset()
+	c.Write(buf)
+	if strings.Contains(buf.String(), "code=\"200\"") {
+		t.Errorf("deleted series still written:\n%s", buf.String())
+	}
+}
+
+func TestGaugeFunc(t *testing.T) {
+	g := NewGaugeFunc("test_gauge_func", "test gauge", []string{"queue"}, func() []Sample {
+		return []Sample{{Values: []string{"b"}, Value: 2}, {Values: []string{"a"}, Value: 1.5}}
+	})
+	buf := &bytes.Buffer{}
+	if err := g.Write(buf); nil != err {
+		t.Fatal(err)
+	}
+	expect := "# HELP test_gauge_func test gauge\n" +
+		"# TYPE test_gauge_func gauge\n" +
+		"test_gauge_func{queue=\"a\"} 1.5\n" +
+		"test_gauge_func{queue=\"b\"} 2\n"
+	if buf.String() != expect {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestHistogramVec(t *testing.T) {
+	h := NewHistogramVec("test_histogram_seconds", "test histogram", []float64{1, 0.5}, "op")
+	h.Observe(0.25, "find")
+	h.Observe(0.75, "find")
+	h.Observe(3, "find")
+
+	buf := &bytes.Buffer{}
+	if err := h.Write(buf); nil != err {
+		t.Fatal(err)
+	}
+	expect := "# HELP test_histogram_seconds test histogram\n" +
+		"# TYPE test_histogram_seconds histogram\n" +
+		"test_histogram_seconds_bucket{op=\"find\",le=\"0.5\"} 1\n" +
+		"test_histogram_seconds_bucket{op=\"find\",le=\"1\"} 2\n" +
+		"test_histogram_seconds_bucket{op=\"find\",le=\"+Inf\"} 3\n" +
+		"test_histogram_seconds_sum{op=\"find\"} 4\n" +
+		"test_histogram_seconds_count{op=\"find\"} 3\n"
+	if buf.String() != expect {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	c := &CounterVec{vec{desc: desc{name: "b_total", typ: "counter"}, series: make(map[string]*series)}}
+	g := &GaugeVec{vec{desc: desc{name: "a", typ: "gauge"}, series: make(map[string]*series)}}
+	if err := r.Register(c); nil != err {
+		t.Fatal(err)
+	}
+	if err := r.Register(g); nil != err {
+		t.Fatal(err)
+	}
+	if err := r.Register(c); nil == err {
+		t.Error("duplicate metric should be rejected")
+	}
+	c.Inc()
+	g.Set(4)
+
+	buf := &bytes.Buffer{}
+	if err := r.Write(buf); nil != err {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "# HELP a") || !strings.Contains(buf.String(), "\nb_total 1\n") || !strings.Contains(buf.String(), "\na 4\n") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestHandler(t *testing.T) {
+	ObserveRequest("/test/{id}", "GET", 200, time.Now())
+	rsp := httptest.NewRecorder()
+	Handler().ServeHTTP(rsp, httptest.NewRequest("GET", Path, nil))
+	if rsp.Header().Get("Content-Type") != ContentType {
+		t.Errorf("unexpected content type %s", rsp.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rsp.Body.String(), "cc_http_requests_total{route=\"/test/{id}\",method=\"GET\",code=\"200\"} 1") {
+		t.Errorf("request not recorded:\n%s", rsp.Body.String())
+	}
+}
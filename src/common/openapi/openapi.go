@@ -0,0 +1,174 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Version the openapi specification version of the document
+const Version = "3.0.0"
+
+// MIMEJSON the media type of the request and response body
+const MIMEJSON = "application/json"
+
+// Document the openapi document
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info the metadata of the api
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Server the server which the paths are relative to
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem the operations of a path, keyed by the lower case http method
+type PathItem map[string]*Operation
+
+// Operation a single api operation on a path
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter a path, query or header parameter of the operation
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody the request body of the operation
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response the response of the operation
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType the schema of the body in a media type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components the reusable schemas referenced by the operations
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+var pathParamRegexp = regexp.MustCompile(`\{([^}]+)\}`)
+
+// NewDocument create an empty document
+func NewDocument(title, version string) *Document {
+	return &Document{
+		OpenAPI:    Version,
+		Info:       Info{Title: title, Version: version},
+		Paths:      make(map[string]*PathItem),
+		Components: Components{Schemas: make(map[string]*Schema)},
+	}
+}
+
+// AddOperation add the operation to the path, the path parameters which are not described are added as string,
+// the operation id is generated from the method and path if empty
+func (d *Document) AddOperation(method, path string, op *Operation) {
+	method = strings.ToLower(method)
+	for _, match := range pathParamRegexp.FindAllStringSubmatch(path, -1) {
+		if nil == op.findParameter(match[1], "path") {
+			op.Parameters = append(op.Parameters, &Parameter{Name: match[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+	if "" == op.OperationID {
+		op.OperationID = OperationID(method, path)
+	}
+	if nil == op.Responses {
+		op.Responses = map[string]*Response{"200": {Description: "success"}}
+	}
+
+	item, ok := d.Paths[path]
+	if false == ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+	(*item)[method] = op
+}
+
+// AddServer add the server url which the paths are relative to, e.g. the root path the api is registered under
+func (d *Document) AddServer(url string) {
+	d.Servers = append(d.Servers, Server{URL: url})
+}
+
+// Operation get the operation of the method on the path, nil if not exists
+func (d *Document) Operation(method, path string) *Operation {
+	item, ok := d.Paths[path]
+	if false == ok {
+		return nil
+	}
+	return (*item)[strings.ToLower(method)]
+}
+
+// OperationID generate the operation id from the method and path, e.g. post_hosts_favorites_by_id_incr
+func OperationID(method, path string) string {
+	words := []string{strings.ToLower(method)}
+	for _, seg := range strings.Split(path, "/") {
+		if "" == seg {
+			continue
+		}
+		if match := pathParamRegexp.FindStringSubmatch(seg); nil != match {
+			seg = fmt.Sprintf("by_%s", match[1])
+		}
+		words = append(words, strings.Map(func(r rune) rune {
+			if ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+				return r
+			}
+			return '_'
+		}, seg))
+	}
+	return strings.Join(words, "_")
+}
+
+func (op *Operation) findParameter(name, in string) *Parameter {
+	for _, param := range op.Parameters {
+		if name == param.Name && in == param.In {
+			return param
+		}
+	}
+	return nil
+}
+
+// JSONContent the json content of the schema
+func JSONContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{MIMEJSON: {Schema: schema}}
+}
@@ -0,0 +1,133 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type page struct {
+	Start int    `json:"start"`
+	Sort  string `json:"sort,omitempty"`
+}
+
+type stamp struct {
+	time.Time
+}
+
+type base struct {
+	Owner string `json:"bk_supplier_account"`
+}
+
+type search struct {
+	base
+	Condition map[string]interface{} `json:"condition"`
+	Page      page                   `json:"page"`
+	Fields    []string               `json:"fields"`
+	Created   time.Time              `json:"create_time"`
+	Modified  *stamp                 `json:"last_time"`
+	ID        int64                  `json:"id,string"`
+	Parent    *search                `json:"parent,omitempty"`
+	Ignored   string                 `json:"-"`
+	hidden    string
+}
+
+func TestSchema(t *testing.T) {
+	doc := NewDocument("test", "v1")
+	schema := doc.Schema(&search{})
+	if "#/components/schemas/openapi.search" != schema.Ref {
+		t.Fatalf("unexpected ref %q", schema.Ref)
+	}
+
+	obj := doc.Components.Schemas["openapi.search"]
+	if nil == obj || "object" != obj.Type {
+		t.Fatalf("search schema not registered: %#v", obj)
+	}
+	expected := map[string]string{
+		"bk_supplier_account": "string",
+		"condition":           "object",
+		"fields":              "array",
+		"create_time":         "string",
+		"last_time":           "string",
+		"id":                  "string",
+	}
+	for name, typ := range expected {
+		prop, ok := obj.Properties[name]
+		if false == ok || typ != prop.Type {
+			t.Errorf("property %s expected type %s, got %#v", name, typ, prop)
+		}
+	}
+	if "#/components/schemas/openapi.page" != obj.Properties["page"].Ref {
+		t.Errorf("page should be referenced, got %#v", obj.Properties["page"])
+	}
+	if "#/components/schemas/openapi.search" != obj.Properties["parent"].Ref {
+		t.Errorf("recursive reference expected, got %#v", obj.Properties["parent"])
+	}
+	if "integer" != doc.Components.Schemas["openapi.page"].Properties["start"].Type {
+		t.Errorf("page start should be integer")
+	}
+	for _, name := range []string{"Ignored", "hidden", "base"} {
+		if _, ok := obj.Properties[name]; ok {
+			t.Errorf("property %s should not be generated", name)
+		}
+	}
+	if s := doc.Schema(nil); "" != s.Type || "" != s.Ref {
+		t.Errorf("nil sample should generate an empty schema, got %#v", s)
+	}
+}
+
+func TestAddOperation(t *testing.T) {
+	doc := NewDocument("test", "v1")
+	doc.AddOperation("POST", "/v3/hosts/favorites/{id}/incr", &Operation{Summary: "incr"})
+	doc.AddOperation("GET", "/v3/hosts/favorites/{id}/incr", &Operation{
+		Parameters: []*Parameter{{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer"}}},
+	})
+
+	op := doc.Operation("post", "/v3/hosts/favorites/{id}/incr")
+	if nil == op {
+		t.Fatal("operation not added")
+	}
+	if "post_v3_hosts_favorites_by_id_incr" != op.OperationID {
+		t.Errorf("unexpected operation id %s", op.OperationID)
+	}
+	if 1 != len(op.Parameters) || "id" != op.Parameters[0].Name || "path" != op.Parameters[0].In || false == op.Parameters[0].Required {
+		t.Errorf("path parameter not generated: %#v", op.Parameters)
+	}
+	if _, ok := op.Responses["200"]; false == ok {
+		t.Errorf("default response not generated")
+	}
+
+	get := doc.Operation("GET", "/v3/hosts/favorites/{id}/incr")
+	if 1 != len(get.Parameters) || "integer" != get.Parameters[0].Schema.Type {
+		t.Errorf("described path parameter should be kept: %#v", get.Parameters)
+	}
+
+	doc.AddServer("/api")
+	data, err := json.Marshal(doc)
+	if nil != err {
+		t.Fatal(err)
+	}
+	out := map[string]interface{}{}
+	if err := json.Unmarshal(data, &out); nil != err {
+		t.Fatal(err)
+	}
+	if Version != out["openapi"] {
+		t.Errorf("unexpected openapi version %v", out["openapi"])
+	}
+	servers, _ := out["servers"].([]interface{})
+	if 1 != len(servers) || "/api" != servers[0].(map[string]interface{})["url"] {
+		t.Errorf("unexpected servers %v", out["servers"])
+	}
+}
@@ -0,0 +1,138 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema the json schema of a value
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Schema generate the schema of the sample value, the named struct is added to the components and referenced,
+// the nil value generate an empty schema which accepts any value
+func (d *Document) Schema(sample interface{}) *Schema {
+	if nil == sample {
+		return &Schema{}
+	}
+	return d.schemaOf(reflect.TypeOf(sample))
+}
+
+func (d *Document) schemaOf(t reflect.Type) *Schema {
+	for reflect.Ptr == t.Kind() {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if reflect.Uint8 == t.Elem().Kind() {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: d.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if timeType == t || isTimeWrapper(t) {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+		if "" == t.Name() {
+			return d.structSchema(t)
+		}
+		name := strings.Replace(t.String(), "*", "", -1)
+		if _, ok := d.Components.Schemas[name]; false == ok {
+			// reserve the name before walking the fields, so a recursive reference stops here
+			d.Components.Schemas[name] = &Schema{Type: "object"}
+			d.Components.Schemas[name] = d.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+// isTimeWrapper the struct which only embeds the time.Time, e.g. types.Time, it is marshaled as the time
+func isTimeWrapper(t reflect.Type) bool {
+	return 1 == t.NumField() && t.Field(0).Anonymous && timeType == t.Field(0).Type
+}
+
+// structSchema generate the object schema of the exported fields, named by the json tag
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if "" != field.PkgPath && false == field.Anonymous {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if "-" == tag {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); 0 <= idx {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+
+		if field.Anonymous && "" == name {
+			ft := field.Type
+			if reflect.Ptr == ft.Kind() {
+				ft = ft.Elem()
+			}
+			if reflect.Struct == ft.Kind() {
+				for key, prop := range d.structSchema(ft).Properties {
+					if _, ok := schema.Properties[key]; false == ok {
+						schema.Properties[key] = prop
+					}
+				}
+				continue
+			}
+			if "" != field.PkgPath {
+				continue
+			}
+		}
+
+		if "" == name {
+			name = field.Name
+		}
+		if strings.Contains(opts, "string") {
+			schema.Properties[name] = &Schema{Type: "string"}
+			continue
+		}
+		schema.Properties[name] = d.schemaOf(field.Type)
+	}
+	return schema
+}
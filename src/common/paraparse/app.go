@@ -14,11 +14,8 @@ package params
 
 import (
 	"configcenter/src/common"
+	"configcenter/src/common/querydsl"
 	"errors"
-	"fmt"
-	"reflect"
-	"regexp"
-	"strings"
 )
 
 //common search struct
@@ -37,65 +34,65 @@ type CommonResult struct {
 	Data    interface{} `json:"data"`
 }
 
-func ParseCommonParams(input []interface{}, output map[string]interface{}) error {
+// ParseCommonParams convert the {field, operator, value} conditions to the db condition,
+// the string compared by $eq is matched as plain text like before
+func ParseCommonParams(input []interface{}, schema querydsl.Schema, output map[string]interface{}) error {
+	expr, err := parseConditions(input, true)
+	if nil != err {
+		return err
+	}
+	return compileTo(expr, schema, output)
+}
+
+// ParseAppSearchParams convert the {field: value} condition to the db condition, the string is matched as plain text
+func ParseAppSearchParams(input map[string]interface{}, schema querydsl.Schema) (map[string]interface{}, error) {
+	expr, err := querydsl.ParseMap(input, true)
+	if nil != err {
+		return nil, err
+	}
+	return querydsl.Compile(expr, schema)
+}
+
+// parseConditions convert the {field, operator, value} conditions to the expression,
+// the string compared by $eq is matched by contains if fuzzyEq
+func parseConditions(input []interface{}, fuzzyEq bool) (*querydsl.Expr, error) {
+	exprs := make([]*querydsl.Expr, 0, len(input))
 	for _, i := range input {
 		j, ok := i.(map[string]interface{})
 		if false == ok {
-			return errors.New("condition error")
+			return nil, errors.New("condition error")
 		}
 		field, ok := j["field"].(string)
 		if false == ok {
-			return errors.New("condition error")
+			return nil, errors.New("condition error")
 		}
 		operator, ok := j["operator"].(string)
 		if false == ok {
-			return errors.New("condition error")
+			return nil, errors.New("condition error")
 		}
 		value := j["value"]
-		switch operator {
-		case common.BKDBEQ:
-			objtype := reflect.TypeOf(value)
-			switch objtype.Kind() {
-			case reflect.Int:
-				output[field] = value
-			case reflect.Float64:
-				output[field] = value
-			case reflect.Float32:
-				output[field] = value
-			default:
-				d := make(map[string]interface{})
-				d[common.BKDBLIKE] = value
-				output[field] = d
-			}
 
-		default:
-			d := make(map[string]interface{})
-			d[operator] = value
-			output[field] = d
+		if _, isStr := value.(string); fuzzyEq && isStr && common.BKDBEQ == operator {
+			exprs = append(exprs, querydsl.NewExpr(querydsl.OpContains, field, value))
+			continue
 		}
-
+		expr, err := querydsl.ParseOperator(field, operator, value)
+		if nil != err {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
 	}
-	return nil
+	return querydsl.And(exprs...), nil
 }
 
-func ParseAppSearchParams(input map[string]interface{}) map[string]interface{} {
-	output := make(map[string]interface{})
-	for i, j := range input {
-		objtype := reflect.TypeOf(j)
-		switch objtype.Kind() {
-		case reflect.String:
-			d := make(map[string]interface{})
-			targetStr := j.(string)
-			re := regexp.MustCompile("([\\^\\$\\(\\)\\*\\+\\?\\.\\\\\\|\\[\\]\\{\\}])")
-			delItems := re.FindAllString(targetStr, -1)
-			for _, target := range delItems {
-				targetStr = strings.Replace(targetStr, target, fmt.Sprintf("\\\\%s", target), -1)
-			}
-			d[common.BKDBLIKE] = targetStr
-			output[i] = d
-		default:
-			output[i] = j
-		}
+// compileTo compile the expression and merge the db condition into the output
+func compileTo(expr *querydsl.Expr, schema querydsl.Schema, output map[string]interface{}) error {
+	cond, err := querydsl.Compile(expr, schema)
+	if nil != err {
+		return err
+	}
+	for key, val := range cond {
+		output[key] = val
 	}
-	return output
+	return nil
 }
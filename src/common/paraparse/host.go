@@ -14,9 +14,10 @@ package params
 
 import (
 	"configcenter/src/common"
+	"configcenter/src/common/querydsl"
 	"configcenter/src/common/util"
-	"errors"
-	"fmt"
+	"regexp"
+	"strings"
 )
 
 //type Flag string
@@ -48,6 +49,12 @@ type PageInfo struct {
 	Start int    `json:"start"`
 	Limit int    `json:"limit"`
 	Sort  string `json:"sort"`
+	// Cursor the next_cursor of the last page, the page after it is returned instead of skipping the start
+	Cursor string `json:"cursor,omitempty"`
+	// WithCursor page by the cursor from the first page, the next_cursor is returned with it
+	WithCursor bool `json:"with_cursor,omitempty"`
+	// SkipCount do not count the matched items
+	SkipCount bool `json:"skip_count,omitempty"`
 }
 
 //search condition
@@ -57,51 +64,54 @@ type SearchCondition struct {
 	ObjectID  string        `json:"bk_obj_id"`
 }
 
-func ParseHostParams(input []interface{}, output map[string]interface{}) error {
-	fmt.Println(input)
-	for _, i := range input {
-		j, ok := i.(map[string]interface{})
-		if false == ok {
-			return errors.New("condition error")
-		}
-		field, ok := j["field"].(string)
-		if false == ok {
-			return errors.New("condition error")
-		}
-		operator, ok := j["operator"].(string)
-		if false == ok {
-			return errors.New("condition error")
-		}
-		value := j["value"]
-
-		switch operator {
-		case common.BKDBEQ:
-			output[field] = value
-		case common.BKDBIN:
-			d := make(map[string]interface{})
-			d[operator] = value
-			output[field] = d
-		default:
-			d := make(map[string]interface{})
-			switch value.(type) {
-			case string:
-
-				valStr := value.(string)
-				if util.IsTime(valStr) {
-
-					value = util.Str2Time(valStr)
-				}
+// ParseHostParams convert the {field, operator, value} conditions of the host search to the db condition
+func ParseHostParams(input []interface{}, schema querydsl.Schema, output map[string]interface{}) error {
+	expr, err := parseConditions(input, false)
+	if nil != err {
+		return err
+	}
+	return compileTo(expr, schema, output)
+}
 
+// IPSearchCondition return the db condition matching the hosts with one of the ips in the indexed ip arrays of the fields,
+// the exact ips are matched by $in and the cidrs such as 10.0.0.0/8 by the anchored regular of the ips in the network
+func IPSearchCondition(ips []string, fields ...string) (map[string]interface{}, error) {
+	exacts := make([]string, 0, len(ips))
+	patterns := make([]string, 0)
+	for _, ip := range ips {
+		if !strings.Contains(ip, "/") {
+			canonical, err := util.NormalizeIP(ip)
+			if nil != err {
+				return nil, err
 			}
-			d[operator] = value
-			output[field] = d
+			exacts = append(exacts, canonical)
+			continue
+		}
+		pattern, err := util.IPSearchPattern(ip)
+		if nil != err {
+			return nil, err
 		}
+		patterns = append(patterns, "^("+pattern+")$")
+	}
 
+	orCond := make([]map[string]interface{}, 0)
+	for _, field := range fields {
+		listField := util.HostIPListFields[field]
+		if 0 != len(exacts) {
+			orCond = append(orCond, map[string]interface{}{listField: map[string]interface{}{common.BKDBIN: exacts}})
+		}
+		for _, pattern := range patterns {
+			orCond = append(orCond, map[string]interface{}{listField: map[string]interface{}{common.BKDBLIKE: pattern}})
+		}
 	}
-	fmt.Println(output)
-	return nil
+	if 1 == len(orCond) {
+		return orCond[0], nil
+	}
+	return map[string]interface{}{common.BKDBOR: orCond}, nil
 }
 
+// ParseHostIPParams convert the ip search to the db condition, the exact search matches the canonical ipv4 or ipv6
+// in the ip arrays of the host, and the cidr such as 10.0.0.0/8 matches the ips in the network
 func ParseHostIPParams(ipCond IPInfo, output map[string]interface{}) error {
 	ipArr := ipCond.Data
 	exact := ipCond.Exact
@@ -111,33 +121,30 @@ func ParseHostIPParams(ipCond IPInfo, output map[string]interface{}) error {
 	}
 	if 1 == exact {
 		//exact search
-		c := make(map[string]interface{})
-		c[common.BKDBIN] = ipArr
+		fields := make([]string, 0)
 		if INNERONLY == flag {
-			output[common.BKHostInnerIPField] = c
-
+			fields = append(fields, common.BKHostInnerIPField)
 		} else if OUTERONLY == flag {
-			output[common.BKHostOuterIPField] = c
+			fields = append(fields, common.BKHostOuterIPField)
 		} else if IOBOTH == flag {
-			io := make([]map[string]interface{}, 2)
-			i := make(map[string]interface{})
-			o := make(map[string]interface{})
-			ic := make(map[string]interface{})
-			oc := make(map[string]interface{})
-			i[common.BKDBIN] = ipArr
-			o[common.BKDBIN] = ipArr
-			ic[common.BKHostInnerIPField] = i
-			oc[common.BKHostOuterIPField] = o
-			io[0] = ic
-			io[1] = oc
-			output[common.BKDBOR] = io
+			fields = append(fields, common.BKHostInnerIPField, common.BKHostOuterIPField)
+		}
+		if 0 == len(fields) {
+			return nil
+		}
+		c, err := IPSearchCondition(ipArr, fields...)
+		if nil != err {
+			return err
+		}
+		for key, val := range c {
+			output[key] = val
 		}
 	} else {
 		//not exact search
 		orCond := make([]map[string]map[string]interface{}, 0)
 		for _, ip := range ipArr {
 			c := make(map[string]interface{})
-			c[common.BKDBLIKE] = ip
+			c[common.BKDBLIKE] = regexp.QuoteMeta(strings.ToLower(ip))
 			if INNERONLY == flag {
 				ipCon := make(map[string]map[string]interface{})
 				ipCon[common.BKHostInnerIPField] = c
@@ -0,0 +1,32 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package common
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatternMultipleIP(t *testing.T) {
+	exp := regexp.MustCompile(PatternMultipleIP)
+	for _, ips := range []string{"10.0.0.1", "10.0.0.1,192.168.1.1", "2001:db8::1", "10.0.0.1,2001:DB8:0:0:0:0:0:1", "fe80::1,::1", "::ffff:10.0.0.1"} {
+		assert.True(t, exp.MatchString(ips), ips)
+	}
+	for _, ips := range []string{"", "10.0.0.256", "10.0.0.1,", "2001:db8::1::2", "2001:db8:0:0:0:0:0:0:1", "host"} {
+		assert.False(t, exp.MatchString(ips), ips)
+	}
+	assert.True(t, regexp.MustCompile(PatternIP).MatchString("2001:db8::1"))
+	assert.False(t, regexp.MustCompile(PatternIP).MatchString("10.0.0.1,10.0.0.2"))
+}
@@ -0,0 +1,241 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+
+package querydsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/util"
+)
+
+const (
+	// maxDepth the max nesting level of the expression
+	maxDepth = 8
+	// maxNodes the max count of the nodes of the expression
+	maxNodes = 1000
+)
+
+// compiler compile the expression with the schema, and count the nodes
+type compiler struct {
+	schema Schema
+	nodes  int
+}
+
+// Compile validate the expression against the schema and compile it to the mongodb condition,
+// the empty and matches all
+func Compile(expr *Expr, schema Schema) (map[string]interface{}, error) {
+	if nil == expr {
+		return map[string]interface{}{}, nil
+	}
+	c := &compiler{schema: schema}
+	return c.compile(expr, 1)
+}
+
+func (c *compiler) compile(expr *Expr, depth int) (map[string]interface{}, error) {
+	if nil == expr {
+		return nil, fmt.Errorf("empty expression")
+	}
+	if depth > maxDepth {
+		return nil, fmt.Errorf("the expression is nested deeper than %d", maxDepth)
+	}
+	c.nodes++
+	if c.nodes > maxNodes {
+		return nil, fmt.Errorf("the expression has more than %d nodes", maxNodes)
+	}
+
+	switch expr.Op {
+	case OpAnd, OpOr, OpNot:
+		return c.compileLogic(expr, depth)
+	}
+
+	fieldType, ok := c.schema[expr.Field]
+	if !ok {
+		return nil, fmt.Errorf("field %s can not be queried", expr.Field)
+	}
+	cond, err := compileField(expr, fieldType)
+	if nil != err {
+		return nil, fmt.Errorf("field %s: %v", expr.Field, err)
+	}
+	return map[string]interface{}{expr.Field: cond}, nil
+}
+
+func (c *compiler) compileLogic(expr *Expr, depth int) (map[string]interface{}, error) {
+	if OpAnd == expr.Op && 0 == len(expr.Exprs) {
+		return map[string]interface{}{}, nil
+	}
+	if 0 == len(expr.Exprs) {
+		return nil, fmt.Errorf("%s requires the sub expressions", expr.Op)
+	}
+	if OpNot == expr.Op && 1 != len(expr.Exprs) {
+		return nil, fmt.Errorf("not requires exactly one sub expression")
+	}
+
+	conds := make([]interface{}, 0, len(expr.Exprs))
+	for _, sub := range expr.Exprs {
+		cond, err := c.compile(sub, depth+1)
+		if nil != err {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+	switch expr.Op {
+	case OpAnd:
+		return map[string]interface{}{"$and": conds}, nil
+	case OpOr:
+		return map[string]interface{}{common.BKDBOR: conds}, nil
+	default:
+		return map[string]interface{}{"$nor": conds}, nil
+	}
+}
+
+func compileField(expr *Expr, fieldType string) (interface{}, error) {
+	switch expr.Op {
+	case OpEq:
+		return toValue(expr.Value, fieldType)
+	case OpNe:
+		val, err := toValue(expr.Value, fieldType)
+		return map[string]interface{}{common.BKDBNE: val}, err
+	case OpIn, OpNin:
+		items, ok := toItems(expr.Value)
+		if !ok {
+			return nil, fmt.Errorf("%s requires an array", expr.Op)
+		}
+		vals := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			val, err := toValue(item, fieldType)
+			if nil != err {
+				return nil, err
+			}
+			vals = append(vals, val)
+		}
+		if OpIn == expr.Op {
+			return map[string]interface{}{common.BKDBIN: vals}, nil
+		}
+		return map[string]interface{}{"$nin": vals}, nil
+	case OpLt, OpGt:
+		if !isOrderedType(fieldType) {
+			return nil, fmt.Errorf("%s is not supported by the type %s", expr.Op, fieldType)
+		}
+		val, err := toValue(expr.Value, fieldType)
+		return map[string]interface{}{"$" + expr.Op: val}, err
+	case OpRange:
+		if !isOrderedType(fieldType) {
+			return nil, fmt.Errorf("%s is not supported by the type %s", expr.Op, fieldType)
+		}
+		bounds, ok := toItems(expr.Value)
+		if !ok || 2 != len(bounds) || (nil == bounds[0] && nil == bounds[1]) {
+			return nil, fmt.Errorf("range requires [from, to]")
+		}
+		cond := make(map[string]interface{})
+		for i, operator := range []string{"$gte", "$lte"} {
+			if nil == bounds[i] {
+				continue
+			}
+			val, err := toValue(bounds[i], fieldType)
+			if nil != err {
+				return nil, err
+			}
+			cond[operator] = val
+		}
+		return cond, nil
+	case OpPrefix, OpContains:
+		if !isStringType(fieldType) {
+			return nil, fmt.Errorf("%s is not supported by the type %s", expr.Op, fieldType)
+		}
+		text, ok := expr.Value.(string)
+		if !ok || "" == text {
+			return nil, fmt.Errorf("%s requires a string", expr.Op)
+		}
+		pattern := regexp.QuoteMeta(text)
+		if OpPrefix == expr.Op {
+			pattern = "^" + pattern
+		}
+		return map[string]interface{}{common.BKDBLIKE: pattern}, nil
+	case OpExists:
+		exists, ok := expr.Value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("exists requires a bool")
+		}
+		return map[string]interface{}{"$exists": exists}, nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", expr.Op)
+}
+
+// toValue check the scalar value against the field type, and convert it to the stored type
+// toItems return the items of any slice or array value, such as the []int built by the callers
+func toItems(value interface{}) ([]interface{}, bool) {
+	if items, ok := value.([]interface{}); ok {
+		return items, true
+	}
+	val := reflect.ValueOf(value)
+	if reflect.Slice != val.Kind() && reflect.Array != val.Kind() {
+		return nil, false
+	}
+	items := make([]interface{}, 0, val.Len())
+	for i := 0; i < val.Len(); i++ {
+		items = append(items, val.Index(i).Interface())
+	}
+	return items, true
+}
+
+func toValue(value interface{}, fieldType string) (interface{}, error) {
+	if num, ok := value.(json.Number); ok {
+		if i, err := num.Int64(); nil == err {
+			value = i
+		} else if f, err := num.Float64(); nil == err {
+			value = f
+		}
+	}
+
+	switch fieldType {
+	case common.FiledTypeInt:
+		switch val := value.(type) {
+		case int, int64, float64:
+			return val, nil
+		case string:
+			if i, err := strconv.ParseInt(val, 10, 64); nil == err {
+				return i, nil
+			}
+		}
+		return nil, fmt.Errorf("%v is not a number", value)
+	case common.FiledTypeBool:
+		if val, ok := value.(bool); ok {
+			return val, nil
+		}
+		return nil, fmt.Errorf("%v is not a bool", value)
+	case common.FiledTypeTime:
+		if val, ok := value.(string); ok && util.IsTime(val) {
+			return util.Str2Time(val), nil
+		}
+		return nil, fmt.Errorf("%v is not a time like 2006-01-02 15:04:05", value)
+	}
+
+	if isStringType(fieldType) {
+		if val, ok := value.(string); ok {
+			return val, nil
+		}
+		return nil, fmt.Errorf("%v is not a string", value)
+	}
+	// the association and the unknown types
+	switch value.(type) {
+	case string, int, int64, float64, bool:
+		return value, nil
+	}
+	return nil, fmt.Errorf("%v is not a scalar value", value)
+}
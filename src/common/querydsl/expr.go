@@ -0,0 +1,143 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+
+// Package querydsl the typed query condition shared by the search apis,
+// the condition is validated against the field types before compiled to the mongodb condition,
+// so the clients can not pass the raw mongodb operators like $where to the db
+package querydsl
+
+import (
+	"fmt"
+	"sort"
+)
+
+// the operators of the expression
+const (
+	OpEq       = "eq"
+	OpNe       = "ne"
+	OpIn       = "in"
+	OpNin      = "nin"
+	OpLt       = "lt"
+	OpGt       = "gt"
+	OpRange    = "range"
+	OpPrefix   = "prefix"
+	OpContains = "contains"
+	OpExists   = "exists"
+
+	OpAnd = "and"
+	OpOr  = "or"
+	OpNot = "not"
+)
+
+// Expr the node of the condition, the field operators compare the field with the value,
+// and, or, not combine the sub expressions
+// the value of range is [from, to], both included, null means unbounded
+type Expr struct {
+	Op    string      `json:"op"`
+	Field string      `json:"field,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+	Exprs []*Expr     `json:"exprs,omitempty"`
+}
+
+// NewExpr return the expression which compares the field with the value
+func NewExpr(op, field string, value interface{}) *Expr {
+	return &Expr{Op: op, Field: field, Value: value}
+}
+
+// And return the expression matches all of the sub expressions
+func And(exprs ...*Expr) *Expr {
+	return &Expr{Op: OpAnd, Exprs: exprs}
+}
+
+// Or return the expression matches any of the sub expressions
+func Or(exprs ...*Expr) *Expr {
+	return &Expr{Op: OpOr, Exprs: exprs}
+}
+
+// Not return the expression matches if the sub expression does not
+func Not(expr *Expr) *Expr {
+	return &Expr{Op: OpNot, Exprs: []*Expr{expr}}
+}
+
+// legacyOperators the mongodb operators accepted by the legacy apis, and the equivalent operators
+var legacyOperators = map[string]string{
+	"$eq":     OpEq,
+	"$ne":     OpNe,
+	"$in":     OpIn,
+	"$nin":    OpNin,
+	"$lt":     OpLt,
+	"$gt":     OpGt,
+	"$regex":  OpContains,
+	"$exists": OpExists,
+}
+
+// ParseOperator convert the {field, operator, value} condition of the legacy apis to the expression,
+// only the mongodb operators with an equivalent are accepted, $regex is matched as plain text
+func ParseOperator(field, operator string, value interface{}) (*Expr, error) {
+	switch operator {
+	case "$gte":
+		return NewExpr(OpRange, field, []interface{}{value, nil}), nil
+	case "$lte":
+		return NewExpr(OpRange, field, []interface{}{nil, value}), nil
+	}
+	op, ok := legacyOperators[operator]
+	if !ok {
+		return nil, fmt.Errorf("field %s: operator %s is not allowed", field, operator)
+	}
+	return NewExpr(op, field, value), nil
+}
+
+// ParseMap convert the {field: value} condition of the legacy apis to the expression,
+// the array or slice value is matched by in, the string value by contains if fuzzy otherwise by eq,
+// the object value like {"$gte": 1, "$lte": 2} is converted by ParseOperator
+func ParseMap(input map[string]interface{}, fuzzy bool) (*Expr, error) {
+	fields := make([]string, 0, len(input))
+	for field := range input {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	exprs := make([]*Expr, 0, len(fields))
+	for _, field := range fields {
+		switch value := input[field].(type) {
+		case []interface{}:
+			exprs = append(exprs, NewExpr(OpIn, field, value))
+		case string:
+			if fuzzy {
+				exprs = append(exprs, NewExpr(OpContains, field, value))
+			} else {
+				exprs = append(exprs, NewExpr(OpEq, field, value))
+			}
+		case map[string]interface{}:
+			operators := make([]string, 0, len(value))
+			for operator := range value {
+				operators = append(operators, operator)
+			}
+			sort.Strings(operators)
+			for _, operator := range operators {
+				expr, err := ParseOperator(field, operator, value[operator])
+				if nil != err {
+					return nil, err
+				}
+				exprs = append(exprs, expr)
+			}
+		default:
+			if items, ok := toItems(value); ok {
+				exprs = append(exprs, NewExpr(OpIn, field, items))
+				continue
+			}
+			exprs = append(exprs, NewExpr(OpEq, field, value))
+		}
+	}
+	return And(exprs...), nil
+}
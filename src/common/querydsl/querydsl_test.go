@@ -0,0 +1,155 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+
+package querydsl
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"configcenter/src/common"
+)
+
+var testSchema = NewInstSchema().
+	Add("bk_host_name", common.FiledTypeSingleChar).
+	Add("bk_cpu", common.FiledTypeInt).
+	Add("bk_os_type", common.FiledTypeEnum).
+	Add("bk_sla", common.FiledTypeBool)
+
+func TestCompile(t *testing.T) {
+	expr := And(
+		NewExpr(OpEq, "bk_host_name", "a.b"),
+		NewExpr(OpIn, common.BKHostIDField, []interface{}{float64(1), json.Number("2")}),
+		Or(NewExpr(OpPrefix, "bk_host_name", "web.*"), NewExpr(OpContains, "bk_host_name", "(x")),
+		Not(NewExpr(OpRange, "bk_cpu", []interface{}{"4", nil})),
+		NewExpr(OpExists, "bk_sla", true),
+	)
+	cond, err := Compile(expr, testSchema)
+	if nil != err {
+		t.Fatalf("compile error: %v", err)
+	}
+	expected := map[string]interface{}{"$and": []interface{}{
+		map[string]interface{}{"bk_host_name": "a.b"},
+		map[string]interface{}{common.BKHostIDField: map[string]interface{}{"$in": []interface{}{float64(1), int64(2)}}},
+		map[string]interface{}{"$or": []interface{}{
+			map[string]interface{}{"bk_host_name": map[string]interface{}{"$regex": `^web\.\*`}},
+			map[string]interface{}{"bk_host_name": map[string]interface{}{"$regex": `\(x`}},
+		}},
+		map[string]interface{}{"$nor": []interface{}{
+			map[string]interface{}{"bk_cpu": map[string]interface{}{"$gte": int64(4)}},
+		}},
+		map[string]interface{}{"bk_sla": map[string]interface{}{"$exists": true}},
+	}}
+	if !reflect.DeepEqual(cond, expected) {
+		t.Errorf("condition not as expected: %#v", cond)
+	}
+}
+
+func TestCompileTypedSlice(t *testing.T) {
+	expr := And(NewExpr(OpIn, common.BKHostIDField, []int{1, 2}), NewExpr(OpNin, "bk_host_name", []string{"a"}))
+	cond, err := Compile(expr, testSchema)
+	if nil != err {
+		t.Fatalf("compile error: %v", err)
+	}
+	expected := map[string]interface{}{"$and": []interface{}{
+		map[string]interface{}{common.BKHostIDField: map[string]interface{}{"$in": []interface{}{1, 2}}},
+		map[string]interface{}{"bk_host_name": map[string]interface{}{"$nin": []interface{}{"a"}}},
+	}}
+	if !reflect.DeepEqual(cond, expected) {
+		t.Errorf("condition not as expected: %#v", cond)
+	}
+
+	expr, err = ParseMap(map[string]interface{}{common.BKHostIDField: []int64{3}}, false)
+	if nil != err {
+		t.Fatalf("parse error: %v", err)
+	}
+	if !reflect.DeepEqual(expr, And(NewExpr(OpIn, common.BKHostIDField, []interface{}{int64(3)}))) {
+		t.Errorf("expression not as expected: %#v", expr)
+	}
+
+	if _, err := Compile(NewExpr(OpIn, common.BKHostIDField, 1), testSchema); nil == err {
+		t.Errorf("in with a scalar should fail")
+	}
+}
+
+func TestCompileInvalid(t *testing.T) {
+	deep := NewExpr(OpEq, "bk_cpu", 1)
+	for i := 0; i < maxDepth; i++ {
+		deep = Not(deep)
+	}
+	for name, expr := range map[string]*Expr{
+		"unknown field":    NewExpr(OpEq, "$where", "sleep(1000)"),
+		"unknown operator": NewExpr("regex", "bk_host_name", ".*"),
+		"object value":     NewExpr(OpEq, "bk_host_name", map[string]interface{}{"$regex": ".*"}),
+		"string as number": NewExpr(OpEq, "bk_cpu", "four"),
+		"order on string":  NewExpr(OpGt, "bk_host_name", "a"),
+		"text on number":   NewExpr(OpContains, "bk_cpu", "1"),
+		"in not array":     NewExpr(OpIn, "bk_cpu", 1),
+		"range one bound":  NewExpr(OpRange, "bk_cpu", []interface{}{1}),
+		"invalid time":     NewExpr(OpGt, common.CreateTimeField, "yesterday"),
+		"empty or":         Or(),
+		"not two":          &Expr{Op: OpNot, Exprs: []*Expr{deep, deep}},
+		"too deep":         deep,
+	} {
+		if _, err := Compile(expr, testSchema); nil == err {
+			t.Errorf("%s: error should not be nil", name)
+		}
+	}
+}
+
+func TestParseOperator(t *testing.T) {
+	expr, err := ParseOperator("bk_cpu", "$gte", 4)
+	if nil != err {
+		t.Fatalf("parse error: %v", err)
+	}
+	if !reflect.DeepEqual(expr, NewExpr(OpRange, "bk_cpu", []interface{}{4, nil})) {
+		t.Errorf("expression not as expected: %#v", expr)
+	}
+	for _, operator := range []string{"$where", "$expr", "$function", "$elemMatch"} {
+		if _, err := ParseOperator("bk_cpu", operator, 1); nil == err {
+			t.Errorf("operator %s should not be allowed", operator)
+		}
+	}
+}
+
+func TestParseMap(t *testing.T) {
+	expr, err := ParseMap(map[string]interface{}{
+		"bk_host_name": "web",
+		"bk_cpu":       map[string]interface{}{"$lte": float64(8), "$gt": float64(1)},
+		"bk_os_type":   []interface{}{"1", "2"},
+	}, true)
+	if nil != err {
+		t.Fatalf("parse error: %v", err)
+	}
+	expected := And(
+		NewExpr(OpGt, "bk_cpu", float64(1)),
+		NewExpr(OpRange, "bk_cpu", []interface{}{nil, float64(8)}),
+		NewExpr(OpContains, "bk_host_name", "web"),
+		NewExpr(OpIn, "bk_os_type", []interface{}{"1", "2"}),
+	)
+	if !reflect.DeepEqual(expr, expected) {
+		t.Errorf("expression not as expected: %#v", expr)
+	}
+
+	if _, err := ParseMap(map[string]interface{}{"bk_host_name": map[string]interface{}{"$where": "1"}}, false); nil == err {
+		t.Errorf("$where should not be allowed")
+	}
+}
+
+func TestCompileEmpty(t *testing.T) {
+	cond, err := Compile(And(), testSchema)
+	if nil != err || 0 != len(cond) {
+		t.Errorf("empty and should match all: %v, %v", cond, err)
+	}
+}
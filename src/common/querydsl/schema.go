@@ -0,0 +1,73 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+
+package querydsl
+
+import (
+	"configcenter/src/common"
+)
+
+// FieldTypeAny the field accepts the value of any scalar type, but can not be compared by order or text
+const FieldTypeAny = "any"
+
+// Schema the type of the fields which can be queried, keyed by the property id,
+// the types are the property types of the object attributes
+type Schema map[string]string
+
+// instFields the fields of the instances which are not described by the object attributes
+var instFields = map[string]string{
+	common.BKOwnerIDField:  common.FiledTypeSingleChar,
+	common.BKObjIDField:    common.FiledTypeSingleChar,
+	common.BKInstIDField:   common.FiledTypeInt,
+	common.BKInstParentStr: common.FiledTypeInt,
+	common.BKAppIDField:    common.FiledTypeInt,
+	common.BKSetIDField:    common.FiledTypeInt,
+	common.BKModuleIDField: common.FiledTypeInt,
+	common.BKHostIDField:   common.FiledTypeInt,
+	common.BKCloudIDField:  common.FiledTypeInt,
+	common.BKDefaultField:  common.FiledTypeInt,
+	common.CreateTimeField: common.FiledTypeTime,
+	common.LastTimeField:   common.FiledTypeTime,
+}
+
+// NewInstSchema return the schema of the instances, the system fields are included
+func NewInstSchema() Schema {
+	schema := make(Schema, len(instFields))
+	for field, fieldType := range instFields {
+		schema[field] = fieldType
+	}
+	return schema
+}
+
+// Add add the field to the schema
+func (s Schema) Add(propertyID, propertyType string) Schema {
+	s[propertyID] = propertyType
+	return s
+}
+
+func isStringType(fieldType string) bool {
+	switch fieldType {
+	case common.FiledTypeSingleChar, common.FiledTypeLongChar, common.FiledTypeUser,
+		common.FiledTypeEnum, common.FieldTypeTimeZone, common.FiledTypeDate:
+		return true
+	}
+	return false
+}
+
+func isOrderedType(fieldType string) bool {
+	switch fieldType {
+	case common.FiledTypeInt, common.FiledTypeDate, common.FiledTypeTime:
+		return true
+	}
+	return false
+}
@@ -38,6 +38,8 @@ type ServerInfo struct {
 	Scheme   string `json:"scheme"`
 	Version  string `json:"version"`
 	Pid      int    `json:"pid"`
+	// Weight the share of the requests for the weighted selection, 1 if it is not set
+	Weight int `json:"weight,omitempty"`
 }
 
 // APIServerServInfo apiserver informaiton
@@ -0,0 +1,272 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package util
+
+import (
+	"configcenter/src/common"
+	"errors"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// IPListSeparator the separator of the multiple ips of a host
+const IPListSeparator = ","
+
+// HostIPListFields the indexed arrays of the canonical ips stored beside the comma separated ips of the host,
+// the exact ip searches match the arrays instead of scanning the comma separated ips
+var HostIPListFields = map[string]string{
+	common.BKHostInnerIPField: common.BKHostInnerIPListField,
+	common.BKHostOuterIPField: common.BKHostOuterIPListField,
+}
+
+const (
+	ipv4AnyOctet  = `\d{1,3}`
+	ipv6AnyGroup  = `[0-9a-f]{1,4}`
+	ipv6AnyTail   = `[0-9a-f:]*`
+	digitsOfBase  = "0123456789abcdef"
+	ipv6GroupBits = 16
+)
+
+// NormalizeIP return the canonical text of the ip, ipv4 and ipv4-mapped ipv6 are returned as dotted decimal,
+// ipv6 are returned lowercase with the longest zero run compressed
+func NormalizeIP(ip string) (string, error) {
+	addr := net.ParseIP(strings.TrimSpace(ip))
+	if nil == addr {
+		return "", errors.New("invalid ip " + ip)
+	}
+	return addr.String(), nil
+}
+
+// NormalizeIPList normalize every ip of the comma separated ips, the empty items and the duplicates are dropped
+func NormalizeIPList(ips string) (string, error) {
+	result := make([]string, 0)
+	for _, ip := range strings.Split(ips, IPListSeparator) {
+		if "" == strings.TrimSpace(ip) {
+			continue
+		}
+		canonical, err := NormalizeIP(ip)
+		if nil != err {
+			return "", err
+		}
+		if !InArray(canonical, result) {
+			result = append(result, canonical)
+		}
+	}
+	return strings.Join(result, IPListSeparator), nil
+}
+
+// SplitIPList return the canonical ips of the comma separated ips, the invalid items are dropped
+func SplitIPList(ips string) []string {
+	result := make([]string, 0)
+	for _, ip := range strings.Split(ips, IPListSeparator) {
+		canonical, err := NormalizeIP(ip)
+		if nil != err {
+			continue
+		}
+		result = append(result, canonical)
+	}
+	return result
+}
+
+// SetHostIPList set the canonical ip arrays of the comma separated ips given in the host data
+func SetHostIPList(data map[string]interface{}) {
+	for field, listField := range HostIPListFields {
+		ips, ok := data[field].(string)
+		if false == ok {
+			continue
+		}
+		data[listField] = SplitIPList(ips)
+	}
+}
+
+// IPSearchPattern return the regular pattern matching one canonical ip equal to the ip,
+// or contained in the network if a cidr such as 10.0.0.0/8 or 2001:db8::/32 is given
+func IPSearchPattern(ipOrCIDR string) (string, error) {
+	ipOrCIDR = strings.TrimSpace(ipOrCIDR)
+	if !strings.Contains(ipOrCIDR, "/") {
+		canonical, err := NormalizeIP(ipOrCIDR)
+		if nil != err {
+			return "", err
+		}
+		return regexp.QuoteMeta(canonical), nil
+	}
+
+	_, network, err := net.ParseCIDR(ipOrCIDR)
+	if nil != err {
+		return "", errors.New("invalid cidr " + ipOrCIDR)
+	}
+	ones, bits := network.Mask.Size()
+	if net.IPv4len*8 == bits {
+		return ipv4NetworkPattern(network.IP.To4(), ones), nil
+	}
+	return ipv6NetworkPattern(network.IP.To16(), ones), nil
+}
+
+// ipv4NetworkPattern match the dotted decimal ipv4 in the network
+func ipv4NetworkPattern(ip net.IP, ones int) string {
+	octets := make([]string, 0, net.IPv4len)
+	for i := 0; i < net.IPv4len; i++ {
+		lo, hi, constrained := prefixRange(int64(ip[i]), i*8, 8, ones)
+		if !constrained {
+			octets = append(octets, ipv4AnyOctet)
+			continue
+		}
+		octets = append(octets, rangePattern(lo, hi, 10))
+	}
+	return strings.Join(octets, `\.`)
+}
+
+// ipv6NetworkPattern match the canonical ipv6 in the network, the constrained groups may be
+// written literally or be part of the compressed zero run
+func ipv6NetworkPattern(ip net.IP, ones int) string {
+	groupCnt := net.IPv6len / 2
+	groups := make([]string, 0, groupCnt)
+	zeroable := make([]bool, 0, groupCnt)
+	for i := 0; i < groupCnt; i++ {
+		value := int64(ip[2*i])<<8 | int64(ip[2*i+1])
+		lo, hi, constrained := prefixRange(value, i*ipv6GroupBits, ipv6GroupBits, ones)
+		if !constrained {
+			break
+		}
+		groups = append(groups, rangePattern(lo, hi, 16))
+		zeroable = append(zeroable, 0 == lo)
+	}
+	k := len(groups)
+	if 0 == k {
+		return `[0-9a-f]*:` + ipv6AnyTail
+	}
+
+	alternatives := make([]string, 0)
+	// the constrained groups are written literally
+	if groupCnt == k {
+		alternatives = append(alternatives, strings.Join(groups, ":"))
+	} else {
+		alternatives = append(alternatives, strings.Join(groups, ":")+":"+ipv6AnyTail)
+	}
+	// the zero run compressed as :: starts at group i and ends at group j
+	for i := 0; i < k; i++ {
+		for j := i; j < k && zeroable[j]; j++ {
+			head := strings.Join(groups[:i], ":") + "::"
+			if j < k-1 {
+				tail := strings.Repeat(":"+ipv6AnyGroup, groupCnt-k)
+				alternatives = append(alternatives, head+strings.Join(groups[j+1:], ":")+tail)
+				continue
+			}
+			// the run may go on after the constrained groups
+			if groupCnt == k {
+				alternatives = append(alternatives, head)
+			} else {
+				alternatives = append(alternatives, head+"("+ipv6AnyGroup+"(:"+ipv6AnyGroup+"){0,"+strconv.Itoa(groupCnt-k-1)+"})?")
+			}
+		}
+	}
+	return "(" + strings.Join(alternatives, "|") + ")"
+}
+
+// prefixRange return the value range of the part of the ip starting at the offset bit,
+// constrained is false if the prefix does not cover the part
+func prefixRange(value int64, offset, width, ones int) (lo, hi int64, constrained bool) {
+	if ones <= offset {
+		return 0, 0, false
+	}
+	free := uint(0)
+	if ones < offset+width {
+		free = uint(offset + width - ones)
+	}
+	lo = value &^ (1<<free - 1)
+	return lo, lo | (1<<free - 1), true
+}
+
+// rangePattern match the numbers between lo and hi written without leading zeros
+func rangePattern(lo, hi int64, base int) string {
+	if lo == hi {
+		return strconv.FormatInt(lo, base)
+	}
+	alternatives := make([]string, 0)
+	minOfWidth, maxOfWidth := int64(0), int64(base)-1
+	for width := 1; minOfWidth <= hi; width++ {
+		from, to := lo, hi
+		if from < minOfWidth {
+			from = minOfWidth
+		}
+		if to > maxOfWidth {
+			to = maxOfWidth
+		}
+		if from <= to {
+			alternatives = append(alternatives, sameWidthPattern(formatWidth(from, base, width), formatWidth(to, base, width), base))
+		}
+		minOfWidth, maxOfWidth = maxOfWidth+1, (maxOfWidth+1)*int64(base)-1
+	}
+	if 1 == len(alternatives) {
+		return alternatives[0]
+	}
+	return "(" + strings.Join(alternatives, "|") + ")"
+}
+
+// sameWidthPattern match the digits between from and to, both have the same width
+func sameWidthPattern(from, to string, base int) string {
+	if from == to {
+		return from
+	}
+	first, last := strings.IndexByte(digitsOfBase, from[0]), strings.IndexByte(digitsOfBase, to[0])
+	restWidth := len(from) - 1
+	if 0 == restWidth {
+		return digitClass(first, last)
+	}
+	if first == last {
+		return from[:1] + sameWidthPattern(from[1:], to[1:], base)
+	}
+
+	minRest := strings.Repeat("0", restWidth)
+	maxRest := strings.Repeat(digitsOfBase[base-1:base], restWidth)
+	anyRest := digitClass(0, base-1)
+	if 1 < restWidth {
+		anyRest += "{" + strconv.Itoa(restWidth) + "}"
+	}
+
+	alternatives := make([]string, 0, 3)
+	middleFirst, middleLast := first, last
+	if from[1:] != minRest {
+		alternatives = append(alternatives, from[:1]+sameWidthPattern(from[1:], maxRest, base))
+		middleFirst++
+	}
+	var high string
+	if to[1:] != maxRest {
+		high = to[:1] + sameWidthPattern(minRest, to[1:], base)
+		middleLast--
+	}
+	if middleFirst <= middleLast {
+		alternatives = append(alternatives, digitClass(middleFirst, middleLast)+anyRest)
+	}
+	if "" != high {
+		alternatives = append(alternatives, high)
+	}
+	if 1 == len(alternatives) {
+		return alternatives[0]
+	}
+	return "(" + strings.Join(alternatives, "|") + ")"
+}
+
+func digitClass(first, last int) string {
+	if first == last {
+		return digitsOfBase[first : first+1]
+	}
+	return "[" + digitsOfBase[first:last+1] + "]"
+}
+
+func formatWidth(value int64, base, width int) string {
+	text := strconv.FormatInt(value, base)
+	return strings.Repeat("0", width-len(text)) + text
+}
@@ -0,0 +1,131 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package util
+
+import (
+	"configcenter/src/common"
+	"math/rand"
+	"net"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeIP(t *testing.T) {
+	cases := map[string]string{
+		"10.0.0.1":                    "10.0.0.1",
+		" 10.0.0.1 ":                  "10.0.0.1",
+		"::ffff:10.0.0.1":             "10.0.0.1",
+		"2001:0DB8:0000:0000:0:0:0:1": "2001:db8::1",
+		"2001:db8:0:1:0:0:0:1":        "2001:db8:0:1::1",
+		"fe80::1":                     "fe80::1",
+	}
+	for ip, expected := range cases {
+		actual, err := NormalizeIP(ip)
+		require.NoError(t, err, ip)
+		assert.Equal(t, expected, actual, ip)
+	}
+
+	_, err := NormalizeIP("10.0.0.256")
+	assert.Error(t, err)
+	_, err = NormalizeIP("2001:db8::1::2")
+	assert.Error(t, err)
+}
+
+func TestNormalizeIPList(t *testing.T) {
+	ips, err := NormalizeIPList("10.0.0.1, 2001:DB8::0:1,,10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1,2001:db8::1", ips)
+
+	_, err = NormalizeIPList("10.0.0.1,host")
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"10.0.0.1", "2001:db8::1"}, SplitIPList("10.0.0.1,host,2001:DB8::1"))
+}
+
+func TestSetHostIPList(t *testing.T) {
+	host := map[string]interface{}{common.BKHostInnerIPField: "10.0.0.1,2001:DB8::1", common.BKHostOuterIPField: ""}
+	SetHostIPList(host)
+	assert.Equal(t, []string{"10.0.0.1", "2001:db8::1"}, host[common.BKHostInnerIPListField])
+	assert.Equal(t, []string{}, host[common.BKHostOuterIPListField])
+
+	// the ip arrays are untouched if the ips are not written
+	host = map[string]interface{}{common.BKHostNameField: "host"}
+	SetHostIPList(host)
+	assert.NotContains(t, host, common.BKHostInnerIPListField)
+}
+
+func TestRangePattern(t *testing.T) {
+	for _, base := range []int{10, 16} {
+		for _, r := range [][2]int64{{0, 0}, {0, 9}, {3, 17}, {128, 255}, {96, 111}, {0, 255}, {8, 4095}, {0x2000, 0x3fff}} {
+			exp := regexp.MustCompile("^" + rangePattern(r[0], r[1], base) + "$")
+			for v := int64(0); v <= 0x4100; v++ {
+				text := strconv.FormatInt(v, base)
+				assert.Equal(t, v >= r[0] && v <= r[1], exp.MatchString(text), "base %d range %v value %s", base, r, text)
+			}
+		}
+	}
+}
+
+func TestIPSearchPattern(t *testing.T) {
+	pattern, err := IPSearchPattern("2001:DB8::1")
+	require.NoError(t, err)
+	assert.Equal(t, `2001:db8::1`, pattern)
+
+	_, err = IPSearchPattern("10.0.0.0/33")
+	assert.Error(t, err)
+
+	random := rand.New(rand.NewSource(1))
+	networks := []string{
+		"10.0.0.0/8", "192.168.1.128/25", "172.16.0.0/12", "10.1.2.3/32", "0.0.0.0/0",
+		"2001:db8::/32", "2001:0:0:5::/64", "2001:db8:0:0:1::/80", "fe80::/10", "::/8", "::/0", "2001:db8::1/128", "::1/128",
+	}
+	for _, cidr := range networks {
+		_, network, err := net.ParseCIDR(cidr)
+		require.NoError(t, err)
+		pattern, err := IPSearchPattern(cidr)
+		require.NoError(t, err)
+		exp := regexp.MustCompile("^" + pattern + "$")
+
+		for i := 0; i < 2000; i++ {
+			ip := randomIP(random, len(network.IP))
+			if 0 == i%2 {
+				// force the address into the network
+				for b := range ip {
+					ip[b] = ip[b]&^network.Mask[b] | network.IP[b]
+				}
+			}
+			text := ip.String()
+			if len(network.IP) == net.IPv6len && nil != ip.To4() {
+				continue
+			}
+			assert.Equal(t, network.Contains(ip), exp.MatchString(text), "network %s ip %s", cidr, text)
+		}
+	}
+}
+
+// randomIP return the ip whose groups are zero by half to produce the compressed text
+func randomIP(random *rand.Rand, size int) net.IP {
+	ip := make(net.IP, size)
+	for i := 0; i < size; i += 2 {
+		if 0 == random.Intn(2) {
+			continue
+		}
+		ip[i] = byte(random.Intn(256))
+		ip[i+1] = byte(random.Intn(256))
+	}
+	return ip
+}
@@ -18,6 +18,7 @@ import (
 	"strings"
 
 	restful "github.com/emicklei/go-restful"
+	"github.com/rs/xid"
 )
 
 func InArray(obj interface{}, target interface{}) bool {
@@ -113,3 +114,13 @@ func GetActionOnwerIDAndUser(req *restful.Request) (string, string) {
 
 	return ownerID, user
 }
+
+// GetActionRequestID returns request id form hender
+func GetActionRequestID(req *restful.Request) string {
+	return req.HeaderParameter(common.BKHTTPRequestID)
+}
+
+// GenerateRequestID returns a new request id
+func GenerateRequestID() string {
+	return xid.New().String()
+}
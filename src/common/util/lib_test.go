@@ -167,3 +167,17 @@ func TestGetActionLanguage(t *testing.T) {
 	language = GetActionLanguage(restful.NewRequest(req))
 	require.NotEqual(t, "cn", language)
 }
+
+func TestGetActionRequestID(t *testing.T) {
+	req := httptest.NewRequest("POST", "http://127.0.0.1/call", nil)
+
+	rid := GetActionRequestID(restful.NewRequest(req))
+	require.Empty(t, rid)
+
+	rid = GenerateRequestID()
+	require.NotEmpty(t, rid)
+	require.NotEqual(t, rid, GenerateRequestID())
+
+	req.Header.Set(common.BKHTTPRequestID, rid)
+	require.Equal(t, rid, GetActionRequestID(restful.NewRequest(req)))
+}
@@ -0,0 +1,92 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package app
+
+import (
+	"configcenter/src/common/confregdiscover"
+	"configcenter/src/common/confregistry"
+	"configcenter/src/common/core/cc/config"
+	"configcenter/src/common/types"
+	confCenter "configcenter/src/scene_server/admin_server/migrate_service/config"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/pflag"
+)
+
+// ConfigCommand the name of the subcommand to diff and push the configure files
+const ConfigCommand = "config"
+
+const configUsage = `usage: %s config <diff|push> [flags]
+  diff  show the differences between the configure files and the configures in center
+  push  write the changed configure files into center, the services reload them without restart
+`
+
+// RunConfigCommand diff or push the configure files of the modules, args are the arguments after the subcommand
+func RunConfigCommand(args []string, out io.Writer) error {
+	fs := pflag.NewFlagSet(ConfigCommand, pflag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(out, configUsage, os.Args[0])
+		fs.PrintDefaults()
+	}
+	server := fs.String("config-server", "127.0.0.1:2181", "the config center, zookeeper hosts or the registry file path")
+	backend := fs.String("regdiscv-backend", config.RegDiscoverZookeeper, "backend of the config center, zookeeper or file")
+	dir := fs.String("dir", "conf", "the directory of the configure files, named as [modulename].conf")
+	modules := fs.StringSlice("module", confCenter.Modules, "the modules to diff or push")
+	if err := fs.Parse(args); nil != err {
+		return err
+	}
+	action := fs.Arg(0)
+	if "diff" != action && "push" != action {
+		fs.Usage()
+		return fmt.Errorf("unknown config action %q", action)
+	}
+
+	crd := confregdiscover.NewConfRegDiscover(config.GetRegDiscoverAddr(*backend, *server))
+	if err := crd.Start(); nil != err {
+		return err
+	}
+	defer crd.Stop()
+
+	for _, module := range *modules {
+		filePath := *dir + "/" + module + ".conf"
+		data, err := ioutil.ReadFile(filePath)
+		if nil != err {
+			return fmt.Errorf("fail to read configure file(%s), err:%s", filePath, err.Error())
+		}
+		key := types.CC_SERVCONF_BASEPATH + "/" + module
+		current, err := crd.Read(key)
+		if nil != err {
+			return fmt.Errorf("fail to read configure of %s from center, err:%s", module, err.Error())
+		}
+
+		changes := confregistry.Diff(confregistry.Parse(current), confregistry.Parse(data))
+		if 0 == len(changes) {
+			fmt.Fprintf(out, "%s: no change\n", module)
+			continue
+		}
+		fmt.Fprintf(out, "%s: %d changes\n", module, len(changes))
+		for _, change := range changes {
+			fmt.Fprintf(out, "  %s\n", change.String())
+		}
+		if "push" == action {
+			if err := crd.Write(key, data); nil != err {
+				return fmt.Errorf("fail to write configure of %s into center, err:%s", module, err.Error())
+			}
+			fmt.Fprintf(out, "%s: pushed\n", module)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package app
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"configcenter/src/common/confregdiscover"
+	"configcenter/src/common/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunConfigCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configcmd")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	registry := filepath.Join(dir, "registry.yaml")
+	require.NoError(t, ioutil.WriteFile(registry, []byte("configs:\n  /cc/services/config/eventserver: |\n    [log]\n    level = 3\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "eventserver.conf"), []byte("[log]\nlevel = 5\n[event]\ncallback_timeout = 5s\n"), 0644))
+
+	args := []string{"--config-server", registry, "--regdiscv-backend", "file", "--dir", dir, "--module", types.CC_MODULE_EVENTSERVER}
+	out := &bytes.Buffer{}
+	require.NoError(t, RunConfigCommand(append([]string{"diff"}, args...), out))
+	assert.Equal(t, "eventserver: 2 changes\n  + event.callback_timeout = 5s\n  ~ log.level = 3 -> 5\n", out.String())
+
+	out.Reset()
+	require.NoError(t, RunConfigCommand(append([]string{"push"}, args...), out))
+	assert.Contains(t, out.String(), "eventserver: pushed\n")
+
+	crd := confregdiscover.NewConfRegDiscover("file://" + registry)
+	require.NoError(t, crd.Start())
+	defer crd.Stop()
+	data, err := crd.Read(types.CC_SERVCONF_BASEPATH + "/" + types.CC_MODULE_EVENTSERVER)
+	require.NoError(t, err)
+	assert.Equal(t, "[log]\nlevel = 5\n[event]\ncallback_timeout = 5s\n", string(data))
+
+	out.Reset()
+	require.NoError(t, RunConfigCommand(append([]string{"diff"}, args...), out))
+	assert.Equal(t, "eventserver: no change\n", out.String())
+
+	assert.Error(t, RunConfigCommand([]string{"apply"}, ioutil.Discard))
+}
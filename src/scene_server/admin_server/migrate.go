@@ -35,6 +35,14 @@ func main() {
 	blog.InitLogs()
 	defer blog.CloseLogs()
 
+	if len(os.Args) > 1 && app.ConfigCommand == os.Args[1] {
+		if err := app.RunConfigCommand(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	op := options.NewServerOption()
 	op.AddFlags(pflag.CommandLine)
 
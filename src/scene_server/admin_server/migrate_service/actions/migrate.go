@@ -36,6 +36,7 @@ func init() {
 	migrate.CreateAction()
 
 	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/migrate/{distribution}/{ownerID}", Params: nil, Handler: migrate.migrate})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/migrate/status/{ownerID}", Params: nil, Handler: migrate.status})
 	// create CC object
 }
 
@@ -57,12 +58,19 @@ func (cli *migrateAction) migrate(req *restful.Request, resp *restful.Response)
 
 	data.Distribution = pathParameters["distribution"]
 
-	err := logics.DBMigrate(ownerID)
+	// dry run only list the pending steps
+	dryRun := "true" == req.QueryParameter("dry_run")
+	steps, err := logics.DBMigrate(ownerID, dryRun)
 	if nil != err {
 		blog.Errorf("db migrate error: %v", err)
 		cli.ResponseFailed(common.CCErrCommMigrateFailed, defErr.Error(common.CCErrCommMigrateFailed), resp)
 		return
 	}
+	if dryRun {
+		cli.ResponseSuccess(steps, resp)
+		return
+	}
+	blog.Infof("db migrate applied %d steps", len(steps))
 
 	err = logics.DefaultAppMigrate(req, migrate.CC, ownerID)
 	if nil != err {
@@ -96,3 +104,19 @@ func (cli *migrateAction) migrate(req *restful.Request, resp *restful.Response)
 	return
 
 }
+
+func (cli *migrateAction) status(req *restful.Request, resp *restful.Response) {
+	language := util.GetActionLanguage(req)
+	// get the error factory by the language
+	defErr := cli.CC.Error.CreateDefaultCCErrorIf(language)
+
+	ownerID := req.PathParameter("ownerID")
+	status, err := logics.GetMigrateStatus(ownerID, cli.CC.InstCli)
+	if nil != err {
+		blog.Errorf("get migrate status error: %v", err)
+		cli.ResponseFailed(common.CCErrCommDBSelectFailed, defErr.Error(common.CCErrCommDBSelectFailed), resp)
+		return
+	}
+
+	cli.ResponseSuccess(status, resp)
+}
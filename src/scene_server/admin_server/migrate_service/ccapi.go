@@ -16,15 +16,15 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/api"
 	"configcenter/src/common/core/cc/config"
+	"configcenter/src/common/discovery"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/http/httpserver"
 	"configcenter/src/common/rdapi"
 	"configcenter/src/common/types"
-	"sync"
-	"time"
 	// migrateCommon "configcenter/src/scene_server/admin_server/common"
 	confCenter "configcenter/src/scene_server/admin_server/migrate_service/config"
-	"configcenter/src/scene_server/admin_server/migrate_service/rdiscover"
+	"sync"
+	"time"
 	//"time"
 )
 
@@ -32,7 +32,7 @@ import (
 type CCAPIServer struct {
 	conf     *config.CCAPIConfig
 	httpServ *httpserver.HttpServer
-	rd       *rdiscover.RegDiscover
+	rd       *discovery.RegDiscover
 	cfCenter *confCenter.ConfCenter
 }
 
@@ -52,11 +52,11 @@ func NewCCAPIServer(conf *config.CCAPIConfig) (*CCAPIServer, error) {
 	a.InitAction()
 
 	configctx, _ := a.ParseConfig()
-	regDiscAddrs := configctx["register-server.addrs"]
-	confCenterAddrs := configctx["config-server.addrs"]
+	regDiscAddrs := config.GetRegDiscoverAddr(s.conf.RegDiscoverBackend, configctx["register-server.addrs"])
+	confCenterAddrs := config.GetRegDiscoverAddr(s.conf.RegDiscoverBackend, configctx["config-server.addrs"])
 
 	//RDiscover
-	s.rd = rdiscover.NewRegDiscover(regDiscAddrs, addr, port, false)
+	s.rd = discovery.NewRegDiscover(regDiscAddrs, types.CC_MODULE_MIGRATE, addr, port, false, discovery.NewOptions(s.conf), types.CC_MODULE_PROC, types.CC_MODULE_TOPO)
 	a.AddrSrv = s.rd
 
 	//ConfCenter
@@ -167,6 +167,8 @@ func (ccAPI *CCAPIServer) initHttpServ() error {
 	a := api.NewAPIResource()
 
 	ccAPI.httpServ.RegisterWebServer("/migrate/{version}", rdapi.GlobalFilter(types.CC_MODULE_PROC, types.CC_MODULE_TOPO), a.Actions)
+	a.RegisterHealth(ccAPI.httpServ)
+	ccAPI.httpServ.RegisterWebServer("/discovery", nil, []*httpserver.Action{ccAPI.rd.SnapshotAction()})
 
 	return nil
 }
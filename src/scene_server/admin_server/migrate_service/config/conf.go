@@ -27,6 +27,21 @@ import (
 	"sync"
 )
 
+// Modules the modules whose configures are saved into center, the migrate service itself is excluded
+var Modules = []string{
+	types.CC_MODULE_APISERVER,
+	types.CC_MODULE_AUDITCONTROLLER,
+	types.CC_MODULE_DATACOLLECTION,
+	types.CC_MODULE_HOST,
+	types.CC_MODULE_HOSTCONTROLLER,
+	types.CC_MODULE_OBJECTCONTROLLER,
+	types.CC_MODULE_PROC,
+	types.CC_MODULE_PROCCONTROLLER,
+	types.CC_MODULE_TOPO,
+	types.CC_MODULE_WEBSERVER,
+	types.CC_MODULE_EVENTSERVER,
+}
+
 // ConfCenter discover configure changed. get, update configures
 type ConfCenter struct {
 	confRegDiscv *confregdiscover.ConfRegDiscover
@@ -172,22 +187,7 @@ func (cc *ConfCenter) WriteLanguagePackage2Center(errorres string) error {
 // parameter[confRootPath] define the configurs root path, the specification name of the configure \
 // file is [modulename].conf \
 func (cc *ConfCenter) WriteConfs2Center(confRootPath string) error {
-	modules := make([]string, 0)
-
-	modules = append(modules, types.CC_MODULE_APISERVER)
-	modules = append(modules, types.CC_MODULE_AUDITCONTROLLER)
-	modules = append(modules, types.CC_MODULE_DATACOLLECTION)
-	modules = append(modules, types.CC_MODULE_HOST)
-	modules = append(modules, types.CC_MODULE_HOSTCONTROLLER)
-	// modules = append(modules, types.CC_MODULE_MIGRATE)
-	modules = append(modules, types.CC_MODULE_OBJECTCONTROLLER)
-	modules = append(modules, types.CC_MODULE_PROC)
-	modules = append(modules, types.CC_MODULE_PROCCONTROLLER)
-	modules = append(modules, types.CC_MODULE_TOPO)
-	modules = append(modules, types.CC_MODULE_WEBSERVER)
-	modules = append(modules, types.CC_MODULE_EVENTSERVER)
-
-	for _, moduleName := range modules {
+	for _, moduleName := range Modules {
 		filePath := confRootPath + "/" + moduleName + ".conf"
 		key := types.CC_SERVCONF_BASEPATH + "/" + moduleName
 		if err := cc.writeConfigure(filePath, key); err != nil {
@@ -32,7 +32,9 @@ var (
 		"cc_Process",
 		"cc_SetBase",
 		"cc_Subscription",
+		"cc_EventHistory",
 		"cc_UserAPI",
+		"cc_HostGroupMember",
 		"cc_UserCustom",
 		"cc_UserGroup",
 		"cc_UserGroupPrivilege",
@@ -42,6 +44,7 @@ var (
 		"cc_ObjClassification",
 		"cc_ObjDes",
 		"cc_PropertyGroup",
+		"cc_System",
 	}
 )
 
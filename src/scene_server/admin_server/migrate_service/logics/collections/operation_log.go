@@ -46,5 +46,5 @@ func (m *migrateOperationLog) createTable(ownerID string, metaData dbStorage.DI,
 
 func init() {
 	m := &migrateOperationLog{tableName: "cc_OperationLog"}
-	migrateregister.RegisterMigrateAction(m.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+m.tableName, 1, m.createTable, migrateregister.MigrateTypeCreateTable)
 }
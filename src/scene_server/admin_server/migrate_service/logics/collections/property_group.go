@@ -56,7 +56,7 @@ func (m *migratePropertyGroup) addData(ownerID string, metaData dbStorage.DI, in
 
 func init() {
 	m := &migratePropertyGroup{tableName: "cc_PropertyGroup"}
-	migrateregister.RegisterMigrateAction(m.createTable, migrateregister.MigrateTypeCreateTable)
-	migrateregister.RegisterMigrateAction(m.addData, migrateregister.MigrateTypeAddData)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+m.tableName, 1, m.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "add_data_"+m.tableName, 1, m.addData, migrateregister.MigrateTypeAddData)
 
 }
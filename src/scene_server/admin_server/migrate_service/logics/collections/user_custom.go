@@ -47,5 +47,5 @@ func (m *migrateUserCustom) createTable(ownerID string, metaData dbStorage.DI, i
 
 func init() {
 	m := &migrateUserCustom{tableName: "cc_UserCustom"}
-	migrateregister.RegisterMigrateAction(m.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+m.tableName, 1, m.createTable, migrateregister.MigrateTypeCreateTable)
 }
@@ -14,6 +14,7 @@ package logics
 
 import (
 	"configcenter/src/common"
+	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/api"
 	"configcenter/src/scene_server/admin_server/migrateregister"
 
@@ -27,57 +28,46 @@ import (
 	_ "configcenter/src/scene_server/admin_server/migrate_service/logics/topo"
 )
 
-func DBMigrate(ownerid string) error {
+// DBMigrate run the migrations which have not been applied to the owner yet or revised since applied in order,
+// and record each of them into the history once it succeeds.
+// with dryRun, nothing is run, only the pending steps are returned
+func DBMigrate(ownerid string, dryRun bool) ([]MigrateStep, error) {
 	a := api.GetAPIResource()
 	if "" == ownerid {
 		ownerid = common.BKDefaultOwnerID
 	}
-	createTable := migrateregister.GetMigrateAction(migrateregister.MigrateTypeCreateTable)
-	for _, f := range createTable {
-		err := f(ownerid, a.InstCli, a.InstCli)
-		if nil != err {
-			return err
-		}
-	}
 
-	alterTable := migrateregister.GetMigrateAction(migrateregister.MigrateTypeAlterTable)
-	for _, f := range alterTable {
-		err := f(ownerid, a.InstCli, a.InstCli)
-		if nil != err {
-			return err
-		}
+	status, err := GetMigrateStatus(ownerid, a.InstCli)
+	if nil != err {
+		return nil, err
 	}
-
-	dropTable := migrateregister.GetMigrateAction(migrateregister.MigrateTypeDropTable)
-	for _, f := range dropTable {
-		err := f(ownerid, a.InstCli, a.InstCli)
-		if nil != err {
-			return err
-		}
+	for _, step := range status.Changed {
+		blog.Warnf("migration %s:%s has been applied with a higher revision than %d, it will not be run again", step.Version, step.Name, step.Revision)
 	}
-
-	addData := migrateregister.GetMigrateAction(migrateregister.MigrateTypeAddData)
-	for _, f := range addData {
-		err := f(ownerid, a.InstCli, a.InstCli)
-		if nil != err {
-			return err
-		}
+	if dryRun {
+		return status.Pending, nil
 	}
 
-	updateData := migrateregister.GetMigrateAction(migrateregister.MigrateTypeUpdateData)
-	for _, f := range updateData {
-		err := f(ownerid, a.InstCli, a.InstCli)
-		if nil != err {
-			return err
-		}
+	pending := make(map[string]bool, len(status.Pending))
+	for _, step := range status.Pending {
+		pending[step.Version+":"+step.Name] = true
 	}
 
-	delData := migrateregister.GetMigrateAction(migrateregister.MigrateTypeDelData)
-	for _, f := range delData {
-		err := f(ownerid, a.InstCli, a.InstCli)
-		if nil != err {
-			return err
+	applied := make([]MigrateStep, 0)
+	for _, m := range migrateregister.GetMigrations() {
+		if !pending[m.Key()] {
+			continue
+		}
+		blog.Infof("start migration %s, type %s", m.Key(), m.Type)
+		if err := m.Handler(ownerid, a.InstCli, a.InstCli); nil != err {
+			blog.Errorf("migration %s error %v", m.Key(), err)
+			return applied, err
+		}
+		if err := saveMigrateHistory(ownerid, m, a.InstCli); nil != err {
+			blog.Errorf("save migration %s history error %v", m.Key(), err)
+			return applied, err
 		}
+		applied = append(applied, newMigrateStep(m))
 	}
-	return nil
+	return applied, nil
 }
@@ -45,5 +45,5 @@ func (m *migrateEvent) createTable(ownerID string, metaData dbStorage.DI, instDa
 
 func init() {
 	m := &migrateEvent{tableName: "cc_Subscription"}
-	migrateregister.RegisterMigrateAction(m.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+m.tableName, 1, m.createTable, migrateregister.MigrateTypeCreateTable)
 }
@@ -47,5 +47,5 @@ func (m *migrateHoistory) createTable(ownerID string, metaData dbStorage.DI, ins
 
 func init() {
 	m := &migrateHoistory{tableName: "cc_History"}
-	migrateregister.RegisterMigrateAction(m.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+m.tableName, 1, m.createTable, migrateregister.MigrateTypeCreateTable)
 }
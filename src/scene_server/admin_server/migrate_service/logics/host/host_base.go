@@ -13,11 +13,16 @@
 package host
 
 import (
+	"configcenter/src/common"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/util"
 	"configcenter/src/scene_server/admin_server/migrateregister"
 	dbStorage "configcenter/src/storage"
 )
 
+// hostPageSize the count of the hosts updated by a page
+const hostPageSize = 500
+
 type migrateHostBase struct {
 	tableName string
 }
@@ -45,7 +50,53 @@ func (m *migrateHostBase) createTable(ownerID string, metaData dbStorage.DI, ins
 	return nil
 }
 
+//主机ip转为标准格式，并保存到带索引的ip数组
+func (m *migrateHostBase) updateIPList(ownerID string, metaData dbStorage.DI, instData dbStorage.DI) error {
+
+	blog.Infof("start update the ip list of %s table", m.tableName)
+
+	fields := []string{common.BKHostIDField, common.BKHostInnerIPField, common.BKHostOuterIPField}
+	for start := 0; ; start += hostPageSize {
+		hosts := make([]map[string]interface{}, 0)
+		err := instData.GetMutilByCondition(m.tableName, fields, map[string]interface{}{}, &hosts, common.BKHostIDField, start, hostPageSize)
+		if nil != err {
+			blog.Errorf("get the hosts of %s table error %v", m.tableName, err)
+			return err
+		}
+		for _, host := range hosts {
+			data := make(map[string]interface{})
+			for field := range util.HostIPListFields {
+				ips, ok := host[field].(string)
+				if false == ok {
+					continue
+				}
+				// keep the invalid ips as they are, the valid ones are still searchable by the list
+				if canonical, err := util.NormalizeIPList(ips); nil == err {
+					ips = canonical
+				}
+				data[field] = ips
+			}
+			if 0 == len(data) {
+				continue
+			}
+			util.SetHostIPList(data)
+			condition := map[string]interface{}{common.BKHostIDField: host[common.BKHostIDField]}
+			if err := instData.UpdateByCondition(m.tableName, data, condition); nil != err {
+				blog.Errorf("update the ip list of host %v error %v", host[common.BKHostIDField], err)
+				return err
+			}
+		}
+		if len(hosts) < hostPageSize {
+			break
+		}
+	}
+	blog.Infof("end update the ip list of %s table", m.tableName)
+
+	return nil
+}
+
 func init() {
 	mHost := &migrateHostBase{tableName: "cc_HostBase"}
-	migrateregister.RegisterMigrateAction(mHost.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+mHost.tableName, 1, mHost.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.7", "update_ip_list_"+mHost.tableName, 1, mHost.updateIPList, migrateregister.MigrateTypeUpdateData)
 }
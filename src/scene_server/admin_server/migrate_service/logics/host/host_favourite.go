@@ -47,5 +47,5 @@ func (m *migrateHostFavourite) createTable(ownerID string, metaData dbStorage.DI
 
 func init() {
 	m := &migrateHostFavourite{tableName: "cc_HostFavourite"}
-	migrateregister.RegisterMigrateAction(m.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+m.tableName, 1, m.createTable, migrateregister.MigrateTypeCreateTable)
 }
@@ -47,5 +47,5 @@ func (m *migrateModuleHostConfig) createTable(ownerID string, metaData dbStorage
 
 func init() {
 	m := &migrateModuleHostConfig{tableName: "cc_ModuleHostConfig"}
-	migrateregister.RegisterMigrateAction(m.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+m.tableName, 1, m.createTable, migrateregister.MigrateTypeCreateTable)
 }
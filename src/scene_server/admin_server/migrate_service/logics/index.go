@@ -46,6 +46,8 @@ func getIndex() map[string][]storage.Index {
 		storage.Index{Name: "", Columns: []string{"bk_host_name"}, Type: storage.INDEX_TYPE_BACKGROUP},
 		storage.Index{Name: "", Columns: []string{"bk_host_innerip"}, Type: storage.INDEX_TYPE_BACKGROUP},
 		storage.Index{Name: "", Columns: []string{"bk_host_outerip"}, Type: storage.INDEX_TYPE_BACKGROUP},
+		storage.Index{Name: "", Columns: []string{"bk_host_innerip_list"}, Type: storage.INDEX_TYPE_BACKGROUP},
+		storage.Index{Name: "", Columns: []string{"bk_host_outerip_list"}, Type: storage.INDEX_TYPE_BACKGROUP},
 	}
 	index["cc_ModuleBase"] = []storage.Index{
 		storage.Index{Name: "", Columns: []string{"bk_module_id"}, Type: storage.INDEX_TYPE_BACKGROUP},
@@ -62,6 +64,10 @@ func getIndex() map[string][]storage.Index {
 		storage.Index{Name: "", Columns: []string{"bk_module_id"}, Type: storage.INDEX_TYPE_BACKGROUP},
 		storage.Index{Name: "", Columns: []string{"bk_set_id"}, Type: storage.INDEX_TYPE_BACKGROUP},
 	}
+	// the host servers may evaluate the same dynamic host group at the same time, a host joins once
+	index["cc_HostGroupMember"] = []storage.Index{
+		storage.Index{Name: "", Columns: []string{"bk_biz_id", "id", "bk_host_id"}, Type: storage.INDEX_TYPE_BACKGROUP_UNIQUE},
+	}
 	index["cc_ObjAsst"] = []storage.Index{
 		storage.Index{Name: "", Columns: []string{"bk_obj_id"}, Type: storage.INDEX_TYPE_BACKGROUP},
 		storage.Index{Name: "", Columns: []string{"bk_asst_obj_id"}, Type: storage.INDEX_TYPE_BACKGROUP},
@@ -90,6 +96,7 @@ func getIndex() map[string][]storage.Index {
 	index["cc_OperationLog"] = []storage.Index{
 		storage.Index{Name: "", Columns: []string{"bk_obj_id"}, Type: storage.INDEX_TYPE_BACKGROUP},
 		storage.Index{Name: "", Columns: []string{"bk_supplier_account"}, Type: storage.INDEX_TYPE_BACKGROUP},
+		storage.Index{Name: "", Columns: []string{"changes.bk_property_id"}, Type: storage.INDEX_TYPE_BACKGROUP},
 	}
 	index["cc_PlatBase"] = []storage.Index{
 		storage.Index{Name: "", Columns: []string{"bk_supplier_account"}, Type: storage.INDEX_TYPE_BACKGROUP},
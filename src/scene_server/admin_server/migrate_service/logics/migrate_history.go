@@ -0,0 +1,150 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/scene_server/admin_server/migrateregister"
+	dbStorage "configcenter/src/storage"
+)
+
+// systemTypeMigrate the type of the migrate history record in cc_System
+const systemTypeMigrate = "migrate"
+
+// MigrateHistory the applied migration record
+type MigrateHistory struct {
+	Type        string    `json:"type" bson:"type"`
+	OwnerID     string    `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	Version     string    `json:"version" bson:"version"`
+	Name        string    `json:"name" bson:"name"`
+	Revision    int       `json:"revision" bson:"revision"`
+	MigrateType string    `json:"migrate_type" bson:"migrate_type"`
+	Checksum    string    `json:"checksum" bson:"checksum"`
+	AppliedAt   time.Time `json:"applied_at" bson:"applied_at"`
+}
+
+// MigrateStep the migration step description
+type MigrateStep struct {
+	Version     string `json:"version"`
+	Name        string `json:"name"`
+	Revision    int    `json:"revision"`
+	MigrateType string `json:"migrate_type"`
+	Checksum    string `json:"checksum"`
+}
+
+// MigrateStatus the migrate status of the owner
+type MigrateStatus struct {
+	OwnerID string           `json:"bk_supplier_account"`
+	Applied []MigrateHistory `json:"applied"`
+	// Pending the steps not applied yet or applied with a lower revision
+	Pending []MigrateStep `json:"pending"`
+	// Changed the applied steps whose revision is lower than the applied one, they will not be run again
+	Changed []MigrateStep `json:"changed"`
+}
+
+func newMigrateStep(m *migrateregister.Migration) MigrateStep {
+	return MigrateStep{
+		Version:     m.Version,
+		Name:        m.Name,
+		Revision:    m.Revision,
+		MigrateType: m.Type.String(),
+		Checksum:    m.Checksum(),
+	}
+}
+
+// createSystemTable create the table which keeps the migrate history
+func createSystemTable(instData dbStorage.DI) error {
+	isExist, err := instData.HasTable(common.BKTableNameSystem)
+	if nil != err {
+		blog.Errorf("create %s table error %v", common.BKTableNameSystem, err)
+		return err
+	}
+	if !isExist {
+		err = instData.CreateTable(common.BKTableNameSystem)
+		if nil != err {
+			blog.Errorf("create %s table error %v", common.BKTableNameSystem, err)
+			return err
+		}
+	}
+	return instData.Index(common.BKTableNameSystem, dbStorage.GetMongoIndex("", []string{"type", common.BKOwnerIDField, "version", "name"}, false, true))
+}
+
+// getMigrateHistory return the applied migration records of the owner, keyed by the migration key,
+// the latest one is kept if the step is applied again after revised
+func getMigrateHistory(ownerID string, instData dbStorage.DI) (map[string]MigrateHistory, []MigrateHistory, error) {
+	condition := map[string]interface{}{
+		"type":                systemTypeMigrate,
+		common.BKOwnerIDField: ownerID,
+	}
+	histories := make([]MigrateHistory, 0)
+	err := instData.GetMutilByCondition(common.BKTableNameSystem, nil, condition, &histories, "applied_at", 0, 0)
+	if nil != err {
+		blog.Errorf("get migrate history error %v", err)
+		return nil, nil, err
+	}
+	applied := make(map[string]MigrateHistory, len(histories))
+	for _, history := range histories {
+		applied[history.Version+":"+history.Name] = history
+	}
+	return applied, histories, nil
+}
+
+// saveMigrateHistory record the migration as applied, the records of the former revisions are kept
+func saveMigrateHistory(ownerID string, m *migrateregister.Migration, instData dbStorage.DI) error {
+	history := MigrateHistory{
+		Type:        systemTypeMigrate,
+		OwnerID:     ownerID,
+		Version:     m.Version,
+		Name:        m.Name,
+		Revision:    m.Revision,
+		MigrateType: m.Type.String(),
+		Checksum:    m.Checksum(),
+		AppliedAt:   time.Now(),
+	}
+	_, err := instData.Insert(common.BKTableNameSystem, history)
+	return err
+}
+
+// GetMigrateStatus return the applied and pending migrations of the owner
+func GetMigrateStatus(ownerID string, instData dbStorage.DI) (*MigrateStatus, error) {
+	if "" == ownerID {
+		ownerID = common.BKDefaultOwnerID
+	}
+	if err := createSystemTable(instData); nil != err {
+		return nil, err
+	}
+	applied, histories, err := getMigrateHistory(ownerID, instData)
+	if nil != err {
+		return nil, err
+	}
+
+	status := &MigrateStatus{
+		OwnerID: ownerID,
+		Applied: histories,
+		Pending: []MigrateStep{},
+		Changed: []MigrateStep{},
+	}
+	for _, m := range migrateregister.GetMigrations() {
+		history, ok := applied[m.Key()]
+		switch {
+		case !ok || history.Revision < m.Revision:
+			status.Pending = append(status.Pending, newMigrateStep(m))
+		case history.Revision > m.Revision:
+			status.Changed = append(status.Changed, newMigrateStep(m))
+		}
+	}
+	return status, nil
+}
@@ -59,7 +59,7 @@ func (m *migrateObjAsst) addData(ownerID string, metaData dbStorage.DI, instData
 
 func init() {
 	m := &migrateObjAsst{tableName: "cc_ObjAsst"}
-	migrateregister.RegisterMigrateAction(m.createTable, migrateregister.MigrateTypeCreateTable)
-	migrateregister.RegisterMigrateAction(m.addData, migrateregister.MigrateTypeAddData)
-	migrateregister.RegisterMigrateAction(m.alterTable, migrateregister.MigrateTypeAlterTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+m.tableName, 1, m.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "add_data_"+m.tableName, 1, m.addData, migrateregister.MigrateTypeAddData)
+	migrateregister.RegisterMigrateAction("v3.0.6", "alter_table_"+m.tableName, 1, m.alterTable, migrateregister.MigrateTypeAlterTable)
 }
@@ -60,9 +60,14 @@ func (m *migrateObjAttrDesc) alterTable(ownerID string, metaData dbStorage.DI, i
 	return nil
 }
 
+func (m *migrateObjAttrDesc) updateHostIPOption(ownerID string, metaData dbStorage.DI, instData dbStorage.DI) error {
+	return models.UpdateHostIPOption(m.tableName, ownerID, metaData)
+}
+
 func init() {
 	mObjAttrDesc := &migrateObjAttrDesc{tableName: "cc_ObjAttDes"}
-	migrateregister.RegisterMigrateAction(mObjAttrDesc.createTable, migrateregister.MigrateTypeCreateTable)
-	migrateregister.RegisterMigrateAction(mObjAttrDesc.addData, migrateregister.MigrateTypeAddData)
-	migrateregister.RegisterMigrateAction(mObjAttrDesc.alterTable, migrateregister.MigrateTypeAlterTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+mObjAttrDesc.tableName, 1, mObjAttrDesc.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "add_data_"+mObjAttrDesc.tableName, 1, mObjAttrDesc.addData, migrateregister.MigrateTypeAddData)
+	migrateregister.RegisterMigrateAction("v3.0.6", "alter_table_"+mObjAttrDesc.tableName, 1, mObjAttrDesc.alterTable, migrateregister.MigrateTypeAlterTable)
+	migrateregister.RegisterMigrateAction("v3.0.7", "update_host_ip_option_"+mObjAttrDesc.tableName, 1, mObjAttrDesc.updateHostIPOption, migrateregister.MigrateTypeUpdateData)
 }
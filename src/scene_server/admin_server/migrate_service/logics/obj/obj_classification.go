@@ -53,6 +53,6 @@ func (m *migrateObjClassification) AddData(ownerID string, metaData dbStorage.DI
 
 func init() {
 	m := &migrateObjClassification{tableName: "cc_ObjClassification"}
-	migrateregister.RegisterMigrateAction(m.createTable, migrateregister.MigrateTypeCreateTable)
-	migrateregister.RegisterMigrateAction(m.AddData, migrateregister.MigrateTypeAddData)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+m.tableName, 1, m.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "add_data_"+m.tableName, 1, m.AddData, migrateregister.MigrateTypeAddData)
 }
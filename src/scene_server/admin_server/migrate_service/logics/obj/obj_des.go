@@ -65,7 +65,7 @@ func (m *migrateObjDes) alterTable(ownerID string, metaData dbStorage.DI, instDa
 
 func init() {
 	m := &migrateObjDes{tableName: "cc_ObjDes"}
-	migrateregister.RegisterMigrateAction(m.createTable, migrateregister.MigrateTypeCreateTable)
-	migrateregister.RegisterMigrateAction(m.alterTable, migrateregister.MigrateTypeAlterTable)
-	migrateregister.RegisterMigrateAction(m.addData, migrateregister.MigrateTypeAddData)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+m.tableName, 1, m.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "alter_table_"+m.tableName, 1, m.alterTable, migrateregister.MigrateTypeAlterTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "add_data_"+m.tableName, 1, m.addData, migrateregister.MigrateTypeAddData)
 }
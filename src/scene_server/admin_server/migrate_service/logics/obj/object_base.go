@@ -46,5 +46,5 @@ func (m *migrateObjectBase) createTable(ownerID string, metaData dbStorage.DI, i
 
 func init() {
 	m := &migrateObjectBase{tableName: "cc_ObjectBase"}
-	migrateregister.RegisterMigrateAction(m.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+m.tableName, 1, m.createTable, migrateregister.MigrateTypeCreateTable)
 }
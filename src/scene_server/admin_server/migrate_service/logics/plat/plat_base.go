@@ -51,6 +51,6 @@ func (m *migratePlatBase) addData(ownerID string, metaData dbStorage.DI, instDat
 
 func init() {
 	m := &migratePlatBase{tableName: "cc_PlatBase"}
-	migrateregister.RegisterMigrateAction(m.createTable, migrateregister.MigrateTypeCreateTable)
-	migrateregister.RegisterMigrateAction(m.addData, migrateregister.MigrateTypeAddData)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+m.tableName, 1, m.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "add_data_"+m.tableName, 1, m.addData, migrateregister.MigrateTypeAddData)
 }
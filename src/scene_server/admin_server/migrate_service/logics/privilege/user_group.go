@@ -46,5 +46,5 @@ func (m *migrateUserGroup) createTable(ownerID string, metaData dbStorage.DI, in
 
 func init() {
 	m := &migrateUserGroup{tableName: "cc_UserGroup"}
-	migrateregister.RegisterMigrateAction(m.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+m.tableName, 1, m.createTable, migrateregister.MigrateTypeCreateTable)
 }
@@ -46,5 +46,5 @@ func (m *migrateUserGroupPrivilege) createTable(ownerID string, metaData dbStora
 
 func init() {
 	m := &migrateUserGroupPrivilege{tableName: "cc_UserGroupPrivilege"}
-	migrateregister.RegisterMigrateAction(m.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+m.tableName, 1, m.createTable, migrateregister.MigrateTypeCreateTable)
 }
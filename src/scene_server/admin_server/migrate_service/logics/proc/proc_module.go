@@ -46,5 +46,5 @@ func (m *migrateProcModule) createTable(ownerID string, metaData dbStorage.DI, i
 
 func init() {
 	m := &migrateProcModule{tableName: "cc_Proc2Module"}
-	migrateregister.RegisterMigrateAction(m.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+m.tableName, 1, m.createTable, migrateregister.MigrateTypeCreateTable)
 }
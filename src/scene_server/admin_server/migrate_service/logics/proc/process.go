@@ -47,5 +47,5 @@ func (m *migrateProcess) createTable(ownerID string, metaData dbStorage.DI, inst
 
 func init() {
 	m := &migrateProcess{tableName: "cc_Process"}
-	migrateregister.RegisterMigrateAction(m.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+m.tableName, 1, m.createTable, migrateregister.MigrateTypeCreateTable)
 }
@@ -48,5 +48,5 @@ func (m *migrateApplictaonBase) createTable(ownerID string, metaData dbStorage.D
 
 func init() {
 	m := &migrateApplictaonBase{tableName: "cc_ApplicationBase"}
-	migrateregister.RegisterMigrateAction(m.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+m.tableName, 1, m.createTable, migrateregister.MigrateTypeCreateTable)
 }
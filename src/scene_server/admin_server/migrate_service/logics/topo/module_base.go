@@ -46,5 +46,5 @@ func (m *migrateModuleBase) createTable(ownerID string, metaData dbStorage.DI, i
 
 func init() {
 	mModule := &migrateModuleBase{tableName: "cc_ModuleBase"}
-	migrateregister.RegisterMigrateAction(mModule.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+mModule.tableName, 1, mModule.createTable, migrateregister.MigrateTypeCreateTable)
 }
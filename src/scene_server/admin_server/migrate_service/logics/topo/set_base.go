@@ -46,5 +46,5 @@ func (m *migrateSetBase) createTable(ownerID string, metaData dbStorage.DI, inst
 
 func init() {
 	mSet := &migrateSetBase{tableName: "cc_SetBase"}
-	migrateregister.RegisterMigrateAction(mSet.createTable, migrateregister.MigrateTypeCreateTable)
+	migrateregister.RegisterMigrateAction("v3.0.6", "create_table_"+mSet.tableName, 1, mSet.createTable, migrateregister.MigrateTypeCreateTable)
 }
@@ -106,3 +106,20 @@ func AlterObjAttrDesTable(tableName string, metaCli dbStorage.DI) error {
 	}
 	return nil
 }
+
+// UpdateHostIPOption set the ip pattern of the host inner and outer ip, which accepts ipv6 since v3.0.7
+func UpdateHostIPOption(tableName, ownerID string, metaCli dbStorage.DI) error {
+	for _, propertyID := range []string{common.BKHostInnerIPField, common.BKHostOuterIPField} {
+		selector := map[string]interface{}{
+			common.BKObjIDField:      common.BKInnerObjIDHost,
+			common.BKPropertyIDField: propertyID,
+			common.BKOwnerIDField:    ownerID,
+		}
+		data := map[string]interface{}{common.BKOptionField: common.PatternMultipleIP}
+		if err := metaCli.UpdateByCondition(tableName, data, selector); nil != err {
+			blog.Errorf("update the %s option of %s table error %s", propertyID, tableName, err)
+			return err
+		}
+	}
+	return nil
+}
@@ -13,6 +13,12 @@
 package migrateregister
 
 import (
+	"crypto/md5"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
 	dbStorage "configcenter/src/storage"
 )
 
@@ -28,50 +34,111 @@ const (
 	MigrateTypeDelData
 )
 
-type MigrateLogic struct {
-	TableName string
-}
-
-var createTableAction []func(ownerID string, mysql dbStorage.DI, mgo dbStorage.DI) error
-var alterTableAction []func(ownerID string, mysql dbStorage.DI, mgo dbStorage.DI) error
-var dropTableAction []func(ownerID string, mysql dbStorage.DI, mgo dbStorage.DI) error
-
-var addDataAction []func(ownerID string, mysql dbStorage.DI, mgo dbStorage.DI) error
-var updateDataAction []func(ownerID string, mysql dbStorage.DI, mgo dbStorage.DI) error
-var delDataAction []func(ownerID string, mysql dbStorage.DI, mgo dbStorage.DI) error
-
-func RegisterMigrateAction(f func(ownerID string, mysql dbStorage.DI, mgo dbStorage.DI) error, mType MigrateType) {
-	switch mType {
+// String return the migrate type name
+func (t MigrateType) String() string {
+	switch t {
 	case MigrateTypeCreateTable:
-		createTableAction = append(createTableAction, f)
+		return "create_table"
 	case MigrateTypeAlterTable:
-		alterTableAction = append(alterTableAction, f)
+		return "alter_table"
 	case MigrateTypeDropTable:
-		dropTableAction = append(dropTableAction, f)
+		return "drop_table"
 	case MigrateTypeAddData:
-		addDataAction = append(addDataAction, f)
+		return "add_data"
 	case MigrateTypeUpdateData:
-		updateDataAction = append(updateDataAction, f)
+		return "update_data"
 	case MigrateTypeDelData:
-		delDataAction = append(delDataAction, f)
+		return "del_data"
 	}
+	return "unknown"
 }
 
-func GetMigrateAction(mType MigrateType) []func(ownerID string, mysql dbStorage.DI, mgo dbStorage.DI) error {
-	switch mType {
-	case MigrateTypeCreateTable:
-		return createTableAction
-	case MigrateTypeAlterTable:
-		return alterTableAction
-	case MigrateTypeDropTable:
-		return dropTableAction
-	case MigrateTypeAddData:
-		return addDataAction
-	case MigrateTypeUpdateData:
-		return updateDataAction
-	case MigrateTypeDelData:
-		return delDataAction
+type MigrateLogic struct {
+	TableName string
+}
+
+// MigrateFunc the migrate step handler
+type MigrateFunc func(ownerID string, mysql dbStorage.DI, mgo dbStorage.DI) error
+
+// Migration a versioned migrate step
+type Migration struct {
+	Version string
+	Name    string
+	// Revision the revision of the step definition, it must be increased once the handler changes,
+	// the step applied with a lower revision is run again
+	Revision int
+	Type     MigrateType
+	Handler  MigrateFunc
+
+	// seq the register order, keep the order of the steps with the same version and type
+	seq int
+}
+
+// Key return the unique key of the migration
+func (m *Migration) Key() string {
+	return m.Version + ":" + m.Name
+}
+
+// Checksum return the digest of the migration definition, it changes when the step is revised
+func (m *Migration) Checksum() string {
+	sum := md5.Sum([]byte(fmt.Sprintf("%s|%s|%d", m.Version, m.Name, m.Revision)))
+	return fmt.Sprintf("%x", sum)
+}
+
+var migrations = map[string]*Migration{}
+
+// RegisterMigrateAction register a migrate step with the version it belongs to,
+// the name must be unique in the version, the revision starts from 1 and must be increased once the handler changes,
+// the handler must be idempotent as it is run again on the revised steps
+func RegisterMigrateAction(version, name string, revision int, f MigrateFunc, mType MigrateType) {
+	m := &Migration{Version: version, Name: name, Revision: revision, Type: mType, Handler: f, seq: len(migrations)}
+	if revision < 1 {
+		panic(fmt.Sprintf("migration %s requires a revision", m.Key()))
+	}
+	if _, ok := migrations[m.Key()]; ok {
+		panic(fmt.Sprintf("duplicate migration %s", m.Key()))
+	}
+	migrations[m.Key()] = m
+}
+
+// GetMigrations return all the registered migrations,
+// ordered by version, then by migrate type, then by the register order
+func GetMigrations() []*Migration {
+	result := make([]*Migration, 0, len(migrations))
+	for _, m := range migrations {
+		result = append(result, m)
 	}
+	sort.Slice(result, func(i, j int) bool {
+		if cmp := CompareVersion(result[i].Version, result[j].Version); 0 != cmp {
+			return cmp < 0
+		}
+		if result[i].Type != result[j].Type {
+			return result[i].Type < result[j].Type
+		}
+		return result[i].seq < result[j].seq
+	})
+	return result
+}
 
-	return nil
+// CompareVersion compare the version like v3.0.6,
+// return -1 if a < b, 0 if a == b, 1 if a > b
+func CompareVersion(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(strings.TrimSpace(a), "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(strings.TrimSpace(b), "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var av, bv int
+		if i < len(as) {
+			av, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			bv, _ = strconv.Atoi(bs[i])
+		}
+		if av < bv {
+			return -1
+		}
+		if av > bv {
+			return 1
+		}
+	}
+	return 0
 }
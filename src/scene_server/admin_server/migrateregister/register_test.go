@@ -0,0 +1,56 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migrateregister
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	dbStorage "configcenter/src/storage"
+)
+
+func TestCompareVersion(t *testing.T) {
+	require.Equal(t, 0, CompareVersion("v3.0.6", "v3.0.6"))
+	require.Equal(t, -1, CompareVersion("v3.0.6", "v3.0.10"))
+	require.Equal(t, 1, CompareVersion("v3.1", "v3.0.9"))
+	require.Equal(t, 0, CompareVersion("v3.1", "3.1.0"))
+}
+
+func TestGetMigrations(t *testing.T) {
+	f := func(ownerID string, mysql dbStorage.DI, mgo dbStorage.DI) error { return nil }
+	RegisterMigrateAction("v3.0.7", "add_data_b", 1, f, MigrateTypeAddData)
+	RegisterMigrateAction("v3.0.7", "create_table_b", 1, f, MigrateTypeCreateTable)
+	RegisterMigrateAction("v3.0.6", "add_data_a", 1, f, MigrateTypeAddData)
+	RegisterMigrateAction("v3.0.6", "create_table_a", 1, f, MigrateTypeCreateTable)
+
+	names := []string{}
+	for _, m := range GetMigrations() {
+		names = append(names, m.Name)
+	}
+	require.Equal(t, []string{"create_table_a", "add_data_a", "create_table_b", "add_data_b"}, names)
+
+	require.Panics(t, func() { RegisterMigrateAction("v3.0.6", "add_data_a", 1, f, MigrateTypeAddData) })
+	require.Panics(t, func() { RegisterMigrateAction("v3.0.6", "add_data_c", 0, f, MigrateTypeAddData) })
+}
+
+func TestChecksum(t *testing.T) {
+	f := func(ownerID string, mysql dbStorage.DI, mgo dbStorage.DI) error { return nil }
+	m := &Migration{Version: "v3.0.6", Name: "add_data_a", Revision: 1, Type: MigrateTypeAddData, Handler: f}
+	revised := *m
+	revised.Revision = 2
+	require.NotEqual(t, m.Checksum(), revised.Checksum())
+	require.Equal(t, m.Checksum(), (&Migration{Version: "v3.0.6", Name: "add_data_a", Revision: 1, Type: MigrateTypeAddData, Handler: f}).Checksum())
+	// the handler is not a part of the definition, e.g. it is renamed by a refactoring
+	require.Equal(t, m.Checksum(), (&Migration{Version: "v3.0.6", Name: "add_data_a", Revision: 1, Type: MigrateTypeAddData}).Checksum())
+}
@@ -23,3 +23,10 @@ func (cli *Client) ReForwardCreateMetaTopo(callfunc func(url, method string) (st
 		return callfunc(fmt.Sprintf("%s/topo/v1/model/mainline", cli.address), common.HTTPCreate)
 	}
 }
+
+func (cli *Client) ReForwardImportTopoBundle(callfunc func(url, method string) (string, error), ownerid string) func() (string, error) {
+
+	return func() (string, error) {
+		return callfunc(fmt.Sprintf("%s/topo/v1/bundle/%s/import", cli.address, ownerid), common.HTTPCreate)
+	}
+}
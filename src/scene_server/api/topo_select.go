@@ -44,3 +44,10 @@ func (cli *Client) ReForwardSelectMetaTopo(callfunc func(url, method string) (st
 		return callfunc(fmt.Sprintf("%s/topo/v1/model/%s", cli.address, ownerid), common.HTTPSelectGet)
 	}
 }
+
+func (cli *Client) ReForwardExportTopoBundle(callfunc func(url, method string) (string, error), ownerid, appid string) func() (string, error) {
+
+	return func() (string, error) {
+		return callfunc(fmt.Sprintf("%s/topo/v1/bundle/%s/%s", cli.address, ownerid, appid), common.HTTPSelectGet)
+	}
+}
@@ -19,6 +19,8 @@ import (
 	"configcenter/src/common/core/cc/actions"
 	"configcenter/src/scene_server/datacollection/datacollection/logics"
 	"configcenter/src/source_controller/common/instdata"
+	"configcenter/src/storage"
+	"configcenter/src/storage/redisclient"
 	"fmt"
 	"gopkg.in/redis.v5"
 	"strconv"
@@ -83,7 +85,7 @@ func getChanName() (string, error) {
 	return defaultAppID + "_snapshot", nil
 }
 
-func getSnapClient(config map[string]string, dType string) (*redis.Client, error) {
+func getSnapClient(config map[string]string, dType string) (storage.Cache, error) {
 	mastername := config[dType+".mastername"]
 	host := config[dType+".host"]
 	auth := config[dType+".pwd"]
@@ -114,7 +116,7 @@ func getSnapClient(config map[string]string, dType string) (*redis.Client, error
 	if err != nil {
 		return nil, err
 	}
-	return client, nil
+	return redisclient.NewCache(client), nil
 }
 
 func mock(config map[string]string) {
@@ -129,7 +131,7 @@ func mock(config map[string]string) {
 	var ts = time.Now()
 	var cnt int64
 	for {
-		err := mockCli.Publish(config["snap-redis.chan"], MOCKMSG).Err()
+		err := mockCli.Publish(config["snap-redis.chan"], MOCKMSG)
 		if err != nil {
 			blog.Error("publish mock fail", err.Error())
 		}
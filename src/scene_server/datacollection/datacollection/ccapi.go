@@ -13,12 +13,13 @@
 package ccapi
 
 import (
+	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/api"
 	"configcenter/src/common/core/cc/config"
-	"configcenter/src/common/blog"
+	"configcenter/src/common/discovery"
 	"configcenter/src/common/http/httpserver"
+	"configcenter/src/common/types"
 	confCenter "configcenter/src/scene_server/datacollection/datacollection/config"
-	"configcenter/src/scene_server/datacollection/datacollection/rdiscover"
 	"configcenter/src/source_controller/common/instdata"
 	"time"
 )
@@ -27,7 +28,7 @@ import (
 type CCAPIServer struct {
 	conf     *config.CCAPIConfig
 	httpServ *httpserver.HttpServer
-	rd       *rdiscover.RegDiscover
+	rd       *discovery.RegDiscover
 	cfCenter *confCenter.ConfCenter
 }
 
@@ -46,10 +47,10 @@ func NewCCAPIServer(conf *config.CCAPIConfig) (*CCAPIServer, error) {
 	a.SetConfig(s.conf)
 	a.InitAction()
 
-	s.rd = rdiscover.NewRegDiscover(s.conf.RegDiscover, addr, port, false)
+	s.rd = discovery.NewRegDiscover(s.conf.GetRegDiscover(), types.CC_MODULE_DATACOLLECTION, addr, port, false, discovery.Options{})
 
 	//ConfCenter
-	s.cfCenter = confCenter.NewConfCenter(s.conf.RegDiscover)
+	s.cfCenter = confCenter.NewConfCenter(s.conf.GetRegDiscover())
 
 	return s, nil
 }
@@ -96,8 +97,13 @@ func (ccAPI *CCAPIServer) Start() error {
 		}
 	}
 
-	//http server
+	//http server, it serves the metrics only
 	ccAPI.initHTTPServ()
+	go func() {
+		err := ccAPI.httpServ.ListenAndServe()
+		blog.Errorf("http listen and serve failed! err:%s", err.Error())
+		chErr <- err
+	}()
 
 	err := a.GetDataCli(config, "mongodb")
 	if err != nil {
@@ -129,7 +135,8 @@ func (ccAPI *CCAPIServer) Start() error {
 }
 
 func (ccAPI *CCAPIServer) initHTTPServ() error {
-	// a := api.NewAPIResource()
-	// ccAPI.httpServ.RegisterWebServer("/datacollection/{version}", nil, a.Actions)
+	a := api.NewAPIResource()
+	ccAPI.httpServ.RegisterWebServer("/datacollection/{version}", nil, a.Actions)
+	a.RegisterHealth(ccAPI.httpServ)
 	return nil
 }
@@ -15,20 +15,22 @@ package logics
 import (
 	bkcommon "configcenter/src/common"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/lifecycle"
+	"configcenter/src/common/metrics"
+	"configcenter/src/common/util"
 	"configcenter/src/scene_server/datacollection/common"
 	"configcenter/src/source_controller/common/instdata"
+	"configcenter/src/storage"
 	"fmt"
 	"github.com/rs/xid"
 	"github.com/tidwall/gjson"
-	"io"
+	"net"
 	"runtime"
 	"strings"
 	"sync"
 	"sync/atomic"
 
 	"time"
-
-	redis "gopkg.in/redis.v5"
 )
 
 // const
@@ -45,6 +47,12 @@ var (
 	maxconcurrent   = runtime.NumCPU()
 )
 
+var (
+	snapshotTotal  = metrics.NewCounterVec("cc_datacollection_snapshots_total", "the host snapshots handled, by result", "result")
+	handleRoutines = metrics.NewGaugeFunc("cc_datacollection_handle_routines", "the routines handling the snapshot messages", nil,
+		func() []metrics.Sample { return []metrics.Sample{{Value: float64(atomic.LoadInt64(&routeCnt))}} })
+)
+
 // HostSnap define HostSnap
 type HostSnap struct {
 	id       string
@@ -59,8 +67,8 @@ type HostSnap struct {
 
 	resetHandle chan struct{}
 
-	redisCli *redis.Client
-	snapCli  *redis.Client
+	redisCli storage.Cache
+	snapCli  storage.Cache
 
 	subscribing bool
 
@@ -77,7 +85,7 @@ type hostcache struct {
 // NewHostSnap  returns new hostsnap object
 //
 // chanName: redis channel name，maxSize: max buffer cache, redisCli: CC redis cli, snapCli: snap redis cli
-func NewHostSnap(chanName string, maxSize int, redisCli, snapCli *redis.Client) *HostSnap {
+func NewHostSnap(chanName string, maxSize int, redisCli, snapCli storage.Cache) *HostSnap {
 	if 0 == maxSize {
 		maxSize = 100
 	}
@@ -116,6 +124,7 @@ func (h *HostSnap) Run() {
 	var msgs []string
 	var addCount int
 	var waitCnt int
+	worker := lifecycle.NewWorker("hostsnap")
 
 	if h.saveRunning() {
 		go h.subChan()
@@ -133,6 +142,12 @@ func (h *HostSnap) Run() {
 				blog.Infof("loop: there is other master process exists, recheck after %v ", getMasterProcIntervalTime)
 			}
 		case msg = <-h.msgChan:
+			if !worker.Begin() {
+				// give up the master, so that another process takes over the snapshots at once
+				blog.Info("hostsnap stopped by shutdown")
+				h.concede()
+				worker.Park()
+			}
 			// read all from msgChan and lock to prevent clear operation
 			h.Lock()
 			h.ts = time.Now()
@@ -165,7 +180,10 @@ func (h *HostSnap) Run() {
 				}
 				if atomic.LoadInt64(&routeCnt) < int64(h.maxconcurrent) {
 					atomic.AddInt64(&routeCnt, 1)
-					go h.handleMsg(msgs, h.resetHandle)
+					go func(msgs []string, resetHandle chan struct{}) {
+						defer worker.End()
+						h.handleMsg(msgs, resetHandle)
+					}(msgs, h.resetHandle)
 					break
 				}
 				waitCnt++
@@ -208,10 +226,12 @@ func (h *HostSnap) handleMsg(msgs []string, resetHandle chan struct{}) error {
 			val := gjson.Parse(data)
 			host := h.getHostByVal(&val)
 			if host == nil {
+				snapshotTotal.Inc("unknown_host")
 				continue
 			}
 			hostid := fmt.Sprint(host[bkcommon.BKHostIDField])
 			if hostid == "" {
+				snapshotTotal.Inc("unknown_host")
 				continue
 			}
 
@@ -227,8 +247,13 @@ func (h *HostSnap) handleMsg(msgs []string, resetHandle chan struct{}) error {
 				blog.Infof("update by %v, to %v", condition, setter)
 				if err := instdata.UpdateHostByCondition(setter, condition); err != nil {
 					blog.Error("update host error:", err.Error())
+					snapshotTotal.Inc("failed")
+				} else {
+					snapshotTotal.Inc("updated")
 				}
 				copyVal(setter, host)
+			} else {
+				snapshotTotal.Inc("unchanged")
 			}
 		}
 	}
@@ -282,12 +307,17 @@ func parseSetter(val *gjson.Result, innerIP, outerIP string) map[string]interfac
 		osname = fmt.Sprintf("%s", platform)
 	}
 	var OuterMAC, InnerMAC string
+	innerIPs := strings.Split(innerIP, util.IPListSeparator)
+	outerIPs := strings.Split(outerIP, util.IPListSeparator)
 	for _, inter := range val.Get("data.net.interface").Array() {
 		for _, addr := range inter.Get("addrs.#.addr").Array() {
-			ip := strings.Split(addr.String(), "/")[0]
-			if ip == innerIP {
+			ip := snapIP(strings.Split(addr.String(), "/")[0])
+			if "" == ip {
+				continue
+			}
+			if util.InArray(ip, innerIPs) {
 				InnerMAC = inter.Get("hardwareaddr").String()
-			} else if ip == outerIP {
+			} else if util.InArray(ip, outerIPs) {
 				OuterMAC = inter.Get("hardwareaddr").String()
 			}
 		}
@@ -307,23 +337,32 @@ func parseSetter(val *gjson.Result, innerIP, outerIP string) map[string]interfac
 		"bk_mac":        InnerMAC,
 	}
 }
+
+// getIPS return the canonical ips of the interfaces and the reported ip
 func getIPS(val *gjson.Result) (ips []string) {
 	interfaces := val.Get("data.net.interface.#.addrs.#.addr").Array()
 	for _, addrs := range interfaces {
 		for _, addr := range addrs.Array() {
-			ip := strings.Split(addr.String(), "/")[0]
-			if strings.HasPrefix(ip, "127.0.0.") {
-				continue
+			if ip := snapIP(strings.Split(addr.String(), "/")[0]); "" != ip {
+				ips = append(ips, ip)
 			}
-			ips = append(ips, ip)
 		}
 	}
-	if !strings.HasPrefix(val.Get("ip").String(), "127.0.0.") {
-		ips = append(ips, val.Get("ip").String())
+	if ip := snapIP(val.Get("ip").String()); "" != ip {
+		ips = append(ips, ip)
 	}
 	return ips
 }
 
+// snapIP return the canonical ip, the loopback, link local and invalid ones are ignored as empty
+func snapIP(text string) string {
+	ip := net.ParseIP(strings.TrimSpace(text))
+	if nil == ip || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() {
+		return ""
+	}
+	return ip.String()
+}
+
 func (h *HostSnap) getHostByVal(val *gjson.Result) map[string]interface{} {
 	cloudid := val.Get("cloudid").String()
 	/*if cloudid == "0" || cloudid == "" {
@@ -333,8 +372,10 @@ func (h *HostSnap) getHostByVal(val *gjson.Result) map[string]interface{} {
 	if len(ips) > 0 {
 		for _, host := range h.getCache() {
 			if fmt.Sprint(host[bkcommon.BKCloudIDField]) == cloudid {
+				innerIP, _ := host[bkcommon.BKHostInnerIPField].(string)
+				hostIPs := strings.Split(innerIP, util.IPListSeparator)
 				for _, ip := range ips {
-					if ip == host[bkcommon.BKHostInnerIPField] {
+					if util.InArray(ip, hostIPs) {
 						return host
 					}
 				}
@@ -349,40 +390,28 @@ func (h *HostSnap) concede() {
 	blog.Info("concede")
 	h.isMaster = false
 	h.subscribing = false
-	val := h.redisCli.Get(common.MASTER_PROC_LOCK_KEY).Val()
-	if len(val) > 5 && h.id == val[0:5] {
-		h.redisCli.Del(common.MASTER_PROC_LOCK_KEY)
+	if err := h.redisCli.Unlock(common.MASTER_PROC_LOCK_KEY, h.id); err != nil {
+		blog.Errorf("concede: unlock err %v", err)
 	}
 }
 
 // saveRunning lock master process
 func (h *HostSnap) saveRunning() (ok bool) {
-	var err error
+	ok, err := h.redisCli.Lock(common.MASTER_PROC_LOCK_KEY, h.id, masterProcLockLiveTime)
+	if err != nil {
+		blog.Errorf("saveRunning err %v", err)
+	}
 	if h.isMaster {
-		var val string
-		val, err = h.redisCli.Get(common.MASTER_PROC_LOCK_KEY).Result()
-		if err != nil {
-			blog.Errorf("master: saveRunning err %v", err)
-		}
-		if len(val) > 5 && h.id == val[0:len(h.id)] {
+		if ok {
 			blog.Infof("master check : i am still master")
-			h.redisCli.Set(common.MASTER_PROC_LOCK_KEY, h.id+"||"+time.Now().Format(time.RFC3339), masterProcLockLiveTime)
-			ok = true
 		} else {
-			blog.Infof("exit master,val = %v, id = %v", val, h.id)
-			h.isMaster = false
-		}
-	} else {
-		ok, err = h.redisCli.SetNX(common.MASTER_PROC_LOCK_KEY, h.id+"||"+time.Now().Format(time.RFC3339), masterProcLockLiveTime).Result()
-		if err != nil {
-			blog.Errorf("slave: saveRunning err %v", err)
-		}
-		if ok {
-			blog.Infof("slave check: ok")
-			blog.Infof("i am master from now")
-			h.isMaster = true
+			blog.Infof("exit master, id = %v", h.id)
 		}
+	} else if ok {
+		blog.Infof("slave check: ok")
+		blog.Infof("i am master from now")
 	}
+	h.isMaster = ok
 	return ok
 }
 
@@ -394,11 +423,13 @@ func (h *HostSnap) subChan() {
 	var l int
 	subChan, err := h.snapCli.Subscribe(h.chanName)
 	if nil != err {
-		h.interrupt <- err
+		h.subscribing = false
 		blog.Error("subscribe channel faile ", err.Error())
+		h.interrupt <- err
+		return
 	}
 	defer func() {
-		subChan.Unsubscribe(h.chanName)
+		subChan.Close()
 		h.subscribing = false
 		blog.Infof("subChan Close")
 	}()
@@ -412,18 +443,11 @@ func (h *HostSnap) subChan() {
 			blog.Info("This is not master process, subChan Close")
 			return
 		}
-		received, err := subChan.Receive()
-		if err == redis.Nil || err == io.EOF {
-			continue
-		}
-		msg, ok := received.(*redis.Message)
-		if !ok {
-			continue
-		}
+		msg, err := subChan.Receive()
 		if nil != err {
 			blog.Debug("receive messave  err", err.Error())
 			h.interrupt <- err
-			continue
+			return
 		}
 
 		if "" == msg.Payload {
@@ -492,6 +516,20 @@ func fetch() []map[string]interface{} {
 	if err != nil {
 		blog.Errorf("fetch db error %v", err)
 	}
+	for _, host := range result {
+		normalizeHostIP(host)
+	}
 	blog.Infof("success fetch %d collections to cache", len(result))
 	return result
 }
+
+// normalizeHostIP make the ips of the cached host canonical to match the snapshot ips
+func normalizeHostIP(host map[string]interface{}) {
+	for _, field := range []string{bkcommon.BKHostInnerIPField, bkcommon.BKHostOuterIPField} {
+		ips, ok := host[field].(string)
+		if false == ok {
+			continue
+		}
+		host[field] = strings.Join(util.SplitIPList(ips), util.IPListSeparator)
+	}
+}
@@ -13,11 +13,31 @@
 package logics
 
 import (
+	"configcenter/src/storage/memclient"
 	"github.com/stretchr/testify/assert"
 	"github.com/tidwall/gjson"
 	"testing"
 )
 
+func TestSaveRunning(t *testing.T) {
+	cache := memclient.NewMemRedis()
+	first := NewHostSnap("snapshot", 0, cache, cache)
+	second := NewHostSnap("snapshot", 0, cache, cache)
+
+	assert.True(t, first.saveRunning())
+	assert.True(t, first.isMaster)
+	assert.False(t, second.saveRunning())
+	assert.False(t, second.isMaster)
+
+	// the master renews its lock
+	assert.True(t, first.saveRunning())
+
+	first.concede()
+	assert.False(t, first.isMaster)
+	assert.True(t, second.saveRunning())
+	assert.False(t, first.saveRunning())
+}
+
 func TestNeedToUpdate(t *testing.T) {
 	need := needToUpdate(map[string]interface{}{"name": "a"}, map[string]interface{}{"name": "a"})
 	if need {
@@ -62,6 +82,37 @@ func TestGetSetter(t *testing.T) {
 	}
 }
 
+func TestGetIPSDualStack(t *testing.T) {
+	val := gjson.Parse(dualStackExample)
+	ips := getIPS(&val)
+	assert.Equal(t, []string{"10.0.0.8", "2001:db8::8", "192.168.0.8", "10.0.0.8"}, ips)
+}
+
+func TestParseSetterDualStack(t *testing.T) {
+	val := gjson.Parse(dualStackExample)
+	host := map[string]interface{}{"bk_host_innerip": "10.0.0.9,2001:DB8:0:0::8", "bk_host_outerip": "192.168.0.8"}
+	normalizeHostIP(host)
+	assert.Equal(t, "10.0.0.9,2001:db8::8", host["bk_host_innerip"])
+
+	actual := parseSetter(&val, host["bk_host_innerip"].(string), host["bk_host_outerip"].(string))
+	assert.Equal(t, "52:54:00:19:2e:e8", actual["bk_mac"])
+	assert.Equal(t, "52:54:00:19:2e:e9", actual["bk_outer_mac"])
+}
+
+var dualStackExample = `{
+    "cloudid":0,
+    "ip":"10.0.0.8",
+    "data":{
+        "net":{
+            "interface":[
+                {"name":"lo","hardwareaddr":"","addrs":[{"addr":"127.0.0.1/8"},{"addr":"::1/128"}]},
+                {"name":"eth0","hardwareaddr":"52:54:00:19:2e:e8","addrs":[{"addr":"10.0.0.8/24"},{"addr":"2001:0db8::0008/64"},{"addr":"fe80::5054:ff:fe19:2ee8/64"}]},
+                {"name":"eth1","hardwareaddr":"52:54:00:19:2e:e9","addrs":[{"addr":"192.168.0.8/24"}]}
+            ]
+        }
+    }
+}`
+
 var example = `{
     "bizid":0,
     "cloudid":0,
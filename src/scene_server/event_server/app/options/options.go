@@ -38,4 +38,5 @@ func (s *ServerOption) AddFlags(fs *pflag.FlagSet) {
 	//fs.UintVar(&s.ServConf.Port, "port", 60009, "The port for the serve on")
 	fs.StringVar(&s.ServConf.ExConfig, "config", "", "The config path. e.g conf/api.conf")
 	fs.StringVar(&s.ServConf.RegDiscover, "regdiscv", "", "hosts of register and discover server. e.g: 127.0.0.1:2181")
+	fs.StringVar(&s.ServConf.RegDiscoverBackend, "regdiscv-backend", config.RegDiscoverZookeeper, "backend of register and discover server, zookeeper or file. the regdiscv is the registry file path for file backend")
 }
@@ -0,0 +1,192 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package subscription
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/core/cc/actions"
+	paraparse "configcenter/src/common/paraparse"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/event_server/event_service/distribution"
+	"configcenter/src/scene_server/event_server/types"
+	"configcenter/src/source_controller/common/instdata"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+)
+
+// deadLetterParams the dist ids to replay or purge, empty means all
+type deadLetterParams struct {
+	DstbIDs []int64 `json:"dstb_ids"`
+}
+
+// deadLetterSearch the page of the dead letters to list
+type deadLetterSearch struct {
+	Page paraparse.PageInfo `json:"page"`
+}
+
+// SearchDeadLetters list the dead letters of the subscription
+func (cli *subscriptionAction) SearchDeadLetters(req *restful.Request, resp *restful.Response) {
+	// get the language
+	language := util.GetActionLanguage(req)
+	// get the error factory by the language
+	defErr := cli.CC.Error.CreateDefaultCCErrorIf(language)
+
+	cli.CallResponseEx(func() (int, interface{}, error) {
+		var id int64
+		pathParameters := req.PathParameters()
+		if nil != cli.GetParams(cli.CC, &pathParameters, "subscribeID", &id, resp) {
+			return http.StatusBadRequest, nil, defErr.Errorf(common.CCErrCommParamsNeedSet, "subscription_id")
+		}
+
+		value, err := ioutil.ReadAll(req.Request.Body)
+		if err != nil {
+			blog.Error("read request body failed, error information is %s", err.Error())
+			return http.StatusBadRequest, nil, defErr.Error(common.CCErrCommHTTPReadBodyFailed)
+		}
+		dat := deadLetterSearch{}
+		if len(value) > 0 {
+			if err := json.Unmarshal(value, &dat); err != nil {
+				blog.Error("unmarshal json failed, error information is %v", err)
+				return http.StatusBadRequest, nil, defErr.Error(common.CCErrCommJSONUnmarshalFailed)
+			}
+		}
+
+		letters, count, err := distribution.ListDeadLetters(id, dat.Page.Start, dat.Page.Limit)
+		if err != nil {
+			blog.Errorf("list dead letters of subscription %d error: %v", id, err)
+			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrEventDeadLetterSelectFailed)
+		}
+
+		info := make(map[string]interface{})
+		info["count"] = count
+		info["info"] = letters
+		return http.StatusOK, info, nil
+	}, resp)
+}
+
+// GetDeadLetter inspect one dead letter of the subscription
+func (cli *subscriptionAction) GetDeadLetter(req *restful.Request, resp *restful.Response) {
+	// get the language
+	language := util.GetActionLanguage(req)
+	// get the error factory by the language
+	defErr := cli.CC.Error.CreateDefaultCCErrorIf(language)
+
+	cli.CallResponseEx(func() (int, interface{}, error) {
+		var id, dstbID int64
+		pathParameters := req.PathParameters()
+		if nil != cli.GetParams(cli.CC, &pathParameters, "subscribeID", &id, resp) {
+			return http.StatusBadRequest, nil, defErr.Errorf(common.CCErrCommParamsNeedSet, "subscription_id")
+		}
+		if nil != cli.GetParams(cli.CC, &pathParameters, "dstbID", &dstbID, resp) {
+			return http.StatusBadRequest, nil, defErr.Errorf(common.CCErrCommParamsNeedSet, "dstb_id")
+		}
+
+		letter, err := distribution.GetDeadLetter(id, dstbID)
+		if err != nil {
+			blog.Errorf("get dead letter %d of subscription %d error: %v", dstbID, id, err)
+			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrEventDeadLetterSelectFailed)
+		}
+		if letter == nil {
+			return http.StatusNotFound, nil, defErr.Error(common.CCErrCommNotFound)
+		}
+		return http.StatusOK, letter, nil
+	}, resp)
+}
+
+// ReplayDeadLetters send the dead letters to the subscriber again
+func (cli *subscriptionAction) ReplayDeadLetters(req *restful.Request, resp *restful.Response) {
+	// get the language
+	language := util.GetActionLanguage(req)
+	// get the error factory by the language
+	defErr := cli.CC.Error.CreateDefaultCCErrorIf(language)
+
+	cli.CallResponseEx(func() (int, interface{}, error) {
+		var id int64
+		pathParameters := req.PathParameters()
+		if nil != cli.GetParams(cli.CC, &pathParameters, "subscribeID", &id, resp) {
+			return http.StatusBadRequest, nil, defErr.Errorf(common.CCErrCommParamsNeedSet, "subscription_id")
+		}
+		params, err := readDeadLetterParams(req)
+		if err != nil {
+			blog.Error("read dead letter params failed, error information is %v", err)
+			return http.StatusBadRequest, nil, defErr.Error(common.CCErrCommJSONUnmarshalFailed)
+		}
+
+		sub := types.Subscription{}
+		condiction := util.NewMapBuilder(common.BKSubscriptionIDField, id).Build()
+		if err := instdata.GetOneSubscriptionByCondition(condiction, &sub); err != nil {
+			blog.Error("fail to get subscription by id %v, error information is %v", id, err)
+			return http.StatusBadRequest, nil, defErr.Error(common.CCErrEventDeadLetterReplayFailed)
+		}
+
+		result, err := distribution.ReplayDeadLetters(&sub, params.DstbIDs)
+		if err != nil {
+			blog.Errorf("replay dead letters of subscription %d error: %v", id, err)
+			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrEventDeadLetterReplayFailed)
+		}
+		return http.StatusOK, result, nil
+	}, resp)
+}
+
+// DeleteDeadLetters purge the dead letters of the subscription
+func (cli *subscriptionAction) DeleteDeadLetters(req *restful.Request, resp *restful.Response) {
+	// get the language
+	language := util.GetActionLanguage(req)
+	// get the error factory by the language
+	defErr := cli.CC.Error.CreateDefaultCCErrorIf(language)
+
+	cli.CallResponseEx(func() (int, interface{}, error) {
+		var id int64
+		pathParameters := req.PathParameters()
+		if nil != cli.GetParams(cli.CC, &pathParameters, "subscribeID", &id, resp) {
+			return http.StatusBadRequest, nil, defErr.Errorf(common.CCErrCommParamsNeedSet, "subscription_id")
+		}
+		params, err := readDeadLetterParams(req)
+		if err != nil {
+			blog.Error("read dead letter params failed, error information is %v", err)
+			return http.StatusBadRequest, nil, defErr.Error(common.CCErrCommJSONUnmarshalFailed)
+		}
+
+		if err := distribution.DeleteDeadLetters(id, params.DstbIDs); err != nil {
+			blog.Errorf("delete dead letters of subscription %d error: %v", id, err)
+			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrEventDeadLetterDeleteFailed)
+		}
+		return http.StatusOK, nil, nil
+	}, resp)
+}
+
+func readDeadLetterParams(req *restful.Request) (*deadLetterParams, error) {
+	params := &deadLetterParams{}
+	value, err := ioutil.ReadAll(req.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(value) == 0 {
+		return params, nil
+	}
+	if err := json.Unmarshal(value, params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+func init() {
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/subscribe/deadletter/search/{ownerID}/{appID}/{subscribeID}", Params: nil, Handler: eventSubscription.SearchDeadLetters})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/subscribe/deadletter/{ownerID}/{appID}/{subscribeID}/{dstbID}", Params: nil, Handler: eventSubscription.GetDeadLetter})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/subscribe/deadletter/replay/{ownerID}/{appID}/{subscribeID}", Params: nil, Handler: eventSubscription.ReplayDeadLetters})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/subscribe/deadletter/{ownerID}/{appID}/{subscribeID}", Params: nil, Handler: eventSubscription.DeleteDeadLetters})
+}
@@ -0,0 +1,80 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package subscription
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/core/cc/actions"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/event_server/event_service/distribution"
+	"configcenter/src/scene_server/event_server/types"
+	"configcenter/src/source_controller/common/instdata"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+)
+
+// Replay re-deliver the historical events to the subscription from the event id or in the time window
+func (cli *subscriptionAction) Replay(req *restful.Request, resp *restful.Response) {
+	// get the language
+	language := util.GetActionLanguage(req)
+	// get the error factory by the language
+	defErr := cli.CC.Error.CreateDefaultCCErrorIf(language)
+
+	cli.CallResponseEx(func() (int, interface{}, error) {
+		var id int64
+		pathParameters := req.PathParameters()
+		if nil != cli.GetParams(cli.CC, &pathParameters, "subscribeID", &id, resp) {
+			return http.StatusBadRequest, nil, defErr.Errorf(common.CCErrCommParamsNeedSet, "subscription_id")
+		}
+
+		value, err := ioutil.ReadAll(req.Request.Body)
+		if err != nil {
+			blog.Error("read request body failed, error information is %s", err.Error())
+			return http.StatusBadRequest, nil, defErr.Error(common.CCErrCommHTTPReadBodyFailed)
+		}
+		opt := distribution.ReplayOption{}
+		if err := json.Unmarshal(value, &opt); err != nil {
+			blog.Error("unmarshal json failed, error information is %v", err)
+			return http.StatusBadRequest, nil, defErr.Error(common.CCErrCommJSONUnmarshalFailed)
+		}
+		if opt.StartEventID <= 0 && opt.StartTime == nil {
+			return http.StatusBadRequest, nil, defErr.Errorf(common.CCErrCommParamsNeedSet, "start_event_id")
+		}
+
+		sub := types.Subscription{}
+		condiction := util.NewMapBuilder(common.BKSubscriptionIDField, id).Build()
+		if err := instdata.GetOneSubscriptionByCondition(condiction, &sub); err != nil {
+			blog.Error("fail to get subscription by id %v, error information is %v", id, err)
+			return http.StatusBadRequest, nil, defErr.Error(common.CCErrEventReplayFailed)
+		}
+
+		count, err := distribution.ReplayEvents(&sub, opt)
+		if err != nil {
+			blog.Errorf("replay events to subscription %d error: %v, %d events replayed", id, err, count)
+			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrEventReplayFailed)
+		}
+		blog.Infof("replayed %d events to subscription %d", count, id)
+
+		info := make(map[string]interface{})
+		info["count"] = count
+		return http.StatusOK, info, nil
+	}, resp)
+}
+
+func init() {
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/subscribe/replay/{ownerID}/{appID}/{subscribeID}", Params: nil, Handler: eventSubscription.Replay})
+}
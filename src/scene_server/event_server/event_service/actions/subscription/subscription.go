@@ -23,12 +23,12 @@ import (
 	commontypes "configcenter/src/common/types"
 	"configcenter/src/common/util"
 	sencecommon "configcenter/src/scene_server/common"
+	"configcenter/src/scene_server/event_server/event_service/distribution"
 	"configcenter/src/scene_server/event_server/types"
 	"configcenter/src/source_controller/common/instdata"
 	"encoding/json"
 	"fmt"
 	"github.com/tidwall/gjson"
-	redis "gopkg.in/redis.v5"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -76,6 +76,11 @@ func (cli *subscriptionAction) Subscribe(req *restful.Request, resp *restful.Res
 		}
 		sub.LastTime = &now
 		sub.SubscriptionForm = strings.Replace(sub.SubscriptionForm, " ", "", 0)
+		if sub.RetryPolicy != nil {
+			sub.RetryPolicy.Normalize()
+		}
+		sub.PreviousSecret = ""
+		sub.PreviousSecretExpire = nil
 
 		count, err := instdata.GetSubscriptionCntByCondition(map[string]interface{}{"subscription_name": sub.SubscriptionName})
 		if err != nil || count > 0 {
@@ -90,20 +95,15 @@ func (cli *subscriptionAction) Subscribe(req *restful.Request, resp *restful.Res
 		// save to subscribeform in cache
 		events := strings.Split(sub.SubscriptionForm, ",")
 		for _, event := range events {
-			cacheValue := common.KvMap{
-				"key":    types.EventCacheSubscribeformKey + event,
-				"values": []string{fmt.Sprint(sub.SubscriptionID)},
-			}
-			if _, err := cli.CC.CacheCli.Insert("sadd", cacheValue); err != nil {
+			if err := cli.CC.Cache.SAdd(types.EventCacheSubscribeformKey+event, fmt.Sprint(sub.SubscriptionID)); err != nil {
 				blog.Error("create subscription failed, error:%s", err.Error())
 				return http.StatusInternalServerError, nil, defErr.Error(common.CCErrEventSubscribeInsertFailed)
 			}
 		}
 
 		mesg, _ := json.Marshal(&sub)
-		redisCli := cli.CC.CacheCli.GetSession().(*redis.Client)
-		redisCli.Publish(types.EventCacheProcessChannel, "create"+string(mesg))
-		redisCli.Del(types.EventCacheDistCallBackCountPrefix + fmt.Sprint(sub.SubscriptionID))
+		cli.CC.Cache.Publish(types.EventCacheProcessChannel, "create"+string(mesg))
+		cli.CC.Cache.Del(types.EventCacheDistCallBackCountPrefix + fmt.Sprint(sub.SubscriptionID))
 
 		info := make(map[string]int64)
 		info[common.BKSubscriptionIDField] = sub.SubscriptionID
@@ -141,22 +141,23 @@ func (cli *subscriptionAction) UnSubscribe(req *restful.Request, resp *restful.R
 		}
 
 		subID := fmt.Sprint(id)
-		redisCli := cli.CC.CacheCli.GetSession().(*redis.Client)
 		eventTypes := strings.Split(sub.SubscriptionForm, ",")
 		for _, eventType := range eventTypes {
 			eventType = strings.TrimSpace(eventType)
-			if err := redisCli.SRem(types.EventCacheSubscribeformKey+eventType, subID).Err(); err != nil {
+			if err := cli.CC.Cache.SRem(types.EventCacheSubscribeformKey+eventType, subID); err != nil {
 				blog.Error("delete subscription failed, error:%s", err.Error())
 				return http.StatusBadRequest, nil, defErr.Error(common.CCErrEventSubscribeDeleteFailed)
 			}
 		}
 
-		redisCli.Del(types.EventCacheDistIDPrefix+subID,
+		cli.CC.Cache.Del(types.EventCacheDistIDPrefix+subID,
 			types.EventCacheDistQueuePrefix+subID,
-			types.EventCacheDistDonePrefix+subID)
+			types.EventCacheDistDonePrefix+subID,
+			types.EventCacheDistDeadLetterPrefix+subID)
+		distribution.DeleteDeliveryMetrics(id)
 
 		mesg, _ := json.Marshal(&sub)
-		redisCli.Publish(types.EventCacheProcessChannel, "delete"+string(mesg))
+		cli.CC.Cache.Publish(types.EventCacheProcessChannel, "delete"+string(mesg))
 
 		return http.StatusOK, nil, nil
 	}, resp)
@@ -215,6 +216,13 @@ func (cli *subscriptionAction) Rebook(req *restful.Request, resp *restful.Respon
 		sub.LastTime = &now
 		sub.SubscriptionForm = strings.Replace(sub.SubscriptionForm, " ", "", 0)
 		sub.Operator = sencecommon.GetUserFromHeader(req)
+		if sub.RetryPolicy == nil {
+			sub.RetryPolicy = oldsub.RetryPolicy
+		}
+		if sub.RetryPolicy != nil {
+			sub.RetryPolicy.Normalize()
+		}
+		sub.RotateSecret(oldsub, now.Time)
 		if updateerr := instdata.UpdateSubscriptionByCondition(sub, util.NewMapBuilder(common.BKSubscriptionIDField, id).Build()); nil != updateerr {
 			blog.Error("fail update subscription by condition, error information is %s", updateerr.Error())
 			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrEventSubscribeUpdateFailed)
@@ -227,29 +235,20 @@ func (cli *subscriptionAction) Rebook(req *restful.Request, resp *restful.Respon
 
 		for _, eventType := range subs {
 			eventType = strings.TrimSpace(eventType)
-			cacheValue := common.KvMap{
-				"key":    types.EventCacheSubscribeformKey + eventType,
-				"values": []interface{}{fmt.Sprint(id)},
-			}
-			if err := cli.CC.CacheCli.DelByCondition("srem", cacheValue); err != nil {
+			if err := cli.CC.Cache.SRem(types.EventCacheSubscribeformKey+eventType, fmt.Sprint(id)); err != nil {
 				blog.Error("delete subscription failed, error:%s", err.Error())
 				return http.StatusInternalServerError, nil, defErr.Error(common.CCErrEventSubscribeUpdateFailed)
 			}
 		}
 		for _, event := range plugs {
-			cacheValue := common.KvMap{
-				"key":    types.EventCacheSubscribeformKey + event,
-				"values": []string{fmt.Sprint(sub.SubscriptionID)},
-			}
-			if _, err := cli.CC.CacheCli.Insert("sadd", cacheValue); err != nil {
+			if err := cli.CC.Cache.SAdd(types.EventCacheSubscribeformKey+event, fmt.Sprint(sub.SubscriptionID)); err != nil {
 				blog.Error("create subscription failed, error:%s", err.Error())
 				return http.StatusInternalServerError, nil, defErr.Error(common.CCErrEventSubscribeUpdateFailed)
 			}
 		}
 
 		mesg, _ := json.Marshal(&sub)
-		redisCli := cli.CC.CacheCli.GetSession().(*redis.Client)
-		redisCli.Publish(types.EventCacheProcessChannel, "update"+string(mesg))
+		cli.CC.Cache.Publish(types.EventCacheProcessChannel, "update"+string(mesg))
 
 		return http.StatusOK, nil, nil
 	}, resp)
@@ -300,15 +299,15 @@ func (cli *subscriptionAction) Query(req *restful.Request, resp *restful.Respons
 			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrEventSubscribeSelectFailed)
 		}
 
-		redisCli := api.GetAPIResource().CacheCli.GetSession().(*redis.Client)
 		for _, sub := range results {
-			val := redisCli.HGetAll(types.EventCacheDistCallBackCountPrefix + fmt.Sprint(sub.SubscriptionID)).Val()
+			val, _ := api.GetAPIResource().Cache.HGetAll(types.EventCacheDistCallBackCountPrefix + fmt.Sprint(sub.SubscriptionID))
 			failue, _ := strconv.ParseInt(val["failue"], 10, 64)
 			total, _ := strconv.ParseInt(val["total"], 10, 64)
 			sub.Statistics = &types.Statistics{
 				Total:   total,
 				Failure: failue,
 			}
+			sub.HideSecrets()
 		}
 
 		info := make(map[string]interface{})
@@ -16,11 +16,13 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/api"
 	"configcenter/src/common/core/cc/config"
+	"configcenter/src/common/discovery"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/http/httpserver"
+	"configcenter/src/common/rdapi"
+	"configcenter/src/common/types"
 	confCenter "configcenter/src/scene_server/event_server/event_service/config"
 	"configcenter/src/scene_server/event_server/event_service/distribution"
-	"configcenter/src/scene_server/event_server/event_service/rdiscover"
 	"configcenter/src/source_controller/common/instdata"
 	"configcenter/src/storage"
 	"sync"
@@ -31,7 +33,7 @@ import (
 type CCAPIServer struct {
 	conf     *config.CCAPIConfig
 	HttpServ *httpserver.HttpServer
-	rd       *rdiscover.RegDiscover
+	rd       *discovery.RegDiscover
 	cfCenter *confCenter.ConfCenter
 }
 
@@ -51,10 +53,10 @@ func NewCCAPIServer(conf *config.CCAPIConfig) (*CCAPIServer, error) {
 	a.InitAction()
 
 	//RDiscover
-	s.rd = rdiscover.NewRegDiscover(s.conf.RegDiscover, addr, port, false)
+	s.rd = discovery.NewRegDiscover(s.conf.GetRegDiscover(), types.CC_MODULE_EVENTSERVER, addr, port, false, discovery.Options{}, types.CC_MODULE_HOSTCONTROLLER)
 
 	//ConfCenter
-	s.cfCenter = confCenter.NewConfCenter(s.conf.RegDiscover)
+	s.cfCenter = confCenter.NewConfCenter(s.conf.GetRegDiscover())
 
 	return s, nil
 }
@@ -120,6 +122,15 @@ func (ccAPI *CCAPIServer) Start() error {
 		}
 	}()
 
+	// the dynamic host groups are marked due through the host controller
+	a.AddrSrv = ccAPI.rd
+	a.HostCtrl = rdapi.GetRdAddrSrvHandle(types.CC_MODULE_HOSTCONTROLLER, a.AddrSrv)
+
+	if err := distribution.InitEventHistory(config); err != nil {
+		blog.Errorf("init event history failed! err:%s", err.Error())
+		return err
+	}
+
 	go func() {
 		err := distribution.Start()
 		blog.Error("Distribute process stop by error: %v", err)
@@ -142,6 +153,7 @@ func (ccAPI *CCAPIServer) InitHttpServ(config map[string]string) error {
 	chErr := make(chan error, 3)
 	a := api.NewAPIResource()
 	ccAPI.HttpServ.RegisterWebServer("/event/{version}", nil, a.Actions)
+	a.RegisterHealth(ccAPI.HttpServ)
 
 	wg := sync.WaitGroup{}
 	//config, _ := a.ParseConfig()
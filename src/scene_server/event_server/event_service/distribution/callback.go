@@ -14,11 +14,11 @@ package distribution
 
 import (
 	"bytes"
+	"configcenter/src/common/confregistry"
 	"configcenter/src/common/core/cc/api"
 	"configcenter/src/common/http/httpclient"
 	"configcenter/src/scene_server/event_server/types"
 	"fmt"
-	redis "gopkg.in/redis.v5"
 	"io/ioutil"
 	"net/http"
 	"regexp"
@@ -26,32 +26,36 @@ import (
 	"time"
 )
 
-func SendCallback(receiver *types.Subscription, event string) (err error) {
-	redisCli := api.GetAPIResource().CacheCli.GetSession().(*redis.Client)
-	redisCli.HIncrBy(types.EventCacheDistCallBackCountPrefix+fmt.Sprint(receiver.SubscriptionID), "total", 1)
+// SendCallback post the event to the subscriber, deliveryID identifies the dist event and keeps the same in retries
+func SendCallback(receiver *types.Subscription, deliveryID string, event string) (err error) {
+	cache := api.GetAPIResource().Cache
+	cache.HIncrBy(types.EventCacheDistCallBackCountPrefix+fmt.Sprint(receiver.SubscriptionID), "total", 1)
+	defer func() { recordDelivery(receiver.SubscriptionID, err) }()
 
 	body := bytes.NewBufferString(event)
 	req, err := http.NewRequest("POST", receiver.CallbackURL, body)
 	if err != nil {
-		redisCli.HIncrBy(types.EventCacheDistCallBackCountPrefix+fmt.Sprint(receiver.SubscriptionID), "failue", 1)
+		cache.HIncrBy(types.EventCacheDistCallBackCountPrefix+fmt.Sprint(receiver.SubscriptionID), "failue", 1)
 		return fmt.Errorf("event distribute fail, build request error: %v, date=[%s]", err, event)
 	}
-	var duration time.Duration
-	if receiver.TimeOut == 0 {
-		duration = timeout
-	} else {
+	signCallback(req, receiver, deliveryID, event, time.Now())
+	duration := callbackTimeout.Get()
+	if receiver.TimeOut != 0 {
 		duration = receiver.GetTimeout()
 	}
+	if max := callbackMaxTimeout.Get(); max > 0 && duration > max {
+		duration = max
+	}
 	resp, err := httpCli.DoWithTimeout(duration, req)
 	if err != nil {
-		redisCli.HIncrBy(types.EventCacheDistCallBackCountPrefix+fmt.Sprint(receiver.SubscriptionID), "failue", 1)
+		cache.HIncrBy(types.EventCacheDistCallBackCountPrefix+fmt.Sprint(receiver.SubscriptionID), "failue", 1)
 		return fmt.Errorf("event distribute fail, send request error: %v, date=[%s]", err, event)
 	}
 	defer resp.Body.Close()
 	respdata, _ := ioutil.ReadAll(resp.Body)
 	if receiver.ConfirmMode == types.ConfirmmodeHttpstatus {
 		if strconv.Itoa(resp.StatusCode) != receiver.ConfirmPattern {
-			redisCli.HIncrBy(types.EventCacheDistCallBackCountPrefix+fmt.Sprint(receiver.SubscriptionID), "failue", 1)
+			cache.HIncrBy(types.EventCacheDistCallBackCountPrefix+fmt.Sprint(receiver.SubscriptionID), "failue", 1)
 			return fmt.Errorf("event distribute fail, received response %s, date=[%s]", respdata, event)
 		}
 	} else if receiver.ConfirmMode == types.ConfirmmodeRegular {
@@ -60,7 +64,7 @@ func SendCallback(receiver *types.Subscription, event string) (err error) {
 			return fmt.Errorf("event distribute fail, build regexp error: %v", err)
 		}
 		if !pattern.Match(respdata) {
-			redisCli.HIncrBy(types.EventCacheDistCallBackCountPrefix+fmt.Sprint(receiver.SubscriptionID), "failue", 1)
+			cache.HIncrBy(types.EventCacheDistCallBackCountPrefix+fmt.Sprint(receiver.SubscriptionID), "failue", 1)
 			return fmt.Errorf("event distribute fail, received response %s, date=[%s]", respdata, event)
 		}
 		return nil
@@ -70,3 +74,10 @@ func SendCallback(receiver *types.Subscription, event string) (err error) {
 }
 
 var httpCli = httpclient.NewHttpClient()
+
+var (
+	// callbackTimeout the timeout of the callbacks whose subscription has no timeout, reloaded from event.callback_timeout
+	callbackTimeout = confregistry.Default.Duration("event.callback_timeout", timeout, nil)
+	// callbackMaxTimeout limit the timeout of the subscriptions, no limit if zero, reloaded from event.callback_max_timeout
+	callbackMaxTimeout = confregistry.Default.Duration("event.callback_max_timeout", 0, nil)
+)
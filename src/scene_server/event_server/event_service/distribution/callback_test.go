@@ -14,11 +14,14 @@ package distribution
 
 import (
 	"configcenter/src/common/core/cc/api"
+	commontypes "configcenter/src/common/types"
 	"configcenter/src/scene_server/event_server/types"
 	"configcenter/src/storage"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func initTester() {
@@ -51,8 +54,105 @@ func TestSendCallback(t *testing.T) {
 		ConfirmPattern: "200",
 		TimeOut:        10,
 	}
-	if err := SendCallback(receiver, "test message"); err != nil {
+	if err := SendCallback(receiver, "1-1", "test message"); err != nil {
 		t.Fail()
 	}
 
 }
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := types.RetryPolicy{MaxAttempts: 5, Backoff: 1000, MaxBackoff: 3000}
+	policy.Normalize()
+
+	expects := []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}
+	for i, expect := range expects {
+		if backoff := policy.GetBackoff(i+1, 0.5); backoff != expect {
+			t.Fatalf("attempt %d expected backoff %v but got %v", i+1, expect, backoff)
+		}
+	}
+
+	policy.JitterFactor = 0.5
+	if backoff := policy.GetBackoff(2, 0); backoff != 500*time.Millisecond {
+		t.Fatalf("expected backoff 500ms but got %v", backoff)
+	}
+	if backoff := policy.GetBackoff(2, 0.999); backoff >= 1500*time.Millisecond {
+		t.Fatalf("expected backoff less than 1.5s but got %v", backoff)
+	}
+}
+
+func TestRetryPolicyNormalize(t *testing.T) {
+	policy := types.RetryPolicy{MaxAttempts: 100, Backoff: 24 * 3600 * 1000, MaxBackoff: 48 * 3600 * 1000, JitterFactor: 2}
+	policy.Normalize()
+	expect := types.RetryPolicy{MaxAttempts: types.MaxRetryAttempts, Backoff: types.MaxRetryBackoff, MaxBackoff: types.MaxRetryBackoff, JitterFactor: 1}
+	if policy != expect {
+		t.Fatalf("expected policy %v but got %v", expect, policy)
+	}
+
+	policy = types.RetryPolicy{MaxAttempts: 5, Backoff: 100000, MaxBackoff: 48 * 3600 * 1000}
+	policy.Normalize()
+	if policy.Backoff != 100000 || policy.MaxBackoff != types.MaxRetryBackoff {
+		t.Fatalf("expected max backoff %d but got %v", types.MaxRetryBackoff, policy)
+	}
+	if backoff := policy.GetBackoff(policy.MaxAttempts, 0.5); backoff != types.MaxRetryBackoff*time.Millisecond {
+		t.Fatalf("expected backoff %v but got %v", types.MaxRetryBackoff*time.Millisecond, backoff)
+	}
+}
+
+func TestSubscriptionRetryPolicy(t *testing.T) {
+	sub := types.Subscription{}
+	if policy := sub.GetRetryPolicy(); policy != types.DefaultRetryPolicy {
+		t.Fatalf("expected default retry policy but got %v", policy)
+	}
+
+	sub.RetryPolicy = &types.RetryPolicy{MaxAttempts: 100}
+	if policy := sub.GetRetryPolicy(); policy.MaxAttempts != types.MaxRetryAttempts {
+		t.Fatalf("expected max attempts %d but got %d", types.MaxRetryAttempts, policy.MaxAttempts)
+	}
+}
+
+func TestSignCallback(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	expire := commontypes.Time{Time: now.Add(time.Hour)}
+	receiver := &types.Subscription{
+		Secret:               "new",
+		PreviousSecret:       "old",
+		PreviousSecretExpire: &expire,
+	}
+	req := httptest.NewRequest("POST", "http://127.0.0.1/callback", nil)
+	signCallback(req, receiver, "1-2", "body", now)
+
+	if req.Header.Get(types.EventCallbackHeaderTimestamp) != "1500000000" {
+		t.Fatalf("unexpected timestamp %s", req.Header.Get(types.EventCallbackHeaderTimestamp))
+	}
+	if req.Header.Get(types.EventCallbackHeaderDelivery) != "1-2" {
+		t.Fatalf("unexpected delivery id %s", req.Header.Get(types.EventCallbackHeaderDelivery))
+	}
+	expect := "sha256=" + computeSignature("new", "1500000000", "body") + ",sha256=" + computeSignature("old", "1500000000", "body")
+	if req.Header.Get(types.EventCallbackHeaderSignature) != expect {
+		t.Fatalf("expected signature %s but got %s", expect, req.Header.Get(types.EventCallbackHeaderSignature))
+	}
+
+	// the old secret is not used after the grace period
+	req = httptest.NewRequest("POST", "http://127.0.0.1/callback", nil)
+	signCallback(req, receiver, "1-2", "body", now.Add(2*time.Hour))
+	if strings.Contains(req.Header.Get(types.EventCallbackHeaderSignature), ",") {
+		t.Fatalf("expected only one signature but got %s", req.Header.Get(types.EventCallbackHeaderSignature))
+	}
+}
+
+func TestRotateSecret(t *testing.T) {
+	now := time.Now()
+	old := types.Subscription{Secret: "old"}
+
+	sub := types.Subscription{}
+	sub.RotateSecret(old, now)
+	if sub.Secret != "old" || sub.PreviousSecret != "" {
+		t.Fatalf("expected the secret kept but got %s, %s", sub.Secret, sub.PreviousSecret)
+	}
+
+	sub = types.Subscription{Secret: "new", SecretGracePeriod: 60}
+	sub.RotateSecret(old, now)
+	if sub.PreviousSecret != "old" || !sub.PreviousSecretExpire.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expected the old secret valid for one minute but got %s, %v", sub.PreviousSecret, sub.PreviousSecretExpire)
+	}
+}
@@ -0,0 +1,176 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"configcenter/src/common/blog"
+	"configcenter/src/common/core/cc/api"
+	commontypes "configcenter/src/common/types"
+	"configcenter/src/scene_server/event_server/types"
+	"configcenter/src/storage"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// sendCallbackWithRetry send the dist event to the subscriber according to its retry policy,
+// the dist event will be parked into the dead letters if all the attempts failed
+func sendCallbackWithRetry(sub *types.Subscription, dist *types.DistInstCtx) (err error) {
+	policy := sub.GetRetryPolicy()
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		if backoff := policy.GetBackoff(attempt, rand.Float64()); backoff > 0 {
+			blog.Infof("retry dist %d of subscription %d after %v, attempt %d", dist.DstbID, sub.SubscriptionID, backoff, attempt)
+			time.Sleep(backoff)
+		}
+		if err = SendCallback(sub, getDeliveryID(dist.SubscriptionID, dist.DstbID), dist.Raw); err == nil {
+			return nil
+		}
+		blog.Errorf("send callback error: %v, attempt %d", err, attempt)
+	}
+
+	if saveErr := SaveDeadLetter(dist, policy.MaxAttempts, err); saveErr != nil {
+		blog.Errorf("save dead letter of dist %d error: %v", dist.DstbID, saveErr)
+	}
+	return err
+}
+
+// SaveDeadLetter park the failed dist event
+func SaveDeadLetter(dist *types.DistInstCtx, attempts int, reason error) error {
+	letter := types.DeadLetter{
+		DstbID:         dist.DstbID,
+		SubscriptionID: dist.SubscriptionID,
+		Attempts:       attempts,
+		FailedTime:     commontypes.Now(),
+		Event:          dist.Raw,
+	}
+	if reason != nil {
+		letter.LastError = reason.Error()
+	}
+	value, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+	return api.GetAPIResource().Cache.HSet(types.EventCacheDistDeadLetterPrefix+fmt.Sprint(dist.SubscriptionID), fmt.Sprint(dist.DstbID), string(value))
+}
+
+// ListDeadLetters return the dead letters of the subscription ordered by the dist id, and the total count
+func ListDeadLetters(subscriptionID int64, start, limit int) ([]types.DeadLetter, int, error) {
+	values, err := api.GetAPIResource().Cache.HGetAll(types.EventCacheDistDeadLetterPrefix + fmt.Sprint(subscriptionID))
+	if err != nil {
+		return nil, 0, err
+	}
+	letters := make([]types.DeadLetter, 0, len(values))
+	for _, value := range values {
+		letter := types.DeadLetter{}
+		if err := json.Unmarshal([]byte(value), &letter); err != nil {
+			blog.Errorf("unmarshal dead letter error: %v, data=[%s]", err, value)
+			continue
+		}
+		letters = append(letters, letter)
+	}
+	sort.Slice(letters, func(i, j int) bool { return letters[i].DstbID < letters[j].DstbID })
+
+	count := len(letters)
+	if start > count {
+		start = count
+	}
+	letters = letters[start:]
+	if limit > 0 && limit < len(letters) {
+		letters = letters[:limit]
+	}
+	return letters, count, nil
+}
+
+// GetDeadLetter return the dead letter of the dist event, nil if not found
+func GetDeadLetter(subscriptionID, dstbID int64) (*types.DeadLetter, error) {
+	value, err := api.GetAPIResource().Cache.HGet(types.EventCacheDistDeadLetterPrefix+fmt.Sprint(subscriptionID), fmt.Sprint(dstbID))
+	if err == storage.ErrCacheNil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	letter := types.DeadLetter{}
+	if err := json.Unmarshal([]byte(value), &letter); err != nil {
+		return nil, err
+	}
+	return &letter, nil
+}
+
+// ReplayDeadLetters send the dead letters to the subscriber again, once for each,
+// the delivered ones are removed, the result is the error message of each dist id, empty means delivered.
+// all the dead letters of the subscription are replayed if dstbIDs is empty
+func ReplayDeadLetters(sub *types.Subscription, dstbIDs []int64) (map[int64]string, error) {
+	letters, err := getDeadLetters(sub.SubscriptionID, dstbIDs)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[int64]string, len(letters))
+	for _, letter := range letters {
+		if err := SendCallback(sub, getDeliveryID(letter.SubscriptionID, letter.DstbID), letter.Event); err != nil {
+			blog.Errorf("replay dead letter %d of subscription %d error: %v", letter.DstbID, sub.SubscriptionID, err)
+			letter.Attempts++
+			letter.LastError = err.Error()
+			letter.FailedTime = commontypes.Now()
+			if value, jsErr := json.Marshal(letter); jsErr == nil {
+				api.GetAPIResource().Cache.HSet(types.EventCacheDistDeadLetterPrefix+fmt.Sprint(sub.SubscriptionID), fmt.Sprint(letter.DstbID), string(value))
+			}
+			result[letter.DstbID] = err.Error()
+			continue
+		}
+		if err := DeleteDeadLetters(sub.SubscriptionID, []int64{letter.DstbID}); err != nil {
+			return result, err
+		}
+		result[letter.DstbID] = ""
+	}
+	return result, nil
+}
+
+// DeleteDeadLetters purge the dead letters, all the dead letters of the subscription are purged if dstbIDs is empty
+func DeleteDeadLetters(subscriptionID int64, dstbIDs []int64) error {
+	key := types.EventCacheDistDeadLetterPrefix + fmt.Sprint(subscriptionID)
+	if len(dstbIDs) == 0 {
+		return api.GetAPIResource().Cache.Del(key)
+	}
+	fields := make([]string, 0, len(dstbIDs))
+	for _, id := range dstbIDs {
+		fields = append(fields, fmt.Sprint(id))
+	}
+	return api.GetAPIResource().Cache.HDel(key, fields...)
+}
+
+func getDeadLetters(subscriptionID int64, dstbIDs []int64) ([]types.DeadLetter, error) {
+	if len(dstbIDs) == 0 {
+		letters, _, err := ListDeadLetters(subscriptionID, 0, 0)
+		return letters, err
+	}
+	sort.Slice(dstbIDs, func(i, j int) bool { return dstbIDs[i] < dstbIDs[j] })
+	letters := make([]types.DeadLetter, 0, len(dstbIDs))
+	for _, id := range dstbIDs {
+		letter, err := GetDeadLetter(subscriptionID, id)
+		if err != nil {
+			return nil, err
+		}
+		if letter != nil {
+			letters = append(letters, *letter)
+		}
+	}
+	return letters, nil
+}
+
+// getDeliveryID return the id of the dist event which the receiver can use to drop the duplicated ones
+func getDeliveryID(subscriptionID, dstbID int64) string {
+	return fmt.Sprintf("%d-%d", subscriptionID, dstbID)
+}
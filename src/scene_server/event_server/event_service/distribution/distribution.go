@@ -13,17 +13,19 @@
 package distribution
 
 import (
-	"configcenter/src/common"
 	"configcenter/src/common/core/cc/api"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/lifecycle"
 	"configcenter/src/scene_server/event_server/types"
 	"encoding/json"
 	"fmt"
-	redis "gopkg.in/redis.v5"
 	"runtime/debug"
 	"time"
 )
 
+// distWorker track the dists being handled, the distribution goroutines stop after the current dist is done on shutdown
+var distWorker *lifecycle.Worker
+
 func StartDistribute() (err error) {
 	defer func() {
 		if err == nil {
@@ -34,6 +36,8 @@ func StartDistribute() (err error) {
 		}
 		debug.PrintStack()
 	}()
+	distWorker = lifecycle.NewWorker("event distributer")
+
 	// reconcil cache from persistent store
 	rccler := newReconciler()
 	rccler.loadAll()
@@ -122,6 +126,7 @@ func distToSubscribe(param types.Subscription, chNew chan types.Subscription, do
 		}
 	}()
 	sub := param
+	defer watchDistQueue(param.SubscriptionID)()
 	go func() {
 		for {
 			sub = <-chNew
@@ -135,13 +140,20 @@ func distToSubscribe(param types.Subscription, chNew chan types.Subscription, do
 		case <-done:
 			return
 		default:
-			dist := popDistInst(sub.SubscriptionID)
-			if dist == nil {
-				continue
-			}
-			if err = handleDist(&sub, dist); err != nil {
-				blog.Errorf("error handle dist: %v, %v", err, dist)
+			if !distWorker.Begin() {
+				blog.Infof("stop handle dist %v by shutdown", sub.SubscriptionID)
+				return nil
 			}
+			func() {
+				defer distWorker.End()
+				dist := popDistInst(sub.SubscriptionID)
+				if dist == nil {
+					return
+				}
+				if err = handleDist(&sub, dist); err != nil {
+					blog.Errorf("error handle dist: %v, %v", err, dist)
+				}
+			}()
 		}
 	}
 }
@@ -151,7 +163,10 @@ func handleDist(sub *types.Subscription, dist *types.DistInstCtx) (err error) {
 	distID := fmt.Sprint(dist.DstbID - 1)
 	subscriberID := fmt.Sprint(dist.SubscriptionID)
 	runningkey := types.EventCacheDistRunningPrefix + subscriberID + "_" + distID
-	if err = saveRunning(runningkey, timeout+sub.GetTimeout()); err != nil {
+	// keep running during all the attempts, the longest delay is the last one with the full jitter
+	policy := sub.GetRetryPolicy()
+	runningTimeout := timeout + time.Duration(policy.MaxAttempts)*(sub.GetTimeout()+policy.GetBackoff(policy.MaxAttempts, 1))
+	if err = saveRunning(runningkey, runningTimeout); err != nil {
 		if ERR_PROCESS_EXISTS == err {
 			blog.Infof("process exist, continue")
 			return nil
@@ -199,7 +214,7 @@ func handleDist(sub *types.Subscription, dist *types.DistInstCtx) (err error) {
 		blog.Info("done event dist : %v", dist.DstbID)
 	}()
 	// if previous done then begin send callback
-	if err = SendCallback(sub, dist.Raw); err != nil {
+	if err = sendCallbackWithRetry(sub, dist); err != nil {
 		blog.Errorf("send callback error: %v", err)
 		return
 	}
@@ -208,14 +223,8 @@ func handleDist(sub *types.Subscription, dist *types.DistInstCtx) (err error) {
 }
 
 func popDistInst(subID int64) *types.DistInstCtx {
-	eventseletor := common.KvMap{
-		"expire": time.Second * 60,
-		"key":    []string{types.EventCacheDistQueuePrefix + fmt.Sprint(subID)},
-	}
-	eventslice := []string{}
-	api.GetAPIResource().CacheCli.GetOneByCondition("blpop", nil, eventseletor, &eventslice)
-
-	if len(eventslice) <= 0 {
+	eventslice, err := api.GetAPIResource().Cache.BLPop(popTimeout, types.EventCacheDistQueuePrefix+fmt.Sprint(subID))
+	if err != nil || len(eventslice) <= 0 {
 		return nil
 	}
 
@@ -231,11 +240,11 @@ func popDistInst(subID int64) *types.DistInstCtx {
 }
 
 func saveDistDone(dist *types.DistInstCtx) (err error) {
-	redisCli := api.GetAPIResource().CacheCli.GetSession().(*redis.Client)
-	if err = redisCli.HSet(types.EventCacheDistDonePrefix+fmt.Sprint(dist.SubscriptionID), fmt.Sprint(dist.DstbID), dist.Raw).Err(); err != nil {
+	cache := api.GetAPIResource().Cache
+	if err = cache.HSet(types.EventCacheDistDonePrefix+fmt.Sprint(dist.SubscriptionID), fmt.Sprint(dist.DstbID), dist.Raw); err != nil {
 		return
 	}
-	if err = redisCli.Del(types.EventCacheDistRunningPrefix + fmt.Sprintf("%d_%d", dist.SubscriptionID, dist.DstbID)).Err(); err != nil {
+	if err = cache.Del(types.EventCacheDistRunningPrefix + fmt.Sprintf("%d_%d", dist.SubscriptionID, dist.DstbID)); err != nil {
 		return
 	}
 	return
@@ -16,10 +16,10 @@ import (
 	"configcenter/src/common"
 	"configcenter/src/common/core/cc/api"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/lifecycle"
 	"configcenter/src/scene_server/event_server/types"
 	"encoding/json"
 	"fmt"
-	redis "gopkg.in/redis.v5"
 	"runtime/debug"
 	"strconv"
 	"time"
@@ -28,6 +28,8 @@ import (
 var (
 	timeout    = time.Second * 10
 	waitperiod = time.Second
+	// popTimeout the blocking pop returns in time, so that the workers can stop on shutdown
+	popTimeout = time.Second * 5
 )
 
 var (
@@ -51,15 +53,24 @@ func StartHandleInsts() (err error) {
 	}()
 
 	blog.Info("event inst handle process started")
+	worker := lifecycle.NewWorker("event inst handler")
 	for {
-		// pod one event from cache
-		event := popEventInst()
-		if event == nil {
-			continue
-		}
-		if err := handleInst(event); err != nil {
-			blog.Errorf("error handle dist: %v, %v", err, event)
+		// stop after the last event is marked done
+		if !worker.Begin() {
+			blog.Info("event inst handle process stopped by shutdown")
+			worker.Park()
 		}
+		func() {
+			defer worker.End()
+			// pod one event from cache
+			event := popEventInst()
+			if event == nil {
+				return
+			}
+			if err := handleInst(event); err != nil {
+				blog.Errorf("error handle dist: %v, %v", err, event)
+			}
+		}()
 	}
 }
 
@@ -77,6 +88,18 @@ func handleInst(event *types.EventInstCtx) (err error) {
 
 	// selete members
 	origindist := event.GetDistInst()
+	if origindist == nil {
+		blog.Errorf("event %v has no valid data, skip", event.ID)
+		return SaveEventDone(event)
+	}
+
+	// keep the event for replay
+	if err = saveEventHistory(event, origindist.GetType()); err != nil {
+		blog.Errorf("save event %v history error: %v", event.ID, err)
+	}
+	// the dynamic host groups are evaluated again after the hosts change
+	hostGroupsDue.collect(origindist)
+
 	subscribers := findEventTypeSubscribers(origindist.GetType())
 	if len(subscribers) <= 0 || "nil" == subscribers[0] {
 		blog.Infof("%v no subscriber，continue", origindist.GetType())
@@ -122,26 +145,31 @@ func handleInst(event *types.EventInstCtx) (err error) {
 	}()
 	// prepare dist event
 	for _, subscriber := range subscribers {
-		var dstbID, subscribeID int64
-
-		distinst := *origindist
-		dstbID, err = nextDistID(subscriber)
-		if err != nil {
+		if err = pushDistInst(subscriber, *origindist); err != nil {
 			return err
 		}
-		subscribeID, err = strconv.ParseInt(subscriber, 10, 64)
-		if err != nil {
-			return err
-		}
-		distinst.DstbID = dstbID
-		distinst.SubscriptionID = subscribeID
-		distByte, _ := json.Marshal(distinst)
-		pushToQueue(types.EventCacheDistQueuePrefix+subscriber, string(distByte))
 	}
 
 	return
 }
 
+// pushDistInst push the event into the dist queue of the subscriber
+func pushDistInst(subscriber string, distinst types.DistInst) (err error) {
+	var dstbID, subscribeID int64
+	dstbID, err = nextDistID(subscriber)
+	if err != nil {
+		return err
+	}
+	subscribeID, err = strconv.ParseInt(subscriber, 10, 64)
+	if err != nil {
+		return err
+	}
+	distinst.DstbID = dstbID
+	distinst.SubscriptionID = subscribeID
+	distByte, _ := json.Marshal(distinst)
+	return pushToQueue(types.EventCacheDistQueuePrefix+subscriber, string(distByte))
+}
+
 func prepareDistInst(subscriber string, event types.EventInstCtx) *types.DistInst {
 	dstbID, err := nextDistID(subscriber)
 	if err != nil {
@@ -178,33 +206,21 @@ func prepareDistInst(subscriber string, event types.EventInstCtx) *types.DistIns
 }
 
 func pushToQueue(key, value string) (err error) {
-	cacheValue := common.KvMap{
-		"key":    key,
-		"values": []string{string(value)},
-	}
-	_, err = api.GetAPIResource().CacheCli.Insert("rpush", cacheValue)
+	err = api.GetAPIResource().Cache.RPush(key, value)
 	blog.Infof("pushed to queue:%v", key)
 	return
 }
 
 func nextDistID(eventtype string) (nextid int64, err error) {
-	eventIDseletor := common.KvMap{
-		"key": types.EventCacheDistIDPrefix + eventtype,
-	}
-	var id int
-	id, err = api.GetAPIResource().CacheCli.Insert("incr", eventIDseletor)
-	if err != nil {
-		return
-	}
-	return int64(id), nil
+	return api.GetAPIResource().Cache.Incr(types.EventCacheDistIDPrefix + eventtype)
 }
 
 func SaveEventDone(event *types.EventInstCtx) (err error) {
-	redisCli := api.GetAPIResource().CacheCli.GetSession().(*redis.Client)
-	if err = redisCli.HSet(types.EventCacheEventDoneKey, fmt.Sprint(event.ID), event.Raw).Err(); err != nil {
+	cache := api.GetAPIResource().Cache
+	if err = cache.HSet(types.EventCacheEventDoneKey, fmt.Sprint(event.ID), event.Raw); err != nil {
 		return
 	}
-	if err = redisCli.Del(types.EventCacheEventRunningPrefix + fmt.Sprint(event.ID)).Err(); err != nil {
+	if err = cache.Del(types.EventCacheEventRunningPrefix + fmt.Sprint(event.ID)); err != nil {
 		return
 	}
 	return
@@ -233,19 +249,16 @@ func checkFromDone(key string, id string) (bool, error) {
 	if id == "0" {
 		return true, nil
 	}
-	redisCli := api.GetAPIResource().CacheCli.GetSession().(*redis.Client)
-	return redisCli.HExists(key, fmt.Sprint(id)).Result()
+	return api.GetAPIResource().Cache.HExists(key, fmt.Sprint(id))
 }
 
 func checkFromRunning(key string) (bool, error) {
-	redisCli := api.GetAPIResource().CacheCli.GetSession().(*redis.Client)
-	return redisCli.Exists(key).Result()
+	return api.GetAPIResource().Cache.Exists(key)
 }
 
 func saveRunning(key string, timeout time.Duration) (err error) {
 	// prevent other process handle the same event
-	redisCli := api.GetAPIResource().CacheCli.GetSession().(*redis.Client)
-	set, err := redisCli.SetNX(key, time.Now().UTC().Format(time.RFC3339), timeout).Result()
+	set, err := api.GetAPIResource().Cache.SetNX(key, time.Now().UTC().Format(time.RFC3339), timeout)
 	if !set {
 		return ERR_PROCESS_EXISTS
 	}
@@ -253,19 +266,13 @@ func saveRunning(key string, timeout time.Duration) (err error) {
 }
 
 func findEventTypeSubscribers(eventtype string) []string {
-	redisCli := api.GetAPIResource().CacheCli.GetSession().(*redis.Client)
-	return redisCli.SMembers(types.EventCacheSubscribeformKey + eventtype).Val()
+	subscribers, _ := api.GetAPIResource().Cache.SMembers(types.EventCacheSubscribeformKey + eventtype)
+	return subscribers
 }
 
 func popEventInst() *types.EventInstCtx {
-	eventseletor := common.KvMap{
-		"expire": time.Second * 60,
-		"key":    []string{types.EventCacheEventQueueKey},
-	}
-	eventslice := []string{}
-	api.GetAPIResource().CacheCli.GetOneByCondition("blpop", nil, eventseletor, &eventslice)
-
-	if len(eventslice) <= 0 || eventslice[1] == "nil" {
+	eventslice, err := api.GetAPIResource().Cache.BLPop(popTimeout, types.EventCacheEventQueueKey)
+	if err != nil || len(eventslice) <= 0 || eventslice[1] == "nil" {
 		return nil
 	}
 
@@ -0,0 +1,142 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"configcenter/src/common/blog"
+	"configcenter/src/common/core/cc/api"
+	commontypes "configcenter/src/common/types"
+	"configcenter/src/scene_server/event_server/types"
+	"configcenter/src/storage"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// replayBatchSize the count of the events loaded from the history at one time when replaying
+const replayBatchSize = 500
+
+// ReplayOption define the range of the events to replay, all the fields are optional
+type ReplayOption struct {
+	StartEventID int64             `json:"start_event_id"`
+	StartTime    *commontypes.Time `json:"start_time"`
+	EndTime      *commontypes.Time `json:"end_time"`
+}
+
+// InitEventHistory ensure the indexes of the event history, the events expire after event.history_expire_days
+func InitEventHistory(config map[string]string) error {
+	expireDays := types.DefaultEventHistoryExpireDays
+	if days, err := strconv.Atoi(config["event.history_expire_days"]); err == nil && days > 0 {
+		expireDays = days
+	}
+
+	instCli := api.GetAPIResource().InstCli
+	isExist, err := instCli.HasTable(types.TableNameEventHistory)
+	if err != nil {
+		return err
+	}
+	if !isExist {
+		if err = instCli.CreateTable(types.TableNameEventHistory); err != nil {
+			return err
+		}
+	}
+
+	indexes := []storage.Index{
+		{Name: "event_id", Columns: []string{"event_id"}, Type: storage.INDEX_TYPE_BACKGROUP_UNIQUE},
+		{Name: "event_key", Columns: []string{"event_key", "event_id"}, Type: storage.INDEX_TYPE_BACKGROUP},
+		{Name: "create_time", Columns: []string{"create_time"}, Type: storage.INDEX_TYPE_BACKGROUP, ExpireAfter: time.Duration(expireDays) * 24 * time.Hour},
+	}
+	for _, index := range indexes {
+		idx := index
+		if err := instCli.Index(types.TableNameEventHistory, &idx); err != nil {
+			// the expiration can not be changed by ensuring the index again, it should be modified manually
+			blog.Warnf("ensure event history index %s error: %v", index.Name, err)
+		}
+	}
+	blog.Infof("event history expires after %d days", expireDays)
+	return nil
+}
+
+func saveEventHistory(event *types.EventInstCtx, eventKey string) error {
+	history := types.EventHistory{
+		EventID:    event.ID,
+		EventKey:   eventKey,
+		ActionTime: event.ActionTime.Time,
+		CreateTime: time.Now(),
+		Event:      event.Raw,
+	}
+	_, err := api.GetAPIResource().InstCli.Insert(types.TableNameEventHistory, history)
+	return err
+}
+
+// ReplayEvents push the events in the history which the subscription subscribes to its dist queue again,
+// in the order of the event id, return the count of the replayed events
+func ReplayEvents(sub *types.Subscription, opt ReplayOption) (int, error) {
+	eventKeys := []string{}
+	for _, key := range strings.Split(sub.SubscriptionForm, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			eventKeys = append(eventKeys, key)
+		}
+	}
+	if len(eventKeys) == 0 {
+		return 0, nil
+	}
+
+	actionTime := map[string]interface{}{}
+	if opt.StartTime != nil {
+		actionTime["$gte"] = opt.StartTime.Time
+	}
+	if opt.EndTime != nil {
+		actionTime["$lte"] = opt.EndTime.Time
+	}
+
+	subscriber := fmt.Sprint(sub.SubscriptionID)
+	lastID := opt.StartEventID - 1
+	count := 0
+	for {
+		condition := map[string]interface{}{
+			"event_key": map[string]interface{}{"$in": eventKeys},
+			"event_id":  map[string]interface{}{"$gt": lastID},
+		}
+		if len(actionTime) > 0 {
+			condition["action_time"] = actionTime
+		}
+		histories := []types.EventHistory{}
+		if err := api.GetAPIResource().InstCli.GetMutilByCondition(types.TableNameEventHistory, nil, condition, &histories, "event_id", 0, replayBatchSize); err != nil {
+			return count, err
+		}
+
+		for _, history := range histories {
+			lastID = history.EventID
+			event := types.EventInst{}
+			if err := json.Unmarshal([]byte(history.Event), &event); err != nil {
+				blog.Errorf("replay event %d error, unmarshal error: %v, data=[%s]", history.EventID, err, history.Event)
+				continue
+			}
+			distinst := event.GetDistInst()
+			if distinst == nil {
+				continue
+			}
+			if err := pushDistInst(subscriber, *distinst); err != nil {
+				return count, err
+			}
+			count++
+		}
+
+		if len(histories) < replayBatchSize {
+			return count, nil
+		}
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/core/cc/api"
+	"configcenter/src/common/lifecycle"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/event_server/types"
+	userAPISdk "configcenter/src/source_controller/api/userapi"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// hostGroupDueInterval the interval the collected host groups are marked due, the host events in the interval
+// are merged into one request to the host controller
+var hostGroupDueInterval = 10 * time.Second
+
+// dueHostGroups the businesses and the hosts whose dynamic host groups are to be marked due
+type dueHostGroups struct {
+	sync.Mutex
+	appIDs  map[int]bool
+	hostIDs map[int]bool
+}
+
+var hostGroupsDue = &dueHostGroups{appIDs: map[int]bool{}, hostIDs: map[int]bool{}}
+
+// collect record the scope of the host groups the event may change, the moduletransfer event changes the groups
+// of its business, the host event carries no business so the groups of the business the host belongs to
+func (d *dueHostGroups) collect(event *types.DistInst) {
+	var ids map[int]bool
+	var field string
+	switch {
+	case types.EventTypeInstData == event.EventType && common.BKInnerObjIDHost == event.ObjType:
+		ids, field = d.hostIDs, common.BKHostIDField
+	case types.EventTypeRelation == event.EventType && "moduletransfer" == event.ObjType:
+		ids, field = d.appIDs, common.BKAppIDField
+	default:
+		return
+	}
+	d.Lock()
+	defer d.Unlock()
+	for _, data := range []interface{}{event.CurData, event.PreData} {
+		row, _ := data.(map[string]interface{})
+		if id, err := util.GetIntByInterface(row[field]); nil == err {
+			ids[id] = true
+		}
+	}
+}
+
+// flush mark the collected host groups due by mark, they are collected again if it failed
+func (d *dueHostGroups) flush(mark func(appIDs, hostIDs []int) error) error {
+	d.Lock()
+	appIDs, hostIDs := d.appIDs, d.hostIDs
+	d.appIDs, d.hostIDs = map[int]bool{}, map[int]bool{}
+	d.Unlock()
+	if 0 == len(appIDs) && 0 == len(hostIDs) {
+		return nil
+	}
+
+	err := mark(intKeys(appIDs), intKeys(hostIDs))
+	if nil != err {
+		d.Lock()
+		for id := range appIDs {
+			d.appIDs[id] = true
+		}
+		for id := range hostIDs {
+			d.hostIDs[id] = true
+		}
+		d.Unlock()
+	}
+	return err
+}
+
+func intKeys(set map[int]bool) []int {
+	keys := make([]int, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// markDueByHostController ask the host controller to clear the eval_time of the dynamic host groups,
+// any host server evaluates them at its next check
+func markDueByHostController(appIDs, hostIDs []int) error {
+	client := userAPISdk.NewClient(api.GetAPIResource().HostCtrl())
+	input := map[string]interface{}{common.BKAppIDField: appIDs, common.BKHostIDField: hostIDs}
+	code, reply, err := client.MarkDue(input)
+	if nil != err {
+		return err
+	}
+	if http.StatusOK != code || !reply.Result {
+		return fmt.Errorf("mark the host groups due failed, code: %d, message: %v", code, reply.Message)
+	}
+	return nil
+}
+
+// StartMarkHostGroupsDue mark the host groups collected from the events due every hostGroupDueInterval
+func StartMarkHostGroupsDue() error {
+	blog.Info("host group due marker started")
+	worker := lifecycle.NewWorker("host group due marker")
+	for {
+		time.Sleep(hostGroupDueInterval)
+		if !worker.Begin() {
+			blog.Info("host group due marker stopped by shutdown")
+			worker.Park()
+		}
+		if err := hostGroupsDue.flush(markDueByHostController); nil != err {
+			blog.Errorf("mark the host groups due error: %v", err)
+		}
+		worker.End()
+	}
+}
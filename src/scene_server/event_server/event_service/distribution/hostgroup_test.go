@@ -0,0 +1,70 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/scene_server/event_server/types"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCollectHostGroupsDue(t *testing.T) {
+	due := &dueHostGroups{appIDs: map[int]bool{}, hostIDs: map[int]bool{}}
+	marked := [][2][]int{}
+	mark := func(appIDs, hostIDs []int) error {
+		sort.Ints(appIDs)
+		sort.Ints(hostIDs)
+		marked = append(marked, [2][]int{appIDs, hostIDs})
+		return nil
+	}
+
+	// the event of the other object changes nothing
+	due.collect(&types.DistInst{EventInst: types.EventInst{EventType: types.EventTypeInstData, ObjType: common.BKInnerObjIDModule,
+		CurData: map[string]interface{}{common.BKAppIDField: float64(1)}}})
+	if err := due.flush(mark); nil != err || 0 != len(marked) {
+		t.Fatalf("nothing should be marked, error: %v, marked: %v", err, marked)
+	}
+
+	// the host moved out of the business 1 and into the business 2, which are read from the json as float64
+	due.collect(&types.DistInst{EventInst: types.EventInst{EventType: types.EventTypeRelation, ObjType: "moduletransfer",
+		PreData: map[string]interface{}{common.BKAppIDField: float64(1), common.BKHostIDField: float64(3)},
+		CurData: map[string]interface{}{common.BKAppIDField: float64(2), common.BKHostIDField: float64(3)}}})
+	// the host events are merged by the host
+	for i := 0; i < 3; i++ {
+		due.collect(&types.DistInst{EventInst: types.EventInst{EventType: types.EventTypeInstData, ObjType: common.BKInnerObjIDHost,
+			Action: types.EventActionUpdate, CurData: map[string]interface{}{common.BKHostIDField: float64(5)}}})
+	}
+	if err := due.flush(mark); nil != err {
+		t.Fatal(err)
+	}
+	if expected := [][2][]int{{{1, 2}, {5}}}; !reflect.DeepEqual(marked, expected) {
+		t.Fatalf("marked not as expected: %v", marked)
+	}
+
+	// the scope is kept for the next flush if failed
+	due.collect(&types.DistInst{EventInst: types.EventInst{EventType: types.EventTypeInstData, ObjType: common.BKInnerObjIDHost,
+		Action: types.EventActionCreate, CurData: map[string]interface{}{common.BKHostIDField: float64(6)}}})
+	if err := due.flush(func(appIDs, hostIDs []int) error { return errors.New("fake error") }); nil == err {
+		t.Fatal("error should not be nil")
+	}
+	marked = nil
+	if err := due.flush(mark); nil != err {
+		t.Fatal(err)
+	}
+	if expected := [][2][]int{{{}, {6}}}; !reflect.DeepEqual(marked, expected) {
+		t.Fatalf("marked not as expected: %v", marked)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"configcenter/src/common/blog"
+	"configcenter/src/common/core/cc/api"
+	"configcenter/src/common/metrics"
+	"configcenter/src/scene_server/event_server/types"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	deliveryTotal = metrics.NewCounterVec("cc_event_delivery_total", "the callbacks sent to the subscribers, by subscription and result", "subscription_id", "result")
+	queueDepth    = metrics.NewGaugeFunc("cc_event_queue_depth", "the events waiting in the event queue and the dist queue of each subscription",
+		[]string{"queue", "subscription_id"}, collectQueueDepth)
+)
+
+// distQueues the subscriptions being distributed, the depths of their dist queues are collected on scrape
+var distQueues = struct {
+	sync.Mutex
+	ids map[int64]bool
+}{ids: map[int64]bool{}}
+
+// watchDistQueue collect the depth of the dist queue of the subscription, the returned func stops it
+func watchDistQueue(subscriptionID int64) func() {
+	distQueues.Lock()
+	distQueues.ids[subscriptionID] = true
+	distQueues.Unlock()
+	return func() {
+		distQueues.Lock()
+		delete(distQueues.ids, subscriptionID)
+		distQueues.Unlock()
+	}
+}
+
+// distQueueIDs return the subscriptions whose dist queues are watched
+func distQueueIDs() []int64 {
+	distQueues.Lock()
+	defer distQueues.Unlock()
+	ids := make([]int64, 0, len(distQueues.ids))
+	for id := range distQueues.ids {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// recordDelivery count the callback by the result
+func recordDelivery(subscriptionID int64, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	deliveryTotal.Inc(fmt.Sprint(subscriptionID), result)
+}
+
+// DeleteDeliveryMetrics drop the delivery counters of the deleted subscription
+func DeleteDeliveryMetrics(subscriptionID int64) {
+	deliveryTotal.Delete(fmt.Sprint(subscriptionID), "success")
+	deliveryTotal.Delete(fmt.Sprint(subscriptionID), "failure")
+}
+
+// collectQueueDepth read the length of the event queue and the dist queues being distributed on scrape
+func collectQueueDepth() []metrics.Sample {
+	cache := api.GetAPIResource().Cache
+	if cache == nil {
+		return nil
+	}
+	samples := []metrics.Sample{}
+	if depth, err := cache.LLen(types.EventCacheEventQueueKey); err == nil {
+		samples = append(samples, metrics.Sample{Values: []string{"event", ""}, Value: float64(depth)})
+	} else {
+		blog.Errorf("get the length of the event queue error: %v", err)
+	}
+
+	for _, subscriptionID := range distQueueIDs() {
+		key := types.EventCacheDistQueuePrefix + fmt.Sprint(subscriptionID)
+		depth, err := cache.LLen(key)
+		if err != nil {
+			blog.Errorf("get the length of %s error: %v", key, err)
+			continue
+		}
+		samples = append(samples, metrics.Sample{Values: []string{"dist", fmt.Sprint(subscriptionID)}, Value: float64(depth)})
+	}
+	return samples
+}
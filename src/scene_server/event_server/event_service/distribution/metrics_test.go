@@ -0,0 +1,45 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+
+package distribution
+
+import (
+	"configcenter/src/common/core/cc/api"
+	"configcenter/src/common/metrics"
+	"configcenter/src/scene_server/event_server/types"
+	"configcenter/src/storage/memclient"
+	"reflect"
+	"testing"
+)
+
+func TestCollectQueueDepth(t *testing.T) {
+	cache := memclient.NewMemRedis()
+	api.GetAPIResource().Cache = cache
+	defer func() { api.GetAPIResource().Cache = nil }()
+	cache.RPush(types.EventCacheEventQueueKey, "a", "b")
+	cache.RPush(types.EventCacheDistQueuePrefix+"1", "a")
+	// the queue of the subscription not distributed here is not collected
+	cache.RPush(types.EventCacheDistQueuePrefix+"2", "a", "b")
+
+	stop := watchDistQueue(1)
+	expected := []metrics.Sample{{Values: []string{"event", ""}, Value: 2}, {Values: []string{"dist", "1"}, Value: 1}}
+	if samples := collectQueueDepth(); !reflect.DeepEqual(samples, expected) {
+		t.Fatalf("samples not as expected: %v", samples)
+	}
+
+	stop()
+	expected = []metrics.Sample{{Values: []string{"event", ""}, Value: 2}}
+	if samples := collectQueueDepth(); !reflect.DeepEqual(samples, expected) {
+		t.Fatalf("samples not as expected: %v", samples)
+	}
+}
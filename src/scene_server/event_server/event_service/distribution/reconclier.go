@@ -19,11 +19,7 @@ import (
 	"configcenter/src/common/util"
 	"configcenter/src/scene_server/event_server/types"
 	"configcenter/src/source_controller/common/instdata"
-	"configcenter/src/storage"
-	"configcenter/src/storage/dbclient"
 	"fmt"
-	redis "gopkg.in/redis.v5"
-	"io"
 	"strings"
 )
 
@@ -51,10 +47,14 @@ func (r *reconciler) loadAll() {
 }
 
 func (r *reconciler) loadAllCached() {
-	redisCli := api.GetAPIResource().CacheCli.GetSession().(*redis.Client)
-	for _, formkey := range redisCli.Keys(types.EventCacheSubscribeformKey + "*").Val() {
+	cache := api.GetAPIResource().Cache
+	formkeys, err := cache.Keys(types.EventCacheSubscribeformKey + "*")
+	if err != nil {
+		blog.Errorf("reconcile err: %v", err)
+	}
+	for _, formkey := range formkeys {
 		if formkey != "" && formkey != "nil" && formkey != "redis" {
-			r.cached[strings.TrimPrefix(formkey, types.EventCacheSubscribeformKey)] = redisCli.SMembers(formkey).Val()
+			r.cached[strings.TrimPrefix(formkey, types.EventCacheSubscribeformKey)], _ = cache.SMembers(formkey)
 		}
 	}
 }
@@ -75,19 +75,19 @@ func (r *reconciler) loadAllPersisted() {
 }
 
 func (r *reconciler) reconcile() {
-	redisCli := api.GetAPIResource().CacheCli.GetSession().(*redis.Client)
+	cache := api.GetAPIResource().Cache
 
 	for k, v := range r.persisted {
 		subs, plugs := util.CalSliceDiff(r.cached[k], v)
 		if len(subs) > 0 {
 			subss, _ := util.GetMapInterfaceByInerface(subs)
-			if err := redisCli.SRem(types.EventCacheSubscribeformKey+k, subss...).Err(); err != nil {
+			if err := cache.SRem(types.EventCacheSubscribeformKey+k, subss...); err != nil {
 				blog.Errorf("reconcile err: %v", err)
 			}
 		}
 		if len(plugs) > 0 {
 			plugss, _ := util.GetMapInterfaceByInerface(plugs)
-			if err := redisCli.SAdd(types.EventCacheSubscribeformKey+k, plugss...).Err(); err != nil {
+			if err := cache.SAdd(types.EventCacheSubscribeformKey+k, plugss...); err != nil {
 				blog.Errorf("reconcile err: %v", err)
 			}
 		}
@@ -95,50 +95,22 @@ func (r *reconciler) reconcile() {
 	}
 
 	for k := range r.cached {
-		redisCli.Del(types.EventCacheSubscribeformKey + k)
+		cache.Del(types.EventCacheSubscribeformKey + k)
 	}
 }
 
 func SubscribeChannel(config map[string]string) (err error) {
-	dType := storage.DI_REDIS
-	host := config[dType+".host"]
-	port := config[dType+".port"]
-	user := config[dType+".usr"]
-	pwd := config[dType+".pwd"]
-	dbName := config[dType+".database"]
-	dataCli, err := dbclient.NewDB(host, port, user, pwd, "", dbName, dType)
-	if err != nil {
-		return err
-	}
-	err = dataCli.Open()
-	if err != nil {
-		return err
-	}
-	session := dataCli.GetSession().(*redis.Client)
-	redisCli := *session
-	subChan, err := redisCli.PSubscribe(types.EventCacheProcessChannel)
+	subChan, err := api.GetAPIResource().Cache.PSubscribe(types.EventCacheProcessChannel)
 	if err != nil {
 		return err
 	}
+	defer subChan.Close()
 	blog.Info("receiving massages 2")
 	for {
-		mesg, err := subChan.Receive()
+		msg, err := subChan.Receive()
 		if err != nil {
 			return err
 		}
-		msg, ok := mesg.(*redis.Message)
-		if !ok {
-			continue
-		}
-		if err == redis.Nil || err == io.EOF {
-			continue
-		}
-		if nil != err {
-			blog.Error("reids err %s", err.Error())
-			subChan.Unsubscribe(types.EventCacheProcessChannel)
-			subChan.Subscribe(types.EventCacheProcessChannel)
-			continue
-		}
 		if "" == msg.Payload {
 			continue
 		}
@@ -0,0 +1,51 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"configcenter/src/scene_server/event_server/types"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// signCallback set the timestamp, delivery id and signature headers of the callback request.
+// the signature is the hex HMAC-SHA256 of "{timestamp}.{body}", one for each valid secret of the subscription,
+// so that the receiver can verify it with either the new or the old secret during the rotation
+func signCallback(req *http.Request, receiver *types.Subscription, deliveryID string, body string, now time.Time) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set(types.EventCallbackHeaderTimestamp, timestamp)
+	req.Header.Set(types.EventCallbackHeaderDelivery, deliveryID)
+
+	secrets := receiver.GetSecrets(now)
+	if len(secrets) == 0 {
+		return
+	}
+	signatures := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		signatures = append(signatures, "sha256="+computeSignature(secret, timestamp, body))
+	}
+	req.Header.Set(types.EventCallbackHeaderSignature, strings.Join(signatures, ","))
+}
+
+func computeSignature(secret, timestamp, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -25,5 +25,10 @@ func Start() error {
 		chErr <- StartDistribute()
 	}()
 
+	// mark the dynamic host groups due
+	go func() {
+		chErr <- StartMarkHostGroupsDue()
+	}()
+
 	return <-chErr
 }
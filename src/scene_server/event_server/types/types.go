@@ -21,18 +21,95 @@ import (
 
 // Subscription define
 type Subscription struct {
-	SubscriptionID   int64       `bson:"subscription_id" json:"subscription_id"`
-	SubscriptionName string      `bson:"subscription_name" json:"subscription_name"`
-	SystemName       string      `bson:"system_name" json:"system_name"`
-	CallbackURL      string      `bson:"callback_url" json:"callback_url"`
-	ConfirmMode      string      `bson:"confirm_mode" json:"confirm_mode"`
-	ConfirmPattern   string      `bson:"confirm_pattern" json:"confirm_pattern"`
-	TimeOut          int64       `bson:"time_out" json:"time_out"`                   // second
-	SubscriptionForm string      `bson:"subscription_form" json:"subscription_form"` // json format
-	Operator         string      `bson:"operator" json:"operator"`
-	OwnerID          string      `bson:"supplier_account" json:"supplier_account"`
-	LastTime         *types.Time `bson:"last_time" json:"last_time"`
-	Statistics       *Statistics `bson:"-" json:"statistics"`
+	SubscriptionID   int64        `bson:"subscription_id" json:"subscription_id"`
+	SubscriptionName string       `bson:"subscription_name" json:"subscription_name"`
+	SystemName       string       `bson:"system_name" json:"system_name"`
+	CallbackURL      string       `bson:"callback_url" json:"callback_url"`
+	ConfirmMode      string       `bson:"confirm_mode" json:"confirm_mode"`
+	ConfirmPattern   string       `bson:"confirm_pattern" json:"confirm_pattern"`
+	TimeOut          int64        `bson:"time_out" json:"time_out"`                   // second
+	SubscriptionForm string       `bson:"subscription_form" json:"subscription_form"` // json format
+	Operator         string       `bson:"operator" json:"operator"`
+	OwnerID          string       `bson:"supplier_account" json:"supplier_account"`
+	LastTime         *types.Time  `bson:"last_time" json:"last_time"`
+	RetryPolicy      *RetryPolicy `bson:"retry_policy" json:"retry_policy"`
+	Statistics       *Statistics  `bson:"-" json:"statistics"`
+
+	// Secret the key to sign the callback body, PreviousSecret is still used to sign until PreviousSecretExpire after rotation
+	Secret               string      `bson:"secret" json:"secret,omitempty"`
+	PreviousSecret       string      `bson:"previous_secret" json:"previous_secret,omitempty"`
+	PreviousSecretExpire *types.Time `bson:"previous_secret_expire" json:"previous_secret_expire,omitempty"`
+	SecretGracePeriod    int64       `bson:"-" json:"secret_grace_period,omitempty"` // second, only used when rotating the secret
+}
+
+// RetryPolicy define how a failed callback is retried before it is parked into the dead letters
+type RetryPolicy struct {
+	MaxAttempts  int     `bson:"max_attempts" json:"max_attempts"`   // including the first attempt
+	Backoff      int64   `bson:"backoff" json:"backoff"`             // millisecond, the delay before the first retry
+	MaxBackoff   int64   `bson:"max_backoff" json:"max_backoff"`     // millisecond, the upper limit of the delay
+	JitterFactor float64 `bson:"jitter_factor" json:"jitter_factor"` // 0~1, the random part of the delay
+}
+
+// DefaultRetryPolicy the retry policy used when the subscription does not define one
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:  3,
+	Backoff:      1000,
+	MaxBackoff:   30000,
+	JitterFactor: 0.2,
+}
+
+// Normalize fill the unset fields with the default value and limit the fields into the valid range
+func (p *RetryPolicy) Normalize() {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.MaxAttempts > MaxRetryAttempts {
+		p.MaxAttempts = MaxRetryAttempts
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = DefaultRetryPolicy.Backoff
+	}
+	if p.Backoff > MaxRetryBackoff {
+		p.Backoff = MaxRetryBackoff
+	}
+	if p.MaxBackoff < p.Backoff {
+		p.MaxBackoff = p.Backoff
+	}
+	if p.MaxBackoff > MaxRetryBackoff {
+		p.MaxBackoff = MaxRetryBackoff
+	}
+	if p.JitterFactor < 0 {
+		p.JitterFactor = 0
+	}
+	if p.JitterFactor > 1 {
+		p.JitterFactor = 1
+	}
+}
+
+// GetBackoff return the delay before the attempt (start from 1), random is a value in [0,1)
+func (p RetryPolicy) GetBackoff(attempt int, random float64) time.Duration {
+	if attempt <= 1 {
+		return 0
+	}
+	backoff := float64(p.Backoff)
+	for i := 2; i < attempt && backoff < float64(p.MaxBackoff); i++ {
+		backoff = backoff * 2
+	}
+	if backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	backoff = backoff * (1 - p.JitterFactor + 2*p.JitterFactor*random)
+	return time.Duration(backoff) * time.Millisecond
+}
+
+// DeadLetter define the dist event which still failed after all the attempts
+type DeadLetter struct {
+	DstbID         int64      `json:"dstb_id"`
+	SubscriptionID int64      `json:"subscription_id"`
+	Attempts       int        `json:"attempts"`
+	LastError      string     `json:"last_error"`
+	FailedTime     types.Time `json:"failed_time"`
+	Event          string     `json:"event"`
 }
 
 // Report define sending statistic
@@ -53,6 +130,11 @@ func (s Subscription) GetCacheKey() string {
 		ConfirmPattern:   s.ConfirmPattern,
 		SubscriptionForm: s.SubscriptionForm,
 		TimeOut:          s.TimeOut,
+		RetryPolicy:      s.RetryPolicy,
+
+		Secret:               s.Secret,
+		PreviousSecret:       s.PreviousSecret,
+		PreviousSecretExpire: s.PreviousSecretExpire,
 	}
 	b, _ := json.Marshal(ns)
 	return string(b)
@@ -62,6 +144,55 @@ func (s Subscription) GetTimeout() time.Duration {
 	return time.Second * time.Duration(s.TimeOut)
 }
 
+// GetSecrets return the secrets to sign the callback body, the current one first
+func (s Subscription) GetSecrets(now time.Time) []string {
+	secrets := []string{}
+	if s.Secret != "" {
+		secrets = append(secrets, s.Secret)
+	}
+	if s.PreviousSecret != "" && s.PreviousSecretExpire != nil && now.Before(s.PreviousSecretExpire.Time) {
+		secrets = append(secrets, s.PreviousSecret)
+	}
+	return secrets
+}
+
+// RotateSecret keep the old secret valid for the grace period if the secret has been changed,
+// the old secret is kept if no new secret is given
+func (s *Subscription) RotateSecret(old Subscription, now time.Time) {
+	if s.Secret == "" || s.Secret == old.Secret {
+		s.Secret = old.Secret
+		s.PreviousSecret = old.PreviousSecret
+		s.PreviousSecretExpire = old.PreviousSecretExpire
+		return
+	}
+	if old.Secret == "" {
+		return
+	}
+	grace := s.SecretGracePeriod
+	if grace <= 0 {
+		grace = DefaultSecretGracePeriod
+	}
+	expire := types.Time{Time: now.Add(time.Duration(grace) * time.Second)}
+	s.PreviousSecret = old.Secret
+	s.PreviousSecretExpire = &expire
+}
+
+// HideSecrets clean the secrets before the subscription is returned to the client
+func (s *Subscription) HideSecrets() {
+	s.Secret = ""
+	s.PreviousSecret = ""
+}
+
+// GetRetryPolicy return the normalized retry policy of the subscription
+func (s Subscription) GetRetryPolicy() RetryPolicy {
+	policy := DefaultRetryPolicy
+	if s.RetryPolicy != nil {
+		policy = *s.RetryPolicy
+	}
+	policy.Normalize()
+	return policy
+}
+
 type EventInst struct {
 	ID          int64       `json:"event_id,omitempty"`
 	EventType   string      `json:"event_type"`
@@ -107,6 +238,15 @@ func (e *EventInst) GetDistInst() *DistInst {
 	return &distinst
 }
 
+// EventHistory the persisted event which can be replayed to the subscribers
+type EventHistory struct {
+	EventID    int64     `bson:"event_id" json:"event_id"`
+	EventKey   string    `bson:"event_key" json:"event_key"` // the subscribed event name, such as hostcreate
+	ActionTime time.Time `bson:"action_time" json:"action_time"`
+	CreateTime time.Time `bson:"create_time" json:"create_time"`
+	Event      string    `bson:"event" json:"event"`
+}
+
 type EventInstCtx struct {
 	EventInst
 	Raw string
@@ -37,6 +37,7 @@ const (
 	EventCacheDistDonePrefix    = common.BKCacheKeyV3Prefix + "event:dist_done_"
 
 	EventCacheDistCallBackCountPrefix = common.BKCacheKeyV3Prefix + "event:dist_callback_"
+	EventCacheDistDeadLetterPrefix    = common.BKCacheKeyV3Prefix + "event:dist_deadletter_"
 
 	// EventCacheSubscribeformKey the key prefix in cache
 	EventCacheSubscribeformKey = common.BKCacheKeyV3Prefix + "event:subscribeform_"
@@ -47,6 +48,27 @@ const (
 // TableNames
 const (
 	TableNameSubscription = "cc_Subscription"
+	TableNameEventHistory = "cc_EventHistory"
+)
+
+// DefaultEventHistoryExpireDays the default days the event is kept in the history for replay
+const DefaultEventHistoryExpireDays = 7
+
+// MaxRetryAttempts the upper limit of the callback attempts of one dist event
+const MaxRetryAttempts = 10
+
+// MaxRetryBackoff millisecond, the upper limit of the delay between the callback attempts,
+// the dist event keeps running during all the delays
+const MaxRetryBackoff = 300000
+
+// DefaultSecretGracePeriod the default seconds the old secret still signs the callback after rotation
+const DefaultSecretGracePeriod = 24 * 60 * 60
+
+// the http headers of the callback request
+const (
+	EventCallbackHeaderTimestamp = "X-Bk-Event-Timestamp"
+	EventCallbackHeaderDelivery  = "X-Bk-Event-Delivery"
+	EventCallbackHeaderSignature = "X-Bk-Event-Signature"
 )
 
 // EventAction
@@ -54,6 +76,10 @@ const (
 	EventActionCreate = "create"
 	EventActionUpdate = "update"
 	EventActionDelete = "delete"
+
+	// EventActionJoin, EventActionLeave the host joins or leaves the dynamic host group
+	EventActionJoin  = "join"
+	EventActionLeave = "leave"
 )
 
 // EventType define
@@ -14,6 +14,7 @@ package options
 
 import (
 	"configcenter/src/common/core/cc/config"
+	"configcenter/src/common/discovery"
 
 	"github.com/spf13/pflag"
 )
@@ -36,5 +37,9 @@ func NewServerOption() *ServerOption {
 func (s *ServerOption) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&s.ServConf.AddrPort, "addrport", "127.0.0.1:60002", "The ip address and port for the serve on")
 	fs.StringVar(&s.ServConf.RegDiscover, "regdiscv", "", "hosts of register and discover server. e.g: 127.0.0.1:2181")
+	fs.StringVar(&s.ServConf.RegDiscoverBackend, "regdiscv-backend", config.RegDiscoverZookeeper, "backend of register and discover server, zookeeper or file. the regdiscv is the registry file path for file backend")
+	fs.StringVar(&s.ServConf.DiscoveryStrategy, "discovery-strategy", string(discovery.RoundRobin), "the way to pick one instance of the discovered servers, roundrobin, leastinflight or weighted")
+	fs.IntVar(&s.ServConf.DiscoveryMaxFails, "discovery-max-fails", discovery.DefaultMaxFails, "the consecutive failures to eject an instance of the discovered servers, negative disables the ejection")
+	fs.DurationVar(&s.ServConf.DiscoveryEjectTime, "discovery-eject-time", discovery.DefaultEjectTime, "how long an ejected instance of the discovered servers is kept out")
 	fs.StringVar(&s.ServConf.ExConfig, "config", "", "The config path. e.g conf/api.conf")
 }
@@ -178,7 +178,7 @@ func (cli *hostAction) DeleteHostBatch(req *restful.Request, resp *restful.Respo
 			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrHostDeleteFail)
 
 		}
-		opClient := auditlog.NewClient(cli.CC.AuditCtrl())
+		opClient := auditlog.NewClient(cli.CC.AuditCtrl()).SetRequestID(util.GetActionRequestID(req))
 		opClient.AuditHostsLog(logConents, "删除主机", ownerID, fmt.Sprintf("%d", appID), user, auditoplog.AuditOpTypeDel)
 
 		return http.StatusOK, common.CCSuccessStr, nil
@@ -158,33 +158,18 @@ func (m *hostModuleConfigAction) HostModuleRelation(req *restful.Request, resp *
 				blog.Error("Host does not belong to the current application; error, params:{appid:%d, hostid:%s}", data.ApplicationID, hostID)
 				return http.StatusInternalServerError, nil, defErr.Errorf(common.CCErrHostNotINAPP, hostID)
 			}
+		}
 
-			params := make(map[string]interface{})
-			delModulesURL := ""
-			params[common.BKAppIDField] = data.ApplicationID
-			params[common.BKHostIDField] = hostID
-
-			if data.IsIncrement {
-				delModulesURL = m.CC.HostCtrl() + "/host/v1/meta/hosts/defaultmodules"
-			} else {
-				delModulesURL = m.CC.HostCtrl() + "/host/v1/meta/hosts/modules"
-
-			}
-			isSuccess, errMsg, _ := logics.GetHttpResult(req, delModulesURL, common.HTTPDelete, params)
-			if !isSuccess {
-				blog.Error("remove hosthostconfig error, params:%v, error:%s", params, errMsg)
-				return http.StatusInternalServerError, nil, defErr.Errorf(common.CCErrHostDELResourcePool, hostID)
-			}
-
-			addModulesURL := m.CC.HostCtrl() + "/host/v1/meta/hosts/modules"
-
-			params[common.BKModuleIDField] = data.ModuleID
-			isSuccess, errMsg, _ = logics.GetHttpResult(req, addModulesURL, common.HTTPCreate, params)
-			if !isSuccess {
-				blog.Error("add hosthostconfig error, params:%v, error:%s", params, errMsg)
-				return http.StatusInternalServerError, nil, defErr.Errorf(common.CCErrHostAddRelationFail, hostID)
-
-			}
+		params := make(map[string]interface{})
+		params[common.BKAppIDField] = data.ApplicationID
+		params[common.BKHostIDField] = data.HostID
+		params[common.BKModuleIDField] = data.ModuleID
+		params["is_increment"] = data.IsIncrement
+		transferURL := m.CC.HostCtrl() + "/host/v1/meta/hosts/transfer"
+		isSuccess, errMsg, _ := logics.GetHttpResult(req, transferURL, common.HTTPUpdate, params)
+		if !isSuccess {
+			blog.Errorf("transfer hostmoduleconfig error, params:%v, error:%s", params, errMsg)
+			return http.StatusInternalServerError, nil, defErr.Errorf(common.CCErrHostAddRelationFail, data.HostID, errMsg)
 		}
 		user := util.GetActionUser(req)
 		logClient.SaveLog(fmt.Sprintf("%d", data.ApplicationID), user)
@@ -448,22 +433,15 @@ func (m *hostModuleConfigAction) moveHostToModuleByName(req *restful.Request, re
 				blog.Error("Host does not belong to the current application; error, params:{appid:%d, hostid:%s}", data.ApplicationID, hostID)
 				return http.StatusInternalServerError, nil, defErr.Errorf(common.CCErrHostNotINAPP, hostID)
 			}
+		}
 
-			moduleHostConfigParams[common.BKHostIDField] = hostID
-			delModulesURL := m.CC.HostCtrl() + "/host/v1/meta/hosts/modules"
-			isSuccess, errMsg, _ := logics.GetHttpResult(req, delModulesURL, common.HTTPDelete, moduleHostConfigParams)
-			if !isSuccess {
-				blog.Error("remove hosthostconfig error, params:%v, error:%s", moduleHostConfigParams, errMsg)
-				return http.StatusInternalServerError, nil, defErr.Errorf(common.CCErrCommHTTPDoRequestFailed)
-			}
-			moduleHostConfigParams[common.BKModuleIDField] = []int{moduleID}
-			addModulesURL := m.CC.HostCtrl() + "/host/v1/meta/hosts/modules"
-
-			isSuccess, errMsg, _ = logics.GetHttpResult(req, addModulesURL, common.HTTPCreate, moduleHostConfigParams)
-			if !isSuccess {
-				blog.Error("add hosthostconfig error, params:%v, error:%s", moduleHostConfigParams, errMsg)
-				return http.StatusInternalServerError, nil, defErr.Errorf(common.CCErrHostModuleRelationAddFailed, errMsg)
-			}
+		moduleHostConfigParams[common.BKHostIDField] = data.HostID
+		moduleHostConfigParams[common.BKModuleIDField] = []int{moduleID}
+		transferURL := m.CC.HostCtrl() + "/host/v1/meta/hosts/transfer"
+		isSuccess, errMsg, _ := logics.GetHttpResult(req, transferURL, common.HTTPUpdate, moduleHostConfigParams)
+		if !isSuccess {
+			blog.Errorf("transfer hostmoduleconfig error, params:%v, error:%s", moduleHostConfigParams, errMsg)
+			return http.StatusInternalServerError, nil, defErr.Errorf(common.CCErrHostModuleRelationAddFailed, errMsg)
 		}
 		user := util.GetActionUser(req)
 		logClient.SetDesc("转移主机到" + moduleName)
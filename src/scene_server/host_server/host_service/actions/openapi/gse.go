@@ -19,11 +19,13 @@ import (
 	"configcenter/src/common/core/cc/actions"
 	httpcli "configcenter/src/common/http/httpclient"
 	"configcenter/src/common/util"
+	"configcenter/src/storage"
+	"configcenter/src/storage/redisclient"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"strconv"
-	"strings"
 	"time"
 
 	myCommon "configcenter/src/scene_server/host_server/common"
@@ -39,9 +41,13 @@ var gse *gseAction = &gseAction{}
 
 var (
 	redisIp string        = ""
-	client  *redis.Client = nil
+	client  storage.Cache = nil
 )
 
+// agentStatusUnsupported the status of the host without ipv4, gse only publishes the alive agents of the
+// cloud area as the bitmap agentalive_cloudid_<id> indexed by the ipv4, there is no source of the ipv6 agents
+const agentStatusUnsupported int64 = -1
+
 type gseAction struct {
 	base.BaseAction
 }
@@ -83,10 +89,9 @@ func (cli *gseAction) GetAgentStatus(req *restful.Request, resp *restful.Respons
 			return
 		}
 
-		intIp := ip2long(ip)
 		comID := platID<<22 + companyID
 		agentFlag := fmt.Sprintf("agentalive_cloudid_%d", comID)
-		cellData := map[string]interface{}{"agentFlag": agentFlag, "offset": intIp}
+		cellData := map[string]interface{}{"agentFlag": agentFlag, "ips": util.SplitIPList(ip)}
 
 		hostDataArr = append(hostDataArr, cellData)
 	}
@@ -100,8 +105,11 @@ func (cli *gseAction) GetAgentStatus(req *restful.Request, resp *restful.Respons
 	agentNorCnt := 0
 	agentAbnorCnt := 0
 
+	agentUnsupportCnt := 0
+
 	agentNorList := make([]map[string]interface{}, 0)
 	agentAbnorList := make([]map[string]interface{}, 0)
+	agentUnsupportList := make([]map[string]interface{}, 0)
 	i := 0
 
 	blog.Debug("agentStatus:%v", agentStatus)
@@ -126,6 +134,9 @@ func (cli *gseAction) GetAgentStatus(req *restful.Request, resp *restful.Respons
 		if status == 1 {
 			agentNorCnt++
 			agentNorList = append(agentNorList, hostMapTemp)
+		} else if status == agentStatusUnsupported {
+			agentUnsupportCnt++
+			agentUnsupportList = append(agentUnsupportList, hostMapTemp)
 		} else {
 			agentAbnorCnt++
 			agentAbnorList = append(agentAbnorList, hostMapTemp)
@@ -135,35 +146,28 @@ func (cli *gseAction) GetAgentStatus(req *restful.Request, resp *restful.Respons
 	}
 
 	resData := map[string]interface{}{
-		"agentNorCnt":    agentNorCnt,
-		"agentAbnorCnt":  agentAbnorCnt,
-		"agentNorList":   agentNorList,
-		"agentAbnorList": agentAbnorList,
+		"agentNorCnt":        agentNorCnt,
+		"agentAbnorCnt":      agentAbnorCnt,
+		"agentUnsupportCnt":  agentUnsupportCnt,
+		"agentNorList":       agentNorList,
+		"agentAbnorList":     agentAbnorList,
+		"agentUnsupportList": agentUnsupportList,
 	}
 
 	cli.ResponseSuccess(resData, resp)
 }
 
-//ip2long
-func ip2long(ip string) int64 {
-	bits := strings.Split(ip, ".")
-
-	b0, _ := strconv.Atoi(bits[0])
-	b1, _ := strconv.Atoi(bits[1])
-	b2, _ := strconv.Atoi(bits[2])
-	b3, _ := strconv.Atoi(bits[3])
-
-	var sum int64
-
-	sum += int64(b0) << 24
-	sum += int64(b1) << 16
-	sum += int64(b2) << 8
-	sum += int64(b3)
-
-	return sum
+//ip2long return the bitmap offset of the ipv4, false if it is not an ipv4
+func ip2long(ip string) (int64, bool) {
+	ipv4 := net.ParseIP(ip).To4()
+	if nil == ipv4 {
+		return 0, false
+	}
+	return int64(ipv4[0])<<24 | int64(ipv4[1])<<16 | int64(ipv4[2])<<8 | int64(ipv4[3]), true
 }
 
-//getGseAgentStatus
+//getGseAgentStatus return 1 for the host if the agent of any of its ipv4 is alive,
+//the ipv6 are not published by gse, so the host with only ipv6 gets agentStatusUnsupported
 func getGseAgentStatus(hostDataArr []interface{}) ([]int64, error) {
 	blog.Infof("getGseAgentStatus hostDataArr1:%v", hostDataArr)
 	if len(hostDataArr) == 0 {
@@ -175,47 +179,46 @@ func getGseAgentStatus(hostDataArr []interface{}) ([]int64, error) {
 		blog.Error("getRedisSession error:%v", err)
 		return nil, err
 	}
-	length := len(hostDataArr)
-	data := make([]int64, length)
-	pipe := client.Pipeline()
-	for _, hostData := range hostDataArr {
+	status := make([]int64, len(hostDataArr))
+	for index := range status {
+		status[index] = agentStatusUnsupported
+	}
+	bits := make([]storage.BitKey, 0, len(hostDataArr))
+	bitHosts := make([]int, 0, len(hostDataArr))
+	for index, hostData := range hostDataArr {
 		hostDataMap := hostData.(map[string]interface{})
 		blog.Infof("get gse hostDataMap:%v", hostDataMap)
-		pipe.GetBit(hostDataMap["agentFlag"].(string), hostDataMap["offset"].(int64))
+		agentFlag := hostDataMap["agentFlag"].(string)
+		for _, ip := range hostDataMap["ips"].([]string) {
+			offset, ok := ip2long(ip)
+			if false == ok {
+				continue
+			}
+			status[index] = 0
+			bits = append(bits, storage.BitKey{Key: agentFlag, Offset: offset})
+			bitHosts = append(bitHosts, index)
+		}
+	}
+	if 0 == len(bits) {
+		return status, nil
 	}
 
-	result, err := pipe.Exec()
-	if err == redis.Nil {
-		return []int64{}, nil
-	} else if err != nil {
-		blog.Error("redis get bit error %s, hostData:%v", err.Error(), hostDataArr)
+	data, err := client.GetBits(bits)
+	if nil != err {
+		blog.Errorf("redis get bit error %s, hostData:%v", err.Error(), hostDataArr)
 		return []int64{}, err
-
-	} else {
-		for i, re := range result {
-
-			reArr := strings.Split(re.String(), ":")
-			var valTemp string
-			if len(reArr) == 2 {
-				valTemp = reArr[1]
-			} else {
-				valTemp = reArr[0]
-			}
-			val := strings.Replace(valTemp, " ", "", -1)
-
-			data[i], err = strconv.ParseInt(val, 10, 64)
-			if nil != err {
-				blog.Error("get bit error %s, re:%v", err, re)
-				return nil, err
-			}
+	}
+	for i, bit := range data {
+		if 1 == bit {
+			status[bitHosts[i]] = 1
 		}
 	}
-	return data, nil
+	return status, nil
 
 }
 
 //getRedisSession
-func getRedisSession() (*redis.Client, error) {
+func getRedisSession() (storage.Cache, error) {
 	newIp, port, auth, err := getRedisIP()
 	blog.Error(fmt.Sprintf("newIp:%v port:%v auth:%v err:%v", newIp, port, auth, err))
 
@@ -231,7 +234,7 @@ func getRedisSession() (*redis.Client, error) {
 	}
 	redisIp = newIp
 	blog.Infof("redisIp:%v", redisIp)
-	client = redis.NewClient(&redis.Options{
+	client = redisclient.NewCache(redis.NewClient(&redis.Options{
 		Addr:         redisIp + ":" + port,
 		DialTimeout:  10 * time.Second,
 		ReadTimeout:  30 * time.Second,
@@ -240,7 +243,7 @@ func getRedisSession() (*redis.Client, error) {
 		PoolTimeout:  30 * time.Second,
 		Password:     auth,
 		DB:           0,
-	})
+	}))
 	return client, nil
 }
 
@@ -19,6 +19,7 @@ import (
 	"configcenter/src/common/core/cc/actions"
 	"configcenter/src/common/core/cc/api"
 	httpcli "configcenter/src/common/http/httpclient"
+	hostParse "configcenter/src/common/paraparse"
 	"configcenter/src/common/util"
 	"configcenter/src/scene_server/host_server/host_service/logics"
 	"encoding/json"
@@ -94,11 +95,23 @@ func (cli *hostAction) HostSearchByIP(req *restful.Request, resp *restful.Respon
 	appIDArrInput, hasAppID := input[common.BKAppIDField]
 	subArea, hasSubArea := input[common.BKCloudIDField]
 
-	orCondition := []map[string]interface{}{
-		map[string]interface{}{common.BKHostInnerIPField: map[string]interface{}{common.BKDBIN: ipArr}},
-		map[string]interface{}{common.BKHostOuterIPField: map[string]interface{}{common.BKDBIN: ipArr}},
+	ips := make([]string, 0)
+	if arr, ok := ipArr.([]interface{}); ok {
+		for _, ip := range arr {
+			ips = append(ips, fmt.Sprint(ip))
+		}
+	}
+	if 0 == len(ips) {
+		blog.Error("input ip is empty:%v", ipArr)
+		cli.ResponseFailed(common.CC_Err_Comm_http_Input_Params, common.CC_Err_Comm_http_Input_Params_STR, resp)
+		return
+	}
+	hostMapCondition, err := hostParse.IPSearchCondition(ips, common.BKHostInnerIPField, common.BKHostOuterIPField)
+	if nil != err {
+		blog.Error("input ip is invalid:%v", ipArr)
+		cli.ResponseFailed(common.CC_Err_Comm_http_Input_Params, common.CC_Err_Comm_http_Input_Params_STR, resp)
+		return
 	}
-	hostMapCondition := map[string]interface{}{common.BKDBOR: orCondition}
 
 	if hasSubArea && subArea != nil && subArea != "" {
 		hostMapCondition[common.BKCloudIDField] = subArea
@@ -479,6 +492,17 @@ func (cli *hostAction) UpdateHost(req *restful.Request, resp *restful.Response)
 		cli.ResponseFailed(common.CC_Err_Comm_http_Input_Params, common.CC_Err_Comm_http_Input_Params_STR, resp)
 		return
 	}
+	condition, ok := input["condition"].(map[string]interface{})
+	if !ok {
+		blog.Error("params condition must be object:%s", string(value))
+		cli.ResponseFailed(common.CC_Err_Comm_http_Input_Params, common.CC_Err_Comm_http_Input_Params_STR, resp)
+		return
+	}
+	if _, err := logics.NormalizeHostIP(condition); nil != err {
+		blog.Error("params condition ip is invalid:%v", err)
+		cli.ResponseFailed(common.CC_Err_Comm_http_Input_Params, common.CC_Err_Comm_http_Input_Params_STR, resp)
+		return
+	}
 
 	// dst host exist return souccess, hongsong tiyi
 	dstHostCondition := map[string]interface{}{
@@ -561,6 +585,11 @@ func (cli *hostAction) UpdateHostByAppID(req *restful.Request, resp *restful.Res
 
 	for _, pro := range proxyArr {
 		proMap := pro.(map[string]interface{})
+		if _, err := logics.NormalizeHostIP(proMap); nil != err {
+			blog.Error("proxy ip is invalid:%v", err)
+			cli.ResponseFailed(common.CC_Err_Comm_http_Input_Params, common.CC_Err_Comm_http_Input_Params_STR, resp)
+			return
+		}
 		var hostID int
 		innerIP := proMap[common.BKHostInnerIPField]
 		outerIP, ok := proMap[common.BKHostOuterIPField]
@@ -842,7 +871,12 @@ func (cli *hostAction) CloneHostProperty(req *restful.Request, resp *restful.Res
 	}
 	blog.Debug("CloneHostProperty input:%v", input)
 	appId, _ := strconv.Atoi(input[common.BKAppIDField].(string))
-	orgIp := input[common.BKOrgIPField]
+	orgIp, err := util.NormalizeIP(fmt.Sprint(input[common.BKOrgIPField]))
+	if nil != err {
+		blog.Error("clone host org ip is invalid:%v", err)
+		cli.ResponseFailed(common.CC_Err_Comm_http_Input_Params, common.CC_Err_Comm_http_Input_Params_STR, resp)
+		return
+	}
 	dstIp := input[common.BKDstIPField]
 
 	platId, hasPlatId := input[common.BKCloudIDField]
@@ -897,6 +931,14 @@ func (cli *hostAction) CloneHostProperty(req *restful.Request, resp *restful.Res
 	}
 	// 处理目标IP
 	dstIpArr := strings.Split(dstIp.(string), ",")
+	for index, ip := range dstIpArr {
+		dstIpArr[index], err = util.NormalizeIP(ip)
+		if nil != err {
+			blog.Error("clone host dst ip is invalid:%v", err)
+			cli.ResponseFailed(common.CC_Err_Comm_http_Input_Params, common.CC_Err_Comm_http_Input_Params_STR, resp)
+			return
+		}
+	}
 	// 获得已存在的主机
 	dstCondition := map[string]interface{}{
 		common.BKHostInnerIPField: map[string]interface{}{
@@ -1267,6 +1309,9 @@ func (cli *hostAction) GetGitServerIp(req *restful.Request, resp *restful.Respon
 func updateHostMain(req *restful.Request, hostCondition, data map[string]interface{}, appID int, hostCtrl, objCtrl, auditCtrl string, errIf errorIfs.CCErrorIf) (string, error) {
 	blog.Debug("updateHostMain start")
 	blog.Debug("hostCondition:%v", hostCondition)
+	if field, err := logics.NormalizeHostIP(data); nil != err {
+		return "", errors.New(fmt.Sprintf("invalid %s:%v", field, err))
+	}
 	_, hostIDArr, err := getHostMapByCond(req, hostCondition)
 
 	blog.Debug("hostIDArr:%v", hostIDArr)
@@ -1328,7 +1373,7 @@ func updateHostMain(req *restful.Request, hostCondition, data map[string]interfa
 			bl, _ := resJs.Get("result").Bool()
 			if bl {
 				user := util.GetActionUser(req)
-				opClient := auditlog.NewClient(auditCtrl)
+				opClient := auditlog.NewClient(auditCtrl).SetRequestID(util.GetActionRequestID(req))
 				content, _ := logContent.GetHostLog(strHostID, false)
 				//(id interface{}, Content interface{}, OpDesc string, InnerIP, ownerID, appID, user string, OpType auditoplog.AuditOpType)
 				opClient.AuditHostLog(hostID, content, "修改主机", logContent.GetInnerIP(), common.BKDefaultOwnerID, fmt.Sprintf("%d", appID), user, auditoplog.AuditOpTypeModify)
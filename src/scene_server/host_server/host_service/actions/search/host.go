@@ -54,6 +54,10 @@ func (cli *hostAction) HostSearch(req *restful.Request, resp *restful.Response)
 			return http.StatusBadRequest, nil, defErr.Error(common.CCErrCommHTTPReadBodyFailed)
 
 		}
+		if err := hostParse.ParseHostIPParams(data.Ip, make(map[string]interface{})); nil != err {
+			blog.Errorf("invalid ip search %v error:%s", data.Ip, err)
+			return http.StatusBadRequest, nil, defErr.Errorf(common.CCErrCommParamsInvalid, "ip")
+		}
 
 		reply, err := logics.HostSearch(req, data, cli.CC.HostCtrl(), cli.CC.ObjCtrl())
 		if nil != err {
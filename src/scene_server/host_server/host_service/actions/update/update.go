@@ -71,6 +71,10 @@ func (cli *hostAction) UpdateHostBatch(req *restful.Request, resp *restful.Respo
 
 		}
 		delete(data, common.BKHostIDField)
+		if field, err := logics.NormalizeHostIP(data); nil != err {
+			blog.Errorf("update host batch invalid %s:%v", field, err)
+			return http.StatusBadRequest, nil, defErr.Errorf(common.CCErrCommParamsInvalid, field)
+		}
 		valid := validator.NewValidMap(common.BKDefaultOwnerID, common.BKInnerObjIDHost, cli.CC.ObjCtrl(), defErr)
 
 		hostIDArr := strings.Split(hostIDStr, ",")
@@ -131,7 +135,7 @@ func (cli *hostAction) UpdateHostBatch(req *restful.Request, resp *restful.Respo
 			logLastConents = append(logLastConents, auditoplog.AuditLogExt{ID: i, Content: logContent, ExtKey: preLogContent.ExtKey})
 
 		}
-		opClient := auditlog.NewClient(cli.CC.AuditCtrl())
+		opClient := auditlog.NewClient(cli.CC.AuditCtrl()).SetRequestID(util.GetActionRequestID(req))
 		opClient.AuditHostsLog(logLastConents, "修改主机", common.BKDefaultOwnerID, appID, user, auditoplog.AuditOpTypeModify)
 
 		return http.StatusOK, common.CCSuccessStr, nil
@@ -46,6 +46,7 @@ func init() {
 	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/userapi/search/{bk_biz_id}", Params: nil, Handler: userAPI.Get})
 	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/userapi/detail/{bk_biz_id}/{id}", Params: nil, Handler: userAPI.Detail})
 	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/userapi/data/{bk_biz_id}/{id}/{start}/{limit}", Params: nil, Handler: userAPI.GetUserAPIData})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/userapi/members/{bk_biz_id}/{id}", Params: nil, Handler: userAPI.GetMembers})
 
 }
 
@@ -85,7 +86,14 @@ func (u *userAPIAction) Add(req *restful.Request, resp *restful.Response) {
 		userAPI.ResponseFailedEx(http.StatusBadRequest, common.CCErrCommParamsNeedSet, defErr.Errorf(common.CCErrCommParamsNeedSet, common.BKAppIDField).Error(), nil, resp)
 		return
 	}
+	if field := checkHostGroupParams(params); "" != field {
+		blog.Errorf("dynamic host group param %s invalid, params:%v", field, params)
+		userAPI.ResponseFailedEx(http.StatusBadRequest, common.CCErrCommParamsInvalid, defErr.Errorf(common.CCErrCommParamsInvalid, field).Error(), nil, resp)
+		return
+	}
 	params["create_user"] = util.GetActionUser(req)
+	// the dynamic host group is evaluated as the owner in the background
+	params[common.BKOwnerIDField] = util.GetActionOnwerID(req)
 	code, reply, err := client.Create(params)
 	if nil != err {
 		userAPI.ResponseFailedEx(http.StatusBadGateway, common.CC_Err_Comm_Host_Get_FAIL, err.Error(), nil, resp)
@@ -121,7 +129,15 @@ func (u *userAPIAction) Update(req *restful.Request, resp *restful.Response) {
 		userAPI.ResponseFailedEx(http.StatusBadRequest, common.CCErrCommJSONUnmarshalFailed, defErr.Error(common.CCErrCommJSONUnmarshalFailed).Error(), nil, resp)
 		return
 	}
+	if field := checkHostGroupParams(params); "" != field {
+		blog.Errorf("dynamic host group param %s invalid, params:%v", field, params)
+		userAPI.ResponseFailedEx(http.StatusBadRequest, common.CCErrCommParamsInvalid, defErr.Errorf(common.CCErrCommParamsInvalid, field).Error(), nil, resp)
+		return
+	}
 	params["modify_user"] = util.GetActionUser(req)
+	if _, ok := params["dynamic"]; ok {
+		params[common.BKOwnerIDField] = util.GetActionOnwerID(req)
+	}
 
 	client := userAPISdk.NewClient(URL)
 	code, reply, err := client.Update(params, req.PathParameter("bk_biz_id"), req.PathParameter("id"))
@@ -313,3 +329,39 @@ func (u *userAPIAction) GetUserAPIData(req *restful.Request, resp *restful.Respo
 	return
 
 }
+
+//GetMembers get the hosts of the dynamic host group, they are stored by the last evaluation
+func (u *userAPIAction) GetMembers(req *restful.Request, resp *restful.Response) {
+
+	client := userAPISdk.NewClient(u.CC.HostCtrl())
+	code, reply, err := client.GetMembers(req.PathParameter("bk_biz_id"), req.PathParameter("id"))
+	if nil != err {
+		userAPI.ResponseFailedEx(http.StatusBadGateway, common.CC_Err_Comm_http_DO, err.Error(), nil, resp)
+		return
+	}
+	if code != http.StatusOK {
+		userAPI.ResponseFailedEx(code, reply.Code, reply.Message, nil, resp)
+		return
+	}
+
+	u.ResponseSuccess(reply.Data, resp)
+	return
+}
+
+// checkHostGroupParams check the dynamic and eval_interval of the custom query, eval_interval is in seconds,
+// return the invalid field
+func checkHostGroupParams(params map[string]interface{}) string {
+	if dynamic, ok := params["dynamic"]; ok {
+		if _, ok := dynamic.(bool); !ok {
+			return "dynamic"
+		}
+	}
+	if interval, ok := params["eval_interval"]; ok {
+		seconds, err := util.GetIntByInterface(interval)
+		if nil != err || seconds < 0 {
+			return "eval_interval"
+		}
+		params["eval_interval"] = seconds
+	}
+	return ""
+}
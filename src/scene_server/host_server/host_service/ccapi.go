@@ -16,6 +16,7 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/api"
 	"configcenter/src/common/core/cc/config"
+	"configcenter/src/common/discovery"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/http/httpserver"
 	"configcenter/src/common/rdapi"
@@ -23,7 +24,7 @@ import (
 
 	myCommon "configcenter/src/scene_server/host_server/common"
 	confCenter "configcenter/src/scene_server/host_server/host_service/config"
-	"configcenter/src/scene_server/host_server/host_service/rdiscover"
+	"configcenter/src/scene_server/host_server/host_service/logics"
 
 	"time"
 )
@@ -32,7 +33,7 @@ import (
 type CCAPIServer struct {
 	conf     *config.CCAPIConfig
 	httpServ *httpserver.HttpServer
-	rd       *rdiscover.RegDiscover
+	rd       *discovery.RegDiscover
 	cfCenter *confCenter.ConfCenter
 }
 
@@ -52,10 +53,10 @@ func NewCCAPIServer(conf *config.CCAPIConfig) (*CCAPIServer, error) {
 	a.InitAction()
 
 	//RDiscover
-	s.rd = rdiscover.NewRegDiscover(s.conf.RegDiscover, addr, port, false)
+	s.rd = discovery.NewRegDiscover(s.conf.GetRegDiscover(), types.CC_MODULE_HOST, addr, port, false, discovery.NewOptions(s.conf), types.CC_MODULE_HOSTCONTROLLER, types.CC_MODULE_OBJECTCONTROLLER, types.CC_MODULE_AUDITCONTROLLER)
 
 	//ConfCenter
-	s.cfCenter = confCenter.NewConfCenter(s.conf.RegDiscover)
+	s.cfCenter = confCenter.NewConfCenter(s.conf.GetRegDiscover())
 
 	return s, nil
 }
@@ -148,6 +149,9 @@ func (ccAPI *CCAPIServer) Start() error {
 
 	a.AuditCtrl = rdapi.GetRdAddrSrvHandle(types.CC_MODULE_AUDITCONTROLLER, a.AddrSrv)
 
+	// evaluate the dynamic host groups
+	go logics.StartHostGroupEval(a)
+
 	//start rdiscover
 	go func() {
 		err := ccAPI.rd.Start()
@@ -167,5 +171,7 @@ func (ccAPI *CCAPIServer) Start() error {
 func (ccAPI *CCAPIServer) InitHttpServ() error {
 	a := api.NewAPIResource()
 	ccAPI.httpServ.RegisterWebServer("/host/{version}", rdapi.AllGlobalFilter(), a.Actions)
+	a.RegisterHealth(ccAPI.httpServ)
+	ccAPI.httpServ.RegisterWebServer("/discovery", nil, []*httpserver.Action{ccAPI.rd.SnapshotAction()})
 	return nil
 }
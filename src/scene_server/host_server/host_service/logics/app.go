@@ -32,8 +32,15 @@ func GetAppIDByCond(req *restful.Request, objURL string, cond []interface{}) ([]
 	condition["sort"] = common.BKAppIDField
 	condition["start"] = 0
 	condition["limit"] = 1000000
+	schema, err := GetObjectSchema(req, common.BKInnerObjIDApp, objURL)
+	if nil != err {
+		return appIDArr, err
+	}
 	condc := make(map[string]interface{})
-	appParse.ParseCommonParams(cond, condc)
+	if err := appParse.ParseCommonParams(cond, schema, condc); nil != err {
+		blog.Errorf("parse the condition error:%v, condition:%v", err, cond)
+		return appIDArr, err
+	}
 	condition["condition"] = condc
 	bodyContent, _ := json.Marshal(condition)
 	url := objURL + "/object/v1/insts/" + common.BKInnerObjIDApp + "/search"
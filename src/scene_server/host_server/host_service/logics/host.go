@@ -56,6 +56,9 @@ func HostSearch(req *restful.Request, data hostParse.HostCommonSearch, hostCtrl,
 	body["start"] = start
 	body["limit"] = limit
 	body["sort"] = sort
+	body["cursor"] = data.Page.Cursor
+	body["with_cursor"] = data.Page.WithCursor
+	body["skip_count"] = data.Page.SkipCount
 	for _, object := range data.Condition {
 		if object.ObjectID == common.BKInnerObjIDHost {
 			hostCond = object
@@ -78,8 +81,12 @@ func HostSearch(req *restful.Request, data hostParse.HostCommonSearch, hostCtrl,
 		cond["value"] = data.AppID
 		appCond.Condition = append(appCond.Condition, cond)
 	}
+	var err error
 	if len(appCond.Condition) > 0 {
-		appIDArr, _ = GetAppIDByCond(req, objCtrl, appCond.Condition)
+		appIDArr, err = GetAppIDByCond(req, objCtrl, appCond.Condition)
+		if nil != err {
+			return nil, err
+		}
 	}
 	//search object by cond
 	if len(objectCond.Condition) > 0 {
@@ -101,7 +108,10 @@ func HostSearch(req *restful.Request, data hostParse.HostCommonSearch, hostCtrl,
 			cond["value"] = objSetIDArr
 			setCond.Condition = append(setCond.Condition, cond)
 		}
-		setIDArr, _ = GetSetIDByCond(req, objCtrl, setCond.Condition)
+		setIDArr, err = GetSetIDByCond(req, objCtrl, setCond.Condition)
+		if nil != err {
+			return nil, err
+		}
 	}
 
 	if len(moduleCond.Condition) > 0 {
@@ -120,7 +130,10 @@ func HostSearch(req *restful.Request, data hostParse.HostCommonSearch, hostCtrl,
 			moduleCond.Condition = append(moduleCond.Condition, cond)
 		}
 		//search module by cond
-		moduleIDArr, _ = GetModuleIDByCond(req, objCtrl, moduleCond.Condition)
+		moduleIDArr, err = GetModuleIDByCond(req, objCtrl, moduleCond.Condition)
+		if nil != err {
+			return nil, err
+		}
 	}
 
 	if len(appCond.Condition) > 0 {
@@ -145,9 +158,19 @@ func HostSearch(req *restful.Request, data hostParse.HostCommonSearch, hostCtrl,
 		hostCond.Fields = append(hostCond.Fields, common.BKHostIDField)
 	}
 	body["fields"] = strings.Join(hostCond.Fields, ",")
+	hostSchema, err := GetObjectSchema(req, common.BKInnerObjIDHost, objCtrl)
+	if nil != err {
+		return nil, err
+	}
 	condition := make(map[string]interface{})
-	hostParse.ParseHostParams(hostCond.Condition, condition)
-	hostParse.ParseHostIPParams(data.Ip, condition)
+	if err := hostParse.ParseHostParams(hostCond.Condition, hostSchema, condition); nil != err {
+		blog.Errorf("parse the host condition error:%v, condition:%v", err, hostCond.Condition)
+		return nil, err
+	}
+	if err := hostParse.ParseHostIPParams(data.Ip, condition); nil != err {
+		blog.Errorf("parse the host ip condition error:%v, ip:%v", err, data.Ip)
+		return nil, err
+	}
 	body["condition"] = condition
 	bodyContent, _ := json.Marshal(body)
 	blog.Info("Get Host By Cond url :%s", url)
@@ -169,6 +192,8 @@ func HostSearch(req *restful.Request, data hostParse.HostCommonSearch, hostCtrl,
 		return nil, errors.New(common.CC_Err_Comm_Host_Get_FAIL_STR)
 	}
 
+	nextCursor, hasCursor := hostResult["next_cursor"]
+
 	// deal the host
 	instapi.Inst.InitInstHelper(hostCtrl, objCtrl)
 	hostResult, retStrErr := instapi.Inst.GetInstDetailsSub(req, common.BKInnerObjIDHost, common.BKDefaultOwnerID, hostResult, map[string]interface{}{
@@ -181,9 +206,8 @@ func HostSearch(req *restful.Request, data hostParse.HostCommonSearch, hostCtrl,
 		blog.Error("failed to replace association object, error code is %d", retStrErr)
 	}
 
-	cnt := hostResult["count"]
+	cnt, hasCnt := hostResult["count"]
 	hostInfo := hostResult["info"].([]interface{})
-	result["count"] = cnt
 	resHostIDArr := make([]int, 0)
 	queryCond := make(map[string]interface{})
 	for _, j := range hostInfo {
@@ -314,7 +338,12 @@ func HostSearch(req *restful.Request, data hostParse.HostCommonSearch, hostCtrl,
 	}
 
 	result["info"] = totalInfo
-	result["count"] = cnt
+	if hasCnt {
+		result["count"] = cnt
+	}
+	if hasCursor {
+		result["next_cursor"] = nextCursor
+	}
 
 	return result, err
 }
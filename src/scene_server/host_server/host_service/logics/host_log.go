@@ -457,7 +457,7 @@ func (h *HostModuleConfigLog) SaveLog(appID, user string) error {
 	if "" == h.desc {
 		h.desc = "主机关系变更"
 	}
-	opClient := auditlog.NewClient(h.auditCtrl)
+	opClient := auditlog.NewClient(h.auditCtrl).SetRequestID(util.GetActionRequestID(h.req))
 	_, err = opClient.AuditHostsLog(logs, h.prefix+h.desc+h.suffix, h.ownerID, appID, user, auditoplog.AuditOpTypeModify)
 
 	return err
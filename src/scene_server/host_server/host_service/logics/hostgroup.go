@@ -0,0 +1,208 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package logics
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/confregistry"
+	"configcenter/src/common/core/cc/api"
+	"configcenter/src/common/lifecycle"
+	hostParse "configcenter/src/common/paraparse"
+	"configcenter/src/common/util"
+	userAPISdk "configcenter/src/source_controller/api/userapi"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+)
+
+const (
+	// hostGroupEvalTick how often the dynamic host groups are checked whether they are due
+	hostGroupEvalTick = 30 * time.Second
+	// hostGroupPageSize the hosts searched per page while evaluating the query
+	hostGroupPageSize = 500
+)
+
+// hostGroupEvalInterval the default interval between the evaluations of a dynamic host group, reloaded from hostgroup.eval_interval,
+// the eval_interval seconds of the group overrides it
+var hostGroupEvalInterval = confregistry.Default.Duration("hostgroup.eval_interval", 5*time.Minute, nil)
+
+// hostGroupMember the host matched by the query of the dynamic host group
+type hostGroupMember struct {
+	HostID  int    `json:"bk_host_id"`
+	InnerIP string `json:"bk_host_innerip"`
+}
+
+// hostGroup the saved custom query marked as dynamic
+type hostGroup struct {
+	AppID      int
+	ID         string
+	OwnerID    string
+	User       string
+	Info       string
+	EvalTime   time.Time
+	EvalPeriod time.Duration
+}
+
+// StartHostGroupEval evaluate the dynamic host groups once they are due, and store the members into the host controller
+// which sends the hostgroup join and leave events, it never returns. The event server makes the groups due on the host
+// and moduletransfer events by clearing their eval_time, so the groups are shared by all the host servers
+func StartHostGroupEval(cc *api.APIResource) {
+	worker := lifecycle.NewWorker("host group evaluator")
+	ticker := time.NewTicker(hostGroupEvalTick)
+	defer ticker.Stop()
+	for range ticker.C {
+		evalHostGroups(cc, worker, time.Now())
+	}
+}
+
+// evalHostGroups evaluate the due groups one by one
+func evalHostGroups(cc *api.APIResource, worker *lifecycle.Worker, now time.Time) {
+	client := userAPISdk.NewClient(cc.HostCtrl())
+	code, reply, err := client.SearchDynamic()
+	if nil != err || http.StatusOK != code {
+		blog.Errorf("search dynamic host groups failed, code:%d, error:%v", code, err)
+		return
+	}
+	data, _ := reply.Data.(map[string]interface{})
+	items, _ := data["info"].([]interface{})
+	for _, item := range items {
+		group := parseHostGroup(item)
+		if !hostGroupDue(group, now) {
+			continue
+		}
+		if !worker.Begin() {
+			blog.Info("host group evaluator stopped by shutdown")
+			worker.Park()
+		}
+		members, err := evalHostGroup(cc, group)
+		if nil != err {
+			blog.Errorf("evaluate host group %s of business %d failed, error:%v", group.ID, group.AppID, err)
+			worker.End()
+			continue
+		}
+		input := map[string]interface{}{"members": members}
+		code, reply, err := client.SetMembers(fmt.Sprint(group.AppID), group.ID, input)
+		if nil != err || http.StatusOK != code {
+			blog.Errorf("set host group %s members failed, code:%d, error:%v", group.ID, code, err)
+		} else {
+			blog.Infof("host group %s of business %d evaluated, members:%d, changes:%v", group.ID, group.AppID, len(members), reply.Data)
+		}
+		worker.End()
+	}
+}
+
+// parseHostGroup parse the group returned by the host controller
+func parseHostGroup(item interface{}) hostGroup {
+	data, _ := item.(map[string]interface{})
+	group := hostGroup{}
+	group.AppID, _ = util.GetIntByInterface(data[common.BKAppIDField])
+	group.ID, _ = data["id"].(string)
+	group.OwnerID, _ = data[common.BKOwnerIDField].(string)
+	if "" == group.OwnerID {
+		group.OwnerID = common.BKDefaultOwnerID
+	}
+	group.User, _ = data["create_user"].(string)
+	group.Info, _ = data["info"].(string)
+	if evalTime, ok := data["eval_time"].(string); ok {
+		group.EvalTime, _ = time.Parse(time.RFC3339Nano, evalTime)
+	}
+	if seconds, err := util.GetIntByInterface(data["eval_interval"]); nil == err && seconds > 0 {
+		group.EvalPeriod = time.Duration(seconds) * time.Second
+	}
+	return group
+}
+
+// hostGroupDue check whether the group should be evaluated, it is due if it is never evaluated since
+// its query or hosts changed, or its interval passed since the last evaluation
+func hostGroupDue(group hostGroup, now time.Time) bool {
+	if group.EvalTime.IsZero() {
+		return true
+	}
+	period := group.EvalPeriod
+	if 0 == period {
+		period = hostGroupEvalInterval.Get()
+	}
+	return !now.Before(group.EvalTime.Add(period))
+}
+
+// hostGroupSearch return the saved query of the group, only the members fields of the hosts are output
+func hostGroupSearch(group hostGroup) (hostParse.HostCommonSearch, error) {
+	input := hostParse.HostCommonSearch{}
+	if err := json.Unmarshal([]byte(group.Info), &input); nil != err {
+		return input, err
+	}
+	input.AppID = group.AppID
+	hasHost := false
+	for i := range input.Condition {
+		if common.BKInnerObjIDHost == input.Condition[i].ObjectID {
+			input.Condition[i].Fields = []string{common.BKHostIDField, common.BKHostInnerIPField}
+			hasHost = true
+			continue
+		}
+		input.Condition[i].Fields = nil
+	}
+	if !hasHost {
+		input.Condition = append(input.Condition, hostParse.SearchCondition{
+			ObjectID:  common.BKInnerObjIDHost,
+			Fields:    []string{common.BKHostIDField, common.BKHostInnerIPField},
+			Condition: []interface{}{},
+		})
+	}
+	return input, nil
+}
+
+// evalHostGroup search all the hosts matched by the query of the group
+func evalHostGroup(cc *api.APIResource, group hostGroup) ([]hostGroupMember, error) {
+	input, err := hostGroupSearch(group)
+	if nil != err {
+		return nil, err
+	}
+	members := make([]hostGroupMember, 0)
+	for start := 0; ; start += hostGroupPageSize {
+		input.Page = hostParse.PageInfo{Start: start, Limit: hostGroupPageSize, Sort: common.BKHostIDField, SkipCount: true}
+		result, err := HostSearch(newHostGroupRequest(group), input, cc.HostCtrl(), cc.ObjCtrl())
+		if nil != err {
+			return nil, err
+		}
+		data, _ := result.(map[string]interface{})
+		info, ok := data["info"].([]interface{})
+		if false == ok {
+			return nil, errors.New(common.CC_Err_Comm_Host_Get_FAIL_STR)
+		}
+		for _, item := range info {
+			hostData, _ := item.(map[string]interface{})
+			host, _ := hostData[common.BKInnerObjIDHost].(map[string]interface{})
+			member := hostGroupMember{}
+			member.HostID, _ = util.GetIntByInterface(host[common.BKHostIDField])
+			member.InnerIP, _ = host[common.BKHostInnerIPField].(string)
+			members = append(members, member)
+		}
+		if len(info) < hostGroupPageSize {
+			return members, nil
+		}
+	}
+}
+
+// newHostGroupRequest create the request the query of the group is searched as, it acts as the creator of the group
+func newHostGroupRequest(group hostGroup) *restful.Request {
+	httpReq, _ := http.NewRequest(common.HTTPSelectPost, "/", strings.NewReader(""))
+	httpReq.Header.Set(common.BKHTTPOwnerID, group.OwnerID)
+	httpReq.Header.Set(common.BKHTTPHeaderUser, group.User)
+	return restful.NewRequest(httpReq)
+}
@@ -0,0 +1,166 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package logics
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/core/cc/api"
+	"configcenter/src/common/lifecycle"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHostGroupDue(t *testing.T) {
+	now := time.Now()
+	group := hostGroup{AppID: 2, EvalTime: now.Add(-time.Minute)}
+
+	if hostGroupDue(group, now) {
+		t.Errorf("group evaluated a minute ago should not be due")
+	}
+	if !hostGroupDue(hostGroup{AppID: 2}, now) {
+		t.Errorf("group never evaluated should be due")
+	}
+	group.EvalPeriod = 30 * time.Second
+	if !hostGroupDue(group, now) {
+		t.Errorf("group whose interval passed should be due")
+	}
+
+	// the eval_time cleared by the event server
+	cleared := parseHostGroup(map[string]interface{}{"eval_time": "0001-01-01T00:00:00Z"})
+	if !hostGroupDue(cleared, now) {
+		t.Errorf("group whose eval_time cleared should be due")
+	}
+}
+
+func TestParseHostGroup(t *testing.T) {
+	group := parseHostGroup(map[string]interface{}{
+		common.BKAppIDField: float64(2),
+		"id":                "group",
+		"info":              "{}",
+		"eval_interval":     float64(60),
+		"eval_time":         "2018-05-01T10:00:00.5+08:00",
+	})
+	if 2 != group.AppID || "group" != group.ID || time.Minute != group.EvalPeriod {
+		t.Errorf("group not as expected: %+v", group)
+	}
+	if common.BKDefaultOwnerID != group.OwnerID {
+		t.Errorf("owner should be the default one: %s", group.OwnerID)
+	}
+	if group.EvalTime.IsZero() {
+		t.Errorf("eval time should be parsed")
+	}
+}
+
+func TestHostGroupSearch(t *testing.T) {
+	info := `{"condition":[{"bk_obj_id":"module","condition":[{"field":"bk_module_name","operator":"$eq","value":"web"}],"fields":["bk_module_name"]}]}`
+	input, err := hostGroupSearch(hostGroup{AppID: 2, Info: info})
+	if nil != err {
+		t.Fatalf("error not as expected: %v", err)
+	}
+	if 2 != input.AppID || 2 != len(input.Condition) {
+		t.Fatalf("search not as expected: %+v", input)
+	}
+	if nil != input.Condition[0].Fields || 1 != len(input.Condition[0].Condition) {
+		t.Errorf("module condition not as expected: %+v", input.Condition[0])
+	}
+	host := input.Condition[1]
+	if common.BKInnerObjIDHost != host.ObjectID || !reflect.DeepEqual(host.Fields, []string{common.BKHostIDField, common.BKHostInnerIPField}) {
+		t.Errorf("host condition not as expected: %+v", host)
+	}
+
+	if _, err := hostGroupSearch(hostGroup{Info: "{"}); nil == err {
+		t.Errorf("error should not be nil")
+	}
+}
+
+// hostGroupController stub the host and object controllers the evaluation calls, the group g of the business 2
+// selects the hosts of the module named web, which are the host 1 and 2
+type hostGroupController struct {
+	t          *testing.T
+	hostSearch string
+	members    interface{}
+}
+
+func (c *hostGroupController) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body := make(map[string]interface{})
+	json.NewDecoder(r.Body).Decode(&body)
+	configs := []interface{}{
+		map[string]interface{}{common.BKAppIDField: 2, common.BKSetIDField: 4, common.BKModuleIDField: 3, common.BKHostIDField: 1},
+		map[string]interface{}{common.BKAppIDField: 2, common.BKSetIDField: 4, common.BKModuleIDField: 3, common.BKHostIDField: 2},
+	}
+	var data interface{}
+	switch r.URL.Path {
+	case "/host/v1/userapi/dynamic/search":
+		info := `{"condition":[{"bk_obj_id":"module","condition":[{"field":"bk_module_name","operator":"$eq","value":"web"}]}]}`
+		data = map[string]interface{}{"count": 1, "info": []interface{}{
+			map[string]interface{}{common.BKAppIDField: 2, "id": "g", "info": info, "create_user": "admin"},
+		}}
+	case "/object/v1/meta/objectatts":
+		attrs := []interface{}{}
+		for _, field := range []string{common.BKAppIDField, common.BKSetIDField, common.BKModuleIDField, common.BKHostIDField} {
+			attrs = append(attrs, map[string]interface{}{"bk_property_id": field, "bk_property_type": common.FiledTypeInt})
+		}
+		for _, field := range []string{common.BKModuleNameField, common.BKHostInnerIPField} {
+			attrs = append(attrs, map[string]interface{}{"bk_property_id": field, "bk_property_type": common.FiledTypeSingleChar})
+		}
+		data = attrs
+	case "/object/v1/insts/biz/search":
+		data = map[string]interface{}{"count": 1, "info": []interface{}{map[string]interface{}{common.BKAppIDField: 2}}}
+	case "/object/v1/insts/module/search":
+		data = map[string]interface{}{"count": 1, "info": []interface{}{map[string]interface{}{common.BKModuleIDField: 3}}}
+	case "/host/v1/meta/hosts/module/config/search":
+		data = configs
+	case "/host/v1/hosts/search":
+		condition, _ := json.Marshal(body["condition"])
+		c.hostSearch = string(condition)
+		data = map[string]interface{}{"count": 2, "info": []interface{}{
+			map[string]interface{}{common.BKHostIDField: 1, common.BKHostInnerIPField: "10.0.0.1"},
+			map[string]interface{}{common.BKHostIDField: 2, common.BKHostInnerIPField: "10.0.0.2"},
+		}}
+	case "/host/v1/userapi/members/2/g":
+		c.members = body["members"]
+		data = map[string]interface{}{}
+	default:
+		c.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}
+	reply, _ := json.Marshal(map[string]interface{}{"result": true, "bk_error_code": 0, "bk_error_msg": "", "data": data})
+	w.Write(reply)
+}
+
+func TestEvalHostGroups(t *testing.T) {
+	ctrl := &hostGroupController{t: t}
+	server := httptest.NewServer(ctrl)
+	defer server.Close()
+	cc := &api.APIResource{}
+	cc.HostCtrl = func() string { return server.URL }
+	cc.ObjCtrl = func() string { return server.URL }
+
+	evalHostGroups(cc, lifecycle.NewWorker("host group evaluator test"), time.Now())
+
+	// the hosts of the business are searched by the typed host id slice
+	if !strings.Contains(ctrl.hostSearch, `{"bk_host_id":{"$in":[1,2]}}`) {
+		t.Errorf("host search condition not as expected: %s", ctrl.hostSearch)
+	}
+	expected := []interface{}{
+		map[string]interface{}{common.BKHostIDField: float64(1), common.BKHostInnerIPField: "10.0.0.1"},
+		map[string]interface{}{common.BKHostIDField: float64(2), common.BKHostInnerIPField: "10.0.0.2"},
+	}
+	if !reflect.DeepEqual(ctrl.members, expected) {
+		t.Errorf("members not as expected: %v", ctrl.members)
+	}
+}
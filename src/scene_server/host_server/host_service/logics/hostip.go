@@ -0,0 +1,35 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package logics
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/util"
+)
+
+// NormalizeHostIP normalize the inner and outer ips of the host data to the canonical comma separated ips,
+// return the field of the invalid ips
+func NormalizeHostIP(data map[string]interface{}) (string, error) {
+	for _, field := range []string{common.BKHostInnerIPField, common.BKHostOuterIPField} {
+		ips, ok := data[field].(string)
+		if false == ok || "" == ips {
+			continue
+		}
+		canonical, err := util.NormalizeIPList(ips)
+		if nil != err {
+			return field, err
+		}
+		data[field] = canonical
+	}
+	return "", nil
+}
@@ -31,8 +31,15 @@ func GetModuleIDByCond(req *restful.Request, objURL string, cond []interface{})
 	condition["sort"] = common.BKModuleIDField
 	condition["start"] = 0
 	condition["limit"] = 0
+	schema, err := GetObjectSchema(req, common.BKInnerObjIDModule, objURL)
+	if nil != err {
+		return moduleIDArr, err
+	}
 	condc := make(map[string]interface{})
-	parse.ParseCommonParams(cond, condc)
+	if err := parse.ParseCommonParams(cond, schema, condc); nil != err {
+		blog.Errorf("parse the condition error:%v, condition:%v", err, cond)
+		return moduleIDArr, err
+	}
 	condition["condition"] = condc
 	bodyContent, _ := json.Marshal(condition)
 	url := objURL + "/object/v1/insts/module/search"
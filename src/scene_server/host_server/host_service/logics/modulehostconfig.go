@@ -136,6 +136,11 @@ func AddHost(req *restful.Request, ownerID string, appID int, hostInfos map[int]
 			errMsg = append(errMsg, fmt.Sprintf("%d行内网ip为空", index))
 			continue
 		}
+		if field, err := NormalizeHostIP(host); nil != err {
+			errMsg = append(errMsg, fmt.Sprintf("%d行%s格式错误:%v", index, field, err))
+			continue
+		}
+		innerIP = host[common.BKHostInnerIPField].(string)
 		notExistFields := []string{} //没有赋值的key，不需要校验
 		for key, value := range defaultFields {
 			_, ok := host[key]
@@ -262,6 +267,10 @@ func EnterIP(req *restful.Request, ownerID string, appID, moduleID int, IP, osTy
 	addParams[common.BKModuleIDField] = []int{moduleID}
 	addModulesURL := hostAddr + "/host/v1/meta/hosts/modules/"
 
+	IP, err := util.NormalizeIPList(IP)
+	if nil != err {
+		return err
+	}
 	conds := map[string]interface{}{
 		common.BKHostInnerIPField: IP,
 		common.BKCloudIDField:     common.BKDefaultDirSubArea,
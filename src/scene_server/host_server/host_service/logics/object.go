@@ -17,7 +17,9 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/errors"
 	httpcli "configcenter/src/common/http/httpclient"
+	"configcenter/src/common/querydsl"
 	"configcenter/src/common/util"
+	sourceAPI "configcenter/src/source_controller/api/object"
 	"encoding/json"
 
 	"github.com/emicklei/go-restful"
@@ -34,6 +36,28 @@ type ObjectData struct {
 	Info  []map[string]interface{} `json:"info"`
 }
 
+//GetObjectSchema get the query schema of the object by its attributes
+func GetObjectSchema(req *restful.Request, objID, objURL string) (querydsl.Schema, error) {
+	ownerID := util.GetActionOnwerID(req)
+	if "" == ownerID {
+		ownerID = common.BKDefaultOwnerID
+	}
+	data := make(map[string]interface{})
+	data[common.BKOwnerIDField] = ownerID
+	data[common.BKObjIDField] = objID
+	info, _ := json.Marshal(data)
+	attrs, err := sourceAPI.NewClient(objURL).SearchMetaObjectAtt([]byte(info))
+	if nil != err {
+		blog.Errorf("get object %s attributes error:%v", objID, err)
+		return nil, err
+	}
+	schema := querydsl.NewInstSchema()
+	for _, attr := range attrs {
+		schema.Add(attr.PropertyID, attr.PropertyType)
+	}
+	return schema, nil
+}
+
 //GetSetIDByObjectCond get set id by object condition
 func GetSetIDByObjectCond(req *restful.Request, objURL string, objectCond []interface{}) []int {
 	objectIDArr := make([]int, 0)
@@ -31,8 +31,15 @@ func GetSetIDByCond(req *restful.Request, objURL string, cond []interface{}) ([]
 	condition["sort"] = common.BKSetIDField
 	condition["start"] = 0
 	condition["limit"] = 0
+	schema, err := GetObjectSchema(req, common.BKInnerObjIDSet, objURL)
+	if nil != err {
+		return setIDArr, err
+	}
 	condc := make(map[string]interface{})
-	parse.ParseCommonParams(cond, condc)
+	if err := parse.ParseCommonParams(cond, schema, condc); nil != err {
+		blog.Errorf("parse the condition error:%v, condition:%v", err, cond)
+		return setIDArr, err
+	}
 	condition["condition"] = condc
 	bodyContent, _ := json.Marshal(condition)
 	url := objURL + "/object/v1/insts/set/search"
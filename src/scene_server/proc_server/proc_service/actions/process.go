@@ -139,7 +139,7 @@ func (cli *procAction) UpdateProcess(req *restful.Request, resp *restful.Respons
 				PreData: preData,
 				Headers: headers,
 			}
-			auditlog.NewClient(cli.CC.AuditCtrl()).AuditProcLog(procID, auditContent, "update process", ownerID, fmt.Sprint(appID), user, auditoplog.AuditOpTypeModify)
+			auditlog.NewClient(cli.CC.AuditCtrl()).SetRequestID(util.GetActionRequestID(req)).AuditProcLog(procID, auditContent, "update process", ownerID, fmt.Sprint(appID), user, auditoplog.AuditOpTypeModify)
 		}
 
 		json, err := simplejson.NewJson([]byte(sProcRes))
@@ -266,7 +266,7 @@ func (cli *procAction) DeleteProcess(req *restful.Request, resp *restful.Respons
 				PreData: preData,
 				Headers: headers,
 			}
-			auditlog.NewClient(cli.CC.AuditCtrl()).AuditProcLog(procID, auditContent, "delete process", ownerID, fmt.Sprint(appID), user, auditoplog.AuditOpTypeDel)
+			auditlog.NewClient(cli.CC.AuditCtrl()).SetRequestID(util.GetActionRequestID(req)).AuditProcLog(procID, auditContent, "delete process", ownerID, fmt.Sprint(appID), user, auditoplog.AuditOpTypeDel)
 		}
 
 		return http.StatusOK, nil, nil
@@ -341,7 +341,7 @@ func (cli *procAction) CreateProcess(req *restful.Request, resp *restful.Respons
 				CurData: curData,
 				Headers: headers,
 			}
-			auditlog.NewClient(cli.CC.AuditCtrl()).AuditProcLog(instID, auditContent, "create process", ownerID, fmt.Sprint(appID), user, auditoplog.AuditOpTypeAdd)
+			auditlog.NewClient(cli.CC.AuditCtrl()).SetRequestID(util.GetActionRequestID(req)).AuditProcLog(instID, auditContent, "create process", ownerID, fmt.Sprint(appID), user, auditoplog.AuditOpTypeAdd)
 		}
 
 		result := make(map[string]interface{})
@@ -16,12 +16,12 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/api"
 	"configcenter/src/common/core/cc/config"
+	"configcenter/src/common/discovery"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/http/httpserver"
 	"configcenter/src/common/rdapi"
 	"configcenter/src/common/types"
 	confCenter "configcenter/src/scene_server/proc_server/proc_service/config"
-	"configcenter/src/scene_server/proc_server/proc_service/rdiscover"
 	"time"
 )
 
@@ -29,7 +29,7 @@ import (
 type CCAPIServer struct {
 	conf     *config.CCAPIConfig
 	httpServ *httpserver.HttpServer
-	rd       *rdiscover.RegDiscover
+	rd       *discovery.RegDiscover
 	cfCenter *confCenter.ConfCenter
 }
 
@@ -48,10 +48,10 @@ func NewCCAPIServer(conf *config.CCAPIConfig) (*CCAPIServer, error) {
 	a.SetConfig(s.conf)
 	a.InitAction()
 
-	s.rd = rdiscover.NewRegDiscover(s.conf.RegDiscover, addr, port, false)
+	s.rd = discovery.NewRegDiscover(s.conf.GetRegDiscover(), types.CC_MODULE_PROC, addr, port, false, discovery.NewOptions(s.conf), types.CC_MODULE_HOSTCONTROLLER, types.CC_MODULE_OBJECTCONTROLLER, types.CC_MODULE_AUDITCONTROLLER, types.CC_MODULE_PROCCONTROLLER)
 
 	//ConfCenter
-	s.cfCenter = confCenter.NewConfCenter(s.conf.RegDiscover)
+	s.cfCenter = confCenter.NewConfCenter(s.conf.GetRegDiscover())
 
 	return s, nil
 }
@@ -158,6 +158,8 @@ func (ccAPI *CCAPIServer) initHttpServ() error {
 	a := api.NewAPIResource()
 
 	ccAPI.httpServ.RegisterWebServer("/process/{version}", rdapi.AllGlobalFilter(), a.Actions)
+	a.RegisterHealth(ccAPI.httpServ)
+	ccAPI.httpServ.RegisterWebServer("/discovery", nil, []*httpserver.Action{ccAPI.rd.SnapshotAction()})
 
 	return nil
 }
@@ -20,6 +20,7 @@ import (
 	"configcenter/src/common/core/cc/actions"
 	httpcli "configcenter/src/common/http/httpclient"
 	"configcenter/src/common/paraparse"
+	"configcenter/src/common/querydsl"
 	"configcenter/src/common/util"
 	sencecommon "configcenter/src/scene_server/common"
 	"configcenter/src/scene_server/validator"
@@ -133,7 +134,7 @@ func (cli *appAction) DeleteApp(req *restful.Request, resp *restful.Response) {
 				PreData: preData,
 				Headers: headers,
 			}
-			auditlog.NewClient(cli.CC.AuditCtrl()).AuditObjLog(instID, auditContent, "delete app", common.BKInnerObjIDApp, ownerID, "0", user, auditoplog.AuditOpTypeModify)
+			auditlog.NewClient(cli.CC.AuditCtrl()).SetRequestID(util.GetActionRequestID(req)).AuditObjLog(instID, auditContent, "delete app", common.BKInnerObjIDApp, ownerID, "0", user, auditoplog.AuditOpTypeModify)
 		}
 		//delete set in app
 		setInput := make(map[string]interface{})
@@ -247,7 +248,7 @@ func (cli *appAction) UpdateApp(req *restful.Request, resp *restful.Response) {
 				CurData: curData,
 				Headers: headers,
 			}
-			auditlog.NewClient(cli.CC.AuditCtrl()).AuditObjLog(instID, auditContent, "update app", common.BKInnerObjIDApp, ownerID, "0", user, auditoplog.AuditOpTypeModify)
+			auditlog.NewClient(cli.CC.AuditCtrl()).SetRequestID(util.GetActionRequestID(req)).AuditObjLog(instID, auditContent, "update app", common.BKInnerObjIDApp, ownerID, "0", user, auditoplog.AuditOpTypeModify)
 		}
 
 		return http.StatusOK, nil, nil
@@ -285,11 +286,25 @@ func (cli *appAction) SearchApp(req *restful.Request, resp *restful.Response) {
 		if nil != err {
 			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrCommJSONUnmarshalFailed)
 		}
+		schema, err := inst.getObjSchema(ownerID, common.BKInnerObjIDApp)
+		if nil != err {
+			blog.Errorf("get app schema error: %v", err)
+			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrTopoAppSearchFailed)
+		}
+		// the native condition is matched exactly, the operators are validated as well
 		var condition map[string]interface{}
 		if 1 == js.Native {
-			condition = js.Condition
+			var expr *querydsl.Expr
+			expr, err = querydsl.ParseMap(js.Condition, false)
+			if nil == err {
+				condition, err = querydsl.Compile(expr, schema)
+			}
 		} else {
-			condition = params.ParseAppSearchParams(js.Condition)
+			condition, err = params.ParseAppSearchParams(js.Condition, schema)
+		}
+		if nil != err {
+			blog.Errorf("search app condition is invalid: %v", err)
+			return http.StatusBadRequest, nil, defErr.Errorf(common.CCErrCommParamsInvalid, err.Error())
 		}
 
 		condition[common.BKOwnerIDField] = ownerID
@@ -380,7 +395,7 @@ func (cli *appAction) CreateApp(req *restful.Request, resp *restful.Response) {
 				CurData: curData,
 				Headers: headers,
 			}
-			auditlog.NewClient(cli.CC.AuditCtrl()).AuditObjLog(instID, auditContent, "create app", common.BKInnerObjIDApp, ownerID, "0", user, auditoplog.AuditOpTypeAdd)
+			auditlog.NewClient(cli.CC.AuditCtrl()).SetRequestID(util.GetActionRequestID(req)).AuditObjLog(instID, auditContent, "create app", common.BKInnerObjIDApp, ownerID, "0", user, auditoplog.AuditOpTypeAdd)
 		}
 		//create default set
 		inputSetInfo := make(map[string]interface{})
@@ -17,10 +17,14 @@ import (
 	"configcenter/src/common/bkbase"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/actions"
+	"configcenter/src/common/errors"
+	httpcli "configcenter/src/common/http/httpclient"
+	"configcenter/src/common/querydsl"
 	"configcenter/src/common/util"
 	auditlogAPI "configcenter/src/source_controller/api/auditlog"
 	"configcenter/src/source_controller/common/commondata"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 
@@ -40,6 +44,7 @@ func init() {
 
 	// register action
 	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/audit/search", Params: nil, Handler: audit.Query})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/audit/export", Params: nil, Handler: audit.Export})
 
 	// create cc
 	audit.CreateAction()
@@ -66,37 +71,8 @@ func (cli *auditAction) Query(req *restful.Request, resp *restful.Response) {
 			blog.Error("get audit input:%v error:%v", value, err)
 			return http.StatusBadRequest, nil, defErr.Error(common.CCErrCommJSONUnmarshalFailed)
 		}
-		//user := sencecommon.GetUserFromHeader(req)
-		ownerID := common.BKDefaultOwnerID
-		iConds := dat.Condition
-		if nil == iConds {
-			dat.Condition = common.KvMap{common.BKOwnerIDField: ownerID}
-		} else {
-			conds := iConds.(map[string]interface{})
-			OpType, ok := conds[common.BKOpTypeField]
-			if ok {
-				strOpType, _ := OpType.(string)
-				switch strOpType {
-				case "add":
-					conds[common.BKOpTypeField] = auditoplog.AuditOpTypeAdd
-				case "update":
-					conds[common.BKOpTypeField] = auditoplog.AuditOpTypeModify
-				case "delete":
-					conds[common.BKOpTypeField] = auditoplog.AuditOpTypeDel
-				}
-			}
-			times, ok := conds[common.BKOpTimeField].([]interface{})
-			if ok {
-				if 2 != len(times) {
-					blog.Error("search operation log input params times error, info: %v", times)
-					return http.StatusInternalServerError, nil, defErr.Error(common.CCErrCommParamsInvalid)
-				}
-
-				conds[common.BKOpTimeField] = common.KvMap{"$gte": times[0], "$lte": times[1], commondata.CC_time_type_parse_flag: "1"}
-				//delete(conds, "Time")
-			}
-			conds[common.BKOwnerIDField] = ownerID
-			dat.Condition = conds
+		if httpcode, err := convAuditCondition(&dat, defErr); nil != err {
+			return httpcode, nil, err
 		}
 		if 0 == dat.Limit {
 			dat.Limit = common.BKDefaultLimit
@@ -115,3 +91,133 @@ func (cli *auditAction) Query(req *restful.Request, resp *restful.Response) {
 	}, resp)
 
 }
+
+// Export export the auditlog as jsonl or csv
+func (cli *auditAction) Export(req *restful.Request, resp *restful.Response) {
+
+	// get language
+	language := util.GetActionLanguage(req)
+
+	// get the error object by the language
+	defErr := cli.CC.Error.CreateDefaultCCErrorIf(language)
+
+	value, err := ioutil.ReadAll(req.Request.Body)
+	if err != nil {
+		cli.ResponseFailed(common.CCErrCommHTTPReadBodyFailed, defErr.Error(common.CCErrCommHTTPReadBodyFailed).Error(), resp)
+		return
+	}
+	var dat commondata.ObjQueryInput
+	if 0 != len(value) {
+		if err := json.Unmarshal([]byte(value), &dat); err != nil {
+			blog.Error("get audit input:%v error:%v", value, err)
+			cli.ResponseFailed(common.CCErrCommJSONUnmarshalFailed, defErr.Error(common.CCErrCommJSONUnmarshalFailed).Error(), resp)
+			return
+		}
+	}
+	if _, err := convAuditCondition(&dat, defErr); nil != err {
+		cli.ResponseFailed(common.CCErrCommParamsInvalid, err.Error(), resp)
+		return
+	}
+
+	body, _ := json.Marshal(dat)
+	url := cli.CC.AuditCtrl() + "/audit/v1/export?format=" + req.QueryParameter("format")
+	if err := httpcli.ReqStreamHttp(req, resp, url, common.HTTPSelectPost, body); nil != err {
+		blog.Error("export operation log error: %v", err)
+	}
+}
+
+// convAuditCondition convert the operation log query condition of the user to the typed filter,
+// the changes condition is built here as it matches the elements of the changes array
+func convAuditCondition(dat *commondata.ObjQueryInput, defErr errors.DefaultCCErrorIf) (int, error) {
+	//user := sencecommon.GetUserFromHeader(req)
+	ownerID := common.BKDefaultOwnerID
+	condition := common.KvMap{common.BKOwnerIDField: ownerID}
+	exprs := make([]*querydsl.Expr, 0)
+	if nil != dat.Filter {
+		exprs = append(exprs, dat.Filter)
+	}
+	if nil != dat.Condition {
+		conds, ok := dat.Condition.(map[string]interface{})
+		if !ok {
+			blog.Error("search operation log input params condition error, info: %v", dat.Condition)
+			return http.StatusBadRequest, defErr.Errorf(common.CCErrCommParamsInvalid, "condition")
+		}
+		delete(conds, common.BKOwnerIDField)
+		if opType, ok := conds[common.BKOpTypeField]; ok {
+			strOpType, _ := opType.(string)
+			switch strOpType {
+			case "add":
+				opType = auditoplog.AuditOpTypeAdd
+			case "update":
+				opType = auditoplog.AuditOpTypeModify
+			case "delete":
+				opType = auditoplog.AuditOpTypeDel
+			}
+			exprs = append(exprs, querydsl.NewExpr(querydsl.OpEq, common.BKOpTypeField, opType))
+			delete(conds, common.BKOpTypeField)
+		}
+		if opTime, ok := conds[common.BKOpTimeField]; ok {
+			times, ok := opTime.([]interface{})
+			if !ok || 2 != len(times) {
+				blog.Error("search operation log input params times error, info: %v", opTime)
+				return http.StatusBadRequest, defErr.Errorf(common.CCErrCommParamsInvalid, common.BKOpTimeField)
+			}
+			exprs = append(exprs, querydsl.NewExpr(querydsl.OpRange, common.BKOpTimeField, times))
+			delete(conds, common.BKOpTimeField)
+		}
+		if changes, ok := conds[common.BKOpChangesField]; ok {
+			changeCond, err := getChangeCondition(changes)
+			if nil != err {
+				blog.Error("search operation log input params changes error, info: %v, error: %v", changes, err)
+				return http.StatusBadRequest, defErr.Errorf(common.CCErrCommParamsInvalid, common.BKOpChangesField)
+			}
+			condition[common.BKOpChangesField] = changeCond
+			delete(conds, common.BKOpChangesField)
+		}
+		expr, err := querydsl.ParseMap(conds, false)
+		if nil != err {
+			blog.Error("search operation log input params condition error, info: %v, error: %v", conds, err)
+			return http.StatusBadRequest, defErr.Errorf(common.CCErrCommParamsInvalid, err.Error())
+		}
+		exprs = append(exprs, expr.Exprs...)
+	}
+	dat.Condition = condition
+	dat.Filter = nil
+	if 0 != len(exprs) {
+		dat.Filter = querydsl.And(exprs...)
+	}
+	return http.StatusOK, nil
+}
+
+// getChangeCondition convert the changes condition to match the changed property of the operation log,
+// the input is like {"bk_property_id":"bk_host_name", "value":"xx"}, value matches the pre value or the cur value,
+// pre_value and cur_value are also supported to match the exact side
+func getChangeCondition(changes interface{}) (common.KvMap, error) {
+	input, ok := changes.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("changes should be an object")
+	}
+	propertyID, ok := input[common.BKPropertyIDField].(string)
+	if !ok || "" == propertyID {
+		return nil, fmt.Errorf("%s is required", common.BKPropertyIDField)
+	}
+
+	for _, key := range []string{"value", "pre_value", "cur_value"} {
+		switch input[key].(type) {
+		case map[string]interface{}, []interface{}:
+			return nil, fmt.Errorf("%s should be a scalar value", key)
+		}
+	}
+
+	elem := common.KvMap{common.BKPropertyIDField: propertyID}
+	if value, ok := input["value"]; ok {
+		elem[common.BKDBOR] = []common.KvMap{{"pre_value": value}, {"cur_value": value}}
+	}
+	if value, ok := input["pre_value"]; ok {
+		elem["pre_value"] = value
+	}
+	if value, ok := input["cur_value"]; ok {
+		elem["cur_value"] = value
+	}
+	return common.KvMap{"$elemMatch": elem}, nil
+}
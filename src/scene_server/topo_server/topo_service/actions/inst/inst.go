@@ -21,6 +21,7 @@ import (
 	"configcenter/src/common/errors"
 	httpcli "configcenter/src/common/http/httpclient"
 	"configcenter/src/common/paraparse"
+	"configcenter/src/common/querydsl"
 	"configcenter/src/common/util"
 	"configcenter/src/scene_server/topo_server/topo_service/actions/object"
 	"configcenter/src/scene_server/topo_server/topo_service/manager"
@@ -252,9 +253,9 @@ func (cli *instAction) subCreateInst(req *restful.Request, defErr errors.Default
 			Headers: headers,
 		}
 		if targetMethod == common.HTTPSelectPost {
-			auditlog.NewClient(cli.CC.AuditCtrl()).AuditObjLog(instID, auditContent, "create inst", objID, ownerID, "0", user, auditoplog.AuditOpTypeAdd)
+			auditlog.NewClient(cli.CC.AuditCtrl()).SetRequestID(util.GetActionRequestID(req)).AuditObjLog(instID, auditContent, "create inst", objID, ownerID, "0", user, auditoplog.AuditOpTypeAdd)
 		} else {
-			auditlog.NewClient(cli.CC.AuditCtrl()).AuditObjLog(instID, auditContent, "update inst", objID, ownerID, "0", user, auditoplog.AuditOpTypeModify)
+			auditlog.NewClient(cli.CC.AuditCtrl()).SetRequestID(util.GetActionRequestID(req)).AuditObjLog(instID, auditContent, "update inst", objID, ownerID, "0", user, auditoplog.AuditOpTypeModify)
 		}
 
 	}
@@ -531,7 +532,7 @@ func (cli *instAction) DeleteInst(req *restful.Request, resp *restful.Response)
 					PreData: preData,
 					Headers: attDesCache[delItem.objID],
 				}
-				auditlog.NewClient(cli.CC.AuditCtrl()).AuditObjLog(delItem.instID, auditContent, "delete inst", delItem.objID, ownerID, "0", user, auditoplog.AuditOpTypeDel)
+				auditlog.NewClient(cli.CC.AuditCtrl()).SetRequestID(util.GetActionRequestID(req)).AuditObjLog(delItem.instID, auditContent, "delete inst", delItem.objID, ownerID, "0", user, auditoplog.AuditOpTypeDel)
 			}
 
 		}
@@ -637,7 +638,7 @@ func (cli *instAction) UpdateInst(req *restful.Request, resp *restful.Response)
 				CurData: curData,
 				Headers: headers,
 			}
-			auditlog.NewClient(cli.CC.AuditCtrl()).AuditObjLog(instID, auditContent, "update inst", objID, ownerID, "0", user, auditoplog.AuditOpTypeModify)
+			auditlog.NewClient(cli.CC.AuditCtrl()).SetRequestID(util.GetActionRequestID(req)).AuditObjLog(instID, auditContent, "update inst", objID, ownerID, "0", user, auditoplog.AuditOpTypeModify)
 		}
 
 		return http.StatusOK, objRes, nil
@@ -675,6 +676,19 @@ func (cli *instAction) getObjAttDes(ownerID, objID string) ([]api.ObjAttDes, int
 	return attDes, 0
 }
 
+// getObjSchema return the queryable fields of the object with their property types
+func (cli *instAction) getObjSchema(ownerID, objID string) (querydsl.Schema, error) {
+	attDes, errCode := cli.getObjAttDes(ownerID, objID)
+	if 0 != errCode {
+		return nil, fmt.Errorf("failed to read the attributes of the object %s", objID)
+	}
+	schema := querydsl.NewInstSchema()
+	for _, item := range attDes {
+		schema.Add(item.PropertyID, item.PropertyType)
+	}
+	return schema, nil
+}
+
 // getObjectAsst read association objectid the return key is engilish property name, value is the objectid
 func (cli *instAction) getObjectAsst(objID, ownerID string) (map[string]string, int) {
 
@@ -1295,7 +1309,16 @@ func (cli *instAction) SelectInst(req *restful.Request, resp *restful.Response)
 			return http.StatusBadRequest, "", defErr.Error(common.CCErrCommJSONUnmarshalFailed)
 		}
 
-		condition := params.ParseAppSearchParams(js.Condition)
+		schema, schemaErr := cli.getObjSchema(ownerID, objID)
+		if nil != schemaErr {
+			blog.Errorf("failed to get the schema of the object %s, error is %v", objID, schemaErr)
+			return http.StatusInternalServerError, "", defErr.Error(common.CCErrTopoInstSelectFailed)
+		}
+		condition, parseErr := params.ParseAppSearchParams(js.Condition, schema)
+		if nil != parseErr {
+			blog.Errorf("the condition is invalid, error is %v", parseErr)
+			return http.StatusBadRequest, "", defErr.Errorf(common.CCErrCommParamsInvalid, parseErr.Error())
+		}
 
 		condition[common.BKOwnerIDField] = ownerID
 		condition[common.BKObjIDField] = objID
@@ -1440,7 +1463,16 @@ func (cli *instAction) SelectInsts(req *restful.Request, resp *restful.Response)
 				return http.StatusBadRequest, "", defErr.Error(common.CCErrCommJSONUnmarshalFailed)
 			}
 
-			condition := params.ParseAppSearchParams(js.Condition)
+			schema, schemaErr := cli.getObjSchema(ownerID, objID)
+			if nil != schemaErr {
+				blog.Errorf("failed to get the schema of the object %s, error is %v", objID, schemaErr)
+				return http.StatusInternalServerError, "", defErr.Error(common.CCErrTopoInstSelectFailed)
+			}
+			condition, parseErr := params.ParseAppSearchParams(js.Condition, schema)
+			if nil != parseErr {
+				blog.Errorf("the condition is invalid, error is %v", parseErr)
+				return http.StatusBadRequest, "", defErr.Errorf(common.CCErrCommParamsInvalid, parseErr.Error())
+			}
 
 			// convert the association field
 			/*
@@ -1460,6 +1492,9 @@ func (cli *instAction) SelectInsts(req *restful.Request, resp *restful.Response)
 			searchParams["start"] = page["start"]
 			searchParams["limit"] = page["limit"]
 			searchParams["sort"] = page["sort"]
+			searchParams["cursor"] = page["cursor"]
+			searchParams["with_cursor"] = page["with_cursor"]
+			searchParams["skip_count"] = page["skip_count"]
 
 		} else {
 			condition := make(map[string]interface{}, 0)
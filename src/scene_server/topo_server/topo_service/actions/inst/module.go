@@ -173,7 +173,7 @@ func (cli *moduleAction) CreateModule(req *restful.Request, resp *restful.Respon
 				CurData: curData,
 				Headers: headers,
 			}
-			auditlog.NewClient(cli.CC.AuditCtrl()).AuditModuleLog(instID, auditContent, "create module", ownerID, fmt.Sprint(appID), user, auditoplog.AuditOpTypeAdd)
+			auditlog.NewClient(cli.CC.AuditCtrl()).SetRequestID(util.GetActionRequestID(req)).AuditModuleLog(instID, auditContent, "create module", ownerID, fmt.Sprint(appID), user, auditoplog.AuditOpTypeAdd)
 		}
 		return http.StatusOK, moduleRes, nil
 	}, resp)
@@ -271,7 +271,7 @@ func (cli *moduleAction) DeleteModule(req *restful.Request, resp *restful.Respon
 				PreData: preData,
 				Headers: headers,
 			}
-			auditlog.NewClient(cli.CC.AuditCtrl()).AuditModuleLog(instID, auditContent, "delete module", ownerID, fmt.Sprint(appID), user, auditoplog.AuditOpTypeDel)
+			auditlog.NewClient(cli.CC.AuditCtrl()).SetRequestID(util.GetActionRequestID(req)).AuditModuleLog(instID, auditContent, "delete module", ownerID, fmt.Sprint(appID), user, auditoplog.AuditOpTypeDel)
 		}
 		return http.StatusOK, moduleRes, nil
 
@@ -390,7 +390,7 @@ func (cli *moduleAction) UpdateModule(req *restful.Request, resp *restful.Respon
 				CurData: curData,
 				Headers: headers,
 			}
-			auditlog.NewClient(cli.CC.AuditCtrl()).AuditModuleLog(instID, auditContent, "update module", ownerID, fmt.Sprint(appID), user, auditoplog.AuditOpTypeModify)
+			auditlog.NewClient(cli.CC.AuditCtrl()).SetRequestID(util.GetActionRequestID(req)).AuditModuleLog(instID, auditContent, "update module", ownerID, fmt.Sprint(appID), user, auditoplog.AuditOpTypeModify)
 		}
 
 		return http.StatusOK, moduleRes, nil
@@ -438,7 +438,16 @@ func (cli *moduleAction) SearchModule(req *restful.Request, resp *restful.Respon
 			return http.StatusInternalServerError, "", defErr.Error(common.CCErrCommJSONUnmarshalFailed)
 		}
 
-		condition := params.ParseAppSearchParams(js.Condition)
+		schema, schemaErr := inst.getObjSchema(ownerID, common.BKInnerObjIDModule)
+		if nil != schemaErr {
+			blog.Errorf("failed to get the schema, error info is %v", schemaErr)
+			return http.StatusInternalServerError, "", defErr.Error(common.CCErrTopoModuleSelectFailed)
+		}
+		condition, parseErr := params.ParseAppSearchParams(js.Condition, schema)
+		if nil != parseErr {
+			blog.Errorf("the condition is invalid, error info is %v", parseErr)
+			return http.StatusBadRequest, "", defErr.Errorf(common.CCErrCommParamsInvalid, parseErr.Error())
+		}
 
 		condition[common.BKAppIDField] = appID
 		condition[common.BKSetIDField] = setID
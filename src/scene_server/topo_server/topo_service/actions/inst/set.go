@@ -162,7 +162,7 @@ func (cli *setAction) CreateSet(req *restful.Request, resp *restful.Response) {
 				CurData: curData,
 				Headers: headers,
 			}
-			auditlog.NewClient(cli.CC.AuditCtrl()).AuditSetLog(instID, auditContent, "create set", ownerID, fmt.Sprint(appID), user, auditoplog.AuditOpTypeAdd)
+			auditlog.NewClient(cli.CC.AuditCtrl()).SetRequestID(util.GetActionRequestID(req)).AuditSetLog(instID, auditContent, "create set", ownerID, fmt.Sprint(appID), user, auditoplog.AuditOpTypeAdd)
 		}
 
 		return http.StatusOK, moduleRes, nil
@@ -272,7 +272,7 @@ func (cli *setAction) DeleteSet(req *restful.Request, resp *restful.Response) {
 				PreData: preData,
 				Headers: headers,
 			}
-			auditlog.NewClient(cli.CC.AuditCtrl()).AuditSetLog(instID, auditContent, "delete set", ownerID, fmt.Sprint(appID), user, auditoplog.AuditOpTypeDel)
+			auditlog.NewClient(cli.CC.AuditCtrl()).SetRequestID(util.GetActionRequestID(req)).AuditSetLog(instID, auditContent, "delete set", ownerID, fmt.Sprint(appID), user, auditoplog.AuditOpTypeDel)
 		}
 		return http.StatusOK, moduleRes, nil
 	}, resp)
@@ -383,7 +383,7 @@ func (cli *setAction) UpdateSet(req *restful.Request, resp *restful.Response) {
 				CurData: curData,
 				Headers: headers,
 			}
-			auditlog.NewClient(cli.CC.AuditCtrl()).AuditSetLog(instID, auditContent, "update set", ownerID, fmt.Sprint(appID), user, auditoplog.AuditOpTypeModify)
+			auditlog.NewClient(cli.CC.AuditCtrl()).SetRequestID(util.GetActionRequestID(req)).AuditSetLog(instID, auditContent, "update set", ownerID, fmt.Sprint(appID), user, auditoplog.AuditOpTypeModify)
 		}
 		return http.StatusOK, moduleRes, nil
 	}, resp)
@@ -422,7 +422,16 @@ func (cli *setAction) SearchSet(req *restful.Request, resp *restful.Response) {
 			return http.StatusBadRequest, "", defErr.Error(common.CCErrCommJSONUnmarshalFailed)
 		}
 
-		condition := params.ParseAppSearchParams(js.Condition)
+		schema, schemaErr := inst.getObjSchema(ownerID, common.BKInnerObjIDSet)
+		if nil != schemaErr {
+			blog.Errorf("failed to get the schema, error info is %v", schemaErr)
+			return http.StatusInternalServerError, "", defErr.Error(common.CCErrTopoSetSelectFailed)
+		}
+		condition, parseErr := params.ParseAppSearchParams(js.Condition, schema)
+		if nil != parseErr {
+			blog.Errorf("the condition is invalid, error info is %v", parseErr)
+			return http.StatusBadRequest, "", defErr.Errorf(common.CCErrCommParamsInvalid, parseErr.Error())
+		}
 
 		condition[common.BKAppIDField] = appID
 
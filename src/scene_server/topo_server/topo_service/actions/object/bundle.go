@@ -0,0 +1,160 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package object
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/bkbase"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/core/cc/actions"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/topo_server/topo_service/logics/bundle"
+	"configcenter/src/scene_server/topo_server/topo_service/manager"
+
+	restful "github.com/emicklei/go-restful"
+)
+
+var bundleAct = &bundleAction{}
+
+// bundleAction export and import the business bundle
+type bundleAction struct {
+	base.BaseAction
+	mgr manager.Manager
+}
+
+// bundleImportParams the body of the bundle import
+type bundleImportParams struct {
+	Bundle *bundle.Bundle `json:"bundle"`
+	AppID  int            `json:"bk_biz_id"`
+	Policy string         `json:"policy"`
+	DryRun bool           `json:"dry_run"`
+}
+
+func init() {
+
+	// register action
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/bundle/{owner_id}/{app_id}", Params: nil, Handler: bundleAct.ExportBundle})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/bundle/{owner_id}/import", Params: nil, Handler: bundleAct.ImportBundle})
+
+	// create cc object
+	bundleAct.CreateAction()
+	// set manager
+	manager.SetManager(bundleAct)
+}
+
+// SetManager implement the manager's Hooker interface
+func (cli *bundleAction) SetManager(mgr manager.Manager) error {
+	cli.mgr = mgr
+	return nil
+}
+
+func (cli *bundleAction) newStore(req *restful.Request, defErr errors.DefaultCCErrorIf) *bundleStore {
+	return &bundleStore{cli: cli, req: req, defErr: defErr}
+}
+
+// ExportBundle export the business with its topo, processes and custom attributes
+func (cli *bundleAction) ExportBundle(req *restful.Request, resp *restful.Response) {
+
+	blog.Info("export business bundle")
+	// get the language
+	language := util.GetActionLanguage(req)
+
+	// get the default error by the language
+	defErr := cli.CC.Error.CreateDefaultCCErrorIf(language)
+	cli.CallResponseEx(func() (int, interface{}, error) {
+
+		ownerID := req.PathParameter("owner_id")
+		appID, convErr := strconv.Atoi(req.PathParameter("app_id"))
+		if nil != convErr || 0 == appID {
+			blog.Error("the app id (%s) is invalid", req.PathParameter("app_id"))
+			return http.StatusBadRequest, nil, defErr.Errorf(common.CCErrCommParamsNeedInt, "app_id")
+		}
+
+		items, err := topo.SelectInstTopo(ownerID, common.BKInnerObjIDApp, appID, 0, 0, req)
+		if nil != err {
+			blog.Error("failed to select the topo of the business %d, error info is %s", appID, err.Error())
+			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrTopoTopoSelectFailed)
+		}
+
+		result, err := bundle.Export(cli.newStore(req, defErr), ownerID, appID, items)
+		if nil != err {
+			blog.Error("failed to export the business %d, error info is %s", appID, err.Error())
+			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrTopoBundleExportFailed)
+		}
+		return http.StatusOK, result, nil
+	}, resp)
+}
+
+// ImportBundle recreate or merge the bundle into the business of the owner,
+// the result is replied with the error too, it tells the conflicts or the data imported before the error
+func (cli *bundleAction) ImportBundle(req *restful.Request, resp *restful.Response) {
+
+	blog.Info("import business bundle")
+	// get the language
+	language := util.GetActionLanguage(req)
+
+	// get the default error by the language
+	defErr := cli.CC.Error.CreateDefaultCCErrorIf(language)
+	httpcode, result, err := cli.importBundle(req, defErr)
+	if ccErr, ok := err.(errors.CCErrorCoder); ok && nil != result {
+		cli.ResponseFailedWithData(ccErr.GetCode(), ccErr.Error(), result, resp)
+		return
+	}
+	cli.CallResponseEx(func() (int, interface{}, error) {
+		return httpcode, result, err
+	}, resp)
+}
+
+func (cli *bundleAction) importBundle(req *restful.Request, defErr errors.DefaultCCErrorIf) (int, *bundle.Result, error) {
+
+	val, err := ioutil.ReadAll(req.Request.Body)
+	if nil != err {
+		blog.Error("read request body failed, error information is %s", err.Error())
+		return http.StatusBadRequest, nil, defErr.Error(common.CCErrCommHTTPReadBodyFailed)
+	}
+
+	params := bundleImportParams{}
+	if jsErr := json.Unmarshal(val, &params); nil != jsErr {
+		blog.Error("unmarshal json failed, error information is %s", jsErr.Error())
+		return http.StatusBadRequest, nil, defErr.Error(common.CCErrCommJSONUnmarshalFailed)
+	}
+	if nil == params.Bundle {
+		return http.StatusBadRequest, nil, defErr.Errorf(common.CCErrCommParamsLostField, "bundle")
+	}
+
+	opts := bundle.Options{
+		OwnerID: req.PathParameter("owner_id"),
+		AppID:   params.AppID,
+		Policy:  params.Policy,
+		DryRun:  params.DryRun,
+	}
+	result, err := bundle.Import(cli.newStore(req, defErr), params.Bundle, opts)
+	if bundle.ErrMainlineMismatch == err {
+		return http.StatusBadRequest, nil, defErr.Error(common.CCErrTopoBundleMainlineMismatch)
+	}
+	if bundle.ErrConflict == err {
+		blog.Error("the bundle conflicts with the owner %s, %d conflicts", opts.OwnerID, result.Conflicts)
+		return http.StatusBadRequest, result, defErr.Error(common.CCErrTopoBundleConflict)
+	}
+	if nil != err {
+		blog.Error("failed to import the bundle, error info is %s", err.Error())
+		return http.StatusInternalServerError, result, defErr.Error(common.CCErrTopoBundleImportFailed)
+	}
+	return http.StatusOK, result, nil
+}
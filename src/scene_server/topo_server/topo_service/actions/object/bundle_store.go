@@ -0,0 +1,291 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	httpcli "configcenter/src/common/http/httpclient"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/topo_server/topo_service/logics/bundle"
+	api "configcenter/src/source_controller/api/object"
+
+	restful "github.com/emicklei/go-restful"
+)
+
+// bundleStore read the data from the controllers, write the models by the manager and the instances by the topo and process apis
+type bundleStore struct {
+	cli    *bundleAction
+	req    *restful.Request
+	defErr errors.DefaultCCErrorIf
+}
+
+// request call the api and return the data of the reply
+func (s *bundleStore) request(url, method string, input interface{}) (interface{}, error) {
+
+	var body []byte
+	if nil != input {
+		val, err := json.Marshal(input)
+		if nil != err {
+			return nil, err
+		}
+		body = val
+	}
+
+	reply, err := httpcli.ReqHttp(s.req, url, method, body)
+	if nil != err {
+		return nil, err
+	}
+
+	rsp, ok := s.cli.IsSuccess([]byte(reply))
+	if false == ok {
+		return nil, fmt.Errorf("request %s failed, %v", url, rsp.Message)
+	}
+	return rsp.Data, nil
+}
+
+// create call the api and return the id of the new data
+func (s *bundleStore) create(url, idField string, input interface{}) (int, error) {
+	data, err := s.request(url, common.HTTPCreate, input)
+	if nil != err {
+		return 0, err
+	}
+	if dataMap, ok := data.(map[string]interface{}); ok {
+		if id, err := util.GetIntByInterface(dataMap[idField]); nil == err {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("not found the '%s' in the reply of %s", idField, url)
+}
+
+func (s *bundleStore) SelectMainline(ownerID string) ([]string, error) {
+	items, err := s.cli.mgr.SelectTopoModel(nil, ownerID, common.BKInnerObjIDApp, "", "", "", s.defErr)
+	if nil != err {
+		return nil, err
+	}
+	mainline := make([]string, 0)
+	for _, item := range items {
+		mainline = append(mainline, item.ObjID)
+	}
+	return mainline, nil
+}
+
+func (s *bundleStore) SelectGroups(ownerID, objID string) ([]bundle.Group, error) {
+	items, err := s.cli.mgr.SelectPropertyGroupByObjectID(ownerID, objID, []byte("{}"), s.defErr)
+	if nil != err {
+		return nil, err
+	}
+	groups := make([]bundle.Group, 0)
+	for _, item := range items {
+		groups = append(groups, bundle.Group{
+			GroupID:    item.GroupID,
+			GroupName:  item.GroupName,
+			GroupIndex: item.GroupIndex,
+			IsPre:      item.IsPre,
+		})
+	}
+	return groups, nil
+}
+
+func (s *bundleStore) CreateGroup(ownerID, objID string, group bundle.Group) error {
+	item := api.ObjAttGroupDes{}
+	item.GroupID = group.GroupID
+	item.GroupName = group.GroupName
+	item.GroupIndex = group.GroupIndex
+	item.ObjectID = objID
+	item.OwnerID = ownerID
+	val, _ := json.Marshal(item)
+	_, err := s.cli.mgr.CreateObjectGroup(val, s.defErr)
+	return err
+}
+
+func (s *bundleStore) UpdateGroup(ownerID, objID string, group bundle.Group) error {
+	input := map[string]interface{}{
+		"condition": map[string]interface{}{
+			common.BKOwnerIDField: ownerID,
+			common.BKObjIDField:   objID,
+			"bk_group_id":         group.GroupID,
+		},
+		"data": map[string]interface{}{
+			"bk_group_name":  group.GroupName,
+			"bk_group_index": group.GroupIndex,
+		},
+	}
+	val, _ := json.Marshal(input)
+	return s.cli.mgr.UpdateObjectGroup(val, s.defErr)
+}
+
+func (s *bundleStore) SelectAttributes(ownerID, objID string) ([]bundle.Attribute, error) {
+	condition, _ := json.Marshal(map[string]interface{}{
+		common.BKOwnerIDField: ownerID,
+		common.BKObjIDField:   objID,
+	})
+	items, err := s.cli.mgr.SelectObjectAtt(condition, s.defErr)
+	if nil != err {
+		return nil, err
+	}
+	attrs := make([]bundle.Attribute, 0)
+	for _, item := range items {
+		attrs = append(attrs, bundle.Attribute{
+			ID:            item.ID,
+			PropertyID:    item.PropertyID,
+			PropertyName:  item.PropertyName,
+			PropertyGroup: item.PropertyGroup,
+			PropertyIndex: item.PropertyIndex,
+			PropertyType:  item.PropertyType,
+			Unit:          item.Unit,
+			Placeholder:   item.Placeholder,
+			Editable:      item.Editable,
+			IsRequired:    item.IsRequired,
+			IsReadOnly:    item.IsReadOnly,
+			IsOnly:        item.IsOnly,
+			Option:        item.Option,
+			Description:   item.Description,
+			IsPre:         item.IsPre,
+		})
+	}
+	return attrs, nil
+}
+
+func (s *bundleStore) CreateAttribute(ownerID, objID string, attr bundle.Attribute) error {
+	item := api.ObjAttDes{}
+	item.OwnerID = ownerID
+	item.ObjectID = objID
+	item.PropertyID = attr.PropertyID
+	item.PropertyName = attr.PropertyName
+	item.PropertyGroup = attr.PropertyGroup
+	item.PropertyIndex = attr.PropertyIndex
+	item.PropertyType = attr.PropertyType
+	item.Unit = attr.Unit
+	item.Placeholder = attr.Placeholder
+	item.Editable = attr.Editable
+	item.IsRequired = attr.IsRequired
+	item.IsReadOnly = attr.IsReadOnly
+	item.IsOnly = attr.IsOnly
+	item.Option = attr.Option
+	item.Description = attr.Description
+	item.Creator = util.GetActionUser(s.req)
+	_, err := s.cli.mgr.CreateObjectAtt(item, s.defErr)
+	return err
+}
+
+func (s *bundleStore) UpdateAttribute(ownerID, objID string, id int, data map[string]interface{}) error {
+	val, _ := json.Marshal(data)
+	return s.cli.mgr.UpdateObjectAtt(id, val, s.defErr)
+}
+
+func (s *bundleStore) SelectInsts(ownerID, objID string, condition map[string]interface{}) ([]map[string]interface{}, error) {
+
+	target := objID
+	switch objID {
+	case common.BKInnerObjIDApp, common.BKInnerObjIDSet, common.BKInnerObjIDModule, common.BKInnerObjIDProc:
+	default:
+		target = common.BKINnerObjIDObject
+		condition[common.BKObjIDField] = objID
+	}
+	condition[common.BKOwnerIDField] = ownerID
+
+	searchParams := map[string]interface{}{
+		"condition": condition,
+		"fields":    "",
+		"start":     0,
+		"limit":     common.BKNoLimit,
+		"sort":      bundle.IDField(objID),
+	}
+	data, err := s.request(s.cli.CC.ObjCtrl()+"/object/v1/insts/"+target+"/search", common.HTTPSelectPost, searchParams)
+	if nil != err {
+		return nil, err
+	}
+
+	val, _ := json.Marshal(data)
+	item := InstItem{}
+	if jsErr := json.Unmarshal(val, &item); nil != jsErr {
+		return nil, jsErr
+	}
+	return item.Info, nil
+}
+
+func (s *bundleStore) CreateInst(ownerID, objID string, appID, parentID int, data map[string]interface{}) (int, error) {
+
+	topoURL := s.cli.CC.TopoAPI() + "/topo/v1"
+	switch objID {
+	case common.BKInnerObjIDApp:
+		return s.create(topoURL+"/app/"+ownerID, common.BKAppIDField, data)
+	case common.BKInnerObjIDSet:
+		data[common.BKOwnerIDField] = ownerID
+		data[common.BKInstParentStr] = parentID
+		return s.create(topoURL+"/set/"+strconv.Itoa(appID), common.BKSetIDField, data)
+	case common.BKInnerObjIDModule:
+		data[common.BKOwnerIDField] = ownerID
+		data[common.BKInstParentStr] = parentID
+		return s.create(fmt.Sprintf("%s/module/%d/%d", topoURL, appID, parentID), common.BKModuleIDField, data)
+	}
+	data[common.BKInstParentStr] = parentID
+	return s.create(topoURL+"/inst/"+ownerID+"/"+objID, common.BKInstIDField, data)
+}
+
+func (s *bundleStore) UpdateInst(ownerID, objID string, appID, parentID, instID int, data map[string]interface{}) error {
+
+	topoURL := s.cli.CC.TopoAPI() + "/topo/v1"
+	var uURL string
+	switch objID {
+	case common.BKInnerObjIDApp:
+		uURL = fmt.Sprintf("%s/app/%s/%d", topoURL, ownerID, instID)
+	case common.BKInnerObjIDSet:
+		uURL = fmt.Sprintf("%s/set/%d/%d", topoURL, appID, instID)
+	case common.BKInnerObjIDModule:
+		uURL = fmt.Sprintf("%s/module/%d/%d/%d", topoURL, appID, parentID, instID)
+	default:
+		uURL = fmt.Sprintf("%s/inst/%s/%s/%d", topoURL, ownerID, objID, instID)
+	}
+	_, err := s.request(uURL, common.HTTPUpdate, data)
+	return err
+}
+
+func (s *bundleStore) SelectProcesses(ownerID string, appID int) ([]map[string]interface{}, error) {
+	return s.SelectInsts(ownerID, common.BKInnerObjIDProc, map[string]interface{}{common.BKAppIDField: appID})
+}
+
+func (s *bundleStore) CreateProcess(ownerID string, appID int, data map[string]interface{}) (int, error) {
+	return s.create(fmt.Sprintf("%s/process/v1/%s/%d", s.cli.CC.ProcAPI(), ownerID, appID), common.BKProcIDField, data)
+}
+
+func (s *bundleStore) UpdateProcess(ownerID string, appID, procID int, data map[string]interface{}) error {
+	_, err := s.request(fmt.Sprintf("%s/process/v1/%s/%d/%d", s.cli.CC.ProcAPI(), ownerID, appID, procID), common.HTTPUpdate, data)
+	return err
+}
+
+func (s *bundleStore) SelectBindings(appID int) ([]bundle.Binding, error) {
+	data, err := s.request(s.cli.CC.ProcCtrl()+"/process/v1/module/search", common.HTTPSelectPost, map[string]interface{}{common.BKAppIDField: appID})
+	if nil != err {
+		return nil, err
+	}
+
+	val, _ := json.Marshal(data)
+	bindings := make([]bundle.Binding, 0)
+	if jsErr := json.Unmarshal(val, &bindings); nil != jsErr {
+		return nil, jsErr
+	}
+	return bindings, nil
+}
+
+func (s *bundleStore) CreateBinding(ownerID string, appID, procID int, moduleName string) error {
+	bURL := fmt.Sprintf("%s/process/v1/module/%s/%d/%d/%s", s.cli.CC.ProcAPI(), ownerID, appID, procID, url.PathEscape(moduleName))
+	_, err := s.request(bURL, common.HTTPUpdate, nil)
+	return err
+}
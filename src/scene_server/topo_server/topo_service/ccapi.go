@@ -16,13 +16,13 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/api"
 	"configcenter/src/common/core/cc/config"
+	"configcenter/src/common/discovery"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/http/httpserver"
 	"configcenter/src/common/rdapi"
 	"configcenter/src/common/types"
 	confCenter "configcenter/src/scene_server/topo_server/topo_service/config"
 	"configcenter/src/scene_server/topo_server/topo_service/manager"
-	"configcenter/src/scene_server/topo_server/topo_service/rdiscover"
 
 	"time"
 )
@@ -31,7 +31,7 @@ import (
 type CCAPIServer struct {
 	conf     *config.CCAPIConfig
 	httpServ *httpserver.HttpServer
-	rd       *rdiscover.RegDiscover
+	rd       *discovery.RegDiscover
 	cfCenter *confCenter.ConfCenter
 }
 
@@ -50,10 +50,10 @@ func NewCCAPIServer(conf *config.CCAPIConfig) (*CCAPIServer, error) {
 	a.SetConfig(s.conf)
 	a.InitAction()
 
-	s.rd = rdiscover.NewRegDiscover(s.conf.RegDiscover, addr, port, false)
+	s.rd = discovery.NewRegDiscover(s.conf.GetRegDiscover(), types.CC_MODULE_TOPO, addr, port, false, discovery.NewOptions(s.conf), types.CC_MODULE_HOSTCONTROLLER, types.CC_MODULE_OBJECTCONTROLLER, types.CC_MODULE_AUDITCONTROLLER)
 
 	//ConfCenter
-	s.cfCenter = confCenter.NewConfCenter(s.conf.RegDiscover)
+	s.cfCenter = confCenter.NewConfCenter(s.conf.GetRegDiscover())
 
 	return s, nil
 }
@@ -164,5 +164,7 @@ func (ccAPI *CCAPIServer) Start() error {
 func (ccAPI *CCAPIServer) InitHttpServ() error {
 	a := api.NewAPIResource()
 	ccAPI.httpServ.RegisterWebServer("/topo/{version}", rdapi.AllGlobalFilter(), a.Actions)
+	a.RegisterHealth(ccAPI.httpServ)
+	ccAPI.httpServ.RegisterWebServer("/discovery", nil, []*httpserver.Action{ccAPI.rd.SnapshotAction()})
 	return nil
 }
@@ -0,0 +1,179 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package bundle
+
+import (
+	"errors"
+
+	"configcenter/src/common"
+)
+
+// Version the bundle format version
+const Version = 1
+
+// the conflict policy of the import
+const (
+	PolicySkip      = "skip"
+	PolicyOverwrite = "overwrite"
+	PolicyFail      = "fail"
+)
+
+// the action of a change
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionUnchanged = "unchanged"
+	ActionSkip      = "skip"
+	ActionConflict  = "conflict"
+)
+
+// the kind of a change
+const (
+	KindGroup     = "group"
+	KindAttribute = "attribute"
+	KindInst      = "inst"
+	KindProcess   = "process"
+	KindBinding   = "binding"
+)
+
+// ErrConflict the bundle conflicts with the target and the policy is fail
+var ErrConflict = errors.New("the bundle conflicts with the target")
+
+// ErrMainlineMismatch the mainline objects of the bundle are different from the target,
+// the instances of the custom mainline levels can not be placed in the target
+var ErrMainlineMismatch = errors.New("the mainline model of the bundle is different from the target")
+
+// Bundle one business with its mainline topo, processes and custom models
+type Bundle struct {
+	Version   int       `json:"version"`
+	OwnerID   string    `json:"bk_supplier_account"`
+	AppID     int       `json:"bk_biz_id"`
+	Mainline  []string  `json:"mainline"`
+	Schemas   []Schema  `json:"schemas"`
+	Topo      Inst      `json:"topo"`
+	Processes []Process `json:"processes"`
+	Bindings  []Binding `json:"proc_module"`
+}
+
+// Schema the custom property groups and attributes of one object
+type Schema struct {
+	ObjID      string      `json:"bk_obj_id"`
+	Groups     []Group     `json:"groups"`
+	Attributes []Attribute `json:"attributes"`
+}
+
+// Group a property group
+type Group struct {
+	GroupID    string `json:"bk_group_id"`
+	GroupName  string `json:"bk_group_name"`
+	GroupIndex int    `json:"bk_group_index"`
+	IsPre      bool   `json:"-"`
+}
+
+// Attribute an object attribute
+type Attribute struct {
+	ID            int    `json:"-"`
+	PropertyID    string `json:"bk_property_id"`
+	PropertyName  string `json:"bk_property_name"`
+	PropertyGroup string `json:"bk_property_group"`
+	PropertyIndex int    `json:"bk_property_index"`
+	PropertyType  string `json:"bk_property_type"`
+	Unit          string `json:"unit"`
+	Placeholder   string `json:"placeholder"`
+	Editable      bool   `json:"editable"`
+	IsRequired    bool   `json:"isrequired"`
+	IsReadOnly    bool   `json:"isreadonly"`
+	IsOnly        bool   `json:"isonly"`
+	Option        string `json:"option"`
+	Description   string `json:"description"`
+	IsPre         bool   `json:"-"`
+}
+
+// Inst a mainline instance and its children
+type Inst struct {
+	ObjID   string                 `json:"bk_obj_id"`
+	InstID  int                    `json:"bk_inst_id"`
+	Name    string                 `json:"bk_inst_name"`
+	Default int                    `json:"default"`
+	Data    map[string]interface{} `json:"data"`
+	Child   []Inst                 `json:"child"`
+}
+
+// Process a process of the business
+type Process struct {
+	ProcessID int                    `json:"bk_process_id"`
+	Name      string                 `json:"bk_process_name"`
+	Data      map[string]interface{} `json:"data"`
+}
+
+// Binding a process bound to a module name
+type Binding struct {
+	ProcessID  int    `json:"bk_process_id"`
+	ModuleName string `json:"bk_module_name"`
+}
+
+// Store read and write the cmdb data the bundle is made of
+type Store interface {
+	// SelectMainline return the mainline objects from the business down to the module
+	SelectMainline(ownerID string) ([]string, error)
+
+	SelectGroups(ownerID, objID string) ([]Group, error)
+	CreateGroup(ownerID, objID string, group Group) error
+	UpdateGroup(ownerID, objID string, group Group) error
+
+	SelectAttributes(ownerID, objID string) ([]Attribute, error)
+	CreateAttribute(ownerID, objID string, attr Attribute) error
+	// UpdateAttribute update the fields of the attribute by its id
+	UpdateAttribute(ownerID, objID string, id int, data map[string]interface{}) error
+
+	SelectInsts(ownerID, objID string, condition map[string]interface{}) ([]map[string]interface{}, error)
+	CreateInst(ownerID, objID string, appID, parentID int, data map[string]interface{}) (int, error)
+	UpdateInst(ownerID, objID string, appID, parentID, instID int, data map[string]interface{}) error
+
+	SelectProcesses(ownerID string, appID int) ([]map[string]interface{}, error)
+	CreateProcess(ownerID string, appID int, data map[string]interface{}) (int, error)
+	UpdateProcess(ownerID string, appID, procID int, data map[string]interface{}) error
+
+	SelectBindings(appID int) ([]Binding, error)
+	CreateBinding(ownerID string, appID, procID int, moduleName string) error
+}
+
+// IDField return the id field of the object instances
+func IDField(objID string) string {
+	switch objID {
+	case common.BKInnerObjIDApp:
+		return common.BKAppIDField
+	case common.BKInnerObjIDSet:
+		return common.BKSetIDField
+	case common.BKInnerObjIDModule:
+		return common.BKModuleIDField
+	case common.BKInnerObjIDProc:
+		return common.BKProcIDField
+	}
+	return common.BKInstIDField
+}
+
+// NameField return the name field of the object instances
+func NameField(objID string) string {
+	switch objID {
+	case common.BKInnerObjIDApp:
+		return common.BKAppNameField
+	case common.BKInnerObjIDSet:
+		return common.BKSetNameField
+	case common.BKInnerObjIDModule:
+		return common.BKModuleNameField
+	case common.BKInnerObjIDProc:
+		return common.BKProcNameField
+	}
+	return common.BKInstNameField
+}
@@ -0,0 +1,455 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package bundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/scene_server/topo_server/topo_service/manager"
+)
+
+type memStore struct {
+	mainline []string
+	nextID   int
+	groups   map[string][]Group
+	attrs    map[string][]Attribute
+	insts    map[string][]map[string]interface{}
+	procs    []map[string]interface{}
+	bindings []Binding
+	writes   int
+	updates  []map[string]interface{} // the data of the attribute updates
+	failObj  string                   // the instances of the object are failed to create
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		mainline: []string{common.BKInnerObjIDApp, common.BKInnerObjIDSet, common.BKInnerObjIDModule},
+		nextID:   100,
+		groups:   make(map[string][]Group),
+		attrs:    make(map[string][]Attribute),
+		insts:    make(map[string][]map[string]interface{}),
+	}
+}
+
+func (s *memStore) SelectMainline(ownerID string) ([]string, error) {
+	return s.mainline, nil
+}
+
+func (s *memStore) SelectGroups(ownerID, objID string) ([]Group, error) {
+	return s.groups[objID], nil
+}
+
+func (s *memStore) CreateGroup(ownerID, objID string, group Group) error {
+	s.writes++
+	s.groups[objID] = append(s.groups[objID], group)
+	return nil
+}
+
+func (s *memStore) UpdateGroup(ownerID, objID string, group Group) error {
+	s.writes++
+	for idx, item := range s.groups[objID] {
+		if item.GroupID == group.GroupID {
+			s.groups[objID][idx] = group
+		}
+	}
+	return nil
+}
+
+func (s *memStore) SelectAttributes(ownerID, objID string) ([]Attribute, error) {
+	return s.attrs[objID], nil
+}
+
+func (s *memStore) CreateAttribute(ownerID, objID string, attr Attribute) error {
+	s.writes++
+	s.nextID++
+	attr.ID = s.nextID
+	s.attrs[objID] = append(s.attrs[objID], attr)
+	return nil
+}
+
+func (s *memStore) UpdateAttribute(ownerID, objID string, id int, data map[string]interface{}) error {
+	s.writes++
+	s.updates = append(s.updates, data)
+	for idx, item := range s.attrs[objID] {
+		if item.ID == id {
+			attr := toMap(item)
+			for key, val := range data {
+				attr[key] = val
+			}
+			value, _ := json.Marshal(attr)
+			json.Unmarshal(value, &s.attrs[objID][idx])
+		}
+	}
+	return nil
+}
+
+func (s *memStore) SelectInsts(ownerID, objID string, condition map[string]interface{}) ([]map[string]interface{}, error) {
+	result := make([]map[string]interface{}, 0)
+	for _, item := range s.insts[objID] {
+		if match(item, condition) {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+func (s *memStore) CreateInst(ownerID, objID string, appID, parentID int, data map[string]interface{}) (int, error) {
+	if objID == s.failObj {
+		return 0, fmt.Errorf("create %s failed", objID)
+	}
+	s.writes++
+	s.nextID++
+	data[IDField(objID)] = s.nextID
+	data[common.BKInstParentStr] = parentID
+	if common.BKInnerObjIDApp != objID {
+		data[common.BKAppIDField] = appID
+	}
+	s.insts[objID] = append(s.insts[objID], data)
+	return s.nextID, nil
+}
+
+func (s *memStore) UpdateInst(ownerID, objID string, appID, parentID, instID int, data map[string]interface{}) error {
+	s.writes++
+	for _, item := range s.insts[objID] {
+		if toString(item[IDField(objID)]) == toString(instID) {
+			for key, val := range data {
+				item[key] = val
+			}
+		}
+	}
+	return nil
+}
+
+func (s *memStore) SelectProcesses(ownerID string, appID int) ([]map[string]interface{}, error) {
+	return s.SelectInsts(ownerID, common.BKInnerObjIDProc, map[string]interface{}{common.BKAppIDField: appID})
+}
+
+func (s *memStore) CreateProcess(ownerID string, appID int, data map[string]interface{}) (int, error) {
+	return s.CreateInst(ownerID, common.BKInnerObjIDProc, appID, 0, data)
+}
+
+func (s *memStore) UpdateProcess(ownerID string, appID, procID int, data map[string]interface{}) error {
+	return s.UpdateInst(ownerID, common.BKInnerObjIDProc, appID, 0, procID, data)
+}
+
+func (s *memStore) SelectBindings(appID int) ([]Binding, error) {
+	return s.bindings, nil
+}
+
+func (s *memStore) CreateBinding(ownerID string, appID, procID int, moduleName string) error {
+	s.writes++
+	s.bindings = append(s.bindings, Binding{ProcessID: procID, ModuleName: moduleName})
+	return nil
+}
+
+func match(item, condition map[string]interface{}) bool {
+	for key, val := range condition {
+		if in, ok := val.(map[string]interface{}); ok {
+			found := false
+			for _, id := range in[common.BKDBIN].([]int) {
+				found = found || toString(id) == toString(item[key])
+			}
+			if false == found {
+				return false
+			}
+			continue
+		}
+		if toString(val) != toString(item[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+func newSourceStore() (*memStore, []manager.TopoInstRst) {
+	s := newMemStore()
+	s.groups[common.BKInnerObjIDSet] = []Group{
+		{GroupID: "default", GroupName: "Default", IsPre: true},
+		{GroupID: "ops", GroupName: "运维信息", GroupIndex: 2},
+	}
+	s.attrs[common.BKInnerObjIDSet] = []Attribute{
+		{ID: 1, PropertyID: common.BKSetNameField, PropertyType: "singlechar", IsPre: true},
+		{ID: 2, PropertyID: "set_level", PropertyName: "等级", PropertyGroup: "ops", PropertyType: "int"},
+		{ID: 3, PropertyID: "set_owner", PropertyType: common.FiledTypeSingleAsst},
+	}
+	s.insts[common.BKInnerObjIDApp] = []map[string]interface{}{
+		{common.BKAppIDField: 1, common.BKAppNameField: "demo", "bk_biz_maintainer": "admin", common.BKOwnerIDField: "0", common.BKDefaultField: 0},
+	}
+	s.insts[common.BKInnerObjIDSet] = []map[string]interface{}{
+		{common.BKSetIDField: 2, common.BKSetNameField: "gamesvr", common.BKAppIDField: 1, common.BKInstParentStr: 1, "set_level": 3, "set_owner": 8},
+	}
+	s.insts[common.BKInnerObjIDModule] = []map[string]interface{}{
+		{common.BKModuleIDField: 3, common.BKModuleNameField: "login", common.BKAppIDField: 1, common.BKSetIDField: 2, common.BKInstParentStr: 2},
+	}
+	s.insts[common.BKInnerObjIDProc] = []map[string]interface{}{
+		{common.BKProcIDField: 10, common.BKProcNameField: "nginx", common.BKAppIDField: 1, "port": "80"},
+	}
+	s.bindings = []Binding{{ProcessID: 10, ModuleName: "login"}, {ProcessID: 99, ModuleName: "login"}}
+
+	topo := []manager.TopoInstRst{{
+		TopoInst: manager.TopoInst{InstID: 1, InstName: "demo", ObjID: common.BKInnerObjIDApp},
+		Child: []manager.TopoInstRst{{
+			TopoInst: manager.TopoInst{InstID: 2, InstName: "gamesvr", ObjID: common.BKInnerObjIDSet},
+			Child: []manager.TopoInstRst{{
+				TopoInst: manager.TopoInst{InstID: 3, InstName: "login", ObjID: common.BKInnerObjIDModule},
+			}},
+		}},
+	}}
+	return s, topo
+}
+
+// exportBundle export the source business and pass it through json like the api does
+func exportBundle(t *testing.T) *Bundle {
+	src, topo := newSourceStore()
+	bundle, err := Export(src, "0", 1, topo)
+	if nil != err {
+		t.Fatalf("export failed, %v", err)
+	}
+	data, _ := json.Marshal(bundle)
+	result := &Bundle{}
+	if err := json.Unmarshal(data, result); nil != err {
+		t.Fatalf("unmarshal the bundle failed, %v", err)
+	}
+	return result
+}
+
+func TestExport(t *testing.T) {
+	bundle := exportBundle(t)
+
+	if 1 != len(bundle.Schemas) || common.BKInnerObjIDSet != bundle.Schemas[0].ObjID {
+		t.Fatalf("unexpected schemas %+v", bundle.Schemas)
+	}
+	if schema := bundle.Schemas[0]; 1 != len(schema.Groups) || 1 != len(schema.Attributes) || "set_level" != schema.Attributes[0].PropertyID {
+		t.Fatalf("only the custom groups and attributes are expected, %+v", schema)
+	}
+
+	set := bundle.Topo.Child[0]
+	if "gamesvr" != set.Name || 1 != len(set.Child) || "login" != set.Child[0].Name {
+		t.Fatalf("unexpected topo %+v", bundle.Topo)
+	}
+	if 1 != len(set.Data) || "3" != fmt.Sprint(set.Data["set_level"]) {
+		t.Fatalf("the system and association fields should be removed, %+v", set.Data)
+	}
+	if "admin" != bundle.Topo.Data["bk_biz_maintainer"] {
+		t.Fatalf("unexpected business data %+v", bundle.Topo.Data)
+	}
+
+	if 1 != len(bundle.Processes) || "nginx" != bundle.Processes[0].Name || "80" != bundle.Processes[0].Data["port"] {
+		t.Fatalf("unexpected processes %+v", bundle.Processes)
+	}
+	if 1 != len(bundle.Bindings) || 10 != bundle.Bindings[0].ProcessID {
+		t.Fatalf("only the bindings of the exported processes are expected, %+v", bundle.Bindings)
+	}
+
+	if "biz/set/module" != strings.Join(bundle.Mainline, "/") {
+		t.Fatalf("unexpected mainline %v", bundle.Mainline)
+	}
+
+	if _, err := Export(newMemStore(), "0", 1, nil); nil == err {
+		t.Fatalf("export an unknown business should fail")
+	}
+}
+
+func TestImportCreate(t *testing.T) {
+	bundle := exportBundle(t)
+	dst := newMemStore()
+
+	plan, err := Import(dst, bundle, Options{OwnerID: "1", DryRun: true})
+	if nil != err {
+		t.Fatalf("dry run failed, %v", err)
+	}
+	if 0 != dst.writes || false == plan.DryRun {
+		t.Fatalf("the dry run should not write, %d writes", dst.writes)
+	}
+	for _, change := range plan.Changes {
+		if ActionCreate != change.Action {
+			t.Fatalf("everything should be created, %+v", change)
+		}
+	}
+	if 7 != len(plan.Changes) {
+		t.Fatalf("unexpected changes %+v", plan.Changes)
+	}
+
+	result, err := Import(dst, bundle, Options{OwnerID: "1"})
+	if nil != err {
+		t.Fatalf("import failed, %v", err)
+	}
+	if 7 != dst.writes || 0 != result.Conflicts {
+		t.Fatalf("unexpected writes %d, result %+v", dst.writes, result)
+	}
+
+	appID := result.IDMap[common.BKInnerObjIDApp][1]
+	setID := result.IDMap[common.BKInnerObjIDSet][2]
+	procID := result.IDMap[common.BKInnerObjIDProc][10]
+	if 0 == appID || appID != result.AppID || 0 == setID || 0 == procID {
+		t.Fatalf("unexpected id map %+v", result.IDMap)
+	}
+	module := dst.insts[common.BKInnerObjIDModule][0]
+	if "login" != module[common.BKModuleNameField] || setID != module[common.BKInstParentStr] || appID != module[common.BKAppIDField] {
+		t.Fatalf("the module should be created under the new set, %+v", module)
+	}
+	if "demo" != dst.insts[common.BKInnerObjIDApp][0][common.BKAppNameField] {
+		t.Fatalf("unexpected business %+v", dst.insts[common.BKInnerObjIDApp])
+	}
+	if 1 != len(dst.bindings) || procID != dst.bindings[0].ProcessID {
+		t.Fatalf("the binding should use the new process id, %+v", dst.bindings)
+	}
+
+	// import again changes nothing
+	dst.writes = 0
+	result, err = Import(dst, bundle, Options{OwnerID: "1"})
+	if nil != err {
+		t.Fatalf("import again failed, %v", err)
+	}
+	for _, change := range result.Changes {
+		if ActionUnchanged != change.Action {
+			t.Fatalf("nothing should be changed, %+v", change)
+		}
+	}
+	if 0 != dst.writes {
+		t.Fatalf("nothing should be written, %d writes", dst.writes)
+	}
+}
+
+func TestImportPolicy(t *testing.T) {
+	bundle := exportBundle(t)
+
+	newTarget := func() *memStore {
+		dst, _ := newSourceStore()
+		dst.insts[common.BKInnerObjIDApp][0][common.BKAppNameField] = "online"
+		dst.insts[common.BKInnerObjIDSet][0]["set_level"] = 1
+		dst.attrs[common.BKInnerObjIDSet][1].PropertyName = "level"
+		return dst
+	}
+
+	// fail writes nothing
+	dst := newTarget()
+	result, err := Import(dst, bundle, Options{OwnerID: "0", AppID: 1, Policy: PolicyFail})
+	if ErrConflict != err || 2 != result.Conflicts || 0 != dst.writes {
+		t.Fatalf("the fail policy should stop the import, %v, %+v, %d writes", err, result, dst.writes)
+	}
+
+	// skip keeps the target
+	dst = newTarget()
+	result, err = Import(dst, bundle, Options{OwnerID: "0", AppID: 1, Policy: PolicySkip})
+	if nil != err || 2 != result.Conflicts || 0 != dst.writes {
+		t.Fatalf("the skip policy should keep the target, %v, %+v, %d writes", err, result, dst.writes)
+	}
+	if 1 != dst.insts[common.BKInnerObjIDSet][0]["set_level"] {
+		t.Fatalf("the set should not be changed")
+	}
+
+	// overwrite updates the target but keeps the business name
+	dst = newTarget()
+	result, err = Import(dst, bundle, Options{OwnerID: "0", AppID: 1, Policy: PolicyOverwrite})
+	if nil != err || 2 != dst.writes {
+		t.Fatalf("the overwrite policy should update the target, %v, %+v, %d writes", err, result, dst.writes)
+	}
+	if "3" != fmt.Sprint(dst.insts[common.BKInnerObjIDSet][0]["set_level"]) || "等级" != dst.attrs[common.BKInnerObjIDSet][1].PropertyName {
+		t.Fatalf("the set and attribute should be updated, %+v", dst.insts[common.BKInnerObjIDSet][0])
+	}
+	if "online" != dst.insts[common.BKInnerObjIDApp][0][common.BKAppNameField] || 1 != result.AppID {
+		t.Fatalf("the business should be kept, %+v", dst.insts[common.BKInnerObjIDApp][0])
+	}
+	// only the changed fields of the attribute are sent
+	if 1 != len(dst.updates) || 1 != len(dst.updates[0]) || "等级" != dst.updates[0][common.BKPropertyNameField] {
+		t.Fatalf("only the property name should be updated, %+v", dst.updates)
+	}
+
+	// the type change is a conflict never overwritten
+	dst = newTarget()
+	dst.attrs[common.BKInnerObjIDSet][1].PropertyType = "singlechar"
+	result, err = Import(dst, bundle, Options{OwnerID: "0", AppID: 1, Policy: PolicyOverwrite})
+	if nil != err || 0 != len(dst.updates) || "singlechar" != dst.attrs[common.BKInnerObjIDSet][1].PropertyType {
+		t.Fatalf("the attribute type should not be changed, %v, %+v", err, dst.attrs[common.BKInnerObjIDSet][1])
+	}
+	if ActionConflict != result.Changes[1].Action || 2 != result.Conflicts {
+		t.Fatalf("the type change should be a conflict, %+v", result)
+	}
+
+	if _, err := Import(dst, bundle, Options{OwnerID: "0", Policy: "merge"}); nil == err {
+		t.Fatalf("an unknown policy should fail")
+	}
+	if _, err := Import(dst, bundle, Options{OwnerID: "0", AppID: 404}); nil == err {
+		t.Fatalf("an unknown business should fail")
+	}
+}
+
+func TestImportFailed(t *testing.T) {
+	bundle := exportBundle(t)
+	dst := newMemStore()
+	dst.failObj = common.BKInnerObjIDModule
+
+	// the data created before the error are returned
+	result, err := Import(dst, bundle, Options{OwnerID: "1"})
+	if nil == err || nil == result {
+		t.Fatalf("the import should fail with the result, %v, %+v", err, result)
+	}
+	appID := result.IDMap[common.BKInnerObjIDApp][1]
+	setID := result.IDMap[common.BKInnerObjIDSet][2]
+	if 0 == appID || 0 == setID || 0 != len(result.IDMap[common.BKInnerObjIDModule]) {
+		t.Fatalf("the business and the set should be created, %+v", result.IDMap)
+	}
+
+	// import again continues with the data not imported
+	dst.failObj = ""
+	dst.writes = 0
+	result, err = Import(dst, bundle, Options{OwnerID: "1"})
+	if nil != err {
+		t.Fatalf("import again failed, %v", err)
+	}
+	if appID != result.AppID || setID != result.IDMap[common.BKInnerObjIDSet][2] || 3 != dst.writes {
+		t.Fatalf("only the module, process and binding should be created, %d writes, %+v", dst.writes, result.IDMap)
+	}
+}
+
+func TestImportCustomMainline(t *testing.T) {
+	// the business of the source has the custom level idc between the business and the set
+	src, topo := newSourceStore()
+	src.mainline = []string{common.BKInnerObjIDApp, "idc", common.BKInnerObjIDSet, common.BKInnerObjIDModule}
+	src.attrs["idc"] = []Attribute{{ID: 4, PropertyID: "idc_city", PropertyName: "城市", PropertyType: "singlechar"}}
+	src.insts["idc"] = []map[string]interface{}{
+		{common.BKInstIDField: 5, common.BKInstNameField: "sz", common.BKObjIDField: "idc", common.BKInstParentStr: 1, "idc_city": "shenzhen"},
+	}
+	topo[0].Child = []manager.TopoInstRst{{
+		TopoInst: manager.TopoInst{InstID: 5, InstName: "sz", ObjID: "idc"},
+		Child:    topo[0].Child,
+	}}
+	bundle, err := Export(src, "0", 1, topo)
+	if nil != err {
+		t.Fatalf("export failed, %v", err)
+	}
+	if 2 != len(bundle.Schemas) || "idc" != bundle.Schemas[0].ObjID || "shenzhen" != bundle.Topo.Child[0].Data["idc_city"] {
+		t.Fatalf("the custom level should be exported, %+v", bundle)
+	}
+
+	// the target without the custom level is rejected even by the dry run
+	dst := newMemStore()
+	if _, err := Import(dst, bundle, Options{OwnerID: "1", DryRun: true}); ErrMainlineMismatch != err {
+		t.Fatalf("the different mainline should be rejected, %v", err)
+	}
+
+	dst.mainline = src.mainline
+	result, err := Import(dst, bundle, Options{OwnerID: "1"})
+	if nil != err {
+		t.Fatalf("import failed, %v", err)
+	}
+	idcID := result.IDMap["idc"][5]
+	if 0 == idcID || idcID != dst.insts[common.BKInnerObjIDSet][0][common.BKInstParentStr] {
+		t.Fatalf("the set should be created under the idc, %+v", dst.insts)
+	}
+}
@@ -0,0 +1,195 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package bundle
+
+import (
+	"fmt"
+
+	"configcenter/src/common"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/topo_server/topo_service/manager"
+)
+
+// systemFields the fields set by cmdb, never carried by the bundle
+var systemFields = []string{
+	"_id",
+	common.BKOwnerIDField,
+	common.BKSupplierIDField,
+	common.BKObjIDField,
+	common.BKDefaultField,
+	common.BKInstParentStr,
+	common.BKAppIDField,
+	common.BKSetIDField,
+	common.BKModuleIDField,
+	common.BKInstIDField,
+	common.BKProcIDField,
+	common.CreateTimeField,
+	common.LastTimeField,
+}
+
+// Export build the bundle of the business, topo is the business topo read by SelectInstTopo
+func Export(store Store, ownerID string, appID int, topo []manager.TopoInstRst) (*Bundle, error) {
+
+	if 0 == len(topo) {
+		return nil, fmt.Errorf("not found the business %d", appID)
+	}
+
+	// the mainline levels are carried, so the bundle is only imported into the same mainline model
+	mainline, err := store.SelectMainline(ownerID)
+	if nil != err {
+		return nil, err
+	}
+
+	// collect the instance ids of every mainline object
+	instIDs := make(map[string][]int)
+	var collect func(items []manager.TopoInstRst)
+	collect = func(items []manager.TopoInstRst) {
+		for _, item := range items {
+			instIDs[item.ObjID] = append(instIDs[item.ObjID], item.InstID)
+			collect(item.Child)
+		}
+	}
+	collect(topo[:1])
+	objIDs := append(append([]string{}, mainline...), common.BKInnerObjIDProc)
+
+	bundle := &Bundle{Version: Version, OwnerID: ownerID, AppID: appID, Mainline: mainline}
+	asstFields := make(map[string]map[string]bool)
+	for _, objID := range objIDs {
+		schema, assts, err := exportSchema(store, ownerID, objID)
+		if nil != err {
+			return nil, err
+		}
+		asstFields[objID] = assts
+		if 0 != len(schema.Groups) || 0 != len(schema.Attributes) {
+			bundle.Schemas = append(bundle.Schemas, schema)
+		}
+	}
+
+	// read the full data of the instances
+	instData := make(map[string]map[int]map[string]interface{})
+	for _, objID := range mainline {
+		if 0 == len(instIDs[objID]) {
+			continue
+		}
+		idField := IDField(objID)
+		items, err := store.SelectInsts(ownerID, objID, map[string]interface{}{
+			idField: map[string]interface{}{common.BKDBIN: instIDs[objID]},
+		})
+		if nil != err {
+			return nil, err
+		}
+		instData[objID] = make(map[int]map[string]interface{})
+		for _, item := range items {
+			id, err := util.GetIntByInterface(item[idField])
+			if nil != err {
+				return nil, fmt.Errorf("the %s of the %s instance is invalid", idField, objID)
+			}
+			instData[objID][id] = cleanData(objID, item, asstFields[objID])
+		}
+	}
+
+	var convert func(item manager.TopoInstRst) Inst
+	convert = func(item manager.TopoInstRst) Inst {
+		inst := Inst{
+			ObjID:   item.ObjID,
+			InstID:  item.InstID,
+			Name:    item.InstName,
+			Default: item.Default,
+			Data:    instData[item.ObjID][item.InstID],
+			Child:   make([]Inst, 0),
+		}
+		for _, child := range item.Child {
+			inst.Child = append(inst.Child, convert(child))
+		}
+		return inst
+	}
+	bundle.Topo = convert(topo[0])
+
+	// the processes and their module bindings
+	procs, err := store.SelectProcesses(ownerID, appID)
+	if nil != err {
+		return nil, err
+	}
+	procIDs := make(map[int]bool)
+	for _, item := range procs {
+		id, err := util.GetIntByInterface(item[common.BKProcIDField])
+		if nil != err {
+			return nil, fmt.Errorf("the %s of the process is invalid", common.BKProcIDField)
+		}
+		procIDs[id] = true
+		bundle.Processes = append(bundle.Processes, Process{
+			ProcessID: id,
+			Name:      fmt.Sprint(item[common.BKProcNameField]),
+			Data:      cleanData(common.BKInnerObjIDProc, item, asstFields[common.BKInnerObjIDProc]),
+		})
+	}
+
+	bindings, err := store.SelectBindings(appID)
+	if nil != err {
+		return nil, err
+	}
+	for _, binding := range bindings {
+		if procIDs[binding.ProcessID] {
+			bundle.Bindings = append(bundle.Bindings, binding)
+		}
+	}
+
+	return bundle, nil
+}
+
+// exportSchema read the custom groups and attributes of the object, and the association fields of it
+func exportSchema(store Store, ownerID, objID string) (Schema, map[string]bool, error) {
+
+	schema := Schema{ObjID: objID}
+	groups, err := store.SelectGroups(ownerID, objID)
+	if nil != err {
+		return schema, nil, err
+	}
+	for _, group := range groups {
+		if false == group.IsPre {
+			schema.Groups = append(schema.Groups, group)
+		}
+	}
+
+	attrs, err := store.SelectAttributes(ownerID, objID)
+	if nil != err {
+		return schema, nil, err
+	}
+	assts := make(map[string]bool)
+	for _, attr := range attrs {
+		// the association fields refer to the instances of the source environment, leave them out
+		if common.FiledTypeSingleAsst == attr.PropertyType || common.FieldTypeMultiAsst == attr.PropertyType {
+			assts[attr.PropertyID] = true
+			continue
+		}
+		if false == attr.IsPre {
+			schema.Attributes = append(schema.Attributes, attr)
+		}
+	}
+	return schema, assts, nil
+}
+
+// cleanData remove the system, name and association fields of the instance
+func cleanData(objID string, data map[string]interface{}, assts map[string]bool) map[string]interface{} {
+	result := make(map[string]interface{})
+	for key, val := range data {
+		if assts[key] || NameField(objID) == key {
+			continue
+		}
+		result[key] = val
+	}
+	for _, field := range systemFields {
+		delete(result, field)
+	}
+	return result
+}
@@ -0,0 +1,509 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package bundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/util"
+)
+
+// Options the options of the import
+type Options struct {
+	// OwnerID the owner to import into
+	OwnerID string
+	// AppID the business to merge into, zero to match the business by its name or create it
+	AppID int
+	// Policy what to do with the data which already exists with other values
+	Policy string
+	// DryRun only diff the bundle with the target
+	DryRun bool
+}
+
+// Change one change of the import
+type Change struct {
+	Kind   string   `json:"kind"`
+	ObjID  string   `json:"bk_obj_id"`
+	Path   string   `json:"path"`
+	Action string   `json:"action"`
+	Fields []string `json:"fields,omitempty"`
+	SrcID  int      `json:"src_id,omitempty"`
+	DstID  int      `json:"dst_id,omitempty"`
+}
+
+// Result the result of the import
+type Result struct {
+	DryRun    bool                   `json:"dry_run"`
+	AppID     int                    `json:"bk_biz_id"`
+	Conflicts int                    `json:"conflicts"`
+	Changes   []Change               `json:"changes"`
+	IDMap     map[string]map[int]int `json:"id_map"`
+}
+
+type importer struct {
+	store  Store
+	opts   Options
+	write  bool
+	app    Change
+	result *Result
+}
+
+// Import recreate or merge the bundle into the target, nothing is written if the policy is fail and any conflict is found.
+// The result of the data written before an error is returned with the error, so the ids of the created data are known,
+// and importing the bundle again by the skip policy continues with the data not imported.
+func Import(store Store, bundle *Bundle, opts Options) (*Result, error) {
+
+	if nil == bundle || Version != bundle.Version {
+		return nil, fmt.Errorf("unsupported bundle version")
+	}
+
+	switch opts.Policy {
+	case "":
+		opts.Policy = PolicySkip
+	case PolicySkip, PolicyOverwrite, PolicyFail:
+	default:
+		return nil, fmt.Errorf("unknown conflict policy %s", opts.Policy)
+	}
+
+	// the instances are placed by the mainline levels, a bundle of other levels is rejected even in the dry run
+	mainline, err := store.SelectMainline(opts.OwnerID)
+	if nil != err {
+		return nil, err
+	}
+	if strings.Join(mainline, "/") != strings.Join(bundle.Mainline, "/") {
+		blog.Errorf("the mainline of the bundle is %v, the mainline of the owner %s is %v", bundle.Mainline, opts.OwnerID, mainline)
+		return nil, ErrMainlineMismatch
+	}
+
+	// diff the bundle with the target first
+	plan, err := newImporter(store, opts, false).run(bundle)
+	if nil != err {
+		return plan, err
+	}
+	if PolicyFail == opts.Policy && 0 != plan.Conflicts {
+		return plan, ErrConflict
+	}
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	return newImporter(store, opts, true).run(bundle)
+}
+
+func newImporter(store Store, opts Options, write bool) *importer {
+	return &importer{
+		store: store,
+		opts:  opts,
+		write: write,
+		result: &Result{
+			DryRun:  false == write,
+			Changes: make([]Change, 0),
+			IDMap:   make(map[string]map[int]int),
+		},
+	}
+}
+
+// run import the bundle, the result holds the changes made before the error if any
+func (i *importer) run(bundle *Bundle) (*Result, error) {
+
+	for _, schema := range bundle.Schemas {
+		if err := i.importGroups(schema.ObjID, schema.Groups); nil != err {
+			return i.result, err
+		}
+		if err := i.importAttributes(schema.ObjID, schema.Attributes); nil != err {
+			return i.result, err
+		}
+	}
+
+	if err := i.importApp(bundle.Topo); nil != err {
+		return i.result, err
+	}
+
+	if err := i.importProcesses(bundle.Processes); nil != err {
+		return i.result, err
+	}
+
+	if err := i.importBindings(bundle.Processes, bundle.Bindings); nil != err {
+		return i.result, err
+	}
+
+	return i.result, nil
+}
+
+func (i *importer) importGroups(objID string, groups []Group) error {
+
+	items, err := i.store.SelectGroups(i.opts.OwnerID, objID)
+	if nil != err {
+		return err
+	}
+	targets := make(map[string]Group)
+	for _, item := range items {
+		targets[item.GroupID] = item
+	}
+
+	for _, group := range groups {
+		change := Change{Kind: KindGroup, ObjID: objID, Path: objID + "/" + group.GroupID}
+		target, ok := targets[group.GroupID]
+		if false == ok {
+			change.Action = ActionCreate
+			if i.write {
+				if err := i.store.CreateGroup(i.opts.OwnerID, objID, group); nil != err {
+					return err
+				}
+			}
+			i.add(change)
+			continue
+		}
+
+		change.Fields = diffData(toMap(group), toMap(target))
+		change.Action = i.resolve(change.Fields, target.IsPre)
+		if ActionUpdate == change.Action && i.write {
+			if err := i.store.UpdateGroup(i.opts.OwnerID, objID, group); nil != err {
+				return err
+			}
+		}
+		i.add(change)
+	}
+	return nil
+}
+
+func (i *importer) importAttributes(objID string, attrs []Attribute) error {
+
+	items, err := i.store.SelectAttributes(i.opts.OwnerID, objID)
+	if nil != err {
+		return err
+	}
+	targets := make(map[string]Attribute)
+	for _, item := range items {
+		targets[item.PropertyID] = item
+	}
+
+	for _, attr := range attrs {
+		change := Change{Kind: KindAttribute, ObjID: objID, Path: objID + "/" + attr.PropertyID}
+		target, ok := targets[attr.PropertyID]
+		if false == ok {
+			change.Action = ActionCreate
+			if i.write {
+				if err := i.store.CreateAttribute(i.opts.OwnerID, objID, attr); nil != err {
+					return err
+				}
+			}
+			i.add(change)
+			continue
+		}
+
+		data := toMap(attr)
+		change.Fields = diffData(data, toMap(target))
+		if attr.PropertyType != target.PropertyType {
+			// the values of the instances are kept in the target type, the type is never overwritten by any policy
+			i.result.Conflicts++
+			change.Action = ActionConflict
+			i.add(change)
+			continue
+		}
+		change.Action = i.resolve(change.Fields, target.IsPre)
+		if ActionUpdate == change.Action && i.write {
+			input := make(map[string]interface{})
+			for _, field := range change.Fields {
+				input[field] = data[field]
+			}
+			if err := i.store.UpdateAttribute(i.opts.OwnerID, objID, target.ID, input); nil != err {
+				return err
+			}
+		}
+		i.add(change)
+	}
+	return nil
+}
+
+// importApp import the business and its mainline instances
+func (i *importer) importApp(topo Inst) error {
+
+	condition := make(map[string]interface{})
+	if 0 != i.opts.AppID {
+		condition[common.BKAppIDField] = i.opts.AppID
+	} else {
+		condition[common.BKAppNameField] = topo.Name
+	}
+	items, err := i.store.SelectInsts(i.opts.OwnerID, common.BKInnerObjIDApp, condition)
+	if nil != err {
+		return err
+	}
+
+	var target map[string]interface{}
+	if 0 != len(items) {
+		target = items[0]
+	} else if 0 != i.opts.AppID {
+		return fmt.Errorf("not found the business %d", i.opts.AppID)
+	}
+
+	// the name of the business given by the options is kept
+	if 0 != i.opts.AppID {
+		topo.Name = fmt.Sprint(target[common.BKAppNameField])
+	}
+
+	change, err := i.importInst(topo, target, topo.Name, 0, 0)
+	if nil != err {
+		return err
+	}
+	i.app = change
+	i.result.AppID = change.DstID
+	return i.importInsts(topo.Child, topo.Name, change)
+}
+
+// importInsts import the child instances of the parent, the parent id is zero if it is not created yet
+func (i *importer) importInsts(items []Inst, path string, parent Change) error {
+
+	appID, parentID := i.result.AppID, parent.DstID
+	targets := make(map[string]map[string]map[string]interface{})
+	for _, item := range items {
+
+		itemPath := path + "/" + item.Name
+		if ActionSkip == parent.Action && 0 == parentID {
+			// the parent is not imported, neither are the children
+			change := Change{Kind: KindInst, ObjID: item.ObjID, Path: itemPath, SrcID: item.InstID, Action: ActionSkip}
+			i.add(change)
+			if err := i.importInsts(item.Child, itemPath, change); nil != err {
+				return err
+			}
+			continue
+		}
+
+		if _, ok := targets[item.ObjID]; false == ok && 0 != parentID {
+			condition := map[string]interface{}{common.BKInstParentStr: parentID}
+			switch item.ObjID {
+			case common.BKInnerObjIDSet, common.BKInnerObjIDModule:
+				condition[common.BKAppIDField] = appID
+			}
+			rows, err := i.store.SelectInsts(i.opts.OwnerID, item.ObjID, condition)
+			if nil != err {
+				return err
+			}
+			targets[item.ObjID] = indexByName(rows, NameField(item.ObjID))
+		}
+
+		change, err := i.importInst(item, targets[item.ObjID][item.Name], itemPath, appID, parentID)
+		if nil != err {
+			return err
+		}
+		if err := i.importInsts(item.Child, itemPath, change); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// importInst import one instance, the id of it in the target is zero if it is not created yet
+func (i *importer) importInst(item Inst, target map[string]interface{}, path string, appID, parentID int) (Change, error) {
+
+	change := Change{Kind: KindInst, ObjID: item.ObjID, Path: path, SrcID: item.InstID}
+
+	// the default instances are created by cmdb itself
+	if nil == target && 0 != item.Default {
+		change.Action = ActionSkip
+		i.add(change)
+		return change, nil
+	}
+
+	err := i.apply(&change, item.Data, target, IDField(item.ObjID), 0 != item.Default,
+		func(data map[string]interface{}) (int, error) {
+			data[NameField(item.ObjID)] = item.Name
+			return i.store.CreateInst(i.opts.OwnerID, item.ObjID, appID, parentID, data)
+		},
+		func(instID int, data map[string]interface{}) error {
+			return i.store.UpdateInst(i.opts.OwnerID, item.ObjID, appID, parentID, instID, data)
+		})
+	return change, err
+}
+
+func (i *importer) importProcesses(procs []Process) error {
+
+	targets := make(map[string]map[string]interface{})
+	if 0 != i.result.AppID {
+		rows, err := i.store.SelectProcesses(i.opts.OwnerID, i.result.AppID)
+		if nil != err {
+			return err
+		}
+		targets = indexByName(rows, common.BKProcNameField)
+	}
+
+	for _, proc := range procs {
+		change := Change{Kind: KindProcess, ObjID: common.BKInnerObjIDProc, Path: proc.Name, SrcID: proc.ProcessID}
+		if ActionSkip == i.app.Action && 0 == i.app.DstID {
+			change.Action = ActionSkip
+			i.add(change)
+			continue
+		}
+		err := i.apply(&change, proc.Data, targets[proc.Name], common.BKProcIDField, false,
+			func(data map[string]interface{}) (int, error) {
+				data[common.BKProcNameField] = proc.Name
+				return i.store.CreateProcess(i.opts.OwnerID, i.result.AppID, data)
+			},
+			func(procID int, data map[string]interface{}) error {
+				return i.store.UpdateProcess(i.opts.OwnerID, i.result.AppID, procID, data)
+			})
+		if nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *importer) importBindings(procs []Process, bindings []Binding) error {
+
+	existing := make(map[string]bool)
+	if 0 != i.result.AppID {
+		rows, err := i.store.SelectBindings(i.result.AppID)
+		if nil != err {
+			return err
+		}
+		for _, row := range rows {
+			existing[fmt.Sprintf("%d/%s", row.ProcessID, row.ModuleName)] = true
+		}
+	}
+
+	procNames := make(map[int]string)
+	for _, proc := range procs {
+		procNames[proc.ProcessID] = proc.Name
+	}
+
+	for _, binding := range bindings {
+		procID := i.result.IDMap[common.BKInnerObjIDProc][binding.ProcessID]
+		change := Change{Kind: KindBinding, ObjID: common.BKInnerObjIDProc, SrcID: binding.ProcessID, DstID: procID}
+		name, ok := procNames[binding.ProcessID]
+		change.Path = name + "/" + binding.ModuleName
+		switch {
+		case false == ok || (i.write && 0 == procID):
+			// the process is not carried by the bundle or not imported
+			change.Action = ActionSkip
+		case existing[fmt.Sprintf("%d/%s", procID, binding.ModuleName)]:
+			change.Action = ActionUnchanged
+		default:
+			change.Action = ActionCreate
+			if i.write {
+				if err := i.store.CreateBinding(i.opts.OwnerID, i.result.AppID, procID, binding.ModuleName); nil != err {
+					return err
+				}
+			}
+		}
+		i.add(change)
+	}
+	return nil
+}
+
+// apply create the data if the target does not exist, or diff and update it by the policy
+func (i *importer) apply(change *Change, data, target map[string]interface{}, idField string, preset bool,
+	create func(data map[string]interface{}) (int, error), update func(id int, data map[string]interface{}) error) error {
+
+	if nil == target {
+		change.Action = ActionCreate
+		if i.write {
+			input := make(map[string]interface{})
+			for key, val := range data {
+				input[key] = val
+			}
+			id, err := create(input)
+			if nil != err {
+				return err
+			}
+			change.DstID = id
+		}
+		i.add(*change)
+		return nil
+	}
+
+	id, err := util.GetIntByInterface(target[idField])
+	if nil != err {
+		return fmt.Errorf("the %s of %s is invalid", idField, change.Path)
+	}
+	change.DstID = id
+	change.Fields = diffData(data, target)
+	change.Action = i.resolve(change.Fields, preset)
+	if ActionUpdate == change.Action && i.write {
+		input := make(map[string]interface{})
+		for _, field := range change.Fields {
+			input[field] = data[field]
+		}
+		if err := update(id, input); nil != err {
+			return err
+		}
+	}
+	i.add(*change)
+	return nil
+}
+
+// resolve decide the action of the existing data by the different fields and the policy, the preset data is never changed
+func (i *importer) resolve(fields []string, preset bool) string {
+	if 0 == len(fields) {
+		return ActionUnchanged
+	}
+	i.result.Conflicts++
+	switch {
+	case PolicyFail == i.opts.Policy:
+		return ActionConflict
+	case PolicyOverwrite == i.opts.Policy && false == preset:
+		return ActionUpdate
+	}
+	return ActionSkip
+}
+
+func (i *importer) add(change Change) {
+	if KindInst == change.Kind || KindProcess == change.Kind {
+		if 0 != change.DstID {
+			if _, ok := i.result.IDMap[change.ObjID]; false == ok {
+				i.result.IDMap[change.ObjID] = make(map[int]int)
+			}
+			i.result.IDMap[change.ObjID][change.SrcID] = change.DstID
+		}
+	}
+	i.result.Changes = append(i.result.Changes, change)
+}
+
+// diffData return the fields of the source whose values are different in the target
+func diffData(src, dst map[string]interface{}) []string {
+	fields := make([]string, 0)
+	for key, val := range src {
+		if toString(val) != toString(dst[key]) {
+			fields = append(fields, key)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func toString(val interface{}) string {
+	if nil == val {
+		return ""
+	}
+	return fmt.Sprint(val)
+}
+
+func toMap(val interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	data, _ := json.Marshal(val)
+	json.Unmarshal(data, &result)
+	return result
+}
+
+func indexByName(rows []map[string]interface{}, nameField string) map[string]map[string]interface{} {
+	result := make(map[string]map[string]interface{})
+	for _, row := range rows {
+		result[toString(row[nameField])] = row
+	}
+	return result
+}
@@ -39,6 +39,12 @@ func NewClient(address string) *Client {
 
 var bk_inst_id_fields string = "inst_id"
 
+// SetRequestID set the request id which the audit log records
+func (cli *Client) SetRequestID(requestID string) *Client {
+	cli.Base.HttpCli.SetHeader(common.BKHTTPRequestID, requestID)
+	return cli
+}
+
 //AuditHostLog  新加主机操作日志
 func (cli *Client) AuditHostLog(id interface{}, Content interface{}, OpDesc string, InnerIP, ownerID, appID, user string, OpType auditoplog.AuditOpType) (interface{}, error) {
 	data := common.KvMap{common.BKContentField: Content, common.BKOpDescField: OpDesc, common.BKHostInnerIPField: InnerIP, common.BKOpTypeField: OpType, bk_inst_id_fields: id}
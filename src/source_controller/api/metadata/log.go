@@ -30,6 +30,10 @@ type OperationLog struct {
 	ExtInfo       string      `bson:"ext_info"            json:"ext_info"`
 	CreateTime    time.Time   `bson:"op_time"         json:"op_time"`
 	InstID        int         `bson:"inst_id"             json:"inst_id"`
+	RequestID     string      `bson:"request_id"          json:"request_id"`
+
+	// Changes the changed properties between the pre data and the cur data of the content
+	Changes []PropertyChange `bson:"changes" json:"changes"`
 }
 
 // TableName return the table name
@@ -37,6 +41,13 @@ func (OperationLog) TableName() string {
 	return "cc_OperationLog"
 }
 
+// PropertyChange the value change of one property
+type PropertyChange struct {
+	PropertyID string      `bson:"bk_property_id" json:"bk_property_id"`
+	PreValue   interface{} `bson:"pre_value"      json:"pre_value"`
+	CurValue   interface{} `bson:"cur_value"      json:"cur_value"`
+}
+
 type Content struct {
 	PreData interface{} `json:"pre_data"`
 	CurData interface{} `json:"cur_data"`
@@ -0,0 +1,42 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package userapi
+
+import (
+	"configcenter/src/common"
+	"fmt"
+)
+
+//SearchDynamic 获取所有业务的动态主机组
+func (cli *Client) SearchDynamic() (int, *common.APIRsp, error) {
+	url := fmt.Sprintf("%s/host/v1/userapi/dynamic/search", cli.GetAddress())
+	return cli.GetRequestInfoEx(common.HTTPSelectPost, nil, url)
+}
+
+//MarkDue 标记业务的动态主机组和主机所属业务的动态主机组待重新计算
+func (cli *Client) MarkDue(input interface{}) (int, *common.APIRsp, error) {
+	url := fmt.Sprintf("%s/host/v1/userapi/dynamic/due", cli.GetAddress())
+	return cli.GetRequestInfoEx(common.HTTPCreate, input, url)
+}
+
+//GetMembers 获取动态主机组的成员
+func (cli *Client) GetMembers(appID, id string) (int, *common.APIRsp, error) {
+	url := fmt.Sprintf("%s/host/v1/userapi/members/%s/%s", cli.GetAddress(), appID, id)
+	return cli.GetRequestInfoEx(common.HTTPSelectGet, nil, url)
+}
+
+//SetMembers 更新动态主机组的成员，发送主机加入和离开的事件
+func (cli *Client) SetMembers(appID, id string, input interface{}) (int, *common.APIRsp, error) {
+	url := fmt.Sprintf("%s/host/v1/userapi/members/%s/%s", cli.GetAddress(), appID, id)
+	return cli.GetRequestInfoEx(common.HTTPUpdate, input, url)
+}
@@ -73,7 +73,7 @@ func (a *appAuditAction) AddLog(req *restful.Request, resp *restful.Response) {
 		return
 	}
 	logics.DB = appAudit.CC.InstCli
-	err = logics.AddLogWithStr(appID, appID, params.OpType, common.BKInnerObjIDApp, params.Content, "", params.OpDesc, ownerID, user)
+	err = logics.AddLogWithStr(appID, appID, params.OpType, common.BKInnerObjIDApp, params.Content, "", params.OpDesc, ownerID, user, util.GetActionRequestID(req))
 	if nil != err {
 		blog.Errorf("add application log error:%s", err.Error())
 		appAudit.ResponseFailed(common.CCErrCommDBInsertFailed, defErr.Error(common.CCErrCommDBInsertFailed).Error(), resp)
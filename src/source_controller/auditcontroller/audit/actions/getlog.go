@@ -20,6 +20,7 @@ import (
 	"configcenter/src/common/util"
 	"configcenter/src/source_controller/auditcontroller/audit/logics"
 	"configcenter/src/source_controller/common/commondata"
+	"configcenter/src/storage"
 	"encoding/json"
 	"io/ioutil"
 
@@ -38,6 +39,7 @@ func init() {
 
 	// register actions
 	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/search", Params: nil, Handler: queryAudit.Get})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/export", Params: nil, Handler: queryAudit.Export})
 	// set cc api resource
 }
 
@@ -59,13 +61,79 @@ func (q *queryAuditAction) Get(req *restful.Request, resp *restful.Response) {
 		q.ResponseFailed(common.CCErrCommJSONUnmarshalFailed, defErr.Error(common.CCErrCommJSONUnmarshalFailed).Error(), resp)
 		return
 	}
+	if err := logics.ApplyFilter(&dat); nil != err {
+		blog.Errorf("the filter is invalid, error:%v", err)
+		q.ResponseFailed(common.CCErrCommParamsInvalid, defErr.Errorf(common.CCErrCommParamsInvalid, err.Error()).Error(), resp)
+		return
+	}
 	logics.DB = appAudit.CC.InstCli
-	rows, cnt, err := logics.Search(dat)
+	rows, cnt, nextCursor, err := logics.Search(dat)
+	if storage.ErrInvalidCursor == err {
+		q.ResponseFailed(common.CCErrCommParamsInvalid, defErr.Errorf(common.CCErrCommParamsInvalid, "cursor").Error(), resp)
+		return
+	}
 	if nil != err {
 		blog.Error("get data from data  error:%s", err.Error())
 		q.ResponseFailed(common.CCErrCommDBSelectFailed, defErr.Error(common.CCErrCommDBSelectFailed).Error(), resp)
 		return
 	}
-	data := common.KvMap{"info": rows, "count": cnt}
+	data := common.KvMap{"info": rows}
+	if !dat.SkipCount {
+		data["count"] = cnt
+	}
+	if dat.UseCursor() {
+		data["next_cursor"] = nextCursor
+	}
 	queryAudit.ResponseSuccess(data, resp)
 }
+
+// Export write the logs matched the query to the response as jsonl or csv,
+// the query is the same as the search, the format is specified by the format query parameter
+func (q *queryAuditAction) Export(req *restful.Request, resp *restful.Response) {
+	language := util.GetActionLanguage(req)
+	defErr := q.CC.Error.CreateDefaultCCErrorIf(language)
+
+	value, err := ioutil.ReadAll(req.Request.Body)
+	if err != nil {
+		blog.Errorf("read http request boody error:%s", err.Error())
+		q.ResponseFailed(common.CCErrCommHTTPReadBodyFailed, defErr.Error(common.CCErrCommHTTPReadBodyFailed).Error(), resp)
+		return
+	}
+	var dat commondata.ObjQueryInput
+	err = json.Unmarshal([]byte(value), &dat)
+	if err != nil {
+		blog.Error("json unmarshal failed,input:%v error:%v", string(value), err)
+		q.ResponseFailed(common.CCErrCommJSONUnmarshalFailed, defErr.Error(common.CCErrCommJSONUnmarshalFailed).Error(), resp)
+		return
+	}
+	if err := logics.ApplyFilter(&dat); nil != err {
+		blog.Errorf("the filter is invalid, error:%v", err)
+		q.ResponseFailed(common.CCErrCommParamsInvalid, defErr.Errorf(common.CCErrCommParamsInvalid, err.Error()).Error(), resp)
+		return
+	}
+
+	format := req.QueryParameter("format")
+	switch format {
+	case "", logics.ExportFormatJSONL:
+		format = logics.ExportFormatJSONL
+		resp.AddHeader("Content-Type", "application/x-ndjson")
+	case logics.ExportFormatCSV:
+		resp.AddHeader("Content-Type", "text/csv; charset=utf-8")
+	default:
+		blog.Errorf("unsupported export format %s", format)
+		q.ResponseFailed(common.CCErrCommParamsInvalid, defErr.Errorf(common.CCErrCommParamsInvalid, "format").Error(), resp)
+		return
+	}
+	resp.AddHeader("Content-Disposition", "attachment; filename=operation_log."+format)
+
+	logics.DB = appAudit.CC.InstCli
+	cnt, err := logics.ExportLogs(resp.ResponseWriter, dat, format)
+	if nil != err {
+		blog.Errorf("export operation logs error:%v, %d logs exported", err, cnt)
+		if 0 == cnt {
+			// nothing is written yet, respond the error instead of the attachment
+			resp.Header().Del("Content-Disposition")
+			q.ResponseFailed(common.CCErrCommDBSelectFailed, defErr.Error(common.CCErrCommDBSelectFailed).Error(), resp)
+		}
+	}
+}
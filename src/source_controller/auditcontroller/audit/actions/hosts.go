@@ -75,7 +75,7 @@ func (a *hostAuditAction) AddHostLog(req *restful.Request, resp *restful.Respons
 	}
 
 	logics.DB = appAudit.CC.InstCli
-	err = logics.AddLogWithStr(appID, params.HostID, params.OpType, common.BKInnerObjIDHost, params.Content, params.InnerIP, params.OpDesc, ownerID, user)
+	err = logics.AddLogWithStr(appID, params.HostID, params.OpType, common.BKInnerObjIDHost, params.Content, params.InnerIP, params.OpDesc, ownerID, user, util.GetActionRequestID(req))
 	if nil != err {
 		blog.Errorf("add host log error:%s", err.Error())
 		hostAudit.ResponseFailed(common.CCErrCommDBInsertFailed, defErr.Error(common.CCErrCommDBInsertFailed).Error(), resp)
@@ -117,7 +117,7 @@ func (a *hostAuditAction) AddHostLogs(req *restful.Request, resp *restful.Respon
 	}
 
 	logics.DB = appAudit.CC.InstCli
-	err = logics.AddLogMultiWithExtKey(appID, params.OpType, common.BKInnerObjIDHost, params.Content, params.OpDesc, ownerID, user)
+	err = logics.AddLogMultiWithExtKey(appID, params.OpType, common.BKInnerObjIDHost, params.Content, params.OpDesc, ownerID, user, util.GetActionRequestID(req))
 	if nil != err {
 		blog.Errorf("add host log error:%s", err.Error())
 		hostAudit.ResponseFailed(common.CCErrCommDBInsertFailed, defErr.Error(common.CCErrCommDBInsertFailed).Error(), resp)
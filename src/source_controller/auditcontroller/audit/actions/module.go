@@ -75,7 +75,7 @@ func (a *moduleAuditAction) AddLog(req *restful.Request, resp *restful.Response)
 	}
 
 	logics.DB = appAudit.CC.InstCli
-	err = logics.AddLogWithStr(appID, params.ModuleID, params.OpType, common.BKInnerObjIDModule, params.Content, "", params.OpDesc, ownerID, user)
+	err = logics.AddLogWithStr(appID, params.ModuleID, params.OpType, common.BKInnerObjIDModule, params.Content, "", params.OpDesc, ownerID, user, util.GetActionRequestID(req))
 	if nil != err {
 		blog.Errorf("add module log error:%s", err.Error())
 		moduleAudit.ResponseFailed(common.CCErrCommDBInsertFailed, defErr.Error(common.CCErrCommDBInsertFailed).Error(), resp)
@@ -116,7 +116,7 @@ func (a *moduleAuditAction) AddLogs(req *restful.Request, resp *restful.Response
 	}
 
 	logics.DB = appAudit.CC.InstCli
-	err = logics.AddLogMulti(appID, params.OpType, common.BKInnerObjIDModule, params.Content, params.OpDesc, ownerID, user)
+	err = logics.AddLogMulti(appID, params.OpType, common.BKInnerObjIDModule, params.Content, params.OpDesc, ownerID, user, util.GetActionRequestID(req))
 	if nil != err {
 		blog.Errorf("add module log error:%s", err.Error())
 		moduleAudit.ResponseFailed(common.CCErrCommDBInsertFailed, defErr.Error(common.CCErrCommDBInsertFailed).Error(), resp)
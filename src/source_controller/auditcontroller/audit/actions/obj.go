@@ -75,7 +75,7 @@ func (a *objAuditAction) AddLog(req *restful.Request, resp *restful.Response) {
 	}
 
 	logics.DB = appAudit.CC.InstCli
-	err = logics.AddLogWithStr(appID, params.InstID, params.OpType, params.OpTarget, params.Content, "", params.OpDesc, ownerID, user)
+	err = logics.AddLogWithStr(appID, params.InstID, params.OpType, params.OpTarget, params.Content, "", params.OpDesc, ownerID, user, util.GetActionRequestID(req))
 	if nil != err {
 		blog.Error("json unmarshal failed,input:%v error:%v", string(value), err)
 		objAudit.ResponseFailed(common.CCErrCommJSONUnmarshalFailed, defErr.Error(common.CCErrCommJSONUnmarshalFailed).Error(), resp)
@@ -117,7 +117,7 @@ func (a *objAuditAction) AddLogs(req *restful.Request, resp *restful.Response) {
 	}
 
 	logics.DB = appAudit.CC.InstCli
-	err = logics.AddLogMulti(appID, params.OpType, params.OpTarget, params.Content, params.OpDesc, ownerID, user)
+	err = logics.AddLogMulti(appID, params.OpType, params.OpTarget, params.Content, params.OpDesc, ownerID, user, util.GetActionRequestID(req))
 	if nil != err {
 		blog.Errorf("add module log error:%s", err.Error())
 		objAudit.ResponseFailed(common.CCErrCommDBInsertFailed, defErr.Error(common.CCErrCommDBInsertFailed).Error(), resp)
@@ -74,7 +74,7 @@ func (a *procAuditAction) AddLog(req *restful.Request, resp *restful.Response) {
 	}
 
 	logics.DB = appAudit.CC.InstCli
-	err = logics.AddLogWithStr(appID, params.ProcID, params.OpType, common.BKInnerObjIDProc, params.Content, "", params.OpDesc, ownerID, user)
+	err = logics.AddLogWithStr(appID, params.ProcID, params.OpType, common.BKInnerObjIDProc, params.Content, "", params.OpDesc, ownerID, user, util.GetActionRequestID(req))
 	if nil != err {
 		blog.Error("json unmarshal failed,input:%v error:%v", string(value), err)
 		procAudit.ResponseFailed(common.CCErrCommJSONUnmarshalFailed, defErr.Error(common.CCErrCommJSONUnmarshalFailed).Error(), resp)
@@ -115,7 +115,7 @@ func (a *procAuditAction) AddLogs(req *restful.Request, resp *restful.Response)
 	}
 
 	logics.DB = appAudit.CC.InstCli
-	err = logics.AddLogMulti(appID, params.OpType, common.BKInnerObjIDProc, params.Content, params.OpDesc, ownerID, user)
+	err = logics.AddLogMulti(appID, params.OpType, common.BKInnerObjIDProc, params.Content, params.OpDesc, ownerID, user, util.GetActionRequestID(req))
 	if nil != err {
 		blog.Error("json unmarshal failed,input:%v error:%v", string(value), err)
 		procAudit.ResponseFailed(common.CCErrCommJSONUnmarshalFailed, defErr.Error(common.CCErrCommJSONUnmarshalFailed).Error(), resp)
@@ -74,7 +74,7 @@ func (a *setAuditAction) AddLog(req *restful.Request, resp *restful.Response) {
 	}
 
 	logics.DB = appAudit.CC.InstCli
-	err = logics.AddLogWithStr(appID, params.SetID, params.OpType, common.BKInnerObjIDSet, params.Content, "", params.OpDesc, ownerID, user)
+	err = logics.AddLogWithStr(appID, params.SetID, params.OpType, common.BKInnerObjIDSet, params.Content, "", params.OpDesc, ownerID, user, util.GetActionRequestID(req))
 	if nil != err {
 		blog.Error("json unmarshal failed,input:%v error:%v", string(value), err)
 		setAudit.ResponseFailed(common.CCErrCommJSONUnmarshalFailed, defErr.Error(common.CCErrCommJSONUnmarshalFailed).Error(), resp)
@@ -115,7 +115,7 @@ func (a *setAuditAction) AddLogs(req *restful.Request, resp *restful.Response) {
 	}
 
 	logics.DB = appAudit.CC.InstCli
-	err = logics.AddLogMulti(appID, params.OpType, common.BKInnerObjIDSet, params.Content, params.OpDesc, ownerID, user)
+	err = logics.AddLogMulti(appID, params.OpType, common.BKInnerObjIDSet, params.Content, params.OpDesc, ownerID, user, util.GetActionRequestID(req))
 	if nil != err {
 		blog.Error("json unmarshal failed,input:%v error:%v", string(value), err)
 		setAudit.ResponseFailed(common.CCErrCommJSONUnmarshalFailed, defErr.Error(common.CCErrCommJSONUnmarshalFailed).Error(), resp)
@@ -16,10 +16,12 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/api"
 	"configcenter/src/common/core/cc/config"
+	"configcenter/src/common/discovery"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/http/httpserver"
+	"configcenter/src/common/types"
 	confCenter "configcenter/src/source_controller/auditcontroller/audit/config"
-	"configcenter/src/source_controller/auditcontroller/audit/rdiscover"
+	"configcenter/src/source_controller/auditcontroller/audit/logics"
 	"time"
 )
 
@@ -27,7 +29,7 @@ import (
 type CCAPIServer struct {
 	conf     *config.CCAPIConfig
 	httpServ *httpserver.HttpServer
-	rd       *rdiscover.RegDiscover
+	rd       *discovery.RegDiscover
 	cfCenter *confCenter.ConfCenter
 }
 
@@ -47,10 +49,10 @@ func NewCCAPIServer(conf *config.CCAPIConfig) (*CCAPIServer, error) {
 	a.InitAction()
 
 	// RDiscover
-	s.rd = rdiscover.NewRegDiscover(s.conf.RegDiscover, addr, port, false)
+	s.rd = discovery.NewRegDiscover(s.conf.GetRegDiscover(), types.CC_MODULE_AUDITCONTROLLER, addr, port, false, discovery.Options{})
 
 	//ConfCenter
-	s.cfCenter = confCenter.NewConfCenter(s.conf.RegDiscover)
+	s.cfCenter = confCenter.NewConfCenter(s.conf.GetRegDiscover())
 
 	return s, nil
 }
@@ -133,7 +135,10 @@ func (ccAPI *CCAPIServer) Start() error {
 		if err != nil {
 			blog.Error("connect mongodb error exit! err:%s", err.Error())
 			chErr <- err
+			return
 		}
+		logics.DB = a.InstCli
+		logics.StartArchiver(logics.ParseArchiveConfig(config))
 	}()
 
 	// register and discover
@@ -156,6 +161,7 @@ func (ccAPI *CCAPIServer) initHttpServ() error {
 	a := api.NewAPIResource()
 
 	ccAPI.httpServ.RegisterWebServer("/audit/{version}", nil, a.Actions)
+	a.RegisterHealth(ccAPI.httpServ)
 
 	return nil
 }
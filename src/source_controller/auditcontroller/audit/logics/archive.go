@@ -0,0 +1,234 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package logics
+
+import (
+	"compress/gzip"
+	"configcenter/src/common/blog"
+	"configcenter/src/source_controller/api/metadata"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/xid"
+	"gopkg.in/mgo.v2"
+)
+
+const (
+	// retentionConfigPrefix the retention days of the op target are configured as audit_retention.<op_target>
+	retentionConfigPrefix = "audit_retention."
+	// retentionDefaultTarget the retention days of the op targets which are not configured
+	retentionDefaultTarget = "default"
+
+	defaultArchivePath     = "./archive"
+	defaultArchiveInterval = time.Hour
+
+	// archiveLockCollection keep the lease of the archiver, only the holder of the lease archives the logs,
+	// so the replicas of the auditcontroller do not archive and remove the same logs at the same time
+	archiveLockCollection = "cc_AuditArchiveLock"
+	archiveLockID         = "archiver"
+)
+
+// ArchiveConfig the retention and archive config of the operation logs
+type ArchiveConfig struct {
+	// Path the dir where the archive files are kept
+	Path string
+	// Interval the interval of the archiver to check the expired logs
+	Interval time.Duration
+	// Retention the days to keep the logs in db of each op target, keep forever if zero
+	Retention map[string]int
+}
+
+// ParseArchiveConfig parse the archive config from the [audit] and [audit_retention] sections
+func ParseArchiveConfig(config map[string]string) *ArchiveConfig {
+	conf := &ArchiveConfig{
+		Path:      defaultArchivePath,
+		Interval:  defaultArchiveInterval,
+		Retention: map[string]int{},
+	}
+	if path := strings.TrimSpace(config["audit.archive_path"]); "" != path {
+		conf.Path = path
+	}
+	if minutes, err := strconv.Atoi(config["audit.archive_interval_minutes"]); nil == err && minutes > 0 {
+		conf.Interval = time.Duration(minutes) * time.Minute
+	}
+	for key, value := range config {
+		if !strings.HasPrefix(key, retentionConfigPrefix) {
+			continue
+		}
+		days, err := strconv.Atoi(strings.TrimSpace(value))
+		if nil != err || days < 0 {
+			blog.Warnf("invalid audit retention %s=%s, ignore it", key, value)
+			continue
+		}
+		conf.Retention[strings.TrimPrefix(key, retentionConfigPrefix)] = days
+	}
+	return conf
+}
+
+// StartArchiver move the expired logs to the archive files periodically,
+// the replica which holds the archive lease runs the archive of the interval, the others skip it
+func StartArchiver(conf *ArchiveConfig) {
+	blog.Infof("start audit archiver, path: %s, interval: %v, retention: %v", conf.Path, conf.Interval, conf.Retention)
+	owner := xid.New().String()
+	for {
+		// the lease outlives the interval, so a run longer than the interval is not taken over by another replica
+		locked, err := lockArchiver(owner, 2*conf.Interval, time.Now())
+		if nil != err {
+			blog.Errorf("lock the audit archiver error: %v", err)
+		} else if locked {
+			if err := Archive(conf, time.Now()); nil != err {
+				blog.Errorf("archive operation logs error: %v", err)
+			}
+		}
+		time.Sleep(conf.Interval)
+	}
+}
+
+// lockArchiver take the archive lease for the ttl if it is expired or held by the owner,
+// return false if the lease is held by another archiver
+func lockArchiver(owner string, ttl time.Duration, now time.Time) (bool, error) {
+	// the owner renews its lease, or a lease expired is given up
+	condition := map[string]interface{}{
+		"_id": archiveLockID,
+		"$or": []interface{}{
+			map[string]interface{}{"owner": owner},
+			map[string]interface{}{"expire_time": map[string]interface{}{"$lt": now}},
+		},
+	}
+	if err := DB.DelByCondition(archiveLockCollection, condition); nil != err {
+		return false, err
+	}
+	// the _id is unique, only one of the archivers gets the lease
+	lock := map[string]interface{}{"_id": archiveLockID, "owner": owner, "expire_time": now.Add(ttl)}
+	if _, err := DB.Insert(archiveLockCollection, lock); nil != err {
+		if mgo.IsDup(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Archive move the logs which expired at now to the archive files, one file for each op target
+func Archive(conf *ArchiveConfig, now time.Time) error {
+	for _, target := range getArchiveTargets(conf) {
+		days := conf.Retention[target]
+		if days <= 0 {
+			continue
+		}
+		condition := getArchiveCondition(conf, target, now.AddDate(0, 0, -days))
+		count, err := archiveLogs(conf.Path, target, condition, now)
+		if nil != err {
+			return fmt.Errorf("archive %s logs failed, %v", target, err)
+		}
+		if count > 0 {
+			blog.Infof("archived %d %s operation logs expired %d days", count, target, days)
+		}
+	}
+	return nil
+}
+
+// getArchiveTargets return the configured op targets, the default target is the last
+func getArchiveTargets(conf *ArchiveConfig) []string {
+	targets := make([]string, 0, len(conf.Retention))
+	for target := range conf.Retention {
+		if retentionDefaultTarget != target {
+			targets = append(targets, target)
+		}
+	}
+	sort.Strings(targets)
+	return append(targets, retentionDefaultTarget)
+}
+
+func getArchiveCondition(conf *ArchiveConfig, target string, expireTime time.Time) map[string]interface{} {
+	condition := map[string]interface{}{
+		"op_time": map[string]interface{}{"$lt": expireTime},
+	}
+	if retentionDefaultTarget != target {
+		condition["op_target"] = target
+		return condition
+	}
+	others := make([]string, 0, len(conf.Retention))
+	for target := range conf.Retention {
+		if retentionDefaultTarget != target {
+			others = append(others, target)
+		}
+	}
+	condition["op_target"] = map[string]interface{}{"$nin": others}
+	return condition
+}
+
+// archiveLogs write the logs matched the condition to the gzip jsonl file, then remove them from db
+func archiveLogs(path, target string, condition map[string]interface{}, now time.Time) (int, error) {
+	logRow := metadata.OperationLog{}
+	count, err := DB.GetCntByCondition(logRow.TableName(), condition)
+	if nil != err || 0 == count {
+		return 0, err
+	}
+	if err := os.MkdirAll(path, 0755); nil != err {
+		return 0, err
+	}
+
+	fileName := filepath.Join(path, fmt.Sprintf("%s_%s_%s.jsonl.gz", logRow.TableName(), target, now.Format("20060102150405")))
+	tmpName := fileName + ".tmp"
+	file, err := os.Create(tmpName)
+	if nil != err {
+		return 0, err
+	}
+	zw := gzip.NewWriter(file)
+	writer, _ := NewLogWriter(zw, ExportFormatJSONL, nil)
+
+	archived := 0
+	for archived < count {
+		rows := make([]map[string]interface{}, 0)
+		if err = DB.GetMutilByCondition(logRow.TableName(), nil, condition, &rows, "op_time", archived, exportBatchSize); nil != err {
+			break
+		}
+		if 0 == len(rows) {
+			break
+		}
+		for _, row := range rows {
+			if err = writer.Write(row); nil != err {
+				break
+			}
+			archived++
+		}
+		if nil != err {
+			break
+		}
+	}
+	if closeErr := zw.Close(); nil == err {
+		err = closeErr
+	}
+	if closeErr := file.Close(); nil == err {
+		err = closeErr
+	}
+	if nil == err {
+		err = os.Rename(tmpName, fileName)
+	}
+	if nil != err {
+		os.Remove(tmpName)
+		return 0, err
+	}
+
+	// the logs are not removed until they are kept in the archive file
+	if err := DB.DelByCondition(logRow.TableName(), condition); nil != err {
+		return archived, err
+	}
+	return archived, nil
+}
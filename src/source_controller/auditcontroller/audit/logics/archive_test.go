@@ -0,0 +1,74 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package logics
+
+import (
+	"configcenter/src/storage/memclient"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseArchiveConfig(t *testing.T) {
+	conf := ParseArchiveConfig(map[string]string{
+		"audit.archive_path":             "/data/archive",
+		"audit.archive_interval_minutes": "10",
+		"audit_retention.default":        "365",
+		"audit_retention.host":           "30",
+		"audit_retention.biz":            "x",
+	})
+	if conf.Path != "/data/archive" || conf.Interval != 10*time.Minute {
+		t.Errorf("unexpected archive config %v", conf)
+	}
+	if !reflect.DeepEqual(conf.Retention, map[string]int{"default": 365, "host": 30}) {
+		t.Errorf("unexpected retention %v", conf.Retention)
+	}
+	if targets := getArchiveTargets(conf); !reflect.DeepEqual(targets, []string{"host", "default"}) {
+		t.Errorf("unexpected targets %v", targets)
+	}
+
+	expire := time.Now()
+	cond := getArchiveCondition(conf, "default", expire)
+	want := map[string]interface{}{
+		"op_time":   map[string]interface{}{"$lt": expire},
+		"op_target": map[string]interface{}{"$nin": []string{"host"}},
+	}
+	if !reflect.DeepEqual(cond, want) {
+		t.Errorf("getArchiveCondition() = %v, want %v", cond, want)
+	}
+
+	conf = ParseArchiveConfig(map[string]string{})
+	if conf.Path != defaultArchivePath || conf.Interval != defaultArchiveInterval || 0 != len(conf.Retention) {
+		t.Errorf("unexpected default archive config %v", conf)
+	}
+}
+
+func TestLockArchiver(t *testing.T) {
+	DB = memclient.NewMemDB()
+	now := time.Now()
+	if locked, err := lockArchiver("a", time.Hour, now); nil != err || !locked {
+		t.Fatalf("the first archiver should take the lease, locked: %v, err: %v", locked, err)
+	}
+	if locked, err := lockArchiver("b", time.Hour, now.Add(time.Minute)); nil != err || locked {
+		t.Errorf("the lease is held by another archiver, locked: %v, err: %v", locked, err)
+	}
+	if locked, err := lockArchiver("a", time.Hour, now.Add(time.Minute)); nil != err || !locked {
+		t.Errorf("the holder should renew the lease, locked: %v, err: %v", locked, err)
+	}
+	if locked, err := lockArchiver("b", time.Hour, now.Add(2*time.Hour)); nil != err || !locked {
+		t.Errorf("the expired lease should be taken over, locked: %v, err: %v", locked, err)
+	}
+	if locked, err := lockArchiver("a", time.Hour, now.Add(2*time.Hour)); nil != err || locked {
+		t.Errorf("the lease is taken over by another archiver, locked: %v, err: %v", locked, err)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package logics
+
+import (
+	"configcenter/src/source_controller/api/metadata"
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// contentData the pre data and the cur data of the audit log content
+type contentData struct {
+	PreData map[string]interface{} `json:"pre_data"`
+	CurData map[string]interface{} `json:"cur_data"`
+}
+
+// GetContentChanges compare the pre data and the cur data of the content field by field,
+// return the changed properties ordered by the property id, nil if the content has no map data
+func GetContentChanges(content interface{}) []metadata.PropertyChange {
+	data, ok := parseContentData(content)
+	if !ok {
+		return nil
+	}
+
+	propertyIDs := make([]string, 0, len(data.PreData)+len(data.CurData))
+	for propertyID := range data.PreData {
+		propertyIDs = append(propertyIDs, propertyID)
+	}
+	for propertyID := range data.CurData {
+		if _, ok := data.PreData[propertyID]; !ok {
+			propertyIDs = append(propertyIDs, propertyID)
+		}
+	}
+	sort.Strings(propertyIDs)
+
+	changes := make([]metadata.PropertyChange, 0)
+	for _, propertyID := range propertyIDs {
+		preValue := data.PreData[propertyID]
+		curValue := data.CurData[propertyID]
+		if reflect.DeepEqual(preValue, curValue) {
+			continue
+		}
+		changes = append(changes, metadata.PropertyChange{
+			PropertyID: propertyID,
+			PreValue:   preValue,
+			CurValue:   curValue,
+		})
+	}
+	return changes
+}
+
+func parseContentData(content interface{}) (*contentData, bool) {
+	if nil == content {
+		return nil, false
+	}
+	if _, ok := content.(string); ok {
+		return nil, false
+	}
+	value, err := json.Marshal(content)
+	if nil != err {
+		return nil, false
+	}
+	data := &contentData{}
+	if err := json.Unmarshal(value, data); nil != err {
+		return nil, false
+	}
+	if nil == data.PreData && nil == data.CurData {
+		return nil, false
+	}
+	return data, true
+}
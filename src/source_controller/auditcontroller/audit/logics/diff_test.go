@@ -0,0 +1,67 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package logics
+
+import (
+	"configcenter/src/source_controller/api/metadata"
+	"reflect"
+	"testing"
+)
+
+func TestGetContentChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		content interface{}
+		want    []metadata.PropertyChange
+	}{
+		{"string content", "sss", nil},
+		{"nil content", nil, nil},
+		{"no data", map[string]interface{}{"header": []interface{}{}}, nil},
+		{
+			name: "update",
+			content: map[string]interface{}{
+				"pre_data": map[string]interface{}{"bk_host_name": "a", "bk_cpu": 1, "bk_os_name": "linux"},
+				"cur_data": map[string]interface{}{"bk_host_name": "b", "bk_cpu": 1, "bk_mem": 2},
+			},
+			want: []metadata.PropertyChange{
+				{PropertyID: "bk_host_name", PreValue: "a", CurValue: "b"},
+				{PropertyID: "bk_mem", PreValue: nil, CurValue: float64(2)},
+				{PropertyID: "bk_os_name", PreValue: "linux", CurValue: nil},
+			},
+		},
+		{
+			name: "create",
+			content: metadata.Content{
+				CurData: map[string]interface{}{"bk_set_name": "set"},
+			},
+			want: []metadata.PropertyChange{
+				{PropertyID: "bk_set_name", PreValue: nil, CurValue: "set"},
+			},
+		},
+		{
+			name: "not changed",
+			content: map[string]interface{}{
+				"pre_data": map[string]interface{}{"module": []interface{}{"m1"}},
+				"cur_data": map[string]interface{}{"module": []interface{}{"m1"}},
+			},
+			want: []metadata.PropertyChange{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetContentChanges(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetContentChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package logics
+
+import (
+	"configcenter/src/common/blog"
+	"configcenter/src/source_controller/api/metadata"
+	"configcenter/src/source_controller/common/commondata"
+	"configcenter/src/storage"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// ExportFormatJSONL one json object of the log per line
+	ExportFormatJSONL = "jsonl"
+	// ExportFormatCSV csv with the header line
+	ExportFormatCSV = "csv"
+
+	// exportBatchSize the count of the logs loaded from db at one time
+	exportBatchSize = 500
+)
+
+// defaultExportFields the columns of the csv if the fields are not specified
+var defaultExportFields = []string{
+	"bk_supplier_account", "bk_biz_id", "op_type", "op_target", "inst_id", "ext_key",
+	"op_desc", "operator", "op_time", "request_id", "content", "changes",
+}
+
+// LogWriter write the operation logs in the export format
+type LogWriter interface {
+	Write(row map[string]interface{}) error
+	Flush() error
+}
+
+// NewLogWriter create the writer of the format, the fields are the csv columns,
+// nothing is written to w until the first row is written or the writer is flushed
+func NewLogWriter(w io.Writer, format string, fields []string) (LogWriter, error) {
+	switch format {
+	case "", ExportFormatJSONL:
+		return &jsonlWriter{w: w}, nil
+	case ExportFormatCSV:
+		if 0 == len(fields) {
+			fields = defaultExportFields
+		}
+		return &csvWriter{w: csv.NewWriter(w), fields: fields}, nil
+	}
+	return nil, fmt.Errorf("unsupported export format %s", format)
+}
+
+type jsonlWriter struct {
+	w io.Writer
+}
+
+func (j *jsonlWriter) Write(row map[string]interface{}) error {
+	value, err := json.Marshal(row)
+	if nil != err {
+		return err
+	}
+	_, err = j.w.Write(append(value, '\n'))
+	return err
+}
+
+func (j *jsonlWriter) Flush() error {
+	return nil
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	fields []string
+	header bool
+}
+
+// writeHeader write the header line before the first row,
+// so a query failed before any row is fetched can still be responded as an error
+func (c *csvWriter) writeHeader() error {
+	if c.header {
+		return nil
+	}
+	c.header = true
+	return c.w.Write(c.fields)
+}
+
+func (c *csvWriter) Write(row map[string]interface{}) error {
+	if err := c.writeHeader(); nil != err {
+		return err
+	}
+	record := make([]string, 0, len(c.fields))
+	for _, field := range c.fields {
+		record = append(record, formatCSVValue(row[field]))
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Flush() error {
+	if err := c.writeHeader(); nil != err {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func formatCSVValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Local().Format("2006-01-02 15:04:05")
+	case int, int32, int64, float64, bool:
+		return fmt.Sprint(v)
+	}
+	content, err := json.Marshal(value)
+	if nil != err {
+		return fmt.Sprint(value)
+	}
+	return string(content)
+}
+
+// ExportLogs write the logs matched the query to w batch by batch, return the count of the exported logs,
+// all the matched logs are exported if the limit is not set
+func ExportLogs(w io.Writer, dat commondata.ObjQueryInput, format string) (int, error) {
+	dat.ConvTime()
+	fields := make([]string, 0)
+	for _, field := range strings.Split(dat.Fields, ",") {
+		if field = strings.TrimSpace(field); "" != field {
+			fields = append(fields, field)
+		}
+	}
+	writer, err := NewLogWriter(w, format, fields)
+	if nil != err {
+		return 0, err
+	}
+
+	sort := dat.Sort
+	if "" == sort {
+		sort = "op_time"
+	}
+	logRow := metadata.OperationLog{}
+	db := storage.Secondary(DB)
+	count := 0
+	for {
+		limit := exportBatchSize
+		if dat.Limit > 0 && dat.Limit-count < limit {
+			limit = dat.Limit - count
+		}
+		if limit <= 0 {
+			break
+		}
+		rows := make([]map[string]interface{}, 0)
+		if err := db.GetMutilByCondition(logRow.TableName(), fields, dat.Condition, &rows, sort, dat.Start+count, limit); nil != err {
+			return count, err
+		}
+		for _, row := range rows {
+			if err := writer.Write(row); nil != err {
+				return count, err
+			}
+			count++
+		}
+		if err := writer.Flush(); nil != err {
+			return count, err
+		}
+		if flusher, ok := w.(interface {
+			Flush()
+		}); ok {
+			flusher.Flush()
+		}
+		if len(rows) < limit {
+			break
+		}
+	}
+	blog.Infof("exported %d operation logs", count)
+	return count, nil
+}
@@ -0,0 +1,71 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package logics
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestLogWriter(t *testing.T) {
+	row := map[string]interface{}{
+		"op_target": "host",
+		"inst_id":   1,
+		"op_time":   time.Date(2018, 3, 8, 3, 30, 28, 0, time.Local),
+		"changes":   []interface{}{map[string]interface{}{"bk_property_id": "bk_host_name"}},
+	}
+
+	buf := &bytes.Buffer{}
+	writer, err := NewLogWriter(buf, ExportFormatJSONL, nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if err := writer.Write(row); nil != err {
+		t.Fatal(err)
+	}
+	writer.Flush()
+	want := `{"changes":[{"bk_property_id":"bk_host_name"}],"inst_id":1,"op_target":"host","op_time":"` + row["op_time"].(time.Time).Format(time.RFC3339Nano) + "\"}\n"
+	if buf.String() != want {
+		t.Errorf("jsonl = %s, want %s", buf.String(), want)
+	}
+
+	buf.Reset()
+	writer, err = NewLogWriter(buf, ExportFormatCSV, []string{"op_target", "inst_id", "op_time", "changes", "op_desc"})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if 0 != buf.Len() {
+		t.Errorf("the csv header should not be written before the first row, got %s", buf.String())
+	}
+	if err := writer.Write(row); nil != err {
+		t.Fatal(err)
+	}
+	writer.Flush()
+	want = "op_target,inst_id,op_time,changes,op_desc\nhost,1,2018-03-08 03:30:28,\"[{\"\"bk_property_id\"\":\"\"bk_host_name\"\"}]\",\n"
+	if buf.String() != want {
+		t.Errorf("csv = %s, want %s", buf.String(), want)
+	}
+
+	// the header is still written if there is no row
+	buf.Reset()
+	writer, _ = NewLogWriter(buf, ExportFormatCSV, []string{"op_target", "inst_id"})
+	writer.Flush()
+	if buf.String() != "op_target,inst_id\n" {
+		t.Errorf("csv without rows = %s", buf.String())
+	}
+
+	if _, err := NewLogWriter(buf, "xml", nil); nil == err {
+		t.Error("xml format should not be supported")
+	}
+}
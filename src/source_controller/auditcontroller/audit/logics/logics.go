@@ -13,7 +13,9 @@
 package logics
 
 import (
+	"configcenter/src/common"
 	"configcenter/src/common/auditoplog"
+	"configcenter/src/common/querydsl"
 	"configcenter/src/source_controller/api/metadata"
 	"configcenter/src/source_controller/common/commondata"
 	storage "configcenter/src/storage"
@@ -25,7 +27,7 @@ var (
 	DB storage.DI = nil
 )
 
-func AddLogMulti(appID int, opType auditoplog.AuditOpType, opTarget string, contents []auditoplog.AuditLogContext, opDesc, ownerID, user string) error {
+func AddLogMulti(appID int, opType auditoplog.AuditOpType, opTarget string, contents []auditoplog.AuditLogContext, opDesc, ownerID, user, requestID string) error {
 	var logRows []interface{}
 
 	for _, content := range contents {
@@ -40,6 +42,8 @@ func AddLogMulti(appID int, opType auditoplog.AuditOpType, opTarget string, cont
 			Content:       content.Content,
 			CreateTime:    time.Now(),
 			InstID:        content.ID,
+			RequestID:     requestID,
+			Changes:       GetContentChanges(content.Content),
 		}
 		logRows = append(logRows, row)
 
@@ -52,7 +56,7 @@ func AddLogMulti(appID int, opType auditoplog.AuditOpType, opTarget string, cont
 	return err
 }
 
-func AddLogMultiWithExtKey(appID int, opType auditoplog.AuditOpType, opTarget string, contents []auditoplog.AuditLogExt, opDesc, ownerID, user string) error {
+func AddLogMultiWithExtKey(appID int, opType auditoplog.AuditOpType, opTarget string, contents []auditoplog.AuditLogExt, opDesc, ownerID, user, requestID string) error {
 	var logRows []interface{}
 
 	for _, content := range contents {
@@ -67,6 +71,8 @@ func AddLogMultiWithExtKey(appID int, opType auditoplog.AuditOpType, opTarget st
 			Content:       content.Content,
 			CreateTime:    time.Now(),
 			InstID:        content.ID,
+			RequestID:     requestID,
+			Changes:       GetContentChanges(content.Content),
 		}
 		logRows = append(logRows, row)
 
@@ -79,7 +85,7 @@ func AddLogMultiWithExtKey(appID int, opType auditoplog.AuditOpType, opTarget st
 	return err
 }
 
-func AddLogWithStr(appID, instID int, opType auditoplog.AuditOpType, opTarget string, content interface{}, extKey, opDesc, ownerID, user string) error {
+func AddLogWithStr(appID, instID int, opType auditoplog.AuditOpType, opTarget string, content interface{}, extKey, opDesc, ownerID, user, requestID string) error {
 	logRow := &metadata.OperationLog{
 		OwnerID:       ownerID,
 		ApplicationID: appID,
@@ -91,12 +97,16 @@ func AddLogWithStr(appID, instID int, opType auditoplog.AuditOpType, opTarget st
 		Content:       content,
 		CreateTime:    time.Now(),
 		InstID:        instID,
+		RequestID:     requestID,
+		Changes:       GetContentChanges(content),
 	}
 	_, err := DB.Insert(logRow.TableName(), logRow)
 	return err
 }
 
-func Search(dat commondata.ObjQueryInput) ([]metadata.OperationLog, int, error) {
+// Search return the operation logs of the page, the count of the matched logs is 0 if skipped,
+// the cursor of the next page is returned if the page is searched by the cursor
+func Search(dat commondata.ObjQueryInput) ([]metadata.OperationLog, int, string, error) {
 	fields := dat.Fields
 	condition := dat.Condition
 	dat.ConvTime()
@@ -106,15 +116,62 @@ func Search(dat commondata.ObjQueryInput) ([]metadata.OperationLog, int, error)
 	fieldArr := strings.Split(fields, ",")
 	rows := make([]metadata.OperationLog, 0)
 	logRow := metadata.OperationLog{}
-	err := DB.GetMutilByCondition(logRow.TableName(), fieldArr, condition, &rows, sort, skip, limit)
+	nextCursor := ""
+	// the log search tolerates the replication lag
+	db := storage.Secondary(DB)
+	var err error
+	if dat.UseCursor() {
+		nextCursor, err = db.GetMutilByCursor(logRow.TableName(), fieldArr, condition, &rows, sort, dat.Cursor, limit)
+	} else {
+		err = db.GetMutilByCondition(logRow.TableName(), fieldArr, condition, &rows, sort, skip, limit)
+	}
 	if nil != err {
-		return nil, 0, err
+		return nil, 0, "", err
+	}
+	if dat.SkipCount {
+		return rows, 0, nextCursor, nil
 	}
-	cnt, err := DB.GetCntByCondition(logRow.TableName(), condition)
+	cnt, err := db.GetCntByCondition(logRow.TableName(), condition)
 	if nil != err {
-		return nil, 0, err
+		return nil, 0, "", err
 	}
 
-	return rows, cnt, nil
+	return rows, cnt, nextCursor, nil
+
+}
+
+// operationLogSchema the fields of the operation log which can be filtered
+var operationLogSchema = querydsl.Schema{
+	common.BKOwnerIDField:  common.FiledTypeSingleChar,
+	common.BKAppIDField:    common.FiledTypeInt,
+	"ext_key":              common.FiledTypeSingleChar,
+	"op_desc":              common.FiledTypeSingleChar,
+	common.BKOpTypeField:   common.FiledTypeInt,
+	common.BKOpTargetField: common.FiledTypeSingleChar,
+	"operator":             common.FiledTypeSingleChar,
+	common.BKOpTimeField:   common.FiledTypeTime,
+	common.BKInstIDField:   common.FiledTypeInt,
+	"request_id":           common.FiledTypeSingleChar,
+}
+
+// ApplyFilter validate the filter of the query against the operation log fields,
+// and merge it into the condition
+func ApplyFilter(dat *commondata.ObjQueryInput) error {
+	if nil == dat.Filter {
+		return nil
+	}
+	filter, err := querydsl.Compile(dat.Filter, operationLogSchema)
+	if nil != err {
+		return err
+	}
+	dat.Filter = nil
+	dat.ConvTime()
 
+	conds, _ := dat.Condition.(map[string]interface{})
+	if 0 == len(conds) {
+		dat.Condition = filter
+		return nil
+	}
+	dat.Condition = map[string]interface{}{"$and": []interface{}{conds, filter}}
+	return nil
 }
@@ -15,9 +15,12 @@ package logics
 import (
 	"configcenter/src/common"
 	"configcenter/src/common/auditoplog"
+	"configcenter/src/common/querydsl"
 	"configcenter/src/source_controller/common/commondata"
 	storage "configcenter/src/storage"
+	"configcenter/src/storage/dbclient"
 	"errors"
+	"reflect"
 	"testing"
 )
 
@@ -28,7 +31,7 @@ func TestAddLogMutilNotData(t *testing.T) {
 		err:  nil,
 	}
 	DB = mockdb
-	err := AddLogMulti(1, auditoplog.AuditOpTypeAdd, common.BKInnerObjIDHost, nil, "null", common.BKDefaultOwnerID, "user")
+	err := AddLogMulti(1, auditoplog.AuditOpTypeAdd, common.BKInnerObjIDHost, nil, "null", common.BKDefaultOwnerID, "user", "")
 	if err != mockdb.err {
 		t.Error(err)
 	}
@@ -48,7 +51,7 @@ func TestAddLogMutilErr(t *testing.T) {
 		auditoplog.AuditLogContext{ID: 1, Content: "sss"},
 	}
 
-	err := AddLogMulti(1, auditoplog.AuditOpTypeAdd, common.BKInnerObjIDHost, contents, "mock desc", common.BKDefaultOwnerID, "user")
+	err := AddLogMulti(1, auditoplog.AuditOpTypeAdd, common.BKInnerObjIDHost, contents, "mock desc", common.BKDefaultOwnerID, "user", "")
 	if err != mockdb.err {
 		t.Error(err)
 	}
@@ -67,7 +70,7 @@ func TestAddLogMutil(t *testing.T) {
 		auditoplog.AuditLogContext{ID: 1, Content: "sss"},
 	}
 
-	err := AddLogMulti(1, auditoplog.AuditOpTypeAdd, common.BKInnerObjIDHost, contents, "mock desc", common.BKDefaultOwnerID, "user")
+	err := AddLogMulti(1, auditoplog.AuditOpTypeAdd, common.BKInnerObjIDHost, contents, "mock desc", common.BKDefaultOwnerID, "user", "")
 	if err != mockdb.err {
 		t.Error(err)
 	}
@@ -81,7 +84,7 @@ func TestAddLogMultiWithExtKeyNotData(t *testing.T) {
 	}
 	DB = mockdb
 
-	err := AddLogMultiWithExtKey(1, auditoplog.AuditOpTypeAdd, common.BKInnerObjIDHost, nil, "mock desc", common.BKDefaultOwnerID, "user")
+	err := AddLogMultiWithExtKey(1, auditoplog.AuditOpTypeAdd, common.BKInnerObjIDHost, nil, "mock desc", common.BKDefaultOwnerID, "user", "")
 	if err != mockdb.err {
 		t.Error(err)
 	}
@@ -100,7 +103,7 @@ func TestAddLogMultiWithExtKeyErr(t *testing.T) {
 		auditoplog.AuditLogExt{ID: 1, Content: "row1", ExtKey: "127.0.0.1"},
 	}
 
-	err := AddLogMultiWithExtKey(1, auditoplog.AuditOpTypeAdd, common.BKInnerObjIDHost, contents, "mock desc", common.BKDefaultOwnerID, "user")
+	err := AddLogMultiWithExtKey(1, auditoplog.AuditOpTypeAdd, common.BKInnerObjIDHost, contents, "mock desc", common.BKDefaultOwnerID, "user", "")
 	if err != mockdb.err {
 		t.Error(err)
 	}
@@ -118,7 +121,7 @@ func TestAddLogMultiWithExtKey(t *testing.T) {
 		auditoplog.AuditLogExt{ID: 2, Content: "row2", ExtKey: "127.0.0.2"},
 	}
 
-	err := AddLogMultiWithExtKey(1, auditoplog.AuditOpTypeAdd, common.BKInnerObjIDHost, contents, "mock desc", common.BKDefaultOwnerID, "user")
+	err := AddLogMultiWithExtKey(1, auditoplog.AuditOpTypeAdd, common.BKInnerObjIDHost, contents, "mock desc", common.BKDefaultOwnerID, "user", "")
 	if err != mockdb.err {
 		t.Error(err)
 	}
@@ -131,7 +134,7 @@ func TestAddLogWithStr(t *testing.T) {
 	}
 	DB = mockdb
 
-	err := AddLogWithStr(1, 0, auditoplog.AuditOpTypeAdd, common.BKInnerObjIDHost, "test TestAddLogWithStr", "key", "mock desc", common.BKDefaultOwnerID, "user")
+	err := AddLogWithStr(1, 0, auditoplog.AuditOpTypeAdd, common.BKInnerObjIDHost, "test TestAddLogWithStr", "key", "mock desc", common.BKDefaultOwnerID, "user", "")
 	if err != mockdb.err {
 		t.Error(err)
 	}
@@ -146,7 +149,7 @@ func TestAddLogWithStrErr(t *testing.T) {
 	}
 	DB = mockdb
 
-	err := AddLogWithStr(1, 0, auditoplog.AuditOpTypeAdd, common.BKInnerObjIDHost, "test TestAddLogWithStr", "key", "mock desc", common.BKDefaultOwnerID, "user")
+	err := AddLogWithStr(1, 0, auditoplog.AuditOpTypeAdd, common.BKInnerObjIDHost, "test TestAddLogWithStr", "key", "mock desc", common.BKDefaultOwnerID, "user", "")
 	if err != mockdb.err {
 		t.Error(err)
 	}
@@ -161,7 +164,7 @@ func TestSearchGetDataErr(t *testing.T) {
 	}
 	DB = mockdb
 	var dat commondata.ObjQueryInput
-	_, _, err := Search(dat)
+	_, _, _, err := Search(dat)
 
 	if mockdb.err != err {
 		t.Error(err)
@@ -178,7 +181,7 @@ func TestSearchGetCntErr(t *testing.T) {
 	}
 	DB = mockdb
 	var dat commondata.ObjQueryInput
-	_, _, err := Search(dat)
+	_, _, _, err := Search(dat)
 
 	if mockdb.err != err {
 		t.Error(err)
@@ -193,7 +196,7 @@ func TestSearch(t *testing.T) {
 	}
 	DB = mockdb
 	var dat commondata.ObjQueryInput
-	_, _, err := Search(dat)
+	_, _, _, err := Search(dat)
 
 	if mockdb.err != err {
 		t.Error(err)
@@ -201,6 +204,89 @@ func TestSearch(t *testing.T) {
 
 }
 
+func TestApplyFilter(t *testing.T) {
+	dat := commondata.ObjQueryInput{
+		Condition: map[string]interface{}{common.BKOwnerIDField: "0"},
+		Filter: querydsl.And(
+			querydsl.NewExpr(querydsl.OpEq, common.BKOpTypeField, float64(1)),
+			querydsl.NewExpr(querydsl.OpPrefix, common.BKOpTargetField, "ho.st"),
+		),
+	}
+	if err := ApplyFilter(&dat); nil != err {
+		t.Fatal(err)
+	}
+	expect := map[string]interface{}{"$and": []interface{}{
+		map[string]interface{}{common.BKOwnerIDField: "0"},
+		map[string]interface{}{"$and": []interface{}{
+			map[string]interface{}{common.BKOpTypeField: float64(1)},
+			map[string]interface{}{common.BKOpTargetField: map[string]interface{}{common.BKDBLIKE: `^ho\.st`}},
+		}},
+	}}
+	if !reflect.DeepEqual(dat.Condition, expect) || nil != dat.Filter {
+		t.Errorf("unexpected condition %v", dat.Condition)
+	}
+
+	for _, filter := range []*querydsl.Expr{
+		querydsl.NewExpr(querydsl.OpEq, "content", "x"),
+		querydsl.NewExpr(querydsl.OpContains, common.BKOpTypeField, "1"),
+		querydsl.NewExpr("$where", common.BKOpTargetField, "true"),
+	} {
+		dat := commondata.ObjQueryInput{Filter: filter}
+		if err := ApplyFilter(&dat); nil == err {
+			t.Errorf("filter %v should be rejected", filter)
+		}
+	}
+}
+
+func TestSearchInMemory(t *testing.T) {
+	db, err := dbclient.NewDB("", "", "", "", "", "", storage.DI_MEMORY, nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+	DB = db
+	for i := 1; i <= 5; i++ {
+		var opType auditoplog.AuditOpType = auditoplog.AuditOpTypeAdd
+		if i%2 == 0 {
+			opType = auditoplog.AuditOpTypeDel
+		}
+		err := AddLogWithStr(1, i, opType, common.BKInnerObjIDHost, map[string]interface{}{"pre_data": nil}, "", "mock desc", common.BKDefaultOwnerID, "user", "")
+		if nil != err {
+			t.Fatal(err)
+		}
+	}
+
+	instIDs := make([]int, 0)
+	dat := commondata.ObjQueryInput{
+		Condition:  map[string]interface{}{common.BKOwnerIDField: common.BKDefaultOwnerID},
+		Filter:     querydsl.NewExpr(querydsl.OpEq, common.BKOpTypeField, float64(auditoplog.AuditOpTypeAdd)),
+		Limit:      2,
+		Sort:       "-" + common.BKInstIDField,
+		WithCursor: true,
+	}
+	if err := ApplyFilter(&dat); nil != err {
+		t.Fatal(err)
+	}
+	for {
+		rows, cnt, next, err := Search(dat)
+		if nil != err {
+			t.Fatal(err)
+		}
+		if 3 != cnt {
+			t.Errorf("unexpected count %d", cnt)
+		}
+		for _, row := range rows {
+			instIDs = append(instIDs, row.InstID)
+		}
+		if "" == next {
+			break
+		}
+		dat.Cursor = next
+	}
+	if !reflect.DeepEqual(instIDs, []int{5, 3, 1}) {
+		t.Errorf("unexpected logs %v", instIDs)
+	}
+}
+
 type mockMongo struct {
 	data           []interface{}
 	err            error
@@ -249,6 +335,13 @@ func (m *mockMongo) GetMutilByCondition(cName string, fields []string, condictio
 	}
 	return nil
 }
+func (m *mockMongo) GetMutilByCursor(cName string, fields []string, condiction interface{}, result interface{}, sort, cursor string, limit int) (string, error) {
+	m.errTriggerStep++
+	if m.errTrigger == m.errTriggerStep {
+		return "", m.err
+	}
+	return "", nil
+}
 func (m *mockMongo) GetCntByCondition(cName string, condiction interface{}) (int, error) {
 	m.errTriggerStep++
 	if m.errTrigger == m.errTriggerStep {
@@ -296,3 +389,9 @@ func (m *mockMongo) Close() {
 func (m *mockMongo) GetSession() interface{} {
 	return nil
 }
+func (m *mockMongo) Ping() error {
+	return nil
+}
+func (m *mockMongo) StartTransaction() (storage.Tx, error) {
+	return storage.NewCompensatingTx(m), nil
+}
@@ -14,6 +14,7 @@ package commondata
 
 import (
 	"configcenter/src/common"
+	"configcenter/src/common/querydsl"
 	"configcenter/src/common/util"
 	"time"
 
@@ -40,6 +41,19 @@ type ObjQueryInput struct {
 	Start     int         `json:"start"`
 	Limit     int         `json:"limit"`
 	Sort      string      `json:"sort"`
+	// Filter the typed condition of the user, validated and merged into the condition by the controller
+	Filter *querydsl.Expr `json:"filter,omitempty"`
+	// Cursor the next_cursor of the last page, the page after it is returned instead of skipping the start
+	Cursor string `json:"cursor,omitempty"`
+	// WithCursor page by the cursor from the first page, the next_cursor is returned with it
+	WithCursor bool `json:"with_cursor,omitempty"`
+	// SkipCount do not count the matched documents
+	SkipCount bool `json:"skip_count,omitempty"`
+}
+
+// UseCursor return whether the page is searched by the cursor, only if the client asks for it
+func (o *ObjQueryInput) UseCursor() bool {
+	return "" != o.Cursor || o.WithCursor
 }
 
 //ConvTime 将查询条件中字段包含cc_type key ，子节点变为time.Time
@@ -82,16 +96,10 @@ func (o *ObjQueryInput) convTimeItem(item interface{}) (interface{}, error) {
 						return nil, err
 					}
 				case map[string]interface{}:
-					arrItemVal, ok := value.(map[string]interface{})
-					if ok {
-						for key, value := range arrItem {
-							var err error
-							arrItemVal[key], err = o.convTimeItem(value)
-							if nil != err {
-								return nil, err
-							}
-						}
-						arrItem[key] = value
+					var err error
+					arrItem[key], err = o.convTimeItem(value)
+					if nil != err {
+						return nil, err
 					}
 
 				default:
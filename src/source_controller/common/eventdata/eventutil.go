@@ -16,6 +16,7 @@ import (
 	"configcenter/src/common"
 	"configcenter/src/common/core/cc/api"
 	commontypes "configcenter/src/common/types"
+	"configcenter/src/common/util"
 	"configcenter/src/scene_server/event_server/types"
 	"encoding/json"
 	"github.com/emicklei/go-restful"
@@ -35,9 +36,12 @@ func NewEventContext(requestID string, requestTime time.Time) *EventContext {
 }
 
 func NewEventContextByReq(req *restful.Request) *EventContext {
-	// TODO get reqid and time from req
+	requestID := util.GetActionRequestID(req)
+	if "" == requestID {
+		requestID = util.GenerateRequestID()
+	}
 	return &EventContext{
-		RequestID:   "xxx-xxxx-xxx-xxx",
+		RequestID:   requestID,
 		RequestTime: commontypes.Now(),
 	}
 }
@@ -13,6 +13,7 @@
 package instdata
 
 import (
+	"configcenter/src/common/util"
 	"configcenter/src/storage"
 )
 
@@ -38,6 +39,7 @@ func DelHostByCondition(condition interface{}) error {
 
 // UpdateHostByCondition update host by condition
 func UpdateHostByCondition(data interface{}, condition interface{}) error {
+	setHostIPList(data)
 	err := DataH.UpdateByCondition("cc_HostBase", data, condition)
 	if nil != err {
 		return err
@@ -61,8 +63,16 @@ func CreateHost(input interface{}, idName *string) (int, error) {
 		return 0, err
 	}
 	inputc := input.(map[string]interface{})
+	setHostIPList(inputc)
 	inputc["ObjectID"] = hostID
 	*idName = "ObjectID"
 	DataH.Insert("cc_HostBase", inputc)
 	return int(hostID), nil
 }
+
+// setHostIPList keep the indexed ip arrays of the host in step with the comma separated ips written
+func setHostIPList(data interface{}) {
+	if host, ok := data.(map[string]interface{}); ok {
+		util.SetHostIPList(host)
+	}
+}
@@ -15,6 +15,7 @@ package instdata
 import (
 	"configcenter/src/common"
 	"configcenter/src/source_controller/common/commondata"
+	"configcenter/src/storage"
 	"errors"
 )
 
@@ -41,6 +42,9 @@ func DelObjByCondition(objType string, condition interface{}) error {
 //UpdateObjByCondition update object by condition
 func UpdateObjByCondition(objType string, data interface{}, condition interface{}) error {
 	tName := commondata.ObjTableMap[objType]
+	if common.BKInnerObjIDHost == objType {
+		setHostIPList(data)
+	}
 	err := DataH.UpdateByCondition(tName, data, condition)
 	if nil != err {
 		return err
@@ -54,6 +58,30 @@ func GetObjectByCondition(objType string, fields []string, condition, result int
 	return DataH.GetMutilByCondition(tName, fields, condition, result, sort, skip, limit)
 }
 
+//GetObjectByCursor get the page of objects after the cursor, return the cursor of the next page
+func GetObjectByCursor(objType string, fields []string, condition, result interface{}, sort, cursor string, limit int) (string, error) {
+	tName := commondata.ObjTableMap[objType]
+	return DataH.GetMutilByCursor(tName, fields, condition, result, sort, cursor, limit)
+}
+
+//SearchObjectByCondition get the objects for the search apis, the search may read from the secondary members
+func SearchObjectByCondition(objType string, fields []string, condition, result interface{}, sort string, skip, limit int) error {
+	tName := commondata.ObjTableMap[objType]
+	return storage.Secondary(DataH).GetMutilByCondition(tName, fields, condition, result, sort, skip, limit)
+}
+
+//SearchObjectByCursor get the page of objects after the cursor for the search apis, the search may read from the secondary members
+func SearchObjectByCursor(objType string, fields []string, condition, result interface{}, sort, cursor string, limit int) (string, error) {
+	tName := commondata.ObjTableMap[objType]
+	return storage.Secondary(DataH).GetMutilByCursor(tName, fields, condition, result, sort, cursor, limit)
+}
+
+//SearchCntByCondition get the count of the objects for the search apis, the count may read from the secondary members
+func SearchCntByCondition(objType string, condition interface{}) (int, error) {
+	tName := commondata.ObjTableMap[objType]
+	return storage.Secondary(DataH).GetCntByCondition(tName, condition)
+}
+
 //CreateObject add new object
 func CreateObject(objType string, input interface{}, idName *string) (int, error) {
 	tName := commondata.ObjTableMap[objType]
@@ -62,6 +90,9 @@ func CreateObject(objType string, input interface{}, idName *string) (int, error
 		return 0, err
 	}
 	inputc := input.(map[string]interface{})
+	if common.BKInnerObjIDHost == objType {
+		setHostIPList(inputc)
+	}
 	*idName = GetIDNameByType(objType)
 	inputc[*idName] = objID
 	DataH.Insert(tName, inputc)
@@ -22,6 +22,7 @@ import (
 	"configcenter/src/source_controller/common/commondata"
 	"configcenter/src/source_controller/common/eventdata"
 	"configcenter/src/source_controller/common/instdata"
+	"configcenter/src/storage"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
@@ -132,18 +133,32 @@ func (cli *hostAction) GetHosts(req *restful.Request, resp *restful.Response) {
 		sort := dat.Sort
 		fieldArr := strings.Split(fields, ",")
 		result := make([]interface{}, 0)
-		count, err := instdata.GetCntByCondition(objType, condition)
-		if err != nil {
-			blog.Error("get object type:%s,input:%v error:%v", objType, value, err)
-			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrHostSelectInst)
+		info := make(map[string]interface{})
+		if dat.UseCursor() {
+			nextCursor, err := instdata.SearchObjectByCursor(objType, fieldArr, condition, &result, sort, dat.Cursor, limit)
+			if err == storage.ErrInvalidCursor {
+				return http.StatusBadRequest, nil, defErr.Errorf(common.CCErrCommParamsInvalid, "cursor")
+			}
+			if err != nil {
+				blog.Error("get object type:%s,input:%v error:%v", objType, value, err)
+				return http.StatusInternalServerError, nil, defErr.Error(common.CCErrHostSelectInst)
+			}
+			info["next_cursor"] = nextCursor
+		} else {
+			err = instdata.SearchObjectByCondition(objType, fieldArr, condition, &result, sort, start, limit)
+			if err != nil {
+				blog.Error("get object type:%s,input:%v error:%v", objType, value, err)
+				return http.StatusInternalServerError, nil, defErr.Error(common.CCErrHostSelectInst)
+			}
 		}
-		err = instdata.GetObjectByCondition(objType, fieldArr, condition, &result, sort, start, limit)
-		if err != nil {
-			blog.Error("get object type:%s,input:%v error:%v", objType, value, err)
-			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrHostSelectInst)
+		if !dat.SkipCount {
+			count, err := instdata.SearchCntByCondition(objType, condition)
+			if err != nil {
+				blog.Error("get object type:%s,input:%v error:%v", objType, value, err)
+				return http.StatusInternalServerError, nil, defErr.Error(common.CCErrHostSelectInst)
+			}
+			info["count"] = count
 		}
-		info := make(map[string]interface{})
-		info["count"] = count
 		info["info"] = result
 		return http.StatusOK, info, nil
 	}, resp)
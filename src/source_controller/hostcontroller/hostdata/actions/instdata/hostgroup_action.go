@@ -0,0 +1,153 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package instdata
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/base"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/core/cc/actions"
+	"configcenter/src/common/core/cc/api"
+	"configcenter/src/common/util"
+	"configcenter/src/source_controller/common/eventdata"
+	"configcenter/src/source_controller/hostcontroller/hostdata/logics"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/emicklei/go-restful"
+)
+
+var hostGroup *hostGroupAction = &hostGroupAction{}
+
+type hostGroupAction struct {
+	base.BaseAction
+}
+
+type hostGroupMembersParams struct {
+	Members []logics.HostGroupMember `json:"members"`
+}
+
+type hostGroupDueParams struct {
+	AppIDs  []int `json:"bk_biz_id"`
+	HostIDs []int `json:"bk_host_id"`
+}
+
+//SearchDynamic search the custom queries marked as dynamic of all the businesses
+func (cli *hostGroupAction) SearchDynamic(req *restful.Request, resp *restful.Response) {
+	language := util.GetActionLanguage(req)
+	defErr := cli.CC.Error.CreateDefaultCCErrorIf(language)
+
+	cli.CallResponseEx(func() (int, interface{}, error) {
+		fields := []string{"id", common.BKAppIDField, "name", "info", "eval_interval", "eval_time", common.BKOwnerIDField}
+		result := make([]map[string]interface{}, 0)
+		if err := cli.CC.InstCli.GetMutilByCondition(userAPI.tableName, fields, map[string]interface{}{"dynamic": true}, &result, common.CreateTimeField, 0, 0); nil != err {
+			blog.Errorf("search dynamic host groups error:%v", err)
+			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrCommDBSelectFailed)
+		}
+		return http.StatusOK, map[string]interface{}{"count": len(result), "info": result}, nil
+	}, resp)
+}
+
+//MarkDue mark the dynamic host groups of the businesses and of the businesses the hosts belong to to be evaluated again
+func (cli *hostGroupAction) MarkDue(req *restful.Request, resp *restful.Response) {
+	language := util.GetActionLanguage(req)
+	defErr := cli.CC.Error.CreateDefaultCCErrorIf(language)
+
+	cli.CallResponseEx(func() (int, interface{}, error) {
+		value, err := ioutil.ReadAll(req.Request.Body)
+		if err != nil {
+			return http.StatusBadRequest, nil, defErr.Error(common.CCErrCommHTTPReadBodyFailed)
+		}
+		params := hostGroupDueParams{}
+		if err := json.Unmarshal(value, &params); nil != err {
+			blog.Error("fail to unmarshal json, error information is %v", err)
+			return http.StatusBadRequest, nil, defErr.Error(common.CCErrCommJSONUnmarshalFailed)
+		}
+		if err := logics.MarkHostGroupsDue(api.NewAPIResource(), params.AppIDs, params.HostIDs); nil != err {
+			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrCommDBUpdateFailed)
+		}
+		return http.StatusOK, nil, nil
+	}, resp)
+}
+
+//GetMembers get the stored members of the dynamic host group
+func (cli *hostGroupAction) GetMembers(req *restful.Request, resp *restful.Response) {
+	language := util.GetActionLanguage(req)
+	defErr := cli.CC.Error.CreateDefaultCCErrorIf(language)
+
+	cli.CallResponseEx(func() (int, interface{}, error) {
+		appID, _ := util.GetIntByInterface(req.PathParameter(common.BKAppIDField))
+		group := logics.HostGroup{AppID: appID, ID: req.PathParameter("id")}
+		members, err := logics.GetHostGroupMembers(api.NewAPIResource(), group)
+		if nil != err {
+			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrCommDBSelectFailed)
+		}
+		return http.StatusOK, map[string]interface{}{"count": len(members), "info": members}, nil
+	}, resp)
+}
+
+//SetMembers replace the members of the dynamic host group with the evaluated ones
+func (cli *hostGroupAction) SetMembers(req *restful.Request, resp *restful.Response) {
+	language := util.GetActionLanguage(req)
+	defErr := cli.CC.Error.CreateDefaultCCErrorIf(language)
+
+	cli.CallResponseEx(func() (int, interface{}, error) {
+		cc := api.NewAPIResource()
+		value, err := ioutil.ReadAll(req.Request.Body)
+		if err != nil {
+			return http.StatusBadRequest, nil, defErr.Error(common.CCErrCommHTTPReadBodyFailed)
+		}
+		params := hostGroupMembersParams{}
+		if err := json.Unmarshal(value, &params); nil != err {
+			blog.Error("fail to unmarshal json, error information is %v", err)
+			return http.StatusBadRequest, nil, defErr.Error(common.CCErrCommJSONUnmarshalFailed)
+		}
+
+		appID, _ := util.GetIntByInterface(req.PathParameter(common.BKAppIDField))
+		condition := map[string]interface{}{common.BKAppIDField: appID, "id": req.PathParameter("id")}
+		detail := make(map[string]interface{})
+		if err := cc.InstCli.GetOneByCondition(userAPI.tableName, []string{"name", "dynamic"}, condition, &detail); nil != err {
+			if mgo_on_not_found_error == err.Error() {
+				return http.StatusBadRequest, nil, defErr.Error(common.CCErrCommNotFound)
+			}
+			blog.Errorf("get host group error:%v, condition:%v", err, condition)
+			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrCommDBSelectFailed)
+		}
+		// the group is no longer dynamic after it was evaluated
+		if dynamic, _ := detail["dynamic"].(bool); !dynamic {
+			params.Members = nil
+		}
+		name, _ := detail["name"].(string)
+		group := logics.HostGroup{AppID: appID, ID: req.PathParameter("id"), Name: name}
+
+		ec := eventdata.NewEventContextByReq(req)
+		joined, left, err := logics.SetHostGroupMembers(ec, cc, group, params.Members)
+		if nil != err {
+			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrCommDBUpdateFailed)
+		}
+		if err := cc.InstCli.UpdateByCondition(userAPI.tableName, map[string]interface{}{"eval_time": time.Now()}, condition); nil != err {
+			blog.Errorf("update host group eval time error:%v, condition:%v", err, condition)
+		}
+		return http.StatusOK, map[string]interface{}{"join": len(joined), "leave": len(left)}, nil
+	}, resp)
+}
+
+func init() {
+	hostGroup.CreateAction()
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/userapi/dynamic/search", Params: nil, Handler: hostGroup.SearchDynamic})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/userapi/dynamic/due", Params: nil, Handler: hostGroup.MarkDue})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectGet, Path: "/userapi/members/{bk_biz_id}/{id}", Params: nil, Handler: hostGroup.GetMembers})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/userapi/members/{bk_biz_id}/{id}", Params: nil, Handler: hostGroup.SetMembers})
+}
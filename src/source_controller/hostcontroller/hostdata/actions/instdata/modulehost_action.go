@@ -168,6 +168,60 @@ func (cli *moduleHostConfigAction) DelModuleHostConfig(req *restful.Request, res
 	}, resp)
 }
 
+//TransferModuleHostConfig transfer the hosts to the modules, all or nothing
+func (cli *moduleHostConfigAction) TransferModuleHostConfig(req *restful.Request, resp *restful.Response) {
+	// get the language
+	language := util.GetActionLanguage(req)
+	// get the error factory by the language
+	defErr := cli.CC.Error.CreateDefaultCCErrorIf(language)
+
+	cli.CallResponseEx(func() (int, interface{}, error) {
+
+		type paramsStruct struct {
+			ApplicationID int   `json:"bk_biz_id"`
+			HostID        []int `json:"bk_host_id"`
+			ModuleID      []int `json:"bk_module_id"`
+			IsIncrement   bool  `json:"is_increment"`
+		}
+
+		cc := api.NewAPIResource()
+		value, err := ioutil.ReadAll(req.Request.Body)
+		if err != nil {
+			return http.StatusBadRequest, nil, defErr.Error(common.CCErrCommHTTPReadBodyFailed)
+		}
+
+		params := paramsStruct{}
+		if err := json.Unmarshal([]byte(value), &params); nil != err {
+			blog.Errorf("fail to unmarshal json, error information is %v", err)
+			return http.StatusBadRequest, nil, defErr.Error(common.CCErrCommJSONUnmarshalFailed)
+		}
+
+		transfer := &logics.ModuleHostTransfer{
+			HostIDs:      params.HostID,
+			SrcAppID:     params.ApplicationID,
+			DstAppID:     params.ApplicationID,
+			DstModuleIDs: params.ModuleID,
+		}
+		// only remove the hosts from the idle and fault modules when increment
+		if params.IsIncrement {
+			defaultModuleIDs, err := logics.GetDefaultModuleIDs(cc, params.ApplicationID)
+			if nil != err {
+				blog.Errorf("defaultModuleIds appID:%d, error:%v", params.ApplicationID, err)
+				return http.StatusInternalServerError, nil, defErr.Error(common.CCErrGetModule)
+			}
+			transfer.SrcModuleIDs = defaultModuleIDs
+		}
+
+		ec := eventdata.NewEventContextByReq(req)
+		if err := logics.TransferHostModuleRelation(ec, cc, transfer); nil != err {
+			blog.Errorf("transfer host %v to module %v error:%v", params.HostID, params.ModuleID, err)
+			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrHostTransferModule)
+		}
+
+		return http.StatusOK, nil, nil
+	}, resp)
+}
+
 //GetHostModulesIDs get host module ids
 func (cli *moduleHostConfigAction) GetHostModulesIDs(req *restful.Request, resp *restful.Response) {
 	// get the language
@@ -336,28 +390,16 @@ func (cli *moduleHostConfigAction) MoveHost2ResourcePool(req *restful.Request, r
 			blog.Errorf("主机属于空闲机以外的模块 %v", data)
 			return http.StatusInternalServerError, data, defErr.Error(common.CCErrNotBelongToIdleModule)
 		}
-		var succ, addErr, delErr []int
-		for _, hostID := range params.HostID {
-			//add new host
-			_, err = logics.AddSingleHostModuleRelation(ec, cc, hostID, params.OwnerModuleID, params.OwnerAppplicationID)
-			if nil != err {
-				addErr = append(addErr, hostID)
-				continue
-			}
-			//delete origin relation
-			_, err := logics.DelSingleHostModuleRelation(ec, cc, hostID, idleModuleID, params.ApplicationID)
-			if nil != err {
-				delErr = append(delErr, hostID)
-				continue
-			}
-
-			succ = append(succ, hostID)
+		transfer := &logics.ModuleHostTransfer{
+			HostIDs:      params.HostID,
+			SrcAppID:     params.ApplicationID,
+			SrcModuleIDs: []int{idleModuleID},
+			DstAppID:     params.OwnerAppplicationID,
+			DstModuleIDs: []int{params.OwnerModuleID},
 		}
-		if 0 != len(addErr) || 0 != len(delErr) {
-			addErr = append(addErr, delErr...)
-			data := common.KvMap{"成功": succ, "失败": addErr}
-			blog.Errorf("主机属于空闲机以外的模块 %v", data)
-			return http.StatusInternalServerError, data, defErr.Error(common.CCErrTransfer2ResourcePool)
+		if err := logics.TransferHostModuleRelation(ec, cc, transfer); nil != err {
+			blog.Errorf("transfer host %v to resource pool error:%v", params.HostID, err)
+			return http.StatusInternalServerError, nil, defErr.Error(common.CCErrTransfer2ResourcePool)
 		}
 
 		return http.StatusOK, nil, nil
@@ -370,6 +412,7 @@ func init() {
 	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/meta/hosts/modules", Params: nil, Handler: moduleHostConfigActionCli.AddModuleHostConfig})
 	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/meta/hosts/modules", Params: nil, Handler: moduleHostConfigActionCli.DelModuleHostConfig})
 	actions.RegisterNewAction(actions.Action{Verb: common.HTTPDelete, Path: "/meta/hosts/defaultmodules", Params: nil, Handler: moduleHostConfigActionCli.DelDefaultModuleHostConfig})
+	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/meta/hosts/transfer", Params: nil, Handler: moduleHostConfigActionCli.TransferModuleHostConfig})
 	actions.RegisterNewAction(actions.Action{Verb: common.HTTPUpdate, Path: "/meta/hosts/resource", Params: nil, Handler: moduleHostConfigActionCli.MoveHost2ResourcePool})
 	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/meta/hosts/assign", Params: nil, Handler: moduleHostConfigActionCli.AssignHostToApp})
 	actions.RegisterNewAction(actions.Action{Verb: common.HTTPSelectPost, Path: "/meta/hosts/module/config/search", Params: nil, Handler: moduleHostConfigActionCli.GetModulesHostConfig})
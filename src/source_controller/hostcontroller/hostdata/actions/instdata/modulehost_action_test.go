@@ -0,0 +1,130 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+
+package instdata
+
+import (
+	"bytes"
+	"configcenter/src/common"
+	"configcenter/src/common/core/cc/actions"
+	"configcenter/src/common/core/cc/api"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/httpserver"
+	eventtypes "configcenter/src/scene_server/event_server/types"
+	metadataTable "configcenter/src/source_controller/api/metadata"
+	"configcenter/src/source_controller/common/instdata"
+	"configcenter/src/storage/memclient"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newModuleHostServer serve the actions of the host controller on the in-memory db with the business 1,
+// its idle module 3, fault module 4 and module 5 of the set 2, the host 1 is in the idle module.
+// the events are pushed into the in-memory cache returned
+func newModuleHostServer(t *testing.T) (*httptest.Server, *memclient.MemRedis) {
+	db := memclient.NewMemDB()
+	rows := []struct {
+		table string
+		data  map[string]interface{}
+	}{
+		{moduleBaseTaleName, map[string]interface{}{common.BKAppIDField: 1, common.BKSetIDField: 1, common.BKModuleIDField: 3, common.BKModuleNameField: "idle", common.BKDefaultField: common.DefaultResModuleFlag}},
+		{moduleBaseTaleName, map[string]interface{}{common.BKAppIDField: 1, common.BKSetIDField: 1, common.BKModuleIDField: 4, common.BKModuleNameField: "fault", common.BKDefaultField: common.DefaultFaultModuleFlag}},
+		{moduleBaseTaleName, map[string]interface{}{common.BKAppIDField: 1, common.BKSetIDField: 2, common.BKModuleIDField: 5, common.BKModuleNameField: "web", common.BKDefaultField: 0}},
+		{"cc_HostBase", map[string]interface{}{common.BKHostIDField: 1, common.BKHostInnerIPField: "127.0.0.1"}},
+		{metadataTable.ModuleHostConfig{}.TableName(), map[string]interface{}{common.BKAppIDField: 1, common.BKSetIDField: 1, common.BKModuleIDField: 3, common.BKHostIDField: 1}},
+	}
+	for _, row := range rows {
+		_, err := db.Insert(row.table, row.data)
+		require.NoError(t, err)
+	}
+	cache := memclient.NewMemRedis()
+	a := api.GetAPIResource()
+	a.InstCli = db
+	a.CacheCli = cache
+	a.Cache = cache
+	a.Error = errors.NewFromCtx(map[string]errors.ErrorCode{})
+	instdata.DataH = db
+
+	serv := httpserver.NewHttpServer(0, "", "")
+	require.NoError(t, serv.RegisterWebServer("/host/{version}", nil, actions.GetAPIAction()))
+	return httptest.NewServer(serv.GetWebContainer()), cache
+}
+
+// doModuleHost request the action and return the reply
+func doModuleHost(t *testing.T, srv *httptest.Server, method, path string, body interface{}) api.BKAPIRsp {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+	req, err := http.NewRequest(method, srv.URL+"/host/v1"+path, bytes.NewReader(data))
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	rsp := api.BKAPIRsp{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&rsp))
+	return rsp
+}
+
+// hostModules return the modules of the host 1 searched by the action
+func hostModules(t *testing.T, srv *httptest.Server) []interface{} {
+	rsp := doModuleHost(t, srv, common.HTTPSelectPost, "/meta/hosts/modules/search", map[string]interface{}{common.BKAppIDField: 1, common.BKHostIDField: 1})
+	require.True(t, rsp.Result, "%v", rsp.Message)
+	modules, _ := rsp.Data.([]interface{})
+	return modules
+}
+
+func TestModuleHostConfigAction(t *testing.T) {
+	srv, cache := newModuleHostServer(t)
+	defer srv.Close()
+	params := map[string]interface{}{common.BKAppIDField: 1, common.BKHostIDField: 1, common.BKModuleIDField: []int{5}}
+
+	rsp := doModuleHost(t, srv, common.HTTPCreate, "/meta/hosts/modules", params)
+	require.True(t, rsp.Result, "%v", rsp.Message)
+	require.Equal(t, []interface{}{float64(3), float64(5)}, hostModules(t, srv))
+
+	rsp = doModuleHost(t, srv, common.HTTPDelete, "/meta/hosts/defaultmodules", params)
+	require.True(t, rsp.Result, "%v", rsp.Message)
+	require.Equal(t, []interface{}{float64(5)}, hostModules(t, srv))
+
+	rsp = doModuleHost(t, srv, common.HTTPDelete, "/meta/hosts/modules", params)
+	require.True(t, rsp.Result, "%v", rsp.Message)
+	require.Empty(t, hostModules(t, srv))
+
+	events, err := cache.LLen(eventtypes.EventCacheEventQueueKey)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), events)
+}
+
+func TestModuleHostConfigActionUnknownModule(t *testing.T) {
+	srv, _ := newModuleHostServer(t)
+	defer srv.Close()
+
+	rsp := doModuleHost(t, srv, common.HTTPCreate, "/meta/hosts/modules", map[string]interface{}{common.BKAppIDField: 1, common.BKHostIDField: 1, common.BKModuleIDField: []int{6}})
+	require.False(t, rsp.Result)
+	require.Equal(t, common.CCErrHostTransferModule, rsp.Code)
+	require.Equal(t, []interface{}{float64(3)}, hostModules(t, srv))
+}
+
+func TestTransferModuleHostConfigAction(t *testing.T) {
+	srv, _ := newModuleHostServer(t)
+	defer srv.Close()
+
+	rsp := doModuleHost(t, srv, common.HTTPUpdate, "/meta/hosts/transfer", map[string]interface{}{
+		common.BKAppIDField: 1, common.BKHostIDField: []int{1}, common.BKModuleIDField: []int{5}, "is_increment": true,
+	})
+	require.True(t, rsp.Result, "%v", rsp.Message)
+	require.Equal(t, []interface{}{float64(5)}, hostModules(t, srv))
+}
@@ -17,8 +17,11 @@ import (
 	"configcenter/src/common/core/cc/actions"
 	"configcenter/src/common/base"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/core/cc/api"
 	"configcenter/src/common/util"
 	"configcenter/src/source_controller/common/commondata"
+	"configcenter/src/source_controller/common/eventdata"
+	"configcenter/src/source_controller/hostcontroller/hostdata/logics"
 	"encoding/json"
 	"io"
 	"io/ioutil"
@@ -30,7 +33,7 @@ import (
 	"github.com/rs/xid"
 )
 
-var userAPI *userAPIAction = &userAPIAction{tableName: "cc_UserAPI"}
+var userAPI *userAPIAction = &userAPIAction{tableName: logics.UserAPITableName}
 
 type userAPIAction struct {
 	base.BaseAction
@@ -148,12 +151,22 @@ func (u *userAPIAction) Update(req *restful.Request, resp *restful.Response) {
 	}
 	//json 中的数字会被转换未doubule， 转换未int64
 	data[common.BKAppIDField] = appID
+	// the dynamic host group is due once its query changes
+	if _, ok := data["info"]; ok {
+		data["eval_time"] = time.Time{}
+	} else if _, ok := data["dynamic"]; ok {
+		data["eval_time"] = time.Time{}
+	}
 	err = u.CC.InstCli.UpdateByCondition(u.tableName, data, params)
 	if nil != err {
 		blog.Error("updata user api fail, error information is %s, params:%v", err.Error(), params)
 		userAPI.ResponseFailedEx(http.StatusBadGateway, common.CCErrCommDBUpdateFailed, defErr.Errorf(common.CCErrCommDBUpdateFailed).Error(), resp)
 		return
 	}
+	// the hosts leave the group once it is no longer dynamic
+	if dynamic, ok := data["dynamic"].(bool); ok && !dynamic {
+		u.clearMembers(req, u.hostGroup(appID, ID))
+	}
 	rsp, _ := u.CC.CreateAPIRspStr(common.CCSuccess, nil)
 	io.WriteString(resp, rsp)
 	return
@@ -183,12 +196,14 @@ func (u *userAPIAction) Delete(req *restful.Request, resp *restful.Response) {
 		userAPI.ResponseFailedEx(http.StatusBadRequest, common.CCErrCommNotFound, defErr.Error(common.CCErrCommNotFound).Error(), resp)
 		return
 	}
+	group := u.hostGroup(appID, ID)
 	err = u.CC.InstCli.DelByCondition(u.tableName, params)
 	if nil != err {
 		blog.Error("delete user api fail, error information is %s, params:%v", err.Error(), params)
 		userAPI.ResponseFailedEx(http.StatusBadGateway, common.CCErrCommDBDeleteFailed, defErr.Errorf(common.CCErrCommDBDeleteFailed).Error(), resp)
 		return
 	}
+	u.clearMembers(req, group)
 	rsp, _ := u.CC.CreateAPIRspStr(common.CCSuccess, nil)
 	io.WriteString(resp, rsp)
 	return
@@ -293,6 +308,24 @@ func (u *userAPIAction) Detail(req *restful.Request, resp *restful.Response) {
 
 }
 
+// hostGroup return the user api as the host group, the name is sent with the hostgroup events
+func (u *userAPIAction) hostGroup(appID int64, ID string) logics.HostGroup {
+	result := make(map[string]interface{})
+	params := map[string]interface{}{common.BKAppIDField: appID, "id": ID}
+	if err := u.CC.InstCli.GetOneByCondition(u.tableName, []string{"name"}, params, &result); nil != err {
+		blog.Errorf("get user api name error:%v, params:%v", err, params)
+	}
+	name, _ := result["name"].(string)
+	return logics.HostGroup{AppID: int(appID), ID: ID, Name: name}
+}
+
+// clearMembers remove the members of the dynamic host group, the hostgroup leave events are sent
+func (u *userAPIAction) clearMembers(req *restful.Request, group logics.HostGroup) {
+	if _, _, err := logics.SetHostGroupMembers(eventdata.NewEventContextByReq(req), api.NewAPIResource(), group, nil); nil != err {
+		blog.Errorf("clear host group %s members error:%v", group.ID, err)
+	}
+}
+
 func init() {
 	userAPI.CreateAction()
 	actions.RegisterNewAction(actions.Action{Verb: common.HTTPCreate, Path: "/userapi", Params: nil, Handler: userAPI.Add})
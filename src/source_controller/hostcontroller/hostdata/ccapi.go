@@ -16,11 +16,12 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/api"
 	"configcenter/src/common/core/cc/config"
+	"configcenter/src/common/discovery"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/http/httpserver"
+	"configcenter/src/common/types"
 	"configcenter/src/source_controller/common/instdata"
 	confCenter "configcenter/src/source_controller/hostcontroller/hostdata/config"
-	"configcenter/src/source_controller/hostcontroller/hostdata/rdiscover"
 	"configcenter/src/storage"
 	"sync"
 	"time"
@@ -30,7 +31,7 @@ import (
 type CCAPIServer struct {
 	conf     *config.CCAPIConfig
 	httpServ *httpserver.HttpServer
-	rd       *rdiscover.RegDiscover
+	rd       *discovery.RegDiscover
 	cfCenter *confCenter.ConfCenter
 }
 
@@ -50,10 +51,10 @@ func NewCCAPIServer(conf *config.CCAPIConfig) (*CCAPIServer, error) {
 	a.InitAction()
 
 	//RDiscover
-	s.rd = rdiscover.NewRegDiscover(s.conf.RegDiscover, addr, port, false)
+	s.rd = discovery.NewRegDiscover(s.conf.GetRegDiscover(), types.CC_MODULE_HOSTCONTROLLER, addr, port, false, discovery.Options{})
 
 	//ConfCenter
-	s.cfCenter = confCenter.NewConfCenter(s.conf.RegDiscover)
+	s.cfCenter = confCenter.NewConfCenter(s.conf.GetRegDiscover())
 
 	return s, nil
 }
@@ -130,6 +131,9 @@ func (ccAPI *CCAPIServer) Start() error {
 		if err != nil {
 			blog.Error("connect mongodb error exit! err:%s", err.Error())
 			chErr <- err
+		} else if err := storage.RecoverTransactions(a.InstCli); nil != err {
+			// the transactions left by the crashed processes are rolled back before serving
+			blog.Errorf("recover transactions error:%v", err)
 		}
 		instdata.DataH = a.InstCli
 		wg.Done()
@@ -172,5 +176,6 @@ func (ccAPI *CCAPIServer) Start() error {
 func (ccAPI *CCAPIServer) initHttpServ() error {
 	a := api.NewAPIResource()
 	ccAPI.httpServ.RegisterWebServer("/host/{version}", nil, a.Actions)
+	a.RegisterHealth(ccAPI.httpServ)
 	return nil
 }
@@ -0,0 +1,229 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package logics
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/core/cc/api"
+	"configcenter/src/common/util"
+	eventtypes "configcenter/src/scene_server/event_server/types"
+	metadataTable "configcenter/src/source_controller/api/metadata"
+	"configcenter/src/source_controller/common/eventdata"
+	"configcenter/src/storage"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+// HostGroupMemberTableName the members of the dynamic host groups
+const HostGroupMemberTableName = "cc_HostGroupMember"
+
+// UserAPITableName the custom queries, the dynamic host groups are the ones marked as dynamic
+const UserAPITableName = "cc_UserAPI"
+
+// hostGroupLockPrefix the cache lock serializes the member changes of a group
+const hostGroupLockPrefix = "cc_host_group_lock:"
+
+// ErrHostGroupLocked the members of the group are being set by the other
+var ErrHostGroupLocked = errors.New("the host group members are being set")
+
+// HostGroup the dynamic host group, it is the saved custom query marked as dynamic
+type HostGroup struct {
+	AppID int    `json:"bk_biz_id"`
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+}
+
+// HostGroupMember the host matched by the query of the dynamic host group
+type HostGroupMember struct {
+	HostID  int    `json:"bk_host_id"`
+	InnerIP string `json:"bk_host_innerip"`
+}
+
+// DiffHostGroupMembers return the hosts of members not in stored as joined, the hosts of stored not in members as left
+func DiffHostGroupMembers(stored, members []HostGroupMember) (joined, left []HostGroupMember) {
+	storedIDs := make(map[int]bool, len(stored))
+	for _, member := range stored {
+		storedIDs[member.HostID] = true
+	}
+	memberIDs := make(map[int]bool, len(members))
+	for _, member := range members {
+		if memberIDs[member.HostID] {
+			continue
+		}
+		memberIDs[member.HostID] = true
+		if !storedIDs[member.HostID] {
+			joined = append(joined, member)
+		}
+	}
+	for _, member := range stored {
+		if !memberIDs[member.HostID] {
+			left = append(left, member)
+		}
+	}
+	return joined, left
+}
+
+// memberReader the storage or the transaction the members are read from
+type memberReader interface {
+	GetMutilByCondition(cName string, fields []string, condiction interface{}, result interface{}, sort string, start, limit int) error
+}
+
+// GetHostGroupMembers return the stored members of the group
+func GetHostGroupMembers(cc *api.APIResource, group HostGroup) ([]HostGroupMember, error) {
+	return readHostGroupMembers(cc.InstCli, group)
+}
+
+// readHostGroupMembers read the stored members of the group
+func readHostGroupMembers(db memberReader, group HostGroup) ([]HostGroupMember, error) {
+	condition := map[string]interface{}{common.BKAppIDField: group.AppID, "id": group.ID}
+	rows := make([]map[string]interface{}, 0)
+	err := db.GetMutilByCondition(HostGroupMemberTableName, []string{common.BKHostIDField, common.BKHostInnerIPField}, condition, &rows, common.BKHostIDField, 0, 0)
+	if nil != err {
+		blog.Errorf("get host group %s members error:%v", group.ID, err)
+		return nil, err
+	}
+	members := make([]HostGroupMember, 0, len(rows))
+	for _, row := range rows {
+		hostID, _ := util.GetIntByInterface(row[common.BKHostIDField])
+		innerIP, _ := row[common.BKHostInnerIPField].(string)
+		members = append(members, HostGroupMember{HostID: hostID, InnerIP: innerIP})
+	}
+	return members, nil
+}
+
+// SetHostGroupMembers replace the stored members of the group in one transaction,
+// the hostgroup join and leave events are sent after committed. The host servers may evaluate the same group
+// at the same time, the changes of a group are serialized by the cache lock so that each join and leave is sent
+// once, ErrHostGroupLocked is returned if the group is locked by the other
+func SetHostGroupMembers(ec *eventdata.EventContext, cc *api.APIResource, group HostGroup, members []HostGroupMember) (joined, left []HostGroupMember, err error) {
+	key := fmt.Sprintf("%s%d:%s", hostGroupLockPrefix, group.AppID, group.ID)
+	token := xid.New().String()
+	locked, err := cc.Cache.Lock(key, token, time.Minute)
+	if nil != err {
+		blog.Errorf("set host group %s members lock error:%v", group.ID, err)
+		return nil, nil, err
+	}
+	if false == locked {
+		return nil, nil, ErrHostGroupLocked
+	}
+	defer cc.Cache.Unlock(key, token)
+
+	tx, err := cc.InstCli.StartTransaction()
+	if nil != err {
+		blog.Errorf("set host group %s members start transaction error:%v", group.ID, err)
+		return nil, nil, err
+	}
+	stored, err := readHostGroupMembers(tx, group)
+	if nil != err {
+		return nil, nil, rollbackHostGroup(tx, group, err)
+	}
+	joined, left = DiffHostGroupMembers(stored, members)
+	if 0 == len(joined) && 0 == len(left) {
+		return joined, left, tx.Commit()
+	}
+	if 0 != len(left) {
+		hostIDs := make([]int, 0, len(left))
+		for _, member := range left {
+			hostIDs = append(hostIDs, member.HostID)
+		}
+		condition := map[string]interface{}{common.BKAppIDField: group.AppID, "id": group.ID, common.BKHostIDField: common.KvMap{common.BKDBIN: hostIDs}}
+		if err := tx.DelByCondition(HostGroupMemberTableName, condition); nil != err {
+			blog.Errorf("set host group %s members del error:%v, condition:%v", group.ID, err, condition)
+			return nil, nil, rollbackHostGroup(tx, group, err)
+		}
+	}
+	now := time.Now()
+	inserted := make([]HostGroupMember, 0, len(joined))
+	for _, member := range joined {
+		row := hostGroupEventData(group, member)
+		row[common.CreateTimeField] = now
+		if _, err := tx.Insert(HostGroupMemberTableName, row); nil != err {
+			// the unique index of bk_biz_id, id and bk_host_id still guards the members written without the lock
+			if storage.IsDuplicated(err) {
+				blog.Infof("set host group %s members skip host %d joined already", group.ID, member.HostID)
+				continue
+			}
+			blog.Errorf("set host group %s members insert error:%v, data:%v", group.ID, err, row)
+			return nil, nil, rollbackHostGroup(tx, group, err)
+		}
+		inserted = append(inserted, member)
+	}
+	joined = inserted
+	if err := tx.Commit(); nil != err {
+		blog.Errorf("set host group %s members commit error:%v", group.ID, err)
+		return nil, nil, err
+	}
+
+	// send events
+	for _, member := range left {
+		if err := ec.InsertEvent(eventtypes.EventTypeRelation, "hostgroup", eventtypes.EventActionLeave, nil, hostGroupEventData(group, member)); err != nil {
+			blog.Errorf("create event error:%v", err)
+		}
+	}
+	for _, member := range joined {
+		if err := ec.InsertEvent(eventtypes.EventTypeRelation, "hostgroup", eventtypes.EventActionJoin, hostGroupEventData(group, member), nil); err != nil {
+			blog.Errorf("create event error:%v", err)
+		}
+	}
+	return joined, left, nil
+}
+
+// MarkHostGroupsDue clear the eval_time of the dynamic host groups of the businesses and of the businesses
+// the hosts belong to, any host server evaluates them at its next check
+func MarkHostGroupsDue(cc *api.APIResource, appIDs, hostIDs []int) error {
+	if 0 != len(hostIDs) {
+		rows := make([]map[string]interface{}, 0)
+		condition := map[string]interface{}{common.BKHostIDField: common.KvMap{common.BKDBIN: hostIDs}}
+		if err := cc.InstCli.GetMutilByCondition(metadataTable.ModuleHostConfig{}.TableName(), []string{common.BKAppIDField}, condition, &rows, "", 0, 0); nil != err {
+			blog.Errorf("get the businesses of the hosts %v error:%v", hostIDs, err)
+			return err
+		}
+		for _, row := range rows {
+			if appID, err := util.GetIntByInterface(row[common.BKAppIDField]); nil == err {
+				appIDs = append(appIDs, appID)
+			}
+		}
+	}
+	if 0 == len(appIDs) {
+		return nil
+	}
+	condition := map[string]interface{}{"dynamic": true, common.BKAppIDField: common.KvMap{common.BKDBIN: appIDs}}
+	if err := cc.InstCli.UpdateByCondition(UserAPITableName, map[string]interface{}{"eval_time": time.Time{}}, condition); nil != err {
+		blog.Errorf("mark the host groups due error:%v, condition:%v", err, condition)
+		return err
+	}
+	return nil
+}
+
+// rollbackHostGroup undo the member changes, the cause is returned
+func rollbackHostGroup(tx storage.Tx, group HostGroup, cause error) error {
+	if err := tx.Rollback(); nil != err {
+		blog.Errorf("set host group %s members rollback error:%v, cause:%v", group.ID, err, cause)
+	}
+	return cause
+}
+
+// hostGroupEventData return the member row, it is also the data of the hostgroup event
+func hostGroupEventData(group HostGroup, member HostGroupMember) map[string]interface{} {
+	return map[string]interface{}{
+		common.BKAppIDField:       group.AppID,
+		"id":                      group.ID,
+		"name":                    group.Name,
+		common.BKHostIDField:      member.HostID,
+		common.BKHostInnerIPField: member.InnerIP,
+	}
+}
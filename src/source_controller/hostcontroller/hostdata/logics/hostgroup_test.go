@@ -0,0 +1,210 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package logics
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/core/cc/api"
+	eventtypes "configcenter/src/scene_server/event_server/types"
+	"configcenter/src/source_controller/common/eventdata"
+	"configcenter/src/storage/memclient"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// eventCache record the actions of the events pushed into the queue
+type eventCache struct {
+	MockDI
+	actions []string
+}
+
+func (m *eventCache) Insert(cName string, data interface{}) (int, error) {
+	if "rpush" != cName {
+		return 0, nil
+	}
+	for _, value := range data.(common.KvMap)["values"].([]string) {
+		event := eventtypes.EventInst{}
+		json.Unmarshal([]byte(value), &event)
+		m.actions = append(m.actions, event.Action)
+	}
+	return 0, nil
+}
+
+func memberIDs(members []HostGroupMember) []int {
+	ids := make([]int, 0, len(members))
+	for _, member := range members {
+		ids = append(ids, member.HostID)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func TestDiffHostGroupMembers(t *testing.T) {
+	stored := []HostGroupMember{{HostID: 1}, {HostID: 2}, {HostID: 3}}
+	members := []HostGroupMember{{HostID: 2}, {HostID: 4}, {HostID: 4}}
+
+	joined, left := DiffHostGroupMembers(stored, members)
+	if ids := memberIDs(joined); !reflect.DeepEqual(ids, []int{4}) {
+		t.Errorf("joined not as expected: %v", ids)
+	}
+	if ids := memberIDs(left); !reflect.DeepEqual(ids, []int{1, 3}) {
+		t.Errorf("left not as expected: %v", ids)
+	}
+}
+
+func TestSetHostGroupMembers(t *testing.T) {
+	ec := &eventdata.EventContext{}
+	db := &relationDI{}
+	cc := &api.APIResource{InstCli: db, Cache: memclient.NewMemRedis()}
+	cache := &eventCache{}
+	api.GetAPIResource().CacheCli = cache
+	group := HostGroup{AppID: 1, ID: "group", Name: "web"}
+
+	joined, left, err := SetHostGroupMembers(ec, cc, group, []HostGroupMember{{HostID: 1, InnerIP: "127.0.0.1"}, {HostID: 2, InnerIP: "127.0.0.2"}})
+	if nil != err {
+		t.Fatalf("error not as expected: %v", err)
+	}
+	if 2 != len(joined) || 0 != len(left) {
+		t.Errorf("changes not as expected, joined: %v, left: %v", joined, left)
+	}
+
+	joined, left, err = SetHostGroupMembers(ec, cc, group, []HostGroupMember{{HostID: 2, InnerIP: "127.0.0.2"}, {HostID: 3, InnerIP: "127.0.0.3"}})
+	if nil != err {
+		t.Fatalf("error not as expected: %v", err)
+	}
+	if ids := memberIDs(joined); !reflect.DeepEqual(ids, []int{3}) {
+		t.Errorf("joined not as expected: %v", ids)
+	}
+	if ids := memberIDs(left); !reflect.DeepEqual(ids, []int{1}) {
+		t.Errorf("left not as expected: %v", ids)
+	}
+
+	stored, _ := GetHostGroupMembers(cc, group)
+	if ids := memberIDs(stored); !reflect.DeepEqual(ids, []int{2, 3}) {
+		t.Errorf("stored members not as expected: %v", ids)
+	}
+	expected := []string{eventtypes.EventActionJoin, eventtypes.EventActionJoin, eventtypes.EventActionLeave, eventtypes.EventActionJoin}
+	if !reflect.DeepEqual(cache.actions, expected) {
+		t.Errorf("events not as expected: %v", cache.actions)
+	}
+}
+
+func TestSetHostGroupMembersJoinedByOther(t *testing.T) {
+	ec := &eventdata.EventContext{}
+	// the host 2 is joined by the other host server at the same time
+	db := &relationDI{dupHost: 2}
+	cc := &api.APIResource{InstCli: db, Cache: memclient.NewMemRedis()}
+	cache := &eventCache{}
+	api.GetAPIResource().CacheCli = cache
+	group := HostGroup{AppID: 1, ID: "group", Name: "web"}
+
+	joined, left, err := SetHostGroupMembers(ec, cc, group, []HostGroupMember{{HostID: 1}, {HostID: 2}})
+	if nil != err {
+		t.Fatalf("error not as expected: %v", err)
+	}
+	if ids := memberIDs(joined); !reflect.DeepEqual(ids, []int{1}) || 0 != len(left) {
+		t.Errorf("changes not as expected, joined: %v, left: %v", joined, left)
+	}
+	if !reflect.DeepEqual(cache.actions, []string{eventtypes.EventActionJoin}) {
+		t.Errorf("events not as expected: %v", cache.actions)
+	}
+}
+
+func TestSetHostGroupMembersRollback(t *testing.T) {
+	ec := &eventdata.EventContext{}
+	db := &relationDI{failAt: 2}
+	cc := &api.APIResource{InstCli: db, Cache: memclient.NewMemRedis()}
+	cache := &eventCache{}
+	api.GetAPIResource().CacheCli = cache
+	group := HostGroup{AppID: 1, ID: "group", Name: "web"}
+
+	_, _, err := SetHostGroupMembers(ec, cc, group, []HostGroupMember{{HostID: 1}, {HostID: 2}})
+	if nil == err {
+		t.Fatalf("error should not be nil")
+	}
+	if 0 != len(db.rows) {
+		t.Errorf("members not rolled back: %v", db.rows)
+	}
+	if 0 != len(cache.actions) {
+		t.Errorf("no event should be sent: %v", cache.actions)
+	}
+}
+
+func TestSetHostGroupMembersLocked(t *testing.T) {
+	ec := &eventdata.EventContext{}
+	db := &relationDI{}
+	cc := &api.APIResource{InstCli: db, Cache: memclient.NewMemRedis()}
+	cache := &eventCache{}
+	api.GetAPIResource().CacheCli = cache
+	group := HostGroup{AppID: 1, ID: "group", Name: "web"}
+
+	// the other host server is setting the members of the group
+	cc.Cache.Lock(hostGroupLockPrefix+"1:group", "other", time.Minute)
+	if _, _, err := SetHostGroupMembers(ec, cc, group, []HostGroupMember{{HostID: 1}}); ErrHostGroupLocked != err {
+		t.Fatalf("error not as expected: %v", err)
+	}
+	if 0 != len(db.rows) || 0 != len(cache.actions) {
+		t.Errorf("nothing should be changed, rows: %v, events: %v", db.rows, cache.actions)
+	}
+
+	cc.Cache.Unlock(hostGroupLockPrefix+"1:group", "other")
+	if joined, _, err := SetHostGroupMembers(ec, cc, group, []HostGroupMember{{HostID: 1}}); nil != err || 1 != len(joined) {
+		t.Fatalf("the member should be joined after unlocked, joined: %v, error: %v", joined, err)
+	}
+}
+
+func TestMarkHostGroupsDue(t *testing.T) {
+	db := memclient.NewMemDB()
+	cc := &api.APIResource{InstCli: db}
+	evalTime := time.Now()
+	for _, group := range []map[string]interface{}{
+		{"id": "a", common.BKAppIDField: 1, "dynamic": true, "eval_time": evalTime},
+		{"id": "b", common.BKAppIDField: 2, "dynamic": true, "eval_time": evalTime},
+		{"id": "c", common.BKAppIDField: 3, "dynamic": true, "eval_time": evalTime},
+		{"id": "d", common.BKAppIDField: 1, "dynamic": false, "eval_time": evalTime},
+	} {
+		if _, err := db.Insert(UserAPITableName, group); nil != err {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.Insert("cc_ModuleHostConfig", map[string]interface{}{common.BKAppIDField: 2, common.BKHostIDField: 5, common.BKModuleIDField: 1}); nil != err {
+		t.Fatal(err)
+	}
+	dueGroups := func() []string {
+		rows := make([]map[string]interface{}, 0)
+		if err := db.GetMutilByCondition(UserAPITableName, nil, nil, &rows, "id", 0, 0); nil != err {
+			t.Fatal(err)
+		}
+		ids := []string{}
+		for _, row := range rows {
+			if evalTime, _ := row["eval_time"].(time.Time); evalTime.IsZero() {
+				ids = append(ids, row["id"].(string))
+			}
+		}
+		return ids
+	}
+
+	if err := MarkHostGroupsDue(cc, nil, nil); nil != err || 0 != len(dueGroups()) {
+		t.Fatalf("no group should be due, error: %v, due: %v", err, dueGroups())
+	}
+	// the host 5 belongs to the business 2
+	if err := MarkHostGroupsDue(cc, []int{1}, []int{5}); nil != err {
+		t.Fatal(err)
+	}
+	if due := dueGroups(); !reflect.DeepEqual(due, []string{"a", "b"}) {
+		t.Errorf("the dynamic groups of the business 1 and 2 should be due: %v", due)
+	}
+}
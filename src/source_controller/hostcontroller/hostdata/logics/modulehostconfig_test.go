@@ -13,42 +13,48 @@
 package logics
 
 import (
-    "configcenter/src/source_controller/common/eventdata"
-    "configcenter/src/source_controller/common/instdata"
-    "configcenter/src/common/core/cc/api"
-    "configcenter/src/storage"
-    "testing"
-    "errors"
-    "flag"
+	"configcenter/src/common"
+	"configcenter/src/common/core/cc/api"
+	eventtypes "configcenter/src/scene_server/event_server/types"
+	metadataTable "configcenter/src/source_controller/api/metadata"
+	"configcenter/src/source_controller/common/eventdata"
+	"configcenter/src/source_controller/common/instdata"
+	"configcenter/src/storage"
+	"configcenter/src/storage/memclient"
+	"encoding/json"
+	"errors"
+	"flag"
+	"reflect"
+	"testing"
 )
 
 type MockDI struct {
-    ErrGetIncID            error
-    ErrInsert              error
-    ErrInsertMuti          error
-    ErrUpdateByCondition   error
-    ErrGetOneByCondition   error
-    ErrGetMutilByCondition error
-    ErrGetCntByCondition   error
-    ErrDelByCondition      error
-    ErrHasTable            error
-    ErrExecSql             error
-    ErrIndex               error
-    ErrDropTable           error
-    ErrHasFields           error
-    ErrAddColumn           error
-    ErrModifyColumn        error
-    ErrDropColumn          error
-    ErrCreateTable         error
-    ErrOpen                error
-    ErrGetSession          error
-
-    VarGetIncID          int64
-    VarInsert            int
-    VarGetCntByCondition int
-    VarHasTable          bool
-    VarHasFields         bool
-    VarGetType           string
+	ErrGetIncID            error
+	ErrInsert              error
+	ErrInsertMuti          error
+	ErrUpdateByCondition   error
+	ErrGetOneByCondition   error
+	ErrGetMutilByCondition error
+	ErrGetCntByCondition   error
+	ErrDelByCondition      error
+	ErrHasTable            error
+	ErrExecSql             error
+	ErrIndex               error
+	ErrDropTable           error
+	ErrHasFields           error
+	ErrAddColumn           error
+	ErrModifyColumn        error
+	ErrDropColumn          error
+	ErrCreateTable         error
+	ErrOpen                error
+	ErrGetSession          error
+
+	VarGetIncID          int64
+	VarInsert            int
+	VarGetCntByCondition int
+	VarHasTable          bool
+	VarHasFields         bool
+	VarGetType           string
 }
 
 func (m *MockDI) GetIncID(cName string) (int64, error) {return m.VarGetIncID, m.ErrGetIncID}
@@ -57,6 +63,7 @@ func (m *MockDI) InsertMuti(cName string, data ...interface{}) error {return m.E
 func (m *MockDI) UpdateByCondition(cName string, data, condition interface{}) error {return m.ErrUpdateByCondition}
 func (m *MockDI) GetOneByCondition(cName string, fields []string, condition interface{}, result interface{}) error {return m.ErrGetOneByCondition}
 func (m *MockDI) GetMutilByCondition(cName string, fields []string, condition interface{}, result interface{}, sort string, start, limit int) error {return m.ErrGetMutilByCondition}
+func (m *MockDI) GetMutilByCursor(cName string, fields []string, condition interface{}, result interface{}, sort, cursor string, limit int) (string, error) {return "", m.ErrGetMutilByCondition}
 func (m *MockDI) GetCntByCondition(cName string, condition interface{}) (int, error) {return m.VarGetCntByCondition, m.ErrGetCntByCondition}
 func (m *MockDI) DelByCondition(cName string, condition interface{}) error {return m.ErrDelByCondition}
 func (m *MockDI) HasTable(cName string) (bool, error) {return m.VarHasTable, m.ErrHasTable}
@@ -72,219 +79,307 @@ func (m *MockDI) GetType() string {return m.VarGetType}
 func (m *MockDI) Open() error {return m.ErrOpen}
 func (m *MockDI) Close() {}
 func (m *MockDI) GetSession() interface{} {return m.ErrGetSession}
+func (m *MockDI) Ping() error {return nil}
+func (m *MockDI) StartTransaction() (storage.Tx, error) {return storage.NewCompensatingTx(m), nil}
+
+// newModuleHostDB return the in-memory db with the business 1 of the resource pool, its idle module 3,
+// fault module 4 and module 5 of the set 2, the host 1 is in the idle module.
+// the events are pushed into the in-memory cache returned
+func newModuleHostDB(t *testing.T) (*api.APIResource, *memclient.MemRedis) {
+	db := memclient.NewMemDB()
+	rows := []struct {
+		table string
+		data  map[string]interface{}
+	}{
+		{"cc_ApplicationBase", map[string]interface{}{common.BKAppIDField: 1, common.BKOwnerIDField: 0, common.BKDefaultField: 1}},
+		{moduleBaseTaleName, map[string]interface{}{common.BKAppIDField: 1, common.BKSetIDField: 1, common.BKModuleIDField: 3, common.BKModuleNameField: "idle", common.BKDefaultField: common.DefaultResModuleFlag}},
+		{moduleBaseTaleName, map[string]interface{}{common.BKAppIDField: 1, common.BKSetIDField: 1, common.BKModuleIDField: 4, common.BKModuleNameField: "fault", common.BKDefaultField: common.DefaultFaultModuleFlag}},
+		{moduleBaseTaleName, map[string]interface{}{common.BKAppIDField: 1, common.BKSetIDField: 2, common.BKModuleIDField: 5, common.BKModuleNameField: "web", common.BKDefaultField: 0}},
+		{"cc_HostBase", map[string]interface{}{common.BKHostIDField: 1, common.BKHostInnerIPField: "127.0.0.1"}},
+		{metadataTable.ModuleHostConfig{}.TableName(), map[string]interface{}{common.BKAppIDField: 1, common.BKSetIDField: 1, common.BKModuleIDField: 3, common.BKHostIDField: 1}},
+	}
+	for _, row := range rows {
+		if _, err := db.Insert(row.table, row.data); nil != err {
+			t.Fatal(err)
+		}
+	}
+	instdata.DataH = db
+	cache := memclient.NewMemRedis()
+	api.GetAPIResource().CacheCli = cache
+	return &api.APIResource{InstCli: db, Cache: cache}, cache
+}
+
+// queuedActions return the actions of the events pushed into the queue
+func queuedActions(t *testing.T, cache *memclient.MemRedis) []string {
+	values, err := cache.LRange(eventtypes.EventCacheEventQueueKey, 0, -1)
+	if nil != err {
+		t.Fatal(err)
+	}
+	actions := make([]string, 0, len(values))
+	for _, value := range values {
+		event := eventtypes.EventInst{}
+		json.Unmarshal([]byte(value), &event)
+		actions = append(actions, event.Action)
+	}
+	return actions
+}
 
 func TestDelSingleHostModuleRelation(t *testing.T) {
-    ec := &eventdata.EventContext{}
-    cc := &api.APIResource{}
-
-    errFake := errors.New("fake error")
-    instdata.DataH = &MockDI{ErrGetOneByCondition: errFake}
-    _, err := DelSingleHostModuleRelation(ec, cc, 1, 2, 3)
-    if err != errFake {
-        t.Errorf("error not as expected: %v", err)
-    }
+	ec := &eventdata.EventContext{}
+	cc := &api.APIResource{}
+
+	errFake := errors.New("fake error")
+	instdata.DataH = &MockDI{ErrGetOneByCondition: errFake}
+	_, err := DelSingleHostModuleRelation(ec, cc, 1, 2, 3)
+	if err != errFake {
+		t.Errorf("error not as expected: %v", err)
+	}
 }
 
 func TestDelSingleHostModuleRelation2(t *testing.T) {
-    ec := &eventdata.EventContext{}
-    cc := &api.APIResource{}
-
-    errFake := errors.New("fake error")
-    cc.InstCli = &MockDI{ErrGetCntByCondition: errFake}
-    instdata.DataH = &MockDI{}
-    _, err := DelSingleHostModuleRelation(ec, cc, 1, 2, 3)
-    if err != errFake {
-        t.Errorf("error not as expected: %v", err)
-    }
+	ec := &eventdata.EventContext{}
+	cc := &api.APIResource{}
+
+	errFake := errors.New("fake error")
+	cc.InstCli = &MockDI{ErrGetCntByCondition: errFake}
+	instdata.DataH = &MockDI{}
+	_, err := DelSingleHostModuleRelation(ec, cc, 1, 2, 3)
+	if err != errFake {
+		t.Errorf("error not as expected: %v", err)
+	}
 }
 
 func TestDelSingleHostModuleRelation3(t *testing.T) {
-    ec := &eventdata.EventContext{}
-    cc := &api.APIResource{}
-
-    cc.InstCli = &MockDI{VarGetCntByCondition: 0}
-    instdata.DataH = &MockDI{}
-    r, err := DelSingleHostModuleRelation(ec, cc, 1, 2, 3)
-    if !r {
-        t.Errorf("result not as expected, should be true")
-    }
-    if err != nil {
-        t.Errorf("error not as expected: %v", err)
-    }
+	ec := &eventdata.EventContext{}
+	cc, cache := newModuleHostDB(t)
+
+	r, err := DelSingleHostModuleRelation(ec, cc, 1, 5, 1)
+	if !r {
+		t.Errorf("result not as expected, should be true")
+	}
+	if err != nil {
+		t.Errorf("error not as expected: %v", err)
+	}
+	if cnt, _ := cc.InstCli.GetCntByCondition(metadataTable.ModuleHostConfig{}.TableName(), nil); 1 != cnt {
+		t.Errorf("the relation of the other module should be kept, %d left", cnt)
+	}
+	if actions := queuedActions(t, cache); 0 != len(actions) {
+		t.Errorf("no event expected: %v", actions)
+	}
 }
 
 func TestDelSingleHostModuleRelation4(t *testing.T) {
-    ec := &eventdata.EventContext{}
-    cc := &api.APIResource{}
-
-    errFake := errors.New("fake error")
-    cc.InstCli = &MockDI{ErrGetMutilByCondition: errFake, VarGetCntByCondition: 1}
-    instdata.DataH = &MockDI{}
-    _, err := DelSingleHostModuleRelation(ec, cc, 1, 2, 3)
-    if err != errFake {
-        t.Errorf("error not as expected: %v", err)
-    }
+	ec := &eventdata.EventContext{}
+	cc := &api.APIResource{}
+
+	errFake := errors.New("fake error")
+	cc.InstCli = &MockDI{ErrGetMutilByCondition: errFake, VarGetCntByCondition: 1}
+	instdata.DataH = &MockDI{}
+	_, err := DelSingleHostModuleRelation(ec, cc, 1, 2, 3)
+	if err != errFake {
+		t.Errorf("error not as expected: %v", err)
+	}
 }
 
 func TestDelSingleHostModuleRelation5(t *testing.T) {
-    ec := &eventdata.EventContext{}
-    cc := &api.APIResource{}
-
-    errFake := errors.New("fake error")
-    cc.InstCli = &MockDI{ErrDelByCondition: errFake, VarGetCntByCondition: 1}
-    instdata.DataH = &MockDI{}
-    _, err := DelSingleHostModuleRelation(ec, cc, 1, 2, 3)
-    if err != errFake {
-        t.Errorf("error not as expected: %v", err)
-    }
+	ec := &eventdata.EventContext{}
+	cc := &api.APIResource{}
+
+	errFake := errors.New("fake error")
+	cc.InstCli = &MockDI{ErrDelByCondition: errFake, VarGetCntByCondition: 1}
+	instdata.DataH = &MockDI{}
+	_, err := DelSingleHostModuleRelation(ec, cc, 1, 2, 3)
+	if err != errFake {
+		t.Errorf("error not as expected: %v", err)
+	}
 }
 
 func TestDelSingleHostModuleRelation6(t *testing.T) {
-    ec := &eventdata.EventContext{}
-    cc := &api.APIResource{}
-
-    cc.InstCli = &MockDI{VarGetCntByCondition: 1}
-    instdata.DataH = &MockDI{}
-    r, err := DelSingleHostModuleRelation(ec, cc, 1, 2, 3)
-    if !r {
-        t.Errorf("result not as expected, should be true")
-    }
-    if err != nil {
-        t.Errorf("error not as expected: %v", err)
-    }
+	ec := &eventdata.EventContext{}
+	cc, cache := newModuleHostDB(t)
+
+	r, err := DelSingleHostModuleRelation(ec, cc, 1, 3, 1)
+	if !r {
+		t.Errorf("result not as expected, should be true")
+	}
+	if err != nil {
+		t.Errorf("error not as expected: %v", err)
+	}
+	if cnt, _ := cc.InstCli.GetCntByCondition(metadataTable.ModuleHostConfig{}.TableName(), nil); 0 != cnt {
+		t.Errorf("the relation should be deleted, %d left", cnt)
+	}
+	if actions := queuedActions(t, cache); !reflect.DeepEqual(actions, []string{eventtypes.EventActionDelete}) {
+		t.Errorf("events not as expected: %v", actions)
+	}
 }
 
 func TestAddSingleHostModuleRelation(t *testing.T) {
-    ec := &eventdata.EventContext{}
-    cc := &api.APIResource{}
-
-    errFake := errors.New("fake error")
-    instdata.DataH = &MockDI{ErrGetOneByCondition: errFake}
-    _, err := AddSingleHostModuleRelation(ec, cc, 1, 2, 3)
-    if err != errFake {
-        t.Errorf("error not as expected: %v", err)
-    }
+	ec := &eventdata.EventContext{}
+	cc := &api.APIResource{}
+
+	errFake := errors.New("fake error")
+	instdata.DataH = &MockDI{ErrGetOneByCondition: errFake}
+	_, err := AddSingleHostModuleRelation(ec, cc, 1, 2, 3)
+	if err != errFake {
+		t.Errorf("error not as expected: %v", err)
+	}
 }
 
 func TestAddSingleHostModuleRelation2(t *testing.T) {
-    ec := &eventdata.EventContext{}
-    cc := &api.APIResource{}
-
-    instdata.DataH = &MockDI{}
-    r, err := AddSingleHostModuleRelation(ec, cc, 1, 2, 3)
-    if r {
-        t.Errorf("result not as expected, should be false")
-    }
-    if err == nil {
-        t.Errorf("error not as expected: %v", err)
-    }
+	ec := &eventdata.EventContext{}
+	cc, _ := newModuleHostDB(t)
+
+	r, err := AddSingleHostModuleRelation(ec, cc, 1, 6, 1)
+	if r {
+		t.Errorf("result not as expected, should be false")
+	}
+	if err == nil {
+		t.Errorf("error not as expected: %v", err)
+	}
+}
+
+func TestAddSingleHostModuleRelation3(t *testing.T) {
+	ec := &eventdata.EventContext{}
+	cc, cache := newModuleHostDB(t)
+
+	for i := 0; i < 2; i++ {
+		r, err := AddSingleHostModuleRelation(ec, cc, 1, 5, 1)
+		if !r || err != nil {
+			t.Fatalf("result not as expected: %v %v", r, err)
+		}
+	}
+	relation := make(map[string]interface{})
+	cond := map[string]interface{}{common.BKHostIDField: 1, common.BKModuleIDField: 5}
+	if err := cc.InstCli.GetOneByCondition(metadataTable.ModuleHostConfig{}.TableName(), nil, cond, &relation); nil != err {
+		t.Fatalf("relation not added: %v", err)
+	}
+	if 2 != relation[common.BKSetIDField] {
+		t.Errorf("the set of the module not as expected: %v", relation)
+	}
+	if actions := queuedActions(t, cache); !reflect.DeepEqual(actions, []string{eventtypes.EventActionCreate}) {
+		t.Errorf("the existing relation should not be added again, events: %v", actions)
+	}
 }
 
 func TestGetDefaultModuleIDs(t *testing.T) {
-    cc := &api.APIResource{}
-
-    errFake := errors.New("fake error")
-    cc.InstCli = &MockDI{ErrGetMutilByCondition: errFake}
-    _, err := GetDefaultModuleIDs(cc, 1)
-    if err == nil {
-        t.Errorf("error not as expected: %v", err)
-    }
+	cc := &api.APIResource{}
+
+	errFake := errors.New("fake error")
+	cc.InstCli = &MockDI{ErrGetMutilByCondition: errFake}
+	_, err := GetDefaultModuleIDs(cc, 1)
+	if err == nil {
+		t.Errorf("error not as expected: %v", err)
+	}
 }
 
 func TestGetDefaultModuleIDs2(t *testing.T) {
-    cc := &api.APIResource{}
-
-    cc.InstCli = &MockDI{}
-    _, err := GetDefaultModuleIDs(cc, 1)
-    if err == nil {
-        t.Errorf("error not as expected: %v", err)
-    }
+	cc, _ := newModuleHostDB(t)
+
+	ids, err := GetDefaultModuleIDs(cc, 1)
+	if err != nil {
+		t.Errorf("error not as expected: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []int{3, 4}) {
+		t.Errorf("default modules not as expected: %v", ids)
+	}
+	if _, err := GetDefaultModuleIDs(cc, 2); err == nil {
+		t.Errorf("error expected for the business without modules")
+	}
 }
 
 func TestGetModuleIDsByHostID(t *testing.T) {
-    cc := &api.APIResource{}
-
-    errFake := errors.New("fake error")
-    cc.InstCli = &MockDI{ErrGetMutilByCondition: errFake}
-    _, err := GetModuleIDsByHostID(cc, 1)
-    if err == nil {
-        t.Errorf("error not as expected: %v", err)
-    }
+	cc := &api.APIResource{}
+
+	errFake := errors.New("fake error")
+	cc.InstCli = &MockDI{ErrGetMutilByCondition: errFake}
+	_, err := GetModuleIDsByHostID(cc, 1)
+	if err == nil {
+		t.Errorf("error not as expected: %v", err)
+	}
 }
 
 func TestGetModuleIDsByHostID2(t *testing.T) {
-    cc := &api.APIResource{}
-
-    cc.InstCli = &MockDI{}
-    _, err := GetModuleIDsByHostID(cc, 1)
-    if err != nil {
-        t.Errorf("error not as expected: %v", err)
-    }
+	cc, _ := newModuleHostDB(t)
+
+	ids, err := GetModuleIDsByHostID(cc, map[string]interface{}{common.BKHostIDField: 1, common.BKAppIDField: 1})
+	if err != nil {
+		t.Errorf("error not as expected: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []int{3}) {
+		t.Errorf("modules not as expected: %v", ids)
+	}
 }
 
 func TestGetResourcePoolApp(t *testing.T) {
-    cc := &api.APIResource{}
-
-    errFake := errors.New("fake error")
-    cc.InstCli = &MockDI{ErrGetOneByCondition: errFake}
-    _, err := GetResourcePoolApp(cc, 1)
-    if err == nil {
-        t.Errorf("error not as expected: %v", err)
-    }
+	cc := &api.APIResource{}
+
+	errFake := errors.New("fake error")
+	cc.InstCli = &MockDI{ErrGetOneByCondition: errFake}
+	_, err := GetResourcePoolApp(cc, 1)
+	if err == nil {
+		t.Errorf("error not as expected: %v", err)
+	}
 }
 
 func TestGetResourcePoolApp2(t *testing.T) {
-    cc := &api.APIResource{}
-
-    cc.InstCli = &MockDI{}
-    _, err := GetResourcePoolApp(cc, 1)
-    if err == nil {
-        t.Errorf("error not as expected: %v", err)
-    }
+	cc, _ := newModuleHostDB(t)
+
+	appID, err := GetResourcePoolApp(cc, 0)
+	if err != nil || 1 != appID {
+		t.Errorf("resource pool not as expected: %d %v", appID, err)
+	}
+	if _, err := GetResourcePoolApp(cc, 1); err == nil {
+		t.Errorf("error expected for the owner without resource pool")
+	}
 }
 
 func TestCheckHostInIDle(t *testing.T) {
-    cc := &api.APIResource{}
-
-    errFake := errors.New("fake error")
-    cc.InstCli = &MockDI{ErrGetMutilByCondition: errFake}
-    _, err := CheckHostInIDle(cc, 1, 2, nil)
-    if err == nil {
-        t.Errorf("error not as expected: %v", err)
-    }
+	cc := &api.APIResource{}
+
+	errFake := errors.New("fake error")
+	cc.InstCli = &MockDI{ErrGetMutilByCondition: errFake}
+	_, err := CheckHostInIDle(cc, 1, 2, nil)
+	if err == nil {
+		t.Errorf("error not as expected: %v", err)
+	}
 }
 
 func TestCheckHostInIDle2(t *testing.T) {
-    cc := &api.APIResource{}
-
-    cc.InstCli = &MockDI{}
-    _, err := CheckHostInIDle(cc, 1, 2, nil)
-    if err != nil {
-        t.Errorf("error not as expected: %v", err)
-    }
+	cc, _ := newModuleHostDB(t)
+
+	ids, err := CheckHostInIDle(cc, 1, 3, []int{1})
+	if err != nil || 0 != len(ids) {
+		t.Errorf("the host is in the idle module: %v %v", ids, err)
+	}
+	ids, err = CheckHostInIDle(cc, 1, 5, []int{1})
+	if err != nil || !reflect.DeepEqual(ids, []int{1}) {
+		t.Errorf("the host not in the module should be returned: %v %v", ids, err)
+	}
 }
 
 func TestGetIDleModuleID(t *testing.T) {
-    cc := &api.APIResource{}
-
-    errFake := errors.New("fake error")
-    cc.InstCli = &MockDI{ErrGetOneByCondition: errFake}
-    _, err := GetIDleModuleID(cc, 1)
-    if err == nil {
-        t.Errorf("error not as expected: %v", err)
-    }
+	cc := &api.APIResource{}
+
+	errFake := errors.New("fake error")
+	cc.InstCli = &MockDI{ErrGetOneByCondition: errFake}
+	_, err := GetIDleModuleID(cc, 1)
+	if err == nil {
+		t.Errorf("error not as expected: %v", err)
+	}
 }
 
 func TestGetIDleModuleID2(t *testing.T) {
-    cc := &api.APIResource{}
-
-    cc.InstCli = &MockDI{}
-    _, err := GetIDleModuleID(cc, 1)
-    if err == nil {
-        t.Errorf("error not as expected: %v", err)
-    }
+	cc, _ := newModuleHostDB(t)
+
+	id, err := GetIDleModuleID(cc, 1)
+	if err != nil || 3 != id {
+		t.Errorf("idle module not as expected: %d %v", id, err)
+	}
+	if _, err := GetIDleModuleID(cc, 2); err == nil {
+		t.Errorf("error expected for the business without idle module")
+	}
 }
 
 func init() {
-    flag.Set("logtostderr", "false")
+	flag.Set("logtostderr", "false")
 }
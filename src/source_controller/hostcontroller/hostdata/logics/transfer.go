@@ -0,0 +1,209 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+
+package logics
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/core/cc/api"
+	"configcenter/src/common/util"
+	eventtypes "configcenter/src/scene_server/event_server/types"
+	metadataTable "configcenter/src/source_controller/api/metadata"
+	"configcenter/src/source_controller/common/commondata"
+	"configcenter/src/source_controller/common/eventdata"
+	"configcenter/src/storage"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+// hostTransferLockPrefix the cache lock serializes the transfers of a host
+const hostTransferLockPrefix = "cc_host_transfer_lock:"
+
+// hostTransferLockWait the transfer waits for the other transfers of its hosts at most the duration
+var hostTransferLockWait = 5 * time.Second
+
+// ErrHostTransferLocked the hosts are being transferred by the other
+var ErrHostTransferLocked = errors.New("the hosts are being transferred")
+
+// ModuleHostTransfer the hosts to move from the source modules to the destination modules
+type ModuleHostTransfer struct {
+	HostIDs []int
+	// SrcAppID, SrcModuleIDs the relations to remove, all the modules of the app if SrcModuleIDs is empty
+	SrcAppID     int
+	SrcModuleIDs []int
+	// DstAppID, DstModuleIDs the relations to add
+	DstAppID     int
+	DstModuleIDs []int
+}
+
+// TransferHostModuleRelation transfer the hosts in one transaction, either all the relations
+// of the hosts are transferred or none of them is changed, the events are sent after committed
+func TransferHostModuleRelation(ec *eventdata.EventContext, cc *api.APIResource, transfer *ModuleHostTransfer) error {
+	unlock, err := lockTransferHosts(cc.Cache, transfer.HostIDs)
+	if nil != err {
+		blog.Errorf("transferHostModuleRelation lock hosts %v error:%v", transfer.HostIDs, err)
+		return err
+	}
+	defer unlock()
+
+	tx, err := cc.InstCli.StartTransaction()
+	if nil != err {
+		blog.Errorf("transferHostModuleRelation start transaction error:%v", err)
+		return err
+	}
+	for _, hostID := range transfer.HostIDs {
+		hostResult := make(map[string]interface{})
+		condition := map[string]interface{}{common.BKHostIDField: hostID}
+		if err := tx.GetOneByCondition(commondata.ObjTableMap[common.BKInnerObjIDHost], []string{common.BKHostInnerIPField}, condition, &hostResult); err != nil {
+			blog.Errorf("transferHostModuleRelation get host %d error:%v", hostID, err)
+			return rollbackTransfer(tx, err)
+		}
+	}
+	setIDs, err := getModuleSetIDs(tx, transfer.DstModuleIDs)
+	if nil != err {
+		return rollbackTransfer(tx, err)
+	}
+
+	tableName := metadataTable.ModuleHostConfig{}.TableName()
+	deleted := make([]map[string]interface{}, 0)
+	added := make([]map[string]interface{}, 0)
+	for _, hostID := range transfer.HostIDs {
+		delCondition := make(map[string]interface{})
+		delCondition[common.BKAppIDField] = transfer.SrcAppID
+		delCondition[common.BKHostIDField] = hostID
+		if 0 != len(transfer.SrcModuleIDs) {
+			delCondition[common.BKModuleIDField] = common.KvMap{common.BKDBIN: transfer.SrcModuleIDs}
+		}
+		origindatas := make([]map[string]interface{}, 0)
+		if err := tx.GetMutilByCondition(tableName, nil, delCondition, &origindatas, "", 0, 0); nil != err {
+			blog.Errorf("transferHostModuleRelation retrieve original datas error:%v, condition:%v", err, delCondition)
+			return rollbackTransfer(tx, err)
+		}
+		if err := tx.DelByCondition(tableName, delCondition); nil != err {
+			blog.Errorf("transferHostModuleRelation del module host relation error:%v, condition:%v", err, delCondition)
+			return rollbackTransfer(tx, err)
+		}
+		deleted = append(deleted, origindatas...)
+
+		for _, moduleID := range transfer.DstModuleIDs {
+			moduleHostConfig := make(map[string]interface{})
+			moduleHostConfig[common.BKAppIDField] = transfer.DstAppID
+			moduleHostConfig[common.BKHostIDField] = hostID
+			moduleHostConfig[common.BKModuleIDField] = moduleID
+			num, err := tx.GetCntByCondition(tableName, moduleHostConfig)
+			if nil != err {
+				blog.Errorf("transferHostModuleRelation get module host relation error:%v", err)
+				return rollbackTransfer(tx, err)
+			}
+			//config exsit, skip
+			if num > 0 {
+				continue
+			}
+			moduleHostConfig[common.BKSetIDField] = setIDs[moduleID]
+			if _, err := tx.Insert(tableName, moduleHostConfig); nil != err {
+				blog.Errorf("transferHostModuleRelation add module host relation error:%v, data:%v", err, moduleHostConfig)
+				return rollbackTransfer(tx, err)
+			}
+			added = append(added, moduleHostConfig)
+		}
+	}
+	if err := tx.Commit(); nil != err {
+		blog.Errorf("transferHostModuleRelation commit error:%v", err)
+		return err
+	}
+
+	// send events
+	for _, origindata := range deleted {
+		if err := ec.InsertEvent(eventtypes.EventTypeRelation, "moduletransfer", eventtypes.EventActionDelete, nil, origindata); err != nil {
+			blog.Errorf("create event error:%v", err)
+		}
+	}
+	for _, moduleHostConfig := range added {
+		if err := ec.InsertEvent(eventtypes.EventTypeRelation, "moduletransfer", eventtypes.EventActionCreate, moduleHostConfig, nil); err != nil {
+			blog.Errorf("create event error:%v", err)
+		}
+	}
+	return nil
+}
+
+// lockTransferHosts lock the hosts in the order of the id, so the transfers of the same hosts wait for each other
+// instead of holding a part of the hosts each, the returned func releases the locks
+func lockTransferHosts(cache storage.Cache, hostIDs []int) (func(), error) {
+	ids := append([]int(nil), hostIDs...)
+	sort.Ints(ids)
+	token := xid.New().String()
+	locked := make([]string, 0, len(ids))
+	unlock := func() {
+		for _, key := range locked {
+			if err := cache.Unlock(key, token); nil != err {
+				blog.Errorf("transferHostModuleRelation unlock %s error:%v", key, err)
+			}
+		}
+	}
+	deadline := time.Now().Add(hostTransferLockWait)
+	for _, hostID := range ids {
+		key := fmt.Sprintf("%s%d", hostTransferLockPrefix, hostID)
+		for {
+			ok, err := cache.Lock(key, token, time.Minute)
+			if nil != err {
+				unlock()
+				return nil, err
+			}
+			if ok {
+				break
+			}
+			if time.Now().After(deadline) {
+				unlock()
+				return nil, ErrHostTransferLocked
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		locked = append(locked, key)
+	}
+	return unlock, nil
+}
+
+// rollbackTransfer undo the transfer, the cause is returned
+func rollbackTransfer(tx storage.Tx, cause error) error {
+	if err := tx.Rollback(); nil != err {
+		blog.Errorf("transferHostModuleRelation rollback error:%v, cause:%v", err, cause)
+	}
+	return cause
+}
+
+// getModuleSetIDs return the set id of the modules, the modules are read in the transaction
+func getModuleSetIDs(tx storage.Tx, moduleIDs []int) (map[int]int, error) {
+	setIDs := make(map[int]int, len(moduleIDs))
+	for _, moduleID := range moduleIDs {
+		moduleResult := make(map[string]interface{})
+		condition := map[string]interface{}{common.BKModuleIDField: moduleID}
+		err := tx.GetOneByCondition(commondata.ObjTableMap[common.BKInnerObjIDModule], []string{common.BKModuleNameField, common.BKSetIDField}, condition, &moduleResult)
+		if nil != err {
+			blog.Errorf("transferHostModuleRelation get module %d error:%v", moduleID, err)
+			return nil, err
+		}
+		moduleName, _ := moduleResult[common.BKModuleNameField].(string)
+		setID, _ := util.GetIntByInterface(moduleResult[common.BKSetIDField])
+		if "" == moduleName || 0 == setID {
+			blog.Errorf("transferHostModuleRelation get module error:not find module width ModuleID:%d", moduleID)
+			return nil, errors.New("未找到对应的模块")
+		}
+		setIDs[moduleID] = setID
+	}
+	return setIDs, nil
+}
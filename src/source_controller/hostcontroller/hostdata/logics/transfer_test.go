@@ -0,0 +1,270 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+
+package logics
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/core/cc/api"
+	"configcenter/src/source_controller/common/eventdata"
+	"configcenter/src/storage"
+	"configcenter/src/storage/memclient"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2"
+)
+
+// relationDI keep the module host relations in memory, the write fails at the failAt-th call,
+// the compensating logs are only counted, all the hosts exist and so do the modules unless noModule,
+// the insert of dupHost is rejected as duplicated
+type relationDI struct {
+	MockDI
+	rows     []map[string]interface{}
+	writes   int
+	failAt   int
+	logs     int
+	noModule bool
+	dupHost  int
+}
+
+func (m *relationDI) StartTransaction() (storage.Tx, error) {
+	return storage.NewCompensatingTx(m), nil
+}
+
+func (m *relationDI) fail() bool {
+	m.writes++
+	return m.writes == m.failAt
+}
+
+func (m *relationDI) match(row map[string]interface{}, condition interface{}) bool {
+	for key, val := range condition.(map[string]interface{}) {
+		in, ok := val.(common.KvMap)
+		if !ok {
+			if row[key] != val {
+				return false
+			}
+			continue
+		}
+		found := false
+		for _, item := range in[common.BKDBIN].([]int) {
+			if row[key] == item {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *relationDI) Insert(cName string, data interface{}) (int, error) {
+	if storage.TxLogCollection == cName {
+		m.logs++
+		return m.logs, nil
+	}
+	if m.fail() {
+		return 0, errors.New("fake insert error")
+	}
+	if hostID, ok := data.(map[string]interface{})[common.BKHostIDField]; ok && 0 != m.dupHost && hostID == m.dupHost {
+		return 0, &mgo.LastError{Code: 11000, Err: "E11000 duplicate key error"}
+	}
+	row := make(map[string]interface{})
+	for key, val := range data.(map[string]interface{}) {
+		row[key] = val
+	}
+	m.rows = append(m.rows, row)
+	return len(m.rows), nil
+}
+
+func (m *relationDI) InsertMuti(cName string, data ...interface{}) error {
+	for _, item := range data {
+		if _, err := m.Insert(cName, item); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *relationDI) GetMutilByCondition(cName string, fields []string, condition interface{}, result interface{}, sort string, start, limit int) error {
+	rows := make([]map[string]interface{}, 0)
+	for _, row := range m.rows {
+		if m.match(row, condition) {
+			rows = append(rows, row)
+		}
+	}
+	*result.(*[]map[string]interface{}) = rows
+	return nil
+}
+
+func (m *relationDI) GetCntByCondition(cName string, condition interface{}) (int, error) {
+	rows := make([]map[string]interface{}, 0)
+	m.GetMutilByCondition(cName, nil, condition, &rows, "", 0, 0)
+	return len(rows), nil
+}
+
+func (m *relationDI) UpdateByCondition(cName string, data, condition interface{}) error {
+	return nil
+}
+
+func (m *relationDI) DelByCondition(cName string, condition interface{}) error {
+	if storage.TxLogCollection == cName {
+		m.logs = 0
+		return nil
+	}
+	if m.fail() {
+		return errors.New("fake delete error")
+	}
+	rows := make([]map[string]interface{}, 0)
+	for _, row := range m.rows {
+		if !m.match(row, condition) {
+			rows = append(rows, row)
+		}
+	}
+	m.rows = rows
+	return nil
+}
+
+// GetOneByCondition return the module named by its id, all the modules belong to the set 10
+func (m *relationDI) GetOneByCondition(cName string, fields []string, condition interface{}, result interface{}) error {
+	row := result.(*map[string]interface{})
+	if moduleID, ok := condition.(map[string]interface{})[common.BKModuleIDField]; ok && !m.noModule {
+		(*row)[common.BKModuleNameField] = "module"
+		(*row)[common.BKSetIDField] = 10
+		(*row)[common.BKModuleIDField] = moduleID
+	}
+	return nil
+}
+
+func newRelationDI() *relationDI {
+	return &relationDI{rows: []map[string]interface{}{
+		{common.BKAppIDField: 1, common.BKHostIDField: 1, common.BKModuleIDField: 1, common.BKSetIDField: 10},
+		{common.BKAppIDField: 1, common.BKHostIDField: 1, common.BKModuleIDField: 2, common.BKSetIDField: 10},
+		{common.BKAppIDField: 1, common.BKHostIDField: 2, common.BKModuleIDField: 1, common.BKSetIDField: 10},
+	}}
+}
+
+// relationKeys return the relations as the sorted host:module pairs
+func relationKeys(rows []map[string]interface{}) [][2]int {
+	keys := make([][2]int, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, [2]int{row[common.BKHostIDField].(int), row[common.BKModuleIDField].(int)})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	return keys
+}
+
+func TestTransferHostModuleRelation(t *testing.T) {
+	ec := &eventdata.EventContext{}
+	db := newRelationDI()
+	cc := &api.APIResource{InstCli: db, Cache: memclient.NewMemRedis()}
+	api.GetAPIResource().CacheCli = &MockDI{}
+
+	transfer := &ModuleHostTransfer{HostIDs: []int{1, 2}, SrcAppID: 1, DstAppID: 1, DstModuleIDs: []int{3, 4}}
+	if err := TransferHostModuleRelation(ec, cc, transfer); nil != err {
+		t.Fatalf("error not as expected: %v", err)
+	}
+	expected := [][2]int{{1, 3}, {1, 4}, {2, 3}, {2, 4}}
+	if keys := relationKeys(db.rows); !reflect.DeepEqual(keys, expected) {
+		t.Errorf("relations not as expected: %v", keys)
+	}
+	if 0 != db.logs {
+		t.Errorf("the log should be discarded, logs: %d", db.logs)
+	}
+}
+
+func TestTransferHostModuleRelationSrcModules(t *testing.T) {
+	ec := &eventdata.EventContext{}
+	db := newRelationDI()
+	cc := &api.APIResource{InstCli: db, Cache: memclient.NewMemRedis()}
+	api.GetAPIResource().CacheCli = &MockDI{}
+
+	// only the relations with the module 1 are removed, the relation already exists is kept
+	transfer := &ModuleHostTransfer{HostIDs: []int{1}, SrcAppID: 1, SrcModuleIDs: []int{1}, DstAppID: 1, DstModuleIDs: []int{2}}
+	if err := TransferHostModuleRelation(ec, cc, transfer); nil != err {
+		t.Fatalf("error not as expected: %v", err)
+	}
+	expected := [][2]int{{1, 2}, {2, 1}}
+	if keys := relationKeys(db.rows); !reflect.DeepEqual(keys, expected) {
+		t.Errorf("relations not as expected: %v", keys)
+	}
+}
+
+func TestTransferHostModuleRelationRollback(t *testing.T) {
+	ec := &eventdata.EventContext{}
+	api.GetAPIResource().CacheCli = &MockDI{}
+	expected := relationKeys(newRelationDI().rows)
+
+	// the transfer has 2 deletes and 4 inserts, fail at each of them
+	for failAt := 1; failAt <= 6; failAt++ {
+		db := newRelationDI()
+		db.failAt = failAt
+		cc := &api.APIResource{InstCli: db, Cache: memclient.NewMemRedis()}
+
+		transfer := &ModuleHostTransfer{HostIDs: []int{1, 2}, SrcAppID: 1, DstAppID: 1, DstModuleIDs: []int{3, 4}}
+		if err := TransferHostModuleRelation(ec, cc, transfer); nil == err {
+			t.Fatalf("fail at %d, error should not be nil", failAt)
+		}
+		if keys := relationKeys(db.rows); !reflect.DeepEqual(keys, expected) {
+			t.Errorf("fail at %d, relations not restored: %v", failAt, keys)
+		}
+	}
+}
+
+func TestTransferHostModuleRelationNoModule(t *testing.T) {
+	ec := &eventdata.EventContext{}
+	db := newRelationDI()
+	db.noModule = true
+	cc := &api.APIResource{InstCli: db, Cache: memclient.NewMemRedis()}
+
+	transfer := &ModuleHostTransfer{HostIDs: []int{1, 2}, SrcAppID: 1, DstAppID: 1, DstModuleIDs: []int{3}}
+	if err := TransferHostModuleRelation(ec, cc, transfer); nil == err {
+		t.Errorf("error should not be nil")
+	}
+	if 0 != db.writes {
+		t.Errorf("nothing should be written, writes: %d", db.writes)
+	}
+}
+
+func TestTransferHostModuleRelationLocked(t *testing.T) {
+	ec := &eventdata.EventContext{}
+	db := newRelationDI()
+	cc := &api.APIResource{InstCli: db, Cache: memclient.NewMemRedis()}
+	api.GetAPIResource().CacheCli = &MockDI{}
+	wait := hostTransferLockWait
+	hostTransferLockWait = 100 * time.Millisecond
+	defer func() { hostTransferLockWait = wait }()
+
+	// the host 2 is being transferred by the other
+	cc.Cache.Lock(hostTransferLockPrefix+"2", "other", time.Minute)
+	transfer := &ModuleHostTransfer{HostIDs: []int{2, 1}, SrcAppID: 1, DstAppID: 1, DstModuleIDs: []int{3}}
+	if err := TransferHostModuleRelation(ec, cc, transfer); ErrHostTransferLocked != err {
+		t.Fatalf("error not as expected: %v", err)
+	}
+	if 0 != db.writes {
+		t.Errorf("nothing should be written, writes: %d", db.writes)
+	}
+	// the lock of the host 1 is released
+	if locked, _ := cc.Cache.Lock(hostTransferLockPrefix+"1", "other", time.Minute); !locked {
+		t.Errorf("the host 1 should be unlocked")
+	}
+}
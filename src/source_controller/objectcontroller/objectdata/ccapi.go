@@ -16,10 +16,11 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/api"
 	"configcenter/src/common/core/cc/config"
+	"configcenter/src/common/discovery"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/http/httpserver"
+	"configcenter/src/common/types"
 	confCenter "configcenter/src/source_controller/objectcontroller/objectdata/config"
-	"configcenter/src/source_controller/objectcontroller/objectdata/rdiscover"
 	"configcenter/src/storage"
 	"sync"
 	"time"
@@ -29,7 +30,7 @@ import (
 type CCAPIServer struct {
 	conf     *config.CCAPIConfig
 	httpServ *httpserver.HttpServer
-	rd       *rdiscover.RegDiscover
+	rd       *discovery.RegDiscover
 	cfCenter *confCenter.ConfCenter
 }
 
@@ -48,10 +49,10 @@ func NewCCAPIServer(conf *config.CCAPIConfig) (*CCAPIServer, error) {
 	a.SetConfig(s.conf)
 	a.InitAction()
 
-	s.rd = rdiscover.NewRegDiscover(s.conf.RegDiscover, addr, port, false)
+	s.rd = discovery.NewRegDiscover(s.conf.GetRegDiscover(), types.CC_MODULE_OBJECTCONTROLLER, addr, port, false, discovery.Options{})
 
 	//ConfCenter
-	s.cfCenter = confCenter.NewConfCenter(s.conf.RegDiscover)
+	s.cfCenter = confCenter.NewConfCenter(s.conf.GetRegDiscover())
 
 	return s, nil
 }
@@ -152,6 +153,7 @@ func (ccAPI *CCAPIServer) InitHttpServ(config map[string]string) error {
 	chErr := make(chan error, 3)
 	a := api.NewAPIResource()
 	ccAPI.httpServ.RegisterWebServer("/object/{version}", nil, a.Actions)
+	a.RegisterHealth(ccAPI.httpServ)
 
 	wg := sync.WaitGroup{}
 
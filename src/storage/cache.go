@@ -0,0 +1,107 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package storage
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrCacheNil the key or the field does not exist, or the blocking pop timed out
+var ErrCacheNil = errors.New("cache: nil")
+
+// BitKey the bit of the key at the offset
+type BitKey struct {
+	Key    string
+	Offset int64
+}
+
+// Message the message received from the subscribed channel
+type Message struct {
+	Channel string
+	// Pattern the matched pattern if received by PSubscribe
+	Pattern string
+	Payload string
+}
+
+// Subscription the subscribed channels
+type Subscription interface {
+	// Receive block until a message arrives
+	Receive() (*Message, error)
+	Close() error
+}
+
+// Cache define the typed cache and queue interface,
+// the values are stored as strings, the numbers and bools are formatted as redis does
+type Cache interface {
+	// Get return ErrCacheNil if the key does not exist
+	Get(key string) (string, error)
+	// Set set the value, no expiration if the expiration is zero
+	Set(key string, value interface{}, expiration time.Duration) error
+	// SetNX set the value only if the key does not exist, return whether it is set
+	SetNX(key string, value interface{}, expiration time.Duration) (bool, error)
+	Del(keys ...string) error
+	Exists(key string) (bool, error)
+	// Expire return false if the key does not exist
+	Expire(key string, expiration time.Duration) (bool, error)
+	// TTL return -2s if the key does not exist, -1s if the key has no expiration
+	TTL(key string) (time.Duration, error)
+	// Keys return the keys matched the glob pattern
+	Keys(pattern string) ([]string, error)
+
+	// Incr increase the integer value of the key by one, return the new value
+	Incr(key string) (int64, error)
+	// IncrBy increase the integer value of the key, return the new value
+	IncrBy(key string, value int64) (int64, error)
+
+	GetBit(key string, offset int64) (int64, error)
+	SetBit(key string, offset int64, value int) error
+	// GetBits return the bits in one round trip
+	GetBits(bits []BitKey) ([]int64, error)
+
+	// HGet return ErrCacheNil if the key or the field does not exist
+	HGet(key, field string) (string, error)
+	HSet(key, field string, value interface{}) error
+	HMSet(key string, fields map[string]string) error
+	HGetAll(key string) (map[string]string, error)
+	HDel(key string, fields ...string) error
+	HExists(key, field string) (bool, error)
+	HLen(key string) (int64, error)
+	// HIncrBy increase the integer value of the field, return the new value
+	HIncrBy(key, field string, incr int64) (int64, error)
+
+	RPush(key string, values ...interface{}) error
+	// BLPop pop the first element of the first non-empty list, return the key and the element,
+	// block until the timeout if all the lists are empty, ErrCacheNil if timed out
+	BLPop(timeout time.Duration, keys ...string) ([]string, error)
+	// LRange return the elements between the start and the stop inclusive, the negative index counts from the end
+	LRange(key string, start, stop int64) ([]string, error)
+	LLen(key string) (int64, error)
+
+	SAdd(key string, members ...interface{}) error
+	SRem(key string, members ...interface{}) error
+	SMembers(key string) ([]string, error)
+
+	// Lock acquire the lock for the owner, or renew it if it is held by the owner already,
+	// return false if the lock is held by the others
+	Lock(key, owner string, expiration time.Duration) (bool, error)
+	// Unlock release the lock if it is held by the owner
+	Unlock(key, owner string) error
+
+	Publish(channel, message string) error
+	Subscribe(channels ...string) (Subscription, error)
+	// PSubscribe subscribe the channels matched the glob patterns
+	PSubscribe(patterns ...string) (Subscription, error)
+
+	Close()
+}
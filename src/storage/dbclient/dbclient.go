@@ -14,24 +14,33 @@ package dbclient
 
 import (
 	"configcenter/src/storage"
+	"configcenter/src/storage/memclient"
 	"configcenter/src/storage/mgoclient"
 	"configcenter/src/storage/redisclient"
 )
 
-// NewDB return DI instance
-func NewDB(host, port, usr, pwd, mechanism, database, driverType string) (storage.DI, error) {
+// NewDB return DI instance, mgoOpts is the options of the mongodb connection, nil keeps the defaults,
+// the document storages are wrapped to record the operation latency
+func NewDB(host, port, usr, pwd, mechanism, database, driverType string, mgoOpts *mgoclient.Options) (storage.DI, error) {
 	if driverType == storage.DI_MONGO {
-		db, err := mgoclient.NewMgoCli(host, port, usr, pwd, mechanism, database)
+		db, err := mgoclient.NewMgoCli(host, port, usr, pwd, mechanism, database, mgoOpts)
 		if err == nil {
-			return db, err
+			return storage.NewMetricDI(db), err
 		}
 		return db, err
+	} else if driverType == storage.DI_MEMORY {
+		return storage.NewMetricDI(memclient.NewMemDB()), nil
+	} else if driverType == storage.DI_MEMORY_REDIS {
+		return memclient.NewMemRedis(), nil
 	} else if driverType == storage.DI_REDIS {
 		db, err := redisclient.NewRedis(host, port, usr, pwd, database)
 		if err == nil {
 			return db, err
 		}
 	}
-	db, err := mgoclient.NewMgoCli(host, port, usr, pwd, mechanism, database)
+	db, err := mgoclient.NewMgoCli(host, port, usr, pwd, mechanism, database, mgoOpts)
+	if err == nil {
+		return storage.NewMetricDI(db), err
+	}
 	return db, err
 }
@@ -0,0 +1,50 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+
+package dbclient
+
+import (
+	"testing"
+
+	"configcenter/src/storage"
+	"configcenter/src/storage/mgoclient"
+)
+
+func TestNewDBSecondary(t *testing.T) {
+	db, err := NewDB("10.0.0.1", "27017", "user", "pwd", "", "cmdb", storage.DI_MONGO, nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if db != storage.Secondary(db) {
+		t.Fatal("expect the primary client without search read preference")
+	}
+
+	db, err = NewDB("10.0.0.1", "27017", "user", "pwd", "", "cmdb", storage.DI_MONGO, &mgoclient.Options{SearchReadPreference: "secondaryPreferred"})
+	if nil != err {
+		t.Fatal(err)
+	}
+	secondary := storage.Secondary(db)
+	if db == secondary {
+		t.Fatal("expect the secondary client through the metric wrapper")
+	}
+	if _, ok := secondary.(*mgoclient.MgoCli); ok {
+		t.Fatal("expect the secondary client wrapped to record the latency")
+	}
+	if secondary != storage.Secondary(secondary) {
+		t.Fatal("expect the secondary client reused")
+	}
+
+	if _, err = NewDB("10.0.0.1", "27017", "user", "pwd", "", "cmdb", storage.DI_MONGO, &mgoclient.Options{SearchReadPreference: "bad"}); nil == err {
+		t.Fatal("expect error of the invalid read preference")
+	}
+}
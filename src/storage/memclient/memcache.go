@@ -0,0 +1,533 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package memclient
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/storage"
+)
+
+// errSubscriptionClosed receive from the closed subscription
+var errSubscriptionClosed = errors.New("subscription closed")
+
+func (r *MemRedis) Get(key string) (string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.getString(key)
+	if nil != err {
+		return "", err
+	}
+	if nil == entry {
+		return "", storage.ErrCacheNil
+	}
+	return entry.str, nil
+}
+
+func (r *MemRedis) Set(key string, value interface{}, expiration time.Duration) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.set(key, value, expiration)
+	return nil
+}
+
+func (r *MemRedis) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if nil != r.get(key) {
+		return false, nil
+	}
+	r.set(key, value, expiration)
+	return true, nil
+}
+
+func (r *MemRedis) Del(keys ...string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, key := range keys {
+		delete(r.keys, key)
+	}
+	return nil
+}
+
+func (r *MemRedis) Exists(key string) (bool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return nil != r.get(key), nil
+}
+
+func (r *MemRedis) Expire(key string, expiration time.Duration) (bool, error) {
+	return r.expireAt(key, time.Now().Add(expiration)), nil
+}
+
+func (r *MemRedis) TTL(key string) (time.Duration, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry := r.get(key)
+	switch {
+	case nil == entry:
+		return -2 * time.Second, nil
+	case entry.expireAt.IsZero():
+		return -1 * time.Second, nil
+	}
+	return time.Duration(entry.expireAt.Sub(time.Now()).Seconds()) * time.Second, nil
+}
+
+func (r *MemRedis) Keys(pattern string) ([]string, error) {
+	re, err := globToRegexp(pattern)
+	if nil != err {
+		return nil, err
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	keys := make([]string, 0)
+	for key := range r.keys {
+		if nil != r.get(key) && re.MatchString(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (r *MemRedis) Incr(key string) (int64, error) {
+	return r.IncrBy(key, 1)
+}
+
+func (r *MemRedis) IncrBy(key string, value int64) (int64, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.incrBy(key, value)
+}
+
+func (r *MemRedis) GetBit(key string, offset int64) (int64, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.getBit(key, offset)
+}
+
+func (r *MemRedis) getBit(key string, offset int64) (int64, error) {
+	entry, err := r.getString(key)
+	if nil != err || nil == entry || offset/8 >= int64(len(entry.str)) {
+		return 0, err
+	}
+	return int64(entry.str[offset/8]>>uint(7-offset%8)) & 1, nil
+}
+
+func (r *MemRedis) SetBit(key string, offset int64, value int) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.getString(key)
+	if nil != err {
+		return err
+	}
+	if nil == entry {
+		entry = &memEntry{}
+		r.keys[key] = entry
+	}
+	data := []byte(entry.str)
+	for int64(len(data)) <= offset/8 {
+		data = append(data, 0)
+	}
+	mask := byte(1) << uint(7-offset%8)
+	if 0 == value {
+		data[offset/8] &^= mask
+	} else {
+		data[offset/8] |= mask
+	}
+	entry.str = string(data)
+	return nil
+}
+
+func (r *MemRedis) GetBits(bits []storage.BitKey) ([]int64, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	result := make([]int64, 0, len(bits))
+	for _, bit := range bits {
+		val, err := r.getBit(bit.Key, bit.Offset)
+		if nil != err {
+			return nil, err
+		}
+		result = append(result, val)
+	}
+	return result, nil
+}
+
+func (r *MemRedis) HGet(key, field string) (string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.getHash(key, false)
+	if nil != err {
+		return "", err
+	}
+	if nil == entry {
+		return "", storage.ErrCacheNil
+	}
+	val, ok := entry.hash[field]
+	if !ok {
+		return "", storage.ErrCacheNil
+	}
+	return val, nil
+}
+
+func (r *MemRedis) HSet(key, field string, value interface{}) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.getHash(key, true)
+	if nil != err {
+		return err
+	}
+	entry.hash[field] = formatArg(value)
+	return nil
+}
+
+func (r *MemRedis) HMSet(key string, fields map[string]string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.getHash(key, true)
+	if nil != err {
+		return err
+	}
+	for field, value := range fields {
+		entry.hash[field] = value
+	}
+	return nil
+}
+
+func (r *MemRedis) HGetAll(key string) (map[string]string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.getHash(key, false)
+	if nil != err {
+		return nil, err
+	}
+	values := make(map[string]string)
+	if nil != entry {
+		for field, value := range entry.hash {
+			values[field] = value
+		}
+	}
+	return values, nil
+}
+
+func (r *MemRedis) HDel(key string, fields ...string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.getHash(key, false)
+	if nil != err || nil == entry {
+		return err
+	}
+	for _, field := range fields {
+		delete(entry.hash, field)
+	}
+	if 0 == len(entry.hash) {
+		delete(r.keys, key)
+	}
+	return nil
+}
+
+func (r *MemRedis) HExists(key, field string) (bool, error) {
+	_, err := r.HGet(key, field)
+	if storage.ErrCacheNil == err {
+		return false, nil
+	}
+	return nil == err, err
+}
+
+func (r *MemRedis) HLen(key string) (int64, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.getHash(key, false)
+	if nil != err || nil == entry {
+		return 0, err
+	}
+	return int64(len(entry.hash)), nil
+}
+
+func (r *MemRedis) HIncrBy(key, field string, incr int64) (int64, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.getHash(key, true)
+	if nil != err {
+		return 0, err
+	}
+	val := int64(0)
+	if str, ok := entry.hash[field]; ok {
+		if val, err = strconv.ParseInt(str, 10, 64); nil != err {
+			return 0, errors.New("ERR hash value is not an integer")
+		}
+	}
+	val += incr
+	entry.hash[field] = strconv.FormatInt(val, 10)
+	return val, nil
+}
+
+func (r *MemRedis) RPush(key string, values ...interface{}) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.getList(key, true)
+	if nil != err {
+		return err
+	}
+	for _, value := range values {
+		entry.list = append(entry.list, formatArg(value))
+	}
+	return nil
+}
+
+// BLPop poll the lists until the timeout, block forever if the timeout is zero as redis does
+func (r *MemRedis) BLPop(timeout time.Duration, keys ...string) ([]string, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		popped, err := r.lpop(keys)
+		if nil != err || nil != popped {
+			return popped, err
+		}
+		if 0 < timeout && !time.Now().Before(deadline) {
+			return nil, storage.ErrCacheNil
+		}
+		time.Sleep(blpopPollDelay)
+	}
+}
+
+func (r *MemRedis) LRange(key string, start, stop int64) ([]string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.getList(key, false)
+	if nil != err {
+		return nil, err
+	}
+	values := make([]string, 0)
+	if nil != entry {
+		from, to := getRange(start, stop, len(entry.list))
+		values = append(values, entry.list[from:to]...)
+	}
+	return values, nil
+}
+
+func (r *MemRedis) LLen(key string) (int64, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.getList(key, false)
+	if nil != err || nil == entry {
+		return 0, err
+	}
+	return int64(len(entry.list)), nil
+}
+
+func (r *MemRedis) SAdd(key string, members ...interface{}) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.getSet(key, true)
+	if nil != err {
+		return err
+	}
+	for _, member := range members {
+		entry.set[formatArg(member)] = struct{}{}
+	}
+	return nil
+}
+
+func (r *MemRedis) SRem(key string, members ...interface{}) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.getSet(key, false)
+	if nil != err || nil == entry {
+		return err
+	}
+	for _, member := range members {
+		delete(entry.set, formatArg(member))
+	}
+	if 0 == len(entry.set) {
+		delete(r.keys, key)
+	}
+	return nil
+}
+
+// SMembers return the members in order, redis returns them in no order
+func (r *MemRedis) SMembers(key string) ([]string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.getSet(key, false)
+	if nil != err {
+		return nil, err
+	}
+	members := make([]string, 0)
+	if nil != entry {
+		for member := range entry.set {
+			members = append(members, member)
+		}
+	}
+	sort.Strings(members)
+	return members, nil
+}
+
+func (r *MemRedis) Lock(key, owner string, expiration time.Duration) (bool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.getString(key)
+	if nil != err {
+		return false, err
+	}
+	if nil != entry && owner != entry.str {
+		return false, nil
+	}
+	r.set(key, owner, expiration)
+	return true, nil
+}
+
+func (r *MemRedis) Unlock(key, owner string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if entry, err := r.getString(key); nil == err && nil != entry && owner == entry.str {
+		delete(r.keys, key)
+	}
+	return nil
+}
+
+// Publish deliver the message to the subscriptions, it never blocks
+func (r *MemRedis) Publish(channel, message string) error {
+	r.lock.Lock()
+	subs := make([]*memSubscription, 0, len(r.subs))
+	for sub := range r.subs {
+		subs = append(subs, sub)
+	}
+	r.lock.Unlock()
+	for _, sub := range subs {
+		sub.deliver(channel, message)
+	}
+	return nil
+}
+
+func (r *MemRedis) Subscribe(channels ...string) (storage.Subscription, error) {
+	sub := &memSubscription{owner: r, channels: channels}
+	return sub, r.subscribe(sub)
+}
+
+func (r *MemRedis) PSubscribe(patterns ...string) (storage.Subscription, error) {
+	sub := &memSubscription{owner: r, patterns: make(map[string]*regexp.Regexp, len(patterns))}
+	for _, pattern := range patterns {
+		re, err := globToRegexp(pattern)
+		if nil != err {
+			return nil, err
+		}
+		sub.patterns[pattern] = re
+	}
+	return sub, r.subscribe(sub)
+}
+
+func (r *MemRedis) subscribe(sub *memSubscription) error {
+	sub.cond = sync.NewCond(&sub.lock)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.subs[sub] = struct{}{}
+	return nil
+}
+
+// memSubscription queue the published messages until they are received
+type memSubscription struct {
+	owner    *MemRedis
+	channels []string
+	patterns map[string]*regexp.Regexp
+
+	lock   sync.Mutex
+	cond   *sync.Cond
+	queue  []*storage.Message
+	closed bool
+}
+
+func (s *memSubscription) deliver(channel, payload string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, subscribed := range s.channels {
+		if subscribed == channel {
+			s.queue = append(s.queue, &storage.Message{Channel: channel, Payload: payload})
+		}
+	}
+	for pattern, re := range s.patterns {
+		if re.MatchString(channel) {
+			s.queue = append(s.queue, &storage.Message{Channel: channel, Pattern: pattern, Payload: payload})
+		}
+	}
+	s.cond.Broadcast()
+}
+
+func (s *memSubscription) Receive() (*storage.Message, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for 0 == len(s.queue) && !s.closed {
+		s.cond.Wait()
+	}
+	if s.closed {
+		return nil, errSubscriptionClosed
+	}
+	msg := s.queue[0]
+	s.queue = s.queue[1:]
+	return msg, nil
+}
+
+func (s *memSubscription) Close() error {
+	s.owner.lock.Lock()
+	delete(s.owner.subs, s)
+	s.owner.lock.Unlock()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.closed = true
+	s.cond.Broadcast()
+	return nil
+}
+
+// globToRegexp convert the redis glob pattern, which supports *, ?, [...] and the escaping by \
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	expr := bytes.Buffer{}
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			expr.WriteString("(?s:.*)")
+		case '?':
+			expr.WriteString("(?s:.)")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "^") {
+				class = "^" + regexp.QuoteMeta(class[1:])
+			} else {
+				class = regexp.QuoteMeta(class)
+			}
+			expr.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
@@ -0,0 +1,135 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package memclient
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"configcenter/src/storage"
+)
+
+func TestMemCache(t *testing.T) {
+	var c storage.Cache = NewMemRedis()
+
+	if _, err := c.Get("k"); storage.ErrCacheNil != err {
+		t.Errorf("expect nil, got %v", err)
+	}
+	c.Set("k", 1.5, 0)
+	if val, _ := c.Get("k"); "1.5" != val {
+		t.Errorf("unexpected value %s", val)
+	}
+	if ok, _ := c.SetNX("k", "x", 0); ok {
+		t.Error("the existing key should not be set")
+	}
+	if _, err := c.Incr("k"); nil == err {
+		t.Error("the float should not be increased")
+	}
+	if val, _ := c.IncrBy("n", 3); 3 != val {
+		t.Errorf("unexpected value %d", val)
+	}
+
+	c.SetBit("bits", 9, 1)
+	if bits, _ := c.GetBits([]storage.BitKey{
+		{Key: "bits", Offset: 9}, {Key: "bits", Offset: 8}, {Key: "bits", Offset: 100}, {Key: "missing", Offset: 1},
+	}); !reflect.DeepEqual(bits, []int64{1, 0, 0, 0}) {
+		t.Errorf("unexpected bits %v", bits)
+	}
+
+	if val, _ := c.HIncrBy("h", "total", 2); 2 != val {
+		t.Errorf("unexpected value %d", val)
+	}
+	if _, err := c.HGet("h", "failure"); storage.ErrCacheNil != err {
+		t.Errorf("expect nil, got %v", err)
+	}
+	if ok, _ := c.HExists("h", "total"); !ok {
+		t.Error("the field should exist")
+	}
+
+	if keys, _ := c.Keys("[bh]*"); !reflect.DeepEqual(keys, []string{"bits", "h"}) {
+		t.Errorf("unexpected keys %v", keys)
+	}
+	if keys, _ := c.Keys("?"); !reflect.DeepEqual(keys, []string{"h", "k", "n"}) {
+		t.Errorf("unexpected keys %v", keys)
+	}
+
+	c.RPush("q", "a", 2)
+	if n, _ := c.LLen("q"); 2 != n {
+		t.Errorf("unexpected length %d", n)
+	}
+	if val, _ := c.BLPop(time.Second, "empty", "q"); !reflect.DeepEqual(val, []string{"q", "a"}) {
+		t.Errorf("unexpected pop %v", val)
+	}
+	c.BLPop(time.Second, "q")
+	if _, err := c.BLPop(10*time.Millisecond, "q"); storage.ErrCacheNil != err {
+		t.Errorf("expect nil, got %v", err)
+	}
+}
+
+func TestMemCacheLock(t *testing.T) {
+	c := NewMemRedis()
+	if ok, _ := c.Lock("lock", "a", time.Minute); !ok {
+		t.Error("a should acquire the lock")
+	}
+	if ok, _ := c.Lock("lock", "b", time.Minute); ok {
+		t.Error("b should not acquire the lock held by a")
+	}
+	if ok, _ := c.Lock("lock", "a", time.Minute); !ok {
+		t.Error("a should renew the lock")
+	}
+	c.Unlock("lock", "b")
+	if ok, _ := c.Exists("lock"); !ok {
+		t.Error("b should not release the lock held by a")
+	}
+	c.Unlock("lock", "a")
+	if ok, _ := c.Lock("lock", "b", time.Millisecond); !ok {
+		t.Error("b should acquire the released lock")
+	}
+	time.Sleep(2 * time.Millisecond)
+	if ok, _ := c.Lock("lock", "a", time.Minute); !ok {
+		t.Error("a should acquire the expired lock")
+	}
+}
+
+func TestMemCachePubSub(t *testing.T) {
+	c := NewMemRedis()
+	sub, err := c.Subscribe("snapshot")
+	if nil != err {
+		t.Fatal(err)
+	}
+	psub, err := c.PSubscribe("event_*")
+	if nil != err {
+		t.Fatal(err)
+	}
+	c.Publish("snapshot", "1")
+	c.Publish("event_process", "2")
+	c.Publish("other", "3")
+
+	if msg, _ := sub.Receive(); !reflect.DeepEqual(msg, &storage.Message{Channel: "snapshot", Payload: "1"}) {
+		t.Errorf("unexpected message %v", msg)
+	}
+	if msg, _ := psub.Receive(); !reflect.DeepEqual(msg, &storage.Message{Channel: "event_process", Pattern: "event_*", Payload: "2"}) {
+		t.Errorf("unexpected message %v", msg)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := sub.Receive()
+		done <- err
+	}()
+	sub.Close()
+	if err := <-done; nil == err {
+		t.Error("receive from the closed subscription should fail")
+	}
+}
@@ -0,0 +1,444 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+// Package memclient the in-memory storage.DI backends, for the tests without mongodb and redis
+package memclient
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"configcenter/src/storage"
+	"configcenter/src/storage/mgoclient"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// MemDB the in-memory storage.DI with the semantics of mongodb,
+// the documents are kept as bson so the types are the same as they are read from mongodb,
+// the unique indexes are enforced, the expiration of the indexes is not
+type MemDB struct {
+	lock   sync.RWMutex
+	tables map[string]*memTable
+	seqs   map[string]int64
+}
+
+type memTable struct {
+	docs    []bson.M
+	indexes []*storage.Index
+}
+
+// NewMemDB return an empty in-memory db
+func NewMemDB() *MemDB {
+	return &MemDB{
+		tables: make(map[string]*memTable),
+		seqs:   make(map[string]int64),
+	}
+}
+
+// Open nothing to open
+func (m *MemDB) Open() error {
+	return nil
+}
+
+// Close nothing to close
+func (m *MemDB) Close() {
+}
+
+// GetSession return the db itself
+func (m *MemDB) GetSession() interface{} {
+	return m
+}
+
+// Ping always succeed as the db is in memory
+func (m *MemDB) Ping() error {
+	return nil
+}
+
+// StartTransaction start a compensating transaction as the mongodb client does
+func (m *MemDB) StartTransaction() (storage.Tx, error) {
+	return storage.NewCompensatingTx(m), nil
+}
+
+// GetType return the memory driver type
+func (m *MemDB) GetType() string {
+	return storage.DI_MEMORY
+}
+
+// table return the table, it is created if not exists
+func (m *MemDB) table(cName string) *memTable {
+	t, ok := m.tables[cName]
+	if !ok {
+		t = &memTable{}
+		m.tables[cName] = t
+	}
+	return t
+}
+
+// find return the indexes of the documents matched the condition
+func (m *MemDB) find(cName string, condiction interface{}) ([]int, error) {
+	cond, err := toDoc(condiction)
+	if nil != err {
+		return nil, err
+	}
+	t, ok := m.tables[cName]
+	if !ok {
+		return []int{}, nil
+	}
+	matched := make([]int, 0)
+	for i, doc := range t.docs {
+		ok, err := match(doc, cond)
+		if nil != err {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, i)
+		}
+	}
+	return matched, nil
+}
+
+// query return the copies of the documents matched the condition, sorted by the sorts
+func (m *MemDB) query(cName string, fields []string, condiction interface{}, keepID bool, sorts ...string) ([]bson.M, error) {
+	matched, err := m.find(cName, condiction)
+	if nil != err {
+		return nil, err
+	}
+	if len(fields) == 1 && fields[0] == "" {
+		fields = nil
+	}
+	docs := make([]bson.M, 0, len(matched))
+	for _, i := range matched {
+		docs = append(docs, m.tables[cName].docs[i])
+	}
+	sortDocs(docs, sorts...)
+	rows := make([]bson.M, 0, len(docs))
+	for _, doc := range docs {
+		rows = append(rows, project(doc, fields, keepID))
+	}
+	return rows, nil
+}
+
+// checkUnique check the document against the unique indexes and the _id, the skip-th document is ignored
+func (t *memTable) checkUnique(cName string, doc bson.M, skip int) error {
+	indexes := append([]*storage.Index{{Name: "_id_", Columns: []string{"_id"}, Type: storage.INDEX_TYPE_UNIQUE}}, t.indexes...)
+	for _, index := range indexes {
+		switch index.Type {
+		case storage.INDEX_TYPE_UNIQUE, storage.INDEX_TYPE_BACKGROUP_UNIQUE, storage.INDEX_TYPE_PRIMAEY:
+		default:
+			continue
+		}
+		for i, other := range t.docs {
+			if i != skip && sameIndexKey(doc, other, index.Columns) {
+				return &mgo.LastError{Code: 11000, Err: fmt.Sprintf("E11000 duplicate key error collection: %s index: %s", cName, index.Name)}
+			}
+		}
+	}
+	return nil
+}
+
+func sameIndexKey(a, b bson.M, columns []string) bool {
+	for _, column := range columns {
+		column = strings.TrimLeft(column, "+-")
+		valA, _ := getField(a, column)
+		valB, _ := getField(b, column)
+		if !equal(valA, valB) {
+			return false
+		}
+	}
+	return true
+}
+
+// Insert insert one document, the html in the strings is escaped as the mongodb client does
+func (m *MemDB) Insert(cName string, data interface{}) (int, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return 0, m.insert(cName, data)
+}
+
+func (m *MemDB) insert(cName string, data interface{}) error {
+	mgoclient.EscapeHtml(data)
+	doc, err := toDoc(data)
+	if nil != err {
+		return err
+	}
+	if _, ok := doc["_id"]; !ok {
+		doc["_id"] = bson.NewObjectId()
+	}
+	t := m.table(cName)
+	if err := t.checkUnique(cName, doc, -1); nil != err {
+		return err
+	}
+	t.docs = append(t.docs, doc)
+	return nil
+}
+
+// InsertMuti insert the documents in order, stop at the first failure
+func (m *MemDB) InsertMuti(cName string, data ...interface{}) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, item := range data {
+		if err := m.insert(cName, item); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateByCondition set the fields of the documents matched the condition
+func (m *MemDB) UpdateByCondition(cName string, data, condiction interface{}) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	mgoclient.EscapeHtml(data)
+	set, err := toDoc(data)
+	if nil != err {
+		return err
+	}
+	delete(set, "_id")
+	return m.update(cName, condiction, func(doc bson.M) {
+		for field, value := range set {
+			setField(doc, field, value)
+		}
+	})
+}
+
+// update update the copies of the documents matched the condition, and replace them if the unique indexes are kept
+func (m *MemDB) update(cName string, condiction interface{}, updateFunc func(doc bson.M)) error {
+	matched, err := m.find(cName, condiction)
+	if nil != err {
+		return err
+	}
+	t := m.table(cName)
+	for _, i := range matched {
+		doc, err := toDoc(t.docs[i])
+		if nil != err {
+			return err
+		}
+		updateFunc(doc)
+		if err := t.checkUnique(cName, doc, i); nil != err {
+			return err
+		}
+		t.docs[i] = doc
+	}
+	return nil
+}
+
+// GetOneByCondition get the first document matched the condition, mgo.ErrNotFound if none
+func (m *MemDB) GetOneByCondition(cName string, fields []string, condiction interface{}, result interface{}) error {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	rows, err := m.query(cName, fields, condiction, false)
+	if nil != err {
+		return err
+	}
+	if 0 == len(rows) {
+		return mgo.ErrNotFound
+	}
+	raw, err := bson.Marshal(rows[0])
+	if nil != err {
+		return err
+	}
+	return bson.Unmarshal(raw, result)
+}
+
+// GetMutilByCondition get the documents matched the condition
+func (m *MemDB) GetMutilByCondition(cName string, fields []string, condiction interface{}, result interface{}, sort string, start, limit int) error {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	rows, err := m.query(cName, fields, condiction, false, sort)
+	if nil != err {
+		return err
+	}
+	return mgoclient.UnmarshalRows(page(rows, start, limit), result)
+}
+
+// GetMutilByCursor get the page after the cursor as the mongodb client does
+func (m *MemDB) GetMutilByCursor(cName string, fields []string, condiction interface{}, result interface{}, sort, cursor string, limit int) (string, error) {
+	q, err := mgoclient.NewCursorQuery(condiction, sort, cursor)
+	if nil != err {
+		return "", err
+	}
+	if nil == q {
+		return "", m.GetMutilByCondition(cName, fields, condiction, result, sort, 0, limit)
+	}
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if len(fields) == 1 && fields[0] == "" {
+		fields = nil
+	}
+	selected := make([]string, 0)
+	for field := range q.Select(fields) {
+		selected = append(selected, field)
+	}
+	rows, err := m.query(cName, selected, q.Condition, true, q.Sort...)
+	if nil != err {
+		return "", err
+	}
+	if 0 < limit {
+		rows = page(rows, 0, limit+1)
+	}
+	rows, next, err := q.Page(rows, fields, limit)
+	if nil != err {
+		return "", err
+	}
+	return next, mgoclient.UnmarshalRows(rows, result)
+}
+
+func page(rows []bson.M, start, limit int) []bson.M {
+	if start >= len(rows) {
+		return rows[:0]
+	}
+	if 0 < start {
+		rows = rows[start:]
+	}
+	if 0 < limit && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+// GetCntByCondition return the count of the documents matched the condition
+func (m *MemDB) GetCntByCondition(cName string, condiction interface{}) (int, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	matched, err := m.find(cName, condiction)
+	return len(matched), err
+}
+
+// GetIncID return the next sequence of the collection
+func (m *MemDB) GetIncID(cName string) (int64, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.seqs[cName]++
+	return m.seqs[cName], nil
+}
+
+// DelByCondition delete the documents matched the condition
+func (m *MemDB) DelByCondition(cName string, condiction interface{}) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	matched, err := m.find(cName, condiction)
+	if nil != err || 0 == len(matched) {
+		return err
+	}
+	t := m.tables[cName]
+	docs := make([]bson.M, 0, len(t.docs)-len(matched))
+	for i, doc := range t.docs {
+		if 0 < len(matched) && i == matched[0] {
+			matched = matched[1:]
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	t.docs = docs
+	return nil
+}
+
+// HasTable return whether the collection exists
+func (m *MemDB) HasTable(cName string) (bool, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	_, ok := m.tables[cName]
+	return ok, nil
+}
+
+// CreateTable create the collection
+func (m *MemDB) CreateTable(cName string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.tables[cName]; ok {
+		return fmt.Errorf("collection already exists")
+	}
+	m.table(cName)
+	return nil
+}
+
+// DropTable drop the collection and its indexes
+func (m *MemDB) DropTable(cName string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.tables[cName]; !ok {
+		return fmt.Errorf("ns not found")
+	}
+	delete(m.tables, cName)
+	return nil
+}
+
+// ExecSql not supported as the mongodb client
+func (m *MemDB) ExecSql(cmd interface{}) error {
+	return errors.New("not support method")
+}
+
+// Index create the index, the documents are checked if the index is unique
+func (m *MemDB) Index(cName string, index *storage.Index) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	t := m.table(cName)
+	check := &memTable{indexes: []*storage.Index{index}}
+	for _, doc := range t.docs {
+		if err := check.checkUnique(cName, doc, -1); nil != err {
+			return err
+		}
+		check.docs = append(check.docs, doc)
+	}
+	for i, existing := range t.indexes {
+		if strings.Join(existing.Columns, ",") == strings.Join(index.Columns, ",") {
+			t.indexes[i] = index
+			return nil
+		}
+	}
+	t.indexes = append(t.indexes, index)
+	return nil
+}
+
+// HasFields return whether any document has the field
+func (m *MemDB) HasFields(cName, field string) (bool, error) {
+	cnt, err := m.GetCntByCondition(cName, bson.M{field: bson.M{"$exists": true}})
+	return cnt > 0, err
+}
+
+// AddColumn set the field of the documents which have not the field
+func (m *MemDB) AddColumn(cName string, column *storage.Column) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	value, err := toValue(column.Ext)
+	if nil != err {
+		return err
+	}
+	return m.update(cName, bson.M{column.Name: bson.M{"$exists": false}}, func(doc bson.M) {
+		setField(doc, column.Name, value)
+	})
+}
+
+// ModifyColumn rename the field of the documents
+func (m *MemDB) ModifyColumn(cName, oldName, newColumn string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.update(cName, bson.M{oldName: bson.M{"$exists": true}}, func(doc bson.M) {
+		value, _ := getField(doc, oldName)
+		unsetField(doc, oldName)
+		setField(doc, newColumn, value)
+	})
+}
+
+// DropColumn remove the field of the documents
+func (m *MemDB) DropColumn(cName, field string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.update(cName, nil, func(doc bson.M) {
+		unsetField(doc, field)
+	})
+}
@@ -0,0 +1,285 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package memclient
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"configcenter/src/storage"
+
+	"gopkg.in/mgo.v2"
+)
+
+type testHost struct {
+	HostID   int64             `bson:"bk_host_id"`
+	InnerIP  string            `bson:"bk_host_innerip"`
+	CloudID  int64             `bson:"bk_cloud_id"`
+	Comment  string            `bson:"bk_comment"`
+	Metadata map[string]string `bson:"metadata,omitempty"`
+}
+
+func newTestDB(t *testing.T) *MemDB {
+	db := NewMemDB()
+	err := db.Index("cc_HostBase", storage.GetMongoIndex("", []string{"bk_host_innerip", "bk_cloud_id"}, true, false))
+	if nil != err {
+		t.Fatal(err)
+	}
+	for i, ip := range []string{"10.0.0.3", "10.0.0.1", "192.168.1.1", "10.0.0.2"} {
+		id, err := db.GetIncID("cc_HostBase")
+		if nil != err {
+			t.Fatal(err)
+		}
+		host := testHost{HostID: id, InnerIP: ip, CloudID: int64(i % 2), Metadata: map[string]string{"zone": ip[:2]}}
+		if _, err := db.Insert("cc_HostBase", host); nil != err {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func hostIDs(hosts []testHost) []int64 {
+	ids := make([]int64, 0, len(hosts))
+	for _, host := range hosts {
+		ids = append(ids, host.HostID)
+	}
+	return ids
+}
+
+func TestMemDBQuery(t *testing.T) {
+	db := newTestDB(t)
+	var _ storage.DI = db
+
+	hosts := make([]testHost, 0)
+	cond := map[string]interface{}{"metadata.zone": "10", "bk_host_id": map[string]interface{}{"$ne": 2}}
+	if err := db.GetMutilByCondition("cc_HostBase", nil, cond, &hosts, "-bk_host_innerip", 0, 0); nil != err {
+		t.Fatal(err)
+	}
+	if ids := hostIDs(hosts); !reflect.DeepEqual(ids, []int64{1, 4}) {
+		t.Errorf("unexpected hosts %v", ids)
+	}
+
+	hosts = make([]testHost, 0)
+	if err := db.GetMutilByCondition("cc_HostBase", []string{"bk_host_id"}, nil, &hosts, "bk_host_innerip", 1, 2); nil != err {
+		t.Fatal(err)
+	}
+	if ids := hostIDs(hosts); !reflect.DeepEqual(ids, []int64{4, 1}) || "" != hosts[0].InnerIP {
+		t.Errorf("unexpected hosts %v", hosts)
+	}
+
+	cnt, err := db.GetCntByCondition("cc_HostBase", map[string]interface{}{"bk_cloud_id": 1})
+	if nil != err || 2 != cnt {
+		t.Errorf("unexpected count %d, %v", cnt, err)
+	}
+
+	host := map[string]interface{}{}
+	if err := db.GetOneByCondition("cc_HostBase", []string{"bk_host_innerip"}, map[string]interface{}{"bk_host_id": 3}, &host); nil != err {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(host, map[string]interface{}{"bk_host_innerip": "192.168.1.1"}) {
+		t.Errorf("unexpected host %v", host)
+	}
+	if err := db.GetOneByCondition("cc_HostBase", nil, map[string]interface{}{"bk_host_id": 9}, &host); mgo.ErrNotFound != err {
+		t.Errorf("expect not found, got %v", err)
+	}
+}
+
+func TestMemDBWrite(t *testing.T) {
+	db := newTestDB(t)
+
+	if _, err := db.Insert("cc_HostBase", testHost{HostID: 5, InnerIP: "10.0.0.1", CloudID: 1}); !mgo.IsDup(err) {
+		t.Errorf("expect duplicate key error, got %v", err)
+	}
+	if err := db.UpdateByCondition("cc_HostBase", map[string]interface{}{"bk_host_innerip": "10.0.0.2", "bk_cloud_id": 1}, map[string]interface{}{"bk_host_id": 1}); !mgo.IsDup(err) {
+		t.Errorf("expect duplicate key error, got %v", err)
+	}
+
+	err := db.UpdateByCondition("cc_HostBase", map[string]interface{}{"bk_comment": "<b>", "metadata.zone": "xx"}, map[string]interface{}{"bk_host_id": map[string]interface{}{"$in": []int64{1, 2}}})
+	if nil != err {
+		t.Fatal(err)
+	}
+	hosts := make([]testHost, 0)
+	if err := db.GetMutilByCondition("cc_HostBase", nil, map[string]interface{}{"metadata.zone": "xx"}, &hosts, "bk_host_id", 0, 0); nil != err {
+		t.Fatal(err)
+	}
+	if ids := hostIDs(hosts); !reflect.DeepEqual(ids, []int64{1, 2}) || "&lt;b&gt;" != hosts[0].Comment {
+		t.Errorf("unexpected hosts %v", hosts)
+	}
+
+	if err := db.DelByCondition("cc_HostBase", map[string]interface{}{"bk_cloud_id": 0}); nil != err {
+		t.Fatal(err)
+	}
+	if cnt, _ := db.GetCntByCondition("cc_HostBase", nil); 2 != cnt {
+		t.Errorf("unexpected count %d", cnt)
+	}
+
+	if err := db.ModifyColumn("cc_HostBase", "bk_comment", "comment"); nil != err {
+		t.Fatal(err)
+	}
+	if ok, _ := db.HasFields("cc_HostBase", "comment"); !ok {
+		t.Error("the column should be renamed")
+	}
+	if err := db.DropColumn("cc_HostBase", "comment"); nil != err {
+		t.Fatal(err)
+	}
+	if ok, _ := db.HasFields("cc_HostBase", "comment"); ok {
+		t.Error("the column should be dropped")
+	}
+
+	if err := db.DropTable("cc_HostBase"); nil != err {
+		t.Fatal(err)
+	}
+	if ok, _ := db.HasTable("cc_HostBase"); ok {
+		t.Error("the table should be dropped")
+	}
+	if err := db.DropTable("cc_HostBase"); nil == err {
+		t.Error("drop the missing table should fail")
+	}
+}
+
+func TestMemDBCursor(t *testing.T) {
+	db := newTestDB(t)
+	ids := make([]int64, 0)
+	cursor := ""
+	for i := 0; i < 3; i++ {
+		hosts := make([]testHost, 0)
+		next, err := db.GetMutilByCursor("cc_HostBase", []string{"bk_host_id"}, nil, &hosts, "-bk_host_innerip", cursor, 3)
+		if nil != err {
+			t.Fatal(err)
+		}
+		ids = append(ids, hostIDs(hosts)...)
+		if "" != hosts[0].InnerIP {
+			t.Errorf("the sort field should not be returned, %v", hosts)
+		}
+		if "" == next {
+			break
+		}
+		cursor = next
+	}
+	if !reflect.DeepEqual(ids, []int64{3, 1, 4, 2}) {
+		t.Errorf("unexpected hosts %v", ids)
+	}
+
+	hosts := make([]testHost, 0)
+	if _, err := db.GetMutilByCursor("cc_HostBase", nil, nil, &hosts, "bk_host_id", cursor, 3); storage.ErrInvalidCursor != err {
+		t.Errorf("expect invalid cursor, got %v", err)
+	}
+}
+
+func TestMemDBTransaction(t *testing.T) {
+	db := newTestDB(t)
+	tx, err := db.StartTransaction()
+	if nil != err {
+		t.Fatal(err)
+	}
+	if _, err := tx.Insert("cc_HostBase", testHost{HostID: 5, InnerIP: "10.0.0.5"}); nil != err {
+		t.Fatal(err)
+	}
+	if err := tx.DelByCondition("cc_HostBase", map[string]interface{}{"bk_host_id": 1}); nil != err {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); nil != err {
+		t.Fatal(err)
+	}
+	hosts := make([]testHost, 0)
+	if err := db.GetMutilByCondition("cc_HostBase", nil, nil, &hosts, "bk_host_id", 0, 0); nil != err {
+		t.Fatal(err)
+	}
+	if ids := hostIDs(hosts); !reflect.DeepEqual(ids, []int64{1, 2, 3, 4}) {
+		t.Errorf("unexpected hosts %v", ids)
+	}
+}
+
+func TestMemDBRecoverTransactions(t *testing.T) {
+	db := newTestDB(t)
+	tx, err := db.StartTransaction()
+	if nil != err {
+		t.Fatal(err)
+	}
+	if _, err := tx.Insert("cc_HostBase", testHost{HostID: 5, InnerIP: "10.0.0.5"}); nil != err {
+		t.Fatal(err)
+	}
+	if err := tx.DelByCondition("cc_HostBase", map[string]interface{}{"bk_host_id": 1}); nil != err {
+		t.Fatal(err)
+	}
+
+	// the transaction is alive, it is kept
+	if err := storage.RecoverTransactions(db); nil != err {
+		t.Fatal(err)
+	}
+	if cnt, _ := db.GetCntByCondition(storage.TxLogCollection, nil); 2 != cnt {
+		t.Fatalf("the logs of the alive transaction should be kept, logs: %d", cnt)
+	}
+
+	// the process crashed, the transaction is rolled back by the recovery
+	timeout := storage.TxTimeout
+	storage.TxTimeout = -time.Minute
+	defer func() { storage.TxTimeout = timeout }()
+	if err := storage.RecoverTransactions(db); nil != err {
+		t.Fatal(err)
+	}
+	hosts := make([]testHost, 0)
+	if err := db.GetMutilByCondition("cc_HostBase", nil, nil, &hosts, "bk_host_id", 0, 0); nil != err {
+		t.Fatal(err)
+	}
+	if ids := hostIDs(hosts); !reflect.DeepEqual(ids, []int64{1, 2, 3, 4}) {
+		t.Errorf("unexpected hosts %v", ids)
+	}
+	if cnt, _ := db.GetCntByCondition(storage.TxLogCollection, nil); 0 != cnt {
+		t.Errorf("the log should be discarded, logs: %d", cnt)
+	}
+}
+
+func TestMemDBRecoverTransactionsWrittenLately(t *testing.T) {
+	db := newTestDB(t)
+	// the transaction began before the timeout but is still writing
+	for seq, logTime := range []time.Time{time.Now().Add(-time.Hour), time.Now()} {
+		op := storage.TxOp{Collection: "cc_HostBase", Deleted: []map[string]interface{}{{"bk_host_id": 10 + seq}}}
+		if _, err := db.Insert(storage.TxLogCollection, &storage.TxLog{TxID: "tx", Seq: seq, Op: op, Time: logTime}); nil != err {
+			t.Fatal(err)
+		}
+	}
+	if err := storage.RecoverTransactions(db); nil != err {
+		t.Fatal(err)
+	}
+	if cnt, _ := db.GetCntByCondition(storage.TxLogCollection, nil); 2 != cnt {
+		t.Errorf("the logs of the alive transaction should be kept, logs: %d", cnt)
+	}
+	if cnt, _ := db.GetCntByCondition("cc_HostBase", map[string]interface{}{"bk_host_id": 10}); 0 != cnt {
+		t.Errorf("the alive transaction should not be rolled back")
+	}
+}
+
+func TestMemDBTransactionDuplicated(t *testing.T) {
+	db := newTestDB(t)
+	tx, err := db.StartTransaction()
+	if nil != err {
+		t.Fatal(err)
+	}
+	// the same as the stored host 2, it is not inserted and the stored one is kept on rollback
+	host := testHost{HostID: 2, InnerIP: "10.0.0.1", CloudID: 1, Metadata: map[string]string{"zone": "10"}}
+	if _, err := tx.Insert("cc_HostBase", host); !mgo.IsDup(err) {
+		t.Fatalf("expect duplicated, got %v", err)
+	}
+	if err := tx.Rollback(); nil != err {
+		t.Fatal(err)
+	}
+	hosts := make([]testHost, 0)
+	if err := db.GetMutilByCondition("cc_HostBase", nil, nil, &hosts, "bk_host_id", 0, 0); nil != err {
+		t.Fatal(err)
+	}
+	if ids := hostIDs(hosts); !reflect.DeepEqual(ids, []int64{1, 2, 3, 4}) {
+		t.Errorf("unexpected hosts %v", ids)
+	}
+}
@@ -0,0 +1,530 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package memclient
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/util"
+	"configcenter/src/storage"
+)
+
+var (
+	errWrongType   = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	errNotInteger  = errors.New("ERR value is not an integer or out of range")
+	errNoSuchKey   = errors.New("ERR no such key")
+	errOutOfRange  = errors.New("ERR index out of range")
+	errNotSupport  = errors.New("no support method")
+	blpopPollDelay = 10 * time.Millisecond
+)
+
+// MemRedis the in-memory storage.Cache, and the storage.DI with the command verbs of the redis client,
+// the keys expire lazily when they are accessed.
+// GetSession return nil, the callers which use the redis client directly are not supported
+type MemRedis struct {
+	lock sync.Mutex
+	keys map[string]*memEntry
+	subs map[*memSubscription]struct{}
+}
+
+// memEntry the value of the key, only one of the values is used according to the type of the key
+type memEntry struct {
+	str      string
+	hash     map[string]string
+	list     []string
+	set      map[string]struct{}
+	expireAt time.Time
+}
+
+// NewMemRedis return an empty in-memory redis
+func NewMemRedis() *MemRedis {
+	return &MemRedis{
+		keys: make(map[string]*memEntry),
+		subs: make(map[*memSubscription]struct{}),
+	}
+}
+
+// Open nothing to open
+func (r *MemRedis) Open() error {
+	return nil
+}
+
+// Close close the subscriptions
+func (r *MemRedis) Close() {
+	r.lock.Lock()
+	subs := r.subs
+	r.subs = make(map[*memSubscription]struct{})
+	r.lock.Unlock()
+	for sub := range subs {
+		sub.Close()
+	}
+}
+
+// GetSession no redis client behind
+func (r *MemRedis) GetSession() interface{} {
+	return nil
+}
+
+// Ping always succeed as the redis is in memory
+func (r *MemRedis) Ping() error {
+	return nil
+}
+
+// GetType return the memory redis driver type
+func (r *MemRedis) GetType() string {
+	return storage.DI_MEMORY_REDIS
+}
+
+// get return the entry of the key, nil if not exists or expired
+func (r *MemRedis) get(key string) *memEntry {
+	entry, ok := r.keys[key]
+	if !ok {
+		return nil
+	}
+	if !entry.expireAt.IsZero() && !time.Now().Before(entry.expireAt) {
+		delete(r.keys, key)
+		return nil
+	}
+	return entry
+}
+
+func (r *MemRedis) getString(key string) (*memEntry, error) {
+	entry := r.get(key)
+	if nil != entry && (nil != entry.hash || nil != entry.list || nil != entry.set) {
+		return nil, errWrongType
+	}
+	return entry, nil
+}
+
+func (r *MemRedis) getHash(key string, create bool) (*memEntry, error) {
+	entry := r.get(key)
+	if nil == entry {
+		if !create {
+			return nil, nil
+		}
+		entry = &memEntry{hash: make(map[string]string)}
+		r.keys[key] = entry
+	}
+	if nil == entry.hash {
+		return nil, errWrongType
+	}
+	return entry, nil
+}
+
+func (r *MemRedis) getList(key string, create bool) (*memEntry, error) {
+	entry := r.get(key)
+	if nil == entry {
+		if !create {
+			return nil, nil
+		}
+		entry = &memEntry{list: make([]string, 0)}
+		r.keys[key] = entry
+	}
+	if nil == entry.list {
+		return nil, errWrongType
+	}
+	return entry, nil
+}
+
+func (r *MemRedis) getSet(key string, create bool) (*memEntry, error) {
+	entry := r.get(key)
+	if nil == entry {
+		if !create {
+			return nil, nil
+		}
+		entry = &memEntry{set: make(map[string]struct{})}
+		r.keys[key] = entry
+	}
+	if nil == entry.set {
+		return nil, errWrongType
+	}
+	return entry, nil
+}
+
+// set set the string value, the expiration is removed if exp is zero
+func (r *MemRedis) set(key string, value interface{}, exp time.Duration) {
+	entry := &memEntry{str: formatArg(value)}
+	if exp > 0 {
+		entry.expireAt = time.Now().Add(exp)
+	}
+	r.keys[key] = entry
+}
+
+// incrBy add the delta to the integer value of the key, return the new value
+func (r *MemRedis) incrBy(key string, delta int64) (int64, error) {
+	entry, err := r.getString(key)
+	if nil != err {
+		return 0, err
+	}
+	if nil == entry {
+		entry = &memEntry{str: "0"}
+		r.keys[key] = entry
+	}
+	val, err := strconv.ParseInt(entry.str, 10, 64)
+	if nil != err {
+		return 0, errNotInteger
+	}
+	val += delta
+	entry.str = strconv.FormatInt(val, 10)
+	return val, nil
+}
+
+// Insert the write commands of the redis client
+func (r *MemRedis) Insert(cName string, data interface{}) (int, error) {
+	var err error
+	mapData, _ := data.(common.KvMap)
+	key, ok := mapData["key"].(string)
+	if !ok {
+		return 0, errors.New("params key can not be empty")
+	}
+	switch strings.ToLower(cName) {
+	case "set":
+		exp, _ := mapData["expire"].(time.Duration)
+		err = r.Set(key, mapData["value"], exp)
+	case "setnx":
+		exp, _ := mapData["expire"].(time.Duration)
+		_, err = r.SetNX(key, mapData["value"], exp)
+	case "decr":
+		_, err = r.IncrBy(key, -1)
+	case "decrby":
+		decr, _ := util.GetInt64ByInterface(mapData["decr"])
+		_, err = r.IncrBy(key, -decr)
+	case "incr":
+		var result int64
+		result, err = r.Incr(key)
+		return (int)(result), err
+	case "incrby":
+		incr, _ := util.GetInt64ByInterface(mapData["incr"])
+		_, err = r.IncrBy(key, incr)
+	case "expire":
+		exp, ok := mapData["expire"].(time.Duration)
+		if !ok {
+			return 0, errors.New("params Expire can not be empty")
+		}
+		return boolToInt(r.expireAt(key, time.Now().Add(exp))), nil
+	case "expireat":
+		exp, ok := mapData["expire"].(time.Time)
+		if !ok {
+			return 0, errors.New("params Expire can not be empty")
+		}
+		return boolToInt(r.expireAt(key, exp)), nil
+	case "hset":
+		field, _ := mapData["field"].(string)
+		err = r.HSet(key, field, mapData["value"])
+	case "hmset":
+		fields, _ := mapData["fields"].(map[string]string)
+		err = r.HMSet(key, fields)
+	case "hsetnx":
+		field, _ := mapData["field"].(string)
+		err = r.hsetnx(key, field, mapData["value"])
+	case "rpush":
+		var values []interface{}
+		if values, err = util.GetMapInterfaceByInerface(mapData["values"]); nil == err {
+			err = r.RPush(key, values...)
+		}
+	case "lset":
+		index, _ := mapData["index"].(int64)
+		err = r.lset(key, index, mapData["value"])
+	case "sadd":
+		var values []interface{}
+		if values, err = util.GetMapInterfaceByInerface(mapData["values"]); nil == err {
+			err = r.SAdd(key, values...)
+		}
+	default:
+		err = errNotSupport
+	}
+	if nil != err {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func boolToInt(ok bool) int {
+	if ok {
+		return 1
+	}
+	return 0
+}
+
+// expireAt set the expiration of the key, return false if the key does not exist
+func (r *MemRedis) expireAt(key string, at time.Time) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry := r.get(key)
+	if nil == entry {
+		return false
+	}
+	entry.expireAt = at
+	return true
+}
+
+func (r *MemRedis) hsetnx(key, field string, value interface{}) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.getHash(key, true)
+	if nil != err {
+		return err
+	}
+	if _, ok := entry.hash[field]; !ok {
+		entry.hash[field] = formatArg(value)
+	}
+	return nil
+}
+
+func (r *MemRedis) lset(key string, index int64, value interface{}) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.getList(key, false)
+	if nil != err {
+		return err
+	}
+	if nil == entry {
+		return errNoSuchKey
+	}
+	if index < 0 {
+		index += int64(len(entry.list))
+	}
+	if index < 0 || index >= int64(len(entry.list)) {
+		return errOutOfRange
+	}
+	entry.list[index] = formatArg(value)
+	return nil
+}
+
+// InsertMuti not supported as the redis client
+func (r *MemRedis) InsertMuti(cName string, data ...interface{}) error {
+	return errNotSupport
+}
+
+// UpdateByCondition not supported as the redis client
+func (r *MemRedis) UpdateByCondition(cName string, data, condiction interface{}) error {
+	return errNotSupport
+}
+
+// GetOneByCondition the read commands of the redis client, the missing key is not an error
+func (r *MemRedis) GetOneByCondition(cName string, fields []string, selector, results interface{}) error {
+	var err error
+	ret, _ := results.(*interface{})
+	mapData, _ := selector.(common.KvMap)
+	key, _ := mapData["key"].(string)
+	switch strings.ToLower(cName) {
+	case "get":
+		*ret, err = r.Get(key)
+	case "hget":
+		field, _ := mapData["field"].(string)
+		*ret, err = r.HGet(key, field)
+	case "hgetall":
+		*ret, err = r.HGetAll(key)
+	case "getrange":
+		start, _ := mapData["start"].(int64)
+		end, _ := mapData["end"].(int64)
+		var val string
+		if val, err = r.Get(key); nil == err {
+			from, to := getRange(start, end, len(val))
+			val = val[from:to]
+		}
+		*ret = val
+	case "lrange":
+		start, _ := util.GetInt64ByInterface(mapData["start"])
+		end, _ := util.GetInt64ByInterface(mapData["end"])
+		if 0 == end {
+			return errors.New("params end is requred")
+		}
+		*ret, err = r.LRange(key, start, end)
+	case "blpop":
+		timeout, _ := mapData["expire"].(time.Duration)
+		keys, _ := mapData["key"].([]string)
+		var popped []string
+		if popped, err = r.BLPop(timeout, keys...); nil == err {
+			*results.(*[]string) = popped
+		}
+	case "hexists":
+		field, _ := mapData["field"].(string)
+		*ret, err = r.HExists(key, field)
+	case "hlen":
+		*ret, err = r.HLen(key)
+	case "exists":
+		*ret, err = r.Exists(key)
+	case "ttl":
+		*ret, err = r.TTL(key)
+	case "smembers":
+		var members []string
+		if members, err = r.SMembers(key); nil == err {
+			*results.(*[]string) = members
+		}
+	default:
+		err = errNotSupport
+	}
+	if storage.ErrCacheNil == err {
+		err = nil
+	}
+	return err
+}
+
+func (r *MemRedis) lpop(keys []string) ([]string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, key := range keys {
+		entry, err := r.getList(key, false)
+		if nil != err {
+			return nil, err
+		}
+		if nil == entry || 0 == len(entry.list) {
+			continue
+		}
+		value := entry.list[0]
+		entry.list = entry.list[1:]
+		if 0 == len(entry.list) {
+			delete(r.keys, key)
+		}
+		return []string{key, value}, nil
+	}
+	return nil, nil
+}
+
+// getRange return the slice bounds of the inclusive redis range, the negative index counts from the end
+func getRange(start, end int64, length int) (int, int) {
+	size := int64(length)
+	if start < 0 {
+		start += size
+	}
+	if end < 0 {
+		end += size
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end >= size {
+		end = size - 1
+	}
+	if start > end {
+		return 0, 0
+	}
+	return int(start), int(end + 1)
+}
+
+// GetMutilByCondition not supported as the redis client
+func (r *MemRedis) GetMutilByCondition(cName string, fields []string, selector, results interface{}, sort string, skip, limit int) error {
+	return errNotSupport
+}
+
+// GetMutilByCursor not supported as the redis client
+func (r *MemRedis) GetMutilByCursor(cName string, fields []string, selector, results interface{}, sort, cursor string, limit int) (string, error) {
+	return "", errNotSupport
+}
+
+// GetCntByCondition not supported as the redis client
+func (r *MemRedis) GetCntByCondition(cName string, selector interface{}) (int, error) {
+	return 0, errNotSupport
+}
+
+// GetIncID not supported as the redis client
+func (r *MemRedis) GetIncID(cName string) (int64, error) {
+	return 0, errors.New("not support method")
+}
+
+// DelByCondition the delete commands of the redis client
+func (r *MemRedis) DelByCondition(cName string, delselector interface{}) error {
+	mapData, _ := delselector.(common.KvMap)
+	key, _ := mapData["key"].(string)
+	switch strings.ToLower(cName) {
+	case "del":
+		keys, _ := delselector.([]string)
+		return r.Del(keys...)
+	case "hdel":
+		fields, _ := mapData["fields"].([]string)
+		return r.HDel(key, fields...)
+	case "srem":
+		values, _ := mapData["values"].([]interface{})
+		return r.SRem(key, values...)
+	}
+	return errNotSupport
+}
+
+// HasTable not supported as the redis client
+func (r *MemRedis) HasTable(tableName string) (bool, error) {
+	return false, errNotSupport
+}
+
+// ExecSql not supported as the redis client
+func (r *MemRedis) ExecSql(cmd interface{}) error {
+	return errNotSupport
+}
+
+// CreateTable not supported as the redis client
+func (r *MemRedis) CreateTable(sql string) error {
+	return errNotSupport
+}
+
+// Index not supported as the redis client
+func (r *MemRedis) Index(tableName string, index *storage.Index) error {
+	return errNotSupport
+}
+
+// DropTable not supported as the redis client
+func (r *MemRedis) DropTable(tableName string) error {
+	return errNotSupport
+}
+
+// HasFields not supported as the redis client
+func (r *MemRedis) HasFields(tableName, field string) (bool, error) {
+	return false, errNotSupport
+}
+
+// AddColumn not supported as the redis client
+func (r *MemRedis) AddColumn(tableName string, column *storage.Column) error {
+	return errNotSupport
+}
+
+// ModifyColumn not supported as the redis client
+func (r *MemRedis) ModifyColumn(tableName, oldName, newColumn string) error {
+	return errNotSupport
+}
+
+// DropColumn not supported as the redis client
+func (r *MemRedis) DropColumn(tableName, field string) error {
+	return errNotSupport
+}
+
+// StartTransaction not supported as the redis client
+func (r *MemRedis) StartTransaction() (storage.Tx, error) {
+	return nil, errNotSupport
+}
+
+// formatArg format the value as the redis client sends it
+func formatArg(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 64)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,109 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package memclient
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/storage"
+)
+
+func TestMemRedisString(t *testing.T) {
+	var r storage.DI = NewMemRedis()
+	var ret interface{}
+
+	if _, err := r.Insert("set", common.KvMap{"key": "k", "value": 10}); nil != err {
+		t.Fatal(err)
+	}
+	if _, err := r.Insert("setnx", common.KvMap{"key": "k", "value": "x"}); nil != err {
+		t.Fatal(err)
+	}
+	if id, err := r.Insert("incr", common.KvMap{"key": "k"}); nil != err || 11 != id {
+		t.Errorf("unexpected incr %d, %v", id, err)
+	}
+	if _, err := r.Insert("decrby", common.KvMap{"key": "k", "decr": 5}); nil != err {
+		t.Fatal(err)
+	}
+	if err := r.GetOneByCondition("get", nil, common.KvMap{"key": "k"}, &ret); nil != err || "6" != ret {
+		t.Errorf("unexpected get %v, %v", ret, err)
+	}
+	if err := r.GetOneByCondition("get", nil, common.KvMap{"key": "missing"}, &ret); nil != err || "" != ret {
+		t.Errorf("unexpected get %v, %v", ret, err)
+	}
+
+	if err := r.GetOneByCondition("ttl", nil, common.KvMap{"key": "k"}, &ret); nil != err || -time.Second != ret {
+		t.Errorf("unexpected ttl %v, %v", ret, err)
+	}
+	if ok, _ := r.Insert("expire", common.KvMap{"key": "k", "expire": time.Millisecond}); 1 != ok {
+		t.Error("the key should be expired")
+	}
+	time.Sleep(2 * time.Millisecond)
+	if err := r.GetOneByCondition("exists", nil, common.KvMap{"key": "k"}, &ret); nil != err || false != ret {
+		t.Errorf("unexpected exists %v, %v", ret, err)
+	}
+	if err := r.GetOneByCondition("ttl", nil, common.KvMap{"key": "k"}, &ret); nil != err || -2*time.Second != ret {
+		t.Errorf("unexpected ttl %v, %v", ret, err)
+	}
+
+	if _, err := r.Insert("rpush", common.KvMap{"key": "k", "values": []string{"a"}}); nil != err {
+		t.Fatal(err)
+	}
+	if _, err := r.Insert("incr", common.KvMap{"key": "k"}); errWrongType != err {
+		t.Errorf("expect wrong type, got %v", err)
+	}
+}
+
+func TestMemRedisCollections(t *testing.T) {
+	r := NewMemRedis()
+	var ret interface{}
+
+	r.Insert("hmset", common.KvMap{"key": "h", "fields": map[string]string{"a": "1", "b": "2"}})
+	r.Insert("hset", common.KvMap{"key": "h", "field": "c", "value": true})
+	r.Insert("hsetnx", common.KvMap{"key": "h", "field": "a", "value": "x"})
+	if err := r.GetOneByCondition("hgetall", nil, common.KvMap{"key": "h"}, &ret); nil != err || !reflect.DeepEqual(ret, map[string]string{"a": "1", "b": "2", "c": "1"}) {
+		t.Errorf("unexpected hgetall %v, %v", ret, err)
+	}
+	r.DelByCondition("hdel", common.KvMap{"key": "h", "fields": []string{"a"}})
+	if err := r.GetOneByCondition("hlen", nil, common.KvMap{"key": "h"}, &ret); nil != err || int64(2) != ret {
+		t.Errorf("unexpected hlen %v, %v", ret, err)
+	}
+
+	r.Insert("rpush", common.KvMap{"key": "l", "values": []int64{1, 2, 3}})
+	r.Insert("lset", common.KvMap{"key": "l", "index": int64(-1), "value": 4})
+	if err := r.GetOneByCondition("lrange", nil, common.KvMap{"key": "l", "start": 0, "end": -1}, &ret); nil != err || !reflect.DeepEqual(ret, []string{"1", "2", "4"}) {
+		t.Errorf("unexpected lrange %v, %v", ret, err)
+	}
+	popped := []string{}
+	if err := r.GetOneByCondition("blpop", nil, common.KvMap{"key": []string{"empty", "l"}, "expire": time.Second}, &popped); nil != err || !reflect.DeepEqual(popped, []string{"l", "1"}) {
+		t.Errorf("unexpected blpop %v, %v", popped, err)
+	}
+	popped = nil
+	if err := r.GetOneByCondition("blpop", nil, common.KvMap{"key": []string{"empty"}, "expire": 20 * time.Millisecond}, &popped); nil != err || nil != popped {
+		t.Errorf("unexpected blpop %v, %v", popped, err)
+	}
+
+	r.Insert("sadd", common.KvMap{"key": "s", "values": []string{"b", "a", "b"}})
+	r.DelByCondition("srem", common.KvMap{"key": "s", "values": []interface{}{"b"}})
+	members := []string{}
+	if err := r.GetOneByCondition("smembers", nil, common.KvMap{"key": "s"}, &members); nil != err || !reflect.DeepEqual(members, []string{"a"}) {
+		t.Errorf("unexpected smembers %v, %v", members, err)
+	}
+
+	r.DelByCondition("del", []string{"h", "l", "s"})
+	if err := r.GetOneByCondition("exists", nil, common.KvMap{"key": "h"}, &ret); nil != err || false != ret {
+		t.Errorf("unexpected exists %v, %v", ret, err)
+	}
+}
@@ -0,0 +1,562 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package memclient
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// toDoc convert the data to the bson document, so the types are the same as they are stored in mongodb
+func toDoc(data interface{}) (bson.M, error) {
+	doc := bson.M{}
+	if nil == data {
+		return doc, nil
+	}
+	raw, err := bson.Marshal(data)
+	if nil != err {
+		return nil, err
+	}
+	if err := bson.Unmarshal(raw, &doc); nil != err {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// toValue convert the value as it is stored in mongodb
+func toValue(value interface{}) (interface{}, error) {
+	doc, err := toDoc(bson.M{"v": value})
+	if nil != err {
+		return nil, err
+	}
+	return doc["v"], nil
+}
+
+// lookup return the values of the field path, the arrays on the path are expanded as mongodb does
+func lookup(value interface{}, parts []string) []interface{} {
+	if 0 == len(parts) {
+		return []interface{}{value}
+	}
+	switch val := value.(type) {
+	case bson.M:
+		child, ok := val[parts[0]]
+		if !ok {
+			return nil
+		}
+		return lookup(child, parts[1:])
+	case []interface{}:
+		if idx, err := strconv.Atoi(parts[0]); nil == err {
+			if idx < 0 || idx >= len(val) {
+				return nil
+			}
+			return lookup(val[idx], parts[1:])
+		}
+		values := make([]interface{}, 0)
+		for _, item := range val {
+			if _, ok := item.(bson.M); ok {
+				values = append(values, lookup(item, parts)...)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// getField return the first value of the field path, nil if not exists
+func getField(doc bson.M, path string) (interface{}, bool) {
+	values := lookup(doc, strings.Split(path, "."))
+	if 0 == len(values) {
+		return nil, false
+	}
+	return values[0], true
+}
+
+// setField set the value of the field path, the missing parent documents are created
+func setField(doc bson.M, path string, value interface{}) {
+	parts := strings.Split(path, ".")
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := doc[part].(bson.M)
+		if !ok {
+			child = bson.M{}
+			doc[part] = child
+		}
+		doc = child
+	}
+	doc[parts[len(parts)-1]] = value
+}
+
+// unsetField remove the field path
+func unsetField(doc bson.M, path string) {
+	parts := strings.Split(path, ".")
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := doc[part].(bson.M)
+		if !ok {
+			return
+		}
+		doc = child
+	}
+	delete(doc, parts[len(parts)-1])
+}
+
+// candidates return the values to compare with, the elements of the arrays are compared as well
+func candidates(values []interface{}) []interface{} {
+	result := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		result = append(result, value)
+		if arr, ok := value.([]interface{}); ok {
+			result = append(result, arr...)
+		}
+	}
+	return result
+}
+
+func isOperatorDoc(value interface{}) (bson.M, bool) {
+	doc, ok := value.(bson.M)
+	if !ok || 0 == len(doc) {
+		return nil, false
+	}
+	for key := range doc {
+		if !strings.HasPrefix(key, "$") {
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+// match return whether the document matches the condition
+func match(doc bson.M, cond bson.M) (bool, error) {
+	for key, value := range cond {
+		var ok bool
+		var err error
+		switch key {
+		case "$and", "$or", "$nor":
+			ok, err = matchLogic(doc, key, value)
+		default:
+			if strings.HasPrefix(key, "$") {
+				return false, fmt.Errorf("unknown top level operator: %s", key)
+			}
+			ok, err = matchField(lookup(doc, strings.Split(key, ".")), value)
+		}
+		if nil != err || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchLogic(doc bson.M, operator string, value interface{}) (bool, error) {
+	conds, ok := value.([]interface{})
+	if !ok || 0 == len(conds) {
+		return false, fmt.Errorf("%s must be a nonempty array", operator)
+	}
+	for _, item := range conds {
+		cond, ok := item.(bson.M)
+		if !ok {
+			return false, fmt.Errorf("%s entries need to be full objects", operator)
+		}
+		matched, err := match(doc, cond)
+		if nil != err {
+			return false, err
+		}
+		switch {
+		case "$and" == operator && !matched:
+			return false, nil
+		case "$or" == operator && matched:
+			return true, nil
+		case "$nor" == operator && matched:
+			return false, nil
+		}
+	}
+	return "$or" != operator, nil
+}
+
+// matchField return whether the values of the field match the condition of the field
+func matchField(values []interface{}, cond interface{}) (bool, error) {
+	ops, ok := isOperatorDoc(cond)
+	if !ok {
+		if regex, ok := cond.(bson.RegEx); ok {
+			return matchRegex(values, regex.Pattern, regex.Options)
+		}
+		return matchEq(values, cond), nil
+	}
+	for op, arg := range ops {
+		matched, err := matchOperator(values, op, arg, ops)
+		if nil != err || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchOperator(values []interface{}, op string, arg interface{}, ops bson.M) (bool, error) {
+	switch op {
+	case "$eq":
+		return matchEq(values, arg), nil
+	case "$ne":
+		return !matchEq(values, arg), nil
+	case "$in", "$nin":
+		items, ok := arg.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s needs an array", op)
+		}
+		in := false
+		for _, item := range items {
+			if regex, ok := item.(bson.RegEx); ok {
+				matched, err := matchRegex(values, regex.Pattern, regex.Options)
+				if nil != err {
+					return false, err
+				}
+				in = in || matched
+				continue
+			}
+			in = in || matchEq(values, item)
+		}
+		return in == ("$in" == op), nil
+	case "$gt", "$gte", "$lt", "$lte":
+		for _, value := range candidates(values) {
+			cmp, ok := compareSameType(value, arg)
+			if !ok {
+				continue
+			}
+			if ("$gt" == op && cmp > 0) || ("$gte" == op && cmp >= 0) || ("$lt" == op && cmp < 0) || ("$lte" == op && cmp <= 0) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$exists":
+		return (0 != len(values)) == isTrue(arg), nil
+	case "$regex":
+		options, _ := ops["$options"].(string)
+		switch pattern := arg.(type) {
+		case string:
+			return matchRegex(values, pattern, options)
+		case bson.RegEx:
+			if "" == options {
+				options = pattern.Options
+			}
+			return matchRegex(values, pattern.Pattern, options)
+		}
+		return false, fmt.Errorf("$regex has to be a string")
+	case "$options":
+		if _, ok := ops["$regex"]; !ok {
+			return false, fmt.Errorf("$options needs a $regex")
+		}
+		return true, nil
+	case "$not":
+		matched, err := matchField(values, arg)
+		return !matched, err
+	case "$elemMatch":
+		cond, ok := arg.(bson.M)
+		if !ok {
+			return false, fmt.Errorf("$elemMatch needs an Object")
+		}
+		_, isOps := isOperatorDoc(cond)
+		for _, value := range values {
+			arr, ok := value.([]interface{})
+			if !ok {
+				continue
+			}
+			for _, item := range arr {
+				var matched bool
+				var err error
+				if isOps {
+					matched, err = matchField([]interface{}{item}, cond)
+				} else if doc, ok := item.(bson.M); ok {
+					matched, err = match(doc, cond)
+				}
+				if nil != err {
+					return false, err
+				}
+				if matched {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	case "$all":
+		items, ok := arg.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("$all needs an array")
+		}
+		for _, item := range items {
+			if !matchEq(values, item) {
+				return false, nil
+			}
+		}
+		return 0 != len(items), nil
+	case "$size":
+		size, ok := toFloat(arg)
+		if !ok {
+			return false, fmt.Errorf("$size needs a number")
+		}
+		for _, value := range values {
+			if arr, ok := value.([]interface{}); ok && float64(len(arr)) == size {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("unknown operator: %s", op)
+}
+
+// matchEq the null matches the missing field as mongodb does
+func matchEq(values []interface{}, arg interface{}) bool {
+	if nil == arg && 0 == len(values) {
+		return true
+	}
+	for _, value := range candidates(values) {
+		if equal(value, arg) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchRegex(values []interface{}, pattern, options string) (bool, error) {
+	flags := ""
+	for _, option := range options {
+		if strings.ContainsRune("ims", option) {
+			flags += string(option)
+		}
+	}
+	if "" != flags {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if nil != err {
+		return false, err
+	}
+	for _, value := range candidates(values) {
+		if str, ok := value.(string); ok && re.MatchString(str) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func isTrue(value interface{}) bool {
+	switch val := value.(type) {
+	case bool:
+		return val
+	case nil:
+		return false
+	}
+	if num, ok := toFloat(value); ok {
+		return 0 != num
+	}
+	return true
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch val := value.(type) {
+	case int:
+		return float64(val), true
+	case int32:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	case float64:
+		return val, true
+	}
+	return 0, false
+}
+
+// typeOrder the order of the types as mongodb compares the values of different types
+func typeOrder(value interface{}) int {
+	switch value.(type) {
+	case nil:
+		return 1
+	case int, int32, int64, float64:
+		return 2
+	case string, bson.Symbol:
+		return 3
+	case bson.M:
+		return 4
+	case []interface{}:
+		return 5
+	case []byte, bson.Binary:
+		return 6
+	case bson.ObjectId:
+		return 7
+	case bool:
+		return 8
+	case time.Time:
+		return 9
+	case bson.MongoTimestamp:
+		return 10
+	case bson.RegEx:
+		return 11
+	}
+	return 12
+}
+
+// compare compare the values of any type, the values of different types are ordered by the type
+func compare(a, b interface{}) int {
+	if cmp, ok := compareSameType(a, b); ok {
+		return cmp
+	}
+	orderA, orderB := typeOrder(a), typeOrder(b)
+	if orderA != orderB {
+		if orderA < orderB {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// compareSameType compare the values of the same type, false if the types are not comparable
+func compareSameType(a, b interface{}) (int, bool) {
+	if typeOrder(a) != typeOrder(b) {
+		return 0, false
+	}
+	switch valA := a.(type) {
+	case nil:
+		return 0, true
+	case string:
+		return strings.Compare(valA, b.(string)), true
+	case bson.ObjectId:
+		return strings.Compare(valA.Hex(), b.(bson.ObjectId).Hex()), true
+	case bool:
+		valB := b.(bool)
+		switch {
+		case valA == valB:
+			return 0, true
+		case valB:
+			return -1, true
+		}
+		return 1, true
+	case time.Time:
+		valB := b.(time.Time)
+		switch {
+		case valA.Before(valB):
+			return -1, true
+		case valA.After(valB):
+			return 1, true
+		}
+		return 0, true
+	}
+	numA, okA := toFloat(a)
+	numB, okB := toFloat(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	switch {
+	case numA < numB:
+		return -1, true
+	case numA > numB:
+		return 1, true
+	}
+	return 0, true
+}
+
+// equal compare the values as mongodb does, the numbers of different types are equal if the values are
+func equal(a, b interface{}) bool {
+	switch valA := a.(type) {
+	case bson.M:
+		valB, ok := b.(bson.M)
+		if !ok || len(valA) != len(valB) {
+			return false
+		}
+		for key, item := range valA {
+			other, ok := valB[key]
+			if !ok || !equal(item, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		valB, ok := b.([]interface{})
+		if !ok || len(valA) != len(valB) {
+			return false
+		}
+		for i := range valA {
+			if !equal(valA[i], valB[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	if cmp, ok := compareSameType(a, b); ok {
+		return 0 == cmp
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// sortDocs sort the documents by the sort like "bk_host_id,-op_time" stably
+func sortDocs(docs []bson.M, sorts ...string) {
+	type sortField struct {
+		field string
+		desc  bool
+	}
+	fields := make([]sortField, 0)
+	for _, item := range sorts {
+		for _, field := range strings.Split(item, ",") {
+			field = strings.TrimSpace(field)
+			desc := strings.HasPrefix(field, "-")
+			field = strings.TrimLeft(field, "+-")
+			if "" != field {
+				fields = append(fields, sortField{field: field, desc: desc})
+			}
+		}
+	}
+	if 0 == len(fields) {
+		return
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		for _, field := range fields {
+			valI, _ := getField(docs[i], field.field)
+			valJ, _ := getField(docs[j], field.field)
+			cmp := compare(valI, valJ)
+			if 0 == cmp {
+				continue
+			}
+			if field.desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+}
+
+// project return the copy of the document with the fields, all the fields if empty,
+// the _id is kept only if keepID
+func project(doc bson.M, fields []string, keepID bool) bson.M {
+	result := bson.M{}
+	if 0 == len(fields) {
+		for key, value := range doc {
+			result[key] = value
+		}
+	} else {
+		for _, field := range fields {
+			if value, ok := getField(doc, field); ok {
+				setField(result, field, value)
+			}
+		}
+		if id, ok := doc["_id"]; ok {
+			result["_id"] = id
+		}
+	}
+	if !keepID {
+		delete(result, "_id")
+	}
+	return result
+}
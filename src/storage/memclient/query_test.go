@@ -0,0 +1,117 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package memclient
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestMatch(t *testing.T) {
+	doc, err := toDoc(map[string]interface{}{
+		"bk_host_id":      3,
+		"bk_host_innerip": "192.168.1.3",
+		"bk_os_type":      nil,
+		"tags":            []string{"db", "web"},
+		"attr":            map[string]interface{}{"cpu": 8, "disks": []map[string]interface{}{{"size": 100}, {"size": 500}}},
+	})
+	if nil != err {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		cond  bson.M
+		match bool
+	}{
+		{bson.M{}, true},
+		{bson.M{"bk_host_id": 3}, true},
+		{bson.M{"bk_host_id": int64(3)}, true},
+		{bson.M{"bk_host_id": 3.0}, true},
+		{bson.M{"bk_host_id": "3"}, false},
+		{bson.M{"bk_host_id": bson.M{"$in": []int{1, 3}}}, true},
+		{bson.M{"bk_host_id": bson.M{"$nin": []int{1, 3}}}, false},
+		{bson.M{"bk_host_id": bson.M{"$ne": 3}}, false},
+		{bson.M{"bk_host_id": bson.M{"$gt": 1, "$lte": 3}}, true},
+		{bson.M{"bk_host_id": bson.M{"$lt": 3}}, false},
+		{bson.M{"bk_host_id": bson.M{"$gt": "1"}}, false},
+		{bson.M{"bk_host_innerip": bson.M{"$regex": "^192\\.168"}}, true},
+		{bson.M{"bk_host_innerip": bson.M{"$regex": "^ABC", "$options": "i"}}, false},
+		{bson.M{"bk_host_innerip": bson.RegEx{Pattern: "1.3$"}}, true},
+		{bson.M{"bk_host_innerip": bson.M{"$in": []interface{}{bson.RegEx{Pattern: "^10\\."}, "192.168.1.3"}}}, true},
+		{bson.M{"bk_os_type": nil}, true},
+		{bson.M{"bk_cloud_id": nil}, true},
+		{bson.M{"bk_cloud_id": bson.M{"$exists": true}}, false},
+		{bson.M{"bk_os_type": bson.M{"$exists": true}}, true},
+		{bson.M{"tags": "web"}, true},
+		{bson.M{"tags": bson.M{"$all": []string{"web", "db"}}}, true},
+		{bson.M{"tags": bson.M{"$size": 2}}, true},
+		{bson.M{"tags": []string{"db", "web"}}, true},
+		{bson.M{"attr.cpu": bson.M{"$gte": 8}}, true},
+		{bson.M{"attr.disks.size": 500}, true},
+		{bson.M{"attr.disks.1.size": 100}, false},
+		{bson.M{"attr.disks": bson.M{"$elemMatch": bson.M{"size": bson.M{"$gt": 200}}}}, true},
+		{bson.M{"bk_host_id": bson.M{"$not": bson.M{"$gt": 2}}}, false},
+		{bson.M{"$or": []bson.M{{"bk_host_id": 1}, {"tags": "db"}}}, true},
+		{bson.M{"$and": []bson.M{{"bk_host_id": 3}, {"tags": "app"}}}, false},
+		{bson.M{"$nor": []bson.M{{"bk_host_id": 1}}}, true},
+	}
+	for _, c := range cases {
+		cond, err := toDoc(c.cond)
+		if nil != err {
+			t.Fatal(err)
+		}
+		ok, err := match(doc, cond)
+		if nil != err {
+			t.Errorf("condition %v: %v", c.cond, err)
+			continue
+		}
+		if ok != c.match {
+			t.Errorf("condition %v: expect %v, got %v", c.cond, c.match, ok)
+		}
+	}
+
+	if _, err := match(doc, bson.M{"bk_host_id": bson.M{"$where": "1"}}); nil == err {
+		t.Error("the unknown operator should fail")
+	}
+}
+
+func TestSortDocs(t *testing.T) {
+	docs := []bson.M{
+		{"name": "b", "id": 2},
+		{"name": "a", "id": 3},
+		{"id": 4},
+		{"name": "b", "id": 1},
+	}
+	sortDocs(docs, "name,-id")
+	ids := []interface{}{}
+	for _, doc := range docs {
+		ids = append(ids, doc["id"])
+	}
+	if !reflect.DeepEqual(ids, []interface{}{4, 3, 2, 1}) {
+		t.Errorf("unexpected order %v", ids)
+	}
+}
+
+func TestProject(t *testing.T) {
+	doc := bson.M{"_id": 1, "a": bson.M{"b": 1, "c": 2}, "d": 3}
+	if result := project(doc, []string{"a.b", "x"}, false); !reflect.DeepEqual(result, bson.M{"a": bson.M{"b": 1}}) {
+		t.Errorf("unexpected projection %v", result)
+	}
+	if result := project(doc, nil, false); !reflect.DeepEqual(result, bson.M{"a": bson.M{"b": 1, "c": 2}, "d": 3}) {
+		t.Errorf("unexpected projection %v", result)
+	}
+	if _, ok := doc["_id"]; !ok {
+		t.Error("the document should not be changed")
+	}
+}
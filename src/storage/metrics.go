@@ -0,0 +1,95 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package storage
+
+import (
+	"time"
+
+	"configcenter/src/common/metrics"
+)
+
+var operationDuration = metrics.NewHistogramVec("cc_storage_operation_duration_seconds",
+	"the latency of the storage operations, by operation, collection and result", nil, "operation", "collection", "result")
+
+// metricDI record the latency of the operations of the wrapped DI
+type metricDI struct {
+	DI
+}
+
+// NewMetricDI wrap the DI to record the operation latency per collection
+func NewMetricDI(db DI) DI {
+	return &metricDI{DI: db}
+}
+
+// Secondary return the secondary of the wrapped DI, wrapped to record its latency as well
+func (m *metricDI) Secondary() DI {
+	secondary := Secondary(m.DI)
+	if secondary == m.DI {
+		return m
+	}
+	return &metricDI{DI: secondary}
+}
+
+// observe record the operation, err is read when the deferred call runs
+func observe(operation, cName string, start time.Time, err *error) {
+	result := "ok"
+	if nil != *err {
+		result = "error"
+	}
+	operationDuration.Since(start, operation, cName, result)
+}
+
+func (m *metricDI) GetIncID(cName string) (id int64, err error) {
+	defer observe("get_inc_id", cName, time.Now(), &err)
+	return m.DI.GetIncID(cName)
+}
+
+func (m *metricDI) Insert(cName string, data interface{}) (id int, err error) {
+	defer observe("insert", cName, time.Now(), &err)
+	return m.DI.Insert(cName, data)
+}
+
+func (m *metricDI) InsertMuti(cName string, data ...interface{}) (err error) {
+	defer observe("insert_muti", cName, time.Now(), &err)
+	return m.DI.InsertMuti(cName, data...)
+}
+
+func (m *metricDI) UpdateByCondition(cName string, data, condiction interface{}) (err error) {
+	defer observe("update", cName, time.Now(), &err)
+	return m.DI.UpdateByCondition(cName, data, condiction)
+}
+
+func (m *metricDI) GetOneByCondition(cName string, fields []string, condiction interface{}, result interface{}) (err error) {
+	defer observe("get_one", cName, time.Now(), &err)
+	return m.DI.GetOneByCondition(cName, fields, condiction, result)
+}
+
+func (m *metricDI) GetMutilByCondition(cName string, fields []string, condiction interface{}, result interface{}, sort string, start, limit int) (err error) {
+	defer observe("get_mutil", cName, time.Now(), &err)
+	return m.DI.GetMutilByCondition(cName, fields, condiction, result, sort, start, limit)
+}
+
+func (m *metricDI) GetMutilByCursor(cName string, fields []string, condiction interface{}, result interface{}, sort, cursor string, limit int) (next string, err error) {
+	defer observe("get_mutil_by_cursor", cName, time.Now(), &err)
+	return m.DI.GetMutilByCursor(cName, fields, condiction, result, sort, cursor, limit)
+}
+
+func (m *metricDI) GetCntByCondition(cName string, condiction interface{}) (cnt int, err error) {
+	defer observe("count", cName, time.Now(), &err)
+	return m.DI.GetCntByCondition(cName, condiction)
+}
+
+func (m *metricDI) DelByCondition(cName string, condiction interface{}) (err error) {
+	defer observe("delete", cName, time.Now(), &err)
+	return m.DI.DelByCondition(cName, condiction)
+}
@@ -0,0 +1,244 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+
+package mgoclient
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/storage"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// cursorDoc the content of the cursor, the sort key and the _id of the last document of the page,
+// the sort is kept to reject the cursor used with another sort
+type cursorDoc struct {
+	Sort  string      `bson:"s"`
+	Value interface{} `bson:"v"`
+	ID    interface{} `bson:"i"`
+}
+
+// encodeCursor encode the cursor as an opaque string, the bson keeps the type of the sort key
+func encodeCursor(cur *cursorDoc) (string, error) {
+	data, err := bson.Marshal(cur)
+	if nil != err {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(cursor string) (*cursorDoc, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if nil != err {
+		return nil, storage.ErrInvalidCursor
+	}
+	cur := &cursorDoc{}
+	if err := bson.Unmarshal(data, cur); nil != err || nil == cur.ID {
+		return nil, storage.ErrInvalidCursor
+	}
+	return cur, nil
+}
+
+// parseCursorSort parse the sort like -op_time, only one top level sort field is supported by the cursor,
+// the dotted field is not as its value is not kept in the row by the key, the empty field means sorted by _id
+func parseCursorSort(sort string) (field string, desc bool, ok bool) {
+	field = strings.TrimSpace(sort)
+	if strings.Contains(field, ",") || strings.Contains(field, ".") {
+		return "", false, false
+	}
+	if strings.HasPrefix(field, "-") {
+		desc = true
+	}
+	field = strings.TrimLeft(field, "+-")
+	if "_id" == field {
+		field = ""
+	}
+	return field, desc, true
+}
+
+// getCursorSort return the sort fields, the _id breaks the ties of the sort field
+func getCursorSort(field string, desc bool) []string {
+	sorts := make([]string, 0, 2)
+	if "" != field {
+		sorts = append(sorts, field)
+	}
+	sorts = append(sorts, "_id")
+	if desc {
+		for i := range sorts {
+			sorts[i] = "-" + sorts[i]
+		}
+	}
+	return sorts
+}
+
+// getCursorCondition return the condition matches the documents after the cursor,
+// the null sort key is the smallest one as mongodb sorts
+func getCursorCondition(field string, desc bool, cur *cursorDoc) bson.M {
+	op := "$gt"
+	if desc {
+		op = "$lt"
+	}
+	if "" == field {
+		return bson.M{"_id": bson.M{op: cur.ID}}
+	}
+	tie := bson.M{field: cur.Value, "_id": bson.M{op: cur.ID}}
+	if nil == cur.Value {
+		if desc {
+			return tie
+		}
+		return bson.M{"$or": []bson.M{{field: bson.M{"$ne": nil}}, tie}}
+	}
+	after := bson.M{field: bson.M{op: cur.Value}}
+	if desc {
+		// the null ones are after all the others
+		return bson.M{"$or": []bson.M{after, {field: nil}, tie}}
+	}
+	return bson.M{"$or": []bson.M{after, tie}}
+}
+
+// CursorQuery the query of the page after the cursor, sorted by the sort field and _id
+type CursorQuery struct {
+	// Condition the condition matches the documents after the cursor
+	Condition interface{}
+	// Sort the sort fields of the query
+	Sort []string
+
+	sort  string
+	field string
+	desc  bool
+}
+
+// NewCursorQuery return the query of the page after the cursor, the first page if the cursor is empty,
+// nil is returned if the sort is not supported by the cursor and the cursor is empty
+func NewCursorQuery(condiction interface{}, sort, cursor string) (*CursorQuery, error) {
+	field, desc, ok := parseCursorSort(sort)
+	if !ok {
+		if "" != cursor {
+			blog.Errorf("the sort %s is not supported by the cursor", sort)
+			return nil, storage.ErrInvalidCursor
+		}
+		return nil, nil
+	}
+	if nil == condiction {
+		condiction = bson.M{}
+	}
+	if "" != cursor {
+		cur, err := decodeCursor(cursor)
+		if nil != err {
+			return nil, err
+		}
+		if cur.Sort != sort {
+			blog.Errorf("the cursor is created with the sort %s, not %s", cur.Sort, sort)
+			return nil, storage.ErrInvalidCursor
+		}
+		condiction = bson.M{"$and": []interface{}{condiction, getCursorCondition(field, desc, cur)}}
+	}
+	return &CursorQuery{Condition: condiction, Sort: getCursorSort(field, desc), sort: sort, field: field, desc: desc}, nil
+}
+
+// Select return the projection of the fields, the sort field is selected to create the next cursor,
+// nil means all the fields
+func (q *CursorQuery) Select(fields []string) map[string]interface{} {
+	if 0 == len(fields) {
+		return nil
+	}
+	fieldmap := make(map[string]interface{})
+	for _, key := range fields {
+		fieldmap[key] = 1
+	}
+	if "" != q.field {
+		fieldmap[q.field] = 1
+	}
+	return fieldmap
+}
+
+// Page trim the rows fetched with the limit+1 to the page, and return the cursor of the next page,
+// the _id and the sort field which is not selected are removed from the rows
+func (q *CursorQuery) Page(rows []bson.M, fields []string, limit int) ([]bson.M, string, error) {
+	next := ""
+	if 0 < limit && len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[limit-1]
+		var err error
+		next, err = encodeCursor(&cursorDoc{Sort: q.sort, Value: last[q.field], ID: last["_id"]})
+		if nil != err {
+			return nil, "", err
+		}
+	}
+	selected := 0 == len(fields)
+	for _, key := range fields {
+		selected = selected || key == q.field
+	}
+	for _, row := range rows {
+		delete(row, "_id")
+		if !selected {
+			delete(row, q.field)
+		}
+	}
+	return rows, next, nil
+}
+
+// GetMutilByCursor get the page after the cursor sorted by the sort field and _id, the first page if the cursor is empty,
+// return the cursor of the next page, empty if no more.
+// the sort with more than one field is not supported by the cursor, the first page is returned without the next cursor
+func (m *MgoCli) GetMutilByCursor(cName string, fields []string, condiction interface{}, result interface{}, sort, cursor string, limit int) (string, error) {
+	q, err := NewCursorQuery(condiction, sort, cursor)
+	if nil != err {
+		return "", err
+	}
+	if nil == q {
+		return "", m.GetMutilByCondition(cName, fields, condiction, result, sort, 0, limit)
+	}
+
+	session, release := m.searchSession()
+	defer release()
+	if len(fields) == 1 && fields[0] == "" {
+		fields = nil
+	}
+	c := session.DB(m.dbName).C(cName)
+	query := c.Find(q.Condition)
+	if fieldmap := q.Select(fields); nil != fieldmap {
+		query = query.Select(fieldmap)
+	}
+	query = query.Sort(q.Sort...)
+	if 0 < limit {
+		query = query.Limit(limit + 1)
+	}
+	rows := make([]bson.M, 0)
+	if err := query.All(&rows); nil != err {
+		return "", err
+	}
+	rows, next, err := q.Page(rows, fields, limit)
+	if nil != err {
+		return "", err
+	}
+	return next, UnmarshalRows(rows, result)
+}
+
+// UnmarshalRows convert the rows to the result as the query does
+func UnmarshalRows(rows []bson.M, result interface{}) error {
+	data, err := bson.Marshal(bson.M{"rows": rows})
+	if nil != err {
+		return err
+	}
+	raw := struct {
+		Rows bson.Raw `bson:"rows"`
+	}{}
+	if err := bson.Unmarshal(data, &raw); nil != err {
+		return err
+	}
+	return raw.Rows.Unmarshal(result)
+}
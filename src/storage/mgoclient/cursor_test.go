@@ -0,0 +1,114 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+
+package mgoclient
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"configcenter/src/storage"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestCursor(t *testing.T) {
+	opTime := time.Date(2018, 3, 8, 3, 30, 28, 0, time.UTC)
+	id := bson.NewObjectId()
+	cursor, err := encodeCursor(&cursorDoc{Sort: "-op_time", Value: opTime, ID: id})
+	if nil != err {
+		t.Fatal(err)
+	}
+	cur, err := decodeCursor(cursor)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if cur.Sort != "-op_time" || !opTime.Equal(cur.Value.(time.Time)) || cur.ID != id {
+		t.Errorf("unexpected cursor %v", cur)
+	}
+	for _, invalid := range []string{"x!", "AAAA", ""} {
+		if _, err := decodeCursor(invalid); nil == err {
+			t.Errorf("cursor %s should be invalid", invalid)
+		}
+	}
+
+	cond := getCursorCondition("op_time", true, cur)
+	expect := bson.M{"$or": []bson.M{
+		{"op_time": bson.M{"$lt": cur.Value}},
+		{"op_time": nil},
+		{"op_time": cur.Value, "_id": bson.M{"$lt": id}},
+	}}
+	if !reflect.DeepEqual(cond, expect) {
+		t.Errorf("unexpected condition %v", cond)
+	}
+	if cond := getCursorCondition("", false, cur); !reflect.DeepEqual(cond, bson.M{"_id": bson.M{"$gt": id}}) {
+		t.Errorf("unexpected condition %v", cond)
+	}
+	cur.Value = nil
+	expect = bson.M{"$or": []bson.M{{"op_time": bson.M{"$ne": nil}}, {"op_time": nil, "_id": bson.M{"$gt": id}}}}
+	if cond := getCursorCondition("op_time", false, cur); !reflect.DeepEqual(cond, expect) {
+		t.Errorf("unexpected condition %v", cond)
+	}
+}
+
+func TestParseCursorSort(t *testing.T) {
+	cases := []struct {
+		sort  string
+		field string
+		desc  bool
+		ok    bool
+		sorts []string
+	}{
+		{"", "", false, true, []string{"_id"}},
+		{"-op_time", "op_time", true, true, []string{"-op_time", "-_id"}},
+		{"bk_host_id", "bk_host_id", false, true, []string{"bk_host_id", "_id"}},
+		{"-_id", "", true, true, []string{"-_id"}},
+		{"bk_host_id,-op_time", "", false, false, nil},
+		{"-content.cur_data.bk_host_id", "", false, false, nil},
+	}
+	for _, c := range cases {
+		field, desc, ok := parseCursorSort(c.sort)
+		if field != c.field || desc != c.desc || ok != c.ok {
+			t.Errorf("sort %s: unexpected %s %v %v", c.sort, field, desc, ok)
+		}
+		if ok && !reflect.DeepEqual(getCursorSort(field, desc), c.sorts) {
+			t.Errorf("sort %s: unexpected sorts %v", c.sort, getCursorSort(field, desc))
+		}
+	}
+	if q, err := NewCursorQuery(nil, "content.bk_host_id", ""); nil != err || nil != q {
+		t.Errorf("the dotted sort should fall back to the offset page, %v %v", q, err)
+	}
+	if _, err := NewCursorQuery(nil, "content.bk_host_id", "AAAA"); storage.ErrInvalidCursor != err {
+		t.Errorf("the cursor with the dotted sort should be invalid, %v", err)
+	}
+}
+
+func TestUnmarshalRows(t *testing.T) {
+	type host struct {
+		HostID int    `bson:"bk_host_id"`
+		Name   string `bson:"bk_host_name"`
+	}
+	rows := []bson.M{{"bk_host_id": 1, "bk_host_name": "a"}, {"bk_host_id": 2, "bk_host_name": "b"}}
+	hosts := make([]host, 0)
+	if err := UnmarshalRows(rows, &hosts); nil != err {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(hosts, []host{{1, "a"}, {2, "b"}}) {
+		t.Errorf("unexpected hosts %v", hosts)
+	}
+	result := make([]interface{}, 0)
+	if err := UnmarshalRows(rows, &result); nil != err || 2 != len(result) {
+		t.Errorf("unexpected result %v, error %v", result, err)
+	}
+}
@@ -21,7 +21,6 @@ import (
 	// "log"
 	// "os"
 	"strconv"
-	"time"
 
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
@@ -34,10 +33,17 @@ type MgoCli struct {
 	pwd       string
 	dbName    string
 	mechanism string
+	opts      Options
 	session   *mgo.Session
+	// searchMode the read mode of the searches opted in by Secondary
+	searchMode mgo.Mode
+	// secondary the searches of the client read with the search mode
+	secondary bool
 }
 
-func NewMgoCli(host, port, usr, pwd, mechanism, database string) (*MgoCli, error) {
+// NewMgoCli create the mongodb client, the host may be the comma separated seeds of the replica set,
+// opts may be nil to keep the defaults
+func NewMgoCli(host, port, usr, pwd, mechanism, database string, opts *Options) (*MgoCli, error) {
 	mgocli := new(MgoCli)
 	mgocli.host = host
 	mgocli.port = port
@@ -45,6 +51,14 @@ func NewMgoCli(host, port, usr, pwd, mechanism, database string) (*MgoCli, error
 	mgocli.pwd = pwd
 	mgocli.dbName = database
 	mgocli.mechanism = mechanism
+	if nil != opts {
+		mgocli.opts = *opts
+	}
+	mode, err := parseReadPreference(mgocli.opts.SearchReadPreference)
+	if nil != err {
+		return nil, err
+	}
+	mgocli.searchMode = mode
 	return mgocli, nil
 }
 
@@ -53,16 +67,9 @@ func (m *MgoCli) Open() error {
 	// mgo.SetDebug(true)
 	// mgo.SetLogger(log.New(os.Stderr, "", log.LstdFlags))
 
-	dialInfo := &mgo.DialInfo{
-		Addrs:     []string{m.host},
-		Direct:    false,
-		Timeout:   time.Second * 5,
-		Database:  m.dbName,
-		Source:    "",
-		Username:  m.usr,
-		Password:  m.pwd,
-		PoolLimit: 4096,
-		Mechanism: m.mechanism,
+	dialInfo, err := m.getDialInfo()
+	if nil != err {
+		return err
 	}
 	session, err := mgo.DialWithInfo(dialInfo)
 	m.session = session
@@ -72,14 +79,58 @@ func (m *MgoCli) Open() error {
 	return nil
 }
 
+// Secondary return the client whose searches read with the search read preference,
+// it shares the session with m and must only serve the searches tolerating stale data
+func (m *MgoCli) Secondary() storage.DI {
+	if m.secondary || mgo.Primary == m.searchMode {
+		return m
+	}
+	secondary := *m
+	secondary.secondary = true
+	return &secondary
+}
+
+// searchSession return the session of the search paths and the function to release it,
+// the session reads from the primary unless the client is returned by Secondary
+func (m *MgoCli) searchSession() (*mgo.Session, func()) {
+	if !m.secondary || m.searchMode == m.session.Mode() {
+		m.session.Refresh()
+		return m.session, func() {}
+	}
+	session := m.session.Copy()
+	session.SetMode(m.searchMode, true)
+	return session, session.Close
+}
+
 // GetSession returns mongo session
 func (m *MgoCli) GetSession() interface{} {
 	return m.session
 }
 
-// Close close mongo session
+// Ping check the connection to mongodb
+func (m *MgoCli) Ping() error {
+	if nil == m.session {
+		return errors.New("mongodb is not connected")
+	}
+	session := m.session.Copy()
+	defer session.Close()
+	return session.Ping()
+}
+
+// StartTransaction start a compensating transaction, the mongodb in use has no multi-document transaction
+// the undo snapshots are always taken from the primary
+func (m *MgoCli) StartTransaction() (storage.Tx, error) {
+	if m.secondary {
+		primary := *m
+		primary.secondary = false
+		return storage.NewCompensatingTx(&primary), nil
+	}
+	return storage.NewCompensatingTx(m), nil
+}
+
+// Close close mongo session, the client returned by Secondary leaves the shared session open
 func (m *MgoCli) Close() {
-	if m.session != nil {
+	if m.session != nil && !m.secondary {
 		m.session.Close()
 	}
 }
@@ -146,11 +197,12 @@ func (m *MgoCli) GetOneByCondition(cName string, fields []string, condiction int
 
 // GetMutilByCondition get multiple document by condiction
 func (m *MgoCli) GetMutilByCondition(cName string, fields []string, condiction interface{}, result interface{}, sort string, start, limit int) error {
-	m.session.Refresh()
+	session, release := m.searchSession()
+	defer release()
 	if len(fields) == 1 && fields[0] == "" {
 		fields = nil
 	}
-	c := m.session.DB(m.dbName).C(cName)
+	c := session.DB(m.dbName).C(cName)
 	fieldmap := make(map[string]interface{})
 	if 0 != len(fields) {
 		for _, key := range fields {
@@ -182,8 +234,9 @@ func (m *MgoCli) GetMutilByCondition(cName string, fields []string, condiction i
 
 // GetCntByCondition returns count number filter by condiction
 func (m *MgoCli) GetCntByCondition(cName string, condiction interface{}) (cnt int, err error) {
-	m.session.Refresh()
-	c := m.session.DB(m.dbName).C(cName)
+	session, release := m.searchSession()
+	defer release()
+	c := session.DB(m.dbName).C(cName)
 	count := 0
 	count, err = c.Find(condiction).Count()
 	if err != nil {
@@ -277,10 +330,11 @@ func (m *MgoCli) Index(tableName string, index *storage.Index) error {
 		backgroud = true
 	}
 	return m.session.DB(m.dbName).C(tableName).EnsureIndex(mgo.Index{
-		Name:       index.Name,
-		Key:        index.Columns,
-		Unique:     unique,
-		Background: backgroud,
+		Name:        index.Name,
+		Key:         index.Columns,
+		Unique:      unique,
+		Background:  backgroud,
+		ExpireAfter: index.ExpireAfter,
 	})
 }
 
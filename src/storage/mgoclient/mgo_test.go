@@ -23,7 +23,7 @@ import (
 )
 
 func TestUint(t *testing.T) {
-	db, err := NewMgoCli("127.0.0.1", "27017", "user", "pwd", "", "cmdb")
+	db, err := NewMgoCli("127.0.0.1", "27017", "user", "pwd", "", "cmdb", nil)
 	require.NoError(t, err)
 	err = db.Open()
 	require.NoError(t, err)
@@ -45,7 +45,7 @@ func TestUint(t *testing.T) {
 }
 
 func TestStruct(t *testing.T) {
-	db, err := NewMgoCli("127.0.0.1", "27017", "user", "pwd", "", "cmdb")
+	db, err := NewMgoCli("127.0.0.1", "27017", "user", "pwd", "", "cmdb", nil)
 	require.NoError(t, err)
 	err = db.Open()
 	require.NoError(t, err)
@@ -200,7 +200,7 @@ func testTable(t *testing.T, db *MgoCli) {
 
 func TestMongoTime(t *testing.T) {
 	return
-	db, err := NewMgoCli("127.0.0.1", "27017", "user", "pwd", "", "cmdb")
+	db, err := NewMgoCli("127.0.0.1", "27017", "user", "pwd", "", "cmdb", nil)
 	err = db.Open()
 	if nil != err {
 		t.Errorf("%s", err)
@@ -214,7 +214,7 @@ func TestMongoTime(t *testing.T) {
 
 func TestSearchTime(t *testing.T) {
 	return
-	db, err := NewMgoCli("127.0.0.1", "27017", "user", "pwd", "", "cmdb")
+	db, err := NewMgoCli("127.0.0.1", "27017", "user", "pwd", "", "cmdb", nil)
 	err = db.Open()
 	if nil != err {
 		t.Errorf("%s", err)
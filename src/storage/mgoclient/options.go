@@ -0,0 +1,215 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package mgoclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/mgo.v2"
+)
+
+const (
+	defaultTimeout   = time.Second * 5
+	defaultPoolLimit = 4096
+
+	// mechanismX509 authenticate with the client certificate
+	mechanismX509 = "MONGODB-X509"
+)
+
+// Options the optional settings of the mongodb connection, the zero value keeps the defaults
+type Options struct {
+	// ReplicaSet the replica set name, the seeds are used as they are if empty
+	ReplicaSet string
+	// SearchReadPreference the read mode of the searches opted in by Secondary, one of primary,
+	// primaryPreferred, secondary, secondaryPreferred and nearest, defaults to primary,
+	// the other reads and the writes always use the primary
+	SearchReadPreference string
+	// AuthSource the database the user is defined in, defaults to the database, $external for x509
+	AuthSource string
+	Timeout    time.Duration
+	PoolLimit  int
+	// TLS connect with tls if not nil
+	TLS *TLSOptions
+}
+
+// TLSOptions the tls settings of the mongodb connection
+type TLSOptions struct {
+	// CAFile the ca to verify the server, the system roots are used if empty
+	CAFile string
+	// CertFile and KeyFile the client certificate, required by the x509 authentication
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+}
+
+// ParseOptions parse the options from the config of the data type, the keys are like:
+//
+// mongodb.replicaSet=rs0
+// mongodb.searchReadPreference=secondaryPreferred
+// mongodb.authSource=admin
+// mongodb.timeout=5 (seconds)
+// mongodb.poolLimit=4096
+// mongodb.tls=true
+// mongodb.tlsCAFile=/data/cmdb/cert/ca.pem
+// mongodb.tlsCertFile=/data/cmdb/cert/client.pem
+// mongodb.tlsKeyFile=/data/cmdb/cert/client.key
+// mongodb.tlsInsecure=false
+func ParseOptions(config map[string]string, dType string) (*Options, error) {
+	opts := &Options{
+		ReplicaSet:           config[dType+".replicaSet"],
+		SearchReadPreference: config[dType+".searchReadPreference"],
+		AuthSource:           config[dType+".authSource"],
+	}
+	if val := config[dType+".timeout"]; "" != val {
+		timeout, err := strconv.Atoi(val)
+		if nil != err || timeout <= 0 {
+			return nil, fmt.Errorf("invalid %s.timeout: %s", dType, val)
+		}
+		opts.Timeout = time.Duration(timeout) * time.Second
+	}
+	if val := config[dType+".poolLimit"]; "" != val {
+		limit, err := strconv.Atoi(val)
+		if nil != err || limit <= 0 {
+			return nil, fmt.Errorf("invalid %s.poolLimit: %s", dType, val)
+		}
+		opts.PoolLimit = limit
+	}
+	if enable, _ := strconv.ParseBool(config[dType+".tls"]); enable {
+		insecure, _ := strconv.ParseBool(config[dType+".tlsInsecure"])
+		opts.TLS = &TLSOptions{
+			CAFile:             config[dType+".tlsCAFile"],
+			CertFile:           config[dType+".tlsCertFile"],
+			KeyFile:            config[dType+".tlsKeyFile"],
+			InsecureSkipVerify: insecure,
+		}
+	}
+	if _, err := parseReadPreference(opts.SearchReadPreference); nil != err {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// parseReadPreference return the mgo mode of the read preference, primary if empty
+func parseReadPreference(pref string) (mgo.Mode, error) {
+	switch strings.ToLower(pref) {
+	case "", "primary":
+		return mgo.Primary, nil
+	case "primarypreferred":
+		return mgo.PrimaryPreferred, nil
+	case "secondary":
+		return mgo.Secondary, nil
+	case "secondarypreferred":
+		return mgo.SecondaryPreferred, nil
+	case "nearest":
+		return mgo.Nearest, nil
+	}
+	return mgo.Primary, fmt.Errorf("unknown read preference %s", pref)
+}
+
+// getSeeds split the comma separated hosts, the host without the port uses the default port of mgo
+func getSeeds(host string) []string {
+	seeds := make([]string, 0)
+	for _, seed := range strings.Split(host, ",") {
+		if seed = strings.TrimSpace(seed); "" != seed {
+			seeds = append(seeds, seed)
+		}
+	}
+	return seeds
+}
+
+// getTLSConfig build the tls config from the options
+func getTLSConfig(opts *TLSOptions) (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: opts.InsecureSkipVerify}
+	if "" != opts.CAFile {
+		ca, err := ioutil.ReadFile(opts.CAFile)
+		if nil != err {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in %s", opts.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	if "" != opts.CertFile {
+		keyFile := opts.KeyFile
+		if "" == keyFile {
+			// the key is bundled with the certificate
+			keyFile = opts.CertFile
+		}
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, keyFile)
+		if nil != err {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// getDialInfo build the dial info of the client
+func (m *MgoCli) getDialInfo() (*mgo.DialInfo, error) {
+	opts := m.opts
+	dialInfo := &mgo.DialInfo{
+		Addrs:          getSeeds(m.host),
+		Direct:         false,
+		Timeout:        defaultTimeout,
+		Database:       m.dbName,
+		ReplicaSetName: opts.ReplicaSet,
+		Source:         opts.AuthSource,
+		Username:       m.usr,
+		Password:       m.pwd,
+		PoolLimit:      defaultPoolLimit,
+		Mechanism:      m.mechanism,
+	}
+	if 0 == len(dialInfo.Addrs) {
+		return nil, errors.New("no mongodb host configured")
+	}
+	if 0 < opts.Timeout {
+		dialInfo.Timeout = opts.Timeout
+	}
+	if 0 < opts.PoolLimit {
+		dialInfo.PoolLimit = opts.PoolLimit
+	}
+
+	if mechanismX509 == strings.ToUpper(m.mechanism) {
+		if nil == opts.TLS || "" == opts.TLS.CertFile {
+			return nil, errors.New("x509 authentication requires the tls client certificate")
+		}
+		if "" == m.usr {
+			return nil, errors.New("x509 authentication requires the subject of the client certificate as the user")
+		}
+		if "" == dialInfo.Source {
+			dialInfo.Source = "$external"
+		}
+		dialInfo.Password = ""
+	}
+
+	if nil != opts.TLS {
+		config, err := getTLSConfig(opts.TLS)
+		if nil != err {
+			return nil, err
+		}
+		dialInfo.DialServer = func(addr *mgo.ServerAddr) (net.Conn, error) {
+			return tls.DialWithDialer(&net.Dialer{Timeout: dialInfo.Timeout}, "tcp", addr.String(), config)
+		}
+	}
+	return dialInfo, nil
+}
@@ -0,0 +1,121 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package mgoclient
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"configcenter/src/storage"
+
+	"gopkg.in/mgo.v2"
+)
+
+func TestParseOptions(t *testing.T) {
+	config := map[string]string{
+		"mongodb.replicaSet":           "rs0",
+		"mongodb.searchReadPreference": "secondaryPreferred",
+		"mongodb.timeout":              "10",
+		"mongodb.poolLimit":            "100",
+		"mongodb.tls":                  "true",
+		"mongodb.tlsCAFile":            "/data/ca.pem",
+	}
+	opts, err := ParseOptions(config, "mongodb")
+	if nil != err {
+		t.Fatal(err)
+	}
+	expect := &Options{
+		ReplicaSet:           "rs0",
+		SearchReadPreference: "secondaryPreferred",
+		Timeout:              time.Second * 10,
+		PoolLimit:            100,
+		TLS:                  &TLSOptions{CAFile: "/data/ca.pem"},
+	}
+	if !reflect.DeepEqual(expect, opts) {
+		t.Fatalf("expect %+v, got %+v", expect, opts)
+	}
+
+	for _, invalid := range []map[string]string{
+		{"mongodb.searchReadPreference": "secondaryOnly"},
+		{"mongodb.timeout": "-1"},
+		{"mongodb.poolLimit": "many"},
+	} {
+		if _, err := ParseOptions(invalid, "mongodb"); nil == err {
+			t.Errorf("expect error of %v", invalid)
+		}
+	}
+}
+
+func TestParseReadPreference(t *testing.T) {
+	cases := map[string]mgo.Mode{
+		"":                   mgo.Primary,
+		"primary":            mgo.Primary,
+		"primaryPreferred":   mgo.PrimaryPreferred,
+		"secondary":          mgo.Secondary,
+		"SecondaryPreferred": mgo.SecondaryPreferred,
+		"nearest":            mgo.Nearest,
+	}
+	for pref, expect := range cases {
+		mode, err := parseReadPreference(pref)
+		if nil != err || mode != expect {
+			t.Errorf("%s: expect %v, got %v, %v", pref, expect, mode, err)
+		}
+	}
+}
+
+func TestGetSeeds(t *testing.T) {
+	seeds := getSeeds("10.0.0.1, 10.0.0.2:27018,,")
+	expect := []string{"10.0.0.1", "10.0.0.2:27018"}
+	if !reflect.DeepEqual(expect, seeds) {
+		t.Fatalf("expect %v, got %v", expect, seeds)
+	}
+}
+
+func TestGetDialInfo(t *testing.T) {
+	db, _ := NewMgoCli("10.0.0.1,10.0.0.2", "27017", "user", "pwd", "", "cmdb", &Options{ReplicaSet: "rs0", AuthSource: "admin"})
+	info, err := db.getDialInfo()
+	if nil != err {
+		t.Fatal(err)
+	}
+	if 2 != len(info.Addrs) || "rs0" != info.ReplicaSetName || "admin" != info.Source ||
+		defaultTimeout != info.Timeout || defaultPoolLimit != info.PoolLimit || nil != info.DialServer {
+		t.Fatalf("unexpected dial info %+v", info)
+	}
+
+	db, _ = NewMgoCli("10.0.0.1", "27017", "", "", "MONGODB-X509", "cmdb", &Options{TLS: &TLSOptions{}})
+	if _, err := db.getDialInfo(); nil == err {
+		t.Fatal("expect error of x509 without the client certificate")
+	}
+
+	db, _ = NewMgoCli("", "27017", "user", "pwd", "", "cmdb", nil)
+	if _, err := db.getDialInfo(); nil == err {
+		t.Fatal("expect error of no host")
+	}
+}
+
+func TestSecondary(t *testing.T) {
+	db, _ := NewMgoCli("10.0.0.1", "27017", "user", "pwd", "", "cmdb", nil)
+	if db != storage.Secondary(db) {
+		t.Fatal("expect the primary client without search read preference")
+	}
+
+	db.searchMode = mgo.SecondaryPreferred
+	secondary, ok := storage.Secondary(db).(*MgoCli)
+	if !ok || db == secondary || !secondary.secondary || db.secondary {
+		t.Fatalf("unexpected secondary client %+v", secondary)
+	}
+	if secondary != secondary.Secondary() {
+		t.Fatal("expect the secondary client reused")
+	}
+}
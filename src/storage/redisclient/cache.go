@@ -0,0 +1,232 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package redisclient
+
+import (
+	"time"
+
+	"configcenter/src/storage"
+
+	redis "gopkg.in/redis.v5"
+)
+
+// lockScript acquire the lock, or renew it if it is held by the owner
+const lockScript = `
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+if redis.call("set", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return 1
+end
+return 0`
+
+// unlockScript release the lock if it is held by the owner
+const unlockScript = `
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0`
+
+// NewCache return the cache on the redis client, the client is closed with the cache
+func NewCache(client *redis.Client) storage.Cache {
+	return &Redis{session: client}
+}
+
+// cacheErr turn the redis nil into the cache nil
+func cacheErr(err error) error {
+	if redis.Nil == err {
+		return storage.ErrCacheNil
+	}
+	return err
+}
+
+func (r *Redis) Get(key string) (string, error) {
+	val, err := r.session.Get(key).Result()
+	return val, cacheErr(err)
+}
+
+func (r *Redis) Set(key string, value interface{}, expiration time.Duration) error {
+	return r.session.Set(key, value, expiration).Err()
+}
+
+func (r *Redis) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	return r.session.SetNX(key, value, expiration).Result()
+}
+
+func (r *Redis) Del(keys ...string) error {
+	return r.session.Del(keys...).Err()
+}
+
+func (r *Redis) Exists(key string) (bool, error) {
+	return r.session.Exists(key).Result()
+}
+
+func (r *Redis) Expire(key string, expiration time.Duration) (bool, error) {
+	return r.session.Expire(key, expiration).Result()
+}
+
+func (r *Redis) TTL(key string) (time.Duration, error) {
+	return r.session.TTL(key).Result()
+}
+
+func (r *Redis) Keys(pattern string) ([]string, error) {
+	return r.session.Keys(pattern).Result()
+}
+
+func (r *Redis) Incr(key string) (int64, error) {
+	return r.session.Incr(key).Result()
+}
+
+func (r *Redis) IncrBy(key string, value int64) (int64, error) {
+	return r.session.IncrBy(key, value).Result()
+}
+
+func (r *Redis) GetBit(key string, offset int64) (int64, error) {
+	return r.session.GetBit(key, offset).Result()
+}
+
+func (r *Redis) SetBit(key string, offset int64, value int) error {
+	return r.session.SetBit(key, offset, value).Err()
+}
+
+func (r *Redis) GetBits(bits []storage.BitKey) ([]int64, error) {
+	if 0 == len(bits) {
+		return []int64{}, nil
+	}
+	pipe := r.session.Pipeline()
+	defer pipe.Close()
+	cmds := make([]*redis.IntCmd, 0, len(bits))
+	for _, bit := range bits {
+		cmds = append(cmds, pipe.GetBit(bit.Key, bit.Offset))
+	}
+	if _, err := pipe.Exec(); nil != err && redis.Nil != err {
+		return nil, err
+	}
+	result := make([]int64, 0, len(cmds))
+	for _, cmd := range cmds {
+		result = append(result, cmd.Val())
+	}
+	return result, nil
+}
+
+func (r *Redis) HGet(key, field string) (string, error) {
+	val, err := r.session.HGet(key, field).Result()
+	return val, cacheErr(err)
+}
+
+func (r *Redis) HSet(key, field string, value interface{}) error {
+	return r.session.HSet(key, field, value).Err()
+}
+
+func (r *Redis) HMSet(key string, fields map[string]string) error {
+	return r.session.HMSet(key, fields).Err()
+}
+
+func (r *Redis) HGetAll(key string) (map[string]string, error) {
+	return r.session.HGetAll(key).Result()
+}
+
+func (r *Redis) HDel(key string, fields ...string) error {
+	return r.session.HDel(key, fields...).Err()
+}
+
+func (r *Redis) HExists(key, field string) (bool, error) {
+	return r.session.HExists(key, field).Result()
+}
+
+func (r *Redis) HLen(key string) (int64, error) {
+	return r.session.HLen(key).Result()
+}
+
+func (r *Redis) HIncrBy(key, field string, incr int64) (int64, error) {
+	return r.session.HIncrBy(key, field, incr).Result()
+}
+
+func (r *Redis) RPush(key string, values ...interface{}) error {
+	return r.session.RPush(key, values...).Err()
+}
+
+func (r *Redis) BLPop(timeout time.Duration, keys ...string) ([]string, error) {
+	val, err := r.session.BLPop(timeout, keys...).Result()
+	return val, cacheErr(err)
+}
+
+func (r *Redis) LRange(key string, start, stop int64) ([]string, error) {
+	return r.session.LRange(key, start, stop).Result()
+}
+
+func (r *Redis) LLen(key string) (int64, error) {
+	return r.session.LLen(key).Result()
+}
+
+func (r *Redis) SAdd(key string, members ...interface{}) error {
+	return r.session.SAdd(key, members...).Err()
+}
+
+func (r *Redis) SRem(key string, members ...interface{}) error {
+	return r.session.SRem(key, members...).Err()
+}
+
+func (r *Redis) SMembers(key string) ([]string, error) {
+	return r.session.SMembers(key).Result()
+}
+
+func (r *Redis) Lock(key, owner string, expiration time.Duration) (bool, error) {
+	val, err := r.session.Eval(lockScript, []string{key}, owner, int64(expiration/time.Millisecond)).Result()
+	if nil != err {
+		return false, err
+	}
+	locked, _ := val.(int64)
+	return 1 == locked, nil
+}
+
+func (r *Redis) Unlock(key, owner string) error {
+	return r.session.Eval(unlockScript, []string{key}, owner).Err()
+}
+
+func (r *Redis) Publish(channel, message string) error {
+	return r.session.Publish(channel, message).Err()
+}
+
+func (r *Redis) Subscribe(channels ...string) (storage.Subscription, error) {
+	pubsub, err := r.session.Subscribe(channels...)
+	if nil != err {
+		return nil, err
+	}
+	return &subscription{pubsub: pubsub}, nil
+}
+
+func (r *Redis) PSubscribe(patterns ...string) (storage.Subscription, error) {
+	pubsub, err := r.session.PSubscribe(patterns...)
+	if nil != err {
+		return nil, err
+	}
+	return &subscription{pubsub: pubsub}, nil
+}
+
+// subscription the redis pubsub, the subscription confirmations and the pongs are skipped
+type subscription struct {
+	pubsub *redis.PubSub
+}
+
+func (s *subscription) Receive() (*storage.Message, error) {
+	msg, err := s.pubsub.ReceiveMessage()
+	if nil != err {
+		return nil, err
+	}
+	return &storage.Message{Channel: msg.Channel, Pattern: msg.Pattern, Payload: msg.Payload}, nil
+}
+
+func (s *subscription) Close() error {
+	return s.pubsub.Close()
+}
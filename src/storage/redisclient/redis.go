@@ -71,6 +71,14 @@ func (r *Redis) GetSession() interface{} {
 	return r.session
 }
 
+// Ping check the connection to redis
+func (r *Redis) Ping() error {
+	if nil == r.session {
+		return errors.New("redis is not connected")
+	}
+	return r.session.Ping().Err()
+}
+
 func (r *Redis) id(object interface{}) int {
 	return 0
 }
@@ -270,6 +278,11 @@ func (r *Redis) GetMutilByCondition(cName string, fields []string, selector, res
 	return errors.New("no support method")
 }
 
+func (r *Redis) GetMutilByCursor(cName string, fields []string, selector, results interface{}, sort, cursor string, limit int) (string, error) {
+
+	return "", errors.New("no support method")
+}
+
 func (r *Redis) GetCntByCondition(cName string, selector interface{}) (cnt int, err error) {
 
 	return 0, errors.New("no support method")
@@ -355,6 +368,10 @@ func (r *Redis) DropColumn(tableName, field string) error {
 	return errors.New("no support method")
 }
 
+func (r *Redis) StartTransaction() (storage.Tx, error) {
+	return nil, errors.New("no support method")
+}
+
 //GetType 获取操作db的类
 func (r *Redis) GetType() string {
 	return storage.DI_REDIS
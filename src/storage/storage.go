@@ -12,6 +12,21 @@
  
 package storage
 
+import (
+	"errors"
+	"time"
+
+	"gopkg.in/mgo.v2"
+)
+
+// ErrInvalidCursor the cursor is malformed or not created by the same sort
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// IsDuplicated return whether the write is rejected by the unique index
+func IsDuplicated(err error) bool {
+	return mgo.IsDup(err)
+}
+
 // DI define storage interface
 type DI interface {
 	GetIncID(cName string) (int64, error)
@@ -20,6 +35,8 @@ type DI interface {
 	UpdateByCondition(cName string, data, condiction interface{}) error
 	GetOneByCondition(cName string, fields []string, condiction interface{}, result interface{}) error
 	GetMutilByCondition(cName string, fields []string, condiction interface{}, result interface{}, sort string, start, limit int) error
+	// GetMutilByCursor get the page after the cursor, return the cursor of the next page, empty if no more
+	GetMutilByCursor(cName string, fields []string, condiction interface{}, result interface{}, sort, cursor string, limit int) (string, error)
 	GetCntByCondition(cName string, condiction interface{}) (int, error)
 	DelByCondition(cName string, condiction interface{}) error
 	HasTable(cName string) (bool, error)
@@ -35,6 +52,24 @@ type DI interface {
 	Open() error
 	Close()
 	GetSession() interface{}
+	// Ping check the connection to the storage
+	Ping() error
+	StartTransaction() (Tx, error)
+}
+
+// SecondaryReader is implemented by the storages able to serve the searches from the secondary members
+type SecondaryReader interface {
+	// Secondary return the DI whose searches may read from the secondary members
+	Secondary() DI
+}
+
+// Secondary return the DI whose searches may read from the secondary members if db supports it,
+// db itself otherwise, the reads backing a write must keep using db
+func Secondary(db DI) DI {
+	if reader, ok := db.(SecondaryReader); ok {
+		return reader.Secondary()
+	}
+	return db
 }
 
 const (
@@ -49,6 +84,8 @@ type Index struct {
 	Name    string
 	Columns []string
 	Type    int
+	// ExpireAfter mongodb only, the documents expire after the time of the only column, no expiration if zero
+	ExpireAfter time.Duration
 }
 
 type Column struct {
@@ -86,4 +123,8 @@ const (
 	DI_MYSQL string = "mysql"
 	DI_MONGO string = "mongodb"
 	DI_REDIS string = "redis"
+	// DI_MEMORY the in-memory mongodb, for the tests only
+	DI_MEMORY string = "memory"
+	// DI_MEMORY_REDIS the in-memory redis, for the tests only
+	DI_MEMORY_REDIS string = "memory_redis"
 )
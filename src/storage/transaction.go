@@ -0,0 +1,277 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/xid"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// ErrTxFinished the transaction has been committed or rolled back
+var ErrTxFinished = errors.New("transaction has been finished")
+
+// TxLogCollection the collection of the compensating logs of the unfinished transactions
+const TxLogCollection = "cc_TxLog"
+
+// TxTimeout the transaction not written for the duration is regarded as abandoned by a crashed process,
+// it is rolled back by RecoverTransactions
+var TxTimeout = time.Minute
+
+// Tx the unit of work of the storage, the writes take effect at once and
+// are undone in the reverse order if the transaction is rolled back
+type Tx interface {
+	Insert(cName string, data interface{}) (int, error)
+	DelByCondition(cName string, condiction interface{}) error
+	// GetOneByCondition, GetMutilByCondition and GetCntByCondition read from the primary,
+	// the checks the writes of the transaction depend on read through them
+	GetOneByCondition(cName string, fields []string, condiction interface{}, result interface{}) error
+	GetMutilByCondition(cName string, fields []string, condiction interface{}, result interface{}, sort string, start, limit int) error
+	GetCntByCondition(cName string, condiction interface{}) (int, error)
+	// Commit discard the compensating log, the writes can not be undone any more
+	Commit() error
+	// Rollback undo the writes done in the transaction
+	Rollback() error
+}
+
+// TxLog the compensating log of a write in a transaction, it is persisted before the write
+// so that the transaction abandoned by a crashed process can still be rolled back.
+// Each write has its own log, the logs of a transaction are never rewritten
+type TxLog struct {
+	TxID string    `bson:"tx_id"`
+	Seq  int       `bson:"seq"`
+	Op   TxOp      `bson:"op"`
+	Time time.Time `bson:"time"`
+}
+
+// TxOp the compensation of a write
+type TxOp struct {
+	Collection string `bson:"collection"`
+	// InsertedID the _id of the inserted data, it is removed on rollback
+	InsertedID interface{} `bson:"inserted_id,omitempty"`
+	// Deleted the deleted datas, they are inserted again on rollback
+	Deleted []map[string]interface{} `bson:"deleted,omitempty"`
+}
+
+// undo run the compensation, it can be run again after a partial rollback
+func (op *TxOp) undo(db DI) error {
+	if nil != op.InsertedID {
+		return db.DelByCondition(op.Collection, map[string]interface{}{"_id": op.InsertedID})
+	}
+	datas := make([]interface{}, 0, len(op.Deleted))
+	for _, origin := range op.Deleted {
+		// remove the restored one first, the origin is not inserted twice if the undo is replayed
+		condiction := origin
+		if id, ok := origin["_id"]; ok {
+			condiction = map[string]interface{}{"_id": id}
+		}
+		if err := db.DelByCondition(op.Collection, condiction); nil != err {
+			return err
+		}
+		datas = append(datas, origin)
+	}
+	return db.InsertMuti(op.Collection, datas...)
+}
+
+// withID return the copy of the data as a document with the _id, the _id is generated if the data has none
+func withID(data interface{}) (map[string]interface{}, interface{}, error) {
+	doc := make(map[string]interface{})
+	switch value := data.(type) {
+	case map[string]interface{}:
+		for key, val := range value {
+			doc[key] = val
+		}
+	default:
+		raw, err := bson.Marshal(data)
+		if nil != err {
+			return nil, nil, err
+		}
+		if err := bson.Unmarshal(raw, &doc); nil != err {
+			return nil, nil, err
+		}
+	}
+	if _, ok := doc["_id"]; !ok {
+		doc["_id"] = bson.NewObjectId()
+	}
+	return doc, doc["_id"], nil
+}
+
+// compensatingTx implement the Tx by recording the inverse operation of each write,
+// it works with the storages which has no multi-document transaction
+type compensatingTx struct {
+	db       DI
+	id       string
+	ops      []TxOp
+	logged   bool
+	finished bool
+}
+
+// NewCompensatingTx return a transaction on the storage which compensates the writes on rollback,
+// the compensating logs are kept in the TxLogCollection of the storage until the transaction finished
+func NewCompensatingTx(db DI) Tx {
+	return &compensatingTx{db: db, id: xid.New().String()}
+}
+
+// log persist the compensating log of the op, the op is discarded if the log can not be persisted
+func (t *compensatingTx) log(op TxOp) error {
+	if _, err := t.db.Insert(TxLogCollection, &TxLog{TxID: t.id, Seq: len(t.ops), Op: op, Time: time.Now()}); nil != err {
+		return err
+	}
+	t.ops = append(t.ops, op)
+	t.logged = true
+	return nil
+}
+
+// Insert insert the data, the data is removed by its _id on rollback
+func (t *compensatingTx) Insert(cName string, data interface{}) (int, error) {
+	if t.finished {
+		return 0, ErrTxFinished
+	}
+	doc, insertedID, err := withID(data)
+	if nil != err {
+		return 0, err
+	}
+	if err := t.log(TxOp{Collection: cName, InsertedID: insertedID}); nil != err {
+		return 0, err
+	}
+	id, err := t.db.Insert(cName, doc)
+	if nil != err {
+		// nothing is inserted, e.g. the data is duplicated, the persisted log removes nothing by the new _id
+		t.ops = t.ops[:len(t.ops)-1]
+		return id, err
+	}
+	return id, nil
+}
+
+// DelByCondition delete the datas, the original datas are inserted again on rollback
+func (t *compensatingTx) DelByCondition(cName string, condiction interface{}) error {
+	if t.finished {
+		return ErrTxFinished
+	}
+	origins := make([]map[string]interface{}, 0)
+	if err := t.db.GetMutilByCondition(cName, nil, condiction, &origins, "", 0, 0); nil != err {
+		return err
+	}
+	if 0 == len(origins) {
+		return nil
+	}
+	if err := t.log(TxOp{Collection: cName, Deleted: origins}); nil != err {
+		return err
+	}
+	return t.db.DelByCondition(cName, condiction)
+}
+
+// GetOneByCondition read one document from the storage of the transaction
+func (t *compensatingTx) GetOneByCondition(cName string, fields []string, condiction interface{}, result interface{}) error {
+	return t.db.GetOneByCondition(cName, fields, condiction, result)
+}
+
+// GetMutilByCondition read the documents from the storage of the transaction
+func (t *compensatingTx) GetMutilByCondition(cName string, fields []string, condiction interface{}, result interface{}, sort string, start, limit int) error {
+	return t.db.GetMutilByCondition(cName, fields, condiction, result, sort, start, limit)
+}
+
+// GetCntByCondition count the documents in the storage of the transaction
+func (t *compensatingTx) GetCntByCondition(cName string, condiction interface{}) (int, error) {
+	return t.db.GetCntByCondition(cName, condiction)
+}
+
+// Commit finish the transaction, the writes are rolled back if the log can not be discarded,
+// otherwise they would be rolled back by RecoverTransactions later
+func (t *compensatingTx) Commit() error {
+	if t.finished {
+		return ErrTxFinished
+	}
+	if t.logged {
+		if err := t.db.DelByCondition(TxLogCollection, map[string]interface{}{"tx_id": t.id}); nil != err {
+			if rbErr := t.Rollback(); nil != rbErr {
+				return fmt.Errorf("commit failed: %v, %v", err, rbErr)
+			}
+			return fmt.Errorf("commit failed, rolled back: %v", err)
+		}
+	}
+	t.finished = true
+	t.ops = nil
+	return nil
+}
+
+// Rollback run the compensations in the reverse order, all of them are tried even if some one failed,
+// the log is kept for RecoverTransactions if any one failed
+func (t *compensatingTx) Rollback() error {
+	if t.finished {
+		return ErrTxFinished
+	}
+	t.finished = true
+	var failed []error
+	for i := len(t.ops) - 1; i >= 0; i-- {
+		if err := t.ops[i].undo(t.db); nil != err {
+			failed = append(failed, err)
+		}
+	}
+	t.ops = nil
+	if 0 != len(failed) {
+		return fmt.Errorf("rollback failed, %d of the compensations failed, the first error: %v", len(failed), failed[0])
+	}
+	if t.logged {
+		return t.db.DelByCondition(TxLogCollection, map[string]interface{}{"tx_id": t.id})
+	}
+	return nil
+}
+
+// RecoverTransactions roll back the transactions abandoned by the crashed processes, it is called at
+// the startup, the transactions written in TxTimeout are regarded as alive in the other processes
+func RecoverTransactions(db DI) error {
+	deadline := time.Now().Add(-TxTimeout)
+	alive := make([]TxLog, 0)
+	if err := db.GetMutilByCondition(TxLogCollection, []string{"tx_id"}, map[string]interface{}{"time": map[string]interface{}{"$gte": deadline}}, &alive, "", 0, 0); nil != err {
+		return err
+	}
+	aliveIDs := make(map[string]bool, len(alive))
+	for _, log := range alive {
+		aliveIDs[log.TxID] = true
+	}
+
+	logs := make([]TxLog, 0)
+	if err := db.GetMutilByCondition(TxLogCollection, nil, map[string]interface{}{"time": map[string]interface{}{"$lt": deadline}}, &logs, "seq", 0, 0); nil != err {
+		return err
+	}
+	txs := make([]*compensatingTx, 0)
+	abandoned := make(map[string]*compensatingTx)
+	for _, log := range logs {
+		if aliveIDs[log.TxID] {
+			continue
+		}
+		tx, ok := abandoned[log.TxID]
+		if !ok {
+			tx = &compensatingTx{db: db, id: log.TxID, logged: true}
+			abandoned[log.TxID] = tx
+			txs = append(txs, tx)
+		}
+		tx.ops = append(tx.ops, log.Op)
+	}
+
+	var failed []error
+	for _, tx := range txs {
+		if err := tx.Rollback(); nil != err {
+			failed = append(failed, fmt.Errorf("transaction %s: %v", tx.id, err))
+		}
+	}
+	if 0 != len(failed) {
+		return fmt.Errorf("recover %d of %d transactions failed, the first error: %v", len(failed), len(txs), failed[0])
+	}
+	return nil
+}
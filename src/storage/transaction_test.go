@@ -0,0 +1,229 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+
+package storage
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// memDI keep the rows of one table in memory, the write fails at the failAt-th call,
+// the compensating logs are only counted, they must never be rewritten
+type memDI struct {
+	DI
+	rows    []map[string]interface{}
+	writes  int
+	failAt  int
+	logs    int
+	updated bool
+}
+
+var errFake = errors.New("fake error")
+
+func (m *memDI) fail() bool {
+	m.writes++
+	return m.writes == m.failAt
+}
+
+func (m *memDI) match(row map[string]interface{}, condiction interface{}) bool {
+	for key, val := range condiction.(map[string]interface{}) {
+		if row[key] != val {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *memDI) Insert(cName string, data interface{}) (int, error) {
+	if TxLogCollection == cName {
+		m.logs++
+		return m.logs, nil
+	}
+	if m.fail() {
+		return 0, errFake
+	}
+	m.rows = append(m.rows, data.(map[string]interface{}))
+	return len(m.rows), nil
+}
+
+func (m *memDI) InsertMuti(cName string, data ...interface{}) error {
+	if m.fail() {
+		return errFake
+	}
+	for _, item := range data {
+		m.rows = append(m.rows, item.(map[string]interface{}))
+	}
+	return nil
+}
+
+func (m *memDI) GetMutilByCondition(cName string, fields []string, condiction interface{}, result interface{}, sort string, start, limit int) error {
+	rows := make([]map[string]interface{}, 0)
+	for _, row := range m.rows {
+		if m.match(row, condiction) {
+			rows = append(rows, row)
+		}
+	}
+	*result.(*[]map[string]interface{}) = rows
+	return nil
+}
+
+func (m *memDI) UpdateByCondition(cName string, data, condiction interface{}) error {
+	m.updated = true
+	return nil
+}
+
+func (m *memDI) DelByCondition(cName string, condiction interface{}) error {
+	if TxLogCollection == cName {
+		m.logs = 0
+		return nil
+	}
+	if m.fail() {
+		return errFake
+	}
+	rows := make([]map[string]interface{}, 0)
+	for _, row := range m.rows {
+		if !m.match(row, condiction) {
+			rows = append(rows, row)
+		}
+	}
+	m.rows = rows
+	return nil
+}
+
+func newMemDI() *memDI {
+	return &memDI{rows: []map[string]interface{}{
+		{"host": 1, "module": 1},
+		{"host": 2, "module": 1},
+	}}
+}
+
+// withoutID return the rows without the _id generated by the transaction
+func withoutID(rows []map[string]interface{}) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		copied := make(map[string]interface{})
+		for key, val := range row {
+			if "_id" != key {
+				copied[key] = val
+			}
+		}
+		result = append(result, copied)
+	}
+	return result
+}
+
+// transfer move the host 1 and 2 from the module 1 to the module 2
+func transfer(tx Tx) error {
+	for _, host := range []int{1, 2} {
+		if err := tx.DelByCondition("t", map[string]interface{}{"host": host}); nil != err {
+			return err
+		}
+		if _, err := tx.Insert("t", map[string]interface{}{"host": host, "module": 2}); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestCompensatingTxCommit(t *testing.T) {
+	db := newMemDI()
+	tx := NewCompensatingTx(db)
+	if err := transfer(tx); nil != err {
+		t.Fatalf("transfer error: %v", err)
+	}
+	if err := tx.Commit(); nil != err {
+		t.Fatalf("commit error: %v", err)
+	}
+	expected := []map[string]interface{}{{"host": 1, "module": 2}, {"host": 2, "module": 2}}
+	if !reflect.DeepEqual(withoutID(db.rows), expected) {
+		t.Errorf("rows not as expected: %v", db.rows)
+	}
+	if db.updated {
+		t.Errorf("the log should be appended only")
+	}
+	if 0 != db.logs {
+		t.Errorf("the log should be discarded, logs: %d", db.logs)
+	}
+	if err := tx.Rollback(); err != ErrTxFinished {
+		t.Errorf("rollback after commit should fail: %v", err)
+	}
+	if _, err := tx.Insert("t", map[string]interface{}{"host": 3}); err != ErrTxFinished {
+		t.Errorf("insert after commit should fail: %v", err)
+	}
+}
+
+func TestCompensatingTxRollback(t *testing.T) {
+	// the transfer has 4 writes, fail at each of them
+	for failAt := 1; failAt <= 4; failAt++ {
+		db := newMemDI()
+		db.failAt = failAt
+		tx := NewCompensatingTx(db)
+		if err := transfer(tx); err != errFake {
+			t.Fatalf("fail at %d, error not as expected: %v", failAt, err)
+		}
+		if err := tx.Rollback(); nil != err {
+			t.Fatalf("fail at %d, rollback error: %v", failAt, err)
+		}
+
+		rows := make([]map[string]interface{}, 0)
+		for _, host := range []int{1, 2} {
+			db.GetMutilByCondition("t", nil, map[string]interface{}{"host": host}, &rows, "", 0, 0)
+			if !reflect.DeepEqual(rows, []map[string]interface{}{{"host": host, "module": 1}}) {
+				t.Errorf("fail at %d, rows of host %d not restored: %v", failAt, host, rows)
+			}
+		}
+		if 0 != db.logs {
+			t.Errorf("fail at %d, the log should be discarded, logs: %d", failAt, db.logs)
+		}
+	}
+}
+
+func TestCompensatingTxRollbackFailed(t *testing.T) {
+	db := newMemDI()
+	tx := NewCompensatingTx(db)
+	if err := transfer(tx); nil != err {
+		t.Fatalf("transfer error: %v", err)
+	}
+	// the first compensation fails, the others are still tried
+	db.failAt = db.writes + 1
+	if err := tx.Rollback(); nil == err {
+		t.Errorf("rollback should fail")
+	}
+	if 3 != len(db.rows) {
+		t.Errorf("rows not as expected: %v", db.rows)
+	}
+	if 4 != db.logs {
+		t.Errorf("the logs should be kept for the recovery, logs: %d", db.logs)
+	}
+}
+
+func TestCompensatingTxRollbackInsertedOnly(t *testing.T) {
+	db := newMemDI()
+	tx := NewCompensatingTx(db)
+	// the same as the stored row, only the inserted one is removed on rollback
+	data := map[string]interface{}{"host": 1, "module": 1}
+	if _, err := tx.Insert("t", data); nil != err {
+		t.Fatalf("insert error: %v", err)
+	}
+	if _, ok := data["_id"]; ok {
+		t.Errorf("the data of the caller should not be changed: %v", data)
+	}
+	if err := tx.Rollback(); nil != err {
+		t.Fatalf("rollback error: %v", err)
+	}
+	if !reflect.DeepEqual(db.rows, newMemDI().rows) {
+		t.Errorf("rows not as expected: %v", db.rows)
+	}
+}
@@ -0,0 +1,56 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package controllers
+
+import (
+	"configcenter/src/common/blog"
+	"configcenter/src/web_server/application/logics"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportStream write the exported rows of the csv or ndjson format to the response page by page
+func exportStream(c *gin.Context, name, format string, export func(w logics.ExportWriter) error) {
+	w, err := logics.NewExportWriter(format, c.DefaultPostForm("header", logics.ExportHeaderID), c.Writer)
+	if nil != err {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logics.AddDownExportHttpHeader(c, fmt.Sprintf("%s.%s", name, format))
+	if err := export(w); nil != err {
+		blog.Errorf("export %s of the format %s error: %v", name, format, err)
+		if c.Writer.Written() {
+			// the rows are being sent with the status 200, the client must not take the partial rows as the whole
+			if writeErr := w.WriteError(err); nil != writeErr {
+				abortExport(c)
+			}
+			return
+		}
+		c.Header("Content-Type", "")
+		c.Header("Content-Disposition", "")
+		c.String(http.StatusBadGateway, "获取数据失败, %s", err.Error())
+	}
+}
+
+// abortExport close the connection without ending the chunked response, the client gets an incomplete transfer
+func abortExport(c *gin.Context) {
+	conn, _, err := c.Writer.Hijack()
+	if nil != err {
+		blog.Errorf("abort the export error: %v", err)
+		return
+	}
+	conn.Close()
+}
@@ -0,0 +1,70 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+
+package controllers
+
+import (
+	"configcenter/src/web_server/application/logics"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// newExportStreamServer serve the export which fails after the first page is sent
+func newExportStreamServer() *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.POST("/export/:format", func(c *gin.Context) {
+		exportStream(c, "host", c.Param("format"), func(w logics.ExportWriter) error {
+			w.WriteHeader([]logics.ExportField{{ID: "bk_host_innerip"}})
+			w.WriteRow(map[string]interface{}{"bk_host_innerip": "127.0.0.1"})
+			w.Flush()
+			return errors.New("search the second page failed")
+		})
+	})
+	return httptest.NewServer(engine)
+}
+
+func TestExportStreamFailedJSON(t *testing.T) {
+	srv := newExportStreamServer()
+	defer srv.Close()
+
+	resp, err := http.PostForm(srv.URL+"/export/"+logics.ExportFormatJSON, url.Values{})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	require.Equal(t, []string{`{"bk_host_innerip":"127.0.0.1"}`, `{"bk_error_msg":"search the second page failed"}`}, lines)
+}
+
+func TestExportStreamFailedCSV(t *testing.T) {
+	srv := newExportStreamServer()
+	defer srv.Close()
+
+	resp, err := http.PostForm(srv.URL+"/export/"+logics.ExportFormatCSV, url.Values{})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	// the connection is closed before the response ends
+	body, err := ioutil.ReadAll(resp.Body)
+	require.Error(t, err)
+	require.Equal(t, "bk_host_innerip\n127.0.0.1\n", string(body))
+}
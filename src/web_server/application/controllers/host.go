@@ -49,66 +49,11 @@ func init() {
 
 // ImportHost import host
 func ImportHost(c *gin.Context) {
-	file, err := c.FormFile("file")
-	if nil != err {
-		msg := getReturnStr(CODE_ERROR_UPLOAD_FILE, "未找到上传文件", nil)
-		c.String(http.StatusOK, string(msg))
-		return
-	}
 	logics.SetProxyHeader(c)
 
-	randNum := rand.Uint32()
-	dir := webCommon.ResourcePath + "/import/"
-	_, err = os.Stat(dir)
-	if nil != err {
-		os.MkdirAll(dir, os.ModeDir|os.ModePerm)
-	}
-	filePath := fmt.Sprintf("%s/importhost-%d-%d.xlsx", dir, time.Now().UnixNano(), randNum)
-	err = c.SaveUploadedFile(file, filePath)
-	if nil != err {
-		msg := getReturnStr(CODE_ERROR_UPLOAD_FILE, fmt.Sprintf("保存文件失败;error:%s", err.Error()), nil)
-		c.String(http.StatusOK, string(msg))
-		return
-	}
-	defer os.Remove(filePath) //del file
-	f, err := xlsx.OpenFile(filePath)
-	if nil != err {
-		msg := getReturnStr(CODE_ERROR_OPEN_FILE, fmt.Sprintf("保存上传文件;error:%s", err.Error()), nil)
-		c.String(http.StatusOK, string(msg))
-		return
-	}
 	cc := api.NewAPIResource()
 	apiSite, _ := cc.AddrSrv.GetServer(types.CC_MODULE_APISERVER)
-	hosts, err := logics.GetImportHosts(f, apiSite, c.Request.Header)
-	if 0 == len(hosts) {
-		msg := getReturnStr(CODE_ERROR_OPEN_FILE, "文件内容不能为空", nil)
-		if nil != err {
-			msg = getReturnStr(CODE_ERROR_OPEN_FILE, "文件内容不能为空;"+err.Error(), nil)
-		}
-		c.String(http.StatusOK, string(msg))
-		return
-	}
-	if nil != err {
-		msg := getReturnStr(CODE_ERROR_OPEN_FILE, err.Error(), nil)
-		c.String(http.StatusOK, string(msg))
-		return
-	}
-	url := apiSite + fmt.Sprintf("/api/%s/hosts/add", webCommon.API_VERSION)
-	params := make(map[string]interface{})
-	params["host_info"] = hosts
-	params["bk_supplier_id"] = common.BKDefaultSupplierID
-
-	blog.Info("add host url: %v", url)
-	blog.Info("add host content: %v", params)
-	reply, err := httpRequest(url, params, c.Request.Header)
-	blog.Info("add host result: %v", reply)
-
-	if nil != err {
-		c.String(http.StatusOK, err.Error())
-	} else {
-		c.String(http.StatusOK, reply)
-	}
-
+	runImportJob(c, logics.NewHostImportTask(apiSite, c.Request.Header), "importhost")
 }
 
 // ExportHost export host
@@ -121,6 +66,12 @@ func ExportHost(c *gin.Context) {
 	logics.SetProxyHeader(c)
 
 	apiSite, _ := cc.AddrSrv.GetServer(types.CC_MODULE_APISERVER)
+	if format := c.DefaultPostForm("format", logics.ExportFormatXlsx); logics.ExportFormatXlsx != format {
+		exportStream(c, "host", format, func(w logics.ExportWriter) error {
+			return logics.ExportHosts(appIDStr, hostIDStr, apiSite, c.Request.Header, w)
+		})
+		return
+	}
 	hostInfo, err := logics.GetHostData(appIDStr, hostIDStr, apiSite, c.Request.Header, kvMap)
 	if err != nil {
 		blog.Error(err.Error())
@@ -0,0 +1,186 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package controllers
+
+import (
+	"bytes"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/core/cc/api"
+	"configcenter/src/common/core/cc/wactions"
+	"configcenter/src/common/types"
+	"configcenter/src/web_server/application/logics"
+	webCommon "configcenter/src/web_server/common"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	wactions.RegisterNewAction(wactions.Action{Verb: common.HTTPSelectGet, Path: "/import/jobs/:job_id", Params: nil, Handler: GetImportJob})
+	wactions.RegisterNewAction(wactions.Action{Verb: common.HTTPSelectGet, Path: "/import/jobs/:job_id/report", Params: nil, Handler: DownloadImportReport})
+	wactions.RegisterNewAction(wactions.Action{Verb: common.HTTPCreate, Path: "/import/jobs/:job_id/resume", Params: nil, Handler: ResumeImportJob})
+}
+
+// GetImportJob get the progress and the failed rows of the import job submitted by the session user
+func GetImportJob(c *gin.Context) {
+	ownerID, user := logics.GetSessionUser(c)
+	job := logics.GetImportJob(c.Param("job_id"), ownerID, user)
+	if nil == job {
+		msg := getReturnStr(common.CCErrCommNotFound, fmt.Sprintf("导入任务%s不存在", c.Param("job_id")), nil)
+		c.String(http.StatusOK, msg)
+		return
+	}
+	c.String(http.StatusOK, getReturnStr(CODE_SUCESS, "", job))
+}
+
+// DownloadImportReport download the imported file with the reasons of the failed rows
+func DownloadImportReport(c *gin.Context) {
+	jobID := c.Param("job_id")
+	ownerID, user := logics.GetSessionUser(c)
+	job := logics.GetImportJob(jobID, ownerID, user)
+	if nil == job {
+		c.String(http.StatusOK, getReturnStr(common.CCErrCommNotFound, fmt.Sprintf("导入任务%s不存在", jobID), nil))
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	if err := logics.WriteImportReport(jobID, ownerID, user, buf); nil != err {
+		blog.Errorf("write the report of the import job %s error: %v", jobID, err)
+		c.String(http.StatusOK, getReturnStr(CODE_ERROR_OPEN_FILE, err.Error(), nil))
+		return
+	}
+	logics.AddDownExportHttpHeader(c, fmt.Sprintf("import_report_%s.%s", jobID, job.Format))
+	c.Writer.WriteHeader(http.StatusOK)
+	c.Writer.Write(buf.Bytes())
+}
+
+// ResumeImportJob import the failed rows of the job again, the rows are read from the uploaded file if given
+func ResumeImportJob(c *gin.Context) {
+	logics.SetProxyHeader(c)
+
+	filePath := ""
+	if _, err := c.FormFile("file"); nil == err {
+		filePath, err = saveImportFile(c, "resume")
+		if nil != err {
+			c.String(http.StatusOK, getReturnStr(CODE_ERROR_UPLOAD_FILE, err.Error(), nil))
+			return
+		}
+	}
+
+	cc := api.NewAPIResource()
+	apiSite, _ := cc.AddrSrv.GetServer(types.CC_MODULE_APISERVER)
+	ownerID, user := logics.GetSessionUser(c)
+	job, err := logics.ResumeImportJob(c.Param("job_id"), ownerID, user, filePath, apiSite, c.Request.Header)
+	if nil != err {
+		if "" != filePath {
+			os.Remove(filePath)
+		}
+		c.String(http.StatusOK, getReturnStr(common.CCErrCommImportFailed, err.Error(), nil))
+		return
+	}
+	replyImportJob(c, job)
+}
+
+// runImportJob import the uploaded file by the job
+func runImportJob(c *gin.Context, task logics.ImportTask, prefix string) {
+	filePath, err := saveImportFile(c, prefix)
+	if nil != err {
+		c.String(http.StatusOK, getReturnStr(CODE_ERROR_UPLOAD_FILE, err.Error(), nil))
+		return
+	}
+
+	ownerID, user := logics.GetSessionUser(c)
+	job, err := logics.SubmitImportJob(task, filePath, ownerID, user, c.Request.Header)
+	if nil != err {
+		os.Remove(filePath) //delete file
+		c.String(http.StatusOK, getReturnStr(CODE_ERROR_OPEN_FILE, err.Error(), nil))
+		return
+	}
+	replyImportJob(c, job)
+}
+
+// replyImportJob reply the job at once if the request is async, otherwise wait the job ends and reply its result,
+// the import of the large file is async unless async=false is requested
+func replyImportJob(c *gin.Context, job *logics.ImportJob) {
+	async, err := strconv.ParseBool(c.PostForm("async"))
+	if nil != err {
+		async = job.Total > logics.ImportSyncRows
+	}
+	if async {
+		c.String(http.StatusOK, getReturnStr(CODE_SUCESS, "", job))
+		return
+	}
+
+	job = logics.WaitImportJob(job.ID)
+	if nil == job {
+		c.String(http.StatusOK, getReturnStr(common.CCErrCommImportFailed, "导入任务已过期", nil))
+		return
+	}
+
+	success := []string{}
+	for _, row := range job.SucceededRows() {
+		success = append(success, fmt.Sprintf("%d", row))
+	}
+	errMsgs := []string{}
+	for _, rowErr := range job.Errors {
+		errMsgs = append(errMsgs, fmt.Sprintf("第%d行%s", rowErr.Row, rowErr.String()))
+	}
+	data := map[string]interface{}{
+		"success": success,
+		"error":   errMsgs,
+		"job":     job,
+	}
+
+	if logics.ImportStatusFinished == job.Status && 0 == job.Failed {
+		c.String(http.StatusOK, getReturnStr(CODE_SUCESS, "", data))
+		return
+	}
+	msg := job.Message
+	if "" == msg {
+		msg = fmt.Sprintf("%d行导入失败", job.Failed)
+	}
+	c.String(http.StatusOK, getReturnStr(common.CCErrCommImportFailed, msg, data))
+}
+
+// saveImportFile save the uploaded xlsx or csv file to the import directory
+func saveImportFile(c *gin.Context, prefix string) (string, error) {
+	file, err := c.FormFile("file")
+	if nil != err {
+		return "", errors.New("未找到上传文件")
+	}
+
+	randNum := rand.Uint32()
+	dir := webCommon.ResourcePath + "/import/"
+	_, err = os.Stat(dir)
+	if nil != err {
+		os.MkdirAll(dir, os.ModeDir|os.ModePerm)
+	}
+	ext := logics.ImportFormatXlsx
+	if ".csv" == strings.ToLower(filepath.Ext(file.Filename)) {
+		ext = logics.ImportFormatCSV
+	}
+	filePath := fmt.Sprintf("%s/%s-%d-%d.%s", dir, prefix, time.Now().UnixNano(), randNum, ext)
+	if err := c.SaveUploadedFile(file, filePath); nil != err {
+		return "", fmt.Errorf("保存文件失败;error:%s", err.Error())
+	}
+	return filePath, nil
+}
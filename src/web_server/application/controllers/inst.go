@@ -23,7 +23,6 @@ import (
 
 	webCommon "configcenter/src/web_server/common"
 	"fmt"
-	"math/rand"
 	"net/http"
 	"os"
 	"reflect"
@@ -42,62 +41,10 @@ func init() {
 func ImportInst(c *gin.Context) {
 	logics.SetProxyHeader(c)
 
-	file, err := c.FormFile("file")
-	if nil != err {
-		msg := getReturnStr(CODE_ERROR_UPLOAD_FILE, "未找到上传文件", nil)
-		c.String(http.StatusOK, string(msg))
-		return
-	}
-
-	randNum := rand.Uint32()
-	dir := webCommon.ResourcePath + "/import/"
-	_, err = os.Stat(dir)
-	if nil != err {
-		os.MkdirAll(dir, os.ModeDir|os.ModePerm)
-	}
-	filePath := fmt.Sprintf("%s/importinsts-%d-%d.xlsx", dir, time.Now().UnixNano(), randNum)
-	err = c.SaveUploadedFile(file, filePath)
-	if nil != err {
-		msg := getReturnStr(CODE_ERROR_UPLOAD_FILE, fmt.Sprintf("保存文件失败;error:%s", err.Error()), nil)
-		c.String(http.StatusOK, string(msg))
-		return
-	}
-	defer os.Remove(filePath) //delete file
-	f, err := xlsx.OpenFile(filePath)
-	if nil != err {
-		msg := getReturnStr(CODE_ERROR_OPEN_FILE, fmt.Sprintf("保存上传文件;error:%s", err.Error()), nil)
-		c.String(http.StatusOK, string(msg))
-		return
-	}
 	cc := api.NewAPIResource()
-	apiAddr, err := cc.AddrSrv.GetServer(types.CC_MODULE_APISERVER)
-	url := apiAddr
-	insts, err := logics.GetImportInsts(f, url, c.Request.Header, 0)
-	if 0 == len(insts) {
-		msg := getReturnStr(CODE_ERROR_OPEN_FILE, "文件内容不能为空", nil)
-		c.String(http.StatusOK, string(msg))
-		return
-	}
-	if nil != err {
-		msg := getReturnStr(CODE_ERROR_OPEN_FILE, err.Error(), nil)
-		c.String(http.StatusOK, string(msg))
-		return
-	}
-
-	blog.Debug("insts data from file:%+v", insts)
 	apiSite, _ := cc.AddrSrv.GetServer(types.CC_MODULE_APISERVER)
-	url = apiSite + "/api/" + webCommon.API_VERSION + "/inst/" + c.Param("bk_supplier_account") + "/" + c.Param("bk_obj_id")
-	blog.Debug("batch insert insts, the url is %s", url)
-	params := make(map[string]interface{})
-	params["BatchInfo"] = insts
-	reply, err := httpRequest(url, params, c.Request.Header)
-	blog.Debug("return the result:", reply)
-	if nil != err {
-		c.String(http.StatusOK, err.Error())
-	} else {
-		c.String(http.StatusOK, reply)
-	}
-
+	task := logics.NewInstImportTask(apiSite, c.Param("bk_supplier_account"), c.Param("bk_obj_id"), c.Request.Header)
+	runImportJob(c, task, "importinsts")
 }
 
 // ExportInst export inst
@@ -111,6 +58,12 @@ func ExportInst(c *gin.Context) {
 
 	kvMap := make(map[string]string)
 	apiSite, _ := cc.AddrSrv.GetServer(types.CC_MODULE_APISERVER)
+	if format := c.DefaultPostForm("format", logics.ExportFormatXlsx); logics.ExportFormatXlsx != format {
+		exportStream(c, fmt.Sprintf("inst_%s", objID), format, func(w logics.ExportWriter) error {
+			return logics.ExportInsts(ownerID, objID, instIDStr, apiSite, c.Request.Header, w)
+		})
+		return
+	}
 	instInfo, err := logics.GetInstData(ownerID, objID, instIDStr, apiSite, c.Request.Header, kvMap)
 	if err != nil {
 		blog.Error(err.Error())
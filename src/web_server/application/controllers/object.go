@@ -23,7 +23,6 @@ import (
 
 	webCommon "configcenter/src/web_server/common"
 	"fmt"
-	"math/rand"
 	"net/http"
 	"os"
 	//"reflect"
@@ -84,65 +83,9 @@ var fields = map[string]string{
 func ImportObject(c *gin.Context) {
 	logics.SetProxyHeader(c)
 
-	file, err := c.FormFile("file")
-	if nil != err {
-		msg := getReturnStr(CODE_ERROR_UPLOAD_FILE, "未找到上传文件", nil)
-		c.String(http.StatusOK, string(msg))
-		return
-	}
-
-	randNum := rand.Uint32()
-	dir := webCommon.ResourcePath + "/import/"
-	_, err = os.Stat(dir)
-	if nil != err {
-		os.MkdirAll(dir, os.ModeDir|os.ModePerm)
-	}
-	filePath := fmt.Sprintf("%s/importinsts-%d-%d.xlsx", dir, time.Now().UnixNano(), randNum)
-	err = c.SaveUploadedFile(file, filePath)
-	if nil != err {
-		msg := getReturnStr(CODE_ERROR_UPLOAD_FILE, fmt.Sprintf("保存文件失败;error:%s", err.Error()), nil)
-		c.String(http.StatusOK, string(msg))
-		return
-	}
-	defer os.Remove(filePath) //delete file
-	f, err := xlsx.OpenFile(filePath)
-	if nil != err {
-		msg := getReturnStr(CODE_ERROR_OPEN_FILE, fmt.Sprintf("保存上传文件;error:%s", err.Error()), nil)
-		c.String(http.StatusOK, string(msg))
-		return
-	}
-
-	attrItems, err := logics.GetImportInsts(f, "", c.Request.Header, 3)
-	if 0 == len(attrItems) {
-		msg := getReturnStr(CODE_ERROR_OPEN_FILE, "文件内容不能为空", nil)
-		c.String(http.StatusOK, string(msg))
-		return
-	}
-
-	blog.Debug("the object file content:%+v", attrItems)
-
 	cc := api.NewAPIResource()
 	apiSite, _ := cc.AddrSrv.GetServer(types.CC_MODULE_APISERVER)
-	url := fmt.Sprintf("%s/api/%s/object/batch", apiSite, webCommon.API_VERSION)
-	blog.Debug("batch insert insts, the url is %s", url)
-	objID := c.Param(common.BKObjIDField)
-	params := map[string]interface{}{
-		objID: map[string]interface{}{
-			"meta": nil,
-			"attr": attrItems,
-		},
-	}
-
-	blog.Debug("import the params(%+v)", params)
-
-	reply, err := httpRequest(url, params, c.Request.Header)
-	blog.Debug("return the result:", reply)
-	if nil != err {
-		c.String(http.StatusOK, err.Error())
-	} else {
-		c.String(http.StatusOK, reply)
-	}
-
+	runImportJob(c, logics.NewObjectImportTask(apiSite, c.Param(common.BKObjIDField)), "importobject")
 }
 
 func setExcelSubTitle(row *xlsx.Row) *xlsx.Row {
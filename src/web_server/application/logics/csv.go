@@ -0,0 +1,159 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package logics
+
+import (
+	"configcenter/src/common"
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// the formats of the imported file
+const (
+	ImportFormatXlsx = "xlsx"
+	ImportFormatCSV  = "csv"
+)
+
+// utf8BOM the spreadsheets may write it at the beginning of the csv
+const utf8BOM = "\ufeff"
+
+// importFileFormat get the format by the extension of the file
+func importFileFormat(filePath string) string {
+	if ".csv" == strings.ToLower(filepath.Ext(filePath)) {
+		return ImportFormatCSV
+	}
+	return ImportFormatXlsx
+}
+
+// readCSV read all the records of the csv file
+func readCSV(filePath string) ([][]string, error) {
+	f, err := os.Open(filePath)
+	if nil != err {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if nil != err {
+		return nil, err
+	}
+	if 0 == len(records) {
+		return nil, errors.New("文件内容不能为空")
+	}
+	if 0 != len(records[0]) {
+		records[0][0] = strings.TrimPrefix(records[0][0], utf8BOM)
+	}
+	return records, nil
+}
+
+// getCSVRows read the rows keyed by the record number, the first record is the property ids,
+// or the property names which are mapped back to the ids, the values are converted by the property types,
+// and the empty row is nil
+func getCSVRows(filePath string, defFields common.KvMap, types, ids map[string]string) (map[int]map[string]interface{}, error) {
+	records, err := readCSV(filePath)
+	if nil != err {
+		return nil, err
+	}
+
+	cols := make([]string, 0, len(records[0]))
+	for _, col := range records[0] {
+		if _, ok := types[col]; false == ok && "" != ids[col] {
+			col = ids[col]
+		}
+		cols = append(cols, col)
+	}
+	rows := make(map[int]map[string]interface{})
+	for index := 1; index < len(records); index++ {
+		row := make(map[string]interface{})
+		for i, value := range records[index] {
+			if i >= len(cols) || "" == cols[i] || "" == value {
+				continue
+			}
+			row[cols[i]] = csvValue(value, types[cols[i]])
+		}
+		if 0 == len(row) {
+			rows[index+1] = nil
+			continue
+		}
+		for k, v := range defFields {
+			row[k] = v
+		}
+		rows[index+1] = row
+	}
+	return rows, nil
+}
+
+// csvValue convert the value by the property type, the value is kept if it can not be converted,
+// so it is reported by the validator
+func csvValue(value, propertyType string) interface{} {
+	switch propertyType {
+	case common.FiledTypeInt:
+		if i, err := strconv.Atoi(value); nil == err {
+			return i
+		}
+	case common.FiledTypeBool:
+		if b, err := strconv.ParseBool(value); nil == err {
+			return b
+		}
+	}
+	return value
+}
+
+// writeCSVReport write the csv file with the reasons of the failed rows in the report column
+func writeCSVReport(filePath string, reasons map[int][]string, w io.Writer) error {
+	records, err := readCSV(filePath)
+	if nil != err {
+		return err
+	}
+
+	// reuse the column if the file is a report already
+	col := -1
+	for i, name := range records[0] {
+		if importReportColumn == name {
+			col = i
+		}
+	}
+	if -1 == col {
+		for _, record := range records {
+			if col < len(record) {
+				col = len(record)
+			}
+		}
+	}
+
+	for index, record := range records {
+		for len(record) <= col {
+			record = append(record, "")
+		}
+		switch msgs := reasons[index+1]; {
+		case 0 == index:
+			record[col] = importReportColumn
+		case 0 != len(msgs):
+			record[col] = strings.Join(msgs, "; ")
+		default:
+			record[col] = ""
+		}
+		records[index] = record
+	}
+
+	writer := csv.NewWriter(w)
+	writer.WriteAll(records)
+	return writer.Error()
+}
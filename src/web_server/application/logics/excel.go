@@ -121,7 +121,23 @@ func GetExcelData(sheet *xlsx.Sheet, fields, defFields common.KvMap, isCheckHead
 	if nil != err {
 		return nil, err
 	}
-	var errMsg string
+	hosts, cellErrs := getExcelRows(sheet, cols, defFields, firstRow)
+	if 0 != len(cellErrs) {
+		var errMsg string
+		for _, cellErr := range cellErrs {
+			errMsg = fmt.Sprintf("%s%s;", errMsg, cellErr.Message)
+		}
+		return nil, errors.New(errMsg)
+	}
+
+	return hosts, nil
+
+}
+
+// getExcelRows read the rows keyed by the excel row number, the empty row is nil,
+// the cells which can not be read are returned as the errors of their rows
+func getExcelRows(sheet *xlsx.Sheet, cols []string, defFields common.KvMap, firstRow int) (map[int]map[string]interface{}, []ImportRowError) {
+	var cellErrs []ImportRowError
 	hosts := make(map[int]map[string]interface{})
 	index := headerRow
 	if 0 != firstRow {
@@ -176,7 +192,13 @@ func GetExcelData(sheet *xlsx.Sheet, fields, defFields common.KvMap, isCheckHead
 				isEmpty = false
 				host[cols[celIDnex]] = cellValue
 			default:
-				errMsg = fmt.Sprintf("%s第%d行%d列无法处理内容;", errMsg, (index + 1), (celIDnex + 1))
+				isEmpty = false
+				cellErrs = append(cellErrs, ImportRowError{
+					Row:     index + 1,
+					Field:   cols[celIDnex],
+					Code:    common.CCErrCommParamsInvalid,
+					Message: fmt.Sprintf("第%d行%d列无法处理内容", (index + 1), (celIDnex + 1)),
+				})
 				blog.Error("unknown the type, %v,   %v", reflect.TypeOf(cell), cell.Type())
 			}
 		}
@@ -191,12 +213,8 @@ func GetExcelData(sheet *xlsx.Sheet, fields, defFields common.KvMap, isCheckHead
 			hosts[index+1] = nil
 		}
 	}
-	if "" != errMsg {
-		return nil, errors.New(errMsg)
-	}
-
-	return hosts, nil
 
+	return hosts, cellErrs
 }
 
 //getFilterFields 不需要展示字段
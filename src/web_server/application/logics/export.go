@@ -0,0 +1,309 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package logics
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	webCommon "configcenter/src/web_server/common"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	simplejson "github.com/bitly/go-simplejson"
+	"github.com/gin-gonic/gin"
+)
+
+// the formats of the export
+const (
+	ExportFormatXlsx = "xlsx"
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "ndjson"
+)
+
+// the header naming of the export
+const (
+	ExportHeaderName = "name" // the property names, the same as the first header row of the excel template
+	ExportHeaderID   = "id"   // the property ids, the same as the last header row of the excel template, the default
+)
+
+// ExportPageSize the rows fetched from the api server in one request
+var ExportPageSize = 500
+
+// ExportField the exported property
+type ExportField struct {
+	ID   string
+	Name string
+}
+
+// ErrExportNoTrailer the format has no place for the error after the rows are written
+var ErrExportNoTrailer = errors.New("the export format has no error trailer")
+
+// ExportWriter write the exported rows, the rows are flushed page by page
+type ExportWriter interface {
+	WriteHeader(fields []ExportField) error
+	WriteRow(row map[string]interface{}) error
+	// WriteError end the rows with the error so that the client can tell the export is incomplete,
+	// ErrExportNoTrailer is returned if the format can not carry it
+	WriteError(err error) error
+	Flush() error
+}
+
+// NewExportWriter create the writer of the streaming format, the header names or ids
+func NewExportWriter(format, header string, w io.Writer) (ExportWriter, error) {
+	if ExportHeaderName != header && ExportHeaderID != header {
+		return nil, fmt.Errorf("不支持的表头%s", header)
+	}
+	switch format {
+	case ExportFormatCSV:
+		return &csvExportWriter{header: header, w: w, writer: csv.NewWriter(w)}, nil
+	case ExportFormatJSON:
+		return &jsonExportWriter{header: header, w: w, encoder: json.NewEncoder(w)}, nil
+	}
+	return nil, fmt.Errorf("不支持的导出格式%s", format)
+}
+
+// ExportHosts write the hosts of the business, or the given hosts if the business is -1
+func ExportHosts(appIDStr, hostIDStr, apiAddr string, header http.Header, w ExportWriter) error {
+	attrs, err := getObjectAttrs(apiAddr, common.BKDefaultOwnerID, common.BKInnerObjIDHost, header)
+	if nil != err {
+		return err
+	}
+	url := apiAddr + fmt.Sprintf("/api/%s/hosts/search", webCommon.API_VERSION)
+	cond := getHostExportCond(appIDStr, hostIDStr)
+	return exportRows(url, cond, common.BKHostIDField, header, exportFields(attrs), w, func(item map[string]interface{}) map[string]interface{} {
+		host, _ := item[common.BKInnerObjIDHost].(map[string]interface{})
+		return host
+	})
+}
+
+// ExportInsts write the given instances of the object
+func ExportInsts(ownerID, objID, instIDStr, apiAddr string, header http.Header, w ExportWriter) error {
+	attrs, err := getObjectAttrs(apiAddr, ownerID, objID, header)
+	if nil != err {
+		return err
+	}
+	url := apiAddr + fmt.Sprintf("/api/%s/inst/search/"+ownerID+"/"+objID, webCommon.API_VERSION)
+	cond := getInstExportCond(ownerID, objID, instIDStr)
+	return exportRows(url, cond, common.BKInstIDField, header, exportFields(attrs), w, func(item map[string]interface{}) map[string]interface{} {
+		return item
+	})
+}
+
+// AddDownExportHttpHeader set the download header of the exported file by its extension
+func AddDownExportHttpHeader(c *gin.Context, name string) {
+	switch {
+	case strings.HasSuffix(name, "."+ExportFormatCSV):
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+	case strings.HasSuffix(name, "."+ExportFormatJSON):
+		c.Header("Content-Type", "application/x-ndjson; charset=utf-8")
+	default:
+		AddDownExcelHttpHeader(c, name)
+		return
+	}
+	c.Header("Content-Disposition", "attachment; filename="+name)
+	c.Header("Cache-Control", "must-revalidate, post-check=0, pre-check=0")
+}
+
+// exportFields the exported properties in the order of the attributes
+func exportFields(attrs []interface{}) []ExportField {
+	fields := make([]ExportField, 0, len(attrs))
+	for _, item := range attrs {
+		attr, _ := item.(map[string]interface{})
+		field := ExportField{}
+		field.ID, _ = attr[common.BKPropertyIDField].(string)
+		field.Name, _ = attr[common.BKPropertyNameField].(string)
+		if "" != field.ID {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// exportRows search the rows page by page, by the cursor if the api server supports it,
+// and write each page once it is fetched. the header is written with the first page,
+// so nothing is written if the first search fails
+func exportRows(url string, cond map[string]interface{}, sort string, header http.Header, fields []ExportField, w ExportWriter,
+	rowOf func(item map[string]interface{}) map[string]interface{}) error {
+
+	page := map[string]interface{}{"start": 0, "limit": ExportPageSize, "sort": sort, "with_cursor": true}
+	cond["page"] = page
+	for index := 0; ; index++ {
+		result, err := httpRequest(url, cond, header)
+		if nil != err {
+			return err
+		}
+		js, err := simplejson.NewJson([]byte(result))
+		if nil != err {
+			blog.Errorf("export search %s reply %s is not json: %v", url, result, err)
+			return err
+		}
+		if ok, _ := js.Get("result").Bool(); false == ok {
+			msg, _ := js.Get("bk_error_msg").String()
+			return errors.New(msg)
+		}
+
+		data := js.Get("data")
+		info, _ := data.Get("info").Array()
+		if 0 == index {
+			if err := w.WriteHeader(fields); nil != err {
+				return err
+			}
+		}
+		for _, item := range info {
+			row, _ := item.(map[string]interface{})
+			if err := w.WriteRow(rowOf(row)); nil != err {
+				return err
+			}
+		}
+		if err := w.Flush(); nil != err {
+			return err
+		}
+
+		if next, ok := data.CheckGet("next_cursor"); ok {
+			cursor, _ := next.String()
+			if "" == cursor {
+				return nil
+			}
+			page["cursor"] = cursor
+			continue
+		}
+		if len(info) < ExportPageSize {
+			return nil
+		}
+		page["start"] = page["start"].(int) + len(info)
+	}
+}
+
+// exportValue the text of the value, the associations are joined as "id:name"
+func exportValue(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			inst, ok := item.(map[string]interface{})
+			if false == ok {
+				items = append(items, fmt.Sprintf("%v", item))
+				continue
+			}
+			id, idOk := inst["id"]
+			if false == idOk {
+				continue
+			}
+			name := inst["name"]
+			if nil == name {
+				name = ""
+			}
+			items = append(items, fmt.Sprintf("%v:%v", id, name))
+		}
+		return strings.Join(items, ",")
+	case map[string]interface{}:
+		out, _ := json.Marshal(v)
+		return string(out)
+	}
+	return fmt.Sprintf("%v", val)
+}
+
+type csvExportWriter struct {
+	header string
+	fields []ExportField
+	w      io.Writer
+	writer *csv.Writer
+}
+
+func (e *csvExportWriter) WriteHeader(fields []ExportField) error {
+	e.fields = fields
+	record := make([]string, 0, len(fields))
+	for _, field := range fields {
+		record = append(record, exportHeaderOf(field, e.header))
+	}
+	return e.writer.Write(record)
+}
+
+func (e *csvExportWriter) WriteRow(row map[string]interface{}) error {
+	record := make([]string, 0, len(e.fields))
+	for _, field := range e.fields {
+		record = append(record, exportValue(row[field.ID]))
+	}
+	return e.writer.Write(record)
+}
+
+func (e *csvExportWriter) WriteError(err error) error {
+	return ErrExportNoTrailer
+}
+
+func (e *csvExportWriter) Flush() error {
+	e.writer.Flush()
+	if err := e.writer.Error(); nil != err {
+		return err
+	}
+	flushExport(e.w)
+	return nil
+}
+
+type jsonExportWriter struct {
+	header  string
+	fields  []ExportField
+	w       io.Writer
+	encoder *json.Encoder
+}
+
+func (e *jsonExportWriter) WriteHeader(fields []ExportField) error {
+	e.fields = fields
+	return nil
+}
+
+func (e *jsonExportWriter) WriteRow(row map[string]interface{}) error {
+	line := make(map[string]interface{}, len(e.fields))
+	for _, field := range e.fields {
+		line[exportHeaderOf(field, e.header)] = row[field.ID]
+	}
+	return e.encoder.Encode(line)
+}
+
+// WriteError write the error as the last line, {"bk_error_msg": "..."}
+func (e *jsonExportWriter) WriteError(err error) error {
+	if encodeErr := e.encoder.Encode(map[string]interface{}{"bk_error_msg": err.Error()}); nil != encodeErr {
+		return encodeErr
+	}
+	return e.Flush()
+}
+
+func (e *jsonExportWriter) Flush() error {
+	flushExport(e.w)
+	return nil
+}
+
+func exportHeaderOf(field ExportField, header string) string {
+	if ExportHeaderID == header || "" == field.Name {
+		return field.ID
+	}
+	return field.Name
+}
+
+// flushExport send the written rows to the client if the writer is the response
+func flushExport(w io.Writer) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
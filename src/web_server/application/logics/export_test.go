@@ -0,0 +1,129 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package logics
+
+import (
+	"bytes"
+	"configcenter/src/common"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newExportServer serve the host attributes and the hosts by the cursor
+func newExportServer(t *testing.T, hosts int) (*httptest.Server, *[]map[string]interface{}) {
+	pages := &[]map[string]interface{}{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		cond := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(body, &cond))
+
+		switch r.URL.Path {
+		case "/api/v3/object/attr/search":
+			fmt.Fprint(w, `{"result":true,"data":[
+				{"bk_property_id":"bk_host_innerip","bk_property_name":"内网IP"},
+				{"bk_property_id":"bk_cpu","bk_property_name":"CPU"},
+				{"bk_property_id":"bk_host_name"}]}`)
+		case "/api/v3/hosts/search":
+			page := cond["page"].(map[string]interface{})
+			*pages = append(*pages, page)
+			start := 0
+			if cursor, ok := page["cursor"].(string); ok {
+				fmt.Sscanf(cursor, "%d", &start)
+			}
+			limit := int(page["limit"].(float64))
+			info := []interface{}{}
+			for i := start; i < hosts && i < start+limit; i++ {
+				info = append(info, map[string]interface{}{
+					"host": map[string]interface{}{"bk_host_innerip": fmt.Sprintf("127.0.0.%d", i+1), "bk_cpu": i, "bk_host_name": nil},
+				})
+			}
+			next := ""
+			if start+limit < hosts {
+				next = fmt.Sprintf("%d", start+limit)
+			}
+			out, _ := json.Marshal(map[string]interface{}{"result": true, "data": map[string]interface{}{"info": info, "next_cursor": next}})
+			w.Write(out)
+		default:
+			fmt.Fprint(w, `{"result":false,"bk_error_msg":"not found"}`)
+		}
+	}))
+	return srv, pages
+}
+
+func TestExportHostsCSV(t *testing.T) {
+	pageSize := ExportPageSize
+	ExportPageSize = 2
+	defer func() { ExportPageSize = pageSize }()
+
+	srv, pages := newExportServer(t, 5)
+	defer srv.Close()
+
+	buf := &bytes.Buffer{}
+	w, err := NewExportWriter(ExportFormatCSV, ExportHeaderName, buf)
+	require.NoError(t, err)
+	require.NoError(t, ExportHosts("1", "", srv.URL, http.Header{}, w))
+
+	require.Equal(t, "内网IP,CPU,bk_host_name\n"+
+		"127.0.0.1,0,\n127.0.0.2,1,\n127.0.0.3,2,\n127.0.0.4,3,\n127.0.0.5,4,\n", buf.String())
+	require.Equal(t, 3, len(*pages))
+	require.Equal(t, common.BKHostIDField, (*pages)[0]["sort"])
+	require.Equal(t, true, (*pages)[0]["with_cursor"])
+	require.Equal(t, "4", (*pages)[2]["cursor"])
+}
+
+func TestExportHostsJSON(t *testing.T) {
+	srv, _ := newExportServer(t, 2)
+	defer srv.Close()
+
+	buf := &bytes.Buffer{}
+	w, err := NewExportWriter(ExportFormatJSON, ExportHeaderID, buf)
+	require.NoError(t, err)
+	require.NoError(t, ExportHosts("-1", "1,2", srv.URL, http.Header{}, w))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Equal(t, 2, len(lines))
+	require.Equal(t, `{"bk_cpu":1,"bk_host_innerip":"127.0.0.2","bk_host_name":null}`, lines[1])
+}
+
+func TestExportFailed(t *testing.T) {
+	srv, _ := newExportServer(t, 2)
+	defer srv.Close()
+
+	buf := &bytes.Buffer{}
+	w, err := NewExportWriter(ExportFormatCSV, ExportHeaderID, buf)
+	require.NoError(t, err)
+	require.Error(t, ExportInsts("0", "switch", "1", srv.URL, http.Header{}, w))
+	require.Equal(t, 0, buf.Len())
+
+	_, err = NewExportWriter("xml", ExportHeaderID, buf)
+	require.Error(t, err)
+	_, err = NewExportWriter(ExportFormatCSV, "alias", buf)
+	require.Error(t, err)
+}
+
+func TestExportValue(t *testing.T) {
+	require.Equal(t, "", exportValue(nil))
+	require.Equal(t, "8", exportValue(json.Number("8")))
+	require.Equal(t, "true", exportValue(true))
+	require.Equal(t, "1:a,2:", exportValue([]interface{}{
+		map[string]interface{}{"id": 1, "name": "a"},
+		map[string]interface{}{"id": 2},
+	}))
+}
@@ -32,38 +32,7 @@ import (
 //GetHostData get host data from excel
 func GetHostData(appIDStr, hostIDStr, apiAddr string, header http.Header, kvMap map[string]string) ([]interface{}, error) {
 	hostInfo := make([]interface{}, 0)
-	sHostCond := make(map[string]interface{})
-	appID, _ := strconv.Atoi(appIDStr)
-	hostIDArr := strings.Split(hostIDStr, ",")
-	iHostIDArr := make([]int, 0)
-	for _, j := range hostIDArr {
-		hostID, _ := strconv.Atoi(j)
-		iHostIDArr = append(iHostIDArr, hostID)
-	}
-	if -1 != appID {
-		sHostCond[common.BKAppIDField] = appID
-		sHostCond["ip"] = make(map[string]interface{})
-		sHostCond["condition"] = make([]interface{}, 0)
-		sHostCond["page"] = make(map[string]interface{})
-	} else {
-		sHostCond[common.BKAppIDField] = -1
-		sHostCond["ip"] = make(map[string]interface{})
-		condArr := make([]interface{}, 0)
-		condition := make(map[string]interface{})
-		hostCondArr := make([]interface{}, 0)
-		hostCond := make(map[string]interface{})
-		hostCond["field"] = common.BKHostIDField
-		hostCond["operator"] = "$in"
-		hostCond["value"] = iHostIDArr
-		hostCondArr = append(hostCondArr, hostCond)
-		condition[common.BKObjIDField] = "host"
-		condition["fields"] = make([]string, 0)
-		condition["condition"] = hostCondArr
-		condArr = append(condArr, condition)
-		sHostCond["condition"] = condArr
-		sHostCond["page"] = make(map[string]interface{})
-
-	}
+	sHostCond := getHostExportCond(appIDStr, hostIDStr)
 	url := apiAddr + fmt.Sprintf("/api/%s/hosts/search", webCommon.API_VERSION)
 	result, _ := httpRequest(url, sHostCond, header)
 	blog.Info("search host  url:%s", url)
@@ -105,6 +74,43 @@ func GetHostData(appIDStr, hostIDStr, apiAddr string, header http.Header, kvMap
 	return hostInfo, nil
 }
 
+// getHostExportCond the search condition of the exported hosts, the hosts of the business or the given hosts if the business is -1
+func getHostExportCond(appIDStr, hostIDStr string) map[string]interface{} {
+	sHostCond := make(map[string]interface{})
+	appID, _ := strconv.Atoi(appIDStr)
+	hostIDArr := strings.Split(hostIDStr, ",")
+	iHostIDArr := make([]int, 0)
+	for _, j := range hostIDArr {
+		hostID, _ := strconv.Atoi(j)
+		iHostIDArr = append(iHostIDArr, hostID)
+	}
+	if -1 != appID {
+		sHostCond[common.BKAppIDField] = appID
+		sHostCond["ip"] = make(map[string]interface{})
+		sHostCond["condition"] = make([]interface{}, 0)
+		sHostCond["page"] = make(map[string]interface{})
+	} else {
+		sHostCond[common.BKAppIDField] = -1
+		sHostCond["ip"] = make(map[string]interface{})
+		condArr := make([]interface{}, 0)
+		condition := make(map[string]interface{})
+		hostCondArr := make([]interface{}, 0)
+		hostCond := make(map[string]interface{})
+		hostCond["field"] = common.BKHostIDField
+		hostCond["operator"] = "$in"
+		hostCond["value"] = iHostIDArr
+		hostCondArr = append(hostCondArr, hostCond)
+		condition[common.BKObjIDField] = "host"
+		condition["fields"] = make([]string, 0)
+		condition["condition"] = hostCondArr
+		condArr = append(condArr, condition)
+		sHostCond["condition"] = condArr
+		sHostCond["page"] = make(map[string]interface{})
+
+	}
+	return sHostCond
+}
+
 //GetImportHosts get import hosts
 func GetImportHosts(f *xlsx.File, url string, header http.Header) (map[int]map[string]interface{}, error) {
 
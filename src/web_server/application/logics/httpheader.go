@@ -28,3 +28,11 @@ func SetProxyHeader(c *gin.Context) {
 	c.Request.Header.Add(common.BKHTTPLanguage, language)
 	c.Request.Header.Add(common.BKHTTPOwnerID, ownerID)
 }
+
+// GetSessionUser get the owner and the user of the login session, which the client can not forge by the header
+func GetSessionUser(c *gin.Context) (string, string) {
+	session := sessions.Default(c)
+	userName, _ := session.Get("userName").(string)
+	ownerID, _ := session.Get("owner_uin").(string)
+	return ownerID, userName
+}
@@ -0,0 +1,302 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package logics
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	webCommon "configcenter/src/web_server/common"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	simplejson "github.com/bitly/go-simplejson"
+)
+
+var importRowRegexp = regexp.MustCompile(`\d+`)
+
+// NewImportTask create the task of the kind by the params of the task, such as the task of the job resumed by another web server
+func NewImportTask(apiSite, kind string, params map[string]string, header http.Header) (ImportTask, error) {
+	switch kind {
+	case ImportKindHost:
+		return NewHostImportTask(apiSite, header), nil
+	case ImportKindInst:
+		return NewInstImportTask(apiSite, params[common.BKOwnerIDField], params[common.BKObjIDField], header), nil
+	case ImportKindObject:
+		return NewObjectImportTask(apiSite, params[common.BKObjIDField]), nil
+	}
+	return ImportTask{}, fmt.Errorf("unknown import kind %s", kind)
+}
+
+// NewHostImportTask the task which imports the hosts to the resource pool
+func NewHostImportTask(apiSite string, header http.Header) ImportTask {
+	task := ImportTask{
+		Kind:     ImportKindHost,
+		Defaults: common.KvMap{"import_from": common.HostAddMethodExcel},
+		Submit: func(rows map[int]map[string]interface{}, header http.Header) ([]ImportRowError, error) {
+			url := apiSite + fmt.Sprintf("/api/%s/hosts/add", webCommon.API_VERSION)
+			params := map[string]interface{}{
+				"host_info":      rows,
+				"bk_supplier_id": common.BKDefaultSupplierID,
+			}
+			reply, err := httpRequest(url, params, header)
+			if nil != err {
+				return nil, err
+			}
+			return parseImportReply(reply, rows, "", "error", "update_error")
+		},
+	}
+
+	attrs, err := getObjectAttrs(apiSite, common.BKDefaultOwnerID, common.BKInnerObjIDHost, header)
+	if nil != err {
+		blog.Warnf("get the attributes of the host error: %v, the rows are imported without validation", err)
+		return task
+	}
+	task.Types = attrTypes(attrs)
+	task.IDs = attrIDs(attrs)
+	task.Validate = newAttrValidator(attrs, true)
+	return task
+}
+
+// NewInstImportTask the task which imports the instances of the object
+func NewInstImportTask(apiSite, ownerID, objID string, header http.Header) ImportTask {
+	task := ImportTask{
+		Kind:     ImportKindInst,
+		Params:   map[string]string{common.BKOwnerIDField: ownerID, common.BKObjIDField: objID},
+		Defaults: common.KvMap{"import_from": common.HostAddMethodExcel},
+		Submit: func(rows map[int]map[string]interface{}, header http.Header) ([]ImportRowError, error) {
+			url := apiSite + "/api/" + webCommon.API_VERSION + "/inst/" + ownerID + "/" + objID
+			reply, err := httpRequest(url, map[string]interface{}{"BatchInfo": rows}, header)
+			if nil != err {
+				return nil, err
+			}
+			return parseImportReply(reply, rows, "", "error", "update_error")
+		},
+	}
+
+	attrs, err := getObjectAttrs(apiSite, ownerID, objID, header)
+	if nil != err {
+		blog.Warnf("get the attributes of the object %s error: %v, the rows are imported without validation", objID, err)
+		return task
+	}
+	// the existing instances are updated by the rows, so the required attributes may be absent
+	task.Types = attrTypes(attrs)
+	task.IDs = attrIDs(attrs)
+	task.Validate = newAttrValidator(attrs, false)
+	return task
+}
+
+// NewObjectImportTask the task which imports the attributes of the object
+func NewObjectImportTask(apiSite, objID string) ImportTask {
+	required := []string{common.BKPropertyIDField, common.BKPropertyNameField, common.BKPropertyTypeField}
+	return ImportTask{
+		Kind:     ImportKindObject,
+		Params:   map[string]string{common.BKObjIDField: objID},
+		FirstRow: 3,
+		Defaults: common.KvMap{"import_from": common.HostAddMethodExcel},
+		Submit: func(rows map[int]map[string]interface{}, header http.Header) ([]ImportRowError, error) {
+			url := fmt.Sprintf("%s/api/%s/object/batch", apiSite, webCommon.API_VERSION)
+			params := map[string]interface{}{
+				objID: map[string]interface{}{
+					"meta": nil,
+					"attr": rows,
+				},
+			}
+			reply, err := httpRequest(url, params, header)
+			if nil != err {
+				return nil, err
+			}
+			return parseImportReply(reply, rows, objID, "insert_failed", "update_failed")
+		},
+		Validate: func(row int, data map[string]interface{}) []ImportRowError {
+			var errs []ImportRowError
+			for _, field := range required {
+				if val, ok := data[field]; false == ok || "" == val {
+					errs = append(errs, ImportRowError{Row: row, Field: field, Code: common.CCErrCommParamsNeedSet, Message: fmt.Sprintf("%s必填", field)})
+				}
+			}
+			return errs
+		},
+	}
+}
+
+// parseImportReply read the failed rows from the message lists of the reply data,
+// the first number of the message is the row number, such as "3行内网ip为空" or "Line:3 Error:..."
+func parseImportReply(reply string, rows map[int]map[string]interface{}, dataKey string, listKeys ...string) ([]ImportRowError, error) {
+	rsp := struct {
+		Result  bool        `json:"result"`
+		Code    int         `json:"bk_error_code"`
+		Message interface{} `json:"bk_error_msg"`
+		Data    interface{} `json:"data"`
+	}{}
+	if err := json.Unmarshal([]byte(reply), &rsp); nil != err {
+		blog.Errorf("import reply %s is not json: %v", reply, err)
+		return nil, err
+	}
+
+	data, _ := rsp.Data.(map[string]interface{})
+	if "" != dataKey {
+		data, _ = data[dataKey].(map[string]interface{})
+	}
+	code := common.CCErrCommImportFailed
+	if false == rsp.Result && 0 != rsp.Code {
+		code = rsp.Code
+	}
+
+	var errs []ImportRowError
+	for _, key := range listKeys {
+		msgs, _ := data[key].([]interface{})
+		for _, msg := range msgs {
+			str := fmt.Sprintf("%v", msg)
+			row, _ := strconv.Atoi(importRowRegexp.FindString(str))
+			if _, ok := rows[row]; false == ok {
+				blog.Warnf("the row of the import message %s is unknown", str)
+				continue
+			}
+			errs = append(errs, ImportRowError{Row: row, Code: code, Message: str})
+		}
+	}
+	if 0 != len(errs) {
+		return errs, nil
+	}
+
+	// the whole batch is rejected
+	message, failed := data["errors"].(string)
+	if false == failed && false == rsp.Result {
+		message, failed = fmt.Sprintf("%v", rsp.Message), true
+	}
+	if failed {
+		for row := range rows {
+			errs = append(errs, ImportRowError{Row: row, Code: code, Message: message})
+		}
+	}
+	return errs, nil
+}
+
+// getObjectAttrs get the attributes of the object
+func getObjectAttrs(apiSite, ownerID, objID string, header http.Header) ([]interface{}, error) {
+	url := fmt.Sprintf("%s/api/%s/object/attr/search", apiSite, webCommon.API_VERSION)
+	result, err := httpRequest(url, common.KvMap{common.BKObjIDField: objID, common.BKOwnerIDField: ownerID}, header)
+	if nil != err {
+		return nil, err
+	}
+	js, err := simplejson.NewJson([]byte(result))
+	if nil != err {
+		return nil, err
+	}
+	if ok, _ := js.Get("result").Bool(); false == ok {
+		msg, _ := js.Get("bk_error_msg").String()
+		return nil, fmt.Errorf("search the attributes failed: %s", msg)
+	}
+	return js.Get("data").Array()
+}
+
+// attrTypes get the property types of the attributes by the property id
+func attrTypes(attrs []interface{}) map[string]string {
+	types := make(map[string]string)
+	for _, item := range attrs {
+		attr, _ := item.(map[string]interface{})
+		id, _ := attr[common.BKPropertyIDField].(string)
+		types[id], _ = attr[common.BKPropertyTypeField].(string)
+	}
+	return types
+}
+
+// attrIDs the property ids by the property names
+func attrIDs(attrs []interface{}) map[string]string {
+	ids := make(map[string]string)
+	for _, item := range attrs {
+		attr, _ := item.(map[string]interface{})
+		id, _ := attr[common.BKPropertyIDField].(string)
+		name, _ := attr[common.BKPropertyNameField].(string)
+		if "" != id && "" != name {
+			ids[name] = id
+		}
+	}
+	return ids
+}
+
+// newAttrValidator check the values of the row by the attribute types, and the required attributes if checkRequired
+func newAttrValidator(attrs []interface{}, checkRequired bool) ImportValidator {
+	type attrRule struct {
+		id, name, typ string
+		required      bool
+	}
+	var rules []attrRule
+	for _, item := range attrs {
+		attr, ok := item.(map[string]interface{})
+		if false == ok {
+			continue
+		}
+		rule := attrRule{}
+		rule.id, _ = attr[common.BKPropertyIDField].(string)
+		rule.name, _ = attr[common.BKPropertyNameField].(string)
+		rule.typ, _ = attr[common.BKPropertyTypeField].(string)
+		rule.required, _ = attr[common.BKIsRequiredField].(bool)
+		if "" == rule.name {
+			rule.name = rule.id
+		}
+		if "" != rule.id {
+			rules = append(rules, rule)
+		}
+	}
+
+	return func(row int, data map[string]interface{}) []ImportRowError {
+		var errs []ImportRowError
+		for _, rule := range rules {
+			val, ok := data[rule.id]
+			if false == ok || "" == val {
+				if checkRequired && rule.required {
+					errs = append(errs, ImportRowError{Row: row, Field: rule.id, Code: common.CCErrCommParamsNeedSet, Message: fmt.Sprintf("%s必填", rule.name)})
+				}
+				continue
+			}
+
+			switch rule.typ {
+			case common.FiledTypeInt:
+				if false == isImportInt(val) {
+					errs = append(errs, ImportRowError{Row: row, Field: rule.id, Code: common.CCErrCommParamsNeedInt, Message: fmt.Sprintf("%s需要为数字", rule.name)})
+				}
+			case common.FiledTypeBool:
+				if false == isImportBool(val) {
+					errs = append(errs, ImportRowError{Row: row, Field: rule.id, Code: common.CCErrCommParamsNeedBool, Message: fmt.Sprintf("%s需要为布尔值", rule.name)})
+				}
+			}
+		}
+		return errs
+	}
+}
+
+func isImportInt(val interface{}) bool {
+	switch v := val.(type) {
+	case int, int64:
+		return true
+	case string:
+		_, err := strconv.Atoi(v)
+		return nil == err
+	}
+	return false
+}
+
+func isImportBool(val interface{}) bool {
+	switch v := val.(type) {
+	case bool:
+		return true
+	case string:
+		_, err := strconv.ParseBool(v)
+		return nil == err
+	}
+	return false
+}
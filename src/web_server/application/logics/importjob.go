@@ -0,0 +1,690 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package logics
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/lifecycle"
+	"configcenter/src/storage"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/tealeg/xlsx"
+)
+
+// the kinds of the import job
+const (
+	ImportKindHost   = "host"
+	ImportKindInst   = "inst"
+	ImportKindObject = "object"
+)
+
+// the status of the import job
+const (
+	ImportStatusRunning  = "running"
+	ImportStatusFinished = "finished"
+	ImportStatusFailed   = "failed"
+)
+
+// importReportColumn the header of the column added to the report
+const importReportColumn = "导入结果"
+
+const (
+	// importJobKeyPrefix the job is kept in the cache as json by the key with the job id
+	importJobKeyPrefix = "cc_import_job:"
+	// importJobLockPrefix prevent the job from being resumed by two web servers at the same time
+	importJobLockPrefix = "cc_import_job_lock:"
+)
+
+var (
+	// ImportBatchSize the rows submitted to the api server in one request
+	ImportBatchSize = 100
+	// ImportJobExpire how long the ended job and its file are kept
+	ImportJobExpire = 24 * time.Hour
+	// ImportSyncRows the import of the file with more rows is replied at once, the progress is queried by the job
+	ImportSyncRows = 1000
+	// importJobStale the running job which is not updated for the duration is interrupted, such as its web server restarted
+	importJobStale = 10 * time.Minute
+)
+
+// ImportJobCache keep the import jobs, so the jobs are shared by the web servers and kept across the restarts
+var ImportJobCache storage.Cache
+
+// ImportRowError the reason of the failed row
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ImportSubmitter submit the rows keyed by the excel row number, return the errors of the failed rows
+type ImportSubmitter func(rows map[int]map[string]interface{}, header http.Header) ([]ImportRowError, error)
+
+// ImportValidator check the row before it is submitted
+type ImportValidator func(row int, data map[string]interface{}) []ImportRowError
+
+// ImportTask describe how the rows of the file are imported
+type ImportTask struct {
+	Kind     string
+	Params   map[string]string // the params to create the task again by NewImportTask
+	FirstRow int               // the index of the first data row, 0 means the row after the default header
+	Defaults common.KvMap
+	Types    map[string]string // the property types by the property id, the csv values are converted by them
+	IDs      map[string]string // the property ids by the property name, the csv exported with the name header is read by them
+	Submit   ImportSubmitter
+	Validate ImportValidator
+}
+
+// ImportJob the background import of an excel file
+type ImportJob struct {
+	ID         string           `json:"job_id"`
+	OwnerID    string           `json:"bk_supplier_account"`
+	User       string           `json:"user"`
+	Kind       string           `json:"kind"`
+	Format     string           `json:"format"`
+	Status     string           `json:"status"`
+	Total      int              `json:"total"`
+	Processed  int              `json:"processed"`
+	Succeeded  int              `json:"succeeded"`
+	Failed     int              `json:"failed"`
+	Message    string           `json:"message,omitempty"`
+	Errors     []ImportRowError `json:"errors"`
+	CreateTime time.Time        `json:"create_time"`
+	LastTime   time.Time        `json:"last_time"`
+
+	task     ImportTask
+	params   map[string]string
+	filePath string
+	header   http.Header
+	rows     map[int]map[string]interface{}
+	success  map[int]bool
+	done     chan struct{}
+}
+
+// importJobRecord the job kept in the cache with the state to resume it
+type importJobRecord struct {
+	*ImportJob
+	Params   map[string]string `json:"params"`
+	FilePath string            `json:"file_path"`
+	Success  []int             `json:"success"`
+}
+
+// importJobs the jobs run by this web server, the task of the job is reused when it is resumed
+var importJobs = struct {
+	sync.RWMutex
+	jobs map[string]*ImportJob
+}{jobs: make(map[string]*ImportJob)}
+
+var (
+	importWorkerOnce sync.Once
+	importWorker     *lifecycle.Worker
+)
+
+// SubmitImportJob import the rows of the file in the background for the user of the owner,
+// the job owns the file once it is submitted
+func SubmitImportJob(task ImportTask, filePath, ownerID, user string, header http.Header) (*ImportJob, error) {
+	if nil == ImportJobCache {
+		return nil, errors.New("导入任务存储不可用")
+	}
+	rows, cellErrs, err := readImportRows(filePath, task)
+	if nil != err {
+		return nil, err
+	}
+	rowNums := importRowNums(rows, nil)
+	if 0 == len(rowNums) {
+		return nil, errors.New("文件内容不能为空")
+	}
+
+	purgeImportJobs(filepath.Dir(filePath))
+
+	now := time.Now()
+	job := &ImportJob{
+		ID:         xid.New().String(),
+		OwnerID:    ownerID,
+		User:       user,
+		Kind:       task.Kind,
+		Format:     importFileFormat(filePath),
+		Status:     ImportStatusRunning,
+		Total:      len(rowNums),
+		Errors:     []ImportRowError{},
+		CreateTime: now,
+		LastTime:   now,
+		task:       task,
+		params:     task.Params,
+		filePath:   filePath,
+		header:     cloneHeader(header),
+		rows:       rows,
+		success:    make(map[int]bool),
+		done:       make(chan struct{}),
+	}
+
+	importJobs.Lock()
+	defer importJobs.Unlock()
+	if err := saveImportJob(job); nil != err {
+		return nil, fmt.Errorf("保存导入任务失败, %v", err)
+	}
+	importJobs.jobs[job.ID] = job
+
+	blog.Infof("import job %s of %s submitted by %s, %d rows", job.ID, job.Kind, user, job.Total)
+	go job.run(rowNums, cellErrs, job.done)
+	return job.snapshot(), nil
+}
+
+// GetImportJob get the progress of the job submitted by the user of the owner, nil if not found
+func GetImportJob(id, ownerID, user string) *ImportJob {
+	importJobs.RLock()
+	defer importJobs.RUnlock()
+	job, err := loadImportJob(id)
+	if nil != err {
+		blog.Errorf("load the import job %s error: %v", id, err)
+		return nil
+	}
+	if nil == job || job.OwnerID != ownerID || job.User != user {
+		return nil
+	}
+	return job
+}
+
+// WaitImportJob wait the job run by this web server ends and get its result, nil if not found
+func WaitImportJob(id string) *ImportJob {
+	importJobs.RLock()
+	job, ok := importJobs.jobs[id]
+	if false == ok {
+		importJobs.RUnlock()
+		return nil
+	}
+	done := job.done
+	importJobs.RUnlock()
+
+	<-done
+	importJobs.RLock()
+	defer importJobs.RUnlock()
+	job, err := loadImportJob(id)
+	if nil != err {
+		blog.Errorf("load the import job %s error: %v", id, err)
+		return nil
+	}
+	return job
+}
+
+// ResumeImportJob import the rows of the job which are not imported successfully again,
+// the rows are read from the file instead if it is given, such as the corrected report, and the job owns the file then.
+// The task is created by the api site if the job is not run by this web server.
+func ResumeImportJob(id, ownerID, user, filePath, apiSite string, header http.Header) (*ImportJob, error) {
+	job := GetImportJob(id, ownerID, user)
+	if nil == job {
+		return nil, fmt.Errorf("导入任务%s不存在", id)
+	}
+
+	token := xid.New().String()
+	locked, err := ImportJobCache.Lock(importJobLockPrefix+id, token, time.Minute)
+	if nil != err {
+		return nil, err
+	}
+	if false == locked {
+		return nil, fmt.Errorf("导入任务%s正在执行", id)
+	}
+	defer ImportJobCache.Unlock(importJobLockPrefix+id, token)
+
+	importJobs.Lock()
+	defer importJobs.Unlock()
+
+	// load the job again in the lock, it may be resumed just now
+	job, err = loadImportJob(id)
+	if nil != err {
+		return nil, err
+	}
+	if nil == job {
+		return nil, fmt.Errorf("导入任务%s不存在", id)
+	}
+	if ImportStatusRunning == job.Status {
+		return nil, fmt.Errorf("导入任务%s正在执行", id)
+	}
+	if local, ok := importJobs.jobs[id]; ok {
+		job.task = local.task
+		job.header = local.header
+	} else {
+		if job.task, err = NewImportTask(apiSite, job.Kind, job.params, header); nil != err {
+			return nil, err
+		}
+	}
+
+	readPath := job.filePath
+	if "" != filePath {
+		if job.Format != importFileFormat(filePath) {
+			return nil, fmt.Errorf("导入任务%s的文件格式为%s", id, job.Format)
+		}
+		readPath = filePath
+	} else if _, err := os.Stat(readPath); nil != err {
+		return nil, fmt.Errorf("导入任务%s的文件不在当前服务器，请上传导入报告继续导入", id)
+	}
+	rows, errs, err := readImportRows(readPath, job.task)
+	if nil != err {
+		return nil, err
+	}
+
+	// the failed rows and the rows not imported of the interrupted job
+	rowNums := make([]int, 0)
+	for _, row := range importRowNums(rows, nil) {
+		if false == job.success[row] {
+			rowNums = append(rowNums, row)
+		}
+	}
+	if 0 == len(rowNums) {
+		return nil, fmt.Errorf("导入任务%s没有失败的行", id)
+	}
+	pending := make(map[int]bool)
+	for _, row := range rowNums {
+		pending[row] = true
+	}
+	var cellErrs []ImportRowError
+	for _, cellErr := range errs {
+		if pending[cellErr.Row] {
+			cellErrs = append(cellErrs, cellErr)
+		}
+	}
+
+	if "" != filePath {
+		if err := os.Remove(job.filePath); nil != err {
+			blog.Warnf("remove the file of the import job %s error: %v", id, err)
+		}
+		job.filePath = filePath
+	}
+	job.rows = rows
+	job.Errors = []ImportRowError{}
+	job.Total = job.Succeeded + len(rowNums)
+	job.Processed = job.Succeeded
+	job.Failed = 0
+	job.Status = ImportStatusRunning
+	job.Message = ""
+	job.LastTime = time.Now()
+	if nil != header {
+		job.header = cloneHeader(header)
+	}
+	job.done = make(chan struct{})
+	if err := saveImportJob(job); nil != err {
+		return nil, fmt.Errorf("保存导入任务失败, %v", err)
+	}
+	importJobs.jobs[id] = job
+
+	blog.Infof("import job %s resumed by %s, %d rows", id, user, len(rowNums))
+	go job.run(rowNums, cellErrs, job.done)
+	return job.snapshot(), nil
+}
+
+// WriteImportReport write the file of the job submitted by the user of the owner,
+// the failed rows are annotated with the reasons in a new column
+func WriteImportReport(id, ownerID, user string, w io.Writer) error {
+	job := GetImportJob(id, ownerID, user)
+	if nil == job {
+		return fmt.Errorf("导入任务%s不存在", id)
+	}
+	filePath := job.filePath
+	if _, err := os.Stat(filePath); nil != err {
+		return fmt.Errorf("导入任务%s的文件不在当前服务器", id)
+	}
+	reasons := make(map[int][]string)
+	for _, rowErr := range job.Errors {
+		reasons[rowErr.Row] = append(reasons[rowErr.Row], rowErr.String())
+	}
+	format := job.Format
+
+	if ImportFormatCSV == format {
+		return writeCSVReport(filePath, reasons, w)
+	}
+
+	f, err := xlsx.OpenFile(filePath)
+	if nil != err {
+		return err
+	}
+	if 0 == len(f.Sheets) || headerRow > len(f.Sheets[0].Rows) {
+		return errors.New("文件内容不能为空")
+	}
+	sheet := f.Sheets[0]
+
+	// reuse the column if the file is a report already
+	col := -1
+	for i, cell := range sheet.Rows[headerRow-1].Cells {
+		if importReportColumn == cell.Value {
+			col = i
+		}
+	}
+	if -1 == col {
+		for _, row := range sheet.Rows {
+			if col < len(row.Cells) {
+				col = len(row.Cells)
+			}
+		}
+	}
+	for _, row := range sheet.Rows[headerRow:] {
+		if col < len(row.Cells) {
+			row.Cells[col] = xlsx.NewCell(row)
+		}
+	}
+
+	// the column is named in all the header rows, so it is known as the report column when the file is imported again
+	for _, row := range sheet.Rows[:headerRow] {
+		reportCell(row, col).Value = importReportColumn
+	}
+	style := xlsx.NewStyle()
+	style.Fill = *xlsx.NewFill("solid", "FFFFC7CE", "FFFFC7CE")
+	style.ApplyFill = true
+	for row, msgs := range reasons {
+		if row <= headerRow || row > len(sheet.Rows) {
+			continue
+		}
+		cell := reportCell(sheet.Rows[row-1], col)
+		cell.Value = strings.Join(msgs, "; ")
+		cell.SetStyle(style)
+	}
+	return f.Write(w)
+}
+
+// String the reason shown in the report
+func (e ImportRowError) String() string {
+	if "" == e.Field {
+		return fmt.Sprintf("[%d]%s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("[%d]%s: %s", e.Code, e.Field, e.Message)
+}
+
+// run import the rows by batch and record the results
+func (j *ImportJob) run(rowNums []int, cellErrs []ImportRowError, done chan struct{}) {
+	defer close(done)
+
+	importWorkerOnce.Do(func() {
+		importWorker = lifecycle.NewWorker("import job")
+	})
+
+	unreadable := make(map[int]bool)
+	for _, cellErr := range cellErrs {
+		unreadable[cellErr.Row] = true
+	}
+
+	for start := 0; start < len(rowNums); start += ImportBatchSize {
+		end := start + ImportBatchSize
+		if end > len(rowNums) {
+			end = len(rowNums)
+		}
+		if false == importWorker.Begin() {
+			j.end(ImportStatusFailed, "服务正在停止，请稍后继续导入失败的行")
+			return
+		}
+		j.importBatch(rowNums[start:end], cellErrs, unreadable)
+		importWorker.End()
+	}
+	j.end(ImportStatusFinished, "")
+}
+
+// importBatch validate and submit the rows, the unreadable rows are not submitted
+func (j *ImportJob) importBatch(batch []int, cellErrs []ImportRowError, unreadable map[int]bool) {
+	var rowErrs []ImportRowError
+	data := make(map[int]map[string]interface{})
+	for _, row := range batch {
+		if unreadable[row] {
+			for _, cellErr := range cellErrs {
+				if row == cellErr.Row {
+					rowErrs = append(rowErrs, cellErr)
+				}
+			}
+			continue
+		}
+		if nil == j.rows[row] {
+			rowErrs = append(rowErrs, ImportRowError{Row: row, Code: common.CCErrCommParamsNeedSet, Message: fmt.Sprintf("第%d行内容为空", row)})
+			continue
+		}
+		if nil != j.task.Validate {
+			if errs := j.task.Validate(row, j.rows[row]); 0 != len(errs) {
+				rowErrs = append(rowErrs, errs...)
+				continue
+			}
+		}
+		data[row] = j.rows[row]
+	}
+
+	if 0 != len(data) {
+		errs, err := j.task.Submit(data, j.header)
+		if nil != err {
+			blog.Errorf("import job %s submit %d rows error: %v", j.ID, len(data), err)
+			for row := range data {
+				rowErrs = append(rowErrs, ImportRowError{Row: row, Code: common.CCErrCommHTTPDoRequestFailed, Message: err.Error()})
+			}
+		}
+		for _, rowErr := range errs {
+			if _, ok := data[rowErr.Row]; ok {
+				rowErrs = append(rowErrs, rowErr)
+			}
+		}
+	}
+
+	j.record(batch, rowErrs)
+}
+
+// record the results of the batch
+func (j *ImportJob) record(batch []int, rowErrs []ImportRowError) {
+	importJobs.Lock()
+	defer importJobs.Unlock()
+
+	failed := make(map[int]bool)
+	for _, rowErr := range rowErrs {
+		failed[rowErr.Row] = true
+	}
+	for _, row := range batch {
+		j.Processed++
+		if failed[row] {
+			j.Failed++
+			delete(j.success, row)
+			continue
+		}
+		j.Succeeded++
+		j.success[row] = true
+	}
+	j.Errors = append(j.Errors, rowErrs...)
+	sort.SliceStable(j.Errors, func(a, b int) bool { return j.Errors[a].Row < j.Errors[b].Row })
+	j.LastTime = time.Now()
+	if err := saveImportJob(j); nil != err {
+		blog.Errorf("save the progress of the import job %s error: %v", j.ID, err)
+	}
+}
+
+func (j *ImportJob) end(status, message string) {
+	importJobs.Lock()
+	defer importJobs.Unlock()
+	j.Status = status
+	j.Message = message
+	j.LastTime = time.Now()
+	// the rows are read from the file again if the job is resumed
+	j.rows = nil
+	if err := saveImportJob(j); nil != err {
+		blog.Errorf("save the import job %s error: %v", j.ID, err)
+	}
+	blog.Infof("import job %s %s, succeeded %d, failed %d", j.ID, status, j.Succeeded, j.Failed)
+}
+
+// SucceededRows the row numbers imported successfully
+func (j *ImportJob) SucceededRows() []int {
+	return importRowNums(nil, j.success)
+}
+
+// snapshot copy the progress, it must be called with the lock held
+func (j *ImportJob) snapshot() *ImportJob {
+	s := &ImportJob{
+		ID:         j.ID,
+		OwnerID:    j.OwnerID,
+		User:       j.User,
+		Kind:       j.Kind,
+		Format:     j.Format,
+		Status:     j.Status,
+		Total:      j.Total,
+		Processed:  j.Processed,
+		Succeeded:  j.Succeeded,
+		Failed:     j.Failed,
+		Message:    j.Message,
+		Errors:     append([]ImportRowError{}, j.Errors...),
+		CreateTime: j.CreateTime,
+		LastTime:   j.LastTime,
+		success:    make(map[int]bool, len(j.success)),
+	}
+	for row := range j.success {
+		s.success[row] = true
+	}
+	return s
+}
+
+// saveImportJob keep the job in the cache until it is not updated for the expire time,
+// it must be called with the lock held
+func saveImportJob(j *ImportJob) error {
+	value, err := json.Marshal(importJobRecord{ImportJob: j, Params: j.params, FilePath: j.filePath, Success: j.SucceededRows()})
+	if nil != err {
+		return err
+	}
+	// the file is kept as long as the job, see purgeImportJobs
+	now := time.Now()
+	if err := os.Chtimes(j.filePath, now, now); nil != err {
+		blog.Warnf("touch the file of the import job %s error: %v", j.ID, err)
+	}
+	return ImportJobCache.Set(importJobKeyPrefix+j.ID, string(value), ImportJobExpire)
+}
+
+// loadImportJob get the job from the cache, nil if not found or expired, it must be called with the lock held
+func loadImportJob(id string) (*ImportJob, error) {
+	value, err := ImportJobCache.Get(importJobKeyPrefix + id)
+	if storage.ErrCacheNil == err {
+		return nil, nil
+	}
+	if nil != err {
+		return nil, err
+	}
+	record := importJobRecord{}
+	if err := json.Unmarshal([]byte(value), &record); nil != err {
+		return nil, err
+	}
+	job := record.ImportJob
+	if nil == job {
+		return nil, fmt.Errorf("导入任务%s的内容为空", id)
+	}
+	job.params = record.Params
+	job.filePath = record.FilePath
+	job.success = make(map[int]bool, len(record.Success))
+	for _, row := range record.Success {
+		job.success[row] = true
+	}
+
+	// the web server running the job is gone, the rows not imported are imported again when the job is resumed
+	_, local := importJobs.jobs[id]
+	if ImportStatusRunning == job.Status && false == local && time.Since(job.LastTime) > importJobStale {
+		job.Status = ImportStatusFailed
+		job.Message = "导入任务已中断，请继续导入未成功的行"
+	}
+	return job, nil
+}
+
+// purgeImportJobs forget the expired jobs run by this web server and remove the expired files in the import dir
+func purgeImportJobs(dir string) {
+	importJobs.Lock()
+	for id, job := range importJobs.jobs {
+		if ImportStatusRunning != job.Status && time.Since(job.LastTime) >= ImportJobExpire {
+			delete(importJobs.jobs, id)
+		}
+	}
+	importJobs.Unlock()
+
+	files, err := ioutil.ReadDir(dir)
+	if nil != err {
+		blog.Warnf("read the import dir %s error: %v", dir, err)
+		return
+	}
+	for _, file := range files {
+		if file.IsDir() || time.Since(file.ModTime()) < ImportJobExpire {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, file.Name())); nil != err {
+			blog.Warnf("remove the expired import file %s error: %v", file.Name(), err)
+		}
+	}
+}
+
+// readImportRows read the rows of the csv file or the first sheet
+func readImportRows(filePath string, task ImportTask) (map[int]map[string]interface{}, []ImportRowError, error) {
+	var rows map[int]map[string]interface{}
+	var cellErrs []ImportRowError
+	if ImportFormatCSV == importFileFormat(filePath) {
+		var err error
+		rows, err = getCSVRows(filePath, task.Defaults, task.Types, task.IDs)
+		if nil != err {
+			return nil, nil, err
+		}
+	} else {
+		f, err := xlsx.OpenFile(filePath)
+		if nil != err {
+			return nil, nil, err
+		}
+		if 0 == len(f.Sheets) || nil == f.Sheets[0] {
+			return nil, nil, errors.New("文件内容不能为空,未找到工作簿")
+		}
+		cols, err := checkExcelHealer(f.Sheets[0], nil, false)
+		if nil != err {
+			return nil, nil, err
+		}
+		rows, cellErrs = getExcelRows(f.Sheets[0], cols, task.Defaults, task.FirstRow)
+	}
+
+	// the report uploaded to resume the job
+	for _, data := range rows {
+		delete(data, importReportColumn)
+	}
+	return rows, cellErrs, nil
+}
+
+// importRowNums the sorted numbers of the non-empty rows or the marked rows
+func importRowNums(rows map[int]map[string]interface{}, marked map[int]bool) []int {
+	nums := make([]int, 0, len(rows)+len(marked))
+	for row, data := range rows {
+		if nil != data {
+			nums = append(nums, row)
+		}
+	}
+	for row := range marked {
+		nums = append(nums, row)
+	}
+	sort.Ints(nums)
+	return nums
+}
+
+func reportCell(row *xlsx.Row, col int) *xlsx.Cell {
+	for len(row.Cells) <= col {
+		row.AddCell()
+	}
+	return row.Cells[col]
+}
+
+func cloneHeader(header http.Header) http.Header {
+	clone := make(http.Header, len(header))
+	for key, val := range header {
+		clone[key] = append([]string(nil), val...)
+	}
+	return clone
+}
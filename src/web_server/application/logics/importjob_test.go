@@ -0,0 +1,334 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package logics
+
+import (
+	"bytes"
+	"configcenter/src/common"
+	"configcenter/src/storage/memclient"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/require"
+	"github.com/tealeg/xlsx"
+)
+
+// writeImportFile write the excel with the header rows and the ip column of the rows
+func writeImportFile(t *testing.T, dir, name string, ips ...string) string {
+	f := xlsx.NewFile()
+	sheet, err := f.AddSheet("host")
+	require.NoError(t, err)
+	for i := 0; i < headerRow; i++ {
+		row := sheet.AddRow()
+		row.AddCell().Value = common.BKHostInnerIPField
+		row.AddCell().Value = common.BKHostNameField
+	}
+	for _, ip := range ips {
+		row := sheet.AddRow()
+		row.AddCell().Value = ip
+		row.AddCell().Value = "host-" + ip
+	}
+	filePath := filepath.Join(dir, name)
+	require.NoError(t, f.Save(filePath))
+	return filePath
+}
+
+// fakeSubmitter fail the rows of the bad ips
+type fakeSubmitter struct {
+	sync.Mutex
+	bad     map[string]bool
+	batches [][]int
+}
+
+func (s *fakeSubmitter) submit(rows map[int]map[string]interface{}, header http.Header) ([]ImportRowError, error) {
+	s.Lock()
+	defer s.Unlock()
+	var errs []ImportRowError
+	batch := importRowNums(rows, nil)
+	for _, row := range batch {
+		if s.bad[rows[row][common.BKHostInnerIPField].(string)] {
+			errs = append(errs, ImportRowError{Row: row, Code: common.CCErrCommImportFailed, Message: "bad ip"})
+		}
+	}
+	s.batches = append(s.batches, batch)
+	return errs, nil
+}
+
+func TestImportJob(t *testing.T) {
+	ImportJobCache = memclient.NewMemRedis()
+	dir, err := ioutil.TempDir("", "import")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	batchSize := ImportBatchSize
+	ImportBatchSize = 2
+	defer func() { ImportBatchSize = batchSize }()
+
+	submitter := &fakeSubmitter{bad: map[string]bool{"127.0.0.3": true}}
+	task := ImportTask{
+		Kind:   ImportKindHost,
+		Submit: submitter.submit,
+		Validate: func(row int, data map[string]interface{}) []ImportRowError {
+			if "bad" == data[common.BKHostInnerIPField] {
+				return []ImportRowError{{Row: row, Field: common.BKHostInnerIPField, Code: common.CCErrCommParamsInvalid, Message: "invalid"}}
+			}
+			return nil
+		},
+	}
+
+	filePath := writeImportFile(t, dir, "import.xlsx", "127.0.0.1", "bad", "127.0.0.3", "127.0.0.4", "127.0.0.5")
+	job, err := SubmitImportJob(task, filePath, "0", "admin", http.Header{})
+	require.NoError(t, err)
+	require.Equal(t, 5, job.Total)
+	require.Equal(t, "admin", job.User)
+
+	job = WaitImportJob(job.ID)
+	require.Equal(t, ImportStatusFinished, job.Status)
+	require.Equal(t, 5, job.Processed)
+	require.Equal(t, 3, job.Succeeded)
+	require.Equal(t, 2, job.Failed)
+	require.Equal(t, []int{4, 7, 8}, job.SucceededRows())
+	require.Equal(t, []ImportRowError{
+		{Row: 5, Field: common.BKHostInnerIPField, Code: common.CCErrCommParamsInvalid, Message: "invalid"},
+		{Row: 6, Code: common.CCErrCommImportFailed, Message: "bad ip"},
+	}, job.Errors)
+	// the invalid row is not submitted
+	require.Equal(t, [][]int{{4}, {6, 7}, {8}}, submitter.batches)
+
+	buf := &bytes.Buffer{}
+	require.Error(t, WriteImportReport(job.ID, "0", "guest", buf))
+	require.NoError(t, WriteImportReport(job.ID, "0", "admin", buf))
+	report, err := xlsx.OpenBinary(buf.Bytes())
+	require.NoError(t, err)
+	rows := report.Sheets[0].Rows
+	require.Equal(t, importReportColumn, rows[headerRow-1].Cells[2].Value)
+	require.Equal(t, "[1199006]bk_host_innerip: invalid", rows[4].Cells[2].Value)
+	require.Equal(t, 2, len(rows[3].Cells))
+
+	// resume with the corrected report, only the failed rows are imported again
+	reportPath := filepath.Join(dir, "report.xlsx")
+	rows[4].Cells[0].Value = "127.0.0.2"
+	require.NoError(t, report.Save(reportPath))
+	submitter.bad = map[string]bool{}
+	submitter.batches = nil
+	_, err = ResumeImportJob(job.ID, "1", "admin", reportPath, "", nil)
+	require.Error(t, err)
+	job, err = ResumeImportJob(job.ID, "0", "admin", reportPath, "", nil)
+	require.NoError(t, err)
+
+	job = WaitImportJob(job.ID)
+	require.Equal(t, ImportStatusFinished, job.Status)
+	require.Equal(t, 5, job.Succeeded)
+	require.Equal(t, 0, job.Failed)
+	require.Equal(t, []ImportRowError{}, job.Errors)
+	require.Equal(t, [][]int{{5, 6}}, submitter.batches)
+
+	_, err = ResumeImportJob(job.ID, "0", "admin", "", "", nil)
+	require.Error(t, err)
+}
+
+func TestImportJobSubmitFailed(t *testing.T) {
+	ImportJobCache = memclient.NewMemRedis()
+	dir, err := ioutil.TempDir("", "import")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	task := ImportTask{
+		Kind: ImportKindInst,
+		Submit: func(rows map[int]map[string]interface{}, header http.Header) ([]ImportRowError, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+	job, err := SubmitImportJob(task, writeImportFile(t, dir, "import.xlsx", "127.0.0.1", "127.0.0.2"), "0", "admin", nil)
+	require.NoError(t, err)
+
+	job = WaitImportJob(job.ID)
+	require.Equal(t, 2, job.Failed)
+	require.Equal(t, common.CCErrCommHTTPDoRequestFailed, job.Errors[0].Code)
+
+	_, err = SubmitImportJob(task, writeImportFile(t, dir, "empty.xlsx"), "0", "admin", nil)
+	require.Error(t, err)
+	require.Nil(t, GetImportJob("not exist", "0", "admin"))
+	require.Nil(t, GetImportJob(job.ID, "0", "guest"))
+	require.NotNil(t, GetImportJob(job.ID, "0", "admin"))
+}
+
+func TestParseImportReply(t *testing.T) {
+	rows := map[int]map[string]interface{}{4: {}, 5: {}, 6: {}}
+
+	errs, err := parseImportReply(`{"result":false,"bk_error_code":1110004,"bk_error_msg":"failed","data":{"success":["4"],"error":["5行内网IP为空"],"update_error":["6行主机不存在"]}}`, rows, "", "error", "update_error")
+	require.NoError(t, err)
+	require.Equal(t, []ImportRowError{
+		{Row: 5, Code: 1110004, Message: "5行内网IP为空"},
+		{Row: 6, Code: 1110004, Message: "6行主机不存在"},
+	}, errs)
+
+	errs, err = parseImportReply(`{"result":true,"bk_error_code":0,"data":{"success":["4"],"error":["Line:5 Error:invalid"]}}`, rows, "", "error", "update_error")
+	require.NoError(t, err)
+	require.Equal(t, []ImportRowError{{Row: 5, Code: common.CCErrCommImportFailed, Message: "Line:5 Error:invalid"}}, errs)
+
+	errs, err = parseImportReply(`{"result":true,"data":{"host":{"insert_failed":["line:6 msg: duplicated"]}}}`, rows, "host", "insert_failed", "update_failed")
+	require.NoError(t, err)
+	require.Equal(t, []ImportRowError{{Row: 6, Code: common.CCErrCommImportFailed, Message: "line:6 msg: duplicated"}}, errs)
+
+	// the whole batch is rejected
+	errs, err = parseImportReply(`{"result":true,"data":{"host":{"errors":"the object(host) is invalid"}}}`, rows, "host", "insert_failed", "update_failed")
+	require.NoError(t, err)
+	require.Equal(t, 3, len(errs))
+
+	errs, err = parseImportReply(`{"result":false,"bk_error_code":1199000,"bk_error_msg":"no permission","data":null}`, rows, "", "error")
+	require.NoError(t, err)
+	require.Equal(t, 3, len(errs))
+	require.Equal(t, "no permission", errs[0].Message)
+
+	_, err = parseImportReply("502 Bad Gateway", rows, "", "error")
+	require.Error(t, err)
+}
+
+func TestAttrValidator(t *testing.T) {
+	validate := newAttrValidator([]interface{}{
+		map[string]interface{}{common.BKPropertyIDField: "ip", common.BKPropertyNameField: "IP", common.BKIsRequiredField: true},
+		map[string]interface{}{common.BKPropertyIDField: "cpu", common.BKPropertyTypeField: common.FiledTypeInt},
+		map[string]interface{}{common.BKPropertyIDField: "on", common.BKPropertyTypeField: common.FiledTypeBool},
+	}, true)
+
+	require.Empty(t, validate(4, map[string]interface{}{"ip": "127.0.0.1", "cpu": "8", "on": true}))
+	errs := validate(5, map[string]interface{}{"cpu": "eight", "on": "yes"})
+	require.Equal(t, 3, len(errs))
+	require.Equal(t, common.CCErrCommParamsNeedSet, errs[0].Code)
+	require.Equal(t, common.CCErrCommParamsNeedInt, errs[1].Code)
+	require.Equal(t, common.CCErrCommParamsNeedBool, errs[2].Code)
+
+	validate = newAttrValidator([]interface{}{
+		map[string]interface{}{common.BKPropertyIDField: "ip", common.BKIsRequiredField: true},
+	}, false)
+	require.Empty(t, validate(5, map[string]interface{}{}))
+}
+
+func TestImportJobCSV(t *testing.T) {
+	ImportJobCache = memclient.NewMemRedis()
+	dir, err := ioutil.TempDir("", "import")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filePath := filepath.Join(dir, "import.csv")
+	content := utf8BOM + "bk_host_innerip,bk_cpu,bk_host_name\n127.0.0.1,8,a\n,,\nbad,x,b\n"
+	require.NoError(t, ioutil.WriteFile(filePath, []byte(content), 0644))
+
+	submitter := &fakeSubmitter{bad: map[string]bool{"bad": true}}
+	task := ImportTask{
+		Kind:     ImportKindHost,
+		Defaults: common.KvMap{"import_from": common.HostAddMethodExcel},
+		Types:    map[string]string{"bk_cpu": common.FiledTypeInt},
+		Submit:   submitter.submit,
+	}
+	job, err := SubmitImportJob(task, filePath, "0", "admin", nil)
+	require.NoError(t, err)
+	require.Equal(t, ImportFormatCSV, job.Format)
+	require.Equal(t, 2, job.Total)
+
+	job = WaitImportJob(job.ID)
+	require.Equal(t, []int{2}, job.SucceededRows())
+	require.Equal(t, 4, job.Errors[0].Row)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteImportReport(job.ID, "0", "admin", buf))
+	require.Equal(t, "bk_host_innerip,bk_cpu,bk_host_name,导入结果\n127.0.0.1,8,a,\n,,,\nbad,x,b,[1199034]bad ip\n", buf.String())
+
+	// the report is imported again without the report column
+	reportPath := filepath.Join(dir, "report.csv")
+	require.NoError(t, ioutil.WriteFile(reportPath, []byte(strings.Replace(buf.String(), "bad,x", "127.0.0.4,4", 1)), 0644))
+	rows, _, err := readImportRows(reportPath, task)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"bk_host_innerip": "127.0.0.4", "bk_cpu": 4, "bk_host_name": "b", "import_from": common.HostAddMethodExcel}, rows[4])
+	require.Nil(t, rows[3])
+
+	_, err = ResumeImportJob(job.ID, "0", "admin", writeImportFile(t, dir, "import.xlsx", "127.0.0.4"), "", nil)
+	require.Error(t, err)
+	submitter.bad = map[string]bool{}
+	job, err = ResumeImportJob(job.ID, "0", "admin", reportPath, "", nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, WaitImportJob(job.ID).Succeeded)
+}
+
+func TestImportCSVNameHeader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "import")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// the csv exported with the name header
+	filePath := filepath.Join(dir, "import.csv")
+	require.NoError(t, ioutil.WriteFile(filePath, []byte("内网IP,CPU逻辑核心数,bk_host_name\n127.0.0.1,8,a\n"), 0644))
+
+	task := ImportTask{
+		Types: map[string]string{"bk_host_innerip": common.FiledTypeSingleChar, "bk_cpu": common.FiledTypeInt, "bk_host_name": common.FiledTypeSingleChar},
+		IDs:   map[string]string{"内网IP": "bk_host_innerip", "CPU逻辑核心数": "bk_cpu", "主机名称": "bk_host_name"},
+	}
+	rows, _, err := readImportRows(filePath, task)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"bk_host_innerip": "127.0.0.1", "bk_cpu": 8, "bk_host_name": "a"}, rows[2])
+}
+
+func TestImportJobInterrupted(t *testing.T) {
+	ImportJobCache = memclient.NewMemRedis()
+	dir, err := ioutil.TempDir("", "import")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// the job was run by another web server which is gone after the row 4 is imported
+	filePath := writeImportFile(t, dir, "import.xlsx", "127.0.0.1", "127.0.0.2", "127.0.0.3")
+	job := &ImportJob{
+		ID:        xid.New().String(),
+		OwnerID:   "0",
+		User:      "admin",
+		Kind:      ImportKindInst,
+		Format:    ImportFormatXlsx,
+		Status:    ImportStatusRunning,
+		Total:     3,
+		Processed: 1,
+		Succeeded: 1,
+		Errors:    []ImportRowError{},
+		LastTime:  time.Now().Add(-time.Hour),
+		params:    map[string]string{common.BKOwnerIDField: "0", common.BKObjIDField: "switch"},
+		filePath:  filePath,
+		success:   map[int]bool{4: true},
+	}
+	require.NoError(t, saveImportJob(job))
+
+	loaded := GetImportJob(job.ID, "0", "admin")
+	require.NotNil(t, loaded)
+	require.Equal(t, ImportStatusFailed, loaded.Status)
+	require.Equal(t, []int{4}, loaded.SucceededRows())
+
+	// the task is created again by the kind and the params, the rows not imported are resumed
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":true,"bk_error_code":0,"data":{}}`))
+	}))
+	defer server.Close()
+	resumed, err := ResumeImportJob(job.ID, "0", "admin", "", server.URL, http.Header{})
+	require.NoError(t, err)
+	require.Equal(t, 3, resumed.Total)
+
+	resumed = WaitImportJob(job.ID)
+	require.Equal(t, ImportStatusFinished, resumed.Status)
+	require.Equal(t, []int{4, 5, 6}, resumed.SucceededRows())
+}
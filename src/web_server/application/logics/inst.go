@@ -47,26 +47,7 @@ func GetImportInsts(f *xlsx.File, url string, header http.Header, headerRow int)
 func GetInstData(ownerID, objID, instIDStr, apiAddr string, header http.Header, kvMap map[string]string) ([]interface{}, error) {
 
 	instInfo := make([]interface{}, 0)
-	sInstCond := make(map[string]interface{})
-	instIDArr := strings.Split(instIDStr, ",")
-
-	iInstIDArr := make([]int, 0)
-	for _, j := range instIDArr {
-		instID, _ := strconv.Atoi(j)
-		iInstIDArr = append(iInstIDArr, instID)
-	}
-
-	// construct the search condition
-
-	sInstCond["fields"] = []string{}
-	sInstCond["condition"] = map[string]interface{}{
-		common.BKInstIDField: map[string]interface{}{
-			"$in": iInstIDArr,
-		},
-		common.BKOwnerIDField: ownerID,
-		common.BKObjIDField:   objID,
-	}
-	sInstCond["page"] = nil
+	sInstCond := getInstExportCond(ownerID, objID, instIDStr)
 
 	// read insts
 	url := apiAddr + fmt.Sprintf("/api/%s/inst/search/"+ownerID+"/"+objID, webCommon.API_VERSION)
@@ -111,3 +92,28 @@ func GetInstData(ownerID, objID, instIDStr, apiAddr string, header http.Header,
 	}
 	return instInfo, nil
 }
+
+// getInstExportCond the search condition of the exported instances
+func getInstExportCond(ownerID, objID, instIDStr string) map[string]interface{} {
+	sInstCond := make(map[string]interface{})
+	instIDArr := strings.Split(instIDStr, ",")
+
+	iInstIDArr := make([]int, 0)
+	for _, j := range instIDArr {
+		instID, _ := strconv.Atoi(j)
+		iInstIDArr = append(iInstIDArr, instID)
+	}
+
+	// construct the search condition
+
+	sInstCond["fields"] = []string{}
+	sInstCond["condition"] = map[string]interface{}{
+		common.BKInstIDField: map[string]interface{}{
+			"$in": iInstIDArr,
+		},
+		common.BKOwnerIDField: ownerID,
+		common.BKObjIDField:   objID,
+	}
+	sInstCond["page"] = nil
+	return sInstCond
+}
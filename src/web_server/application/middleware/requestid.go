@@ -0,0 +1,36 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except 
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and 
+ * limitations under the License.
+ */
+ 
+package middleware
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestID accept the request id of the browser or generate a new one,
+// it is passed on to the api server and written back in the response header
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rid := c.Request.Header.Get(common.BKHTTPRequestID)
+		if "" == rid {
+			rid = util.GenerateRequestID()
+			c.Request.Header.Set(common.BKHTTPRequestID, rid)
+		}
+		c.Writer.Header().Set(common.BKHTTPRequestID, rid)
+		blog.Infof("%s %s, rid: %s", c.Request.Method, c.Request.URL.Path, rid)
+		c.Next()
+	}
+}
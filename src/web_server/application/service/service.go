@@ -17,16 +17,19 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/core/cc/api"
 	"configcenter/src/common/core/cc/config"
+	"configcenter/src/common/discovery"
 	"configcenter/src/common/http/httpserver/webserver"
+	"configcenter/src/common/lifecycle"
+	"configcenter/src/common/metrics"
 	"configcenter/src/common/rdapi"
 	"configcenter/src/common/types"
 	confCenter "configcenter/src/web_server/application/config"
 	"configcenter/src/web_server/application/logics"
 	"configcenter/src/web_server/application/middleware"
-	"configcenter/src/web_server/application/rdiscover"
 	webCommon "configcenter/src/web_server/common"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
@@ -39,7 +42,7 @@ import (
 type CCWebServer struct {
 	conf     *config.CCAPIConfig
 	httpServ *gin.Engine
-	rd       *rdiscover.RegDiscover
+	rd       *discovery.RegDiscover
 	cfCenter *confCenter.ConfCenter
 }
 
@@ -57,10 +60,10 @@ func NewCCWebServer(conf *config.CCAPIConfig) (*CCWebServer, error) {
 	a.InitWaction()
 
 	//RDiscover
-	s.rd = rdiscover.NewRegDiscover(s.conf.RegDiscover, addr, port, false)
+	s.rd = discovery.NewRegDiscover(s.conf.GetRegDiscover(), types.CC_MODULE_WEBSERVER, addr, port, false, discovery.NewOptions(s.conf), types.CC_MODULE_APISERVER)
 	a.AddrSrv = s.rd
 	//ConfCenter
-	s.cfCenter = confCenter.NewConfCenter(s.conf.RegDiscover)
+	s.cfCenter = confCenter.NewConfCenter(s.conf.GetRegDiscover())
 
 	return s, nil
 }
@@ -122,12 +125,26 @@ func (ccWeb *CCWebServer) Start() error {
 		if rediserr != nil {
 			panic(rediserr)
 		}
+		// the import jobs are kept in the redis of the session
+		rediserr = a.GetDataCli(map[string]string{"redis.host": redisIp, "redis.port": redisPort, "redis.pwd": redisSecret}, "redis")
+		if rediserr != nil {
+			panic(rediserr)
+		}
+		logics.ImportJobCache = a.Cache
+		ccWeb.httpServ.Use(middleware.RequestID())
+		ccWeb.httpServ.Use(middleware.Metrics())
+		ccWeb.httpServ.GET(metrics.Path, gin.WrapH(metrics.Handler()))
+		ccWeb.httpServ.GET(api.HealthzPath, gin.WrapF(a.Healthz))
+		ccWeb.httpServ.GET(api.ReadyzPath, gin.WrapF(a.Readyz))
 		ccWeb.httpServ.Use(sessions.Sessions(sessionName, store))
 		ccWeb.httpServ.Use(middleware.Cors())
 
 		ccWeb.RegisterActions(a.Wactions)
 		middleware.APIAddr = rdapi.GetRdAddrSrvHandle(types.CC_MODULE_APISERVER, a.AddrSrv)
 		ccWeb.httpServ.Use(middleware.ValidLogin(loginURL, appCode, site, check_url, apiSite, skipLogin, multipleOwner))
+		ccWeb.httpServ.GET("/discovery/snapshot", func(c *gin.Context) {
+			c.JSON(200, api.BKAPIRsp{Result: true, Code: common.CCSuccess, Message: common.CCSuccessStr, Data: ccWeb.rd.Snapshot()})
+		})
 		ccWeb.httpServ.Static("/static", static)
 		blog.Info(static)
 		ccWeb.httpServ.LoadHTMLFiles(static + "/index.html") //("static/index.html")
@@ -189,7 +206,8 @@ func (ccWeb *CCWebServer) Start() error {
 		port, _ := ccWeb.conf.GetPort()
 		portStr := strconv.Itoa(int(port))
 		addr := ip + ":" + portStr
-		err := ccWeb.httpServ.Run(addr)
+		server := &http.Server{Addr: addr, Handler: ccWeb.httpServ}
+		err := lifecycle.Serve(server, server.ListenAndServe)
 
 		blog.Error("http listen and serve failed! err:%s", err.Error())
 		chErr <- err